@@ -19,10 +19,16 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	CommentService_GetCommentList_FullMethodName = "/comment.v1.CommentService/GetCommentList"
-	CommentService_DeleteComment_FullMethodName  = "/comment.v1.CommentService/DeleteComment"
-	CommentService_CreateComment_FullMethodName  = "/comment.v1.CommentService/CreateComment"
-	CommentService_GetMoreReplies_FullMethodName = "/comment.v1.CommentService/GetMoreReplies"
+	CommentService_GetCommentList_FullMethodName      = "/comment.v1.CommentService/GetCommentList"
+	CommentService_DeleteComment_FullMethodName       = "/comment.v1.CommentService/DeleteComment"
+	CommentService_CreateComment_FullMethodName       = "/comment.v1.CommentService/CreateComment"
+	CommentService_GetMoreReplies_FullMethodName      = "/comment.v1.CommentService/GetMoreReplies"
+	CommentService_LikeComment_FullMethodName         = "/comment.v1.CommentService/LikeComment"
+	CommentService_CancelLikeComment_FullMethodName   = "/comment.v1.CommentService/CancelLikeComment"
+	CommentService_GetCommentCount_FullMethodName     = "/comment.v1.CommentService/GetCommentCount"
+	CommentService_SubscribeComments_FullMethodName   = "/comment.v1.CommentService/SubscribeComments"
+	CommentService_ListPendingComments_FullMethodName = "/comment.v1.CommentService/ListPendingComments"
+	CommentService_ReviewComment_FullMethodName       = "/comment.v1.CommentService/ReviewComment"
 )
 
 // CommentServiceClient is the client API for CommentService service.
@@ -31,11 +37,25 @@ const (
 type CommentServiceClient interface {
 	// GetCommentList Comment的id为0 获取一级评论
 	GetCommentList(ctx context.Context, in *CommentListRequest, opts ...grpc.CallOption) (*CommentListResponse, error)
-	// DeleteComment 删除评论，删除本评论和其子评论
+	// DeleteComment 软删除评论，子评论都保留，列表里面显示 "该评论已删除"。
+	// 只有评论者和评论对象的作者可以删除
 	DeleteComment(ctx context.Context, in *DeleteCommentRequest, opts ...grpc.CallOption) (*DeleteCommentResponse, error)
 	// CreateComment 创建评论
 	CreateComment(ctx context.Context, in *CreateCommentRequest, opts ...grpc.CallOption) (*CreateCommentResponse, error)
 	GetMoreReplies(ctx context.Context, in *GetMoreRepliesRequest, opts ...grpc.CallOption) (*GetMoreRepliesResponse, error)
+	// LikeComment 点赞评论，重复点赞不会重复计数
+	LikeComment(ctx context.Context, in *LikeCommentRequest, opts ...grpc.CallOption) (*LikeCommentResponse, error)
+	CancelLikeComment(ctx context.Context, in *CancelLikeCommentRequest, opts ...grpc.CallOption) (*CancelLikeCommentResponse, error)
+	// GetCommentCount 批量查询评论数，包括回复，只算能看到的、没删除的
+	GetCommentCount(ctx context.Context, in *GetCommentCountRequest, opts ...grpc.CallOption) (*GetCommentCountResponse, error)
+	// SubscribeComments 推送新发表的和审核通过的评论，直到客户端断开。
+	// 基于 Redis 的 pub/sub，不保证送达
+	SubscribeComments(ctx context.Context, in *SubscribeCommentsRequest, opts ...grpc.CallOption) (CommentService_SubscribeCommentsClient, error)
+	// 下面是管理后台用的
+	// ListPendingComments 等待人工审核的评论，先提交的在前面
+	ListPendingComments(ctx context.Context, in *ListPendingCommentsRequest, opts ...grpc.CallOption) (*ListPendingCommentsResponse, error)
+	// ReviewComment 人工审核，只能改成通过、拒绝或者折叠
+	ReviewComment(ctx context.Context, in *ReviewCommentRequest, opts ...grpc.CallOption) (*ReviewCommentResponse, error)
 }
 
 type commentServiceClient struct {
@@ -82,17 +102,108 @@ func (c *commentServiceClient) GetMoreReplies(ctx context.Context, in *GetMoreRe
 	return out, nil
 }
 
+func (c *commentServiceClient) LikeComment(ctx context.Context, in *LikeCommentRequest, opts ...grpc.CallOption) (*LikeCommentResponse, error) {
+	out := new(LikeCommentResponse)
+	err := c.cc.Invoke(ctx, CommentService_LikeComment_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commentServiceClient) CancelLikeComment(ctx context.Context, in *CancelLikeCommentRequest, opts ...grpc.CallOption) (*CancelLikeCommentResponse, error) {
+	out := new(CancelLikeCommentResponse)
+	err := c.cc.Invoke(ctx, CommentService_CancelLikeComment_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commentServiceClient) GetCommentCount(ctx context.Context, in *GetCommentCountRequest, opts ...grpc.CallOption) (*GetCommentCountResponse, error) {
+	out := new(GetCommentCountResponse)
+	err := c.cc.Invoke(ctx, CommentService_GetCommentCount_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commentServiceClient) SubscribeComments(ctx context.Context, in *SubscribeCommentsRequest, opts ...grpc.CallOption) (CommentService_SubscribeCommentsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &CommentService_ServiceDesc.Streams[0], CommentService_SubscribeComments_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &commentServiceSubscribeCommentsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CommentService_SubscribeCommentsClient interface {
+	Recv() (*Comment, error)
+	grpc.ClientStream
+}
+
+type commentServiceSubscribeCommentsClient struct {
+	grpc.ClientStream
+}
+
+func (x *commentServiceSubscribeCommentsClient) Recv() (*Comment, error) {
+	m := new(Comment)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *commentServiceClient) ListPendingComments(ctx context.Context, in *ListPendingCommentsRequest, opts ...grpc.CallOption) (*ListPendingCommentsResponse, error) {
+	out := new(ListPendingCommentsResponse)
+	err := c.cc.Invoke(ctx, CommentService_ListPendingComments_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commentServiceClient) ReviewComment(ctx context.Context, in *ReviewCommentRequest, opts ...grpc.CallOption) (*ReviewCommentResponse, error) {
+	out := new(ReviewCommentResponse)
+	err := c.cc.Invoke(ctx, CommentService_ReviewComment_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CommentServiceServer is the server API for CommentService service.
 // All implementations must embed UnimplementedCommentServiceServer
 // for forward compatibility
 type CommentServiceServer interface {
 	// GetCommentList Comment的id为0 获取一级评论
 	GetCommentList(context.Context, *CommentListRequest) (*CommentListResponse, error)
-	// DeleteComment 删除评论，删除本评论和其子评论
+	// DeleteComment 软删除评论，子评论都保留，列表里面显示 "该评论已删除"。
+	// 只有评论者和评论对象的作者可以删除
 	DeleteComment(context.Context, *DeleteCommentRequest) (*DeleteCommentResponse, error)
 	// CreateComment 创建评论
 	CreateComment(context.Context, *CreateCommentRequest) (*CreateCommentResponse, error)
 	GetMoreReplies(context.Context, *GetMoreRepliesRequest) (*GetMoreRepliesResponse, error)
+	// LikeComment 点赞评论，重复点赞不会重复计数
+	LikeComment(context.Context, *LikeCommentRequest) (*LikeCommentResponse, error)
+	CancelLikeComment(context.Context, *CancelLikeCommentRequest) (*CancelLikeCommentResponse, error)
+	// GetCommentCount 批量查询评论数，包括回复，只算能看到的、没删除的
+	GetCommentCount(context.Context, *GetCommentCountRequest) (*GetCommentCountResponse, error)
+	// SubscribeComments 推送新发表的和审核通过的评论，直到客户端断开。
+	// 基于 Redis 的 pub/sub，不保证送达
+	SubscribeComments(*SubscribeCommentsRequest, CommentService_SubscribeCommentsServer) error
+	// 下面是管理后台用的
+	// ListPendingComments 等待人工审核的评论，先提交的在前面
+	ListPendingComments(context.Context, *ListPendingCommentsRequest) (*ListPendingCommentsResponse, error)
+	// ReviewComment 人工审核，只能改成通过、拒绝或者折叠
+	ReviewComment(context.Context, *ReviewCommentRequest) (*ReviewCommentResponse, error)
 	mustEmbedUnimplementedCommentServiceServer()
 }
 
@@ -112,6 +223,24 @@ func (UnimplementedCommentServiceServer) CreateComment(context.Context, *CreateC
 func (UnimplementedCommentServiceServer) GetMoreReplies(context.Context, *GetMoreRepliesRequest) (*GetMoreRepliesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMoreReplies not implemented")
 }
+func (UnimplementedCommentServiceServer) LikeComment(context.Context, *LikeCommentRequest) (*LikeCommentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LikeComment not implemented")
+}
+func (UnimplementedCommentServiceServer) CancelLikeComment(context.Context, *CancelLikeCommentRequest) (*CancelLikeCommentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelLikeComment not implemented")
+}
+func (UnimplementedCommentServiceServer) GetCommentCount(context.Context, *GetCommentCountRequest) (*GetCommentCountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCommentCount not implemented")
+}
+func (UnimplementedCommentServiceServer) SubscribeComments(*SubscribeCommentsRequest, CommentService_SubscribeCommentsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeComments not implemented")
+}
+func (UnimplementedCommentServiceServer) ListPendingComments(context.Context, *ListPendingCommentsRequest) (*ListPendingCommentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPendingComments not implemented")
+}
+func (UnimplementedCommentServiceServer) ReviewComment(context.Context, *ReviewCommentRequest) (*ReviewCommentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReviewComment not implemented")
+}
 func (UnimplementedCommentServiceServer) mustEmbedUnimplementedCommentServiceServer() {}
 
 // UnsafeCommentServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -197,6 +326,117 @@ func _CommentService_GetMoreReplies_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CommentService_LikeComment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LikeCommentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommentServiceServer).LikeComment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommentService_LikeComment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommentServiceServer).LikeComment(ctx, req.(*LikeCommentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CommentService_CancelLikeComment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelLikeCommentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommentServiceServer).CancelLikeComment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommentService_CancelLikeComment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommentServiceServer).CancelLikeComment(ctx, req.(*CancelLikeCommentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CommentService_GetCommentCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCommentCountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommentServiceServer).GetCommentCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommentService_GetCommentCount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommentServiceServer).GetCommentCount(ctx, req.(*GetCommentCountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CommentService_SubscribeComments_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeCommentsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CommentServiceServer).SubscribeComments(m, &commentServiceSubscribeCommentsServer{stream})
+}
+
+type CommentService_SubscribeCommentsServer interface {
+	Send(*Comment) error
+	grpc.ServerStream
+}
+
+type commentServiceSubscribeCommentsServer struct {
+	grpc.ServerStream
+}
+
+func (x *commentServiceSubscribeCommentsServer) Send(m *Comment) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _CommentService_ListPendingComments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPendingCommentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommentServiceServer).ListPendingComments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommentService_ListPendingComments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommentServiceServer).ListPendingComments(ctx, req.(*ListPendingCommentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CommentService_ReviewComment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReviewCommentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommentServiceServer).ReviewComment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommentService_ReviewComment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommentServiceServer).ReviewComment(ctx, req.(*ReviewCommentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CommentService_ServiceDesc is the grpc.ServiceDesc for CommentService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -220,7 +460,33 @@ var CommentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetMoreReplies",
 			Handler:    _CommentService_GetMoreReplies_Handler,
 		},
+		{
+			MethodName: "LikeComment",
+			Handler:    _CommentService_LikeComment_Handler,
+		},
+		{
+			MethodName: "CancelLikeComment",
+			Handler:    _CommentService_CancelLikeComment_Handler,
+		},
+		{
+			MethodName: "GetCommentCount",
+			Handler:    _CommentService_GetCommentCount_Handler,
+		},
+		{
+			MethodName: "ListPendingComments",
+			Handler:    _CommentService_ListPendingComments_Handler,
+		},
+		{
+			MethodName: "ReviewComment",
+			Handler:    _CommentService_ReviewComment_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeComments",
+			Handler:       _CommentService_SubscribeComments_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "comment/v1/comment.proto",
 }
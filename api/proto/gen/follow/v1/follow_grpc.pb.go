@@ -19,10 +19,25 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	FollowService_Follow_FullMethodName       = "/follow.v1.FollowService/Follow"
-	FollowService_CancelFollow_FullMethodName = "/follow.v1.FollowService/CancelFollow"
-	FollowService_GetFollowee_FullMethodName  = "/follow.v1.FollowService/GetFollowee"
-	FollowService_FollowInfo_FullMethodName   = "/follow.v1.FollowService/FollowInfo"
+	FollowService_Follow_FullMethodName             = "/follow.v1.FollowService/Follow"
+	FollowService_CancelFollow_FullMethodName       = "/follow.v1.FollowService/CancelFollow"
+	FollowService_UpdateFollow_FullMethodName       = "/follow.v1.FollowService/UpdateFollow"
+	FollowService_GetFollowee_FullMethodName        = "/follow.v1.FollowService/GetFollowee"
+	FollowService_GetFolloweeByGroup_FullMethodName = "/follow.v1.FollowService/GetFolloweeByGroup"
+	FollowService_GetFollower_FullMethodName        = "/follow.v1.FollowService/GetFollower"
+	FollowService_GetSpecialFollower_FullMethodName = "/follow.v1.FollowService/GetSpecialFollower"
+	FollowService_GetMutualFollow_FullMethodName    = "/follow.v1.FollowService/GetMutualFollow"
+	FollowService_FollowInfo_FullMethodName         = "/follow.v1.FollowService/FollowInfo"
+	FollowService_BatchFollowStatus_FullMethodName  = "/follow.v1.FollowService/BatchFollowStatus"
+	FollowService_GetFollowStatics_FullMethodName   = "/follow.v1.FollowService/GetFollowStatics"
+	FollowService_CreateFollowGroup_FullMethodName  = "/follow.v1.FollowService/CreateFollowGroup"
+	FollowService_RenameFollowGroup_FullMethodName  = "/follow.v1.FollowService/RenameFollowGroup"
+	FollowService_DeleteFollowGroup_FullMethodName  = "/follow.v1.FollowService/DeleteFollowGroup"
+	FollowService_ListFollowGroup_FullMethodName    = "/follow.v1.FollowService/ListFollowGroup"
+	FollowService_Block_FullMethodName              = "/follow.v1.FollowService/Block"
+	FollowService_Unblock_FullMethodName            = "/follow.v1.FollowService/Unblock"
+	FollowService_ListBlocked_FullMethodName        = "/follow.v1.FollowService/ListBlocked"
+	FollowService_IsBlocked_FullMethodName          = "/follow.v1.FollowService/IsBlocked"
 )
 
 // FollowServiceClient is the client API for FollowService service.
@@ -32,9 +47,36 @@ type FollowServiceClient interface {
 	// 增删
 	Follow(ctx context.Context, in *FollowRequest, opts ...grpc.CallOption) (*FollowResponse, error)
 	CancelFollow(ctx context.Context, in *CancelFollowRequest, opts ...grpc.CallOption) (*CancelFollowResponse, error)
+	// 修改分组、特别关注和备注
+	UpdateFollow(ctx context.Context, in *UpdateFollowRequest, opts ...grpc.CallOption) (*UpdateFollowResponse, error)
 	// 获得某个人的关注列表
 	GetFollowee(ctx context.Context, in *GetFolloweeRequest, opts ...grpc.CallOption) (*GetFolloweeResponse, error)
+	// 获得某个人某个分组里面的关注列表
+	GetFolloweeByGroup(ctx context.Context, in *GetFolloweeByGroupRequest, opts ...grpc.CallOption) (*GetFolloweeByGroupResponse, error)
+	// 获得某个人的粉丝列表
+	GetFollower(ctx context.Context, in *GetFollowerRequest, opts ...grpc.CallOption) (*GetFollowerResponse, error)
+	// 特别关注了某个人的粉丝，feed 给粉丝多的作者推送新文章的时候用
+	GetSpecialFollower(ctx context.Context, in *GetSpecialFollowerRequest, opts ...grpc.CallOption) (*GetSpecialFollowerResponse, error)
+	// 获得和某个人互相关注的人
+	GetMutualFollow(ctx context.Context, in *GetMutualFollowRequest, opts ...grpc.CallOption) (*GetMutualFollowResponse, error)
 	FollowInfo(ctx context.Context, in *FollowInfoRequest, opts ...grpc.CallOption) (*FollowInfoResponse, error)
+	// 批量查询和一批人的关注关系，渲染 feed 的时候用
+	BatchFollowStatus(ctx context.Context, in *BatchFollowStatusRequest, opts ...grpc.CallOption) (*BatchFollowStatusResponse, error)
+	// 关注了多少人，有多少粉丝
+	GetFollowStatics(ctx context.Context, in *GetFollowStaticsRequest, opts ...grpc.CallOption) (*GetFollowStaticsResponse, error)
+	// 分组
+	CreateFollowGroup(ctx context.Context, in *CreateFollowGroupRequest, opts ...grpc.CallOption) (*CreateFollowGroupResponse, error)
+	RenameFollowGroup(ctx context.Context, in *RenameFollowGroupRequest, opts ...grpc.CallOption) (*RenameFollowGroupResponse, error)
+	// 分组里面的人不会取消关注，只是变成没有分组
+	DeleteFollowGroup(ctx context.Context, in *DeleteFollowGroupRequest, opts ...grpc.CallOption) (*DeleteFollowGroupResponse, error)
+	ListFollowGroup(ctx context.Context, in *ListFollowGroupRequest, opts ...grpc.CallOption) (*ListFollowGroupResponse, error)
+	// 拉黑，两个方向的关注都会取消，之后也不能再关注
+	Block(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*BlockResponse, error)
+	Unblock(ctx context.Context, in *UnblockRequest, opts ...grpc.CallOption) (*UnblockResponse, error)
+	// 黑名单，按照拉黑的时间倒序
+	ListBlocked(ctx context.Context, in *ListBlockedRequest, opts ...grpc.CallOption) (*ListBlockedResponse, error)
+	// 其它服务用来检查有没有被拉黑，调用方应该自己缓存结果
+	IsBlocked(ctx context.Context, in *IsBlockedRequest, opts ...grpc.CallOption) (*IsBlockedResponse, error)
 }
 
 type followServiceClient struct {
@@ -63,6 +105,15 @@ func (c *followServiceClient) CancelFollow(ctx context.Context, in *CancelFollow
 	return out, nil
 }
 
+func (c *followServiceClient) UpdateFollow(ctx context.Context, in *UpdateFollowRequest, opts ...grpc.CallOption) (*UpdateFollowResponse, error) {
+	out := new(UpdateFollowResponse)
+	err := c.cc.Invoke(ctx, FollowService_UpdateFollow_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *followServiceClient) GetFollowee(ctx context.Context, in *GetFolloweeRequest, opts ...grpc.CallOption) (*GetFolloweeResponse, error) {
 	out := new(GetFolloweeResponse)
 	err := c.cc.Invoke(ctx, FollowService_GetFollowee_FullMethodName, in, out, opts...)
@@ -72,6 +123,42 @@ func (c *followServiceClient) GetFollowee(ctx context.Context, in *GetFolloweeRe
 	return out, nil
 }
 
+func (c *followServiceClient) GetFolloweeByGroup(ctx context.Context, in *GetFolloweeByGroupRequest, opts ...grpc.CallOption) (*GetFolloweeByGroupResponse, error) {
+	out := new(GetFolloweeByGroupResponse)
+	err := c.cc.Invoke(ctx, FollowService_GetFolloweeByGroup_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *followServiceClient) GetFollower(ctx context.Context, in *GetFollowerRequest, opts ...grpc.CallOption) (*GetFollowerResponse, error) {
+	out := new(GetFollowerResponse)
+	err := c.cc.Invoke(ctx, FollowService_GetFollower_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *followServiceClient) GetSpecialFollower(ctx context.Context, in *GetSpecialFollowerRequest, opts ...grpc.CallOption) (*GetSpecialFollowerResponse, error) {
+	out := new(GetSpecialFollowerResponse)
+	err := c.cc.Invoke(ctx, FollowService_GetSpecialFollower_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *followServiceClient) GetMutualFollow(ctx context.Context, in *GetMutualFollowRequest, opts ...grpc.CallOption) (*GetMutualFollowResponse, error) {
+	out := new(GetMutualFollowResponse)
+	err := c.cc.Invoke(ctx, FollowService_GetMutualFollow_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *followServiceClient) FollowInfo(ctx context.Context, in *FollowInfoRequest, opts ...grpc.CallOption) (*FollowInfoResponse, error) {
 	out := new(FollowInfoResponse)
 	err := c.cc.Invoke(ctx, FollowService_FollowInfo_FullMethodName, in, out, opts...)
@@ -81,6 +168,96 @@ func (c *followServiceClient) FollowInfo(ctx context.Context, in *FollowInfoRequ
 	return out, nil
 }
 
+func (c *followServiceClient) BatchFollowStatus(ctx context.Context, in *BatchFollowStatusRequest, opts ...grpc.CallOption) (*BatchFollowStatusResponse, error) {
+	out := new(BatchFollowStatusResponse)
+	err := c.cc.Invoke(ctx, FollowService_BatchFollowStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *followServiceClient) GetFollowStatics(ctx context.Context, in *GetFollowStaticsRequest, opts ...grpc.CallOption) (*GetFollowStaticsResponse, error) {
+	out := new(GetFollowStaticsResponse)
+	err := c.cc.Invoke(ctx, FollowService_GetFollowStatics_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *followServiceClient) CreateFollowGroup(ctx context.Context, in *CreateFollowGroupRequest, opts ...grpc.CallOption) (*CreateFollowGroupResponse, error) {
+	out := new(CreateFollowGroupResponse)
+	err := c.cc.Invoke(ctx, FollowService_CreateFollowGroup_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *followServiceClient) RenameFollowGroup(ctx context.Context, in *RenameFollowGroupRequest, opts ...grpc.CallOption) (*RenameFollowGroupResponse, error) {
+	out := new(RenameFollowGroupResponse)
+	err := c.cc.Invoke(ctx, FollowService_RenameFollowGroup_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *followServiceClient) DeleteFollowGroup(ctx context.Context, in *DeleteFollowGroupRequest, opts ...grpc.CallOption) (*DeleteFollowGroupResponse, error) {
+	out := new(DeleteFollowGroupResponse)
+	err := c.cc.Invoke(ctx, FollowService_DeleteFollowGroup_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *followServiceClient) ListFollowGroup(ctx context.Context, in *ListFollowGroupRequest, opts ...grpc.CallOption) (*ListFollowGroupResponse, error) {
+	out := new(ListFollowGroupResponse)
+	err := c.cc.Invoke(ctx, FollowService_ListFollowGroup_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *followServiceClient) Block(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*BlockResponse, error) {
+	out := new(BlockResponse)
+	err := c.cc.Invoke(ctx, FollowService_Block_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *followServiceClient) Unblock(ctx context.Context, in *UnblockRequest, opts ...grpc.CallOption) (*UnblockResponse, error) {
+	out := new(UnblockResponse)
+	err := c.cc.Invoke(ctx, FollowService_Unblock_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *followServiceClient) ListBlocked(ctx context.Context, in *ListBlockedRequest, opts ...grpc.CallOption) (*ListBlockedResponse, error) {
+	out := new(ListBlockedResponse)
+	err := c.cc.Invoke(ctx, FollowService_ListBlocked_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *followServiceClient) IsBlocked(ctx context.Context, in *IsBlockedRequest, opts ...grpc.CallOption) (*IsBlockedResponse, error) {
+	out := new(IsBlockedResponse)
+	err := c.cc.Invoke(ctx, FollowService_IsBlocked_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // FollowServiceServer is the server API for FollowService service.
 // All implementations must embed UnimplementedFollowServiceServer
 // for forward compatibility
@@ -88,9 +265,36 @@ type FollowServiceServer interface {
 	// 增删
 	Follow(context.Context, *FollowRequest) (*FollowResponse, error)
 	CancelFollow(context.Context, *CancelFollowRequest) (*CancelFollowResponse, error)
+	// 修改分组、特别关注和备注
+	UpdateFollow(context.Context, *UpdateFollowRequest) (*UpdateFollowResponse, error)
 	// 获得某个人的关注列表
 	GetFollowee(context.Context, *GetFolloweeRequest) (*GetFolloweeResponse, error)
+	// 获得某个人某个分组里面的关注列表
+	GetFolloweeByGroup(context.Context, *GetFolloweeByGroupRequest) (*GetFolloweeByGroupResponse, error)
+	// 获得某个人的粉丝列表
+	GetFollower(context.Context, *GetFollowerRequest) (*GetFollowerResponse, error)
+	// 特别关注了某个人的粉丝，feed 给粉丝多的作者推送新文章的时候用
+	GetSpecialFollower(context.Context, *GetSpecialFollowerRequest) (*GetSpecialFollowerResponse, error)
+	// 获得和某个人互相关注的人
+	GetMutualFollow(context.Context, *GetMutualFollowRequest) (*GetMutualFollowResponse, error)
 	FollowInfo(context.Context, *FollowInfoRequest) (*FollowInfoResponse, error)
+	// 批量查询和一批人的关注关系，渲染 feed 的时候用
+	BatchFollowStatus(context.Context, *BatchFollowStatusRequest) (*BatchFollowStatusResponse, error)
+	// 关注了多少人，有多少粉丝
+	GetFollowStatics(context.Context, *GetFollowStaticsRequest) (*GetFollowStaticsResponse, error)
+	// 分组
+	CreateFollowGroup(context.Context, *CreateFollowGroupRequest) (*CreateFollowGroupResponse, error)
+	RenameFollowGroup(context.Context, *RenameFollowGroupRequest) (*RenameFollowGroupResponse, error)
+	// 分组里面的人不会取消关注，只是变成没有分组
+	DeleteFollowGroup(context.Context, *DeleteFollowGroupRequest) (*DeleteFollowGroupResponse, error)
+	ListFollowGroup(context.Context, *ListFollowGroupRequest) (*ListFollowGroupResponse, error)
+	// 拉黑，两个方向的关注都会取消，之后也不能再关注
+	Block(context.Context, *BlockRequest) (*BlockResponse, error)
+	Unblock(context.Context, *UnblockRequest) (*UnblockResponse, error)
+	// 黑名单，按照拉黑的时间倒序
+	ListBlocked(context.Context, *ListBlockedRequest) (*ListBlockedResponse, error)
+	// 其它服务用来检查有没有被拉黑，调用方应该自己缓存结果
+	IsBlocked(context.Context, *IsBlockedRequest) (*IsBlockedResponse, error)
 	mustEmbedUnimplementedFollowServiceServer()
 }
 
@@ -104,12 +308,57 @@ func (UnimplementedFollowServiceServer) Follow(context.Context, *FollowRequest)
 func (UnimplementedFollowServiceServer) CancelFollow(context.Context, *CancelFollowRequest) (*CancelFollowResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CancelFollow not implemented")
 }
+func (UnimplementedFollowServiceServer) UpdateFollow(context.Context, *UpdateFollowRequest) (*UpdateFollowResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateFollow not implemented")
+}
 func (UnimplementedFollowServiceServer) GetFollowee(context.Context, *GetFolloweeRequest) (*GetFolloweeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetFollowee not implemented")
 }
+func (UnimplementedFollowServiceServer) GetFolloweeByGroup(context.Context, *GetFolloweeByGroupRequest) (*GetFolloweeByGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFolloweeByGroup not implemented")
+}
+func (UnimplementedFollowServiceServer) GetFollower(context.Context, *GetFollowerRequest) (*GetFollowerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFollower not implemented")
+}
+func (UnimplementedFollowServiceServer) GetSpecialFollower(context.Context, *GetSpecialFollowerRequest) (*GetSpecialFollowerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSpecialFollower not implemented")
+}
+func (UnimplementedFollowServiceServer) GetMutualFollow(context.Context, *GetMutualFollowRequest) (*GetMutualFollowResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMutualFollow not implemented")
+}
 func (UnimplementedFollowServiceServer) FollowInfo(context.Context, *FollowInfoRequest) (*FollowInfoResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FollowInfo not implemented")
 }
+func (UnimplementedFollowServiceServer) BatchFollowStatus(context.Context, *BatchFollowStatusRequest) (*BatchFollowStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchFollowStatus not implemented")
+}
+func (UnimplementedFollowServiceServer) GetFollowStatics(context.Context, *GetFollowStaticsRequest) (*GetFollowStaticsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFollowStatics not implemented")
+}
+func (UnimplementedFollowServiceServer) CreateFollowGroup(context.Context, *CreateFollowGroupRequest) (*CreateFollowGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateFollowGroup not implemented")
+}
+func (UnimplementedFollowServiceServer) RenameFollowGroup(context.Context, *RenameFollowGroupRequest) (*RenameFollowGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenameFollowGroup not implemented")
+}
+func (UnimplementedFollowServiceServer) DeleteFollowGroup(context.Context, *DeleteFollowGroupRequest) (*DeleteFollowGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteFollowGroup not implemented")
+}
+func (UnimplementedFollowServiceServer) ListFollowGroup(context.Context, *ListFollowGroupRequest) (*ListFollowGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFollowGroup not implemented")
+}
+func (UnimplementedFollowServiceServer) Block(context.Context, *BlockRequest) (*BlockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Block not implemented")
+}
+func (UnimplementedFollowServiceServer) Unblock(context.Context, *UnblockRequest) (*UnblockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unblock not implemented")
+}
+func (UnimplementedFollowServiceServer) ListBlocked(context.Context, *ListBlockedRequest) (*ListBlockedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBlocked not implemented")
+}
+func (UnimplementedFollowServiceServer) IsBlocked(context.Context, *IsBlockedRequest) (*IsBlockedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IsBlocked not implemented")
+}
 func (UnimplementedFollowServiceServer) mustEmbedUnimplementedFollowServiceServer() {}
 
 // UnsafeFollowServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -159,6 +408,24 @@ func _FollowService_CancelFollow_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _FollowService_UpdateFollow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateFollowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FollowServiceServer).UpdateFollow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FollowService_UpdateFollow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FollowServiceServer).UpdateFollow(ctx, req.(*UpdateFollowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _FollowService_GetFollowee_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetFolloweeRequest)
 	if err := dec(in); err != nil {
@@ -177,6 +444,78 @@ func _FollowService_GetFollowee_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _FollowService_GetFolloweeByGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFolloweeByGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FollowServiceServer).GetFolloweeByGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FollowService_GetFolloweeByGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FollowServiceServer).GetFolloweeByGroup(ctx, req.(*GetFolloweeByGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FollowService_GetFollower_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFollowerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FollowServiceServer).GetFollower(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FollowService_GetFollower_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FollowServiceServer).GetFollower(ctx, req.(*GetFollowerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FollowService_GetSpecialFollower_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSpecialFollowerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FollowServiceServer).GetSpecialFollower(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FollowService_GetSpecialFollower_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FollowServiceServer).GetSpecialFollower(ctx, req.(*GetSpecialFollowerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FollowService_GetMutualFollow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMutualFollowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FollowServiceServer).GetMutualFollow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FollowService_GetMutualFollow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FollowServiceServer).GetMutualFollow(ctx, req.(*GetMutualFollowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _FollowService_FollowInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(FollowInfoRequest)
 	if err := dec(in); err != nil {
@@ -195,6 +534,186 @@ func _FollowService_FollowInfo_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _FollowService_BatchFollowStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchFollowStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FollowServiceServer).BatchFollowStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FollowService_BatchFollowStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FollowServiceServer).BatchFollowStatus(ctx, req.(*BatchFollowStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FollowService_GetFollowStatics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFollowStaticsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FollowServiceServer).GetFollowStatics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FollowService_GetFollowStatics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FollowServiceServer).GetFollowStatics(ctx, req.(*GetFollowStaticsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FollowService_CreateFollowGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateFollowGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FollowServiceServer).CreateFollowGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FollowService_CreateFollowGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FollowServiceServer).CreateFollowGroup(ctx, req.(*CreateFollowGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FollowService_RenameFollowGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameFollowGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FollowServiceServer).RenameFollowGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FollowService_RenameFollowGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FollowServiceServer).RenameFollowGroup(ctx, req.(*RenameFollowGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FollowService_DeleteFollowGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteFollowGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FollowServiceServer).DeleteFollowGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FollowService_DeleteFollowGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FollowServiceServer).DeleteFollowGroup(ctx, req.(*DeleteFollowGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FollowService_ListFollowGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFollowGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FollowServiceServer).ListFollowGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FollowService_ListFollowGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FollowServiceServer).ListFollowGroup(ctx, req.(*ListFollowGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FollowService_Block_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FollowServiceServer).Block(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FollowService_Block_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FollowServiceServer).Block(ctx, req.(*BlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FollowService_Unblock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnblockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FollowServiceServer).Unblock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FollowService_Unblock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FollowServiceServer).Unblock(ctx, req.(*UnblockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FollowService_ListBlocked_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBlockedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FollowServiceServer).ListBlocked(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FollowService_ListBlocked_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FollowServiceServer).ListBlocked(ctx, req.(*ListBlockedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FollowService_IsBlocked_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IsBlockedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FollowServiceServer).IsBlocked(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FollowService_IsBlocked_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FollowServiceServer).IsBlocked(ctx, req.(*IsBlockedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // FollowService_ServiceDesc is the grpc.ServiceDesc for FollowService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -210,14 +729,74 @@ var FollowService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CancelFollow",
 			Handler:    _FollowService_CancelFollow_Handler,
 		},
+		{
+			MethodName: "UpdateFollow",
+			Handler:    _FollowService_UpdateFollow_Handler,
+		},
 		{
 			MethodName: "GetFollowee",
 			Handler:    _FollowService_GetFollowee_Handler,
 		},
+		{
+			MethodName: "GetFolloweeByGroup",
+			Handler:    _FollowService_GetFolloweeByGroup_Handler,
+		},
+		{
+			MethodName: "GetFollower",
+			Handler:    _FollowService_GetFollower_Handler,
+		},
+		{
+			MethodName: "GetSpecialFollower",
+			Handler:    _FollowService_GetSpecialFollower_Handler,
+		},
+		{
+			MethodName: "GetMutualFollow",
+			Handler:    _FollowService_GetMutualFollow_Handler,
+		},
 		{
 			MethodName: "FollowInfo",
 			Handler:    _FollowService_FollowInfo_Handler,
 		},
+		{
+			MethodName: "BatchFollowStatus",
+			Handler:    _FollowService_BatchFollowStatus_Handler,
+		},
+		{
+			MethodName: "GetFollowStatics",
+			Handler:    _FollowService_GetFollowStatics_Handler,
+		},
+		{
+			MethodName: "CreateFollowGroup",
+			Handler:    _FollowService_CreateFollowGroup_Handler,
+		},
+		{
+			MethodName: "RenameFollowGroup",
+			Handler:    _FollowService_RenameFollowGroup_Handler,
+		},
+		{
+			MethodName: "DeleteFollowGroup",
+			Handler:    _FollowService_DeleteFollowGroup_Handler,
+		},
+		{
+			MethodName: "ListFollowGroup",
+			Handler:    _FollowService_ListFollowGroup_Handler,
+		},
+		{
+			MethodName: "Block",
+			Handler:    _FollowService_Block_Handler,
+		},
+		{
+			MethodName: "Unblock",
+			Handler:    _FollowService_Unblock_Handler,
+		},
+		{
+			MethodName: "ListBlocked",
+			Handler:    _FollowService_ListBlocked_Handler,
+		},
+		{
+			MethodName: "IsBlocked",
+			Handler:    _FollowService_IsBlocked_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "follow/v1/follow.proto",
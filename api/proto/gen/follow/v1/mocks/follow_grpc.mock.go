@@ -0,0 +1,776 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./follow_grpc.pb.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./follow_grpc.pb.go -package=followmocks -destination=mocks/follow_grpc.mock.go
+//
+// Package followmocks is a generated GoMock package.
+package followmocks
+
+import (
+	context "context"
+	reflect "reflect"
+	followv1 "webooktrial/api/proto/gen/follow/v1"
+
+	gomock "go.uber.org/mock/gomock"
+	grpc "google.golang.org/grpc"
+)
+
+// MockFollowServiceClient is a mock of FollowServiceClient interface.
+type MockFollowServiceClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockFollowServiceClientMockRecorder
+}
+
+// MockFollowServiceClientMockRecorder is the mock recorder for MockFollowServiceClient.
+type MockFollowServiceClientMockRecorder struct {
+	mock *MockFollowServiceClient
+}
+
+// NewMockFollowServiceClient creates a new mock instance.
+func NewMockFollowServiceClient(ctrl *gomock.Controller) *MockFollowServiceClient {
+	mock := &MockFollowServiceClient{ctrl: ctrl}
+	mock.recorder = &MockFollowServiceClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFollowServiceClient) EXPECT() *MockFollowServiceClientMockRecorder {
+	return m.recorder
+}
+
+// BatchFollowStatus mocks base method.
+func (m *MockFollowServiceClient) BatchFollowStatus(ctx context.Context, in *followv1.BatchFollowStatusRequest, opts ...grpc.CallOption) (*followv1.BatchFollowStatusResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "BatchFollowStatus", varargs...)
+	ret0, _ := ret[0].(*followv1.BatchFollowStatusResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchFollowStatus indicates an expected call of BatchFollowStatus.
+func (mr *MockFollowServiceClientMockRecorder) BatchFollowStatus(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchFollowStatus", reflect.TypeOf((*MockFollowServiceClient)(nil).BatchFollowStatus), varargs...)
+}
+
+// Block mocks base method.
+func (m *MockFollowServiceClient) Block(ctx context.Context, in *followv1.BlockRequest, opts ...grpc.CallOption) (*followv1.BlockResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Block", varargs...)
+	ret0, _ := ret[0].(*followv1.BlockResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Block indicates an expected call of Block.
+func (mr *MockFollowServiceClientMockRecorder) Block(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Block", reflect.TypeOf((*MockFollowServiceClient)(nil).Block), varargs...)
+}
+
+// CancelFollow mocks base method.
+func (m *MockFollowServiceClient) CancelFollow(ctx context.Context, in *followv1.CancelFollowRequest, opts ...grpc.CallOption) (*followv1.CancelFollowResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CancelFollow", varargs...)
+	ret0, _ := ret[0].(*followv1.CancelFollowResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelFollow indicates an expected call of CancelFollow.
+func (mr *MockFollowServiceClientMockRecorder) CancelFollow(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelFollow", reflect.TypeOf((*MockFollowServiceClient)(nil).CancelFollow), varargs...)
+}
+
+// CreateFollowGroup mocks base method.
+func (m *MockFollowServiceClient) CreateFollowGroup(ctx context.Context, in *followv1.CreateFollowGroupRequest, opts ...grpc.CallOption) (*followv1.CreateFollowGroupResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateFollowGroup", varargs...)
+	ret0, _ := ret[0].(*followv1.CreateFollowGroupResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFollowGroup indicates an expected call of CreateFollowGroup.
+func (mr *MockFollowServiceClientMockRecorder) CreateFollowGroup(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFollowGroup", reflect.TypeOf((*MockFollowServiceClient)(nil).CreateFollowGroup), varargs...)
+}
+
+// DeleteFollowGroup mocks base method.
+func (m *MockFollowServiceClient) DeleteFollowGroup(ctx context.Context, in *followv1.DeleteFollowGroupRequest, opts ...grpc.CallOption) (*followv1.DeleteFollowGroupResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteFollowGroup", varargs...)
+	ret0, _ := ret[0].(*followv1.DeleteFollowGroupResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFollowGroup indicates an expected call of DeleteFollowGroup.
+func (mr *MockFollowServiceClientMockRecorder) DeleteFollowGroup(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFollowGroup", reflect.TypeOf((*MockFollowServiceClient)(nil).DeleteFollowGroup), varargs...)
+}
+
+// Follow mocks base method.
+func (m *MockFollowServiceClient) Follow(ctx context.Context, in *followv1.FollowRequest, opts ...grpc.CallOption) (*followv1.FollowResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Follow", varargs...)
+	ret0, _ := ret[0].(*followv1.FollowResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Follow indicates an expected call of Follow.
+func (mr *MockFollowServiceClientMockRecorder) Follow(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Follow", reflect.TypeOf((*MockFollowServiceClient)(nil).Follow), varargs...)
+}
+
+// FollowInfo mocks base method.
+func (m *MockFollowServiceClient) FollowInfo(ctx context.Context, in *followv1.FollowInfoRequest, opts ...grpc.CallOption) (*followv1.FollowInfoResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FollowInfo", varargs...)
+	ret0, _ := ret[0].(*followv1.FollowInfoResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FollowInfo indicates an expected call of FollowInfo.
+func (mr *MockFollowServiceClientMockRecorder) FollowInfo(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FollowInfo", reflect.TypeOf((*MockFollowServiceClient)(nil).FollowInfo), varargs...)
+}
+
+// GetFollowStatics mocks base method.
+func (m *MockFollowServiceClient) GetFollowStatics(ctx context.Context, in *followv1.GetFollowStaticsRequest, opts ...grpc.CallOption) (*followv1.GetFollowStaticsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetFollowStatics", varargs...)
+	ret0, _ := ret[0].(*followv1.GetFollowStaticsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFollowStatics indicates an expected call of GetFollowStatics.
+func (mr *MockFollowServiceClientMockRecorder) GetFollowStatics(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollowStatics", reflect.TypeOf((*MockFollowServiceClient)(nil).GetFollowStatics), varargs...)
+}
+
+// GetFollowee mocks base method.
+func (m *MockFollowServiceClient) GetFollowee(ctx context.Context, in *followv1.GetFolloweeRequest, opts ...grpc.CallOption) (*followv1.GetFolloweeResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetFollowee", varargs...)
+	ret0, _ := ret[0].(*followv1.GetFolloweeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFollowee indicates an expected call of GetFollowee.
+func (mr *MockFollowServiceClientMockRecorder) GetFollowee(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollowee", reflect.TypeOf((*MockFollowServiceClient)(nil).GetFollowee), varargs...)
+}
+
+// GetFolloweeByGroup mocks base method.
+func (m *MockFollowServiceClient) GetFolloweeByGroup(ctx context.Context, in *followv1.GetFolloweeByGroupRequest, opts ...grpc.CallOption) (*followv1.GetFolloweeByGroupResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetFolloweeByGroup", varargs...)
+	ret0, _ := ret[0].(*followv1.GetFolloweeByGroupResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFolloweeByGroup indicates an expected call of GetFolloweeByGroup.
+func (mr *MockFollowServiceClientMockRecorder) GetFolloweeByGroup(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFolloweeByGroup", reflect.TypeOf((*MockFollowServiceClient)(nil).GetFolloweeByGroup), varargs...)
+}
+
+// GetFollower mocks base method.
+func (m *MockFollowServiceClient) GetFollower(ctx context.Context, in *followv1.GetFollowerRequest, opts ...grpc.CallOption) (*followv1.GetFollowerResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetFollower", varargs...)
+	ret0, _ := ret[0].(*followv1.GetFollowerResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFollower indicates an expected call of GetFollower.
+func (mr *MockFollowServiceClientMockRecorder) GetFollower(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollower", reflect.TypeOf((*MockFollowServiceClient)(nil).GetFollower), varargs...)
+}
+
+// GetMutualFollow mocks base method.
+func (m *MockFollowServiceClient) GetMutualFollow(ctx context.Context, in *followv1.GetMutualFollowRequest, opts ...grpc.CallOption) (*followv1.GetMutualFollowResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetMutualFollow", varargs...)
+	ret0, _ := ret[0].(*followv1.GetMutualFollowResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMutualFollow indicates an expected call of GetMutualFollow.
+func (mr *MockFollowServiceClientMockRecorder) GetMutualFollow(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMutualFollow", reflect.TypeOf((*MockFollowServiceClient)(nil).GetMutualFollow), varargs...)
+}
+
+// GetSpecialFollower mocks base method.
+func (m *MockFollowServiceClient) GetSpecialFollower(ctx context.Context, in *followv1.GetSpecialFollowerRequest, opts ...grpc.CallOption) (*followv1.GetSpecialFollowerResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetSpecialFollower", varargs...)
+	ret0, _ := ret[0].(*followv1.GetSpecialFollowerResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSpecialFollower indicates an expected call of GetSpecialFollower.
+func (mr *MockFollowServiceClientMockRecorder) GetSpecialFollower(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSpecialFollower", reflect.TypeOf((*MockFollowServiceClient)(nil).GetSpecialFollower), varargs...)
+}
+
+// IsBlocked mocks base method.
+func (m *MockFollowServiceClient) IsBlocked(ctx context.Context, in *followv1.IsBlockedRequest, opts ...grpc.CallOption) (*followv1.IsBlockedResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "IsBlocked", varargs...)
+	ret0, _ := ret[0].(*followv1.IsBlockedResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsBlocked indicates an expected call of IsBlocked.
+func (mr *MockFollowServiceClientMockRecorder) IsBlocked(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBlocked", reflect.TypeOf((*MockFollowServiceClient)(nil).IsBlocked), varargs...)
+}
+
+// ListBlocked mocks base method.
+func (m *MockFollowServiceClient) ListBlocked(ctx context.Context, in *followv1.ListBlockedRequest, opts ...grpc.CallOption) (*followv1.ListBlockedResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListBlocked", varargs...)
+	ret0, _ := ret[0].(*followv1.ListBlockedResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBlocked indicates an expected call of ListBlocked.
+func (mr *MockFollowServiceClientMockRecorder) ListBlocked(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBlocked", reflect.TypeOf((*MockFollowServiceClient)(nil).ListBlocked), varargs...)
+}
+
+// ListFollowGroup mocks base method.
+func (m *MockFollowServiceClient) ListFollowGroup(ctx context.Context, in *followv1.ListFollowGroupRequest, opts ...grpc.CallOption) (*followv1.ListFollowGroupResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListFollowGroup", varargs...)
+	ret0, _ := ret[0].(*followv1.ListFollowGroupResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFollowGroup indicates an expected call of ListFollowGroup.
+func (mr *MockFollowServiceClientMockRecorder) ListFollowGroup(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFollowGroup", reflect.TypeOf((*MockFollowServiceClient)(nil).ListFollowGroup), varargs...)
+}
+
+// RenameFollowGroup mocks base method.
+func (m *MockFollowServiceClient) RenameFollowGroup(ctx context.Context, in *followv1.RenameFollowGroupRequest, opts ...grpc.CallOption) (*followv1.RenameFollowGroupResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RenameFollowGroup", varargs...)
+	ret0, _ := ret[0].(*followv1.RenameFollowGroupResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameFollowGroup indicates an expected call of RenameFollowGroup.
+func (mr *MockFollowServiceClientMockRecorder) RenameFollowGroup(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameFollowGroup", reflect.TypeOf((*MockFollowServiceClient)(nil).RenameFollowGroup), varargs...)
+}
+
+// Unblock mocks base method.
+func (m *MockFollowServiceClient) Unblock(ctx context.Context, in *followv1.UnblockRequest, opts ...grpc.CallOption) (*followv1.UnblockResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Unblock", varargs...)
+	ret0, _ := ret[0].(*followv1.UnblockResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unblock indicates an expected call of Unblock.
+func (mr *MockFollowServiceClientMockRecorder) Unblock(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unblock", reflect.TypeOf((*MockFollowServiceClient)(nil).Unblock), varargs...)
+}
+
+// UpdateFollow mocks base method.
+func (m *MockFollowServiceClient) UpdateFollow(ctx context.Context, in *followv1.UpdateFollowRequest, opts ...grpc.CallOption) (*followv1.UpdateFollowResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateFollow", varargs...)
+	ret0, _ := ret[0].(*followv1.UpdateFollowResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateFollow indicates an expected call of UpdateFollow.
+func (mr *MockFollowServiceClientMockRecorder) UpdateFollow(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFollow", reflect.TypeOf((*MockFollowServiceClient)(nil).UpdateFollow), varargs...)
+}
+
+// MockFollowServiceServer is a mock of FollowServiceServer interface.
+type MockFollowServiceServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockFollowServiceServerMockRecorder
+}
+
+// MockFollowServiceServerMockRecorder is the mock recorder for MockFollowServiceServer.
+type MockFollowServiceServerMockRecorder struct {
+	mock *MockFollowServiceServer
+}
+
+// NewMockFollowServiceServer creates a new mock instance.
+func NewMockFollowServiceServer(ctrl *gomock.Controller) *MockFollowServiceServer {
+	mock := &MockFollowServiceServer{ctrl: ctrl}
+	mock.recorder = &MockFollowServiceServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFollowServiceServer) EXPECT() *MockFollowServiceServerMockRecorder {
+	return m.recorder
+}
+
+// BatchFollowStatus mocks base method.
+func (m *MockFollowServiceServer) BatchFollowStatus(arg0 context.Context, arg1 *followv1.BatchFollowStatusRequest) (*followv1.BatchFollowStatusResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchFollowStatus", arg0, arg1)
+	ret0, _ := ret[0].(*followv1.BatchFollowStatusResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchFollowStatus indicates an expected call of BatchFollowStatus.
+func (mr *MockFollowServiceServerMockRecorder) BatchFollowStatus(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchFollowStatus", reflect.TypeOf((*MockFollowServiceServer)(nil).BatchFollowStatus), arg0, arg1)
+}
+
+// Block mocks base method.
+func (m *MockFollowServiceServer) Block(arg0 context.Context, arg1 *followv1.BlockRequest) (*followv1.BlockResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Block", arg0, arg1)
+	ret0, _ := ret[0].(*followv1.BlockResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Block indicates an expected call of Block.
+func (mr *MockFollowServiceServerMockRecorder) Block(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Block", reflect.TypeOf((*MockFollowServiceServer)(nil).Block), arg0, arg1)
+}
+
+// CancelFollow mocks base method.
+func (m *MockFollowServiceServer) CancelFollow(arg0 context.Context, arg1 *followv1.CancelFollowRequest) (*followv1.CancelFollowResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelFollow", arg0, arg1)
+	ret0, _ := ret[0].(*followv1.CancelFollowResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelFollow indicates an expected call of CancelFollow.
+func (mr *MockFollowServiceServerMockRecorder) CancelFollow(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelFollow", reflect.TypeOf((*MockFollowServiceServer)(nil).CancelFollow), arg0, arg1)
+}
+
+// CreateFollowGroup mocks base method.
+func (m *MockFollowServiceServer) CreateFollowGroup(arg0 context.Context, arg1 *followv1.CreateFollowGroupRequest) (*followv1.CreateFollowGroupResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFollowGroup", arg0, arg1)
+	ret0, _ := ret[0].(*followv1.CreateFollowGroupResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFollowGroup indicates an expected call of CreateFollowGroup.
+func (mr *MockFollowServiceServerMockRecorder) CreateFollowGroup(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFollowGroup", reflect.TypeOf((*MockFollowServiceServer)(nil).CreateFollowGroup), arg0, arg1)
+}
+
+// DeleteFollowGroup mocks base method.
+func (m *MockFollowServiceServer) DeleteFollowGroup(arg0 context.Context, arg1 *followv1.DeleteFollowGroupRequest) (*followv1.DeleteFollowGroupResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFollowGroup", arg0, arg1)
+	ret0, _ := ret[0].(*followv1.DeleteFollowGroupResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFollowGroup indicates an expected call of DeleteFollowGroup.
+func (mr *MockFollowServiceServerMockRecorder) DeleteFollowGroup(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFollowGroup", reflect.TypeOf((*MockFollowServiceServer)(nil).DeleteFollowGroup), arg0, arg1)
+}
+
+// Follow mocks base method.
+func (m *MockFollowServiceServer) Follow(arg0 context.Context, arg1 *followv1.FollowRequest) (*followv1.FollowResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Follow", arg0, arg1)
+	ret0, _ := ret[0].(*followv1.FollowResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Follow indicates an expected call of Follow.
+func (mr *MockFollowServiceServerMockRecorder) Follow(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Follow", reflect.TypeOf((*MockFollowServiceServer)(nil).Follow), arg0, arg1)
+}
+
+// FollowInfo mocks base method.
+func (m *MockFollowServiceServer) FollowInfo(arg0 context.Context, arg1 *followv1.FollowInfoRequest) (*followv1.FollowInfoResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FollowInfo", arg0, arg1)
+	ret0, _ := ret[0].(*followv1.FollowInfoResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FollowInfo indicates an expected call of FollowInfo.
+func (mr *MockFollowServiceServerMockRecorder) FollowInfo(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FollowInfo", reflect.TypeOf((*MockFollowServiceServer)(nil).FollowInfo), arg0, arg1)
+}
+
+// GetFollowStatics mocks base method.
+func (m *MockFollowServiceServer) GetFollowStatics(arg0 context.Context, arg1 *followv1.GetFollowStaticsRequest) (*followv1.GetFollowStaticsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFollowStatics", arg0, arg1)
+	ret0, _ := ret[0].(*followv1.GetFollowStaticsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFollowStatics indicates an expected call of GetFollowStatics.
+func (mr *MockFollowServiceServerMockRecorder) GetFollowStatics(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollowStatics", reflect.TypeOf((*MockFollowServiceServer)(nil).GetFollowStatics), arg0, arg1)
+}
+
+// GetFollowee mocks base method.
+func (m *MockFollowServiceServer) GetFollowee(arg0 context.Context, arg1 *followv1.GetFolloweeRequest) (*followv1.GetFolloweeResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFollowee", arg0, arg1)
+	ret0, _ := ret[0].(*followv1.GetFolloweeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFollowee indicates an expected call of GetFollowee.
+func (mr *MockFollowServiceServerMockRecorder) GetFollowee(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollowee", reflect.TypeOf((*MockFollowServiceServer)(nil).GetFollowee), arg0, arg1)
+}
+
+// GetFolloweeByGroup mocks base method.
+func (m *MockFollowServiceServer) GetFolloweeByGroup(arg0 context.Context, arg1 *followv1.GetFolloweeByGroupRequest) (*followv1.GetFolloweeByGroupResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFolloweeByGroup", arg0, arg1)
+	ret0, _ := ret[0].(*followv1.GetFolloweeByGroupResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFolloweeByGroup indicates an expected call of GetFolloweeByGroup.
+func (mr *MockFollowServiceServerMockRecorder) GetFolloweeByGroup(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFolloweeByGroup", reflect.TypeOf((*MockFollowServiceServer)(nil).GetFolloweeByGroup), arg0, arg1)
+}
+
+// GetFollower mocks base method.
+func (m *MockFollowServiceServer) GetFollower(arg0 context.Context, arg1 *followv1.GetFollowerRequest) (*followv1.GetFollowerResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFollower", arg0, arg1)
+	ret0, _ := ret[0].(*followv1.GetFollowerResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFollower indicates an expected call of GetFollower.
+func (mr *MockFollowServiceServerMockRecorder) GetFollower(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollower", reflect.TypeOf((*MockFollowServiceServer)(nil).GetFollower), arg0, arg1)
+}
+
+// GetMutualFollow mocks base method.
+func (m *MockFollowServiceServer) GetMutualFollow(arg0 context.Context, arg1 *followv1.GetMutualFollowRequest) (*followv1.GetMutualFollowResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMutualFollow", arg0, arg1)
+	ret0, _ := ret[0].(*followv1.GetMutualFollowResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMutualFollow indicates an expected call of GetMutualFollow.
+func (mr *MockFollowServiceServerMockRecorder) GetMutualFollow(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMutualFollow", reflect.TypeOf((*MockFollowServiceServer)(nil).GetMutualFollow), arg0, arg1)
+}
+
+// GetSpecialFollower mocks base method.
+func (m *MockFollowServiceServer) GetSpecialFollower(arg0 context.Context, arg1 *followv1.GetSpecialFollowerRequest) (*followv1.GetSpecialFollowerResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSpecialFollower", arg0, arg1)
+	ret0, _ := ret[0].(*followv1.GetSpecialFollowerResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSpecialFollower indicates an expected call of GetSpecialFollower.
+func (mr *MockFollowServiceServerMockRecorder) GetSpecialFollower(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSpecialFollower", reflect.TypeOf((*MockFollowServiceServer)(nil).GetSpecialFollower), arg0, arg1)
+}
+
+// IsBlocked mocks base method.
+func (m *MockFollowServiceServer) IsBlocked(arg0 context.Context, arg1 *followv1.IsBlockedRequest) (*followv1.IsBlockedResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBlocked", arg0, arg1)
+	ret0, _ := ret[0].(*followv1.IsBlockedResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsBlocked indicates an expected call of IsBlocked.
+func (mr *MockFollowServiceServerMockRecorder) IsBlocked(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBlocked", reflect.TypeOf((*MockFollowServiceServer)(nil).IsBlocked), arg0, arg1)
+}
+
+// ListBlocked mocks base method.
+func (m *MockFollowServiceServer) ListBlocked(arg0 context.Context, arg1 *followv1.ListBlockedRequest) (*followv1.ListBlockedResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBlocked", arg0, arg1)
+	ret0, _ := ret[0].(*followv1.ListBlockedResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBlocked indicates an expected call of ListBlocked.
+func (mr *MockFollowServiceServerMockRecorder) ListBlocked(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBlocked", reflect.TypeOf((*MockFollowServiceServer)(nil).ListBlocked), arg0, arg1)
+}
+
+// ListFollowGroup mocks base method.
+func (m *MockFollowServiceServer) ListFollowGroup(arg0 context.Context, arg1 *followv1.ListFollowGroupRequest) (*followv1.ListFollowGroupResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFollowGroup", arg0, arg1)
+	ret0, _ := ret[0].(*followv1.ListFollowGroupResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFollowGroup indicates an expected call of ListFollowGroup.
+func (mr *MockFollowServiceServerMockRecorder) ListFollowGroup(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFollowGroup", reflect.TypeOf((*MockFollowServiceServer)(nil).ListFollowGroup), arg0, arg1)
+}
+
+// RenameFollowGroup mocks base method.
+func (m *MockFollowServiceServer) RenameFollowGroup(arg0 context.Context, arg1 *followv1.RenameFollowGroupRequest) (*followv1.RenameFollowGroupResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameFollowGroup", arg0, arg1)
+	ret0, _ := ret[0].(*followv1.RenameFollowGroupResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameFollowGroup indicates an expected call of RenameFollowGroup.
+func (mr *MockFollowServiceServerMockRecorder) RenameFollowGroup(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameFollowGroup", reflect.TypeOf((*MockFollowServiceServer)(nil).RenameFollowGroup), arg0, arg1)
+}
+
+// Unblock mocks base method.
+func (m *MockFollowServiceServer) Unblock(arg0 context.Context, arg1 *followv1.UnblockRequest) (*followv1.UnblockResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unblock", arg0, arg1)
+	ret0, _ := ret[0].(*followv1.UnblockResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unblock indicates an expected call of Unblock.
+func (mr *MockFollowServiceServerMockRecorder) Unblock(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unblock", reflect.TypeOf((*MockFollowServiceServer)(nil).Unblock), arg0, arg1)
+}
+
+// UpdateFollow mocks base method.
+func (m *MockFollowServiceServer) UpdateFollow(arg0 context.Context, arg1 *followv1.UpdateFollowRequest) (*followv1.UpdateFollowResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFollow", arg0, arg1)
+	ret0, _ := ret[0].(*followv1.UpdateFollowResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateFollow indicates an expected call of UpdateFollow.
+func (mr *MockFollowServiceServerMockRecorder) UpdateFollow(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFollow", reflect.TypeOf((*MockFollowServiceServer)(nil).UpdateFollow), arg0, arg1)
+}
+
+// mustEmbedUnimplementedFollowServiceServer mocks base method.
+func (m *MockFollowServiceServer) mustEmbedUnimplementedFollowServiceServer() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "mustEmbedUnimplementedFollowServiceServer")
+}
+
+// mustEmbedUnimplementedFollowServiceServer indicates an expected call of mustEmbedUnimplementedFollowServiceServer.
+func (mr *MockFollowServiceServerMockRecorder) mustEmbedUnimplementedFollowServiceServer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "mustEmbedUnimplementedFollowServiceServer", reflect.TypeOf((*MockFollowServiceServer)(nil).mustEmbedUnimplementedFollowServiceServer))
+}
+
+// MockUnsafeFollowServiceServer is a mock of UnsafeFollowServiceServer interface.
+type MockUnsafeFollowServiceServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockUnsafeFollowServiceServerMockRecorder
+}
+
+// MockUnsafeFollowServiceServerMockRecorder is the mock recorder for MockUnsafeFollowServiceServer.
+type MockUnsafeFollowServiceServerMockRecorder struct {
+	mock *MockUnsafeFollowServiceServer
+}
+
+// NewMockUnsafeFollowServiceServer creates a new mock instance.
+func NewMockUnsafeFollowServiceServer(ctrl *gomock.Controller) *MockUnsafeFollowServiceServer {
+	mock := &MockUnsafeFollowServiceServer{ctrl: ctrl}
+	mock.recorder = &MockUnsafeFollowServiceServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUnsafeFollowServiceServer) EXPECT() *MockUnsafeFollowServiceServerMockRecorder {
+	return m.recorder
+}
+
+// mustEmbedUnimplementedFollowServiceServer mocks base method.
+func (m *MockUnsafeFollowServiceServer) mustEmbedUnimplementedFollowServiceServer() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "mustEmbedUnimplementedFollowServiceServer")
+}
+
+// mustEmbedUnimplementedFollowServiceServer indicates an expected call of mustEmbedUnimplementedFollowServiceServer.
+func (mr *MockUnsafeFollowServiceServerMockRecorder) mustEmbedUnimplementedFollowServiceServer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "mustEmbedUnimplementedFollowServiceServer", reflect.TypeOf((*MockUnsafeFollowServiceServer)(nil).mustEmbedUnimplementedFollowServiceServer))
+}
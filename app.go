@@ -5,10 +5,15 @@ import (
 	"github.com/robfig/cron/v3"
 
 	"webooktrial/internal/events"
+	"webooktrial/internal/events/article"
+	"webooktrial/internal/job"
 )
 
 type App struct {
 	web       *gin.Engine
 	consumers []events.Consumer
 	cron      *cron.Cron
+	scheduler *job.Scheduler
+	// readProducer 退出的时候要把缓冲的阅读事件发出去
+	readProducer *article.BatchReadEventProducer
 }
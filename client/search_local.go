@@ -0,0 +1,23 @@
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	searchv1 "webooktrial/api/proto/gen/search/v1"
+	grpc2 "webooktrial/search/grpc"
+)
+
+// SearchServiceAdapter 在本地直接调用 search 的实现，不走网络，测试的时候用
+type SearchServiceAdapter struct {
+	server *grpc2.SearchServiceServer
+}
+
+func NewSearchServiceAdapter(server *grpc2.SearchServiceServer) *SearchServiceAdapter {
+	return &SearchServiceAdapter{server: server}
+}
+
+func (s *SearchServiceAdapter) SearchArticle(ctx context.Context, in *searchv1.SearchArticleRequest, opts ...grpc.CallOption) (*searchv1.SearchArticleResponse, error) {
+	return s.server.SearchArticle(ctx, in)
+}
@@ -10,6 +10,8 @@ type Comment struct {
 	Biz string `json:"biz"`
 	// 评论对象ID
 	BizID int64 `json:"bizId"`
+	// 评论对象的作者，服务端自己查询，创建评论的时候用来判断是不是被拉黑了
+	BizOwner int64 `json:"-"`
 	// 根评论
 	RootComment *Comment `json:"rootComment"`
 	// 父级评论
@@ -17,10 +19,69 @@ type Comment struct {
 	// 评论内容
 	Content  string
 	Children []Comment `json:"children"`
+	// 点赞数
+	LikeCnt int64 `json:"likeCnt"`
+	// 回复数，只有根评论有，整棵树的回复都算
+	ReplyCnt int64 `json:"replyCnt"`
+	// 热度，按照热度翻页的时候要用
+	Hot float64 `json:"hot"`
+	// 看评论的人有没有点赞
+	Liked bool `json:"liked"`
+	// 审核状态，列表里面只会有 CommentStatusApproved 和 CommentStatusFolded
+	Status CommentStatus `json:"status"`
+	// 删除了的评论还会留在列表里面，保留回复的上下文，
+	// 但是内容换成 DeletedContent，评论者也不会返回
+	Deleted bool `json:"deleted"`
+	// 审核分数和命中的敏感词，只给管理后台看
+	ModScore float64  `json:"-"`
+	ModHits  []string `json:"-"`
 	Ctime    time.Time
 	Utime    time.Time
 }
 
+// DeletedContent 删除了的评论显示的内容
+const DeletedContent = "该评论已删除"
+
+type CommentStatus uint8
+
+const (
+	CommentStatusUnknown CommentStatus = iota
+	// CommentStatusPending 等待人工审核，只有管理后台能看到
+	CommentStatusPending
+	CommentStatusApproved
+	CommentStatusRejected
+	// CommentStatusFolded 审核通过了但是质量不高，客户端默认折叠起来
+	CommentStatusFolded
+)
+
+// Visible 普通用户能不能看到
+func (s CommentStatus) Visible() bool {
+	return s == CommentStatusApproved || s == CommentStatusFolded
+}
+
+type CommentSort uint8
+
+const (
+	// CommentSortLatest 按照 ID 倒序，也就是最新的在前面
+	CommentSortLatest CommentSort = iota
+	// CommentSortHot 按照热度倒序
+	CommentSortHot
+)
+
+// CommentListQuery 一级评论列表的查询条件
+type CommentListQuery struct {
+	Biz   string
+	BizID int64
+	Sort  CommentSort
+	// MinID 上一页最后一条的 ID，按照时间排序的时候为 0 表示第一页
+	MinID int64
+	// MaxHot 上一页最后一条的热度，按照热度排序的时候和 MinID 一起使用，都为 0 表示第一页
+	MaxHot float64
+	Limit  int64
+	// Viewer 看评论的人，用来判断有没有点赞，0 表示不判断
+	Viewer int64
+}
+
 type User struct {
 	ID   int64  `json:"id"`
 	Name string `json:"name"`
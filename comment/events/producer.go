@@ -0,0 +1,52 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/IBM/sarama"
+)
+
+const TopicCommentCreated = "comment_created"
+
+// CommentCreatedEvent 评论能被看到了，包括直接通过的和人工审核通过的。
+// 人工审核通过的不知道评论对象的作者，BizOwner 为 0
+type CommentCreatedEvent struct {
+	Id       int64
+	Biz      string
+	BizId    int64
+	BizOwner int64
+	Uid      int64
+	// ParentId 和 ParentUid 是被回复的评论和它的评论者，一级评论为 0
+	ParentId  int64
+	ParentUid int64
+	Content   string
+}
+
+type Producer interface {
+	ProduceCommentCreatedEvent(ctx context.Context, evt CommentCreatedEvent) error
+}
+
+type KafkaProducer struct {
+	producer sarama.SyncProducer
+}
+
+func NewKafkaProducer(pc sarama.SyncProducer) Producer {
+	return &KafkaProducer{
+		producer: pc,
+	}
+}
+
+func (k *KafkaProducer) ProduceCommentCreatedEvent(ctx context.Context, evt CommentCreatedEvent) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, _, err = k.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: TopicCommentCreated,
+		Key:   sarama.StringEncoder(strconv.FormatInt(evt.Id, 10)),
+		Value: sarama.ByteEncoder(data),
+	})
+	return err
+}
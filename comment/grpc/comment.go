@@ -2,9 +2,12 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"math"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	commentv1 "webooktrial/api/proto/gen/comment/v1"
@@ -27,38 +30,75 @@ func NewCommentServiceServer(svc service.CommentService) *CommentServiceServer {
 
 func (c *CommentServiceServer) GetCommentList(ctx context.Context, req *commentv1.CommentListRequest) (*commentv1.CommentListResponse, error) {
 	// 可以在这里判断是否触发了限流或者降级，如果触发，直接返回
-	minID := req.MinId
+	minID := req.GetMinId()
 	// 第一次查询，这边我们认为用户没有传
-	if minID <= 0 {
+	if req.GetSort() == commentv1.CommentSort_COMMENT_SORT_LATEST && minID <= 0 {
 		minID = math.MaxInt64
 	}
 	domainComments, err := c.svc.
-		GetCommentList(ctx,
-			req.GetBiz(),
-			req.GetBizId(),
-			req.GetMinId(),
-			req.GetLimit())
+		GetCommentList(ctx, domain.CommentListQuery{
+			Biz:    req.GetBiz(),
+			BizID:  req.GetBizId(),
+			Sort:   domain.CommentSort(req.GetSort()),
+			MinID:  minID,
+			MaxHot: req.GetMaxHot(),
+			Limit:  req.GetLimit(),
+			Viewer: req.GetUid(),
+		})
 	if err != nil {
 		return nil, err
 	}
+	// 预览的子评论放在一级评论的 children 里面，
+	// 子评论单独转换，这样它们的 root_comment 不会指回一级评论
+	comments := c.toDTO(domainComments)
+	for i, cm := range domainComments {
+		comments[i].Children = c.toDTO(cm.Children)
+	}
 	return &commentv1.CommentListResponse{
-		Comments: c.toDTO(domainComments),
+		Comments: comments,
 	}, nil
 }
 
 func (c *CommentServiceServer) DeleteComment(ctx context.Context, req *commentv1.DeleteCommentRequest) (*commentv1.DeleteCommentResponse, error) {
-	err := c.svc.DeleteComment(ctx, req.Id)
+	err := c.svc.DeleteComment(ctx, req.GetUid(), req.GetId())
+	switch {
+	case errors.Is(err, service.ErrPermissionDenied):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrCommentNotFound):
+		return nil, status.Error(codes.NotFound, "评论不存在")
+	}
 	return &commentv1.DeleteCommentResponse{}, err
 }
 
+func (c *CommentServiceServer) GetCommentCount(ctx context.Context, req *commentv1.GetCommentCountRequest) (*commentv1.GetCommentCountResponse, error) {
+	cnts, err := c.svc.GetCommentCount(ctx, req.GetBiz(), req.GetBizIds())
+	if err != nil {
+		return nil, err
+	}
+	return &commentv1.GetCommentCountResponse{
+		Counts: cnts,
+	}, nil
+}
+
 func (c *CommentServiceServer) CreateComment(ctx context.Context, req *commentv1.CreateCommentRequest) (*commentv1.CreateCommentResponse, error) {
 	// 可以在这里判断是否触发了限流或者降级，如果触发，则将消息丢进kafka后返回
-	err := c.svc.CreateComment(ctx, convertToDomain(req.GetComment()))
-	return &commentv1.CreateCommentResponse{}, err
+	comment := convertToDomain(req.GetComment())
+	st, err := c.svc.CreateComment(ctx, comment)
+	switch {
+	case errors.Is(err, service.ErrBlocked):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrContentRejected):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case err != nil:
+		return nil, err
+	}
+	return &commentv1.CreateCommentResponse{
+		Status: commentv1.CommentStatus(st),
+	}, nil
 }
 
 func (c *CommentServiceServer) GetMoreReplies(ctx context.Context, req *commentv1.GetMoreRepliesRequest) (*commentv1.GetMoreRepliesResponse, error) {
-	cs, err := c.svc.GetMoreReplies(ctx, req.Rid, req.MaxId, req.Limit)
+	cs, err := c.svc.GetMoreReplies(ctx, req.GetUid(), req.Rid, req.MaxId, req.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -67,17 +107,75 @@ func (c *CommentServiceServer) GetMoreReplies(ctx context.Context, req *commentv
 	}, nil
 }
 
+func (c *CommentServiceServer) LikeComment(ctx context.Context, req *commentv1.LikeCommentRequest) (*commentv1.LikeCommentResponse, error) {
+	err := c.svc.LikeComment(ctx, req.GetUid(), req.GetCid())
+	if errors.Is(err, service.ErrCommentNotFound) {
+		return nil, status.Error(codes.NotFound, "评论不存在")
+	}
+	return &commentv1.LikeCommentResponse{}, err
+}
+
+func (c *CommentServiceServer) CancelLikeComment(ctx context.Context, req *commentv1.CancelLikeCommentRequest) (*commentv1.CancelLikeCommentResponse, error) {
+	err := c.svc.CancelLikeComment(ctx, req.GetUid(), req.GetCid())
+	return &commentv1.CancelLikeCommentResponse{}, err
+}
+
+func (c *CommentServiceServer) SubscribeComments(req *commentv1.SubscribeCommentsRequest,
+	stream commentv1.CommentService_SubscribeCommentsServer) error {
+	ch := c.svc.SubscribeComments(stream.Context(), req.GetBiz(), req.GetBizId())
+	// 客户端断开之后 channel 会被关闭
+	for cm := range ch {
+		err := stream.Send(c.toDTO([]domain.Comment{cm})[0])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *CommentServiceServer) ListPendingComments(ctx context.Context, req *commentv1.ListPendingCommentsRequest) (*commentv1.ListPendingCommentsResponse, error) {
+	cs, err := c.svc.ListPendingComments(ctx, req.GetMinId(), req.GetLimit())
+	if err != nil {
+		return nil, err
+	}
+	res := c.toDTO(cs)
+	for i, cm := range cs {
+		res[i].ModScore = cm.ModScore
+		res[i].ModHits = cm.ModHits
+	}
+	return &commentv1.ListPendingCommentsResponse{
+		Comments: res,
+	}, nil
+}
+
+func (c *CommentServiceServer) ReviewComment(ctx context.Context, req *commentv1.ReviewCommentRequest) (*commentv1.ReviewCommentResponse, error) {
+	err := c.svc.ReviewComment(ctx, req.GetId(), domain.CommentStatus(req.GetStatus()), req.GetReviewer())
+	switch {
+	case errors.Is(err, service.ErrInvalidReviewStatus):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrCommentNotFound):
+		return nil, status.Error(codes.NotFound, "评论不存在")
+	}
+	return &commentv1.ReviewCommentResponse{}, err
+}
+
 func (c *CommentServiceServer) toDTO(domainComments []domain.Comment) []*commentv1.Comment {
 	rpcComments := make([]*commentv1.Comment, 0, len(domainComments))
 	for _, domainComment := range domainComments {
 		rpcComment := &commentv1.Comment{
-			Id:      domainComment.Id,
-			Uid:     domainComment.Commentator.ID,
-			Biz:     domainComment.Biz,
-			Bizid:   domainComment.BizID,
-			Content: domainComment.Content,
-			Ctime:   timestamppb.New(domainComment.Ctime),
-			Utime:   timestamppb.New(domainComment.Utime),
+			Id:       domainComment.Id,
+			Uid:      domainComment.Commentator.ID,
+			Biz:      domainComment.Biz,
+			Bizid:    domainComment.BizID,
+			Content:  domainComment.Content,
+			Ctime:    timestamppb.New(domainComment.Ctime),
+			Utime:    timestamppb.New(domainComment.Utime),
+			LikeCnt:  domainComment.LikeCnt,
+			ReplyCnt: domainComment.ReplyCnt,
+			Hot:      domainComment.Hot,
+			Liked:    domainComment.Liked,
+			Status:   commentv1.CommentStatus(domainComment.Status),
+			Deleted:  domainComment.Deleted,
 		}
 		if domainComment.RootComment != nil {
 			rpcComment.RootComment = &commentv1.Comment{
@@ -0,0 +1,187 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	commentv1 "webooktrial/api/proto/gen/comment/v1"
+	"webooktrial/comment/domain"
+	repomocks "webooktrial/comment/repository/mocks"
+	"webooktrial/comment/service"
+	"webooktrial/pkg/logger"
+)
+
+// fakeResolver 所有资源的作者都是 owner
+type fakeResolver struct {
+	owner int64
+}
+
+func (f fakeResolver) Owner(ctx context.Context, biz string, bizId int64) (int64, error) {
+	return f.owner, nil
+}
+
+// TestCommentServiceServer_DeleteComment 调用方传过来的 biz_owner 不能用来判断权限
+func TestCommentServiceServer_DeleteComment(t *testing.T) {
+	cm := domain.Comment{
+		Id:          100,
+		Commentator: domain.User{ID: 2},
+		Biz:         "article",
+		BizID:       11,
+	}
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) *repomocks.MockCommentRepository
+		req      *commentv1.DeleteCommentRequest
+		wantCode codes.Code
+	}{
+		{
+			name: "冒充文章作者",
+			mock: func(ctrl *gomock.Controller) *repomocks.MockCommentRepository {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(100)).Return(cm, nil)
+				return repo
+			},
+			req:      &commentv1.DeleteCommentRequest{Id: 100, Uid: 3, BizOwner: 3},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name: "真的文章作者不传 biz_owner",
+			mock: func(ctrl *gomock.Controller) *repomocks.MockCommentRepository {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(100)).Return(cm, nil)
+				repo.EXPECT().DeleteComment(gomock.Any(), cm).Return(nil)
+				return repo
+			},
+			req:      &commentv1.DeleteCommentRequest{Id: 100, Uid: 1},
+			wantCode: codes.OK,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := service.NewCommentService(tc.mock(ctrl), nil, fakeResolver{owner: 1},
+				nil, nil, nil, logger.NewNopLogger())
+			_, err := NewCommentServiceServer(svc).DeleteComment(context.Background(), tc.req)
+			assert.Equal(t, tc.wantCode, status.Code(err))
+		})
+	}
+}
+
+// fakeListService 只实现 GetCommentList
+type fakeListService struct {
+	service.CommentService
+	comments []domain.Comment
+}
+
+func (f fakeListService) GetCommentList(ctx context.Context, q domain.CommentListQuery) ([]domain.Comment, error) {
+	return f.comments, nil
+}
+
+// TestCommentServiceServer_GetCommentList 预览的回复放在一级评论的 children 里面
+func TestCommentServiceServer_GetCommentList(t *testing.T) {
+	root := &domain.Comment{Id: 1}
+	svc := fakeListService{comments: []domain.Comment{
+		{
+			Id: 1,
+			Children: []domain.Comment{
+				{Id: 3, RootComment: root, ParentComment: root},
+				{Id: 4, RootComment: root, ParentComment: &domain.Comment{Id: 3}},
+			},
+		},
+		{Id: 2},
+	}}
+	resp, err := NewCommentServiceServer(svc).GetCommentList(context.Background(),
+		&commentv1.CommentListRequest{Biz: "article", BizId: 11, Limit: 10})
+	assert.NoError(t, err)
+	comments := resp.GetComments()
+	assert.Len(t, comments, 2)
+	assert.Equal(t, int64(1), comments[0].GetId())
+	assert.Equal(t, int64(2), comments[1].GetId())
+	assert.Empty(t, comments[1].GetChildren())
+
+	children := comments[0].GetChildren()
+	assert.Len(t, children, 2)
+	assert.Equal(t, int64(3), children[0].GetId())
+	// 不会反过来引用一级评论，不然序列化的时候会死循环
+	assert.Empty(t, children[0].GetRootComment().GetChildren())
+	assert.Equal(t, int64(1), children[0].GetRootComment().GetId())
+	assert.Equal(t, int64(3), children[1].GetParentComment().GetId())
+	_, err = proto.Marshal(resp)
+	assert.NoError(t, err)
+}
+
+// fakeSubscribeService 只实现 SubscribeComments，返回的就是 ch
+type fakeSubscribeService struct {
+	service.CommentService
+	ch chan domain.Comment
+}
+
+func (f fakeSubscribeService) SubscribeComments(ctx context.Context, biz string, bizId int64) <-chan domain.Comment {
+	return f.ch
+}
+
+type fakeSubscribeStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*commentv1.Comment
+	err  error
+}
+
+func (f *fakeSubscribeStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeSubscribeStream) Send(c *commentv1.Comment) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, c)
+	return nil
+}
+
+func TestCommentServiceServer_SubscribeComments(t *testing.T) {
+	testCases := []struct {
+		name    string
+		sendErr error
+
+		wantErr  error
+		wantSent []int64
+	}{
+		{
+			name:     "推送到订阅关闭",
+			wantSent: []int64{1, 2},
+		},
+		{
+			name:    "客户端断开，发送失败",
+			sendErr: errors.New("连接断开"),
+			wantErr: errors.New("连接断开"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ch := make(chan domain.Comment, 2)
+			ch <- domain.Comment{Id: 1, Biz: "article", BizID: 11}
+			ch <- domain.Comment{Id: 2, Biz: "article", BizID: 11,
+				RootComment: &domain.Comment{Id: 1}}
+			close(ch)
+			stream := &fakeSubscribeStream{ctx: context.Background(), err: tc.sendErr}
+			err := NewCommentServiceServer(fakeSubscribeService{ch: ch}).
+				SubscribeComments(&commentv1.SubscribeCommentsRequest{Biz: "article", BizId: 11}, stream)
+			assert.Equal(t, tc.wantErr, err)
+			var sent []int64
+			for _, c := range stream.sent {
+				sent = append(sent, c.GetId())
+			}
+			assert.Equal(t, tc.wantSent, sent)
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package ioc
+
+import (
+	"github.com/IBM/sarama"
+	"gorm.io/gorm"
+
+	"webooktrial/pkg/bizowner"
+	"webooktrial/pkg/logger"
+)
+
+// InitBizOwnerResolver 评论对象的作者从文章发表的消息同步到本地
+func InitBizOwnerResolver(db *gorm.DB, client sarama.Client, l logger.LoggerV1) bizowner.Resolver {
+	return bizowner.InitResolver(db, client, "comment_biz_owner", l)
+}
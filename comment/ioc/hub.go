@@ -0,0 +1,19 @@
+package ioc
+
+import (
+	"webooktrial/comment/repository"
+	"webooktrial/comment/service"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/saramax"
+)
+
+func InitCommentHub(repo repository.CommentRepository, l logger.LoggerV1) *service.CommentHub {
+	return service.NewCommentHub(repo, l)
+}
+
+// NewConsumers 新评论的推送也是在消费消息，和 Kafka 的消费者一起启动和关闭
+func NewConsumers(hub *service.CommentHub) []saramax.CloseableConsumer {
+	return []saramax.CloseableConsumer{
+		hub,
+	}
+}
@@ -1,6 +1,8 @@
 package comment
 
 import (
+	"log"
+
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
@@ -8,9 +10,16 @@ import (
 func main() {
 	initViperV2Watch()
 	app := Init()
-	err := app.GRPCServer.Serve()
-	if err != nil {
-		panic(err)
+	for _, c := range app.consumers {
+		err := c.Start()
+		if err != nil {
+			panic(err)
+		}
+	}
+	err := app.server.Serve()
+	log.Println(err)
+	for _, c := range app.consumers {
+		_ = c.Close()
 	}
 }
 
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+
+	"webooktrial/comment/domain"
+)
+
+const newCommentChannel = "comment:new"
+
+// CommentBroadcaster 广播新的评论，每个实例再推给本实例上打开了对应页面的用户。
+// 所有的新评论都走一个 channel，实例自己按照 biz 和 bizId 分发，
+// 这样每个实例只需要一个订阅的连接
+type CommentBroadcaster interface {
+	Publish(ctx context.Context, c domain.Comment) error
+	// Subscribe 收到新评论就回调 fn，一直阻塞到 ctx 被取消
+	Subscribe(ctx context.Context, fn func(c domain.Comment)) error
+}
+
+// RedisCommentBroadcaster 基于 Redis 的 pub/sub，不保证送达，
+// 漏掉的评论用户刷新一下页面就能看到
+type RedisCommentBroadcaster struct {
+	client redis.Cmdable
+}
+
+func NewRedisCommentBroadcaster(client redis.Cmdable) CommentBroadcaster {
+	return &RedisCommentBroadcaster{client: client}
+}
+
+func (r *RedisCommentBroadcaster) Publish(ctx context.Context, c domain.Comment) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return r.client.Publish(ctx, newCommentChannel, data).Err()
+}
+
+func (r *RedisCommentBroadcaster) Subscribe(ctx context.Context, fn func(c domain.Comment)) error {
+	// Cmdable 里面没有 Subscribe，实际上传进来的都是 *redis.Client 或者集群的客户端
+	client, ok := r.client.(redis.UniversalClient)
+	if !ok {
+		return errors.New("redis 客户端不支持订阅")
+	}
+	sub := client.Subscribe(ctx, newCommentChannel)
+	defer sub.Close()
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			var c domain.Comment
+			err := json.Unmarshal([]byte(msg.Payload), &c)
+			if err != nil {
+				continue
+			}
+			fn(c)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"webooktrial/comment/domain"
+)
+
+var (
+	//go:embed lua/incr_cnt.lua
+	luaIncrCnt string
+)
+
+var ErrKeyNotExist = redis.Nil
+
+// CommentCache 缓存每个 biz 的评论数，只算能看到的、没删除的评论。
+// 还缓存热门文章最常看的第一页评论，带上预览的子评论
+//
+//go:generate mockgen -source=./comment.go -package=cachemocks -destination=mocks/comment.mock.go CommentCache
+type CommentCache interface {
+	// IncrCntIfPresent 如果缓存里面有，就加上 delta
+	IncrCntIfPresent(ctx context.Context, biz string, bizId int64, delta int64) error
+	// GetCnts 缓存里面没有的 bizId 不会出现在结果里面
+	GetCnts(ctx context.Context, biz string, bizIds []int64) (map[int64]int64, error)
+	SetCnt(ctx context.Context, biz string, bizId int64, cnt int64) error
+
+	// GetFirstPage 缓存里面没有返回 ErrKeyNotExist
+	GetFirstPage(ctx context.Context, biz string, bizId int64, sort domain.CommentSort) ([]domain.Comment, error)
+	SetFirstPage(ctx context.Context, biz string, bizId int64, sort domain.CommentSort, cs []domain.Comment) error
+	// DelFirstPage 所有排序方式的第一页都删掉
+	DelFirstPage(ctx context.Context, biz string, bizId int64) error
+}
+
+type RedisCommentCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+	// pageExpiration 点赞不会删除第一页的缓存，热度排序靠过期来更新，所以设置得短一点
+	pageExpiration time.Duration
+}
+
+func NewRedisCommentCache(client redis.Cmdable) CommentCache {
+	return &RedisCommentCache{
+		client:         client,
+		expiration:     time.Minute * 15,
+		pageExpiration: time.Minute,
+	}
+}
+
+func (r *RedisCommentCache) IncrCntIfPresent(ctx context.Context, biz string, bizId int64, delta int64) error {
+	return r.client.Eval(ctx, luaIncrCnt, []string{r.cntKey(biz, bizId)}, delta).Err()
+}
+
+func (r *RedisCommentCache) GetCnts(ctx context.Context, biz string, bizIds []int64) (map[int64]int64, error) {
+	res := make(map[int64]int64, len(bizIds))
+	if len(bizIds) == 0 {
+		return res, nil
+	}
+	keys := make([]string, 0, len(bizIds))
+	for _, id := range bizIds {
+		keys = append(keys, r.cntKey(biz, id))
+	}
+	vals, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, val := range vals {
+		str, ok := val.(string)
+		if !ok {
+			// 缓存里面没有
+			continue
+		}
+		cnt, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			continue
+		}
+		res[bizIds[i]] = cnt
+	}
+	return res, nil
+}
+
+func (r *RedisCommentCache) SetCnt(ctx context.Context, biz string, bizId int64, cnt int64) error {
+	return r.client.Set(ctx, r.cntKey(biz, bizId), cnt, r.expiration).Err()
+}
+
+func (r *RedisCommentCache) GetFirstPage(ctx context.Context, biz string, bizId int64,
+	sort domain.CommentSort) ([]domain.Comment, error) {
+	data, err := r.client.Get(ctx, r.pageKey(biz, bizId, sort)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var res []domain.Comment
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
+
+func (r *RedisCommentCache) SetFirstPage(ctx context.Context, biz string, bizId int64,
+	sort domain.CommentSort, cs []domain.Comment) error {
+	data, err := json.Marshal(cs)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.pageKey(biz, bizId, sort), data, r.pageExpiration).Err()
+}
+
+func (r *RedisCommentCache) DelFirstPage(ctx context.Context, biz string, bizId int64) error {
+	return r.client.Del(ctx,
+		r.pageKey(biz, bizId, domain.CommentSortLatest),
+		r.pageKey(biz, bizId, domain.CommentSortHot)).Err()
+}
+
+func (r *RedisCommentCache) pageKey(biz string, bizId int64, sort domain.CommentSort) string {
+	return fmt.Sprintf("comment:first_page:%d:%s:%d", sort, biz, bizId)
+}
+
+func (r *RedisCommentCache) cntKey(biz string, bizId int64) string {
+	return fmt.Sprintf("comment:cnt:%s:%d", biz, bizId)
+}
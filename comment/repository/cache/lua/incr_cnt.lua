@@ -0,0 +1,11 @@
+local key = KEYS[1]
+-- +1 或者 -1
+local delta = tonumber(ARGV[1])
+local exists = redis.call("EXISTS", key)
+if exists == 1 then
+    redis.call("INCRBY", key, delta)
+    return 1
+else
+    -- 缓存里面没有就不管了，下次查询的时候从数据库里面重新数
+    return 0
+end
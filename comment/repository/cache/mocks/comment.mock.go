@@ -0,0 +1,126 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./comment.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./comment.go -package=cachemocks -destination=mocks/comment.mock.go CommentCache
+//
+// Package cachemocks is a generated GoMock package.
+package cachemocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "webooktrial/comment/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCommentCache is a mock of CommentCache interface.
+type MockCommentCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommentCacheMockRecorder
+}
+
+// MockCommentCacheMockRecorder is the mock recorder for MockCommentCache.
+type MockCommentCacheMockRecorder struct {
+	mock *MockCommentCache
+}
+
+// NewMockCommentCache creates a new mock instance.
+func NewMockCommentCache(ctrl *gomock.Controller) *MockCommentCache {
+	mock := &MockCommentCache{ctrl: ctrl}
+	mock.recorder = &MockCommentCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommentCache) EXPECT() *MockCommentCacheMockRecorder {
+	return m.recorder
+}
+
+// DelFirstPage mocks base method.
+func (m *MockCommentCache) DelFirstPage(ctx context.Context, biz string, bizId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelFirstPage", ctx, biz, bizId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelFirstPage indicates an expected call of DelFirstPage.
+func (mr *MockCommentCacheMockRecorder) DelFirstPage(ctx, biz, bizId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelFirstPage", reflect.TypeOf((*MockCommentCache)(nil).DelFirstPage), ctx, biz, bizId)
+}
+
+// GetCnts mocks base method.
+func (m *MockCommentCache) GetCnts(ctx context.Context, biz string, bizIds []int64) (map[int64]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCnts", ctx, biz, bizIds)
+	ret0, _ := ret[0].(map[int64]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCnts indicates an expected call of GetCnts.
+func (mr *MockCommentCacheMockRecorder) GetCnts(ctx, biz, bizIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCnts", reflect.TypeOf((*MockCommentCache)(nil).GetCnts), ctx, biz, bizIds)
+}
+
+// GetFirstPage mocks base method.
+func (m *MockCommentCache) GetFirstPage(ctx context.Context, biz string, bizId int64, sort domain.CommentSort) ([]domain.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFirstPage", ctx, biz, bizId, sort)
+	ret0, _ := ret[0].([]domain.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFirstPage indicates an expected call of GetFirstPage.
+func (mr *MockCommentCacheMockRecorder) GetFirstPage(ctx, biz, bizId, sort any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFirstPage", reflect.TypeOf((*MockCommentCache)(nil).GetFirstPage), ctx, biz, bizId, sort)
+}
+
+// IncrCntIfPresent mocks base method.
+func (m *MockCommentCache) IncrCntIfPresent(ctx context.Context, biz string, bizId, delta int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrCntIfPresent", ctx, biz, bizId, delta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrCntIfPresent indicates an expected call of IncrCntIfPresent.
+func (mr *MockCommentCacheMockRecorder) IncrCntIfPresent(ctx, biz, bizId, delta any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrCntIfPresent", reflect.TypeOf((*MockCommentCache)(nil).IncrCntIfPresent), ctx, biz, bizId, delta)
+}
+
+// SetCnt mocks base method.
+func (m *MockCommentCache) SetCnt(ctx context.Context, biz string, bizId, cnt int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCnt", ctx, biz, bizId, cnt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCnt indicates an expected call of SetCnt.
+func (mr *MockCommentCacheMockRecorder) SetCnt(ctx, biz, bizId, cnt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCnt", reflect.TypeOf((*MockCommentCache)(nil).SetCnt), ctx, biz, bizId, cnt)
+}
+
+// SetFirstPage mocks base method.
+func (m *MockCommentCache) SetFirstPage(ctx context.Context, biz string, bizId int64, sort domain.CommentSort, cs []domain.Comment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFirstPage", ctx, biz, bizId, sort, cs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetFirstPage indicates an expected call of SetFirstPage.
+func (mr *MockCommentCacheMockRecorder) SetFirstPage(ctx, biz, bizId, sort, cs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFirstPage", reflect.TypeOf((*MockCommentCache)(nil).SetFirstPage), ctx, biz, bizId, sort, cs)
+}
@@ -3,61 +3,165 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"math"
+	"strings"
 	"time"
 
 	"golang.org/x/sync/errgroup"
 
 	"webooktrial/comment/domain"
+	"webooktrial/comment/repository/cache"
 	"webooktrial/comment/repository/dao"
 	"webooktrial/pkg/logger"
 )
 
+var ErrCommentNotFound = dao.ErrDataNotFound
+
+// firstPageSize 缓存的第一页的大小，要的比这个多就不走缓存了
+const firstPageSize = 30
+
+//go:generate mockgen -source=./comment.go -package=repomocks -destination=mocks/comment.mock.go CommentRepository
 type CommentRepository interface {
 	// FindByBiz 根据 ID 倒序查找
-	// 并且会返回每个评论的三条直接回复
+	// 并且会返回每个评论热度最高的三条直接回复
 	FindByBiz(ctx context.Context, biz string, bizId, minId, limit int64) ([]domain.Comment, error)
+	// FindHotByBiz 根据热度倒序查找，也会返回热度最高的三条直接回复
+	FindHotByBiz(ctx context.Context, biz string, bizId int64, maxHot float64, minId, limit int64) ([]domain.Comment, error)
+	// DeleteComment 软删除，子评论都保留
 	DeleteComment(ctx context.Context, comment domain.Comment) error
-	CreateComment(ctx context.Context, comment domain.Comment) error
+	// FindById 删除了的也会返回，Deleted 为 true
+	FindById(ctx context.Context, id int64) (domain.Comment, error)
+	// CreateComment 返回新评论的 ID
+	CreateComment(ctx context.Context, comment domain.Comment) (int64, error)
 	// GetCommentByIds 获取单条评论 支持批量获取
 	GetCommentByIds(ctx context.Context, ids []int64) ([]domain.Comment, error)
 	GetMoreReplies(ctx context.Context, rid int64, maxId, limit int64) ([]domain.Comment, error)
+
+	Like(ctx context.Context, uid, cid int64) error
+	CancelLike(ctx context.Context, uid, cid int64) error
+	// LikedIn cids 里面 uid 点赞了的那些
+	LikedIn(ctx context.Context, uid int64, cids []int64) ([]int64, error)
+
+	// FindByStatus 按照 ID 正序，minId 是上一页最后一条的 ID
+	FindByStatus(ctx context.Context, status domain.CommentStatus, minId, limit int64) ([]domain.Comment, error)
+	// UpdateStatus 返回修改之前的评论
+	UpdateStatus(ctx context.Context, id int64, status domain.CommentStatus, reviewer int64) (domain.Comment, error)
+	// CountByBiz 每个 bizId 的评论数，没有评论的是 0
+	CountByBiz(ctx context.Context, biz string, bizIds []int64) (map[int64]int64, error)
+	// WatchNewComments 所有实例上新出现的、能看到的评论都会回调 fn，
+	// 包括审核通过的。一直阻塞到 ctx 被取消
+	WatchNewComments(ctx context.Context, fn func(c domain.Comment)) error
 }
 
 type CachedCommentRepo struct {
-	dao dao.CommentDAO
-	l   logger.LoggerV1
+	dao         dao.CommentDAO
+	cache       cache.CommentCache
+	broadcaster cache.CommentBroadcaster
+	l           logger.LoggerV1
 }
 
 func (c *CachedCommentRepo) FindByBiz(ctx context.Context, biz string, bizId, minId, limit int64) ([]domain.Comment, error) {
 	// 事实上，最新评论它的缓存效果不是很好
 	// 在这里缓存第一页，缓存没有，就去找数据库
-	// 也可以考虑定时刷新缓存
 	// 拿到的就是顶级评论
-	daoComments, err := c.dao.FindByBiz(ctx, biz, bizId, minId, limit)
+	find := func(limit int64) ([]domain.Comment, error) {
+		daoComments, err := c.dao.FindByBiz(ctx, biz, bizId, minId, limit)
+		if err != nil {
+			return nil, err
+		}
+		return c.withHotReplies(ctx, daoComments)
+	}
+	// 第一页的 minId 是 math.MaxInt64
+	if minId != math.MaxInt64 || limit > firstPageSize {
+		return find(limit)
+	}
+	return c.firstPage(ctx, biz, bizId, domain.CommentSortLatest, limit, find)
+}
+
+func (c *CachedCommentRepo) FindHotByBiz(ctx context.Context, biz string, bizId int64,
+	maxHot float64, minId, limit int64) ([]domain.Comment, error) {
+	find := func(limit int64) ([]domain.Comment, error) {
+		daoComments, err := c.dao.FindHotByBiz(ctx, biz, bizId, maxHot, minId, limit)
+		if err != nil {
+			return nil, err
+		}
+		return c.withHotReplies(ctx, daoComments)
+	}
+	if maxHot > 0 || minId > 0 || limit > firstPageSize {
+		return find(limit)
+	}
+	return c.firstPage(ctx, biz, bizId, domain.CommentSortHot, limit, find)
+}
+
+// firstPage 缓存里面总是放 firstPageSize 条，再按照 limit 截断
+func (c *CachedCommentRepo) firstPage(ctx context.Context, biz string, bizId int64,
+	sort domain.CommentSort, limit int64,
+	find func(limit int64) ([]domain.Comment, error)) ([]domain.Comment, error) {
+	res, err := c.cache.GetFirstPage(ctx, biz, bizId, sort)
+	if err == nil {
+		return res[:min(int64(len(res)), limit)], nil
+	}
+	res, err = find(firstPageSize)
 	if err != nil {
 		return nil, err
 	}
-	res := make([]domain.Comment, 0, len(daoComments))
-	// 拿到前三条子评论
-	// 按照 pid 来分组，取组内三条（这三条是按照 ID 降序排序）
+	// 降级的时候没有查子评论，不能放进缓存
+	if ctx.Value("downgraded") != true {
+		er := c.cache.SetFirstPage(ctx, biz, bizId, sort, res)
+		if er != nil {
+			c.l.Error("回写第一页评论缓存失败",
+				logger.String("biz", biz),
+				logger.Int64("bizId", bizId),
+				logger.Error(er))
+		}
+	}
+	return res[:min(int64(len(res)), limit)], nil
+}
+
+// invalidate 数据库已经改好了，删不掉缓存就等它过期
+func (c *CachedCommentRepo) invalidate(ctx context.Context, biz string, bizId int64) {
+	err := c.cache.DelFirstPage(ctx, biz, bizId)
+	if err != nil {
+		c.l.Error("删除第一页评论缓存失败",
+			logger.String("biz", biz),
+			logger.Int64("bizId", bizId),
+			logger.Error(err))
+	}
+}
+
+// publish 推送失败只是打开了页面的用户收不到，刷新一下就有了
+func (c *CachedCommentRepo) publish(ctx context.Context, cm domain.Comment) {
+	err := c.broadcaster.Publish(ctx, cm)
+	if err != nil {
+		c.l.Error("广播新评论失败", logger.Int64("cid", cm.Id), logger.Error(err))
+	}
+}
+
+func (c *CachedCommentRepo) WatchNewComments(ctx context.Context, fn func(c domain.Comment)) error {
+	return c.broadcaster.Subscribe(ctx, fn)
+}
+
+// withHotReplies 拿到前三条子评论
+// 按照 pid 来分组，取组内热度最高的三条
+func (c *CachedCommentRepo) withHotReplies(ctx context.Context, daoComments []dao.Comment) ([]domain.Comment, error) {
+	res := make([]domain.Comment, len(daoComments))
 	var eg errgroup.Group
 	downgraded := ctx.Value("downgraded") == true
-	for _, d := range daoComments {
-		d := d
-		cm := c.toDomain(d)
-		res = append(res, cm)
+	for i, d := range daoComments {
+		i, d := i, d
+		res[i] = c.toDomain(d)
 		if downgraded {
 			continue
 		}
 		eg.Go(func() error {
-			rs, err := c.dao.FindRepliesByPid(ctx, d.Id, 0, 3)
+			rs, err := c.dao.FindHotRepliesByPid(ctx, d.Id, 3)
 			if err != nil {
 				// 我们认为这是一个可以容忍的错误
 				c.l.Error("查询子评论失败", logger.Error(err))
 				return nil
 			}
 			for _, r := range rs {
-				cm.Children = append(cm.Children, c.toDomain(r))
+				res[i].Children = append(res[i].Children, c.toDomain(r))
 			}
 			return nil
 		})
@@ -66,13 +170,79 @@ func (c *CachedCommentRepo) FindByBiz(ctx context.Context, biz string, bizId, mi
 }
 
 func (c *CachedCommentRepo) DeleteComment(ctx context.Context, comment domain.Comment) error {
-	return c.dao.Delete(ctx, dao.Comment{
-		Id: comment.Id,
-	})
+	old, err := c.dao.Delete(ctx, comment.Id)
+	if err != nil || old.Dtime > 0 || !domain.CommentStatus(old.Status).Visible() {
+		return err
+	}
+	c.invalidate(ctx, old.Biz, old.BizId)
+	c.incrCnt(ctx, old.Biz, old.BizId, -1)
+	return nil
 }
 
-func (c *CachedCommentRepo) CreateComment(ctx context.Context, comment domain.Comment) error {
-	return c.dao.Insert(ctx, c.toEntity(comment))
+func (c *CachedCommentRepo) FindById(ctx context.Context, id int64) (domain.Comment, error) {
+	cm, err := c.dao.FindById(ctx, id)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	return c.toDomain(cm), nil
+}
+
+func (c *CachedCommentRepo) CreateComment(ctx context.Context, comment domain.Comment) (int64, error) {
+	entity := c.toEntity(comment)
+	id, err := c.dao.Insert(ctx, entity)
+	if err != nil || !comment.Status.Visible() {
+		return id, err
+	}
+	entity.Id = id
+	c.invalidate(ctx, comment.Biz, comment.BizID)
+	c.incrCnt(ctx, comment.Biz, comment.BizID, 1)
+	c.publish(ctx, c.toDomain(entity))
+	return id, nil
+}
+
+// incrCnt 数据库已经改好了，缓存更新失败只是有一段时间不准，等过期就好了
+func (c *CachedCommentRepo) incrCnt(ctx context.Context, biz string, bizId, delta int64) {
+	err := c.cache.IncrCntIfPresent(ctx, biz, bizId, delta)
+	if err != nil {
+		c.l.Error("更新评论数缓存失败",
+			logger.String("biz", biz),
+			logger.Int64("bizId", bizId),
+			logger.Error(err))
+	}
+}
+
+func (c *CachedCommentRepo) CountByBiz(ctx context.Context, biz string, bizIds []int64) (map[int64]int64, error) {
+	res, err := c.cache.GetCnts(ctx, biz, bizIds)
+	if err != nil {
+		// 缓存出问题了就都查数据库
+		c.l.Error("查询评论数缓存失败", logger.String("biz", biz), logger.Error(err))
+		res = make(map[int64]int64, len(bizIds))
+	}
+	missed := make([]int64, 0, len(bizIds))
+	for _, id := range bizIds {
+		if _, ok := res[id]; !ok {
+			missed = append(missed, id)
+		}
+	}
+	if len(missed) == 0 {
+		return res, nil
+	}
+	cnts, err := c.dao.CountByBiz(ctx, biz, missed)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range missed {
+		// 没有评论的也要缓存起来，不然每次都要数一遍
+		res[id] = cnts[id]
+		er := c.cache.SetCnt(ctx, biz, id, cnts[id])
+		if er != nil {
+			c.l.Error("回写评论数缓存失败",
+				logger.String("biz", biz),
+				logger.Int64("bizId", id),
+				logger.Error(er))
+		}
+	}
+	return res, nil
 }
 
 func (c *CachedCommentRepo) GetCommentByIds(ctx context.Context, ids []int64) ([]domain.Comment, error) {
@@ -100,17 +270,100 @@ func (c *CachedCommentRepo) GetMoreReplies(ctx context.Context, rid int64, maxId
 	return res, nil
 }
 
+func (c *CachedCommentRepo) Like(ctx context.Context, uid, cid int64) error {
+	err := c.dao.Like(ctx, uid, cid)
+	if err != nil {
+		return err
+	}
+	c.invalidateByComment(ctx, cid)
+	return nil
+}
+
+func (c *CachedCommentRepo) CancelLike(ctx context.Context, uid, cid int64) error {
+	err := c.dao.CancelLike(ctx, uid, cid)
+	if err != nil {
+		return err
+	}
+	c.invalidateByComment(ctx, cid)
+	return nil
+}
+
+// invalidateByComment 点赞数和热度变了，第一页缓存里面的点赞数和顺序都不对了
+func (c *CachedCommentRepo) invalidateByComment(ctx context.Context, cid int64) {
+	cm, err := c.dao.FindById(ctx, cid)
+	if err != nil {
+		c.l.Error("查找评论失败，没有删除第一页评论缓存",
+			logger.Int64("cid", cid),
+			logger.Error(err))
+		return
+	}
+	c.invalidate(ctx, cm.Biz, cm.BizId)
+}
+
+func (c *CachedCommentRepo) LikedIn(ctx context.Context, uid int64, cids []int64) ([]int64, error) {
+	return c.dao.LikedIn(ctx, uid, cids)
+}
+
+func (c *CachedCommentRepo) FindByStatus(ctx context.Context, status domain.CommentStatus,
+	minId, limit int64) ([]domain.Comment, error) {
+	cs, err := c.dao.FindByStatus(ctx, uint8(status), minId, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Comment, 0, len(cs))
+	for _, cm := range cs {
+		res = append(res, c.toDomain(cm))
+	}
+	return res, nil
+}
+
+func (c *CachedCommentRepo) UpdateStatus(ctx context.Context, id int64,
+	status domain.CommentStatus, reviewer int64) (domain.Comment, error) {
+	old, err := c.dao.UpdateStatus(ctx, id, uint8(status), reviewer)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	res := c.toDomain(old)
+	if old.Dtime > 0 {
+		return res, nil
+	}
+	// 从通过改成折叠，列表里面的状态也要变，所以都要删缓存
+	c.invalidate(ctx, old.Biz, old.BizId)
+	switch oldVisible := domain.CommentStatus(old.Status).Visible(); {
+	case !oldVisible && status.Visible():
+		c.incrCnt(ctx, old.Biz, old.BizId, 1)
+		old.Status = uint8(status)
+		c.publish(ctx, c.toDomain(old))
+	case oldVisible && !status.Visible():
+		c.incrCnt(ctx, old.Biz, old.BizId, -1)
+	}
+	return res, nil
+}
+
 func (c *CachedCommentRepo) toDomain(daoComment dao.Comment) domain.Comment {
 	val := domain.Comment{
 		Id: daoComment.Id,
 		Commentator: domain.User{
 			ID: daoComment.Uid,
 		},
-		Biz:     daoComment.Biz,
-		BizID:   daoComment.BizId,
-		Content: daoComment.Content,
-		Ctime:   time.UnixMilli(daoComment.Ctime),
-		Utime:   time.UnixMilli(daoComment.Utime),
+		Biz:      daoComment.Biz,
+		BizID:    daoComment.BizId,
+		Content:  daoComment.Content,
+		LikeCnt:  daoComment.LikeCnt,
+		ReplyCnt: daoComment.ReplyCnt,
+		Hot:      daoComment.Hot,
+		Status:   domain.CommentStatus(daoComment.Status),
+		ModScore: daoComment.ModScore,
+		Ctime:    time.UnixMilli(daoComment.Ctime),
+		Utime:    time.UnixMilli(daoComment.Utime),
+	}
+	if daoComment.Dtime > 0 {
+		val.Deleted = true
+		val.Content = domain.DeletedContent
+		val.Commentator = domain.User{}
+	}
+	if daoComment.ModHits != "" {
+		val.ModHits = strings.Split(daoComment.ModHits, ",")
 	}
 	if daoComment.PID.Valid {
 		val.ParentComment = &domain.Comment{
@@ -127,11 +380,14 @@ func (c *CachedCommentRepo) toDomain(daoComment dao.Comment) domain.Comment {
 
 func (c *CachedCommentRepo) toEntity(domainComment domain.Comment) dao.Comment {
 	daoComment := dao.Comment{
-		Id:      domainComment.Id,
-		Uid:     domainComment.Commentator.ID,
-		Biz:     domainComment.Biz,
-		BizId:   domainComment.BizID,
-		Content: domainComment.Content,
+		Id:       domainComment.Id,
+		Uid:      domainComment.Commentator.ID,
+		Biz:      domainComment.Biz,
+		BizId:    domainComment.BizID,
+		Content:  domainComment.Content,
+		Status:   uint8(domainComment.Status),
+		ModScore: domainComment.ModScore,
+		ModHits:  strings.Join(domainComment.ModHits, ","),
 	}
 	if domainComment.RootComment != nil {
 		daoComment.RootID = sql.NullInt64{
@@ -150,9 +406,12 @@ func (c *CachedCommentRepo) toEntity(domainComment domain.Comment) dao.Comment {
 	return daoComment
 }
 
-func NewCommentRepo(commentDAO dao.CommentDAO, l logger.LoggerV1) CommentRepository {
+func NewCommentRepo(commentDAO dao.CommentDAO, cache cache.CommentCache,
+	broadcaster cache.CommentBroadcaster, l logger.LoggerV1) CommentRepository {
 	return &CachedCommentRepo{
-		dao: commentDAO,
-		l:   l,
+		dao:         commentDAO,
+		cache:       cache,
+		broadcaster: broadcaster,
+		l:           l,
 	}
 }
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"webooktrial/comment/repository/cache"
+	cachemocks "webooktrial/comment/repository/cache/mocks"
+	"webooktrial/comment/repository/dao"
+	daomocks "webooktrial/comment/repository/dao/mocks"
+	"webooktrial/pkg/logger"
+)
+
+func TestCachedCommentRepo_Like(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (dao.CommentDAO, cache.CommentCache)
+		like bool
+
+		wantErr error
+	}{
+		{
+			name: "点赞，删除第一页缓存",
+			mock: func(ctrl *gomock.Controller) (dao.CommentDAO, cache.CommentCache) {
+				d := daomocks.NewMockCommentDAO(ctrl)
+				c := cachemocks.NewMockCommentCache(ctrl)
+				d.EXPECT().Like(gomock.Any(), int64(123), int64(2)).Return(nil)
+				d.EXPECT().FindById(gomock.Any(), int64(2)).
+					Return(dao.Comment{Id: 2, Biz: "article", BizId: 11}, nil)
+				c.EXPECT().DelFirstPage(gomock.Any(), "article", int64(11)).Return(nil)
+				return d, c
+			},
+			like: true,
+		},
+		{
+			name: "取消点赞，删除第一页缓存",
+			mock: func(ctrl *gomock.Controller) (dao.CommentDAO, cache.CommentCache) {
+				d := daomocks.NewMockCommentDAO(ctrl)
+				c := cachemocks.NewMockCommentCache(ctrl)
+				d.EXPECT().CancelLike(gomock.Any(), int64(123), int64(2)).Return(nil)
+				d.EXPECT().FindById(gomock.Any(), int64(2)).
+					Return(dao.Comment{Id: 2, Biz: "article", BizId: 11}, nil)
+				c.EXPECT().DelFirstPage(gomock.Any(), "article", int64(11)).Return(nil)
+				return d, c
+			},
+		},
+		{
+			name: "点赞失败，不动缓存",
+			mock: func(ctrl *gomock.Controller) (dao.CommentDAO, cache.CommentCache) {
+				d := daomocks.NewMockCommentDAO(ctrl)
+				d.EXPECT().Like(gomock.Any(), int64(123), int64(2)).Return(dao.ErrDataNotFound)
+				return d, cachemocks.NewMockCommentCache(ctrl)
+			},
+			like:    true,
+			wantErr: dao.ErrDataNotFound,
+		},
+		{
+			name: "找不到评论，点赞还是成功的",
+			mock: func(ctrl *gomock.Controller) (dao.CommentDAO, cache.CommentCache) {
+				d := daomocks.NewMockCommentDAO(ctrl)
+				d.EXPECT().Like(gomock.Any(), int64(123), int64(2)).Return(nil)
+				d.EXPECT().FindById(gomock.Any(), int64(2)).
+					Return(dao.Comment{}, errors.New("数据库错误"))
+				return d, cachemocks.NewMockCommentCache(ctrl)
+			},
+			like: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, c := tc.mock(ctrl)
+			repo := NewCommentRepo(d, c, nil, logger.NewNopLogger())
+			var err error
+			if tc.like {
+				err = repo.Like(context.Background(), 123, 2)
+			} else {
+				err = repo.CancelLike(context.Background(), 123, 2)
+			}
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
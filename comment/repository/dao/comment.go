@@ -3,26 +3,73 @@ package dao
 import (
 	"context"
 	"database/sql"
+	"math"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"webooktrial/comment/domain"
 )
 
 // ErrDataNotFound 通用的数据没找到
 var ErrDataNotFound = gorm.ErrRecordNotFound
 
+// hotExpr 热度，点赞和回复数取对数，回复比点赞重要一点。
+// 发表时间每晚 12.5 个小时，就要多十倍的点赞和回复才能排在一样的位置。
+// 热度只在点赞和回复变化的时候更新，不会随着时间衰减，所以可以建索引
+const hotExpr = "LOG10(GREATEST(like_cnt + 2 * reply_cnt, 1)) + ctime / 45000000"
+
+// visibleStatuses 普通用户能看到的评论，回复数也只算这些。
+// 不能用 []uint8，那样会被当成 []byte 绑定成一个参数，IN 就展不开了
+var visibleStatuses = []int{
+	int(domain.CommentStatusApproved),
+	int(domain.CommentStatusFolded),
+}
+
+func visible(status uint8) bool {
+	return domain.CommentStatus(status).Visible()
+}
+
+// hotScore 和 hotExpr 保持一致
+func hotScore(likeCnt, replyCnt, ctime int64) float64 {
+	return math.Log10(math.Max(float64(likeCnt+2*replyCnt), 1)) + float64(ctime)/45000000
+}
+
 //go:generate mockgen -source=./comment.go -package=daomocks -destination=mocks/comment.mock.go CommentDAO
 type CommentDAO interface {
-	Insert(ctx context.Context, c Comment) error
+	// Insert 返回新评论的 ID
+	Insert(ctx context.Context, c Comment) (int64, error)
 	// FindByBiz 只查找一级评论
 	FindByBiz(ctx context.Context, biz string,
 		bizId, minId, limit int64) ([]Comment, error)
 	// FindCommentList Comment的ID为0 获取一级评论，如果不为0获取对应的评论，和其评论的所有回复
 	FindCommentList(ctx context.Context, c Comment) ([]Comment, error)
 	FindRepliesByPid(ctx context.Context, pid int64, offset, limit int) ([]Comment, error)
-	// Delete 删除本节点和其对应的子节点
-	Delete(ctx context.Context, u Comment) error
+	// Delete 软删除，子评论都保留。返回删除之前的评论，评论不存在返回 ErrDataNotFound
+	Delete(ctx context.Context, id int64) (Comment, error)
+	// FindById 删除了的也会返回
+	FindById(ctx context.Context, id int64) (Comment, error)
 	FindOneByIds(ctx context.Context, Ids []int64) ([]Comment, error)
 	FindRepliesByRid(ctx context.Context, rid int64, Id int64, limit int64) ([]Comment, error)
+
+	// FindHotByBiz 按照热度倒序查找一级评论，maxHot 和 minId 是上一页最后一条的，都为 0 表示第一页
+	FindHotByBiz(ctx context.Context, biz string, bizId int64,
+		maxHot float64, minId, limit int64) ([]Comment, error)
+	// FindHotRepliesByPid 热度最高的几条直接回复
+	FindHotRepliesByPid(ctx context.Context, pid int64, limit int) ([]Comment, error)
+	// Like 重复点赞不会报错，也不会重复计数。评论不存在返回 ErrDataNotFound
+	Like(ctx context.Context, uid, cid int64) error
+	CancelLike(ctx context.Context, uid, cid int64) error
+	// LikedIn cids 里面 uid 点赞了的那些
+	LikedIn(ctx context.Context, uid int64, cids []int64) ([]int64, error)
+
+	// FindByStatus 审核队列，按照 ID 正序，先提交的先审核
+	FindByStatus(ctx context.Context, status uint8, minId, limit int64) ([]Comment, error)
+	// UpdateStatus 修改审核状态，返回修改之前的评论，评论不存在返回 ErrDataNotFound
+	UpdateStatus(ctx context.Context, id int64, status uint8, reviewer int64) (Comment, error)
+	// CountByBiz 每个 bizId 的评论数，包括回复，只算能看到的、没删除的。没有评论的不在结果里面
+	CountByBiz(ctx context.Context, biz string, bizIds []int64) (map[int64]int64, error)
 }
 
 type GORMCommentDAO struct {
@@ -33,19 +80,103 @@ func NewGORMCommentDAO(db *gorm.DB) CommentDAO {
 	return &GORMCommentDAO{db: db}
 }
 
-func (g *GORMCommentDAO) Insert(ctx context.Context, c Comment) error {
-	return g.db.WithContext(ctx).Create(&c).Error
+func (g *GORMCommentDAO) Insert(ctx context.Context, c Comment) (int64, error) {
+	c.Hot = hotScore(0, 0, c.Ctime)
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&c).Error
+		if err != nil || !c.RootID.Valid || !visible(c.Status) {
+			return err
+		}
+		// 回复数记在根评论上，整棵树的回复都算。待审核的等审核通过了再算
+		return tx.Exec("UPDATE comments SET reply_cnt = reply_cnt + 1, hot = "+hotExpr+" WHERE id = ?",
+			c.RootID.Int64).Error
+	})
+	return c.Id, err
 }
 
 func (g *GORMCommentDAO) FindByBiz(ctx context.Context, biz string, bizId, minId, limit int64) ([]Comment, error) {
 	var res []Comment
 	err := g.db.WithContext(ctx).
-		Where("biz = ? AND biz_id = ? AND id < ? AND pid IS NULL", biz, bizId, minId).
+		Where("biz = ? AND biz_id = ? AND id < ? AND pid IS NULL AND status IN ?",
+			biz, bizId, minId, visibleStatuses).
+		// 删除了的一级评论，没有回复了就不用再显示出来
+		Where("dtime = 0 OR reply_cnt > 0").
+		Order("id DESC").
 		Limit(int(limit)).
 		Find(&res).Error
 	return res, err
 }
 
+func (g *GORMCommentDAO) FindHotByBiz(ctx context.Context, biz string, bizId int64,
+	maxHot float64, minId, limit int64) ([]Comment, error) {
+	var res []Comment
+	db := g.db.WithContext(ctx).
+		Where("biz = ? AND biz_id = ? AND pid IS NULL AND status IN ?", biz, bizId, visibleStatuses).
+		Where("dtime = 0 OR reply_cnt > 0")
+	if maxHot > 0 || minId > 0 {
+		db = db.Where("(hot < ? OR (hot = ? AND id < ?))", maxHot, maxHot, minId)
+	}
+	err := db.Order("hot DESC, id DESC").
+		Limit(int(limit)).
+		Find(&res).Error
+	return res, err
+}
+
+func (g *GORMCommentDAO) FindHotRepliesByPid(ctx context.Context, pid int64, limit int) ([]Comment, error) {
+	var res []Comment
+	// 预览的时候删除了的回复就不要占位置了
+	err := g.db.WithContext(ctx).Where("pid = ? AND status IN ? AND dtime = 0", pid, visibleStatuses).
+		Order("hot DESC, id DESC").
+		Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (g *GORMCommentDAO) Like(ctx context.Context, uid, cid int64) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&CommentLike{
+			Uid:   uid,
+			Cid:   cid,
+			Ctime: time.Now().UnixMilli(),
+		})
+		if res.Error != nil || res.RowsAffected == 0 {
+			// 已经点过赞了
+			return res.Error
+		}
+		res = tx.Exec("UPDATE comments SET like_cnt = like_cnt + 1, hot = "+hotExpr+
+			" WHERE id = ? AND status IN ? AND dtime = 0", cid, visibleStatuses)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			// 评论不存在、还没审核通过或者删除了，回滚掉点赞记录
+			return ErrDataNotFound
+		}
+		return nil
+	})
+}
+
+func (g *GORMCommentDAO) CancelLike(ctx context.Context, uid, cid int64) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("uid = ? AND cid = ?", uid, cid).Delete(&CommentLike{})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return tx.Exec("UPDATE comments SET like_cnt = GREATEST(like_cnt, 1) - 1, hot = "+hotExpr+" WHERE id = ?",
+			cid).Error
+	})
+}
+
+func (g *GORMCommentDAO) LikedIn(ctx context.Context, uid int64, cids []int64) ([]int64, error) {
+	var res []int64
+	if len(cids) == 0 {
+		return res, nil
+	}
+	err := g.db.WithContext(ctx).Model(&CommentLike{}).
+		Where("uid = ? AND cid IN ?", uid, cids).
+		Pluck("cid", &res).Error
+	return res, err
+}
+
 func (g *GORMCommentDAO) FindCommentList(ctx context.Context, c Comment) ([]Comment, error) {
 	var res []Comment
 	builder := g.db.WithContext(ctx)
@@ -69,12 +200,58 @@ func (g *GORMCommentDAO) FindRepliesByPid(ctx context.Context, pid int64, offset
 	return res, err
 }
 
-func (g *GORMCommentDAO) Delete(ctx context.Context, u Comment) error {
-	// 数据库帮你级联删除了，不需要担忧并发问题
-	// 假如 4 已经删了，按照外键的约束，如果你插入一个 pid=4 的行，你是插不进去的
-	return g.db.WithContext(ctx).Delete(&Comment{
-		Id: u.Id,
-	}).Error
+func (g *GORMCommentDAO) Delete(ctx context.Context, id int64) (Comment, error) {
+	var c Comment
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).First(&c).Error
+		if err != nil || c.Dtime > 0 {
+			// 已经删除过了
+			return err
+		}
+		now := time.Now().UnixMilli()
+		err = tx.Model(&Comment{}).Where("id = ?", id).
+			Updates(map[string]any{
+				"dtime": now,
+				"utime": now,
+			}).Error
+		if err != nil || !c.RootID.Valid || !visible(c.Status) {
+			return err
+		}
+		// 子评论还在，所以回复数只减掉自己
+		return tx.Exec("UPDATE comments SET reply_cnt = GREATEST(reply_cnt, 1) - 1, hot = "+hotExpr+" WHERE id = ?",
+			c.RootID.Int64).Error
+	})
+	return c, err
+}
+
+func (g *GORMCommentDAO) FindById(ctx context.Context, id int64) (Comment, error) {
+	var c Comment
+	err := g.db.WithContext(ctx).Where("id = ?", id).First(&c).Error
+	return c, err
+}
+
+func (g *GORMCommentDAO) CountByBiz(ctx context.Context, biz string, bizIds []int64) (map[int64]int64, error) {
+	res := make(map[int64]int64, len(bizIds))
+	if len(bizIds) == 0 {
+		return res, nil
+	}
+	var rows []struct {
+		BizId int64
+		Cnt   int64
+	}
+	err := g.db.WithContext(ctx).Model(&Comment{}).
+		Select("biz_id, COUNT(*) AS cnt").
+		Where("biz = ? AND biz_id IN ? AND status IN ? AND dtime = 0", biz, bizIds, visibleStatuses).
+		Group("biz_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		res[r.BizId] = r.Cnt
+	}
+	return res, nil
 }
 
 func (g *GORMCommentDAO) FindOneByIds(ctx context.Context, Ids []int64) ([]Comment, error) {
@@ -89,12 +266,51 @@ func (g *GORMCommentDAO) FindOneByIds(ctx context.Context, Ids []int64) ([]Comme
 func (g *GORMCommentDAO) FindRepliesByRid(ctx context.Context, rid int64, id int64, limit int64) ([]Comment, error) {
 	var res []Comment
 	err := g.db.WithContext(ctx).
-		Where("root_ID = ? AND id > ?", rid, id).
+		Where("root_ID = ? AND id > ? AND status IN ?", rid, id, visibleStatuses).
 		Order("ID DESC").
 		Limit(int(limit)).Find(&res).Error
 	return res, err
 }
 
+func (g *GORMCommentDAO) FindByStatus(ctx context.Context, status uint8, minId, limit int64) ([]Comment, error) {
+	var res []Comment
+	err := g.db.WithContext(ctx).
+		Where("status = ? AND id > ?", status, minId).
+		Order("id ASC").
+		Limit(int(limit)).
+		Find(&res).Error
+	return res, err
+}
+
+func (g *GORMCommentDAO) UpdateStatus(ctx context.Context, id int64, status uint8, reviewer int64) (Comment, error) {
+	var c Comment
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁住这一行，两个管理员同时审核的时候回复数不会算错
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).First(&c).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&Comment{}).Where("id = ?", id).
+			Updates(map[string]any{
+				"status":   status,
+				"reviewer": reviewer,
+				"utime":    time.Now().UnixMilli(),
+			}).Error
+		// 删除了的评论已经从回复数里面减掉了
+		if err != nil || !c.RootID.Valid || c.Dtime > 0 || visible(c.Status) == visible(status) {
+			return err
+		}
+		delta := "reply_cnt + 1"
+		if !visible(status) {
+			delta = "GREATEST(reply_cnt, 1) - 1"
+		}
+		return tx.Exec("UPDATE comments SET reply_cnt = "+delta+", hot = "+hotExpr+" WHERE id = ?",
+			c.RootID.Int64).Error
+	})
+	return c, err
+}
+
 type Comment struct {
 	// 代表你评论本体
 	Id int64
@@ -104,16 +320,17 @@ type Comment struct {
 	Uid int64
 	// 这个代表的是你评论的对象是什么？
 	// 比如说代表某个帖子，代表某个视频，代表某个图片
-	Biz   string `gorm:"index:biz_type_id"`
-	BizId int64  `gorm:"index:biz_type_if"`
+	Biz   string `gorm:"index:biz_type_id;index:biz_hot,priority:1"`
+	BizId int64  `gorm:"index:biz_type_id;index:biz_hot,priority:2"`
 
 	// 用 NULL 来表达没有父亲
 	// 你可以考虑用 -1 来代表没有父亲
 	// 索引是如何处理 NULL 的？？？
 	// NULL 的取值非常多
 
-	PID sql.NullInt64 `gorm:"index"`
-	// 外键指向的也是同一张表
+	PID sql.NullInt64 `gorm:"index;index:pid_hot,priority:1"`
+	// 外键指向的也是同一张表。
+	// 现在是软删除，级联删除只是兜底，正常不会触发
 	ParentComment *Comment `gorm:"ForeignKey:PID;AssociationForeignKey:ID;constraint:OnDelete:CASCADE"`
 
 	// 引入 RootID 这个设计
@@ -125,9 +342,36 @@ type Comment struct {
 	// 评论的内容
 	Content string
 
+	// 点赞数
+	LikeCnt int64
+	// 回复数，只有根评论有，整棵树的回复都算
+	ReplyCnt int64
+	// 热度，见 hotExpr
+	Hot float64 `gorm:"index:biz_hot,priority:3;index:pid_hot,priority:2"`
+
+	// 审核状态，见 domain.CommentStatus。
+	// 加这个字段之前的评论都当作审核通过了，所以默认值是 2
+	Status uint8 `gorm:"index;default:2"`
+	// ModScore 审核分数，ModHits 命中的敏感词，用逗号分隔
+	ModScore float64
+	ModHits  string
+	// Reviewer 人工审核的管理员，0 表示是自动审核的
+	Reviewer int64
+	// Dtime 删除时间，0 表示没有删除。删除了的评论内容还留着，方便追查
+	Dtime int64
+
 	Utime int64
 }
 
 func (*Comment) TableName() string {
 	return "comments"
 }
+
+// CommentLike 评论的点赞，不用 interactive 是因为热度排序要在这里算。
+// 典型查询是某个人有没有点赞一批评论，所以是 <uid, cid>
+type CommentLike struct {
+	Id    int64 `gorm:"primaryKey,autoIncrement"`
+	Uid   int64 `gorm:"uniqueIndex:uid_cid"`
+	Cid   int64 `gorm:"uniqueIndex:uid_cid"`
+	Ctime int64
+}
@@ -0,0 +1,527 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"webooktrial/comment/domain"
+)
+
+func TestGORMCommentDAO_UpdateStatus(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(t *testing.T) *sql.DB
+
+		status uint8
+
+		wantErr error
+		wantRes Comment
+	}{
+		{
+			name: "回复审核通过，根评论回复数加一",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `comments` WHERE id = \\? .* FOR UPDATE").
+					WithArgs(int64(2)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "root_id", "status", "dtime"}).
+						AddRow(2, 1, uint8(domain.CommentStatusPending), 0))
+				mock.ExpectExec("UPDATE `comments` SET `reviewer`=\\?,`status`=\\?,`utime`=\\? WHERE id = \\?").
+					WithArgs(int64(9), sqlmock.AnyArg(), sqlmock.AnyArg(), int64(2)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE comments SET reply_cnt = reply_cnt \\+ 1, hot = .* WHERE id = \\?").
+					WithArgs(int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			status: uint8(domain.CommentStatusApproved),
+			wantRes: Comment{
+				Id:     2,
+				RootID: sql.NullInt64{Int64: 1, Valid: true},
+				Status: uint8(domain.CommentStatusPending),
+			},
+		},
+		{
+			name: "回复被拒绝，根评论回复数减一",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `comments` WHERE id = \\? .* FOR UPDATE").
+					WithArgs(int64(2)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "root_id", "status", "dtime"}).
+						AddRow(2, 1, uint8(domain.CommentStatusApproved), 0))
+				mock.ExpectExec("UPDATE `comments` SET `reviewer`=\\?,`status`=\\?,`utime`=\\? WHERE id = \\?").
+					WithArgs(int64(9), sqlmock.AnyArg(), sqlmock.AnyArg(), int64(2)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE comments SET reply_cnt = GREATEST\\(reply_cnt, 1\\) - 1, hot = .* WHERE id = \\?").
+					WithArgs(int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			status: uint8(domain.CommentStatusRejected),
+			wantRes: Comment{
+				Id:     2,
+				RootID: sql.NullInt64{Int64: 1, Valid: true},
+				Status: uint8(domain.CommentStatusApproved),
+			},
+		},
+		{
+			name: "通过改成折叠，回复数不变",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `comments` WHERE id = \\? .* FOR UPDATE").
+					WithArgs(int64(2)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "root_id", "status", "dtime"}).
+						AddRow(2, 1, uint8(domain.CommentStatusApproved), 0))
+				mock.ExpectExec("UPDATE `comments` SET `reviewer`=\\?,`status`=\\?,`utime`=\\? WHERE id = \\?").
+					WithArgs(int64(9), sqlmock.AnyArg(), sqlmock.AnyArg(), int64(2)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			status: uint8(domain.CommentStatusFolded),
+			wantRes: Comment{
+				Id:     2,
+				RootID: sql.NullInt64{Int64: 1, Valid: true},
+				Status: uint8(domain.CommentStatusApproved),
+			},
+		},
+		{
+			name: "删除了的回复，回复数不变",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `comments` WHERE id = \\? .* FOR UPDATE").
+					WithArgs(int64(2)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "root_id", "status", "dtime"}).
+						AddRow(2, 1, uint8(domain.CommentStatusPending), 123))
+				mock.ExpectExec("UPDATE `comments` SET `reviewer`=\\?,`status`=\\?,`utime`=\\? WHERE id = \\?").
+					WithArgs(int64(9), sqlmock.AnyArg(), sqlmock.AnyArg(), int64(2)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			status: uint8(domain.CommentStatusApproved),
+			wantRes: Comment{
+				Id:     2,
+				RootID: sql.NullInt64{Int64: 1, Valid: true},
+				Status: uint8(domain.CommentStatusPending),
+				Dtime:  123,
+			},
+		},
+		{
+			name: "一级评论，没有根评论要改",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `comments` WHERE id = \\? .* FOR UPDATE").
+					WithArgs(int64(2)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "root_id", "status", "dtime"}).
+						AddRow(2, nil, uint8(domain.CommentStatusPending), 0))
+				mock.ExpectExec("UPDATE `comments` SET `reviewer`=\\?,`status`=\\?,`utime`=\\? WHERE id = \\?").
+					WithArgs(int64(9), sqlmock.AnyArg(), sqlmock.AnyArg(), int64(2)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			status: uint8(domain.CommentStatusApproved),
+			wantRes: Comment{
+				Id:     2,
+				Status: uint8(domain.CommentStatusPending),
+			},
+		},
+		{
+			name: "评论不存在",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `comments` WHERE id = \\? .* FOR UPDATE").
+					WithArgs(int64(2)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectRollback()
+				return db
+			},
+			status:  uint8(domain.CommentStatusApproved),
+			wantErr: gorm.ErrRecordNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+				Conn:                      tc.mock(t),
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			require.NoError(t, err)
+			d := NewGORMCommentDAO(db)
+			res, err := d.UpdateStatus(context.Background(), 2, tc.status, 9)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
+
+func openMockDB(t *testing.T, db *sql.DB) *gorm.DB {
+	res, err := gorm.Open(gormMysql.New(gormMysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	return res
+}
+
+func TestGORMCommentDAO_Insert(t *testing.T) {
+	// 这里只关心回复数，插入的每一列都不校验
+	insertArgs := make([]driver.Value, 16)
+	for i := range insertArgs {
+		insertArgs[i] = sqlmock.AnyArg()
+	}
+	testCases := []struct {
+		name string
+		mock func(t *testing.T) *sql.DB
+
+		comment Comment
+
+		wantErr error
+		wantId  int64
+	}{
+		{
+			name: "一级评论，不用改回复数",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `comments` .*").
+					WithArgs(insertArgs...).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			comment: Comment{Biz: "article", BizId: 1, Status: uint8(domain.CommentStatusApproved)},
+			wantId:  3,
+		},
+		{
+			name: "回复，根评论回复数加一",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `comments` .*").
+					WithArgs(insertArgs...).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectExec("UPDATE comments SET reply_cnt = reply_cnt \\+ 1, hot = .* WHERE id = \\?").
+					WithArgs(int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			comment: Comment{
+				Biz:    "article",
+				BizId:  1,
+				PID:    sql.NullInt64{Int64: 2, Valid: true},
+				RootID: sql.NullInt64{Int64: 1, Valid: true},
+				Status: uint8(domain.CommentStatusApproved),
+			},
+			wantId: 3,
+		},
+		{
+			name: "待审核的回复，审核通过了再算",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `comments` .*").
+					WithArgs(insertArgs...).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			comment: Comment{
+				Biz:    "article",
+				BizId:  1,
+				PID:    sql.NullInt64{Int64: 2, Valid: true},
+				RootID: sql.NullInt64{Int64: 1, Valid: true},
+				Status: uint8(domain.CommentStatusPending),
+			},
+			wantId: 3,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewGORMCommentDAO(openMockDB(t, tc.mock(t)))
+			id, err := d.Insert(context.Background(), tc.comment)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+		})
+	}
+}
+
+func TestGORMCommentDAO_Delete(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(t *testing.T) *sql.DB
+
+		wantErr error
+		wantRes Comment
+	}{
+		{
+			name: "删除回复，根评论回复数减一",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `comments` WHERE id = \\? .* FOR UPDATE").
+					WithArgs(int64(2)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "root_id", "status", "dtime"}).
+						AddRow(2, 1, uint8(domain.CommentStatusApproved), 0))
+				mock.ExpectExec("UPDATE `comments` SET `dtime`=\\?,`utime`=\\? WHERE id = \\?").
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), int64(2)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE comments SET reply_cnt = GREATEST\\(reply_cnt, 1\\) - 1, hot = .* WHERE id = \\?").
+					WithArgs(int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			wantRes: Comment{
+				Id:     2,
+				RootID: sql.NullInt64{Int64: 1, Valid: true},
+				Status: uint8(domain.CommentStatusApproved),
+			},
+		},
+		{
+			name: "已经删除过了，回复数不变",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `comments` WHERE id = \\? .* FOR UPDATE").
+					WithArgs(int64(2)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "root_id", "status", "dtime"}).
+						AddRow(2, 1, uint8(domain.CommentStatusApproved), 123))
+				mock.ExpectCommit()
+				return db
+			},
+			wantRes: Comment{
+				Id:     2,
+				RootID: sql.NullInt64{Int64: 1, Valid: true},
+				Status: uint8(domain.CommentStatusApproved),
+				Dtime:  123,
+			},
+		},
+		{
+			name: "待审核的回复本来就没算，回复数不变",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `comments` WHERE id = \\? .* FOR UPDATE").
+					WithArgs(int64(2)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "root_id", "status", "dtime"}).
+						AddRow(2, 1, uint8(domain.CommentStatusPending), 0))
+				mock.ExpectExec("UPDATE `comments` SET `dtime`=\\?,`utime`=\\? WHERE id = \\?").
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), int64(2)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			wantRes: Comment{
+				Id:     2,
+				RootID: sql.NullInt64{Int64: 1, Valid: true},
+				Status: uint8(domain.CommentStatusPending),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewGORMCommentDAO(openMockDB(t, tc.mock(t)))
+			res, err := d.Delete(context.Background(), 2)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
+
+func TestGORMCommentDAO_Like(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(t *testing.T) *sql.DB
+
+		wantErr error
+	}{
+		{
+			name: "点赞成功，点赞数和热度一起更新",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `comment_likes` .*").
+					WithArgs(int64(123), int64(2), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE comments SET like_cnt = like_cnt \\+ 1, hot = .* WHERE id = \\? AND status IN \\(\\?,\\?\\) AND dtime = 0").
+					WithArgs(int64(2), int(domain.CommentStatusApproved), int(domain.CommentStatusFolded)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+		},
+		{
+			name: "重复点赞，不重复计数",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `comment_likes` .*").
+					WithArgs(int64(123), int64(2), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+				return db
+			},
+		},
+		{
+			name: "评论不存在，回滚点赞记录",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `comment_likes` .*").
+					WithArgs(int64(123), int64(2), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE comments SET like_cnt = like_cnt \\+ 1, .*").
+					WithArgs(int64(2), int(domain.CommentStatusApproved), int(domain.CommentStatusFolded)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				return db
+			},
+			wantErr: ErrDataNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewGORMCommentDAO(openMockDB(t, tc.mock(t)))
+			err := d.Like(context.Background(), 123, 2)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestGORMCommentDAO_CancelLike(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(t *testing.T) *sql.DB
+
+		wantErr error
+	}{
+		{
+			name: "取消点赞，点赞数减一",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `comment_likes` WHERE uid = \\? AND cid = \\?").
+					WithArgs(int64(123), int64(2)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE comments SET like_cnt = GREATEST\\(like_cnt, 1\\) - 1, hot = .* WHERE id = \\?").
+					WithArgs(int64(2)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+		},
+		{
+			name: "没有点过赞，点赞数不变",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `comment_likes` WHERE uid = \\? AND cid = \\?").
+					WithArgs(int64(123), int64(2)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+				return db
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewGORMCommentDAO(openMockDB(t, tc.mock(t)))
+			err := d.CancelLike(context.Background(), 123, 2)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestGORMCommentDAO_FindHotByBiz(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(t *testing.T) *sql.DB
+
+		maxHot float64
+		minId  int64
+
+		wantErr error
+		wantRes []Comment
+	}{
+		{
+			name: "第一页",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectQuery("SELECT \\* FROM `comments` WHERE \\(biz = \\? AND biz_id = \\? AND pid IS NULL AND status IN \\(\\?,\\?\\)\\) "+
+					"AND \\(dtime = 0 OR reply_cnt > 0\\) ORDER BY hot DESC, id DESC LIMIT 10").
+					WithArgs("article", int64(1), int(domain.CommentStatusApproved), int(domain.CommentStatusFolded)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "hot"}).
+						AddRow(3, 2.5).AddRow(2, 1.5))
+				return db
+			},
+			wantRes: []Comment{{Id: 3, Hot: 2.5}, {Id: 2, Hot: 1.5}},
+		},
+		{
+			name: "后面的页，热度一样的按照 ID 排",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectQuery("SELECT \\* FROM `comments` WHERE .* AND \\(dtime = 0 OR reply_cnt > 0\\) "+
+					"AND \\(\\(hot < \\? OR \\(hot = \\? AND id < \\?\\)\\)\\) ORDER BY hot DESC, id DESC LIMIT 10").
+					WithArgs("article", int64(1), int(domain.CommentStatusApproved), int(domain.CommentStatusFolded),
+						1.5, 1.5, int64(2)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "hot"}).
+						AddRow(1, 1.5))
+				return db
+			},
+			maxHot:  1.5,
+			minId:   2,
+			wantRes: []Comment{{Id: 1, Hot: 1.5}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewGORMCommentDAO(openMockDB(t, tc.mock(t)))
+			res, err := d.FindHotByBiz(context.Background(), "article", 1, tc.maxHot, tc.minId, 10)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
@@ -0,0 +1,278 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./comment.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./comment.go -package=daomocks -destination=mocks/comment.mock.go CommentDAO
+//
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	dao "webooktrial/comment/repository/dao"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCommentDAO is a mock of CommentDAO interface.
+type MockCommentDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommentDAOMockRecorder
+}
+
+// MockCommentDAOMockRecorder is the mock recorder for MockCommentDAO.
+type MockCommentDAOMockRecorder struct {
+	mock *MockCommentDAO
+}
+
+// NewMockCommentDAO creates a new mock instance.
+func NewMockCommentDAO(ctrl *gomock.Controller) *MockCommentDAO {
+	mock := &MockCommentDAO{ctrl: ctrl}
+	mock.recorder = &MockCommentDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommentDAO) EXPECT() *MockCommentDAOMockRecorder {
+	return m.recorder
+}
+
+// CancelLike mocks base method.
+func (m *MockCommentDAO) CancelLike(ctx context.Context, uid, cid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelLike", ctx, uid, cid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelLike indicates an expected call of CancelLike.
+func (mr *MockCommentDAOMockRecorder) CancelLike(ctx, uid, cid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelLike", reflect.TypeOf((*MockCommentDAO)(nil).CancelLike), ctx, uid, cid)
+}
+
+// CountByBiz mocks base method.
+func (m *MockCommentDAO) CountByBiz(ctx context.Context, biz string, bizIds []int64) (map[int64]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByBiz", ctx, biz, bizIds)
+	ret0, _ := ret[0].(map[int64]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByBiz indicates an expected call of CountByBiz.
+func (mr *MockCommentDAOMockRecorder) CountByBiz(ctx, biz, bizIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByBiz", reflect.TypeOf((*MockCommentDAO)(nil).CountByBiz), ctx, biz, bizIds)
+}
+
+// Delete mocks base method.
+func (m *MockCommentDAO) Delete(ctx context.Context, id int64) (dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCommentDAOMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCommentDAO)(nil).Delete), ctx, id)
+}
+
+// FindByBiz mocks base method.
+func (m *MockCommentDAO) FindByBiz(ctx context.Context, biz string, bizId, minId, limit int64) ([]dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByBiz", ctx, biz, bizId, minId, limit)
+	ret0, _ := ret[0].([]dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByBiz indicates an expected call of FindByBiz.
+func (mr *MockCommentDAOMockRecorder) FindByBiz(ctx, biz, bizId, minId, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByBiz", reflect.TypeOf((*MockCommentDAO)(nil).FindByBiz), ctx, biz, bizId, minId, limit)
+}
+
+// FindById mocks base method.
+func (m *MockCommentDAO) FindById(ctx context.Context, id int64) (dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", ctx, id)
+	ret0, _ := ret[0].(dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockCommentDAOMockRecorder) FindById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockCommentDAO)(nil).FindById), ctx, id)
+}
+
+// FindByStatus mocks base method.
+func (m *MockCommentDAO) FindByStatus(ctx context.Context, status uint8, minId, limit int64) ([]dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByStatus", ctx, status, minId, limit)
+	ret0, _ := ret[0].([]dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByStatus indicates an expected call of FindByStatus.
+func (mr *MockCommentDAOMockRecorder) FindByStatus(ctx, status, minId, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByStatus", reflect.TypeOf((*MockCommentDAO)(nil).FindByStatus), ctx, status, minId, limit)
+}
+
+// FindCommentList mocks base method.
+func (m *MockCommentDAO) FindCommentList(ctx context.Context, c dao.Comment) ([]dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCommentList", ctx, c)
+	ret0, _ := ret[0].([]dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCommentList indicates an expected call of FindCommentList.
+func (mr *MockCommentDAOMockRecorder) FindCommentList(ctx, c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCommentList", reflect.TypeOf((*MockCommentDAO)(nil).FindCommentList), ctx, c)
+}
+
+// FindHotByBiz mocks base method.
+func (m *MockCommentDAO) FindHotByBiz(ctx context.Context, biz string, bizId int64, maxHot float64, minId, limit int64) ([]dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindHotByBiz", ctx, biz, bizId, maxHot, minId, limit)
+	ret0, _ := ret[0].([]dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindHotByBiz indicates an expected call of FindHotByBiz.
+func (mr *MockCommentDAOMockRecorder) FindHotByBiz(ctx, biz, bizId, maxHot, minId, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindHotByBiz", reflect.TypeOf((*MockCommentDAO)(nil).FindHotByBiz), ctx, biz, bizId, maxHot, minId, limit)
+}
+
+// FindHotRepliesByPid mocks base method.
+func (m *MockCommentDAO) FindHotRepliesByPid(ctx context.Context, pid int64, limit int) ([]dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindHotRepliesByPid", ctx, pid, limit)
+	ret0, _ := ret[0].([]dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindHotRepliesByPid indicates an expected call of FindHotRepliesByPid.
+func (mr *MockCommentDAOMockRecorder) FindHotRepliesByPid(ctx, pid, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindHotRepliesByPid", reflect.TypeOf((*MockCommentDAO)(nil).FindHotRepliesByPid), ctx, pid, limit)
+}
+
+// FindOneByIds mocks base method.
+func (m *MockCommentDAO) FindOneByIds(ctx context.Context, Ids []int64) ([]dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOneByIds", ctx, Ids)
+	ret0, _ := ret[0].([]dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOneByIds indicates an expected call of FindOneByIds.
+func (mr *MockCommentDAOMockRecorder) FindOneByIds(ctx, Ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOneByIds", reflect.TypeOf((*MockCommentDAO)(nil).FindOneByIds), ctx, Ids)
+}
+
+// FindRepliesByPid mocks base method.
+func (m *MockCommentDAO) FindRepliesByPid(ctx context.Context, pid int64, offset, limit int) ([]dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRepliesByPid", ctx, pid, offset, limit)
+	ret0, _ := ret[0].([]dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRepliesByPid indicates an expected call of FindRepliesByPid.
+func (mr *MockCommentDAOMockRecorder) FindRepliesByPid(ctx, pid, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRepliesByPid", reflect.TypeOf((*MockCommentDAO)(nil).FindRepliesByPid), ctx, pid, offset, limit)
+}
+
+// FindRepliesByRid mocks base method.
+func (m *MockCommentDAO) FindRepliesByRid(ctx context.Context, rid, Id, limit int64) ([]dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRepliesByRid", ctx, rid, Id, limit)
+	ret0, _ := ret[0].([]dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRepliesByRid indicates an expected call of FindRepliesByRid.
+func (mr *MockCommentDAOMockRecorder) FindRepliesByRid(ctx, rid, Id, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRepliesByRid", reflect.TypeOf((*MockCommentDAO)(nil).FindRepliesByRid), ctx, rid, Id, limit)
+}
+
+// Insert mocks base method.
+func (m *MockCommentDAO) Insert(ctx context.Context, c dao.Comment) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, c)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockCommentDAOMockRecorder) Insert(ctx, c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockCommentDAO)(nil).Insert), ctx, c)
+}
+
+// Like mocks base method.
+func (m *MockCommentDAO) Like(ctx context.Context, uid, cid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Like", ctx, uid, cid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Like indicates an expected call of Like.
+func (mr *MockCommentDAOMockRecorder) Like(ctx, uid, cid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Like", reflect.TypeOf((*MockCommentDAO)(nil).Like), ctx, uid, cid)
+}
+
+// LikedIn mocks base method.
+func (m *MockCommentDAO) LikedIn(ctx context.Context, uid int64, cids []int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LikedIn", ctx, uid, cids)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LikedIn indicates an expected call of LikedIn.
+func (mr *MockCommentDAOMockRecorder) LikedIn(ctx, uid, cids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LikedIn", reflect.TypeOf((*MockCommentDAO)(nil).LikedIn), ctx, uid, cids)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockCommentDAO) UpdateStatus(ctx context.Context, id int64, status uint8, reviewer int64) (dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, id, status, reviewer)
+	ret0, _ := ret[0].(dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockCommentDAOMockRecorder) UpdateStatus(ctx, id, status, reviewer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockCommentDAO)(nil).UpdateStatus), ctx, id, status, reviewer)
+}
@@ -2,43 +2,266 @@ package service
 
 import (
 	"context"
+	"errors"
 
 	"webooktrial/comment/domain"
+	"webooktrial/comment/events"
 	"webooktrial/comment/repository"
+	followcli "webooktrial/follow/client"
+	"webooktrial/pkg/bizowner"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/moderation"
+)
+
+var (
+	// ErrBlocked 被评论对象的作者拉黑了
+	ErrBlocked         = errors.New("被作者拉黑了")
+	ErrCommentNotFound = repository.ErrCommentNotFound
+	// ErrContentRejected 没过自动审核，评论不会保存
+	ErrContentRejected = errors.New("评论包含违规内容")
+	// ErrInvalidReviewStatus 人工审核只能改成通过、拒绝或者折叠
+	ErrInvalidReviewStatus = errors.New("不合法的审核状态")
+	// ErrPermissionDenied 只有评论者和评论对象的作者可以删除评论
+	ErrPermissionDenied = errors.New("没有权限删除评论")
 )
 
 type CommentService interface {
-	// GetCommentList Comment的id为0 获取一级评论
-	// 按照 ID 升序排序
-	GetCommentList(ctx context.Context, biz string, bizId, minId, limit int64) ([]domain.Comment, error)
-	// DeleteComment 删除评论，删除本评论及其子评论
-	DeleteComment(ctx context.Context, id int64) error
-	// CreateComment 创建评论
-	CreateComment(ctx context.Context, comment domain.Comment) error
-	GetMoreReplies(ctx context.Context, rid int64, maxId, limit int64) ([]domain.Comment, error)
+	// GetCommentList 获取一级评论，按照 ID 或者热度倒序排序，
+	// 每条一级评论带上热度最高的三条直接回复
+	GetCommentList(ctx context.Context, q domain.CommentListQuery) ([]domain.Comment, error)
+	// DeleteComment 软删除评论，子评论都保留。
+	// 评论者或者评论对象的作者可以删除，重复删除不会报错
+	DeleteComment(ctx context.Context, uid, id int64) error
+	// CreateComment 创建评论，返回审核状态。
+	// 分数低的直接通过，分数高的返回 ErrContentRejected，中间的等待人工审核
+	CreateComment(ctx context.Context, comment domain.Comment) (domain.CommentStatus, error)
+	// GetMoreReplies viewer 是看评论的人，用来判断有没有点赞，0 表示不判断
+	GetMoreReplies(ctx context.Context, viewer, rid int64, maxId, limit int64) ([]domain.Comment, error)
+	// LikeComment 重复点赞不会重复计数
+	LikeComment(ctx context.Context, uid, cid int64) error
+	CancelLikeComment(ctx context.Context, uid, cid int64) error
+
+	// ListPendingComments 等待人工审核的评论，minId 是上一页最后一条的 ID，第一页传 0
+	ListPendingComments(ctx context.Context, minId, limit int64) ([]domain.Comment, error)
+	// ReviewComment 人工审核，已经审核过的也可以改，比如把通过的评论折叠起来
+	ReviewComment(ctx context.Context, id int64, status domain.CommentStatus, reviewer int64) error
+	// GetCommentCount 每个 bizId 的评论数，包括回复，只算能看到的、没删除的
+	GetCommentCount(ctx context.Context, biz string, bizIds []int64) (map[int64]int64, error)
+	// SubscribeComments 新发表的和审核通过的评论，ctx 被取消之后会关闭返回的 channel
+	SubscribeComments(ctx context.Context, biz string, bizId int64) <-chan domain.Comment
 }
 
 type commentService struct {
-	repo repository.CommentRepository
+	repo         repository.CommentRepository
+	blockChecker followcli.BlockChecker
+	// owners 评论对象的作者，不能用调用方传过来的
+	owners    bizowner.Resolver
+	moderator *moderation.Moderator
+	hub       *CommentHub
+	// producer 评论能被看到之后通知下游，比如回复和 @ 的通知
+	producer events.Producer
+	l        logger.LoggerV1
+}
+
+func NewCommentService(repo repository.CommentRepository,
+	blockChecker followcli.BlockChecker,
+	owners bizowner.Resolver,
+	moderator *moderation.Moderator, hub *CommentHub,
+	producer events.Producer, l logger.LoggerV1) CommentService {
+	return &commentService{repo: repo, blockChecker: blockChecker, owners: owners,
+		moderator: moderator, hub: hub, producer: producer, l: l}
+}
+
+func (c *commentService) GetCommentList(ctx context.Context, q domain.CommentListQuery) ([]domain.Comment, error) {
+	var (
+		list []domain.Comment
+		err  error
+	)
+	if q.Sort == domain.CommentSortHot {
+		list, err = c.repo.FindHotByBiz(ctx, q.Biz, q.BizID, q.MaxHot, q.MinID, q.Limit)
+	} else {
+		list, err = c.repo.FindByBiz(ctx, q.Biz, q.BizID, q.MinID, q.Limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.fillLiked(ctx, q.Viewer, list)
+	return list, nil
+}
+
+// fillLiked 包括子评论。查询失败只是显示成没有点赞
+func (c *commentService) fillLiked(ctx context.Context, viewer int64, list []domain.Comment) {
+	if viewer <= 0 || len(list) == 0 {
+		return
+	}
+	var cids []int64
+	for _, cm := range list {
+		cids = append(cids, cm.Id)
+		for _, child := range cm.Children {
+			cids = append(cids, child.Id)
+		}
+	}
+	liked, err := c.repo.LikedIn(ctx, viewer, cids)
+	if err != nil {
+		c.l.Error("查询评论点赞状态失败", logger.Int64("viewer", viewer), logger.Error(err))
+		return
+	}
+	likedSet := make(map[int64]struct{}, len(liked))
+	for _, cid := range liked {
+		likedSet[cid] = struct{}{}
+	}
+	for i := range list {
+		_, list[i].Liked = likedSet[list[i].Id]
+		for j := range list[i].Children {
+			_, list[i].Children[j].Liked = likedSet[list[i].Children[j].Id]
+		}
+	}
+}
+
+func (c *commentService) DeleteComment(ctx context.Context, uid, id int64) error {
+	cm, err := c.repo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if cm.Deleted {
+		return nil
+	}
+	if uid != cm.Commentator.ID && !c.isBizOwner(ctx, cm, uid) {
+		return ErrPermissionDenied
+	}
+	return c.repo.DeleteComment(ctx, cm)
+}
+
+// isBizOwner 查不到作者的时候只有评论者自己能删
+func (c *commentService) isBizOwner(ctx context.Context, cm domain.Comment, uid int64) bool {
+	owner := c.findBizOwner(ctx, cm)
+	return owner > 0 && owner == uid
+}
+
+// findBizOwner 评论对象的作者，查不到的时候返回 0
+func (c *commentService) findBizOwner(ctx context.Context, cm domain.Comment) int64 {
+	owner, err := c.owners.Owner(ctx, cm.Biz, cm.BizID)
+	if err != nil && !errors.Is(err, bizowner.ErrOwnerNotFound) {
+		c.l.Error("查询评论对象的作者失败",
+			logger.String("biz", cm.Biz),
+			logger.Int64("bizId", cm.BizID),
+			logger.Error(err))
+	}
+	return owner
+}
+
+func (c *commentService) CreateComment(ctx context.Context, comment domain.Comment) (domain.CommentStatus, error) {
+	uid := comment.Commentator.ID
+	owner := c.findBizOwner(ctx, comment)
+	comment.BizOwner = owner
+	if owner > 0 && owner != uid {
+		blocked, err := c.blockChecker.IsBlocked(ctx, owner, uid)
+		if err != nil {
+			return domain.CommentStatusUnknown, err
+		}
+		if blocked {
+			return domain.CommentStatusUnknown, ErrBlocked
+		}
+	}
+	verdict, err := c.moderator.Moderate(ctx, comment.Content)
+	if err != nil {
+		// 审核出问题了就转人工，不能直接放出去
+		c.l.Error("评论自动审核失败",
+			logger.Int64("uid", uid),
+			logger.Error(err))
+	}
+	switch verdict.Decision {
+	case moderation.DecisionReject:
+		return domain.CommentStatusRejected, ErrContentRejected
+	case moderation.DecisionApprove:
+		comment.Status = domain.CommentStatusApproved
+	default:
+		comment.Status = domain.CommentStatusPending
+	}
+	comment.ModScore = verdict.Score
+	comment.ModHits = verdict.Hits
+	id, err := c.repo.CreateComment(ctx, comment)
+	if err != nil {
+		return comment.Status, err
+	}
+	if comment.Status.Visible() {
+		comment.Id = id
+		c.produceCreated(ctx, comment)
+	}
+	return comment.Status, nil
+}
+
+// produceCreated 评论已经保存好了，通知发不出去只记录日志。
+// 审核通过的时候评论是从数据库里面查出来的，保存的作者可能是旧版本客户端传的，
+// 所以作者每次都在服务端重新查
+func (c *commentService) produceCreated(ctx context.Context, cm domain.Comment) {
+	evt := events.CommentCreatedEvent{
+		Id:       cm.Id,
+		Biz:      cm.Biz,
+		BizId:    cm.BizID,
+		BizOwner: c.findBizOwner(ctx, cm),
+		Uid:      cm.Commentator.ID,
+		Content:  cm.Content,
+	}
+	if cm.ParentComment != nil {
+		evt.ParentId = cm.ParentComment.Id
+		parent, err := c.repo.FindById(ctx, evt.ParentId)
+		if err != nil {
+			c.l.Error("查询被回复的评论失败",
+				logger.Int64("pid", evt.ParentId), logger.Error(err))
+		}
+		// 被回复的评论删除了就不通知它的评论者了
+		evt.ParentUid = parent.Commentator.ID
+	}
+	err := c.producer.ProduceCommentCreatedEvent(ctx, evt)
+	if err != nil {
+		c.l.Error("发送评论创建事件失败",
+			logger.Int64("cid", cm.Id), logger.Error(err))
+	}
+}
+
+func (c *commentService) GetMoreReplies(ctx context.Context, viewer, rid int64, maxId int64, limit int64) ([]domain.Comment, error) {
+	list, err := c.repo.GetMoreReplies(ctx, rid, maxId, limit)
+	if err != nil {
+		return nil, err
+	}
+	c.fillLiked(ctx, viewer, list)
+	return list, nil
+}
+
+func (c *commentService) LikeComment(ctx context.Context, uid, cid int64) error {
+	return c.repo.Like(ctx, uid, cid)
 }
 
-func NewCommentService(repo repository.CommentRepository) CommentService {
-	return &commentService{repo: repo}
+func (c *commentService) CancelLikeComment(ctx context.Context, uid, cid int64) error {
+	return c.repo.CancelLike(ctx, uid, cid)
 }
 
-func (c *commentService) GetCommentList(ctx context.Context, biz string, bizId, minId, limit int64) ([]domain.Comment, error) {
-	list, err := c.repo.FindByBiz(ctx, biz, bizId, minId, limit)
-	return list, err
+func (c *commentService) GetCommentCount(ctx context.Context, biz string, bizIds []int64) (map[int64]int64, error) {
+	return c.repo.CountByBiz(ctx, biz, bizIds)
 }
 
-func (c *commentService) DeleteComment(ctx context.Context, id int64) error {
-	return c.DeleteComment(ctx, id)
+func (c *commentService) SubscribeComments(ctx context.Context, biz string, bizId int64) <-chan domain.Comment {
+	return c.hub.Subscribe(ctx, biz, bizId)
 }
 
-func (c *commentService) CreateComment(ctx context.Context, comment domain.Comment) error {
-	return c.repo.CreateComment(ctx, comment)
+func (c *commentService) ListPendingComments(ctx context.Context, minId, limit int64) ([]domain.Comment, error) {
+	return c.repo.FindByStatus(ctx, domain.CommentStatusPending, minId, limit)
 }
 
-func (c *commentService) GetMoreReplies(ctx context.Context, rid int64, maxId int64, limit int64) ([]domain.Comment, error) {
-	return c.repo.GetMoreReplies(ctx, rid, maxId, limit)
+func (c *commentService) ReviewComment(ctx context.Context, id int64,
+	status domain.CommentStatus, reviewer int64) error {
+	switch status {
+	case domain.CommentStatusApproved, domain.CommentStatusRejected, domain.CommentStatusFolded:
+		old, err := c.repo.UpdateStatus(ctx, id, status, reviewer)
+		if err != nil {
+			return err
+		}
+		if !old.Deleted && !old.Status.Visible() && status.Visible() {
+			c.produceCreated(ctx, old)
+		}
+		return nil
+	default:
+		return ErrInvalidReviewStatus
+	}
 }
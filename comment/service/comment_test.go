@@ -0,0 +1,425 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"webooktrial/comment/domain"
+	"webooktrial/comment/events"
+	"webooktrial/comment/repository"
+	repomocks "webooktrial/comment/repository/mocks"
+	"webooktrial/pkg/bizowner"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/moderation"
+)
+
+// fakeBlockChecker blocked 里面的是 [blocker, blocked]
+type fakeBlockChecker struct {
+	blocked map[[2]int64]bool
+	err     error
+}
+
+func (f *fakeBlockChecker) IsBlocked(ctx context.Context, blocker, blocked int64) (bool, error) {
+	return f.blocked[[2]int64{blocker, blocked}], f.err
+}
+
+// fakeProducer 记录发出去的事件
+type fakeProducer struct {
+	evts []events.CommentCreatedEvent
+}
+
+func (f *fakeProducer) ProduceCommentCreatedEvent(ctx context.Context, evt events.CommentCreatedEvent) error {
+	f.evts = append(f.evts, evt)
+	return nil
+}
+
+// fakeResolver key 是 bizId，没有的返回 bizowner.ErrOwnerNotFound
+type fakeResolver map[int64]int64
+
+func (f fakeResolver) Owner(ctx context.Context, biz string, bizId int64) (int64, error) {
+	owner, ok := f[bizId]
+	if !ok {
+		return 0, bizowner.ErrOwnerNotFound
+	}
+	return owner, nil
+}
+
+type errChecker struct{}
+
+func (errChecker) Check(ctx context.Context, text string) (moderation.Result, error) {
+	return moderation.Result{}, errors.New("审核服务不可用")
+}
+
+func newTestModerator() *moderation.Moderator {
+	return moderation.NewModerator(moderation.NewWordFilter([]moderation.Word{
+		{Text: "广告", Score: 0.5},
+		{Text: "违禁", Score: 0.9},
+	}), moderation.Policy{ApproveBelow: 0.3, RejectAbove: 0.8})
+}
+
+func TestCommentService_CreateComment(t *testing.T) {
+	testCases := []struct {
+		name      string
+		mock      func(ctrl *gomock.Controller) repository.CommentRepository
+		moderator *moderation.Moderator
+		owners    fakeResolver
+		blocked   map[[2]int64]bool
+
+		comment domain.Comment
+
+		wantStatus domain.CommentStatus
+		wantErr    error
+		wantEvts   []events.CommentCreatedEvent
+	}{
+		{
+			name: "自动通过",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().CreateComment(gomock.Any(), domain.Comment{
+					Commentator: domain.User{ID: 2},
+					Biz:         "article",
+					BizID:       11,
+					BizOwner:    1,
+					Content:     "写得好",
+					Status:      domain.CommentStatusApproved,
+				}).Return(int64(100), nil)
+				return repo
+			},
+			owners: fakeResolver{11: 1},
+			comment: domain.Comment{
+				Commentator: domain.User{ID: 2},
+				Biz:         "article",
+				BizID:       11,
+				Content:     "写得好",
+			},
+			wantStatus: domain.CommentStatusApproved,
+			wantEvts: []events.CommentCreatedEvent{
+				{Id: 100, Biz: "article", BizId: 11, BizOwner: 1, Uid: 2, Content: "写得好"},
+			},
+		},
+		{
+			name: "等待人工审核",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().CreateComment(gomock.Any(), domain.Comment{
+					Commentator: domain.User{ID: 2},
+					Biz:         "article",
+					BizID:       11,
+					Content:     "加我看广告",
+					Status:      domain.CommentStatusPending,
+					ModScore:    0.5,
+					ModHits:     []string{"广告"},
+				}).Return(int64(100), nil)
+				return repo
+			},
+			comment: domain.Comment{
+				Commentator: domain.User{ID: 2},
+				Biz:         "article",
+				BizID:       11,
+				Content:     "加我看广告",
+			},
+			wantStatus: domain.CommentStatusPending,
+		},
+		{
+			name: "审核出错转人工",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().CreateComment(gomock.Any(), domain.Comment{
+					Commentator: domain.User{ID: 2},
+					Biz:         "article",
+					BizID:       11,
+					Content:     "写得好",
+					Status:      domain.CommentStatusPending,
+				}).Return(int64(100), nil)
+				return repo
+			},
+			moderator: moderation.NewModerator(errChecker{},
+				moderation.Policy{ApproveBelow: 0.3, RejectAbove: 0.8}),
+			comment: domain.Comment{
+				Commentator: domain.User{ID: 2},
+				Biz:         "article",
+				BizID:       11,
+				Content:     "写得好",
+			},
+			wantStatus: domain.CommentStatusPending,
+		},
+		{
+			name: "自动拒绝",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				// 不会保存
+				return repomocks.NewMockCommentRepository(ctrl)
+			},
+			comment: domain.Comment{
+				Commentator: domain.User{ID: 2},
+				Biz:         "article",
+				BizID:       11,
+				Content:     "违禁内容",
+			},
+			wantStatus: domain.CommentStatusRejected,
+			wantErr:    ErrContentRejected,
+		},
+		{
+			name: "被拉黑",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				return repomocks.NewMockCommentRepository(ctrl)
+			},
+			owners:  fakeResolver{11: 1},
+			blocked: map[[2]int64]bool{{1, 2}: true},
+			comment: domain.Comment{
+				Commentator: domain.User{ID: 2},
+				Biz:         "article",
+				BizID:       11,
+				Content:     "写得好",
+			},
+			wantStatus: domain.CommentStatusUnknown,
+			wantErr:    ErrBlocked,
+		},
+		{
+			name: "调用方传的作者不算数",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().CreateComment(gomock.Any(), domain.Comment{
+					Commentator: domain.User{ID: 2},
+					Biz:         "article",
+					BizID:       11,
+					BizOwner:    1,
+					Content:     "写得好",
+					Status:      domain.CommentStatusApproved,
+				}).Return(int64(100), nil)
+				return repo
+			},
+			owners: fakeResolver{11: 1},
+			// 3 没有拉黑 2，1 拉黑了 2 才对
+			blocked: map[[2]int64]bool{{1, 2}: false, {3, 2}: true},
+			comment: domain.Comment{
+				Commentator: domain.User{ID: 2},
+				Biz:         "article",
+				BizID:       11,
+				BizOwner:    3,
+				Content:     "写得好",
+			},
+			wantStatus: domain.CommentStatusApproved,
+			wantEvts: []events.CommentCreatedEvent{
+				{Id: 100, Biz: "article", BizId: 11, BizOwner: 1, Uid: 2, Content: "写得好"},
+			},
+		},
+		{
+			name: "保存失败",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().CreateComment(gomock.Any(), gomock.Any()).
+					Return(int64(0), errors.New("mock db error"))
+				return repo
+			},
+			comment: domain.Comment{
+				Commentator: domain.User{ID: 2},
+				Biz:         "article",
+				BizID:       11,
+				Content:     "写得好",
+			},
+			wantStatus: domain.CommentStatusApproved,
+			wantErr:    errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			moderator := tc.moderator
+			if moderator == nil {
+				moderator = newTestModerator()
+			}
+			producer := &fakeProducer{}
+			svc := NewCommentService(tc.mock(ctrl), &fakeBlockChecker{blocked: tc.blocked},
+				tc.owners, moderator, nil, producer, logger.NewNopLogger())
+			status, err := svc.CreateComment(context.Background(), tc.comment)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantStatus, status)
+			assert.Equal(t, tc.wantEvts, producer.evts)
+		})
+	}
+}
+
+func TestCommentService_ReviewComment(t *testing.T) {
+	testCases := []struct {
+		name   string
+		mock   func(ctrl *gomock.Controller) repository.CommentRepository
+		status domain.CommentStatus
+
+		wantErr  error
+		wantEvts []events.CommentCreatedEvent
+	}{
+		{
+			name: "审核通过发出事件",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().UpdateStatus(gomock.Any(), int64(100), domain.CommentStatusApproved, int64(9)).
+					Return(domain.Comment{
+						Id:          100,
+						Commentator: domain.User{ID: 2},
+						Biz:         "article",
+						BizID:       11,
+						Content:     "加我看广告",
+						Status:      domain.CommentStatusPending,
+					}, nil)
+				return repo
+			},
+			status: domain.CommentStatusApproved,
+			wantEvts: []events.CommentCreatedEvent{
+				{Id: 100, Biz: "article", BizId: 11, BizOwner: 1, Uid: 2, Content: "加我看广告"},
+			},
+		},
+		{
+			name: "保存的作者不可信，发事件的时候重新查",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().UpdateStatus(gomock.Any(), int64(100), domain.CommentStatusApproved, int64(9)).
+					Return(domain.Comment{
+						Id:          100,
+						Commentator: domain.User{ID: 2},
+						Biz:         "article",
+						BizID:       11,
+						BizOwner:    5,
+						Content:     "加我看广告",
+						Status:      domain.CommentStatusPending,
+					}, nil)
+				return repo
+			},
+			status: domain.CommentStatusApproved,
+			wantEvts: []events.CommentCreatedEvent{
+				{Id: 100, Biz: "article", BizId: 11, BizOwner: 1, Uid: 2, Content: "加我看广告"},
+			},
+		},
+		{
+			name: "已经通过的折叠起来不发事件",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().UpdateStatus(gomock.Any(), int64(100), domain.CommentStatusFolded, int64(9)).
+					Return(domain.Comment{Id: 100, Status: domain.CommentStatusApproved}, nil)
+				return repo
+			},
+			status: domain.CommentStatusFolded,
+		},
+		{
+			name: "不合法的状态",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				return repomocks.NewMockCommentRepository(ctrl)
+			},
+			status:  domain.CommentStatusPending,
+			wantErr: ErrInvalidReviewStatus,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			producer := &fakeProducer{}
+			svc := NewCommentService(tc.mock(ctrl), &fakeBlockChecker{},
+				fakeResolver{11: 1}, newTestModerator(), nil, producer, logger.NewNopLogger())
+			err := svc.ReviewComment(context.Background(), 100, tc.status, 9)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantEvts, producer.evts)
+		})
+	}
+}
+
+func TestCommentService_DeleteComment(t *testing.T) {
+	// 文章 11 的作者是 1，评论 100 是 2 在文章 11 下面发的
+	cm := domain.Comment{
+		Id:          100,
+		Commentator: domain.User{ID: 2},
+		Biz:         "article",
+		BizID:       11,
+	}
+	testCases := []struct {
+		name   string
+		mock   func(ctrl *gomock.Controller) repository.CommentRepository
+		owners fakeResolver
+		uid    int64
+
+		wantErr error
+	}{
+		{
+			name: "评论者自己删除",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(100)).Return(cm, nil)
+				repo.EXPECT().DeleteComment(gomock.Any(), cm).Return(nil)
+				return repo
+			},
+			owners: fakeResolver{11: 1},
+			uid:    2,
+		},
+		{
+			name: "文章作者删除",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(100)).Return(cm, nil)
+				repo.EXPECT().DeleteComment(gomock.Any(), cm).Return(nil)
+				return repo
+			},
+			owners: fakeResolver{11: 1},
+			uid:    1,
+		},
+		{
+			name: "其他人不能删除",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(100)).Return(cm, nil)
+				return repo
+			},
+			owners:  fakeResolver{11: 1},
+			uid:     3,
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name: "查不到作者，只有评论者能删",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(100)).Return(cm, nil)
+				return repo
+			},
+			owners:  fakeResolver{},
+			uid:     1,
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name: "已经删除了",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				deleted := cm
+				deleted.Deleted = true
+				repo.EXPECT().FindById(gomock.Any(), int64(100)).Return(deleted, nil)
+				return repo
+			},
+			owners: fakeResolver{11: 1},
+			uid:    3,
+		},
+		{
+			name: "评论不存在",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(100)).
+					Return(domain.Comment{}, ErrCommentNotFound)
+				return repo
+			},
+			owners:  fakeResolver{11: 1},
+			uid:     1,
+			wantErr: ErrCommentNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewCommentService(tc.mock(ctrl), &fakeBlockChecker{}, tc.owners,
+				newTestModerator(), nil, &fakeProducer{}, logger.NewNopLogger())
+			err := svc.DeleteComment(context.Background(), tc.uid, 100)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
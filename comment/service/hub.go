@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"webooktrial/comment/domain"
+	"webooktrial/comment/repository"
+	"webooktrial/pkg/logger"
+)
+
+// CommentHub 把新评论推给本实例上订阅了对应 biz 的连接
+type CommentHub struct {
+	repo repository.CommentRepository
+	l    logger.LoggerV1
+
+	mu   sync.RWMutex
+	subs map[string]map[chan domain.Comment]struct{}
+	// bufferSize 每个订阅者的缓冲，客户端太慢了就丢掉新评论，不能拖慢其它订阅者
+	bufferSize int
+	cancel     context.CancelFunc
+}
+
+func NewCommentHub(repo repository.CommentRepository, l logger.LoggerV1) *CommentHub {
+	return &CommentHub{
+		repo:       repo,
+		l:          l,
+		subs:       make(map[string]map[chan domain.Comment]struct{}),
+		bufferSize: 16,
+	}
+}
+
+// Start 开始监听新评论，和 Kafka 的消费者一样不会阻塞
+func (h *CommentHub) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+	go func() {
+		err := h.repo.WatchNewComments(ctx, h.dispatch)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			h.l.Error("退出了新评论的订阅", logger.Error(err))
+		}
+	}()
+	return nil
+}
+
+// Close 停止监听新评论，已经建立的订阅要等各自的 ctx 取消
+func (h *CommentHub) Close() error {
+	if h.cancel != nil {
+		h.cancel()
+	}
+	return nil
+}
+
+// Subscribe ctx 被取消之后会关闭返回的 channel
+func (h *CommentHub) Subscribe(ctx context.Context, biz string, bizId int64) <-chan domain.Comment {
+	key := h.key(biz, bizId)
+	ch := make(chan domain.Comment, h.bufferSize)
+	h.mu.Lock()
+	if h.subs[key] == nil {
+		h.subs[key] = make(map[chan domain.Comment]struct{})
+	}
+	h.subs[key][ch] = struct{}{}
+	h.mu.Unlock()
+	go func() {
+		<-ctx.Done()
+		// 拿着写锁关闭，dispatch 就不会往关闭了的 channel 里面写
+		h.mu.Lock()
+		delete(h.subs[key], ch)
+		if len(h.subs[key]) == 0 {
+			delete(h.subs, key)
+		}
+		h.mu.Unlock()
+		close(ch)
+	}()
+	return ch
+}
+
+func (h *CommentHub) dispatch(c domain.Comment) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch := range h.subs[h.key(c.Biz, c.BizID)] {
+		select {
+		case ch <- c:
+		default:
+			h.l.Warn("订阅者太慢，丢弃新评论", logger.Int64("cid", c.Id))
+		}
+	}
+}
+
+func (h *CommentHub) key(biz string, bizId int64) string {
+	return fmt.Sprintf("%s:%d", biz, bizId)
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"webooktrial/comment/domain"
+	repomocks "webooktrial/comment/repository/mocks"
+	"webooktrial/pkg/logger"
+)
+
+// startHub 返回的 dispatch 就是 repo 收到新评论之后的回调，watchDone 在监听退出之后关闭
+func startHub(t *testing.T) (*CommentHub, func(c domain.Comment), <-chan struct{}) {
+	ctrl := gomock.NewController(t)
+	repo := repomocks.NewMockCommentRepository(ctrl)
+	fns := make(chan func(c domain.Comment), 1)
+	watchDone := make(chan struct{})
+	repo.EXPECT().WatchNewComments(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(c domain.Comment)) error {
+			fns <- fn
+			<-ctx.Done()
+			close(watchDone)
+			return ctx.Err()
+		})
+	hub := NewCommentHub(repo, logger.NewNopLogger())
+	require.NoError(t, hub.Start())
+	return hub, <-fns, watchDone
+}
+
+func TestCommentHub_Dispatch(t *testing.T) {
+	hub, dispatch, _ := startHub(t)
+	defer hub.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch1 := hub.Subscribe(ctx, "article", 1)
+	ch2 := hub.Subscribe(ctx, "article", 1)
+	other := hub.Subscribe(ctx, "article", 2)
+
+	dispatch(domain.Comment{Id: 10, Biz: "article", BizID: 1})
+	// 同一篇文章的订阅者都能收到
+	assert.Equal(t, int64(10), (<-ch1).Id)
+	assert.Equal(t, int64(10), (<-ch2).Id)
+	// 别的文章的订阅者收不到
+	select {
+	case c := <-other:
+		t.Fatalf("不应该收到别的文章的评论 %d", c.Id)
+	default:
+	}
+}
+
+func TestCommentHub_SlowSubscriber(t *testing.T) {
+	hub, dispatch, _ := startHub(t)
+	defer hub.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	slow := hub.Subscribe(ctx, "article", 1)
+	fast := hub.Subscribe(ctx, "article", 1)
+	for i := 0; i < hub.bufferSize+5; i++ {
+		dispatch(domain.Comment{Id: int64(i + 1), Biz: "article", BizID: 1})
+		// fast 每次都读走，不会因为 slow 满了就收不到
+		assert.Equal(t, int64(i+1), (<-fast).Id)
+	}
+	// slow 只保留了缓冲区那么多，多出来的丢掉了
+	assert.Len(t, slow, hub.bufferSize)
+	assert.Equal(t, int64(1), (<-slow).Id)
+}
+
+func TestCommentHub_Unsubscribe(t *testing.T) {
+	hub, dispatch, _ := startHub(t)
+	defer hub.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := hub.Subscribe(ctx, "article", 1)
+	cancel()
+	// ctx 取消之后 channel 会被关闭
+	select {
+	case _, ok := <-ch:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("取消订阅之后没有关闭 channel")
+	}
+	hub.mu.RLock()
+	assert.Empty(t, hub.subs)
+	hub.mu.RUnlock()
+	// 取消之后再有新评论也不会往关闭了的 channel 里面写
+	dispatch(domain.Comment{Id: 10, Biz: "article", BizID: 1})
+}
+
+func TestCommentHub_Close(t *testing.T) {
+	hub, _, watchDone := startHub(t)
+	require.NoError(t, hub.Close())
+	select {
+	case <-watchDone:
+	case <-time.After(time.Second):
+		t.Fatal("Close 之后没有停止监听新评论")
+	}
+}
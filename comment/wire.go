@@ -1,34 +1,52 @@
+//go:build wireinject
+
 package comment
 
 import (
 	"github.com/google/wire"
 
+	"webooktrial/comment/events"
 	"webooktrial/comment/grpc"
 	"webooktrial/comment/ioc"
 	"webooktrial/comment/repository"
+	"webooktrial/comment/repository/cache"
 	"webooktrial/comment/repository/dao"
 	"webooktrial/comment/service"
-	"webooktrial/pkg/wego"
+	followcli "webooktrial/follow/client"
+	"webooktrial/pkg/grpcx"
 )
 
 var serviceProviderSet = wire.NewSet(
 	dao.NewGORMCommentDAO,
+	cache.NewRedisCommentCache,
+	cache.NewRedisCommentBroadcaster,
 	repository.NewCommentRepo,
 	service.NewCommentService,
+	events.NewKafkaProducer,
 	grpc.NewCommentServiceServer,
 )
 
 var thirdProvider = wire.NewSet(
 	ioc.InitLogger,
 	ioc.InitDB,
+	ioc.InitRedis,
+	ioc.InitKafka,
+	ioc.InitSyncProducer,
+	grpcx.InitEtcdClient,
+	ioc.InitFollowClient,
+	followcli.InitBlockChecker,
+	ioc.InitBizOwnerResolver,
+	ioc.InitModerator,
+	ioc.InitCommentHub,
 )
 
-func Init() *wego.App {
+func Init() *App {
 	wire.Build(
 		thirdProvider,
 		serviceProviderSet,
+		ioc.NewConsumers,
 		ioc.InitGRPCxServer,
-		wire.Struct(new(wego.App), "GRPCServer"),
+		wire.Struct(new(App), "*"),
 	)
-	return new(wego.App)
+	return new(App)
 }
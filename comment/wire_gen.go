@@ -8,32 +8,49 @@ package comment
 
 import (
 	"github.com/google/wire"
+	"webooktrial/comment/events"
 	"webooktrial/comment/grpc"
 	"webooktrial/comment/ioc"
 	"webooktrial/comment/repository"
+	"webooktrial/comment/repository/cache"
 	"webooktrial/comment/repository/dao"
 	"webooktrial/comment/service"
-	"webooktrial/pkg/wego"
+	followcli "webooktrial/follow/client"
+	"webooktrial/pkg/grpcx"
 )
 
 // Injectors from wire.go:
 
-func Init() *wego.App {
+func Init() *App {
 	db := ioc.InitDB()
 	commentDAO := dao.NewGORMCommentDAO(db)
+	cmdable := ioc.InitRedis()
+	commentCache := cache.NewRedisCommentCache(cmdable)
+	commentBroadcaster := cache.NewRedisCommentBroadcaster(cmdable)
 	loggerV1 := ioc.InitLogger()
-	commentRepository := repository.NewCommentRepo(commentDAO, loggerV1)
-	commentService := service.NewCommentService(commentRepository)
+	commentRepository := repository.NewCommentRepo(commentDAO, commentCache, commentBroadcaster, loggerV1)
+	etcdClient := grpcx.InitEtcdClient()
+	followServiceClient := ioc.InitFollowClient(etcdClient)
+	blockChecker := followcli.InitBlockChecker(followServiceClient, loggerV1)
+	client := ioc.InitKafka()
+	resolver := ioc.InitBizOwnerResolver(db, client, loggerV1)
+	moderator := ioc.InitModerator(loggerV1)
+	commentHub := ioc.InitCommentHub(commentRepository, loggerV1)
+	syncProducer := ioc.InitSyncProducer(client)
+	producer := events.NewKafkaProducer(syncProducer)
+	commentService := service.NewCommentService(commentRepository, blockChecker, resolver, moderator, commentHub, producer, loggerV1)
 	commentServiceServer := grpc.NewCommentServiceServer(commentService)
 	server := ioc.InitGRPCxServer(commentServiceServer, loggerV1)
-	app := &wego.App{
-		GRPCServer: server,
+	v := ioc.NewConsumers(commentHub)
+	app := &App{
+		server:    server,
+		consumers: v,
 	}
 	return app
 }
 
 // wire.go:
 
-var serviceProviderSet = wire.NewSet(dao.NewGORMCommentDAO, repository.NewCommentRepo, service.NewCommentService, grpc.NewCommentServiceServer)
+var serviceProviderSet = wire.NewSet(dao.NewGORMCommentDAO, cache.NewRedisCommentCache, cache.NewRedisCommentBroadcaster, repository.NewCommentRepo, service.NewCommentService, events.NewKafkaProducer, grpc.NewCommentServiceServer)
 
-var thirdProvider = wire.NewSet(ioc.InitLogger, ioc.InitDB)
+var thirdProvider = wire.NewSet(ioc.InitLogger, ioc.InitDB, ioc.InitRedis, ioc.InitKafka, ioc.InitSyncProducer, grpcx.InitEtcdClient, ioc.InitFollowClient, followcli.InitBlockChecker, ioc.InitBizOwnerResolver, ioc.InitModerator, ioc.InitCommentHub)
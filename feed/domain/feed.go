@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// FeedItem timeline 里面的一条，目前只有文章
+type FeedItem struct {
+	Aid    int64
+	Author int64
+	// Ctime 发表时间
+	Ctime time.Time
+}
@@ -0,0 +1,116 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"webooktrial/feed/domain"
+	"webooktrial/feed/service"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/saramax"
+)
+
+const (
+	topicArticlePublished = "article_published"
+	topicArticleWithdrawn = "article_withdrawn"
+)
+
+// ArticlePublishedConsumer 文章发表之后推送到粉丝的收件箱，重复消费会被忽略
+type ArticlePublishedConsumer struct {
+	client sarama.Client
+	svc    service.FeedService
+	l      logger.LoggerV1
+	cancel context.CancelFunc
+}
+
+func NewArticlePublishedConsumer(client sarama.Client,
+	svc service.FeedService,
+	l logger.LoggerV1) *ArticlePublishedConsumer {
+	return &ArticlePublishedConsumer{
+		client: client,
+		svc:    svc,
+		l:      l,
+	}
+}
+
+func (a *ArticlePublishedConsumer) Start() error {
+	cg, err := sarama.NewConsumerGroupFromClient("feed_article_published", a.client)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	go saramax.ConsumeLoop(ctx, cg, []string{topicArticlePublished},
+		saramax.NewHandler[ArticlePublishedEvent](a.l, a.Consume), a.l)
+	return nil
+}
+
+// Close 停止消费
+func (a *ArticlePublishedConsumer) Close() error {
+	if a.cancel != nil {
+		a.cancel()
+	}
+	return nil
+}
+
+func (a *ArticlePublishedConsumer) Consume(msg *sarama.ConsumerMessage, evt ArticlePublishedEvent) error {
+	// 要一页一页地推送给粉丝，所以时间长一点
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	return a.svc.PublishArticle(ctx, domain.FeedItem{
+		Aid:    evt.Aid,
+		Author: evt.Uid,
+		Ctime:  time.UnixMilli(evt.Utime),
+	})
+}
+
+// ArticleWithdrawnConsumer 文章撤回之后从发件箱和收件箱里面删除
+type ArticleWithdrawnConsumer struct {
+	client sarama.Client
+	svc    service.FeedService
+	l      logger.LoggerV1
+	cancel context.CancelFunc
+}
+
+func NewArticleWithdrawnConsumer(client sarama.Client,
+	svc service.FeedService,
+	l logger.LoggerV1) *ArticleWithdrawnConsumer {
+	return &ArticleWithdrawnConsumer{
+		client: client,
+		svc:    svc,
+		l:      l,
+	}
+}
+
+func (a *ArticleWithdrawnConsumer) Start() error {
+	cg, err := sarama.NewConsumerGroupFromClient("feed_article_withdrawn", a.client)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	go saramax.ConsumeLoop(ctx, cg, []string{topicArticleWithdrawn},
+		saramax.NewHandler[ArticleWithdrawnEvent](a.l, a.Consume), a.l)
+	return nil
+}
+
+// Close 停止消费
+func (a *ArticleWithdrawnConsumer) Close() error {
+	if a.cancel != nil {
+		a.cancel()
+	}
+	return nil
+}
+
+func (a *ArticleWithdrawnConsumer) Consume(msg *sarama.ConsumerMessage, evt ArticleWithdrawnEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	utime := evt.Utime
+	if utime == 0 {
+		// 老版本的事件，用消息的时间凑合
+		utime = msg.Timestamp.UnixMilli()
+	}
+	return a.svc.WithdrawArticle(ctx, evt.Aid, evt.Uid, time.UnixMilli(utime))
+}
@@ -0,0 +1,58 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"webooktrial/feed/service"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/saramax"
+)
+
+const topicFollowCanceled = "follow_canceled"
+
+// FollowCanceledConsumer 取消关注之后把对方的文章从收件箱里面删除
+type FollowCanceledConsumer struct {
+	client sarama.Client
+	svc    service.FeedService
+	l      logger.LoggerV1
+	cancel context.CancelFunc
+}
+
+func NewFollowCanceledConsumer(client sarama.Client,
+	svc service.FeedService,
+	l logger.LoggerV1) *FollowCanceledConsumer {
+	return &FollowCanceledConsumer{
+		client: client,
+		svc:    svc,
+		l:      l,
+	}
+}
+
+func (f *FollowCanceledConsumer) Start() error {
+	cg, err := sarama.NewConsumerGroupFromClient("feed_follow_canceled", f.client)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+	go saramax.ConsumeLoop(ctx, cg, []string{topicFollowCanceled},
+		saramax.NewHandler[FollowCanceledEvent](f.l, f.Consume), f.l)
+	return nil
+}
+
+// Close 停止消费
+func (f *FollowCanceledConsumer) Close() error {
+	if f.cancel != nil {
+		f.cancel()
+	}
+	return nil
+}
+
+func (f *FollowCanceledConsumer) Consume(msg *sarama.ConsumerMessage, evt FollowCanceledEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return f.svc.CancelFollow(ctx, evt.Follower, evt.Followee)
+}
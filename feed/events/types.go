@@ -0,0 +1,24 @@
+package events
+
+// ArticlePublishedEvent 和 internal/events/article.PublishedEvent 保持一致，
+// 只需要其中几个字段
+type ArticlePublishedEvent struct {
+	Aid int64
+	Uid int64
+	// 发表时间，毫秒数
+	Utime int64
+}
+
+// ArticleWithdrawnEvent 和 internal/events/article.WithdrawnEvent 保持一致
+type ArticleWithdrawnEvent struct {
+	Aid int64
+	Uid int64
+	// 撤回时间，毫秒数，老版本没有这个字段
+	Utime int64
+}
+
+// FollowCanceledEvent 和 follow/events.FollowCanceledEvent 保持一致
+type FollowCanceledEvent struct {
+	Follower int64
+	Followee int64
+}
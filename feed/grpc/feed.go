@@ -0,0 +1,60 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/ecodeclub/ekit/slice"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	feedv1 "webooktrial/api/proto/gen/feed/v1"
+	"webooktrial/feed/domain"
+	"webooktrial/feed/service"
+	"webooktrial/pkg/pagination"
+)
+
+// maxLimit 一页最多这么多条
+const maxLimit = 100
+
+type FeedServiceServer struct {
+	feedv1.UnimplementedFeedServiceServer
+	svc service.FeedService
+	// codec timeline 的分页游标
+	codec *pagination.Codec
+}
+
+func NewFeedServiceServer(svc service.FeedService, codec *pagination.Codec) *FeedServiceServer {
+	return &FeedServiceServer{svc: svc, codec: codec}
+}
+
+func (f *FeedServiceServer) Register(server *grpc.Server) {
+	feedv1.RegisterFeedServiceServer(server, f)
+}
+
+func (f *FeedServiceServer) GetTimeline(ctx context.Context, request *feedv1.GetTimelineRequest) (*feedv1.GetTimelineResponse, error) {
+	if request.GetLimit() <= 0 || request.GetLimit() > maxLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit 必须在 1 到 %d 之间", maxLimit)
+	}
+	cursor, err := f.codec.Decode(request.GetCursor())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	items, err := f.svc.GetTimeline(ctx, request.GetUid(), cursor, request.GetLimit())
+	if err != nil {
+		return nil, err
+	}
+	next := pagination.Next(items, int(request.GetLimit()), func(item domain.FeedItem) pagination.Cursor {
+		return pagination.Cursor{Key: item.Ctime.UnixMilli(), Id: item.Aid}
+	})
+	return &feedv1.GetTimelineResponse{
+		Items: slice.Map(items, func(idx int, src domain.FeedItem) *feedv1.FeedItem {
+			return &feedv1.FeedItem{
+				Aid:    src.Aid,
+				Author: src.Author,
+				Ctime:  src.Ctime.UnixMilli(),
+			}
+		}),
+		NextCursor: f.codec.Encode(next),
+	}, nil
+}
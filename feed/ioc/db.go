@@ -0,0 +1,33 @@
+package ioc
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"webooktrial/feed/repository/dao"
+)
+
+func InitDB() *gorm.DB {
+	type Config struct {
+		DSN string `yaml:"dsn"`
+	}
+	c := Config{
+		DSN: "root:root@tcp(localhost:3306)/mysql",
+	}
+	err := viper.UnmarshalKey("db", &c)
+	if err != nil {
+		panic(fmt.Errorf("初始化配置失败 %v, 原因 %w", c, err))
+	}
+	db, err := gorm.Open(mysql.Open(c.DSN), &gorm.Config{})
+	if err != nil {
+		panic(err)
+	}
+	err = dao.InitTables(db)
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
@@ -0,0 +1,17 @@
+package ioc
+
+import (
+	"github.com/spf13/viper"
+
+	"webooktrial/feed/service"
+)
+
+func InitFeedConfig() service.FeedConfig {
+	// 没有配置的字段，NewFeedService 里面会用默认值
+	var cfg service.FeedConfig
+	err := viper.UnmarshalKey("feed", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
@@ -0,0 +1,40 @@
+package ioc
+
+import (
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+
+	grpc2 "webooktrial/feed/grpc"
+	"webooktrial/pkg/grpcx"
+	"webooktrial/pkg/logger"
+)
+
+func InitGRPCxServer(l logger.LoggerV1,
+	feedServer *grpc2.FeedServiceServer) *grpcx.Server {
+	type Config struct {
+		Port      int      `yaml:"port"`
+		EtcdAddrs []string `yaml:"etcdAddrs"`
+		EtcdTTL   int64    `yaml:"etcdTTL"`
+		Weight    int      `yaml:"weight"`
+		Labels    []string `yaml:"labels"`
+		Group     string   `yaml:"group"`
+	}
+	var cfg Config
+	err := viper.UnmarshalKey("grpc.server", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	server := grpc.NewServer()
+	feedServer.Register(server)
+	return &grpcx.Server{
+		Server:    server,
+		Port:      cfg.Port,
+		EtcdAddrs: cfg.EtcdAddrs,
+		EtcdTTL:   cfg.EtcdTTL,
+		Weight:    cfg.Weight,
+		Labels:    cfg.Labels,
+		Group:     cfg.Group,
+		Name:      "feed",
+		L:         l,
+	}
+}
@@ -0,0 +1,36 @@
+package ioc
+
+import (
+	"github.com/IBM/sarama"
+	"github.com/spf13/viper"
+
+	"webooktrial/feed/events"
+	"webooktrial/pkg/saramax"
+)
+
+func InitKafka() sarama.Client {
+	type Config struct {
+		Addrs []string `yaml:"addrs"`
+	}
+	saramaCfg := sarama.NewConfig()
+	var cfg Config
+	err := viper.UnmarshalKey("kafka", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	client, err := sarama.NewClient(cfg.Addrs, saramaCfg)
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
+func NewConsumers(published *events.ArticlePublishedConsumer,
+	withdrawn *events.ArticleWithdrawnConsumer,
+	canceled *events.FollowCanceledConsumer) []saramax.CloseableConsumer {
+	return []saramax.CloseableConsumer{
+		published,
+		withdrawn,
+		canceled,
+	}
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"webooktrial/feed/domain"
+)
+
+var ErrKeyNotExist = redis.Nil
+
+type FeedCache interface {
+	// GetFirstPage timeline 的第一页
+	GetFirstPage(ctx context.Context, uid int64) ([]domain.FeedItem, error)
+	SetFirstPage(ctx context.Context, uid int64, items []domain.FeedItem) error
+	DelFirstPage(ctx context.Context, uids ...int64) error
+
+	// GetPullAuthors 拉模式的作者，每次读 timeline 都要用，所以缓存起来
+	GetPullAuthors(ctx context.Context) ([]int64, error)
+	SetPullAuthors(ctx context.Context, authors []int64) error
+	DelPullAuthors(ctx context.Context) error
+}
+
+type RedisFeedCache struct {
+	client redis.Cmdable
+}
+
+func NewRedisFeedCache(client redis.Cmdable) FeedCache {
+	return &RedisFeedCache{client: client}
+}
+
+func (r *RedisFeedCache) GetFirstPage(ctx context.Context, uid int64) ([]domain.FeedItem, error) {
+	bs, err := r.client.Get(ctx, r.firstPageKey(uid)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var items []domain.FeedItem
+	err = json.Unmarshal(bs, &items)
+	return items, err
+}
+
+func (r *RedisFeedCache) SetFirstPage(ctx context.Context, uid int64, items []domain.FeedItem) error {
+	bs, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	// 拉模式的作者发表了文章不会删除这个缓存，所以过期时间要短
+	return r.client.Set(ctx, r.firstPageKey(uid), bs, time.Minute).Err()
+}
+
+func (r *RedisFeedCache) DelFirstPage(ctx context.Context, uids ...int64) error {
+	if len(uids) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		keys = append(keys, r.firstPageKey(uid))
+	}
+	return r.client.Del(ctx, keys...).Err()
+}
+
+func (r *RedisFeedCache) GetPullAuthors(ctx context.Context) ([]int64, error) {
+	bs, err := r.client.Get(ctx, r.pullAuthorsKey()).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var authors []int64
+	err = json.Unmarshal(bs, &authors)
+	return authors, err
+}
+
+func (r *RedisFeedCache) SetPullAuthors(ctx context.Context, authors []int64) error {
+	bs, err := json.Marshal(authors)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.pullAuthorsKey(), bs, time.Minute*10).Err()
+}
+
+func (r *RedisFeedCache) DelPullAuthors(ctx context.Context) error {
+	return r.client.Del(ctx, r.pullAuthorsKey()).Err()
+}
+
+func (r *RedisFeedCache) firstPageKey(uid int64) string {
+	return fmt.Sprintf("feed:first_page:%d", uid)
+}
+
+func (r *RedisFeedCache) pullAuthorsKey() string {
+	return "feed:pull_authors"
+}
@@ -0,0 +1,186 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"webooktrial/pkg/pagination"
+)
+
+// FeedInbox 收件箱，推模式下作者发表的文章会写到每一个粉丝这里。
+// 典型查询是某个人的 timeline，按照 <ctime, aid> 倒序
+type FeedInbox struct {
+	Id  int64 `gorm:"primaryKey,autoIncrement"`
+	Uid int64 `gorm:"uniqueIndex:uid_aid,priority:1;index:uid_ctime_aid,priority:1;index:uid_author,priority:1"`
+	// 撤回的时候按照文章删除
+	Aid    int64 `gorm:"uniqueIndex:uid_aid,priority:2;index:uid_ctime_aid,priority:3;index:aid"`
+	Author int64 `gorm:"index:uid_author,priority:2"`
+	// 发表时间，毫秒数
+	Ctime int64 `gorm:"index:uid_ctime_aid,priority:2"`
+}
+
+// FeedOutbox 发件箱，每一篇发表的文章都会写一条。
+// 粉丝太多的作者不推送，读 timeline 的时候从这里拉
+type FeedOutbox struct {
+	Id     int64 `gorm:"primaryKey,autoIncrement"`
+	Aid    int64 `gorm:"uniqueIndex"`
+	Author int64 `gorm:"index:author_ctime_aid,priority:1;index:pushed_author,priority:2"`
+	// Pushed 有没有推送到粉丝的收件箱，没有推送的要拉
+	Pushed bool `gorm:"index:pushed_author,priority:1"`
+	// Withdrawn 撤回了。记录不删除，留着 Utime 挡住乱序到达的旧的发表事件
+	Withdrawn bool
+	// 发表时间，毫秒数
+	Ctime int64 `gorm:"index:author_ctime_aid,priority:2"`
+	// Utime 最后处理的发表或者撤回事件的时间，毫秒数，当作版本号用
+	Utime int64
+}
+
+// ErrStaleEvent 事件比已经处理过的旧，发表和撤回是两个 topic，到达的顺序没有保证
+var ErrStaleEvent = errors.New("事件比已经处理过的旧")
+
+type FeedDAO interface {
+	// UpsertOutbox o.Utime 是版本号，比已有的记录旧返回 ErrStaleEvent，版本一样的什么也不做。
+	// 重新发表保留第一次的发表时间，撤回之后再发表用新的发表时间
+	UpsertOutbox(ctx context.Context, o FeedOutbox) error
+	// InsertInboxes 重复的会忽略，所以重试是安全的
+	InsertInboxes(ctx context.Context, inboxes []FeedInbox) error
+	// FindInbox cursor 的 Key 是 ctime，Id 是 aid，零值表示第一页
+	FindInbox(ctx context.Context, uid int64, cursor pagination.Cursor, limit int64) ([]FeedInbox, error)
+	// FindPullOutbox 这些作者没有推送的文章，排序和 cursor 都和 FindInbox 一样
+	FindPullOutbox(ctx context.Context, authors []int64, cursor pagination.Cursor, limit int64) ([]FeedOutbox, error)
+	// PullAuthors 有没有推送的文章的作者，也就是粉丝多的作者
+	PullAuthors(ctx context.Context) ([]int64, error)
+	// Withdraw 发件箱里面标记为撤回，从所有人的收件箱里面删除，返回删除了哪些人的收件箱。
+	// utime 是版本号，比已有的记录旧返回 ErrStaleEvent
+	Withdraw(ctx context.Context, aid, author, utime int64) ([]int64, error)
+	// DeleteInboxByAuthor 把 author 的文章从 uid 的收件箱里面删除
+	DeleteInboxByAuthor(ctx context.Context, uid, author int64) error
+}
+
+type GORMFeedDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMFeedDAO(db *gorm.DB) FeedDAO {
+	return &GORMFeedDAO{db: db}
+}
+
+func (g *GORMFeedDAO) UpsertOutbox(ctx context.Context, o FeedOutbox) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var old FeedOutbox
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("aid = ?", o.Aid).First(&old).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return tx.Create(&o).Error
+		case err != nil:
+			return err
+		case old.Utime > o.Utime:
+			return ErrStaleEvent
+		case old.Utime == o.Utime:
+			// 重复消费
+			return nil
+		}
+		updates := map[string]any{
+			"pushed":    o.Pushed,
+			"withdrawn": false,
+			"utime":     o.Utime,
+		}
+		if old.Withdrawn {
+			updates["ctime"] = o.Ctime
+		}
+		return tx.Model(&FeedOutbox{}).Where("id = ?", old.Id).Updates(updates).Error
+	})
+}
+
+func (g *GORMFeedDAO) InsertInboxes(ctx context.Context, inboxes []FeedInbox) error {
+	if len(inboxes) == 0 {
+		return nil
+	}
+	return g.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&inboxes).Error
+}
+
+func (g *GORMFeedDAO) FindInbox(ctx context.Context, uid int64,
+	cursor pagination.Cursor, limit int64) ([]FeedInbox, error) {
+	var res []FeedInbox
+	db := g.db.WithContext(ctx).Where("uid = ?", uid)
+	if !cursor.IsZero() {
+		db = db.Where("(ctime < ? OR (ctime = ? AND aid < ?))", cursor.Key, cursor.Key, cursor.Id)
+	}
+	err := db.Order("ctime DESC, aid DESC").Limit(int(limit)).Find(&res).Error
+	return res, err
+}
+
+func (g *GORMFeedDAO) FindPullOutbox(ctx context.Context, authors []int64,
+	cursor pagination.Cursor, limit int64) ([]FeedOutbox, error) {
+	var res []FeedOutbox
+	if len(authors) == 0 {
+		return res, nil
+	}
+	db := g.db.WithContext(ctx).Where("author IN ? AND pushed = ? AND withdrawn = ?", authors, false, false)
+	if !cursor.IsZero() {
+		db = db.Where("(ctime < ? OR (ctime = ? AND aid < ?))", cursor.Key, cursor.Key, cursor.Id)
+	}
+	err := db.Order("ctime DESC, aid DESC").Limit(int(limit)).Find(&res).Error
+	return res, err
+}
+
+func (g *GORMFeedDAO) PullAuthors(ctx context.Context) ([]int64, error) {
+	var res []int64
+	err := g.db.WithContext(ctx).Model(&FeedOutbox{}).
+		Where("pushed = ? AND withdrawn = ?", false, false).
+		Distinct("author").
+		Pluck("author", &res).Error
+	return res, err
+}
+
+func (g *GORMFeedDAO) Withdraw(ctx context.Context, aid, author, utime int64) ([]int64, error) {
+	var uids []int64
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var old FeedOutbox
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("aid = ?", aid).First(&old).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			// 撤回事件先到了，留一条撤回的记录挡住后到的发表事件
+			return tx.Create(&FeedOutbox{
+				Aid:       aid,
+				Author:    author,
+				Withdrawn: true,
+				Utime:     utime,
+			}).Error
+		case err != nil:
+			return err
+		case old.Utime > utime:
+			return ErrStaleEvent
+		}
+		err = tx.Model(&FeedOutbox{}).Where("id = ?", old.Id).
+			Updates(map[string]any{
+				"withdrawn": true,
+				"utime":     utime,
+			}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&FeedInbox{}).Where("aid = ?", aid).Pluck("uid", &uids).Error
+		if err != nil || len(uids) == 0 {
+			return err
+		}
+		return tx.Where("aid = ?", aid).Delete(&FeedInbox{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return uids, nil
+}
+
+func (g *GORMFeedDAO) DeleteInboxByAuthor(ctx context.Context, uid, author int64) error {
+	return g.db.WithContext(ctx).
+		Where("uid = ? AND author = ?", uid, author).
+		Delete(&FeedInbox{}).Error
+}
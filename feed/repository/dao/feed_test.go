@@ -0,0 +1,205 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestGORMFeedDAO_UpsertOutbox(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(t *testing.T) *sql.DB
+
+		wantErr error
+	}{
+		{
+			name: "第一次发表",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `feed_outboxes` WHERE aid = \\? .* FOR UPDATE").
+					WithArgs(int64(10)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectExec("INSERT INTO `feed_outboxes`").
+					WithArgs(int64(10), int64(1), true, false, int64(200), int64(200)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				return db
+			},
+		},
+		{
+			name: "撤回之后重新发表，用新的发表时间",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `feed_outboxes` WHERE aid = \\? .* FOR UPDATE").
+					WithArgs(int64(10)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "aid", "withdrawn", "ctime", "utime"}).
+						AddRow(1, 10, true, 100, 150))
+				mock.ExpectExec("UPDATE `feed_outboxes` SET `ctime`=\\?,`pushed`=\\?,`utime`=\\?,`withdrawn`=\\? WHERE id = \\?").
+					WithArgs(int64(200), true, int64(200), false, int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+		},
+		{
+			name: "重复消费",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `feed_outboxes` WHERE aid = \\? .* FOR UPDATE").
+					WithArgs(int64(10)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "aid", "ctime", "utime"}).
+						AddRow(1, 10, 200, 200))
+				mock.ExpectCommit()
+				return db
+			},
+		},
+		{
+			name: "撤回事件比发表事件新",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `feed_outboxes` WHERE aid = \\? .* FOR UPDATE").
+					WithArgs(int64(10)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "aid", "withdrawn", "utime"}).
+						AddRow(1, 10, true, 300))
+				mock.ExpectRollback()
+				return db
+			},
+			wantErr: ErrStaleEvent,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewGORMFeedDAO(openDB(t, tc.mock(t)))
+			err := d.UpsertOutbox(context.Background(), FeedOutbox{
+				Aid: 10, Author: 1, Pushed: true, Ctime: 200, Utime: 200,
+			})
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestGORMFeedDAO_Withdraw(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(t *testing.T) *sql.DB
+
+		wantErr  error
+		wantUids []int64
+	}{
+		{
+			name: "撤回并且删除收件箱",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `feed_outboxes` WHERE aid = \\? .* FOR UPDATE").
+					WithArgs(int64(10)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "aid", "ctime", "utime"}).
+						AddRow(1, 10, 200, 200))
+				mock.ExpectExec("UPDATE `feed_outboxes` SET `utime`=\\?,`withdrawn`=\\? WHERE id = \\?").
+					WithArgs(int64(300), true, int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT `uid` FROM `feed_inboxes` WHERE aid = \\?").
+					WithArgs(int64(10)).
+					WillReturnRows(sqlmock.NewRows([]string{"uid"}).AddRow(2).AddRow(3))
+				mock.ExpectExec("DELETE FROM `feed_inboxes` WHERE aid = \\?").
+					WithArgs(int64(10)).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+				return db
+			},
+			wantUids: []int64{2, 3},
+		},
+		{
+			name: "撤回事件先到，留下撤回的记录",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `feed_outboxes` WHERE aid = \\? .* FOR UPDATE").
+					WithArgs(int64(10)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectExec("INSERT INTO `feed_outboxes`").
+					WithArgs(int64(10), int64(1), false, true, int64(0), int64(300)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				return db
+			},
+		},
+		{
+			name: "重新发表事件比撤回事件新",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `feed_outboxes` WHERE aid = \\? .* FOR UPDATE").
+					WithArgs(int64(10)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "aid", "ctime", "utime"}).
+						AddRow(1, 10, 400, 400))
+				mock.ExpectRollback()
+				return db
+			},
+			wantErr: ErrStaleEvent,
+		},
+		{
+			name: "删除收件箱失败，回滚",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `feed_outboxes` WHERE aid = \\? .* FOR UPDATE").
+					WithArgs(int64(10)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "aid", "ctime", "utime"}).
+						AddRow(1, 10, 200, 200))
+				mock.ExpectExec("UPDATE `feed_outboxes`").
+					WithArgs(int64(300), true, int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT `uid` FROM `feed_inboxes`").
+					WithArgs(int64(10)).
+					WillReturnRows(sqlmock.NewRows([]string{"uid"}).AddRow(2))
+				mock.ExpectExec("DELETE FROM `feed_inboxes`").
+					WithArgs(int64(10)).
+					WillReturnError(errors.New("db 错误"))
+				mock.ExpectRollback()
+				return db
+			},
+			wantErr: errors.New("db 错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewGORMFeedDAO(openDB(t, tc.mock(t)))
+			uids, err := d.Withdraw(context.Background(), 10, 1, 300)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantUids, uids)
+		})
+	}
+}
+
+func openDB(t *testing.T, conn *sql.DB) *gorm.DB {
+	db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+		Conn:                      conn,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	return db
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"webooktrial/feed/domain"
+	"webooktrial/feed/repository/cache"
+	"webooktrial/feed/repository/dao"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/pagination"
+)
+
+//go:generate mockgen -source=./feed.go -package=repomocks -destination=mocks/feed.mock.go FeedRepository
+type FeedRepository interface {
+	// AddOutbox pushed 表示会不会推送到粉丝的收件箱。
+	// 发表时间就是版本号，比已经处理过的事件旧返回 ErrStaleEvent
+	AddOutbox(ctx context.Context, item domain.FeedItem, pushed bool) error
+	// AddInbox 推送到这些人的收件箱
+	AddInbox(ctx context.Context, uids []int64, item domain.FeedItem) error
+	// FindInbox cursor 的 Key 是发表时间的毫秒数，Id 是文章 ID，零值表示第一页
+	FindInbox(ctx context.Context, uid int64, cursor pagination.Cursor, limit int64) ([]domain.FeedItem, error)
+	// FindPullOutbox 这些作者没有推送的文章，cursor 和 FindInbox 一样
+	FindPullOutbox(ctx context.Context, authors []int64, cursor pagination.Cursor, limit int64) ([]domain.FeedItem, error)
+	// PullAuthors 拉模式的作者
+	PullAuthors(ctx context.Context) ([]int64, error)
+	// DeleteArticle 文章撤回了，哪里都删掉。
+	// utime 是撤回时间，比已经处理过的事件旧返回 ErrStaleEvent
+	DeleteArticle(ctx context.Context, aid, author int64, utime time.Time) error
+	// DeleteInboxByAuthor 取消关注了，把 author 的文章从 uid 的收件箱里面删掉
+	DeleteInboxByAuthor(ctx context.Context, uid, author int64) error
+
+	// GetFirstPage 缓存的 timeline 第一页
+	GetFirstPage(ctx context.Context, uid int64) ([]domain.FeedItem, error)
+	SetFirstPage(ctx context.Context, uid int64, items []domain.FeedItem) error
+}
+
+// ErrStaleEvent 发表和撤回事件乱序到达，旧的那个要丢掉
+var ErrStaleEvent = dao.ErrStaleEvent
+
+// delFirstPageBatch 一次最多删除这么多个人的第一页缓存
+const delFirstPageBatch = 500
+
+type CachedFeedRepository struct {
+	dao   dao.FeedDAO
+	cache cache.FeedCache
+	l     logger.LoggerV1
+}
+
+func NewCachedFeedRepository(dao dao.FeedDAO, cache cache.FeedCache, l logger.LoggerV1) FeedRepository {
+	return &CachedFeedRepository{dao: dao, cache: cache, l: l}
+}
+
+func (c *CachedFeedRepository) AddOutbox(ctx context.Context, item domain.FeedItem, pushed bool) error {
+	err := c.dao.UpsertOutbox(ctx, dao.FeedOutbox{
+		Aid:    item.Aid,
+		Author: item.Author,
+		Pushed: pushed,
+		Ctime:  item.Ctime.UnixMilli(),
+		Utime:  item.Ctime.UnixMilli(),
+	})
+	if err == nil && !pushed {
+		// 可能多了一个拉模式的作者
+		er := c.cache.DelPullAuthors(ctx)
+		if er != nil {
+			c.l.Error("删除拉模式作者的缓存失败", logger.Error(er))
+		}
+	}
+	return err
+}
+
+func (c *CachedFeedRepository) AddInbox(ctx context.Context, uids []int64, item domain.FeedItem) error {
+	inboxes := make([]dao.FeedInbox, 0, len(uids))
+	for _, uid := range uids {
+		inboxes = append(inboxes, dao.FeedInbox{
+			Uid:    uid,
+			Aid:    item.Aid,
+			Author: item.Author,
+			Ctime:  item.Ctime.UnixMilli(),
+		})
+	}
+	err := c.dao.InsertInboxes(ctx, inboxes)
+	if err == nil {
+		c.delFirstPage(ctx, uids...)
+	}
+	return err
+}
+
+func (c *CachedFeedRepository) FindInbox(ctx context.Context, uid int64,
+	cursor pagination.Cursor, limit int64) ([]domain.FeedItem, error) {
+	res, err := c.dao.FindInbox(ctx, uid, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]domain.FeedItem, 0, len(res))
+	for _, src := range res {
+		items = append(items, domain.FeedItem{
+			Aid:    src.Aid,
+			Author: src.Author,
+			Ctime:  time.UnixMilli(src.Ctime),
+		})
+	}
+	return items, nil
+}
+
+func (c *CachedFeedRepository) FindPullOutbox(ctx context.Context, authors []int64,
+	cursor pagination.Cursor, limit int64) ([]domain.FeedItem, error) {
+	res, err := c.dao.FindPullOutbox(ctx, authors, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]domain.FeedItem, 0, len(res))
+	for _, src := range res {
+		items = append(items, domain.FeedItem{
+			Aid:    src.Aid,
+			Author: src.Author,
+			Ctime:  time.UnixMilli(src.Ctime),
+		})
+	}
+	return items, nil
+}
+
+func (c *CachedFeedRepository) PullAuthors(ctx context.Context) ([]int64, error) {
+	authors, err := c.cache.GetPullAuthors(ctx)
+	if err == nil {
+		return authors, nil
+	}
+	authors, err = c.dao.PullAuthors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	er := c.cache.SetPullAuthors(ctx, authors)
+	if er != nil {
+		c.l.Error("回写拉模式作者的缓存失败", logger.Error(er))
+	}
+	return authors, nil
+}
+
+func (c *CachedFeedRepository) DeleteArticle(ctx context.Context, aid, author int64, utime time.Time) error {
+	uids, err := c.dao.Withdraw(ctx, aid, author, utime.UnixMilli())
+	if err != nil {
+		return err
+	}
+	// 推模式的删掉收件箱里面有这篇文章的人的第一页。
+	// 拉模式的不知道是哪些人，等缓存过期，第一页缓存只有一分钟
+	for start := 0; start < len(uids); start += delFirstPageBatch {
+		c.delFirstPage(ctx, uids[start:min(start+delFirstPageBatch, len(uids))]...)
+	}
+	return nil
+}
+
+func (c *CachedFeedRepository) DeleteInboxByAuthor(ctx context.Context, uid, author int64) error {
+	err := c.dao.DeleteInboxByAuthor(ctx, uid, author)
+	if err == nil {
+		c.delFirstPage(ctx, uid)
+	}
+	return err
+}
+
+func (c *CachedFeedRepository) GetFirstPage(ctx context.Context, uid int64) ([]domain.FeedItem, error) {
+	return c.cache.GetFirstPage(ctx, uid)
+}
+
+func (c *CachedFeedRepository) SetFirstPage(ctx context.Context, uid int64, items []domain.FeedItem) error {
+	return c.cache.SetFirstPage(ctx, uid, items)
+}
+
+func (c *CachedFeedRepository) delFirstPage(ctx context.Context, uids ...int64) {
+	err := c.cache.DelFirstPage(ctx, uids...)
+	if err != nil {
+		c.l.Error("删除 timeline 第一页缓存失败", logger.Error(err))
+	}
+}
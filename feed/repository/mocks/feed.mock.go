@@ -0,0 +1,172 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./feed.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./feed.go -package=repomocks -destination=mocks/feed.mock.go FeedRepository
+//
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	domain "webooktrial/feed/domain"
+	pagination "webooktrial/pkg/pagination"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFeedRepository is a mock of FeedRepository interface.
+type MockFeedRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedRepositoryMockRecorder
+}
+
+// MockFeedRepositoryMockRecorder is the mock recorder for MockFeedRepository.
+type MockFeedRepositoryMockRecorder struct {
+	mock *MockFeedRepository
+}
+
+// NewMockFeedRepository creates a new mock instance.
+func NewMockFeedRepository(ctrl *gomock.Controller) *MockFeedRepository {
+	mock := &MockFeedRepository{ctrl: ctrl}
+	mock.recorder = &MockFeedRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeedRepository) EXPECT() *MockFeedRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddInbox mocks base method.
+func (m *MockFeedRepository) AddInbox(ctx context.Context, uids []int64, item domain.FeedItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddInbox", ctx, uids, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddInbox indicates an expected call of AddInbox.
+func (mr *MockFeedRepositoryMockRecorder) AddInbox(ctx, uids, item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddInbox", reflect.TypeOf((*MockFeedRepository)(nil).AddInbox), ctx, uids, item)
+}
+
+// AddOutbox mocks base method.
+func (m *MockFeedRepository) AddOutbox(ctx context.Context, item domain.FeedItem, pushed bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddOutbox", ctx, item, pushed)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddOutbox indicates an expected call of AddOutbox.
+func (mr *MockFeedRepositoryMockRecorder) AddOutbox(ctx, item, pushed any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOutbox", reflect.TypeOf((*MockFeedRepository)(nil).AddOutbox), ctx, item, pushed)
+}
+
+// DeleteArticle mocks base method.
+func (m *MockFeedRepository) DeleteArticle(ctx context.Context, aid, author int64, utime time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteArticle", ctx, aid, author, utime)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteArticle indicates an expected call of DeleteArticle.
+func (mr *MockFeedRepositoryMockRecorder) DeleteArticle(ctx, aid, author, utime any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteArticle", reflect.TypeOf((*MockFeedRepository)(nil).DeleteArticle), ctx, aid, author, utime)
+}
+
+// DeleteInboxByAuthor mocks base method.
+func (m *MockFeedRepository) DeleteInboxByAuthor(ctx context.Context, uid, author int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteInboxByAuthor", ctx, uid, author)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteInboxByAuthor indicates an expected call of DeleteInboxByAuthor.
+func (mr *MockFeedRepositoryMockRecorder) DeleteInboxByAuthor(ctx, uid, author any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteInboxByAuthor", reflect.TypeOf((*MockFeedRepository)(nil).DeleteInboxByAuthor), ctx, uid, author)
+}
+
+// FindInbox mocks base method.
+func (m *MockFeedRepository) FindInbox(ctx context.Context, uid int64, cursor pagination.Cursor, limit int64) ([]domain.FeedItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindInbox", ctx, uid, cursor, limit)
+	ret0, _ := ret[0].([]domain.FeedItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindInbox indicates an expected call of FindInbox.
+func (mr *MockFeedRepositoryMockRecorder) FindInbox(ctx, uid, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindInbox", reflect.TypeOf((*MockFeedRepository)(nil).FindInbox), ctx, uid, cursor, limit)
+}
+
+// FindPullOutbox mocks base method.
+func (m *MockFeedRepository) FindPullOutbox(ctx context.Context, authors []int64, cursor pagination.Cursor, limit int64) ([]domain.FeedItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPullOutbox", ctx, authors, cursor, limit)
+	ret0, _ := ret[0].([]domain.FeedItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPullOutbox indicates an expected call of FindPullOutbox.
+func (mr *MockFeedRepositoryMockRecorder) FindPullOutbox(ctx, authors, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPullOutbox", reflect.TypeOf((*MockFeedRepository)(nil).FindPullOutbox), ctx, authors, cursor, limit)
+}
+
+// GetFirstPage mocks base method.
+func (m *MockFeedRepository) GetFirstPage(ctx context.Context, uid int64) ([]domain.FeedItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFirstPage", ctx, uid)
+	ret0, _ := ret[0].([]domain.FeedItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFirstPage indicates an expected call of GetFirstPage.
+func (mr *MockFeedRepositoryMockRecorder) GetFirstPage(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFirstPage", reflect.TypeOf((*MockFeedRepository)(nil).GetFirstPage), ctx, uid)
+}
+
+// PullAuthors mocks base method.
+func (m *MockFeedRepository) PullAuthors(ctx context.Context) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PullAuthors", ctx)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PullAuthors indicates an expected call of PullAuthors.
+func (mr *MockFeedRepositoryMockRecorder) PullAuthors(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PullAuthors", reflect.TypeOf((*MockFeedRepository)(nil).PullAuthors), ctx)
+}
+
+// SetFirstPage mocks base method.
+func (m *MockFeedRepository) SetFirstPage(ctx context.Context, uid int64, items []domain.FeedItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFirstPage", ctx, uid, items)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetFirstPage indicates an expected call of SetFirstPage.
+func (mr *MockFeedRepositoryMockRecorder) SetFirstPage(ctx, uid, items any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFirstPage", reflect.TypeOf((*MockFeedRepository)(nil).SetFirstPage), ctx, uid, items)
+}
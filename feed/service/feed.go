@@ -0,0 +1,258 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	followv1 "webooktrial/api/proto/gen/follow/v1"
+	"webooktrial/feed/domain"
+	"webooktrial/feed/repository"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/pagination"
+)
+
+const (
+	// firstPageSize 缓存的第一页的大小，超过这个 limit 的不走缓存
+	firstPageSize = 50
+	// fanoutBatch 推送的时候每次查询这么多个粉丝，写一批收件箱
+	fanoutBatch = 500
+	// followeeBatch 读 timeline 的时候每次查询这么多个关注的人
+	followeeBatch = 500
+)
+
+type FeedConfig struct {
+	// PushThreshold 粉丝数超过这个值的作者不推送，读的时候拉
+	PushThreshold int64 `yaml:"pushThreshold"`
+}
+
+type FeedService interface {
+	// PublishArticle 粉丝不多的作者推送到每个粉丝的收件箱，
+	// 粉丝多的写发件箱，只推送给特别关注了作者的粉丝
+	PublishArticle(ctx context.Context, item domain.FeedItem) error
+	// WithdrawArticle 文章撤回了，从发件箱和收件箱里面删掉。
+	// utime 是撤回时间，比它新的发表事件已经处理过了就什么也不做
+	WithdrawArticle(ctx context.Context, aid, author int64, utime time.Time) error
+	// CancelFollow 把 followee 的文章从 follower 的收件箱里面删掉
+	CancelFollow(ctx context.Context, follower, followee int64) error
+	// GetTimeline 收件箱和拉模式的作者的文章合并在一起，按照发表时间倒序。
+	// cursor 的 Key 是发表时间的毫秒数，Id 是文章 ID，零值表示第一页
+	GetTimeline(ctx context.Context, uid int64, cursor pagination.Cursor, limit int64) ([]domain.FeedItem, error)
+}
+
+type feedService struct {
+	repo         repository.FeedRepository
+	followClient followv1.FollowServiceClient
+	cfg          FeedConfig
+	l            logger.LoggerV1
+}
+
+func NewFeedService(repo repository.FeedRepository,
+	followClient followv1.FollowServiceClient,
+	cfg FeedConfig, l logger.LoggerV1) FeedService {
+	if cfg.PushThreshold <= 0 {
+		cfg.PushThreshold = 5000
+	}
+	return &feedService{repo: repo, followClient: followClient, cfg: cfg, l: l}
+}
+
+func (s *feedService) PublishArticle(ctx context.Context, item domain.FeedItem) error {
+	statics, err := s.followClient.GetFollowStatics(ctx, &followv1.GetFollowStaticsRequest{
+		Uid: item.Author,
+	})
+	if err != nil {
+		return err
+	}
+	pushed := statics.GetFollowers() <= s.cfg.PushThreshold
+	err = s.repo.AddOutbox(ctx, item, pushed)
+	if errors.Is(err, repository.ErrStaleEvent) {
+		s.l.Warn("文章已经撤回，忽略旧的发表事件", logger.Int64("aid", item.Aid))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if pushed {
+		return s.fanout(ctx, item, s.followers)
+	}
+	// 特别关注的人要马上看到，拉模式的作者也推送给他们
+	return s.fanout(ctx, item, s.specialFollowers)
+}
+
+// followerPage 查询 followee 的一页粉丝，返回下一页的 cursor，为空说明没有下一页了
+type followerPage func(ctx context.Context, followee int64, cursor string) ([]*followv1.FollowRelation, string, error)
+
+func (s *feedService) followers(ctx context.Context, followee int64,
+	cursor string) ([]*followv1.FollowRelation, string, error) {
+	resp, err := s.followClient.GetFollower(ctx, &followv1.GetFollowerRequest{
+		Followee: followee,
+		Limit:    fanoutBatch,
+		Cursor:   cursor,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return resp.GetFollowRelations(), resp.GetNextCursor(), nil
+}
+
+func (s *feedService) specialFollowers(ctx context.Context, followee int64,
+	cursor string) ([]*followv1.FollowRelation, string, error) {
+	resp, err := s.followClient.GetSpecialFollower(ctx, &followv1.GetSpecialFollowerRequest{
+		Followee: followee,
+		Limit:    fanoutBatch,
+		Cursor:   cursor,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return resp.GetFollowRelations(), resp.GetNextCursor(), nil
+}
+
+// fanout 一页一页地查粉丝，写到他们的收件箱。
+// 中途失败了重试的时候会从头开始，已经写过的会被忽略
+func (s *feedService) fanout(ctx context.Context, item domain.FeedItem, page followerPage) error {
+	cursor := ""
+	for {
+		relations, next, err := page(ctx, item.Author, cursor)
+		if err != nil {
+			return err
+		}
+		uids := make([]int64, 0, len(relations))
+		for _, r := range relations {
+			uids = append(uids, r.GetFollower())
+		}
+		err = s.repo.AddInbox(ctx, uids, item)
+		if err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+func (s *feedService) WithdrawArticle(ctx context.Context, aid, author int64, utime time.Time) error {
+	err := s.repo.DeleteArticle(ctx, aid, author, utime)
+	if errors.Is(err, repository.ErrStaleEvent) {
+		s.l.Warn("文章又发表了，忽略旧的撤回事件", logger.Int64("aid", aid))
+		return nil
+	}
+	return err
+}
+
+func (s *feedService) CancelFollow(ctx context.Context, follower, followee int64) error {
+	return s.repo.DeleteInboxByAuthor(ctx, follower, followee)
+}
+
+func (s *feedService) GetTimeline(ctx context.Context, uid int64,
+	cursor pagination.Cursor, limit int64) ([]domain.FeedItem, error) {
+	if !cursor.IsZero() || limit > firstPageSize {
+		return s.timeline(ctx, uid, cursor, limit)
+	}
+	items, err := s.repo.GetFirstPage(ctx, uid)
+	// 缓存的不满一页，说明全部都在这里了
+	if err == nil && (int64(len(items)) >= limit || len(items) < firstPageSize) {
+		return items[:min(int64(len(items)), limit)], nil
+	}
+	items, err = s.timeline(ctx, uid, cursor, firstPageSize)
+	if err != nil {
+		return nil, err
+	}
+	er := s.repo.SetFirstPage(ctx, uid, items)
+	if er != nil {
+		s.l.Error("回写 timeline 第一页缓存失败", logger.Int64("uid", uid), logger.Error(er))
+	}
+	return items[:min(int64(len(items)), limit)], nil
+}
+
+// timeline 两边都最多取 limit 条，合并之后的前 limit 条一定是对的
+func (s *feedService) timeline(ctx context.Context, uid int64,
+	cursor pagination.Cursor, limit int64) ([]domain.FeedItem, error) {
+	var (
+		eg     errgroup.Group
+		inbox  []domain.FeedItem
+		pulled []domain.FeedItem
+	)
+	eg.Go(func() error {
+		var err error
+		inbox, err = s.repo.FindInbox(ctx, uid, cursor, limit)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		pulled, err = s.findPulled(ctx, uid, cursor, limit)
+		return err
+	})
+	err := eg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	return mergeItems(inbox, pulled, limit), nil
+}
+
+// findPulled uid 关注了的拉模式作者的文章。
+// 翻 uid 的关注列表和拉模式的作者取交集，一般人关注的人比拉模式的作者少得多
+func (s *feedService) findPulled(ctx context.Context, uid int64,
+	cursor pagination.Cursor, limit int64) ([]domain.FeedItem, error) {
+	authors, err := s.repo.PullAuthors(ctx)
+	if err != nil || len(authors) == 0 {
+		return nil, err
+	}
+	pullAuthors := make(map[int64]struct{}, len(authors))
+	for _, author := range authors {
+		pullAuthors[author] = struct{}{}
+	}
+	var (
+		followed []int64
+		next     string
+	)
+	for {
+		resp, err := s.followClient.GetFollowee(ctx, &followv1.GetFolloweeRequest{
+			Follower: uid,
+			Limit:    followeeBatch,
+			Cursor:   next,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range resp.GetFollowRelations() {
+			if _, ok := pullAuthors[r.GetFollowee()]; ok {
+				followed = append(followed, r.GetFollowee())
+			}
+		}
+		if resp.GetNextCursor() == "" {
+			break
+		}
+		next = resp.GetNextCursor()
+	}
+	if len(followed) == 0 {
+		return nil, nil
+	}
+	return s.repo.FindPullOutbox(ctx, followed, cursor, limit)
+}
+
+// mergeItems 两边都是按照 <ctime, aid> 倒序的。
+// 作者从推模式变成拉模式之前的文章可能两边都有，按照 aid 去重
+func mergeItems(inbox, pulled []domain.FeedItem, limit int64) []domain.FeedItem {
+	res := make([]domain.FeedItem, 0, len(inbox)+len(pulled))
+	res = append(res, inbox...)
+	res = append(res, pulled...)
+	slices.SortFunc(res, func(a, b domain.FeedItem) int {
+		if c := b.Ctime.Compare(a.Ctime); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Aid, a.Aid)
+	})
+	res = slices.CompactFunc(res, func(a, b domain.FeedItem) bool {
+		return a.Aid == b.Aid
+	})
+	if int64(len(res)) > limit {
+		res = res[:limit]
+	}
+	return res
+}
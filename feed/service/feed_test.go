@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	followv1 "webooktrial/api/proto/gen/follow/v1"
+	followmocks "webooktrial/api/proto/gen/follow/v1/mocks"
+	"webooktrial/feed/domain"
+	"webooktrial/feed/repository"
+	repomocks "webooktrial/feed/repository/mocks"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/pagination"
+)
+
+func TestFeedService_PublishArticle(t *testing.T) {
+	item := domain.FeedItem{Aid: 10, Author: 1, Ctime: time.UnixMilli(1700000000000)}
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.FeedRepository, followv1.FollowServiceClient)
+
+		wantErr error
+	}{
+		{
+			name: "粉丝少，分页推送",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository, followv1.FollowServiceClient) {
+				client := followmocks.NewMockFollowServiceClient(ctrl)
+				client.EXPECT().GetFollowStatics(gomock.Any(), &followv1.GetFollowStaticsRequest{Uid: 1}).
+					Return(&followv1.GetFollowStaticsResponse{Followers: 3}, nil)
+				client.EXPECT().GetFollower(gomock.Any(), &followv1.GetFollowerRequest{
+					Followee: 1, Limit: fanoutBatch,
+				}).Return(&followv1.GetFollowerResponse{
+					FollowRelations: []*followv1.FollowRelation{{Follower: 2}, {Follower: 3}},
+					NextCursor:      "next",
+				}, nil)
+				client.EXPECT().GetFollower(gomock.Any(), &followv1.GetFollowerRequest{
+					Followee: 1, Limit: fanoutBatch, Cursor: "next",
+				}).Return(&followv1.GetFollowerResponse{
+					FollowRelations: []*followv1.FollowRelation{{Follower: 4}},
+				}, nil)
+				repo := repomocks.NewMockFeedRepository(ctrl)
+				repo.EXPECT().AddOutbox(gomock.Any(), item, true).Return(nil)
+				repo.EXPECT().AddInbox(gomock.Any(), []int64{2, 3}, item).Return(nil)
+				repo.EXPECT().AddInbox(gomock.Any(), []int64{4}, item).Return(nil)
+				return repo, client
+			},
+		},
+		{
+			name: "粉丝多，写发件箱，只推送给特别关注的粉丝",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository, followv1.FollowServiceClient) {
+				client := followmocks.NewMockFollowServiceClient(ctrl)
+				client.EXPECT().GetFollowStatics(gomock.Any(), gomock.Any()).
+					Return(&followv1.GetFollowStaticsResponse{Followers: 101}, nil)
+				client.EXPECT().GetSpecialFollower(gomock.Any(), &followv1.GetSpecialFollowerRequest{
+					Followee: 1, Limit: fanoutBatch,
+				}).Return(&followv1.GetSpecialFollowerResponse{
+					FollowRelations: []*followv1.FollowRelation{{Follower: 5}},
+				}, nil)
+				repo := repomocks.NewMockFeedRepository(ctrl)
+				repo.EXPECT().AddOutbox(gomock.Any(), item, false).Return(nil)
+				repo.EXPECT().AddInbox(gomock.Any(), []int64{5}, item).Return(nil)
+				return repo, client
+			},
+		},
+		{
+			name: "文章已经撤回了，不推送",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository, followv1.FollowServiceClient) {
+				client := followmocks.NewMockFollowServiceClient(ctrl)
+				client.EXPECT().GetFollowStatics(gomock.Any(), gomock.Any()).
+					Return(&followv1.GetFollowStaticsResponse{Followers: 3}, nil)
+				repo := repomocks.NewMockFeedRepository(ctrl)
+				repo.EXPECT().AddOutbox(gomock.Any(), item, true).Return(repository.ErrStaleEvent)
+				return repo, client
+			},
+		},
+		{
+			name: "查询粉丝数失败",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository, followv1.FollowServiceClient) {
+				client := followmocks.NewMockFollowServiceClient(ctrl)
+				client.EXPECT().GetFollowStatics(gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("follow 挂了"))
+				return repomocks.NewMockFeedRepository(ctrl), client
+			},
+			wantErr: errors.New("follow 挂了"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, client := tc.mock(ctrl)
+			svc := NewFeedService(repo, client, FeedConfig{PushThreshold: 100}, logger.NewNopLogger())
+			err := svc.PublishArticle(context.Background(), item)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestFeedService_GetTimeline(t *testing.T) {
+	at := func(aid, author, ms int64) domain.FeedItem {
+		return domain.FeedItem{Aid: aid, Author: author, Ctime: time.UnixMilli(ms)}
+	}
+	cursor := pagination.Cursor{Key: 1000, Id: 9}
+	testCases := []struct {
+		name   string
+		mock   func(ctrl *gomock.Controller) (repository.FeedRepository, followv1.FollowServiceClient)
+		cursor pagination.Cursor
+		limit  int64
+
+		wantItems []domain.FeedItem
+		wantErr   error
+	}{
+		{
+			name: "合并收件箱和拉模式作者的文章",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository, followv1.FollowServiceClient) {
+				repo := repomocks.NewMockFeedRepository(ctrl)
+				repo.EXPECT().FindInbox(gomock.Any(), int64(1), cursor, int64(3)).
+					Return([]domain.FeedItem{at(8, 2, 900), at(5, 2, 700)}, nil)
+				// 自己是拉模式的作者，不会出现在自己的 timeline 里面
+				repo.EXPECT().PullAuthors(gomock.Any()).Return([]int64{1, 3, 4, 5}, nil)
+				repo.EXPECT().FindPullOutbox(gomock.Any(), []int64{3, 5}, cursor, int64(3)).
+					Return([]domain.FeedItem{at(7, 3, 900), at(6, 3, 800)}, nil)
+				client := followmocks.NewMockFollowServiceClient(ctrl)
+				client.EXPECT().GetFollowee(gomock.Any(), &followv1.GetFolloweeRequest{
+					Follower: 1, Limit: followeeBatch,
+				}).Return(&followv1.GetFolloweeResponse{
+					FollowRelations: []*followv1.FollowRelation{{Followee: 2}, {Followee: 3}},
+					NextCursor:      "next",
+				}, nil)
+				client.EXPECT().GetFollowee(gomock.Any(), &followv1.GetFolloweeRequest{
+					Follower: 1, Limit: followeeBatch, Cursor: "next",
+				}).Return(&followv1.GetFolloweeResponse{
+					FollowRelations: []*followv1.FollowRelation{{Followee: 5}},
+				}, nil)
+				return repo, client
+			},
+			cursor:    cursor,
+			limit:     3,
+			wantItems: []domain.FeedItem{at(8, 2, 900), at(7, 3, 900), at(6, 3, 800)},
+		},
+		{
+			name: "第一页命中缓存",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository, followv1.FollowServiceClient) {
+				repo := repomocks.NewMockFeedRepository(ctrl)
+				repo.EXPECT().GetFirstPage(gomock.Any(), int64(1)).
+					Return([]domain.FeedItem{at(8, 2, 900), at(5, 2, 700)}, nil)
+				return repo, followmocks.NewMockFollowServiceClient(ctrl)
+			},
+			limit:     1,
+			wantItems: []domain.FeedItem{at(8, 2, 900)},
+		},
+		{
+			name: "第一页没有缓存，按照缓存的大小查询并回写",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository, followv1.FollowServiceClient) {
+				repo := repomocks.NewMockFeedRepository(ctrl)
+				repo.EXPECT().GetFirstPage(gomock.Any(), int64(1)).
+					Return(nil, errors.New("缓存没有"))
+				repo.EXPECT().FindInbox(gomock.Any(), int64(1), pagination.Cursor{}, int64(firstPageSize)).
+					Return([]domain.FeedItem{at(8, 2, 900), at(5, 2, 700)}, nil)
+				repo.EXPECT().PullAuthors(gomock.Any()).Return(nil, nil)
+				repo.EXPECT().SetFirstPage(gomock.Any(), int64(1),
+					[]domain.FeedItem{at(8, 2, 900), at(5, 2, 700)}).Return(nil)
+				return repo, followmocks.NewMockFollowServiceClient(ctrl)
+			},
+			limit:     1,
+			wantItems: []domain.FeedItem{at(8, 2, 900)},
+		},
+		{
+			name: "查询关注关系失败",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository, followv1.FollowServiceClient) {
+				repo := repomocks.NewMockFeedRepository(ctrl)
+				repo.EXPECT().FindInbox(gomock.Any(), int64(1), cursor, int64(3)).Return(nil, nil)
+				repo.EXPECT().PullAuthors(gomock.Any()).Return([]int64{3}, nil)
+				client := followmocks.NewMockFollowServiceClient(ctrl)
+				client.EXPECT().GetFollowee(gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("follow 挂了"))
+				return repo, client
+			},
+			cursor:  cursor,
+			limit:   3,
+			wantErr: errors.New("follow 挂了"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, client := tc.mock(ctrl)
+			svc := NewFeedService(repo, client, FeedConfig{}, logger.NewNopLogger())
+			items, err := svc.GetTimeline(context.Background(), 1, tc.cursor, tc.limit)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantItems, items)
+		})
+	}
+}
+
+func TestMergeItems(t *testing.T) {
+	at := func(aid, ms int64) domain.FeedItem {
+		return domain.FeedItem{Aid: aid, Ctime: time.UnixMilli(ms)}
+	}
+	// 同一个时间按照 aid 倒序，两边都有的只保留一条
+	res := mergeItems(
+		[]domain.FeedItem{at(3, 100), at(2, 100), at(1, 50)},
+		[]domain.FeedItem{at(4, 100), at(2, 100)},
+		10)
+	assert.Equal(t, []domain.FeedItem{at(4, 100), at(3, 100), at(2, 100), at(1, 50)}, res)
+}
@@ -0,0 +1,47 @@
+//go:build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+
+	"webooktrial/feed/events"
+	"webooktrial/feed/grpc"
+	"webooktrial/feed/ioc"
+	"webooktrial/feed/repository"
+	"webooktrial/feed/repository/cache"
+	"webooktrial/feed/repository/dao"
+	"webooktrial/feed/service"
+	"webooktrial/pkg/grpcx"
+)
+
+var thirdPartySet = wire.NewSet(
+	ioc.InitLogger,
+	ioc.InitDB,
+	ioc.InitRedis,
+	ioc.InitKafka,
+	grpcx.InitEtcdClient,
+	ioc.InitFollowClient,
+	ioc.InitCursorCodec)
+
+var feedSvcProvider = wire.NewSet(
+	service.NewFeedService,
+	repository.NewCachedFeedRepository,
+	dao.NewGORMFeedDAO,
+	cache.NewRedisFeedCache,
+	ioc.InitFeedConfig,
+)
+
+func InitApp() *App {
+	wire.Build(thirdPartySet,
+		feedSvcProvider,
+		events.NewArticlePublishedConsumer,
+		events.NewArticleWithdrawnConsumer,
+		events.NewFollowCanceledConsumer,
+		grpc.NewFeedServiceServer,
+		ioc.NewConsumers,
+		ioc.InitGRPCxServer,
+		wire.Struct(new(App), "*"),
+	)
+	return new(App)
+}
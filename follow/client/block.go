@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"encoding/binary"
+	"time"
+
+	"github.com/coocood/freecache"
+
+	followv1 "webooktrial/api/proto/gen/follow/v1"
+	"webooktrial/pkg/logger"
+)
+
+// BlockChecker 其它服务检查拉黑关系用
+type BlockChecker interface {
+	// IsBlocked blocker 有没有拉黑 blocked
+	IsBlocked(ctx context.Context, blocker, blocked int64) (bool, error)
+}
+
+// CachedBlockChecker 调用 follow 的 IsBlocked，结果在本地缓存一段时间。
+// 拉黑和取消拉黑最多延迟 expiration 才生效，点赞、评论这种场景可以接受
+type CachedBlockChecker struct {
+	client     followv1.FollowServiceClient
+	cache      *freecache.Cache
+	expiration time.Duration
+}
+
+func NewCachedBlockChecker(client followv1.FollowServiceClient, expiration time.Duration) *CachedBlockChecker {
+	return &CachedBlockChecker{
+		client: client,
+		// 每一条只有几十个字节，16M 足够了
+		cache:      freecache.NewCache(16 * 1024 * 1024),
+		expiration: expiration,
+	}
+}
+
+var (
+	blockedValue   = []byte{1}
+	unblockedValue = []byte{0}
+)
+
+func (c *CachedBlockChecker) IsBlocked(ctx context.Context, blocker, blocked int64) (bool, error) {
+	key := c.key(blocker, blocked)
+	val, err := c.cache.Get(key)
+	if err == nil {
+		return val[0] == blockedValue[0], nil
+	}
+	resp, err := c.client.IsBlocked(ctx, &followv1.IsBlockedRequest{
+		Blocker: blocker,
+		Blocked: blocked,
+	})
+	if err != nil {
+		return false, err
+	}
+	val = unblockedValue
+	if resp.Blocked {
+		val = blockedValue
+	}
+	// 缓存失败了也没关系，下一次再查
+	_ = c.cache.Set(key, val, int(c.expiration.Seconds()))
+	return resp.Blocked, nil
+}
+
+func (c *CachedBlockChecker) key(blocker, blocked int64) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key, uint64(blocker))
+	binary.BigEndian.PutUint64(key[8:], uint64(blocked))
+	return key
+}
+
+// FailOpenBlockChecker 查询失败的时候当作没有拉黑，只记录日志。
+// 拉黑只是限制互动，评论、点赞、打赏这些主流程不能因为 follow 不可用受影响
+type FailOpenBlockChecker struct {
+	checker BlockChecker
+	l       logger.LoggerV1
+}
+
+func NewFailOpenBlockChecker(checker BlockChecker, l logger.LoggerV1) *FailOpenBlockChecker {
+	return &FailOpenBlockChecker{checker: checker, l: l}
+}
+
+func (f *FailOpenBlockChecker) IsBlocked(ctx context.Context, blocker, blocked int64) (bool, error) {
+	res, err := f.checker.IsBlocked(ctx, blocker, blocked)
+	if err != nil {
+		f.l.Error("查询拉黑关系失败，当作没有拉黑",
+			logger.Int64("blocker", blocker),
+			logger.Int64("blocked", blocked),
+			logger.Error(err))
+		return false, nil
+	}
+	return res, nil
+}
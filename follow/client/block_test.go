@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	followv1 "webooktrial/api/proto/gen/follow/v1"
+	"webooktrial/pkg/logger"
+)
+
+// fakeFollowClient 只实现了 IsBlocked
+type fakeFollowClient struct {
+	followv1.FollowServiceClient
+	blocked map[[2]int64]bool
+	calls   int
+	err     error
+}
+
+func (f *fakeFollowClient) IsBlocked(ctx context.Context, in *followv1.IsBlockedRequest,
+	opts ...grpc.CallOption) (*followv1.IsBlockedResponse, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &followv1.IsBlockedResponse{Blocked: f.blocked[[2]int64{in.Blocker, in.Blocked}]}, nil
+}
+
+func TestCachedBlockChecker_IsBlocked(t *testing.T) {
+	ctx := context.Background()
+	fc := &fakeFollowClient{blocked: map[[2]int64]bool{{1, 2}: true}}
+	checker := NewCachedBlockChecker(fc, time.Minute)
+
+	blocked, err := checker.IsBlocked(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.True(t, blocked)
+	// 方向是反的
+	blocked, err = checker.IsBlocked(ctx, 2, 1)
+	require.NoError(t, err)
+	assert.False(t, blocked)
+	assert.Equal(t, 2, fc.calls)
+
+	// 两个结果都缓存了，包括没有拉黑的
+	fc.err = errors.New("follow 不可用")
+	blocked, err = checker.IsBlocked(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.True(t, blocked)
+	blocked, err = checker.IsBlocked(ctx, 2, 1)
+	require.NoError(t, err)
+	assert.False(t, blocked)
+	assert.Equal(t, 2, fc.calls)
+
+	// 没有缓存的时候把错误返回给调用方
+	_, err = checker.IsBlocked(ctx, 1, 3)
+	assert.Equal(t, fc.err, err)
+}
+
+func TestFailOpenBlockChecker_IsBlocked(t *testing.T) {
+	ctx := context.Background()
+	fc := &fakeFollowClient{blocked: map[[2]int64]bool{{1, 2}: true}}
+	checker := NewFailOpenBlockChecker(NewCachedBlockChecker(fc, time.Minute), logger.NewNopLogger())
+
+	blocked, err := checker.IsBlocked(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.True(t, blocked)
+
+	// follow 不可用的时候当作没有拉黑
+	fc.err = errors.New("follow 不可用")
+	blocked, err = checker.IsBlocked(ctx, 1, 3)
+	require.NoError(t, err)
+	assert.False(t, blocked)
+}
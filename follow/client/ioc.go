@@ -0,0 +1,48 @@
+package client
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+	etcdv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/naming/resolver"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	followv1 "webooktrial/api/proto/gen/follow/v1"
+	"webooktrial/pkg/grpcx/interceptors"
+	"webooktrial/pkg/logger"
+)
+
+// InitFollowClient 读取 grpc.client.follow 的配置，通过 etcd 发现 follow 服务。
+// caller 是调用方的服务名，follow 那边按照它限流
+func InitFollowClient(etcdClient *etcdv3.Client, caller string) followv1.FollowServiceClient {
+	type Config struct {
+		Target string `json:"target"`
+		Secure bool   `json:"secure"`
+	}
+	var cfg Config
+	err := viper.UnmarshalKey("grpc.client.follow", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	rs, err := resolver.NewBuilder(etcdClient)
+	if err != nil {
+		panic(err)
+	}
+	opts := []grpc.DialOption{grpc.WithResolvers(rs),
+		grpc.WithChainUnaryInterceptor(interceptors.BuildCallerClientInterceptor(caller))}
+	if !cfg.Secure {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+	cc, err := grpc.Dial(cfg.Target, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return followv1.NewFollowServiceClient(cc)
+}
+
+// InitBlockChecker 拉黑之后最多一分钟生效，follow 出问题了当作没有拉黑
+func InitBlockChecker(client followv1.FollowServiceClient, l logger.LoggerV1) BlockChecker {
+	return NewFailOpenBlockChecker(NewCachedBlockChecker(client, time.Minute), l)
+}
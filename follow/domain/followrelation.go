@@ -1,14 +1,49 @@
 package domain
 
+import "time"
+
 // FollowRelation 关注数据
 type FollowRelation struct {
+	Id int64
 	// 被关注的人
 	Followee int64
 	// 关注的人
 	Follower int64
-	// 根据业务需要，可以在这里加字段
-	// 比如说备注，标签之类的
-	// Note string
+	// Gid 分组，0 表示没有分组
+	Gid int64
+	// Special 特别关注，Followee 发了新的内容会主动推给 Follower
+	Special bool
+	// Note 备注，只有 Follower 自己能看到
+	Note string
+
+	// Utime 关注的时间，关注列表按照它倒序
+	Utime time.Time
+	// Mutual 反方向的关注也存在，也就是互相关注。只有列表查询会填充
+	Mutual bool
+}
+
+// FollowPrefsField 修改关注的时候要修改哪些字段，没有在里面的保持原样
+type FollowPrefsField uint8
+
+const (
+	FollowPrefsGid FollowPrefsField = 1 << iota
+	FollowPrefsSpecial
+	FollowPrefsNote
+
+	FollowPrefsAll = FollowPrefsGid | FollowPrefsSpecial | FollowPrefsNote
+)
+
+func (f FollowPrefsField) Has(field FollowPrefsField) bool {
+	return f&field != 0
+}
+
+// FollowStatus 某个人和 Uid 之间的关注关系，渲染 feed 的时候批量查询
+type FollowStatus struct {
+	Uid int64
+	// Following 我关注了 Uid
+	Following bool
+	// FollowedBy Uid 关注了我
+	FollowedBy bool
 }
 
 type FollowStatics struct {
@@ -16,4 +51,26 @@ type FollowStatics struct {
 	Followers int64
 	// 自己关注了多少人
 	Followees int64
+	// 自己特别关注了多少人
+	Specials int64
+	// Groups 自己每个分组里面有多少人，key 是分组 ID
+	Groups map[int64]int64
+}
+
+// FollowGroup 关注分组
+type FollowGroup struct {
+	Id   int64
+	Uid  int64
+	Name string
+	// Cnt 分组里面有多少人
+	Cnt int64
+}
+
+// Block 拉黑关系
+type Block struct {
+	Id      int64
+	Blocker int64
+	Blocked int64
+	// Ctime 拉黑的时间，黑名单按照它倒序
+	Ctime time.Time
 }
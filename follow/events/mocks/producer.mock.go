@@ -0,0 +1,68 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./producer.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./producer.go -package=evtmocks -destination=mocks/producer.mock.go Producer
+//
+// Package evtmocks is a generated GoMock package.
+package evtmocks
+
+import (
+	context "context"
+	reflect "reflect"
+	events "webooktrial/follow/events"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockProducer is a mock of Producer interface.
+type MockProducer struct {
+	ctrl     *gomock.Controller
+	recorder *MockProducerMockRecorder
+}
+
+// MockProducerMockRecorder is the mock recorder for MockProducer.
+type MockProducerMockRecorder struct {
+	mock *MockProducer
+}
+
+// NewMockProducer creates a new mock instance.
+func NewMockProducer(ctrl *gomock.Controller) *MockProducer {
+	mock := &MockProducer{ctrl: ctrl}
+	mock.recorder = &MockProducerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProducer) EXPECT() *MockProducerMockRecorder {
+	return m.recorder
+}
+
+// ProduceFollowCanceledEvent mocks base method.
+func (m *MockProducer) ProduceFollowCanceledEvent(ctx context.Context, evt events.FollowCanceledEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProduceFollowCanceledEvent", ctx, evt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProduceFollowCanceledEvent indicates an expected call of ProduceFollowCanceledEvent.
+func (mr *MockProducerMockRecorder) ProduceFollowCanceledEvent(ctx, evt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProduceFollowCanceledEvent", reflect.TypeOf((*MockProducer)(nil).ProduceFollowCanceledEvent), ctx, evt)
+}
+
+// ProduceFollowCreatedEvent mocks base method.
+func (m *MockProducer) ProduceFollowCreatedEvent(ctx context.Context, evt events.FollowCreatedEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProduceFollowCreatedEvent", ctx, evt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProduceFollowCreatedEvent indicates an expected call of ProduceFollowCreatedEvent.
+func (mr *MockProducerMockRecorder) ProduceFollowCreatedEvent(ctx, evt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProduceFollowCreatedEvent", reflect.TypeOf((*MockProducer)(nil).ProduceFollowCreatedEvent), ctx, evt)
+}
@@ -0,0 +1,70 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/IBM/sarama"
+)
+
+const (
+	TopicFollowCanceled = "follow_canceled"
+	TopicFollowCreated  = "follow_created"
+)
+
+// FollowCreatedEvent 关注了，重复关注也会发，下游要自己去重
+type FollowCreatedEvent struct {
+	Follower int64
+	Followee int64
+}
+
+// FollowCanceledEvent 取消关注了，拉黑的时候两个方向都会发。
+// feed 之类的下游收到之后清理自己的数据
+type FollowCanceledEvent struct {
+	Follower int64
+	Followee int64
+}
+
+//go:generate mockgen -source=./producer.go -package=evtmocks -destination=mocks/producer.mock.go Producer
+type Producer interface {
+	ProduceFollowCanceledEvent(ctx context.Context, evt FollowCanceledEvent) error
+	ProduceFollowCreatedEvent(ctx context.Context, evt FollowCreatedEvent) error
+}
+
+type KafkaProducer struct {
+	producer sarama.SyncProducer
+}
+
+func NewKafkaProducer(pc sarama.SyncProducer) Producer {
+	return &KafkaProducer{
+		producer: pc,
+	}
+}
+
+func (k *KafkaProducer) ProduceFollowCanceledEvent(ctx context.Context, evt FollowCanceledEvent) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, _, err = k.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: TopicFollowCanceled,
+		// 同一个人的关注变化落到同一个分区
+		Key:   sarama.StringEncoder(strconv.FormatInt(evt.Follower, 10)),
+		Value: sarama.ByteEncoder(data),
+	})
+	return err
+}
+
+func (k *KafkaProducer) ProduceFollowCreatedEvent(ctx context.Context, evt FollowCreatedEvent) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, _, err = k.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: TopicFollowCreated,
+		Key:   sarama.StringEncoder(strconv.FormatInt(evt.Follower, 10)),
+		Value: sarama.ByteEncoder(data),
+	})
+	return err
+}
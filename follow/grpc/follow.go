@@ -2,22 +2,34 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	followv1 "webooktrial/api/proto/gen/follow/v1"
 	"webooktrial/follow/domain"
 	"webooktrial/follow/service"
+	"webooktrial/pkg/pagination"
 )
 
+// maxBatchFollowStatus 一次批量查询最多这么多人
+const maxBatchFollowStatus = 100
+
 type FollowServiceServer struct {
 	followv1.UnimplementedFollowServiceServer
 	svc service.FollowRelationService
+	// codec 关注列表的分页游标
+	codec *pagination.Codec
 }
 
-func NewFollowRelationServiceServer(svc service.FollowRelationService) *FollowServiceServer {
+func NewFollowRelationServiceServer(svc service.FollowRelationService,
+	codec *pagination.Codec) *FollowServiceServer {
 	return &FollowServiceServer{
-		svc: svc,
+		svc:   svc,
+		codec: codec,
 	}
 }
 
@@ -26,17 +38,159 @@ func (f *FollowServiceServer) Register(server grpc.ServiceRegistrar) {
 }
 
 func (f *FollowServiceServer) GetFollowee(ctx context.Context, request *followv1.GetFolloweeRequest) (*followv1.GetFolloweeResponse, error) {
-	relationList, err := f.svc.GetFollowee(ctx, request.Follower, request.Offset, request.Limit)
+	var (
+		relationList []domain.FollowRelation
+		err          error
+	)
+	if request.Cursor == "" && request.Offset > 0 {
+		// 兼容还在用 offset 的调用方
+		relationList, err = f.svc.GetFollowee(ctx, request.Follower, request.Offset, request.Limit)
+	} else {
+		cursor, er := f.codec.Decode(request.Cursor)
+		if er != nil {
+			return nil, status.Error(codes.InvalidArgument, er.Error())
+		}
+		relationList, err = f.svc.GetFolloweeByCursor(ctx, request.Follower, cursor, request.Limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	res, next := f.toPage(relationList, request.Limit)
+	return &followv1.GetFolloweeResponse{
+		FollowRelations: res,
+		NextCursor:      next,
+	}, nil
+}
+
+func (f *FollowServiceServer) GetFollower(ctx context.Context, request *followv1.GetFollowerRequest) (*followv1.GetFollowerResponse, error) {
+	cursor, err := f.codec.Decode(request.Cursor)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	relationList, err := f.svc.GetFollowerByCursor(ctx, request.Followee, cursor, request.Limit)
+	if err != nil {
+		return nil, err
+	}
+	res, next := f.toPage(relationList, request.Limit)
+	return &followv1.GetFollowerResponse{
+		FollowRelations: res,
+		NextCursor:      next,
+	}, nil
+}
+
+func (f *FollowServiceServer) GetSpecialFollower(ctx context.Context, request *followv1.GetSpecialFollowerRequest) (*followv1.GetSpecialFollowerResponse, error) {
+	cursor, err := f.codec.Decode(request.Cursor)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	relationList, err := f.svc.GetSpecialFollowerByCursor(ctx, request.Followee, cursor, request.Limit)
+	if err != nil {
+		return nil, err
+	}
+	res, next := f.toPage(relationList, request.Limit)
+	return &followv1.GetSpecialFollowerResponse{
+		FollowRelations: res,
+		NextCursor:      next,
+	}, nil
+}
+
+func (f *FollowServiceServer) GetMutualFollow(ctx context.Context, request *followv1.GetMutualFollowRequest) (*followv1.GetMutualFollowResponse, error) {
+	cursor, err := f.codec.Decode(request.Cursor)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	relationList, err := f.svc.GetMutualByCursor(ctx, request.Uid, cursor, request.Limit)
+	if err != nil {
+		return nil, err
+	}
+	res, next := f.toPage(relationList, request.Limit)
+	return &followv1.GetMutualFollowResponse{
+		FollowRelations: res,
+		NextCursor:      next,
+	}, nil
+}
+
+func (f *FollowServiceServer) BatchFollowStatus(ctx context.Context, request *followv1.BatchFollowStatusRequest) (*followv1.BatchFollowStatusResponse, error) {
+	if len(request.Targets) > maxBatchFollowStatus {
+		return nil, status.Errorf(codes.InvalidArgument, "一次最多查询 %d 个人", maxBatchFollowStatus)
+	}
+	statuses, err := f.svc.BatchFollowStatus(ctx, request.Uid, request.Targets)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*followv1.FollowStatus, 0, len(statuses))
+	for _, s := range statuses {
+		res = append(res, &followv1.FollowStatus{
+			Uid:        s.Uid,
+			Following:  s.Following,
+			FollowedBy: s.FollowedBy,
+		})
+	}
+	return &followv1.BatchFollowStatusResponse{Statuses: res}, nil
+}
+
+func (f *FollowServiceServer) GetFollowStatics(ctx context.Context, request *followv1.GetFollowStaticsRequest) (*followv1.GetFollowStaticsResponse, error) {
+	statics, err := f.svc.GetFollowStatics(ctx, request.Uid)
 	if err != nil {
 		return nil, err
 	}
+	return &followv1.GetFollowStaticsResponse{
+		Followers: statics.Followers,
+		Followees: statics.Followees,
+		Specials:  statics.Specials,
+	}, nil
+}
+
+func (f *FollowServiceServer) Block(ctx context.Context, request *followv1.BlockRequest) (*followv1.BlockResponse, error) {
+	err := f.svc.Block(ctx, request.Blocker, request.Blocked)
+	return &followv1.BlockResponse{}, toStatusErr(err)
+}
+
+func (f *FollowServiceServer) Unblock(ctx context.Context, request *followv1.UnblockRequest) (*followv1.UnblockResponse, error) {
+	err := f.svc.Unblock(ctx, request.Blocker, request.Blocked)
+	return &followv1.UnblockResponse{}, err
+}
+
+func (f *FollowServiceServer) ListBlocked(ctx context.Context, request *followv1.ListBlockedRequest) (*followv1.ListBlockedResponse, error) {
+	cursor, err := f.codec.Decode(request.Cursor)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	blocks, err := f.svc.ListBlocked(ctx, request.Blocker, cursor, request.Limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*followv1.BlockedUser, 0, len(blocks))
+	for _, b := range blocks {
+		res = append(res, &followv1.BlockedUser{Uid: b.Blocked, Ctime: b.Ctime.UnixMilli()})
+	}
+	next := pagination.Next(blocks, int(request.Limit), func(b domain.Block) pagination.Cursor {
+		return pagination.Cursor{Key: b.Ctime.UnixMilli(), Id: b.Id}
+	})
+	return &followv1.ListBlockedResponse{
+		Users:      res,
+		NextCursor: f.codec.Encode(next),
+	}, nil
+}
+
+func (f *FollowServiceServer) IsBlocked(ctx context.Context, request *followv1.IsBlockedRequest) (*followv1.IsBlockedResponse, error) {
+	blocked, err := f.svc.IsBlocked(ctx, request.Blocker, request.Blocked)
+	if err != nil {
+		return nil, err
+	}
+	return &followv1.IsBlockedResponse{Blocked: blocked}, nil
+}
+
+// toPage 关注列表、粉丝列表和互关列表都按照 utime 和 id 翻页
+func (f *FollowServiceServer) toPage(relationList []domain.FollowRelation, limit int64) ([]*followv1.FollowRelation, string) {
 	res := make([]*followv1.FollowRelation, 0, len(relationList))
 	for _, relation := range relationList {
 		res = append(res, f.convertToView(relation))
 	}
-	return &followv1.GetFolloweeResponse{
-		FollowRelations: res,
-	}, nil
+	next := pagination.Next(relationList, int(limit), func(r domain.FollowRelation) pagination.Cursor {
+		return pagination.Cursor{Key: r.Utime.UnixMilli(), Id: r.Id}
+	})
+	return res, f.codec.Encode(next)
 }
 
 func (f *FollowServiceServer) FollowInfo(ctx context.Context, request *followv1.FollowInfoRequest) (*followv1.FollowInfoResponse, error) {
@@ -50,8 +204,98 @@ func (f *FollowServiceServer) FollowInfo(ctx context.Context, request *followv1.
 }
 
 func (f *FollowServiceServer) Follow(ctx context.Context, request *followv1.FollowRequest) (*followv1.FollowResponse, error) {
-	err := f.svc.Follow(ctx, request.Follower, request.Followee)
-	return &followv1.FollowResponse{}, err
+	err := f.svc.Follow(ctx, domain.FollowRelation{
+		Follower: request.Follower,
+		Followee: request.Followee,
+		Gid:      request.Gid,
+		Special:  request.Notification,
+		Note:     request.Note,
+	})
+	return &followv1.FollowResponse{}, toStatusErr(err)
+}
+
+func (f *FollowServiceServer) UpdateFollow(ctx context.Context, request *followv1.UpdateFollowRequest) (*followv1.UpdateFollowResponse, error) {
+	fields, err := f.toPrefsFields(request.GetUpdateMask().GetPaths())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	err = f.svc.UpdateFollow(ctx, domain.FollowRelation{
+		Follower: request.Follower,
+		Followee: request.Followee,
+		Gid:      request.Gid,
+		Special:  request.Notification,
+		Note:     request.Note,
+	}, fields)
+	return &followv1.UpdateFollowResponse{}, toStatusErr(err)
+}
+
+// toPrefsFields 没有传 update_mask 的时候全部修改
+func (f *FollowServiceServer) toPrefsFields(paths []string) (domain.FollowPrefsField, error) {
+	if len(paths) == 0 {
+		return domain.FollowPrefsAll, nil
+	}
+	var res domain.FollowPrefsField
+	for _, p := range paths {
+		switch p {
+		case "gid":
+			res |= domain.FollowPrefsGid
+		case "notification":
+			res |= domain.FollowPrefsSpecial
+		case "note":
+			res |= domain.FollowPrefsNote
+		default:
+			return 0, fmt.Errorf("不能修改的字段 %s", p)
+		}
+	}
+	return res, nil
+}
+
+func (f *FollowServiceServer) GetFolloweeByGroup(ctx context.Context, request *followv1.GetFolloweeByGroupRequest) (*followv1.GetFolloweeByGroupResponse, error) {
+	cursor, err := f.codec.Decode(request.Cursor)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	relationList, err := f.svc.GetFolloweeByGroup(ctx, request.Follower, request.Gid, cursor, request.Limit)
+	if err != nil {
+		return nil, err
+	}
+	res, next := f.toPage(relationList, request.Limit)
+	return &followv1.GetFolloweeByGroupResponse{
+		FollowRelations: res,
+		NextCursor:      next,
+	}, nil
+}
+
+func (f *FollowServiceServer) CreateFollowGroup(ctx context.Context, request *followv1.CreateFollowGroupRequest) (*followv1.CreateFollowGroupResponse, error) {
+	g, err := f.svc.CreateGroup(ctx, request.Uid, request.Name)
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+	return &followv1.CreateFollowGroupResponse{
+		Group: &followv1.FollowGroup{Id: g.Id, Name: g.Name},
+	}, nil
+}
+
+func (f *FollowServiceServer) RenameFollowGroup(ctx context.Context, request *followv1.RenameFollowGroupRequest) (*followv1.RenameFollowGroupResponse, error) {
+	err := f.svc.RenameGroup(ctx, request.Uid, request.Gid, request.Name)
+	return &followv1.RenameFollowGroupResponse{}, toStatusErr(err)
+}
+
+func (f *FollowServiceServer) DeleteFollowGroup(ctx context.Context, request *followv1.DeleteFollowGroupRequest) (*followv1.DeleteFollowGroupResponse, error) {
+	err := f.svc.DeleteGroup(ctx, request.Uid, request.Gid)
+	return &followv1.DeleteFollowGroupResponse{}, toStatusErr(err)
+}
+
+func (f *FollowServiceServer) ListFollowGroup(ctx context.Context, request *followv1.ListFollowGroupRequest) (*followv1.ListFollowGroupResponse, error) {
+	groups, err := f.svc.ListGroups(ctx, request.Uid)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*followv1.FollowGroup, 0, len(groups))
+	for _, g := range groups {
+		res = append(res, &followv1.FollowGroup{Id: g.Id, Name: g.Name, Cnt: g.Cnt})
+	}
+	return &followv1.ListFollowGroupResponse{Groups: res}, nil
 }
 
 func (f *FollowServiceServer) CancelFollow(ctx context.Context, request *followv1.CancelFollowRequest) (*followv1.CancelFollowResponse, error) {
@@ -61,7 +305,35 @@ func (f *FollowServiceServer) CancelFollow(ctx context.Context, request *followv
 
 func (f *FollowServiceServer) convertToView(relation domain.FollowRelation) *followv1.FollowRelation {
 	return &followv1.FollowRelation{
-		Followee: relation.Followee,
-		Follower: relation.Follower,
+		Id:           relation.Id,
+		Followee:     relation.Followee,
+		Follower:     relation.Follower,
+		Mutual:       relation.Mutual,
+		Gid:          relation.Gid,
+		Notification: relation.Special,
+		Note:         relation.Note,
+	}
+}
+
+// toStatusErr 把业务错误转成对应的 gRPC 错误码，其它的原样返回
+func toStatusErr(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, service.ErrFollowRelationNotFound),
+		errors.Is(err, service.ErrFollowGroupNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrDuplicateFollowGroup):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, service.ErrTooManyFollowGroups):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, service.ErrBlocked):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrInvalidFollowGroupName),
+		errors.Is(err, service.ErrFollowNoteTooLong),
+		errors.Is(err, service.ErrBlockSelf):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return err
 	}
 }
@@ -1 +1,105 @@
 package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+
+	followv1 "webooktrial/api/proto/gen/follow/v1"
+	"webooktrial/follow/grpc"
+	"webooktrial/follow/integration/startup"
+	"webooktrial/follow/repository/dao"
+)
+
+// FollowTestSuite 走真的 MySQL 和 Redis，从 gRPC 一直到数据库
+type FollowTestSuite struct {
+	suite.Suite
+	server *grpc.FollowServiceServer
+	db     *gorm.DB
+	rdb    redis.Cmdable
+}
+
+func (s *FollowTestSuite) SetupSuite() {
+	s.server = startup.InitServer()
+	s.db = startup.InitTestDB()
+	s.rdb = startup.InitRedis()
+}
+
+func (s *FollowTestSuite) TearDownTest() {
+	err := s.db.Exec("TRUNCATE TABLE `follow_relations`").Error
+	assert.NoError(s.T(), err)
+	err = s.db.Exec("TRUNCATE TABLE `follow_groups`").Error
+	assert.NoError(s.T(), err)
+	for _, uid := range []int64{1, 2} {
+		s.rdb.Del(context.Background(), fmt.Sprintf("follow:statics:%d", uid))
+	}
+}
+
+func (s *FollowTestSuite) TestCancelFollow() {
+	t := s.T()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	g, err := s.server.CreateFollowGroup(ctx, &followv1.CreateFollowGroupRequest{Uid: 1, Name: "同学"})
+	require.NoError(t, err)
+	gid := g.Group.Id
+	_, err = s.server.Follow(ctx, &followv1.FollowRequest{
+		Follower: 1, Followee: 2, Gid: gid, Notification: true,
+	})
+	require.NoError(t, err)
+
+	// 先把统计信息加载到缓存里面，后面取消关注的时候要调整缓存
+	statics, err := s.server.GetFollowStatics(ctx, &followv1.GetFollowStaticsRequest{Uid: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), statics.Followees)
+	assert.Equal(t, int64(1), statics.Specials)
+	followerStatics, err := s.server.GetFollowStatics(ctx, &followv1.GetFollowStaticsRequest{Uid: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), followerStatics.Followers)
+
+	_, err = s.server.CancelFollow(ctx, &followv1.CancelFollowRequest{Follower: 1, Followee: 2})
+	require.NoError(t, err)
+
+	var fr dao.FollowRelation
+	err = s.db.Where("follower = ? AND followee = ?", 1, 2).First(&fr).Error
+	require.NoError(t, err)
+	assert.Equal(t, dao.FollowRelationStatusInactive, fr.Status)
+
+	// 缓存里面的统计信息要减回去
+	statics, err = s.server.GetFollowStatics(ctx, &followv1.GetFollowStaticsRequest{Uid: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), statics.Followees)
+	assert.Equal(t, int64(0), statics.Specials)
+	followerStatics, err = s.server.GetFollowStatics(ctx, &followv1.GetFollowStaticsRequest{Uid: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), followerStatics.Followers)
+	groups, err := s.server.ListFollowGroup(ctx, &followv1.ListFollowGroupRequest{Uid: 1})
+	require.NoError(t, err)
+	require.Len(t, groups.Groups, 1)
+	assert.Equal(t, int64(0), groups.Groups[0].Cnt)
+
+	// 重复取消关注，统计信息不能再减
+	_, err = s.server.CancelFollow(ctx, &followv1.CancelFollowRequest{Follower: 1, Followee: 2})
+	require.NoError(t, err)
+	statics, err = s.server.GetFollowStatics(ctx, &followv1.GetFollowStaticsRequest{Uid: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), statics.Followees)
+
+	// 缓存和数据库要一致
+	s.rdb.Del(ctx, "follow:statics:1")
+	statics, err = s.server.GetFollowStatics(ctx, &followv1.GetFollowStaticsRequest{Uid: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), statics.Followees)
+	assert.Equal(t, int64(0), statics.Specials)
+}
+
+func TestFollow(t *testing.T) {
+	suite.Run(t, new(FollowTestSuite))
+}
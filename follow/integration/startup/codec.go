@@ -0,0 +1,7 @@
+package startup
+
+import "webooktrial/pkg/pagination"
+
+func InitCursorCodec() *pagination.Codec {
+	return pagination.NewCodec([]byte("follow_test"))
+}
@@ -0,0 +1,22 @@
+package startup
+
+import (
+	"context"
+
+	"webooktrial/follow/events"
+)
+
+// InitProducer 测试里面不关心下游，消息直接丢掉
+func InitProducer() events.Producer {
+	return nopProducer{}
+}
+
+type nopProducer struct{}
+
+func (nopProducer) ProduceFollowCanceledEvent(ctx context.Context, evt events.FollowCanceledEvent) error {
+	return nil
+}
+
+func (nopProducer) ProduceFollowCreatedEvent(ctx context.Context, evt events.FollowCreatedEvent) error {
+	return nil
+}
@@ -1,3 +1,5 @@
+//go:build wireinject
+
 package startup
 
 import (
@@ -16,9 +18,13 @@ func InitServer() *grpc.FollowServiceServer {
 		InitLog,
 		InitTestDB,
 		dao.NewGORMFollowRelationDAO,
+		dao.NewGORMBlockDAO,
 		cache.NewRedisFollowCache,
 		repository.NewCachedRelationRepository,
+		repository.NewBlockRepository,
+		InitProducer,
 		service.NewFollowRelationService,
+		InitCursorCodec,
 		grpc.NewFollowRelationServiceServer,
 	)
 	return new(grpc.FollowServiceServer)
@@ -23,7 +23,11 @@ func InitServer() *grpc.FollowServiceServer {
 	followCache := cache.NewRedisFollowCache(cmdable)
 	loggerV1 := InitLog()
 	followRepository := repository.NewCachedRelationRepository(followRelationDao, followCache, loggerV1)
-	followRelationService := service.NewFollowRelationService(followRepository)
-	followServiceServer := grpc.NewFollowRelationServiceServer(followRelationService)
+	blockDAO := dao.NewGORMBlockDAO(gormDB)
+	blockRepository := repository.NewBlockRepository(blockDAO, followCache, loggerV1)
+	producer := InitProducer()
+	followRelationService := service.NewFollowRelationService(followRepository, blockRepository, producer, loggerV1)
+	codec := InitCursorCodec()
+	followServiceServer := grpc.NewFollowRelationServiceServer(followRelationService, codec)
 	return followServiceServer
 }
@@ -6,14 +6,19 @@ import (
 
 	grpc2 "webooktrial/follow/grpc"
 	"webooktrial/pkg/grpcx"
+	"webooktrial/pkg/logger"
 )
 
-func InitGRPCxServer(followRelation *grpc2.FollowServiceServer) *grpcx.Server {
+// InitGRPCxServer 注册到 etcd 的 service/follow 下面，
+// 其它服务通过它查询拉黑关系
+func InitGRPCxServer(l logger.LoggerV1, followRelation *grpc2.FollowServiceServer) *grpcx.Server {
 	type Config struct {
-		Addr []string `yaml:"etcdAddrs"`
+		Port      int      `yaml:"port"`
+		EtcdAddrs []string `yaml:"etcdAddrs"`
+		EtcdTTL   int64    `yaml:"etcdTTL"`
 	}
 	var cfg Config
-	err := viper.UnmarshalKey("grpc", &cfg)
+	err := viper.UnmarshalKey("grpc.server", &cfg)
 	if err != nil {
 		panic(err)
 	}
@@ -21,6 +26,10 @@ func InitGRPCxServer(followRelation *grpc2.FollowServiceServer) *grpcx.Server {
 	followRelation.Register(server)
 	return &grpcx.Server{
 		Server:    server,
-		EtcdAddrs: cfg.Addr,
+		Port:      cfg.Port,
+		EtcdAddrs: cfg.EtcdAddrs,
+		EtcdTTL:   cfg.EtcdTTL,
+		Name:      "follow",
+		L:         l,
 	}
 }
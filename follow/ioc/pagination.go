@@ -0,0 +1,16 @@
+package ioc
+
+import (
+	"github.com/spf13/viper"
+
+	"webooktrial/pkg/pagination"
+)
+
+// InitCursorCodec 所有实例要用同一个 secret
+func InitCursorCodec() *pagination.Codec {
+	secret := viper.GetString("cursor.secret")
+	if secret == "" {
+		panic("没有配置分页游标的 cursor.secret")
+	}
+	return pagination.NewCodec([]byte(secret))
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"webooktrial/follow/domain"
+	"webooktrial/follow/repository/cache"
+	"webooktrial/follow/repository/dao"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/pagination"
+)
+
+//go:generate mockgen -source=./block.go -package=repomocks -destination=mocks/block.mock.go BlockRepository
+type BlockRepository interface {
+	// Block 拉黑，两个方向的关注都会取消
+	Block(ctx context.Context, blocker, blocked int64) error
+	Unblock(ctx context.Context, blocker, blocked int64) error
+	// ListBlocked 按照拉黑的时间倒序，cursor 零值表示第一页
+	ListBlocked(ctx context.Context, blocker int64, cursor pagination.Cursor, limit int64) ([]domain.Block, error)
+	IsBlocked(ctx context.Context, blocker, blocked int64) (bool, error)
+	// IsBlockedEither 任何一方拉黑了对方都算
+	IsBlockedEither(ctx context.Context, uid1, uid2 int64) (bool, error)
+}
+
+type blockRepository struct {
+	dao   dao.BlockDAO
+	cache cache.FollowCache
+	l     logger.LoggerV1
+}
+
+func NewBlockRepository(dao dao.BlockDAO, cache cache.FollowCache, l logger.LoggerV1) BlockRepository {
+	return &blockRepository{dao: dao, cache: cache, l: l}
+}
+
+func (b *blockRepository) Block(ctx context.Context, blocker, blocked int64) error {
+	// 取消了的关注关系要调整统计信息
+	actives, err := b.dao.Block(ctx, blocker, blocked)
+	if err != nil {
+		return err
+	}
+	for _, fr := range actives {
+		er := b.cache.CancelFollow(ctx, domain.FollowRelation{
+			Follower: fr.Follower,
+			Followee: fr.Followee,
+			Gid:      fr.Gid,
+			Special:  fr.Type == dao.FollowTypeSpecial,
+		})
+		if er != nil {
+			b.l.Error("拉黑之后调整关注统计信息失败",
+				logger.Int64("follower", fr.Follower),
+				logger.Int64("followee", fr.Followee),
+				logger.Error(er))
+		}
+	}
+	return nil
+}
+
+func (b *blockRepository) Unblock(ctx context.Context, blocker, blocked int64) error {
+	return b.dao.Unblock(ctx, blocker, blocked)
+}
+
+func (b *blockRepository) ListBlocked(ctx context.Context, blocker int64,
+	cursor pagination.Cursor, limit int64) ([]domain.Block, error) {
+	list, err := b.dao.ListByCursor(ctx, blocker, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Block, 0, len(list))
+	for _, ub := range list {
+		res = append(res, domain.Block{
+			Id:      ub.ID,
+			Blocker: ub.Blocker,
+			Blocked: ub.Blocked,
+			Ctime:   time.UnixMilli(ub.Ctime),
+		})
+	}
+	return res, nil
+}
+
+func (b *blockRepository) IsBlocked(ctx context.Context, blocker, blocked int64) (bool, error) {
+	return b.dao.Exists(ctx, blocker, blocked)
+}
+
+func (b *blockRepository) IsBlockedEither(ctx context.Context, uid1, uid2 int64) (bool, error) {
+	return b.dao.ExistsEither(ctx, uid1, uid2)
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"webooktrial/follow/domain"
+	"webooktrial/follow/repository/cache"
+	cachemocks "webooktrial/follow/repository/cache/mocks"
+	"webooktrial/follow/repository/dao"
+	daomocks "webooktrial/follow/repository/dao/mocks"
+	"webooktrial/pkg/logger"
+)
+
+func TestBlockRepository_Block(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (dao.BlockDAO, cache.FollowCache)
+
+		wantErr error
+	}{
+		{
+			name: "取消了两个方向的关注，都调整统计信息",
+			mock: func(ctrl *gomock.Controller) (dao.BlockDAO, cache.FollowCache) {
+				d := daomocks.NewMockBlockDAO(ctrl)
+				d.EXPECT().Block(gomock.Any(), int64(1), int64(2)).Return([]dao.FollowRelation{
+					{Follower: 1, Followee: 2, Gid: 3},
+					{Follower: 2, Followee: 1, Type: dao.FollowTypeSpecial},
+				}, nil)
+				c := cachemocks.NewMockFollowCache(ctrl)
+				c.EXPECT().CancelFollow(gomock.Any(), domain.FollowRelation{
+					Follower: 1, Followee: 2, Gid: 3}).Return(nil)
+				c.EXPECT().CancelFollow(gomock.Any(), domain.FollowRelation{
+					Follower: 2, Followee: 1, Special: true}).Return(nil)
+				return d, c
+			},
+		},
+		{
+			name: "没有关注关系，不用调整统计信息",
+			mock: func(ctrl *gomock.Controller) (dao.BlockDAO, cache.FollowCache) {
+				d := daomocks.NewMockBlockDAO(ctrl)
+				d.EXPECT().Block(gomock.Any(), int64(1), int64(2)).Return(nil, nil)
+				return d, cachemocks.NewMockFollowCache(ctrl)
+			},
+		},
+		{
+			name: "调整统计信息失败不影响拉黑",
+			mock: func(ctrl *gomock.Controller) (dao.BlockDAO, cache.FollowCache) {
+				d := daomocks.NewMockBlockDAO(ctrl)
+				d.EXPECT().Block(gomock.Any(), int64(1), int64(2)).Return([]dao.FollowRelation{
+					{Follower: 2, Followee: 1},
+				}, nil)
+				c := cachemocks.NewMockFollowCache(ctrl)
+				c.EXPECT().CancelFollow(gomock.Any(), gomock.Any()).Return(errors.New("redis 错误"))
+				return d, c
+			},
+		},
+		{
+			name: "拉黑失败",
+			mock: func(ctrl *gomock.Controller) (dao.BlockDAO, cache.FollowCache) {
+				d := daomocks.NewMockBlockDAO(ctrl)
+				d.EXPECT().Block(gomock.Any(), int64(1), int64(2)).Return(nil, errors.New("db 错误"))
+				return d, cachemocks.NewMockFollowCache(ctrl)
+			},
+			wantErr: errors.New("db 错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, c := tc.mock(ctrl)
+			repo := NewBlockRepository(d, c, logger.NewNopLogger())
+			err := repo.Block(context.Background(), 1, 2)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
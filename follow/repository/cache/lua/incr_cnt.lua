@@ -0,0 +1,12 @@
+local key = KEYS[1]
+-- hincrby 的 field
+local field = ARGV[1]
+-- +1 或者 -1
+local delta = tonumber(ARGV[2])
+-- 只有缓存了完整的统计信息才自增，不然会留下只有一部分字段的缓存
+if redis.call("EXISTS", key) == 1 then
+    redis.call("HINCRBY", key, field, delta)
+    return 1
+else
+    return 0
+end
@@ -2,8 +2,10 @@ package cache
 
 import (
 	"context"
+	_ "embed"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/redis/go-redis/v9"
 
@@ -12,6 +14,11 @@ import (
 
 var ErrKeyNotExist = redis.Nil
 
+var (
+	//go:embed lua/incr_cnt.lua
+	luaIncrCnt string
+)
+
 type RedisFollowCache struct {
 	client redis.Cmdable
 }
@@ -21,6 +28,10 @@ const (
 	fieldFollowerCnt = "follower_cnt"
 	// 关注了多少人
 	fieldFolloweeCnt = "followee_cnt"
+	// 特别关注了多少人
+	fieldSpecialCnt = "special_cnt"
+	// 分组里面有多少人，后面跟着分组 ID
+	fieldGroupCntPrefix = "group_cnt:"
 )
 
 func NewRedisFollowCache(client redis.Cmdable) FollowCache {
@@ -41,35 +52,105 @@ func (r *RedisFollowCache) StaticsInfo(ctx context.Context, uid int64) (domain.F
 	// 理论上来说，这里不可能有 error
 	followerCnt, _ := strconv.ParseInt(data[fieldFollowerCnt], 10, 64)
 	followeeCnt, _ := strconv.ParseInt(data[fieldFolloweeCnt], 10, 64)
+	specialCnt, _ := strconv.ParseInt(data[fieldSpecialCnt], 10, 64)
+	groups := make(map[int64]int64)
+	for field, val := range data {
+		if !strings.HasPrefix(field, fieldGroupCntPrefix) {
+			continue
+		}
+		gid, _ := strconv.ParseInt(strings.TrimPrefix(field, fieldGroupCntPrefix), 10, 64)
+		cnt, _ := strconv.ParseInt(val, 10, 64)
+		if cnt > 0 {
+			groups[gid] = cnt
+		}
+	}
 	return domain.FollowStatics{
 		Followees: followeeCnt,
 		Followers: followerCnt,
+		Specials:  specialCnt,
+		Groups:    groups,
 	}, nil
 }
 
 func (r *RedisFollowCache) SetStaticsInfo(ctx context.Context, uid int64, statics domain.FollowStatics) error {
 	key := r.staticsKey(uid)
-	return r.client.HMSet(ctx, key, fieldFolloweeCnt, statics.Followees, fieldFollowerCnt, statics.Followers).Err()
+	values := []any{
+		fieldFolloweeCnt, statics.Followees,
+		fieldFollowerCnt, statics.Followers,
+		fieldSpecialCnt, statics.Specials,
+	}
+	for gid, cnt := range statics.Groups {
+		values = append(values, r.groupField(gid), cnt)
+	}
+	// 先删掉，不然已经删除的分组会留下来
+	tx := r.client.TxPipeline()
+	tx.Del(ctx, key)
+	tx.HMSet(ctx, key, values...)
+	_, err := tx.Exec(ctx)
+	return err
 }
 
-func (r *RedisFollowCache) Follow(ctx context.Context, follower, followee int64) error {
-	return r.updateStaticsInfo(ctx, follower, followee, 1)
+func (r *RedisFollowCache) Follow(ctx context.Context, f domain.FollowRelation) error {
+	return r.updateStaticsInfo(ctx, f, 1)
 }
 
-func (r *RedisFollowCache) updateStaticsInfo(ctx context.Context, follower, followee int64, delta int64) error {
+func (r *RedisFollowCache) updateStaticsInfo(ctx context.Context, f domain.FollowRelation, delta int64) error {
 	tx := r.client.TxPipeline()
 	// 增加 follower 的关注多少人的数量
-	tx.HIncrBy(ctx, r.staticsKey(follower), fieldFolloweeCnt, delta)
+	r.incr(ctx, tx, f.Follower, fieldFolloweeCnt, delta)
 	// 增加 followee 被多少人关注的数量
-	tx.HIncrBy(ctx, r.staticsKey(followee), fieldFollowerCnt, delta)
+	r.incr(ctx, tx, f.Followee, fieldFollowerCnt, delta)
+	if f.Special {
+		r.incr(ctx, tx, f.Follower, fieldSpecialCnt, delta)
+	}
+	if f.Gid > 0 {
+		r.incr(ctx, tx, f.Follower, r.groupField(f.Gid), delta)
+	}
+	_, err := tx.Exec(ctx)
+	return err
+}
+
+func (r *RedisFollowCache) CancelFollow(ctx context.Context, f domain.FollowRelation) error {
+	return r.updateStaticsInfo(ctx, f, -1)
+}
+
+func (r *RedisFollowCache) UpdatePrefs(ctx context.Context, old, f domain.FollowRelation) error {
+	if old.Special == f.Special && old.Gid == f.Gid {
+		return nil
+	}
+	tx := r.client.TxPipeline()
+	if old.Special != f.Special {
+		delta := int64(1)
+		if old.Special {
+			delta = -1
+		}
+		r.incr(ctx, tx, f.Follower, fieldSpecialCnt, delta)
+	}
+	if old.Gid != f.Gid {
+		if old.Gid > 0 {
+			r.incr(ctx, tx, f.Follower, r.groupField(old.Gid), -1)
+		}
+		if f.Gid > 0 {
+			r.incr(ctx, tx, f.Follower, r.groupField(f.Gid), 1)
+		}
+	}
 	_, err := tx.Exec(ctx)
 	return err
 }
 
-func (r *RedisFollowCache) CancelFollow(ctx context.Context, follower, followee int64) error {
-	return r.updateStaticsInfo(ctx, follower, followee, -1)
+func (r *RedisFollowCache) DeleteGroup(ctx context.Context, uid, gid int64) error {
+	return r.client.HDel(ctx, r.staticsKey(uid), r.groupField(gid)).Err()
+}
+
+// incr 缓存不存在的时候不自增
+func (r *RedisFollowCache) incr(ctx context.Context, tx redis.Pipeliner, uid int64, field string, delta int64) {
+	tx.Eval(ctx, luaIncrCnt, []string{r.staticsKey(uid)}, field, delta)
 }
 
 func (r *RedisFollowCache) staticsKey(uid int64) string {
 	return fmt.Sprintf("follow:statics:%d", uid)
 }
+
+func (r *RedisFollowCache) groupField(gid int64) string {
+	return fieldGroupCntPrefix + strconv.FormatInt(gid, 10)
+}
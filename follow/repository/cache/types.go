@@ -6,9 +6,14 @@ import (
 	"webooktrial/follow/domain"
 )
 
+//go:generate mockgen -source=./types.go -package=cachemocks -destination=mocks/types.mock.go FollowCache
 type FollowCache interface {
 	StaticsInfo(ctx context.Context, uid int64) (domain.FollowStatics, error)
 	SetStaticsInfo(ctx context.Context, uid int64, statics domain.FollowStatics) error
-	Follow(ctx context.Context, follower, followee int64) error
-	CancelFollow(ctx context.Context, follower, followee int64) error
+	Follow(ctx context.Context, f domain.FollowRelation) error
+	CancelFollow(ctx context.Context, f domain.FollowRelation) error
+	// UpdatePrefs 特别关注或者分组变了，调整统计信息
+	UpdatePrefs(ctx context.Context, old, f domain.FollowRelation) error
+	// DeleteGroup 分组删掉之后，里面的人都变成没有分组，不再统计
+	DeleteGroup(ctx context.Context, uid, gid int64) error
 }
@@ -0,0 +1,114 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"webooktrial/pkg/pagination"
+)
+
+// UserBlock 拉黑关系，取消拉黑直接删除
+// 典型场景是我拉黑了哪些人，所以是 <blocker, blocked>
+type UserBlock struct {
+	ID      int64 `gorm:"primaryKey,autoIncrement,column:id"`
+	Blocker int64 `gorm:"not null;uniqueIndex:blocker_blocked;index:blocker_ctime,priority:1"`
+	Blocked int64 `gorm:"not null;uniqueIndex:blocker_blocked"`
+	// 黑名单按照拉黑的时间翻页
+	Ctime int64 `gorm:"index:blocker_ctime,priority:2"`
+}
+
+//go:generate mockgen -source=./block.go -package=daomocks -destination=mocks/block.mock.go BlockDAO
+type BlockDAO interface {
+	// Block 拉黑，同时取消两个方向的关注，返回被取消的关注关系
+	Block(ctx context.Context, blocker, blocked int64) ([]FollowRelation, error)
+	Unblock(ctx context.Context, blocker, blocked int64) error
+	// ListByCursor 按照 ctime 和 id 倒序，cursor 的 Key 是 ctime，零值表示第一页
+	ListByCursor(ctx context.Context, blocker int64, cursor pagination.Cursor, limit int64) ([]UserBlock, error)
+	// Exists blocker 有没有拉黑 blocked
+	Exists(ctx context.Context, blocker, blocked int64) (bool, error)
+	// ExistsEither 两个人之间有没有任何一方拉黑了对方
+	ExistsEither(ctx context.Context, uid1, uid2 int64) (bool, error)
+}
+
+type GORMBlockDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMBlockDAO(db *gorm.DB) BlockDAO {
+	return &GORMBlockDAO{db: db}
+}
+
+func (g *GORMBlockDAO) Block(ctx context.Context, blocker, blocked int64) ([]FollowRelation, error) {
+	now := time.Now().UnixMilli()
+	var actives []FollowRelation
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&UserBlock{
+			Blocker: blocker,
+			Blocked: blocked,
+			Ctime:   now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		// 在事务里面锁住还生效的关注关系，
+		// 避免并发的关注、取消关注让调用方按照过期的关系调整统计信息
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("((follower = ? AND followee = ?) OR (follower = ? AND followee = ?)) AND status = ?",
+				blocker, blocked, blocked, blocker, FollowRelationStatusActive).
+			Find(&actives).Error
+		if err != nil || len(actives) == 0 {
+			return err
+		}
+		ids := make([]int64, 0, len(actives))
+		for _, fr := range actives {
+			ids = append(ids, fr.ID)
+		}
+		return tx.Model(&FollowRelation{}).
+			Where("id IN ?", ids).
+			Updates(map[string]any{
+				"status": FollowRelationStatusInactive,
+				"utime":  now,
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return actives, nil
+}
+
+func (g *GORMBlockDAO) Unblock(ctx context.Context, blocker, blocked int64) error {
+	return g.db.WithContext(ctx).
+		Where("blocker = ? AND blocked = ?", blocker, blocked).
+		Delete(&UserBlock{}).Error
+}
+
+func (g *GORMBlockDAO) ListByCursor(ctx context.Context, blocker int64,
+	cursor pagination.Cursor, limit int64) ([]UserBlock, error) {
+	var res []UserBlock
+	db := g.db.WithContext(ctx).Where("blocker = ?", blocker)
+	if !cursor.IsZero() {
+		db = db.Where("(ctime < ? OR (ctime = ? AND id < ?))", cursor.Key, cursor.Key, cursor.Id)
+	}
+	err := db.Order("ctime DESC, id DESC").Limit(int(limit)).Find(&res).Error
+	return res, err
+}
+
+func (g *GORMBlockDAO) Exists(ctx context.Context, blocker, blocked int64) (bool, error) {
+	var cnt int64
+	err := g.db.WithContext(ctx).Model(&UserBlock{}).
+		Where("blocker = ? AND blocked = ?", blocker, blocked).
+		Count(&cnt).Error
+	return cnt > 0, err
+}
+
+func (g *GORMBlockDAO) ExistsEither(ctx context.Context, uid1, uid2 int64) (bool, error) {
+	var cnt int64
+	err := g.db.WithContext(ctx).Model(&UserBlock{}).
+		Where("(blocker = ? AND blocked = ?) OR (blocker = ? AND blocked = ?)",
+			uid1, uid2, uid2, uid1).
+		Count(&cnt).Error
+	return cnt > 0, err
+}
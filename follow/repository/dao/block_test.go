@@ -0,0 +1,103 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestGORMBlockDAO_Block(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(t *testing.T) *sql.DB
+
+		wantErr error
+		wantRes []FollowRelation
+	}{
+		{
+			name: "取消两个方向的关注",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `user_blocks` .* ON DUPLICATE KEY UPDATE").
+					WithArgs(int64(1), int64(2), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("SELECT \\* FROM `follow_relations` WHERE .* FOR UPDATE").
+					WithArgs(int64(1), int64(2), int64(2), int64(1), FollowRelationStatusActive).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "follower", "followee", "status", "type"}).
+						AddRow(10, 1, 2, FollowRelationStatusActive, FollowTypeNormal).
+						AddRow(11, 2, 1, FollowRelationStatusActive, FollowTypeSpecial))
+				mock.ExpectExec("UPDATE `follow_relations` SET `status`=\\?,`utime`=\\? WHERE id IN \\(\\?,\\?\\)").
+					WithArgs(FollowRelationStatusInactive, sqlmock.AnyArg(), int64(10), int64(11)).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+				return db
+			},
+			wantRes: []FollowRelation{
+				{ID: 10, Follower: 1, Followee: 2, Status: FollowRelationStatusActive, Type: FollowTypeNormal},
+				{ID: 11, Follower: 2, Followee: 1, Status: FollowRelationStatusActive, Type: FollowTypeSpecial},
+			},
+		},
+		{
+			name: "没有关注关系",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `user_blocks` .* ON DUPLICATE KEY UPDATE").
+					WithArgs(int64(1), int64(2), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("SELECT \\* FROM `follow_relations` WHERE .* FOR UPDATE").
+					WithArgs(int64(1), int64(2), int64(2), int64(1), FollowRelationStatusActive).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectCommit()
+				return db
+			},
+			wantRes: []FollowRelation{},
+		},
+		{
+			name: "取消关注失败，回滚",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `user_blocks` .* ON DUPLICATE KEY UPDATE").
+					WithArgs(int64(1), int64(2), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("SELECT \\* FROM `follow_relations` WHERE .* FOR UPDATE").
+					WithArgs(int64(1), int64(2), int64(2), int64(1), FollowRelationStatusActive).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "follower", "followee"}).AddRow(10, 1, 2))
+				mock.ExpectExec("UPDATE `follow_relations`").
+					WithArgs(FollowRelationStatusInactive, sqlmock.AnyArg(), int64(10)).
+					WillReturnError(errors.New("db 错误"))
+				mock.ExpectRollback()
+				return db
+			},
+			wantErr: errors.New("db 错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+				Conn:                      tc.mock(t),
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			require.NoError(t, err)
+			d := NewGORMBlockDAO(db)
+			res, err := d.Block(context.Background(), 1, 2)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
@@ -2,10 +2,14 @@ package dao
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+
+	"webooktrial/pkg/pagination"
 )
 
 type GORMFollowRelationDAO struct {
@@ -25,6 +29,76 @@ func (g *GORMFollowRelationDAO) FollowRelationList(ctx context.Context, follower
 	return res, err
 }
 
+func (g *GORMFollowRelationDAO) FollowRelationListByCursor(ctx context.Context, follower int64,
+	cursor pagination.Cursor, limit int64) ([]FollowRelation, error) {
+	var res []FollowRelation
+	db := g.db.WithContext(ctx).
+		Where("follower = ? AND status = ?", follower, FollowRelationStatusActive)
+	if !cursor.IsZero() {
+		db = db.Where("(utime < ? OR (utime = ? AND id < ?))", cursor.Key, cursor.Key, cursor.Id)
+	}
+	err := db.Order("utime DESC, id DESC").Limit(int(limit)).
+		Find(&res).Error
+	return res, err
+}
+
+func (g *GORMFollowRelationDAO) FollowerListByCursor(ctx context.Context, followee int64,
+	cursor pagination.Cursor, limit int64) ([]FollowRelation, error) {
+	var res []FollowRelation
+	db := g.db.WithContext(ctx).
+		Where("followee = ? AND status = ?", followee, FollowRelationStatusActive)
+	if !cursor.IsZero() {
+		db = db.Where("(utime < ? OR (utime = ? AND id < ?))", cursor.Key, cursor.Key, cursor.Id)
+	}
+	err := db.Order("utime DESC, id DESC").Limit(int(limit)).
+		Find(&res).Error
+	return res, err
+}
+
+func (g *GORMFollowRelationDAO) SpecialFollowerListByCursor(ctx context.Context, followee int64,
+	cursor pagination.Cursor, limit int64) ([]FollowRelation, error) {
+	var res []FollowRelation
+	// 特别关注的不多，走 followee_utime 索引过滤
+	db := g.db.WithContext(ctx).
+		Where("followee = ? AND status = ? AND type = ?", followee, FollowRelationStatusActive, FollowTypeSpecial)
+	if !cursor.IsZero() {
+		db = db.Where("(utime < ? OR (utime = ? AND id < ?))", cursor.Key, cursor.Key, cursor.Id)
+	}
+	err := db.Order("utime DESC, id DESC").Limit(int(limit)).
+		Find(&res).Error
+	return res, err
+}
+
+func (g *GORMFollowRelationDAO) MutualListByCursor(ctx context.Context, uid int64,
+	cursor pagination.Cursor, limit int64) ([]FollowRelation, error) {
+	var res []FollowRelation
+	// a 是 uid 关注别人，b 是别人关注 uid，b 走的是 follower_followee 唯一索引
+	db := g.db.WithContext(ctx).Table("follow_relations AS a").
+		Select("a.*").
+		Joins("JOIN follow_relations AS b ON b.follower = a.followee AND b.followee = a.follower AND b.status = ?",
+			FollowRelationStatusActive).
+		Where("a.follower = ? AND a.status = ?", uid, FollowRelationStatusActive)
+	if !cursor.IsZero() {
+		db = db.Where("(a.utime < ? OR (a.utime = ? AND a.id < ?))", cursor.Key, cursor.Key, cursor.Id)
+	}
+	err := db.Order("a.utime DESC, a.id DESC").Limit(int(limit)).
+		Find(&res).Error
+	return res, err
+}
+
+func (g *GORMFollowRelationDAO) FollowRelationListByGroup(ctx context.Context, follower, gid int64,
+	cursor pagination.Cursor, limit int64) ([]FollowRelation, error) {
+	var res []FollowRelation
+	db := g.db.WithContext(ctx).
+		Where("follower = ? AND gid = ? AND status = ?", follower, gid, FollowRelationStatusActive)
+	if !cursor.IsZero() {
+		db = db.Where("(utime < ? OR (utime = ? AND id < ?))", cursor.Key, cursor.Key, cursor.Id)
+	}
+	err := db.Order("utime DESC, id DESC").Limit(int(limit)).
+		Find(&res).Error
+	return res, err
+}
+
 func (g *GORMFollowRelationDAO) FollowRelationDetail(ctx context.Context, follower int64, followee int64) (FollowRelation, error) {
 	var res FollowRelation
 	err := g.db.WithContext(ctx).Where("follower = ? AND followee = ? AND status = ?",
@@ -32,6 +106,31 @@ func (g *GORMFollowRelationDAO) FollowRelationDetail(ctx context.Context, follow
 	return res, err
 }
 
+func (g *GORMFollowRelationDAO) FolloweesIn(ctx context.Context, follower int64, followees []int64) ([]int64, error) {
+	var res []int64
+	if len(followees) == 0 {
+		return res, nil
+	}
+	err := g.db.WithContext(ctx).Model(&FollowRelation{}).
+		Where("follower = ? AND followee IN ? AND status = ?",
+			follower, followees, FollowRelationStatusActive).
+		Pluck("followee", &res).Error
+	return res, err
+}
+
+func (g *GORMFollowRelationDAO) FollowersIn(ctx context.Context, followee int64, followers []int64) ([]int64, error) {
+	var res []int64
+	if len(followers) == 0 {
+		return res, nil
+	}
+	// 唯一索引是 <follower, followee>，followers 不多的时候也能用上
+	err := g.db.WithContext(ctx).Model(&FollowRelation{}).
+		Where("follower IN ? AND followee = ? AND status = ?",
+			followers, followee, FollowRelationStatusActive).
+		Pluck("follower", &res).Error
+	return res, err
+}
+
 func (g *GORMFollowRelationDAO) CreateFollowRelation(ctx context.Context, f FollowRelation) error {
 	now := time.Now().UnixMilli()
 	f.Utime = now
@@ -41,13 +140,28 @@ func (g *GORMFollowRelationDAO) CreateFollowRelation(ctx context.Context, f Foll
 		DoUpdates: clause.Assignments(map[string]interface{}{
 			"utime":  now,
 			"status": FollowRelationStatusActive,
+			"type":   f.Type,
+			"gid":    f.Gid,
+			"remark": f.Note,
 		}),
 	}).Create(&f).Error
 }
 
+func (g *GORMFollowRelationDAO) UpdatePrefs(ctx context.Context, f FollowRelation, columns []string) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	// utime 是关注的时间，列表按照它排序，所以这里不更新
+	return g.db.WithContext(ctx).Model(&FollowRelation{}).
+		Where("follower = ? AND followee = ? AND status = ?",
+			f.Follower, f.Followee, FollowRelationStatusActive).
+		Select(columns).
+		Updates(&f).Error
+}
+
 func (g *GORMFollowRelationDAO) UpdateStatus(ctx context.Context, followee int64, follower int64, status uint8) error {
 	now := time.Now().UnixMilli()
-	return g.db.WithContext(ctx).
+	return g.db.WithContext(ctx).Model(&FollowRelation{}).
 		Where("follower = ? AND followee = ?", follower, followee).
 		Updates(map[string]any{
 			"status": status,
@@ -57,7 +171,7 @@ func (g *GORMFollowRelationDAO) UpdateStatus(ctx context.Context, followee int64
 
 func (g *GORMFollowRelationDAO) CntFollower(ctx context.Context, uid int64) (int64, error) {
 	var res int64
-	err := g.db.WithContext(ctx).Select("count(follower)").
+	err := g.db.WithContext(ctx).Model(&FollowRelation{}).
 		Where("followee =? AND status = ?", uid, FollowRelationStatusActive).
 		Count(&res).Error
 	return res, err
@@ -65,9 +179,105 @@ func (g *GORMFollowRelationDAO) CntFollower(ctx context.Context, uid int64) (int
 
 func (g *GORMFollowRelationDAO) CntFollowee(ctx context.Context, uid int64) (int64, error) {
 	var res int64
-	err := g.db.WithContext(ctx).
-		Select("count(followee)").
+	err := g.db.WithContext(ctx).Model(&FollowRelation{}).
 		Where("follower = ? AND status = ?",
 			uid, FollowRelationStatusActive).Count(&res).Error
 	return res, err
 }
+
+func (g *GORMFollowRelationDAO) CntSpecial(ctx context.Context, uid int64) (int64, error) {
+	var res int64
+	err := g.db.WithContext(ctx).Model(&FollowRelation{}).
+		Where("follower = ? AND type = ? AND status = ?",
+			uid, FollowTypeSpecial, FollowRelationStatusActive).Count(&res).Error
+	return res, err
+}
+
+func (g *GORMFollowRelationDAO) CntByGroup(ctx context.Context, uid int64) (map[int64]int64, error) {
+	var rows []struct {
+		Gid int64
+		Cnt int64
+	}
+	err := g.db.WithContext(ctx).Model(&FollowRelation{}).
+		Select("gid, COUNT(*) AS cnt").
+		Where("follower = ? AND gid > 0 AND status = ?", uid, FollowRelationStatusActive).
+		Group("gid").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]int64, len(rows))
+	for _, r := range rows {
+		res[r.Gid] = r.Cnt
+	}
+	return res, nil
+}
+
+func (g *GORMFollowRelationDAO) CreateGroup(ctx context.Context, group FollowGroup) (int64, error) {
+	now := time.Now().UnixMilli()
+	group.Ctime = now
+	group.Utime = now
+	err := g.db.WithContext(ctx).Create(&group).Error
+	if isDuplicate(err) {
+		return 0, ErrDuplicateFollowGroup
+	}
+	return group.ID, err
+}
+
+func (g *GORMFollowRelationDAO) UpdateGroupName(ctx context.Context, uid, gid int64, name string) error {
+	res := g.db.WithContext(ctx).Model(&FollowGroup{}).
+		Where("id = ? AND uid = ?", gid, uid).
+		Updates(map[string]any{
+			"name":  name,
+			"utime": time.Now().UnixMilli(),
+		})
+	if isDuplicate(res.Error) {
+		return ErrDuplicateFollowGroup
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func (g *GORMFollowRelationDAO) DeleteGroup(ctx context.Context, uid, gid int64) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND uid = ?", gid, uid).Delete(&FollowGroup{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+		// 取消关注的记录也一起改，免得留下指向不存在的分组的数据
+		return tx.Model(&FollowRelation{}).
+			Where("follower = ? AND gid = ?", uid, gid).
+			Update("gid", 0).Error
+	})
+}
+
+func (g *GORMFollowRelationDAO) FindGroup(ctx context.Context, uid, gid int64) (FollowGroup, error) {
+	var res FollowGroup
+	err := g.db.WithContext(ctx).Where("id = ? AND uid = ?", gid, uid).First(&res).Error
+	return res, err
+}
+
+func (g *GORMFollowRelationDAO) ListGroups(ctx context.Context, uid int64) ([]FollowGroup, error) {
+	var res []FollowGroup
+	err := g.db.WithContext(ctx).Where("uid = ?", uid).Order("id ASC").Find(&res).Error
+	return res, err
+}
+
+func (g *GORMFollowRelationDAO) CntGroups(ctx context.Context, uid int64) (int64, error) {
+	var res int64
+	err := g.db.WithContext(ctx).Model(&FollowGroup{}).Where("uid = ?", uid).Count(&res).Error
+	return res, err
+}
+
+func isDuplicate(err error) bool {
+	var me *mysql.MySQLError
+	// 1062 是唯一索引冲突
+	return errors.As(err, &me) && me.Number == 1062
+}
@@ -0,0 +1,59 @@
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestGORMFollowRelationDAO_UpdatePrefs(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	// 只更新指定的列，移出分组的 0 也要写进去
+	mock.ExpectExec("UPDATE `follow_relations` SET `gid`=\\?,`remark`=\\? "+
+		"WHERE follower = \\? AND followee = \\? AND status = \\?").
+		WithArgs(int64(0), "同学", int64(1), int64(2), FollowRelationStatusActive).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	d := NewGORMFollowRelationDAO(db)
+	err = d.UpdatePrefs(context.Background(), FollowRelation{
+		Follower: 1, Followee: 2, Type: FollowTypeSpecial, Note: "同学",
+	}, []string{FollowColumnGid, FollowColumnNote})
+	assert.NoError(t, err)
+	// 没有要更新的列就不查数据库
+	assert.NoError(t, d.UpdatePrefs(context.Background(), FollowRelation{Follower: 1, Followee: 2}, nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGORMFollowRelationDAO_UpdateStatus(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	mock.ExpectExec("UPDATE `follow_relations` SET `status`=\\?,`utime`=\\? "+
+		"WHERE follower = \\? AND followee = \\?").
+		WithArgs(FollowRelationStatusInactive, sqlmock.AnyArg(), int64(1), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	// 取消关注
+	err = NewGORMFollowRelationDAO(db).UpdateStatus(context.Background(), 2, 1, FollowRelationStatusInactive)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
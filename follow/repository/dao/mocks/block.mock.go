@@ -0,0 +1,115 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./block.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./block.go -package=daomocks -destination=mocks/block.mock.go BlockDAO
+//
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	dao "webooktrial/follow/repository/dao"
+	pagination "webooktrial/pkg/pagination"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockBlockDAO is a mock of BlockDAO interface.
+type MockBlockDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlockDAOMockRecorder
+}
+
+// MockBlockDAOMockRecorder is the mock recorder for MockBlockDAO.
+type MockBlockDAOMockRecorder struct {
+	mock *MockBlockDAO
+}
+
+// NewMockBlockDAO creates a new mock instance.
+func NewMockBlockDAO(ctrl *gomock.Controller) *MockBlockDAO {
+	mock := &MockBlockDAO{ctrl: ctrl}
+	mock.recorder = &MockBlockDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlockDAO) EXPECT() *MockBlockDAOMockRecorder {
+	return m.recorder
+}
+
+// Block mocks base method.
+func (m *MockBlockDAO) Block(ctx context.Context, blocker, blocked int64) ([]dao.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Block", ctx, blocker, blocked)
+	ret0, _ := ret[0].([]dao.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Block indicates an expected call of Block.
+func (mr *MockBlockDAOMockRecorder) Block(ctx, blocker, blocked any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Block", reflect.TypeOf((*MockBlockDAO)(nil).Block), ctx, blocker, blocked)
+}
+
+// Exists mocks base method.
+func (m *MockBlockDAO) Exists(ctx context.Context, blocker, blocked int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exists", ctx, blocker, blocked)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exists indicates an expected call of Exists.
+func (mr *MockBlockDAOMockRecorder) Exists(ctx, blocker, blocked any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockBlockDAO)(nil).Exists), ctx, blocker, blocked)
+}
+
+// ExistsEither mocks base method.
+func (m *MockBlockDAO) ExistsEither(ctx context.Context, uid1, uid2 int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExistsEither", ctx, uid1, uid2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExistsEither indicates an expected call of ExistsEither.
+func (mr *MockBlockDAOMockRecorder) ExistsEither(ctx, uid1, uid2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExistsEither", reflect.TypeOf((*MockBlockDAO)(nil).ExistsEither), ctx, uid1, uid2)
+}
+
+// ListByCursor mocks base method.
+func (m *MockBlockDAO) ListByCursor(ctx context.Context, blocker int64, cursor pagination.Cursor, limit int64) ([]dao.UserBlock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByCursor", ctx, blocker, cursor, limit)
+	ret0, _ := ret[0].([]dao.UserBlock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByCursor indicates an expected call of ListByCursor.
+func (mr *MockBlockDAOMockRecorder) ListByCursor(ctx, blocker, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByCursor", reflect.TypeOf((*MockBlockDAO)(nil).ListByCursor), ctx, blocker, cursor, limit)
+}
+
+// Unblock mocks base method.
+func (m *MockBlockDAO) Unblock(ctx context.Context, blocker, blocked int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unblock", ctx, blocker, blocked)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unblock indicates an expected call of Unblock.
+func (mr *MockBlockDAOMockRecorder) Unblock(ctx, blocker, blocked any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unblock", reflect.TypeOf((*MockBlockDAO)(nil).Unblock), ctx, blocker, blocked)
+}
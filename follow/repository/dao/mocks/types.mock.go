@@ -0,0 +1,366 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./types.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./types.go -package=daomocks -destination=mocks/types.mock.go FollowRelationDao
+//
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	dao "webooktrial/follow/repository/dao"
+	pagination "webooktrial/pkg/pagination"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFollowRelationDao is a mock of FollowRelationDao interface.
+type MockFollowRelationDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockFollowRelationDaoMockRecorder
+}
+
+// MockFollowRelationDaoMockRecorder is the mock recorder for MockFollowRelationDao.
+type MockFollowRelationDaoMockRecorder struct {
+	mock *MockFollowRelationDao
+}
+
+// NewMockFollowRelationDao creates a new mock instance.
+func NewMockFollowRelationDao(ctrl *gomock.Controller) *MockFollowRelationDao {
+	mock := &MockFollowRelationDao{ctrl: ctrl}
+	mock.recorder = &MockFollowRelationDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFollowRelationDao) EXPECT() *MockFollowRelationDaoMockRecorder {
+	return m.recorder
+}
+
+// CntByGroup mocks base method.
+func (m *MockFollowRelationDao) CntByGroup(ctx context.Context, uid int64) (map[int64]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CntByGroup", ctx, uid)
+	ret0, _ := ret[0].(map[int64]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CntByGroup indicates an expected call of CntByGroup.
+func (mr *MockFollowRelationDaoMockRecorder) CntByGroup(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CntByGroup", reflect.TypeOf((*MockFollowRelationDao)(nil).CntByGroup), ctx, uid)
+}
+
+// CntFollowee mocks base method.
+func (m *MockFollowRelationDao) CntFollowee(ctx context.Context, uid int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CntFollowee", ctx, uid)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CntFollowee indicates an expected call of CntFollowee.
+func (mr *MockFollowRelationDaoMockRecorder) CntFollowee(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CntFollowee", reflect.TypeOf((*MockFollowRelationDao)(nil).CntFollowee), ctx, uid)
+}
+
+// CntFollower mocks base method.
+func (m *MockFollowRelationDao) CntFollower(ctx context.Context, uid int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CntFollower", ctx, uid)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CntFollower indicates an expected call of CntFollower.
+func (mr *MockFollowRelationDaoMockRecorder) CntFollower(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CntFollower", reflect.TypeOf((*MockFollowRelationDao)(nil).CntFollower), ctx, uid)
+}
+
+// CntGroups mocks base method.
+func (m *MockFollowRelationDao) CntGroups(ctx context.Context, uid int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CntGroups", ctx, uid)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CntGroups indicates an expected call of CntGroups.
+func (mr *MockFollowRelationDaoMockRecorder) CntGroups(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CntGroups", reflect.TypeOf((*MockFollowRelationDao)(nil).CntGroups), ctx, uid)
+}
+
+// CntSpecial mocks base method.
+func (m *MockFollowRelationDao) CntSpecial(ctx context.Context, uid int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CntSpecial", ctx, uid)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CntSpecial indicates an expected call of CntSpecial.
+func (mr *MockFollowRelationDaoMockRecorder) CntSpecial(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CntSpecial", reflect.TypeOf((*MockFollowRelationDao)(nil).CntSpecial), ctx, uid)
+}
+
+// CreateFollowRelation mocks base method.
+func (m *MockFollowRelationDao) CreateFollowRelation(ctx context.Context, f dao.FollowRelation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFollowRelation", ctx, f)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateFollowRelation indicates an expected call of CreateFollowRelation.
+func (mr *MockFollowRelationDaoMockRecorder) CreateFollowRelation(ctx, f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFollowRelation", reflect.TypeOf((*MockFollowRelationDao)(nil).CreateFollowRelation), ctx, f)
+}
+
+// CreateGroup mocks base method.
+func (m *MockFollowRelationDao) CreateGroup(ctx context.Context, g dao.FollowGroup) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGroup", ctx, g)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGroup indicates an expected call of CreateGroup.
+func (mr *MockFollowRelationDaoMockRecorder) CreateGroup(ctx, g any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroup", reflect.TypeOf((*MockFollowRelationDao)(nil).CreateGroup), ctx, g)
+}
+
+// DeleteGroup mocks base method.
+func (m *MockFollowRelationDao) DeleteGroup(ctx context.Context, uid, gid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGroup", ctx, uid, gid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGroup indicates an expected call of DeleteGroup.
+func (mr *MockFollowRelationDaoMockRecorder) DeleteGroup(ctx, uid, gid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGroup", reflect.TypeOf((*MockFollowRelationDao)(nil).DeleteGroup), ctx, uid, gid)
+}
+
+// FindGroup mocks base method.
+func (m *MockFollowRelationDao) FindGroup(ctx context.Context, uid, gid int64) (dao.FollowGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindGroup", ctx, uid, gid)
+	ret0, _ := ret[0].(dao.FollowGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindGroup indicates an expected call of FindGroup.
+func (mr *MockFollowRelationDaoMockRecorder) FindGroup(ctx, uid, gid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindGroup", reflect.TypeOf((*MockFollowRelationDao)(nil).FindGroup), ctx, uid, gid)
+}
+
+// FollowRelationDetail mocks base method.
+func (m *MockFollowRelationDao) FollowRelationDetail(ctx context.Context, follower, followee int64) (dao.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FollowRelationDetail", ctx, follower, followee)
+	ret0, _ := ret[0].(dao.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FollowRelationDetail indicates an expected call of FollowRelationDetail.
+func (mr *MockFollowRelationDaoMockRecorder) FollowRelationDetail(ctx, follower, followee any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FollowRelationDetail", reflect.TypeOf((*MockFollowRelationDao)(nil).FollowRelationDetail), ctx, follower, followee)
+}
+
+// FollowRelationList mocks base method.
+func (m *MockFollowRelationDao) FollowRelationList(ctx context.Context, follower, offset, limit int64) ([]dao.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FollowRelationList", ctx, follower, offset, limit)
+	ret0, _ := ret[0].([]dao.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FollowRelationList indicates an expected call of FollowRelationList.
+func (mr *MockFollowRelationDaoMockRecorder) FollowRelationList(ctx, follower, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FollowRelationList", reflect.TypeOf((*MockFollowRelationDao)(nil).FollowRelationList), ctx, follower, offset, limit)
+}
+
+// FollowRelationListByCursor mocks base method.
+func (m *MockFollowRelationDao) FollowRelationListByCursor(ctx context.Context, follower int64, cursor pagination.Cursor, limit int64) ([]dao.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FollowRelationListByCursor", ctx, follower, cursor, limit)
+	ret0, _ := ret[0].([]dao.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FollowRelationListByCursor indicates an expected call of FollowRelationListByCursor.
+func (mr *MockFollowRelationDaoMockRecorder) FollowRelationListByCursor(ctx, follower, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FollowRelationListByCursor", reflect.TypeOf((*MockFollowRelationDao)(nil).FollowRelationListByCursor), ctx, follower, cursor, limit)
+}
+
+// FollowRelationListByGroup mocks base method.
+func (m *MockFollowRelationDao) FollowRelationListByGroup(ctx context.Context, follower, gid int64, cursor pagination.Cursor, limit int64) ([]dao.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FollowRelationListByGroup", ctx, follower, gid, cursor, limit)
+	ret0, _ := ret[0].([]dao.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FollowRelationListByGroup indicates an expected call of FollowRelationListByGroup.
+func (mr *MockFollowRelationDaoMockRecorder) FollowRelationListByGroup(ctx, follower, gid, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FollowRelationListByGroup", reflect.TypeOf((*MockFollowRelationDao)(nil).FollowRelationListByGroup), ctx, follower, gid, cursor, limit)
+}
+
+// FolloweesIn mocks base method.
+func (m *MockFollowRelationDao) FolloweesIn(ctx context.Context, follower int64, followees []int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FolloweesIn", ctx, follower, followees)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FolloweesIn indicates an expected call of FolloweesIn.
+func (mr *MockFollowRelationDaoMockRecorder) FolloweesIn(ctx, follower, followees any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FolloweesIn", reflect.TypeOf((*MockFollowRelationDao)(nil).FolloweesIn), ctx, follower, followees)
+}
+
+// FollowerListByCursor mocks base method.
+func (m *MockFollowRelationDao) FollowerListByCursor(ctx context.Context, followee int64, cursor pagination.Cursor, limit int64) ([]dao.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FollowerListByCursor", ctx, followee, cursor, limit)
+	ret0, _ := ret[0].([]dao.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FollowerListByCursor indicates an expected call of FollowerListByCursor.
+func (mr *MockFollowRelationDaoMockRecorder) FollowerListByCursor(ctx, followee, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FollowerListByCursor", reflect.TypeOf((*MockFollowRelationDao)(nil).FollowerListByCursor), ctx, followee, cursor, limit)
+}
+
+// FollowersIn mocks base method.
+func (m *MockFollowRelationDao) FollowersIn(ctx context.Context, followee int64, followers []int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FollowersIn", ctx, followee, followers)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FollowersIn indicates an expected call of FollowersIn.
+func (mr *MockFollowRelationDaoMockRecorder) FollowersIn(ctx, followee, followers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FollowersIn", reflect.TypeOf((*MockFollowRelationDao)(nil).FollowersIn), ctx, followee, followers)
+}
+
+// ListGroups mocks base method.
+func (m *MockFollowRelationDao) ListGroups(ctx context.Context, uid int64) ([]dao.FollowGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGroups", ctx, uid)
+	ret0, _ := ret[0].([]dao.FollowGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListGroups indicates an expected call of ListGroups.
+func (mr *MockFollowRelationDaoMockRecorder) ListGroups(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGroups", reflect.TypeOf((*MockFollowRelationDao)(nil).ListGroups), ctx, uid)
+}
+
+// MutualListByCursor mocks base method.
+func (m *MockFollowRelationDao) MutualListByCursor(ctx context.Context, uid int64, cursor pagination.Cursor, limit int64) ([]dao.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MutualListByCursor", ctx, uid, cursor, limit)
+	ret0, _ := ret[0].([]dao.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MutualListByCursor indicates an expected call of MutualListByCursor.
+func (mr *MockFollowRelationDaoMockRecorder) MutualListByCursor(ctx, uid, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MutualListByCursor", reflect.TypeOf((*MockFollowRelationDao)(nil).MutualListByCursor), ctx, uid, cursor, limit)
+}
+
+// SpecialFollowerListByCursor mocks base method.
+func (m *MockFollowRelationDao) SpecialFollowerListByCursor(ctx context.Context, followee int64, cursor pagination.Cursor, limit int64) ([]dao.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SpecialFollowerListByCursor", ctx, followee, cursor, limit)
+	ret0, _ := ret[0].([]dao.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SpecialFollowerListByCursor indicates an expected call of SpecialFollowerListByCursor.
+func (mr *MockFollowRelationDaoMockRecorder) SpecialFollowerListByCursor(ctx, followee, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SpecialFollowerListByCursor", reflect.TypeOf((*MockFollowRelationDao)(nil).SpecialFollowerListByCursor), ctx, followee, cursor, limit)
+}
+
+// UpdateGroupName mocks base method.
+func (m *MockFollowRelationDao) UpdateGroupName(ctx context.Context, uid, gid int64, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateGroupName", ctx, uid, gid, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateGroupName indicates an expected call of UpdateGroupName.
+func (mr *MockFollowRelationDaoMockRecorder) UpdateGroupName(ctx, uid, gid, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGroupName", reflect.TypeOf((*MockFollowRelationDao)(nil).UpdateGroupName), ctx, uid, gid, name)
+}
+
+// UpdatePrefs mocks base method.
+func (m *MockFollowRelationDao) UpdatePrefs(ctx context.Context, f dao.FollowRelation, columns []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePrefs", ctx, f, columns)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePrefs indicates an expected call of UpdatePrefs.
+func (mr *MockFollowRelationDaoMockRecorder) UpdatePrefs(ctx, f, columns any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePrefs", reflect.TypeOf((*MockFollowRelationDao)(nil).UpdatePrefs), ctx, f, columns)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockFollowRelationDao) UpdateStatus(ctx context.Context, followee, follower int64, status uint8) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, followee, follower, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockFollowRelationDaoMockRecorder) UpdateStatus(ctx, followee, follower, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockFollowRelationDao)(nil).UpdateStatus), ctx, followee, follower, status)
+}
@@ -1,6 +1,25 @@
 package dao
 
-import "context"
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"webooktrial/pkg/pagination"
+)
+
+var (
+	ErrRecordNotFound       = gorm.ErrRecordNotFound
+	ErrDuplicateFollowGroup = errors.New("分组名字重复了")
+)
+
+// FollowRelation 里面可以单独修改的列
+const (
+	FollowColumnType = "type"
+	FollowColumnGid  = "gid"
+	FollowColumnNote = "remark"
+)
 
 // FollowRelation 这个是类似于点赞的表设计
 // 取消关注，不是真的删除了数据，而是更新了状态
@@ -14,19 +33,38 @@ type FollowRelation struct {
 
 	// 如果我的典型场景是，我有多少粉丝 WHERE followee = ? （传入 uid = 123)
 	// 这种情况下 <followee, follower> 在后
-	Follower int64 `gorm:"type:int(11);not null;uniqueIndex:follower_followee"`
-	Followee int64 `gorm:"type:int(11);not null;uniqueIndex:follower_followee"`
+	// 粉丝列表也要用，所以还有一个 <followee, utime> 的索引
+	Follower int64 `gorm:"type:int(11);not null;uniqueIndex:follower_followee;index:follower_utime,priority:1;index:follower_gid_utime,priority:1"`
+	Followee int64 `gorm:"type:int(11);not null;uniqueIndex:follower_followee;index:followee_utime,priority:1"`
 
 	// 对应于关注来说，就是插入或者将这个状态更新为可用状态
 	// 对于取消关注来说，就是将这个状态更新为不可用状态
 	Status uint8
 
-	// 这里你可以根据自己的业务来增加字段，比如说
-	// 关系类型，可以搞些什么普通关注，特殊关注
-	// Type int64 `gorm:"column:type;type:int(11);comment:关注类型 0-普通关注"`
-	// 备注
-	// Note string `gorm:"column:remark;type:varchar(255);"`
+	// 关系类型，普通关注或者特别关注
+	Type uint8 `gorm:"column:type;not null;default:0;comment:关注类型 0-普通关注 1-特别关注"`
+	// Gid 分组，0 表示没有分组，按照分组翻页关注列表
+	Gid int64 `gorm:"not null;default:0;index:follower_gid_utime,priority:2"`
+	// 备注，只有 follower 自己能看到
+	Note string `gorm:"column:remark;type:varchar(255);"`
 	// 创建时间
+	Ctime int64
+	// 关注列表和粉丝列表都按照 utime 翻页
+	Utime int64 `gorm:"index:follower_utime,priority:2;index:followee_utime,priority:2;index:follower_gid_utime,priority:3"`
+}
+
+const (
+	FollowTypeNormal uint8 = iota
+	// FollowTypeSpecial 特别关注，followee 发了新的内容会主动推给 follower
+	FollowTypeSpecial
+)
+
+// FollowGroup 关注分组，分组里面有哪些人记录在 FollowRelation.Gid 上
+type FollowGroup struct {
+	ID   int64  `gorm:"primaryKey,autoIncrement,column:id"`
+	Uid  int64  `gorm:"not null;uniqueIndex:uid_name"`
+	Name string `gorm:"type:varchar(64);not null;uniqueIndex:uid_name"`
+
 	Ctime int64
 	Utime int64
 }
@@ -37,18 +75,50 @@ const (
 	FollowRelationStatusInactive
 )
 
+//go:generate mockgen -source=./types.go -package=daomocks -destination=mocks/types.mock.go FollowRelationDao
 type FollowRelationDao interface {
 	// FollowRelationList 获取某人的关注列表
+	// Deprecated: 深分页性能差，用 FollowRelationListByCursor
 	FollowRelationList(ctx context.Context, follower, offset, limit int64) ([]FollowRelation, error)
+	// FollowRelationListByCursor 按照 utime 和 id 倒序，cursor 的 Key 是 utime，零值表示第一页
+	FollowRelationListByCursor(ctx context.Context, follower int64, cursor pagination.Cursor, limit int64) ([]FollowRelation, error)
+	// FollowerListByCursor 获取某人的粉丝列表，排序和游标同 FollowRelationListByCursor
+	FollowerListByCursor(ctx context.Context, followee int64, cursor pagination.Cursor, limit int64) ([]FollowRelation, error)
+	// SpecialFollowerListByCursor 特别关注了 followee 的粉丝，排序和游标同 FollowerListByCursor
+	SpecialFollowerListByCursor(ctx context.Context, followee int64, cursor pagination.Cursor, limit int64) ([]FollowRelation, error)
+	// MutualListByCursor 获取和某人互相关注的人，返回的是 uid 关注对方的那一条，排序和游标同 FollowRelationListByCursor
+	MutualListByCursor(ctx context.Context, uid int64, cursor pagination.Cursor, limit int64) ([]FollowRelation, error)
 	FollowRelationDetail(ctx context.Context, follower int64, followee int64) (FollowRelation, error)
-	// CreateFollowRelation 创建联系人
+	// FolloweesIn follower 关注了 followees 里面的哪些人
+	FolloweesIn(ctx context.Context, follower int64, followees []int64) ([]int64, error)
+	// FollowersIn followers 里面的哪些人关注了 followee
+	FollowersIn(ctx context.Context, followee int64, followers []int64) ([]int64, error)
+	// FollowRelationListByGroup 获取某人某个分组里面的关注列表，gid 为 0 表示没有分组的，排序和游标同 FollowRelationListByCursor
+	FollowRelationListByGroup(ctx context.Context, follower, gid int64, cursor pagination.Cursor, limit int64) ([]FollowRelation, error)
+	// CreateFollowRelation 创建联系人，已经存在的话会覆盖类型、分组和备注
 	CreateFollowRelation(ctx context.Context, f FollowRelation) error
+	// UpdatePrefs 更新类型、分组和备注里面 columns 指定的列，不会改变关注的时间
+	UpdatePrefs(ctx context.Context, f FollowRelation, columns []string) error
 	// UpdateStatus 更新状态
 	UpdateStatus(ctx context.Context, followee int64, follower int64, status uint8) error
 	// CntFollower 统计计算关注自己的人有多少
 	CntFollower(ctx context.Context, uid int64) (int64, error)
 	// CntFollowee 统计自己关注了多少人
 	CntFollowee(ctx context.Context, uid int64) (int64, error)
+	// CntSpecial 统计自己特别关注了多少人
+	CntSpecial(ctx context.Context, uid int64) (int64, error)
+	// CntByGroup 统计自己每个分组里面有多少人，没有分组的不统计
+	CntByGroup(ctx context.Context, uid int64) (map[int64]int64, error)
+
+	// CreateGroup 名字重复的时候返回 ErrDuplicateFollowGroup
+	CreateGroup(ctx context.Context, g FollowGroup) (int64, error)
+	// UpdateGroupName 不是 uid 的分组的时候返回 ErrRecordNotFound
+	UpdateGroupName(ctx context.Context, uid, gid int64, name string) error
+	// DeleteGroup 删除分组，分组里面的人变成没有分组
+	DeleteGroup(ctx context.Context, uid, gid int64) error
+	FindGroup(ctx context.Context, uid, gid int64) (FollowGroup, error)
+	ListGroups(ctx context.Context, uid int64) ([]FollowGroup, error)
+	CntGroups(ctx context.Context, uid int64) (int64, error)
 }
 
 // UserRelation 另外一种设计方案，但是不要这么做
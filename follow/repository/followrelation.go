@@ -2,23 +2,58 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"webooktrial/follow/domain"
 	"webooktrial/follow/repository/cache"
 	"webooktrial/follow/repository/dao"
 	"webooktrial/pkg/logger"
+	"webooktrial/pkg/pagination"
+)
+
+//go:generate mockgen -source=./followrelation.go -package=repomocks -destination=mocks/followrelation.mock.go FollowRepository
+var (
+	ErrFollowRelationNotFound = dao.ErrRecordNotFound
+	ErrFollowGroupNotFound    = errors.New("分组不存在")
+	ErrDuplicateFollowGroup   = dao.ErrDuplicateFollowGroup
 )
 
 type FollowRepository interface {
 	// GetFollowee 获取某人的关注列表
+	// Deprecated: 用 GetFolloweeByCursor
 	GetFollowee(ctx context.Context, follower, offset, limit int64) ([]domain.FollowRelation, error)
+	// GetFolloweeByCursor 按照关注时间倒序，cursor 零值表示第一页
+	GetFolloweeByCursor(ctx context.Context, follower int64, cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error)
+	// GetFollowerByCursor 获取某人的粉丝列表，按照关注时间倒序
+	GetFollowerByCursor(ctx context.Context, followee int64, cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error)
+	// GetSpecialFollowerByCursor 特别关注了 followee 的粉丝，排序和 GetFollowerByCursor 一样
+	GetSpecialFollowerByCursor(ctx context.Context, followee int64, cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error)
+	// GetMutualByCursor 获取和某人互相关注的人，返回 uid 关注对方的那一条
+	GetMutualByCursor(ctx context.Context, uid int64, cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error)
+	// FollowStatus uid 和 targets 里面每个人的关注关系，顺序和 targets 一样
+	FollowStatus(ctx context.Context, uid int64, targets []int64) ([]domain.FollowStatus, error)
 	// FollowInfo 查看关注人的详情
 	FollowInfo(ctx context.Context, follower int64, followee int64) (domain.FollowRelation, error)
-	// AddFollowRelation 创建关注关系
+	// GetFolloweeByGroup 获取某个分组里面的关注列表，gid 为 0 表示没有分组的
+	GetFolloweeByGroup(ctx context.Context, follower, gid int64, cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error)
+	// AddFollowRelation 创建关注关系，已经关注了的话更新分组、特别关注和备注
 	AddFollowRelation(ctx context.Context, f domain.FollowRelation) error
+	// UpdateFollowPrefs 更新分组、特别关注和备注里面 fields 指定的字段，
+	// 没有关注的时候返回 ErrFollowRelationNotFound
+	UpdateFollowPrefs(ctx context.Context, f domain.FollowRelation, fields domain.FollowPrefsField) error
 	// InactiveFollowRelation 取消关注
 	InactiveFollowRelation(ctx context.Context, follower int64, followee int64) error
 	GetFollowStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+
+	CreateGroup(ctx context.Context, g domain.FollowGroup) (int64, error)
+	RenameGroup(ctx context.Context, uid, gid int64, name string) error
+	DeleteGroup(ctx context.Context, uid, gid int64) error
+	// FindGroup 不是 uid 的分组也返回 ErrFollowGroupNotFound
+	FindGroup(ctx context.Context, uid, gid int64) (domain.FollowGroup, error)
+	// ListGroups 会带上每个分组里面有多少人
+	ListGroups(ctx context.Context, uid int64) ([]domain.FollowGroup, error)
+	CntGroups(ctx context.Context, uid int64) (int64, error)
 }
 
 type CachedRelationRepository struct {
@@ -39,6 +74,72 @@ func (c *CachedRelationRepository) GetFollowee(ctx context.Context, follower, of
 	return c.genFollowRelationList(followerList), nil
 }
 
+func (c *CachedRelationRepository) GetFolloweeByCursor(ctx context.Context, follower int64,
+	cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error) {
+	followerList, err := c.dao.FollowRelationListByCursor(ctx, follower, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	return c.genFollowRelationList(followerList), nil
+}
+
+func (c *CachedRelationRepository) GetFollowerByCursor(ctx context.Context, followee int64,
+	cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error) {
+	followerList, err := c.dao.FollowerListByCursor(ctx, followee, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	return c.genFollowRelationList(followerList), nil
+}
+
+func (c *CachedRelationRepository) GetSpecialFollowerByCursor(ctx context.Context, followee int64,
+	cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error) {
+	list, err := c.dao.SpecialFollowerListByCursor(ctx, followee, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	return c.genFollowRelationList(list), nil
+}
+
+func (c *CachedRelationRepository) GetMutualByCursor(ctx context.Context, uid int64,
+	cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error) {
+	list, err := c.dao.MutualListByCursor(ctx, uid, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := c.genFollowRelationList(list)
+	for i := range res {
+		res[i].Mutual = true
+	}
+	return res, nil
+}
+
+func (c *CachedRelationRepository) FollowStatus(ctx context.Context, uid int64, targets []int64) ([]domain.FollowStatus, error) {
+	followees, err := c.dao.FolloweesIn(ctx, uid, targets)
+	if err != nil {
+		return nil, err
+	}
+	followers, err := c.dao.FollowersIn(ctx, uid, targets)
+	if err != nil {
+		return nil, err
+	}
+	following := make(map[int64]struct{}, len(followees))
+	for _, id := range followees {
+		following[id] = struct{}{}
+	}
+	followedBy := make(map[int64]struct{}, len(followers))
+	for _, id := range followers {
+		followedBy[id] = struct{}{}
+	}
+	res := make([]domain.FollowStatus, 0, len(targets))
+	for _, target := range targets {
+		_, ok1 := following[target]
+		_, ok2 := followedBy[target]
+		res = append(res, domain.FollowStatus{Uid: target, Following: ok1, FollowedBy: ok2})
+	}
+	return res, nil
+}
+
 func (c *CachedRelationRepository) FollowInfo(ctx context.Context, follower int64, followee int64) (domain.FollowRelation, error) {
 	f, err := c.dao.FollowRelationDetail(ctx, follower, followee)
 	if err != nil {
@@ -47,20 +148,134 @@ func (c *CachedRelationRepository) FollowInfo(ctx context.Context, follower int6
 	return c.toDomain(f), nil
 }
 
+func (c *CachedRelationRepository) GetFolloweeByGroup(ctx context.Context, follower, gid int64,
+	cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error) {
+	list, err := c.dao.FollowRelationListByGroup(ctx, follower, gid, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	return c.genFollowRelationList(list), nil
+}
+
 func (c *CachedRelationRepository) AddFollowRelation(ctx context.Context, f domain.FollowRelation) error {
-	err := c.dao.CreateFollowRelation(ctx, c.toEntity(f))
+	// 重复关注的时候统计信息不能再加一次，只调整分组和特别关注
+	old, err := c.dao.FollowRelationDetail(ctx, f.Follower, f.Followee)
+	active := err == nil
+	if err != nil && !errors.Is(err, dao.ErrRecordNotFound) {
+		return err
+	}
+	err = c.dao.CreateFollowRelation(ctx, c.toEntity(f))
 	if err != nil {
 		return err
 	}
-	return c.cache.Follow(ctx, f.Follower, f.Followee)
+	if active {
+		return c.cache.UpdatePrefs(ctx, c.toDomain(old), f)
+	}
+	return c.cache.Follow(ctx, f)
+}
+
+func (c *CachedRelationRepository) UpdateFollowPrefs(ctx context.Context, f domain.FollowRelation,
+	fields domain.FollowPrefsField) error {
+	old, err := c.dao.FollowRelationDetail(ctx, f.Follower, f.Followee)
+	if err != nil {
+		return err
+	}
+	// 没有指定的字段保留原来的值，统计信息也按照合并之后的调整
+	merged := c.toDomain(old)
+	columns := make([]string, 0, 3)
+	if fields.Has(domain.FollowPrefsGid) {
+		merged.Gid = f.Gid
+		columns = append(columns, dao.FollowColumnGid)
+	}
+	if fields.Has(domain.FollowPrefsSpecial) {
+		merged.Special = f.Special
+		columns = append(columns, dao.FollowColumnType)
+	}
+	if fields.Has(domain.FollowPrefsNote) {
+		merged.Note = f.Note
+		columns = append(columns, dao.FollowColumnNote)
+	}
+	err = c.dao.UpdatePrefs(ctx, c.toEntity(merged), columns)
+	if err != nil {
+		return err
+	}
+	return c.cache.UpdatePrefs(ctx, c.toDomain(old), merged)
 }
 
 func (c *CachedRelationRepository) InactiveFollowRelation(ctx context.Context, follower int64, followee int64) error {
-	err := c.dao.UpdateStatus(ctx, followee, follower, dao.FollowRelationStatusInactive)
+	// 要知道原本的分组和特别关注，才能调整统计信息
+	old, err := c.dao.FollowRelationDetail(ctx, follower, followee)
+	if errors.Is(err, dao.ErrRecordNotFound) {
+		// 本来就没有关注
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = c.dao.UpdateStatus(ctx, followee, follower, dao.FollowRelationStatusInactive)
+	if err != nil {
+		return err
+	}
+	return c.cache.CancelFollow(ctx, c.toDomain(old))
+}
+
+func (c *CachedRelationRepository) CreateGroup(ctx context.Context, g domain.FollowGroup) (int64, error) {
+	return c.dao.CreateGroup(ctx, dao.FollowGroup{Uid: g.Uid, Name: g.Name})
+}
+
+func (c *CachedRelationRepository) RenameGroup(ctx context.Context, uid, gid int64, name string) error {
+	err := c.dao.UpdateGroupName(ctx, uid, gid, name)
+	if errors.Is(err, dao.ErrRecordNotFound) {
+		return ErrFollowGroupNotFound
+	}
+	return err
+}
+
+func (c *CachedRelationRepository) DeleteGroup(ctx context.Context, uid, gid int64) error {
+	err := c.dao.DeleteGroup(ctx, uid, gid)
+	if errors.Is(err, dao.ErrRecordNotFound) {
+		return ErrFollowGroupNotFound
+	}
 	if err != nil {
 		return err
 	}
-	return c.cache.CancelFollow(ctx, follower, followee)
+	return c.cache.DeleteGroup(ctx, uid, gid)
+}
+
+func (c *CachedRelationRepository) FindGroup(ctx context.Context, uid, gid int64) (domain.FollowGroup, error) {
+	g, err := c.dao.FindGroup(ctx, uid, gid)
+	if errors.Is(err, dao.ErrRecordNotFound) {
+		return domain.FollowGroup{}, ErrFollowGroupNotFound
+	}
+	if err != nil {
+		return domain.FollowGroup{}, err
+	}
+	return domain.FollowGroup{Id: g.ID, Uid: g.Uid, Name: g.Name}, nil
+}
+
+func (c *CachedRelationRepository) ListGroups(ctx context.Context, uid int64) ([]domain.FollowGroup, error) {
+	groups, err := c.dao.ListGroups(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	statics, err := c.GetFollowStatics(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.FollowGroup, 0, len(groups))
+	for _, g := range groups {
+		res = append(res, domain.FollowGroup{
+			Id:   g.ID,
+			Uid:  g.Uid,
+			Name: g.Name,
+			Cnt:  statics.Groups[g.ID],
+		})
+	}
+	return res, nil
+}
+
+func (c *CachedRelationRepository) CntGroups(ctx context.Context, uid int64) (int64, error) {
+	return c.dao.CntGroups(ctx, uid)
 }
 
 func (c *CachedRelationRepository) GetFollowStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
@@ -78,6 +293,14 @@ func (c *CachedRelationRepository) GetFollowStatics(ctx context.Context, uid int
 	if err != nil {
 		return res, err
 	}
+	res.Specials, err = c.dao.CntSpecial(ctx, uid)
+	if err != nil {
+		return res, err
+	}
+	res.Groups, err = c.dao.CntByGroup(ctx, uid)
+	if err != nil {
+		return res, err
+	}
 	err = c.cache.SetStaticsInfo(ctx, uid, res)
 	if err != nil {
 		// 这里记录日志
@@ -98,14 +321,26 @@ func (c *CachedRelationRepository) genFollowRelationList(followerList []dao.Foll
 
 func (c *CachedRelationRepository) toDomain(fr dao.FollowRelation) domain.FollowRelation {
 	return domain.FollowRelation{
+		Id:       fr.ID,
 		Followee: fr.Followee,
 		Follower: fr.Follower,
+		Gid:      fr.Gid,
+		Special:  fr.Type == dao.FollowTypeSpecial,
+		Note:     fr.Note,
+		Utime:    time.UnixMilli(fr.Utime),
 	}
 }
 
 func (c *CachedRelationRepository) toEntity(f domain.FollowRelation) dao.FollowRelation {
+	typ := dao.FollowTypeNormal
+	if f.Special {
+		typ = dao.FollowTypeSpecial
+	}
 	return dao.FollowRelation{
 		Followee: f.Followee,
 		Follower: f.Follower,
+		Type:     typ,
+		Gid:      f.Gid,
+		Note:     f.Note,
 	}
 }
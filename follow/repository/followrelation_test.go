@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"webooktrial/follow/domain"
+	"webooktrial/follow/repository/cache"
+	cachemocks "webooktrial/follow/repository/cache/mocks"
+	"webooktrial/follow/repository/dao"
+	daomocks "webooktrial/follow/repository/dao/mocks"
+	"webooktrial/pkg/logger"
+)
+
+func TestCachedRelationRepository_UpdateFollowPrefs(t *testing.T) {
+	old := dao.FollowRelation{Follower: 1, Followee: 2, Type: dao.FollowTypeSpecial, Gid: 3, Note: "老同事", Utime: 100}
+	// 关注的时间不会变
+	utime := time.UnixMilli(100)
+	testCases := []struct {
+		name   string
+		mock   func(ctrl *gomock.Controller) (dao.FollowRelationDao, cache.FollowCache)
+		f      domain.FollowRelation
+		fields domain.FollowPrefsField
+
+		wantErr error
+	}{
+		{
+			name: "只改备注，分组和特别关注保持原样",
+			mock: func(ctrl *gomock.Controller) (dao.FollowRelationDao, cache.FollowCache) {
+				d := daomocks.NewMockFollowRelationDao(ctrl)
+				d.EXPECT().FollowRelationDetail(gomock.Any(), int64(1), int64(2)).Return(old, nil)
+				d.EXPECT().UpdatePrefs(gomock.Any(), dao.FollowRelation{
+					Follower: 1, Followee: 2, Type: dao.FollowTypeSpecial, Gid: 3, Note: "大学同学",
+				}, []string{dao.FollowColumnNote}).Return(nil)
+				c := cachemocks.NewMockFollowCache(ctrl)
+				c.EXPECT().UpdatePrefs(gomock.Any(),
+					domain.FollowRelation{Follower: 1, Followee: 2, Special: true, Gid: 3, Note: "老同事", Utime: utime},
+					domain.FollowRelation{Follower: 1, Followee: 2, Special: true, Gid: 3, Note: "大学同学", Utime: utime}).
+					Return(nil)
+				return d, c
+			},
+			f:      domain.FollowRelation{Follower: 1, Followee: 2, Note: "大学同学"},
+			fields: domain.FollowPrefsNote,
+		},
+		{
+			name: "移出分组，取消特别关注",
+			mock: func(ctrl *gomock.Controller) (dao.FollowRelationDao, cache.FollowCache) {
+				d := daomocks.NewMockFollowRelationDao(ctrl)
+				d.EXPECT().FollowRelationDetail(gomock.Any(), int64(1), int64(2)).Return(old, nil)
+				d.EXPECT().UpdatePrefs(gomock.Any(), dao.FollowRelation{
+					Follower: 1, Followee: 2, Type: dao.FollowTypeNormal, Note: "老同事",
+				}, []string{dao.FollowColumnGid, dao.FollowColumnType}).Return(nil)
+				c := cachemocks.NewMockFollowCache(ctrl)
+				c.EXPECT().UpdatePrefs(gomock.Any(),
+					domain.FollowRelation{Follower: 1, Followee: 2, Special: true, Gid: 3, Note: "老同事", Utime: utime},
+					domain.FollowRelation{Follower: 1, Followee: 2, Note: "老同事", Utime: utime}).
+					Return(nil)
+				return d, c
+			},
+			f:      domain.FollowRelation{Follower: 1, Followee: 2},
+			fields: domain.FollowPrefsGid | domain.FollowPrefsSpecial,
+		},
+		{
+			name: "没有关注",
+			mock: func(ctrl *gomock.Controller) (dao.FollowRelationDao, cache.FollowCache) {
+				d := daomocks.NewMockFollowRelationDao(ctrl)
+				d.EXPECT().FollowRelationDetail(gomock.Any(), int64(1), int64(2)).
+					Return(dao.FollowRelation{}, dao.ErrRecordNotFound)
+				return d, cachemocks.NewMockFollowCache(ctrl)
+			},
+			f:       domain.FollowRelation{Follower: 1, Followee: 2},
+			fields:  domain.FollowPrefsAll,
+			wantErr: dao.ErrRecordNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, c := tc.mock(ctrl)
+			repo := NewCachedRelationRepository(d, c, logger.NewNopLogger())
+			err := repo.UpdateFollowPrefs(context.Background(), tc.f, tc.fields)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
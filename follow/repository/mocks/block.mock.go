@@ -0,0 +1,114 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./block.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./block.go -package=repomocks -destination=mocks/block.mock.go BlockRepository
+//
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "webooktrial/follow/domain"
+	pagination "webooktrial/pkg/pagination"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockBlockRepository is a mock of BlockRepository interface.
+type MockBlockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlockRepositoryMockRecorder
+}
+
+// MockBlockRepositoryMockRecorder is the mock recorder for MockBlockRepository.
+type MockBlockRepositoryMockRecorder struct {
+	mock *MockBlockRepository
+}
+
+// NewMockBlockRepository creates a new mock instance.
+func NewMockBlockRepository(ctrl *gomock.Controller) *MockBlockRepository {
+	mock := &MockBlockRepository{ctrl: ctrl}
+	mock.recorder = &MockBlockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlockRepository) EXPECT() *MockBlockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Block mocks base method.
+func (m *MockBlockRepository) Block(ctx context.Context, blocker, blocked int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Block", ctx, blocker, blocked)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Block indicates an expected call of Block.
+func (mr *MockBlockRepositoryMockRecorder) Block(ctx, blocker, blocked any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Block", reflect.TypeOf((*MockBlockRepository)(nil).Block), ctx, blocker, blocked)
+}
+
+// IsBlocked mocks base method.
+func (m *MockBlockRepository) IsBlocked(ctx context.Context, blocker, blocked int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBlocked", ctx, blocker, blocked)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsBlocked indicates an expected call of IsBlocked.
+func (mr *MockBlockRepositoryMockRecorder) IsBlocked(ctx, blocker, blocked any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBlocked", reflect.TypeOf((*MockBlockRepository)(nil).IsBlocked), ctx, blocker, blocked)
+}
+
+// IsBlockedEither mocks base method.
+func (m *MockBlockRepository) IsBlockedEither(ctx context.Context, uid1, uid2 int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBlockedEither", ctx, uid1, uid2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsBlockedEither indicates an expected call of IsBlockedEither.
+func (mr *MockBlockRepositoryMockRecorder) IsBlockedEither(ctx, uid1, uid2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBlockedEither", reflect.TypeOf((*MockBlockRepository)(nil).IsBlockedEither), ctx, uid1, uid2)
+}
+
+// ListBlocked mocks base method.
+func (m *MockBlockRepository) ListBlocked(ctx context.Context, blocker int64, cursor pagination.Cursor, limit int64) ([]domain.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBlocked", ctx, blocker, cursor, limit)
+	ret0, _ := ret[0].([]domain.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBlocked indicates an expected call of ListBlocked.
+func (mr *MockBlockRepositoryMockRecorder) ListBlocked(ctx, blocker, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBlocked", reflect.TypeOf((*MockBlockRepository)(nil).ListBlocked), ctx, blocker, cursor, limit)
+}
+
+// Unblock mocks base method.
+func (m *MockBlockRepository) Unblock(ctx context.Context, blocker, blocked int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unblock", ctx, blocker, blocked)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unblock indicates an expected call of Unblock.
+func (mr *MockBlockRepositoryMockRecorder) Unblock(ctx, blocker, blocked any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unblock", reflect.TypeOf((*MockBlockRepository)(nil).Unblock), ctx, blocker, blocked)
+}
@@ -0,0 +1,306 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./followrelation.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./followrelation.go -package=repomocks -destination=mocks/followrelation.mock.go FollowRepository
+//
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "webooktrial/follow/domain"
+	pagination "webooktrial/pkg/pagination"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFollowRepository is a mock of FollowRepository interface.
+type MockFollowRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFollowRepositoryMockRecorder
+}
+
+// MockFollowRepositoryMockRecorder is the mock recorder for MockFollowRepository.
+type MockFollowRepositoryMockRecorder struct {
+	mock *MockFollowRepository
+}
+
+// NewMockFollowRepository creates a new mock instance.
+func NewMockFollowRepository(ctrl *gomock.Controller) *MockFollowRepository {
+	mock := &MockFollowRepository{ctrl: ctrl}
+	mock.recorder = &MockFollowRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFollowRepository) EXPECT() *MockFollowRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddFollowRelation mocks base method.
+func (m *MockFollowRepository) AddFollowRelation(ctx context.Context, f domain.FollowRelation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFollowRelation", ctx, f)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddFollowRelation indicates an expected call of AddFollowRelation.
+func (mr *MockFollowRepositoryMockRecorder) AddFollowRelation(ctx, f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFollowRelation", reflect.TypeOf((*MockFollowRepository)(nil).AddFollowRelation), ctx, f)
+}
+
+// CntGroups mocks base method.
+func (m *MockFollowRepository) CntGroups(ctx context.Context, uid int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CntGroups", ctx, uid)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CntGroups indicates an expected call of CntGroups.
+func (mr *MockFollowRepositoryMockRecorder) CntGroups(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CntGroups", reflect.TypeOf((*MockFollowRepository)(nil).CntGroups), ctx, uid)
+}
+
+// CreateGroup mocks base method.
+func (m *MockFollowRepository) CreateGroup(ctx context.Context, g domain.FollowGroup) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGroup", ctx, g)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGroup indicates an expected call of CreateGroup.
+func (mr *MockFollowRepositoryMockRecorder) CreateGroup(ctx, g any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroup", reflect.TypeOf((*MockFollowRepository)(nil).CreateGroup), ctx, g)
+}
+
+// DeleteGroup mocks base method.
+func (m *MockFollowRepository) DeleteGroup(ctx context.Context, uid, gid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGroup", ctx, uid, gid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGroup indicates an expected call of DeleteGroup.
+func (mr *MockFollowRepositoryMockRecorder) DeleteGroup(ctx, uid, gid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGroup", reflect.TypeOf((*MockFollowRepository)(nil).DeleteGroup), ctx, uid, gid)
+}
+
+// FindGroup mocks base method.
+func (m *MockFollowRepository) FindGroup(ctx context.Context, uid, gid int64) (domain.FollowGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindGroup", ctx, uid, gid)
+	ret0, _ := ret[0].(domain.FollowGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindGroup indicates an expected call of FindGroup.
+func (mr *MockFollowRepositoryMockRecorder) FindGroup(ctx, uid, gid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindGroup", reflect.TypeOf((*MockFollowRepository)(nil).FindGroup), ctx, uid, gid)
+}
+
+// FollowInfo mocks base method.
+func (m *MockFollowRepository) FollowInfo(ctx context.Context, follower, followee int64) (domain.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FollowInfo", ctx, follower, followee)
+	ret0, _ := ret[0].(domain.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FollowInfo indicates an expected call of FollowInfo.
+func (mr *MockFollowRepositoryMockRecorder) FollowInfo(ctx, follower, followee any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FollowInfo", reflect.TypeOf((*MockFollowRepository)(nil).FollowInfo), ctx, follower, followee)
+}
+
+// FollowStatus mocks base method.
+func (m *MockFollowRepository) FollowStatus(ctx context.Context, uid int64, targets []int64) ([]domain.FollowStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FollowStatus", ctx, uid, targets)
+	ret0, _ := ret[0].([]domain.FollowStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FollowStatus indicates an expected call of FollowStatus.
+func (mr *MockFollowRepositoryMockRecorder) FollowStatus(ctx, uid, targets any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FollowStatus", reflect.TypeOf((*MockFollowRepository)(nil).FollowStatus), ctx, uid, targets)
+}
+
+// GetFollowStatics mocks base method.
+func (m *MockFollowRepository) GetFollowStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFollowStatics", ctx, uid)
+	ret0, _ := ret[0].(domain.FollowStatics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFollowStatics indicates an expected call of GetFollowStatics.
+func (mr *MockFollowRepositoryMockRecorder) GetFollowStatics(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollowStatics", reflect.TypeOf((*MockFollowRepository)(nil).GetFollowStatics), ctx, uid)
+}
+
+// GetFollowee mocks base method.
+func (m *MockFollowRepository) GetFollowee(ctx context.Context, follower, offset, limit int64) ([]domain.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFollowee", ctx, follower, offset, limit)
+	ret0, _ := ret[0].([]domain.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFollowee indicates an expected call of GetFollowee.
+func (mr *MockFollowRepositoryMockRecorder) GetFollowee(ctx, follower, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollowee", reflect.TypeOf((*MockFollowRepository)(nil).GetFollowee), ctx, follower, offset, limit)
+}
+
+// GetFolloweeByCursor mocks base method.
+func (m *MockFollowRepository) GetFolloweeByCursor(ctx context.Context, follower int64, cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFolloweeByCursor", ctx, follower, cursor, limit)
+	ret0, _ := ret[0].([]domain.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFolloweeByCursor indicates an expected call of GetFolloweeByCursor.
+func (mr *MockFollowRepositoryMockRecorder) GetFolloweeByCursor(ctx, follower, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFolloweeByCursor", reflect.TypeOf((*MockFollowRepository)(nil).GetFolloweeByCursor), ctx, follower, cursor, limit)
+}
+
+// GetFolloweeByGroup mocks base method.
+func (m *MockFollowRepository) GetFolloweeByGroup(ctx context.Context, follower, gid int64, cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFolloweeByGroup", ctx, follower, gid, cursor, limit)
+	ret0, _ := ret[0].([]domain.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFolloweeByGroup indicates an expected call of GetFolloweeByGroup.
+func (mr *MockFollowRepositoryMockRecorder) GetFolloweeByGroup(ctx, follower, gid, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFolloweeByGroup", reflect.TypeOf((*MockFollowRepository)(nil).GetFolloweeByGroup), ctx, follower, gid, cursor, limit)
+}
+
+// GetFollowerByCursor mocks base method.
+func (m *MockFollowRepository) GetFollowerByCursor(ctx context.Context, followee int64, cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFollowerByCursor", ctx, followee, cursor, limit)
+	ret0, _ := ret[0].([]domain.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFollowerByCursor indicates an expected call of GetFollowerByCursor.
+func (mr *MockFollowRepositoryMockRecorder) GetFollowerByCursor(ctx, followee, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollowerByCursor", reflect.TypeOf((*MockFollowRepository)(nil).GetFollowerByCursor), ctx, followee, cursor, limit)
+}
+
+// GetMutualByCursor mocks base method.
+func (m *MockFollowRepository) GetMutualByCursor(ctx context.Context, uid int64, cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMutualByCursor", ctx, uid, cursor, limit)
+	ret0, _ := ret[0].([]domain.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMutualByCursor indicates an expected call of GetMutualByCursor.
+func (mr *MockFollowRepositoryMockRecorder) GetMutualByCursor(ctx, uid, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMutualByCursor", reflect.TypeOf((*MockFollowRepository)(nil).GetMutualByCursor), ctx, uid, cursor, limit)
+}
+
+// GetSpecialFollowerByCursor mocks base method.
+func (m *MockFollowRepository) GetSpecialFollowerByCursor(ctx context.Context, followee int64, cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSpecialFollowerByCursor", ctx, followee, cursor, limit)
+	ret0, _ := ret[0].([]domain.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSpecialFollowerByCursor indicates an expected call of GetSpecialFollowerByCursor.
+func (mr *MockFollowRepositoryMockRecorder) GetSpecialFollowerByCursor(ctx, followee, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSpecialFollowerByCursor", reflect.TypeOf((*MockFollowRepository)(nil).GetSpecialFollowerByCursor), ctx, followee, cursor, limit)
+}
+
+// InactiveFollowRelation mocks base method.
+func (m *MockFollowRepository) InactiveFollowRelation(ctx context.Context, follower, followee int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InactiveFollowRelation", ctx, follower, followee)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InactiveFollowRelation indicates an expected call of InactiveFollowRelation.
+func (mr *MockFollowRepositoryMockRecorder) InactiveFollowRelation(ctx, follower, followee any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InactiveFollowRelation", reflect.TypeOf((*MockFollowRepository)(nil).InactiveFollowRelation), ctx, follower, followee)
+}
+
+// ListGroups mocks base method.
+func (m *MockFollowRepository) ListGroups(ctx context.Context, uid int64) ([]domain.FollowGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGroups", ctx, uid)
+	ret0, _ := ret[0].([]domain.FollowGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListGroups indicates an expected call of ListGroups.
+func (mr *MockFollowRepositoryMockRecorder) ListGroups(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGroups", reflect.TypeOf((*MockFollowRepository)(nil).ListGroups), ctx, uid)
+}
+
+// RenameGroup mocks base method.
+func (m *MockFollowRepository) RenameGroup(ctx context.Context, uid, gid int64, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameGroup", ctx, uid, gid, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameGroup indicates an expected call of RenameGroup.
+func (mr *MockFollowRepositoryMockRecorder) RenameGroup(ctx, uid, gid, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameGroup", reflect.TypeOf((*MockFollowRepository)(nil).RenameGroup), ctx, uid, gid, name)
+}
+
+// UpdateFollowPrefs mocks base method.
+func (m *MockFollowRepository) UpdateFollowPrefs(ctx context.Context, f domain.FollowRelation, fields domain.FollowPrefsField) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFollowPrefs", ctx, f, fields)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFollowPrefs indicates an expected call of UpdateFollowPrefs.
+func (mr *MockFollowRepositoryMockRecorder) UpdateFollowPrefs(ctx, f, fields any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFollowPrefs", reflect.TypeOf((*MockFollowRepository)(nil).UpdateFollowPrefs), ctx, f, fields)
+}
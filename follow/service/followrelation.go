@@ -2,41 +2,338 @@ package service
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
 
 	"webooktrial/follow/domain"
+	"webooktrial/follow/events"
 	"webooktrial/follow/repository"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/pagination"
+)
+
+var (
+	ErrFollowRelationNotFound = repository.ErrFollowRelationNotFound
+	ErrFollowGroupNotFound    = repository.ErrFollowGroupNotFound
+	ErrDuplicateFollowGroup   = repository.ErrDuplicateFollowGroup
+	ErrTooManyFollowGroups    = errors.New("分组太多了")
+	ErrInvalidFollowGroupName = errors.New("分组名字不合法")
+	ErrFollowNoteTooLong      = errors.New("备注太长了")
+	// ErrBlocked 任何一方拉黑了对方，都不能关注
+	ErrBlocked   = errors.New("已经被拉黑")
+	ErrBlockSelf = errors.New("不能拉黑自己")
+)
+
+const (
+	// maxFollowGroups 每个人最多可以建这么多个分组
+	maxFollowGroups = 20
+	// 下面两个按照字符数，和表结构的长度一致
+	maxFollowGroupNameLen = 64
+	maxFollowNoteLen      = 255
 )
 
 type FollowRelationService interface {
+	// Deprecated: 用 GetFolloweeByCursor
 	GetFollowee(ctx context.Context, follower, offset, limit int64) ([]domain.FollowRelation, error)
+	// GetFolloweeByCursor 关注列表，会填充 Mutual
+	GetFolloweeByCursor(ctx context.Context, follower int64, cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error)
+	// GetFolloweeByGroup 某个分组里面的关注列表，gid 为 0 表示没有分组的，会填充 Mutual
+	GetFolloweeByGroup(ctx context.Context, follower, gid int64, cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error)
+	// GetFollowerByCursor 粉丝列表，会填充 Mutual。分组、特别关注和备注是粉丝自己的，不会返回
+	GetFollowerByCursor(ctx context.Context, followee int64, cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error)
+	// GetSpecialFollowerByCursor 特别关注了 followee 的粉丝，给其它服务推送用，只有 Follower 和 Followee
+	GetSpecialFollowerByCursor(ctx context.Context, followee int64, cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error)
+	// GetMutualByCursor 互相关注的列表
+	GetMutualByCursor(ctx context.Context, uid int64, cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error)
+	// BatchFollowStatus uid 和 targets 里面每个人的关注关系，顺序和 targets 一样
+	BatchFollowStatus(ctx context.Context, uid int64, targets []int64) ([]domain.FollowStatus, error)
+	GetFollowStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
 	FollowInfo(ctx context.Context, follower, followee int64) (domain.FollowRelation, error)
-	Follow(ctx context.Context, follower, followee int64) error
+	// Follow 可以同时指定分组、特别关注和备注，已经关注了的话会覆盖
+	Follow(ctx context.Context, f domain.FollowRelation) error
+	// UpdateFollow 修改分组、特别关注和备注，只修改 fields 里面的字段
+	UpdateFollow(ctx context.Context, f domain.FollowRelation, fields domain.FollowPrefsField) error
 	CancelFollow(ctx context.Context, follower, followee int64) error
+
+	CreateGroup(ctx context.Context, uid int64, name string) (domain.FollowGroup, error)
+	RenameGroup(ctx context.Context, uid, gid int64, name string) error
+	// DeleteGroup 分组里面的人不会取消关注，只是变成没有分组
+	DeleteGroup(ctx context.Context, uid, gid int64) error
+	ListGroups(ctx context.Context, uid int64) ([]domain.FollowGroup, error)
+
+	// Block 拉黑，两个方向的关注都会取消，之后也不能再关注
+	Block(ctx context.Context, blocker, blocked int64) error
+	Unblock(ctx context.Context, blocker, blocked int64) error
+	// ListBlocked 黑名单，按照拉黑的时间倒序
+	ListBlocked(ctx context.Context, blocker int64, cursor pagination.Cursor, limit int64) ([]domain.Block, error)
+	// IsBlocked blocker 有没有拉黑 blocked
+	IsBlocked(ctx context.Context, blocker, blocked int64) (bool, error)
 }
 
 type followRelationService struct {
-	repo repository.FollowRepository
+	repo      repository.FollowRepository
+	blockRepo repository.BlockRepository
+	producer  events.Producer
+	l         logger.LoggerV1
 }
 
-func NewFollowRelationService(repo repository.FollowRepository) FollowRelationService {
-	return &followRelationService{repo: repo}
+func NewFollowRelationService(repo repository.FollowRepository,
+	blockRepo repository.BlockRepository,
+	producer events.Producer, l logger.LoggerV1) FollowRelationService {
+	return &followRelationService{repo: repo, blockRepo: blockRepo, producer: producer, l: l}
 }
 
 func (f *followRelationService) GetFollowee(ctx context.Context, follower, offset, limit int64) ([]domain.FollowRelation, error) {
 	return f.repo.GetFollowee(ctx, follower, offset, limit)
 }
 
+func (f *followRelationService) GetFolloweeByCursor(ctx context.Context, follower int64,
+	cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error) {
+	list, err := f.repo.GetFolloweeByCursor(ctx, follower, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	// 我关注的人里面，哪些也关注了我
+	err = f.fillMutual(ctx, follower, list, func(r domain.FollowRelation) int64 {
+		return r.Followee
+	}, func(s domain.FollowStatus) bool {
+		return s.FollowedBy
+	})
+	return list, err
+}
+
+func (f *followRelationService) GetFolloweeByGroup(ctx context.Context, follower, gid int64,
+	cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error) {
+	list, err := f.repo.GetFolloweeByGroup(ctx, follower, gid, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	err = f.fillMutual(ctx, follower, list, func(r domain.FollowRelation) int64 {
+		return r.Followee
+	}, func(s domain.FollowStatus) bool {
+		return s.FollowedBy
+	})
+	return list, err
+}
+
+func (f *followRelationService) GetFollowerByCursor(ctx context.Context, followee int64,
+	cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error) {
+	list, err := f.repo.GetFollowerByCursor(ctx, followee, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].Gid = 0
+		list[i].Special = false
+		list[i].Note = ""
+	}
+	// 我的粉丝里面，哪些我也关注了
+	err = f.fillMutual(ctx, followee, list, func(r domain.FollowRelation) int64 {
+		return r.Follower
+	}, func(s domain.FollowStatus) bool {
+		return s.Following
+	})
+	return list, err
+}
+
+func (f *followRelationService) GetSpecialFollowerByCursor(ctx context.Context, followee int64,
+	cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error) {
+	list, err := f.repo.GetSpecialFollowerByCursor(ctx, followee, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].Gid = 0
+		list[i].Note = ""
+	}
+	return list, nil
+}
+
+// fillMutual 一页只查一次，不要一条一条查
+func (f *followRelationService) fillMutual(ctx context.Context, uid int64, list []domain.FollowRelation,
+	other func(r domain.FollowRelation) int64, mutual func(s domain.FollowStatus) bool) error {
+	if len(list) == 0 {
+		return nil
+	}
+	targets := make([]int64, 0, len(list))
+	for _, r := range list {
+		targets = append(targets, other(r))
+	}
+	statuses, err := f.repo.FollowStatus(ctx, uid, targets)
+	if err != nil {
+		return err
+	}
+	for i := range list {
+		list[i].Mutual = mutual(statuses[i])
+	}
+	return nil
+}
+
+func (f *followRelationService) GetMutualByCursor(ctx context.Context, uid int64,
+	cursor pagination.Cursor, limit int64) ([]domain.FollowRelation, error) {
+	return f.repo.GetMutualByCursor(ctx, uid, cursor, limit)
+}
+
+func (f *followRelationService) BatchFollowStatus(ctx context.Context, uid int64, targets []int64) ([]domain.FollowStatus, error) {
+	if len(targets) == 0 {
+		return []domain.FollowStatus{}, nil
+	}
+	return f.repo.FollowStatus(ctx, uid, targets)
+}
+
+func (f *followRelationService) GetFollowStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	return f.repo.GetFollowStatics(ctx, uid)
+}
+
 func (f *followRelationService) FollowInfo(ctx context.Context, follower, followee int64) (domain.FollowRelation, error) {
-	return f.FollowInfo(ctx, follower, followee)
+	return f.repo.FollowInfo(ctx, follower, followee)
 }
 
-func (f *followRelationService) Follow(ctx context.Context, follower, followee int64) error {
-	return f.repo.AddFollowRelation(ctx, domain.FollowRelation{
-		Followee: followee,
-		Follower: follower,
+func (f *followRelationService) Follow(ctx context.Context, r domain.FollowRelation) error {
+	err := f.checkPrefs(ctx, r, domain.FollowPrefsAll)
+	if err != nil {
+		return err
+	}
+	blocked, err := f.blockRepo.IsBlockedEither(ctx, r.Follower, r.Followee)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrBlocked
+	}
+	err = f.repo.AddFollowRelation(ctx, r)
+	if err != nil {
+		return err
+	}
+	// 关注已经成功了，通知丢了问题不大
+	er := f.producer.ProduceFollowCreatedEvent(ctx, events.FollowCreatedEvent{
+		Follower: r.Follower,
+		Followee: r.Followee,
 	})
+	if er != nil {
+		f.l.Error("发送关注事件失败",
+			logger.Int64("follower", r.Follower),
+			logger.Int64("followee", r.Followee),
+			logger.Error(er))
+	}
+	return nil
+}
+
+func (f *followRelationService) UpdateFollow(ctx context.Context, r domain.FollowRelation,
+	fields domain.FollowPrefsField) error {
+	err := f.checkPrefs(ctx, r, fields)
+	if err != nil {
+		return err
+	}
+	return f.repo.UpdateFollowPrefs(ctx, r, fields)
+}
+
+// checkPrefs 只检查要修改的字段，分组必须是 follower 自己的
+func (f *followRelationService) checkPrefs(ctx context.Context, r domain.FollowRelation,
+	fields domain.FollowPrefsField) error {
+	if fields.Has(domain.FollowPrefsNote) && utf8.RuneCountInString(r.Note) > maxFollowNoteLen {
+		return ErrFollowNoteTooLong
+	}
+	if !fields.Has(domain.FollowPrefsGid) || r.Gid == 0 {
+		return nil
+	}
+	_, err := f.repo.FindGroup(ctx, r.Follower, r.Gid)
+	return err
 }
 
 func (f *followRelationService) CancelFollow(ctx context.Context, follower, followee int64) error {
-	return f.repo.InactiveFollowRelation(ctx, follower, followee)
+	err := f.repo.InactiveFollowRelation(ctx, follower, followee)
+	if err != nil {
+		return err
+	}
+	f.produceCanceled(ctx, follower, followee)
+	return nil
+}
+
+// produceCanceled 关注关系已经改了，发消息失败只记录日志，
+// 下游的数据最多是多留了一些
+func (f *followRelationService) produceCanceled(ctx context.Context, follower, followee int64) {
+	err := f.producer.ProduceFollowCanceledEvent(ctx, events.FollowCanceledEvent{
+		Follower: follower,
+		Followee: followee,
+	})
+	if err != nil {
+		f.l.Error("发送取消关注事件失败",
+			logger.Int64("follower", follower),
+			logger.Int64("followee", followee),
+			logger.Error(err))
+	}
+}
+
+func (f *followRelationService) CreateGroup(ctx context.Context, uid int64, name string) (domain.FollowGroup, error) {
+	name, err := f.checkGroupName(name)
+	if err != nil {
+		return domain.FollowGroup{}, err
+	}
+	// 并发创建的时候可能会稍微超出一点，问题不大
+	cnt, err := f.repo.CntGroups(ctx, uid)
+	if err != nil {
+		return domain.FollowGroup{}, err
+	}
+	if cnt >= maxFollowGroups {
+		return domain.FollowGroup{}, ErrTooManyFollowGroups
+	}
+	g := domain.FollowGroup{Uid: uid, Name: name}
+	g.Id, err = f.repo.CreateGroup(ctx, g)
+	if err != nil {
+		return domain.FollowGroup{}, err
+	}
+	return g, nil
+}
+
+func (f *followRelationService) RenameGroup(ctx context.Context, uid, gid int64, name string) error {
+	name, err := f.checkGroupName(name)
+	if err != nil {
+		return err
+	}
+	return f.repo.RenameGroup(ctx, uid, gid, name)
+}
+
+func (f *followRelationService) DeleteGroup(ctx context.Context, uid, gid int64) error {
+	return f.repo.DeleteGroup(ctx, uid, gid)
+}
+
+func (f *followRelationService) ListGroups(ctx context.Context, uid int64) ([]domain.FollowGroup, error) {
+	return f.repo.ListGroups(ctx, uid)
+}
+
+func (f *followRelationService) checkGroupName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxFollowGroupNameLen {
+		return "", ErrInvalidFollowGroupName
+	}
+	return name, nil
+}
+
+func (f *followRelationService) Block(ctx context.Context, blocker, blocked int64) error {
+	if blocker == blocked {
+		return ErrBlockSelf
+	}
+	err := f.blockRepo.Block(ctx, blocker, blocked)
+	if err != nil {
+		return err
+	}
+	// 不知道原本有没有关注，两个方向都发，下游要保证幂等
+	f.produceCanceled(ctx, blocker, blocked)
+	f.produceCanceled(ctx, blocked, blocker)
+	return nil
+}
+
+func (f *followRelationService) Unblock(ctx context.Context, blocker, blocked int64) error {
+	return f.blockRepo.Unblock(ctx, blocker, blocked)
+}
+
+func (f *followRelationService) ListBlocked(ctx context.Context, blocker int64,
+	cursor pagination.Cursor, limit int64) ([]domain.Block, error) {
+	return f.blockRepo.ListBlocked(ctx, blocker, cursor, limit)
+}
+
+func (f *followRelationService) IsBlocked(ctx context.Context, blocker, blocked int64) (bool, error) {
+	return f.blockRepo.IsBlocked(ctx, blocker, blocked)
 }
@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"webooktrial/follow/domain"
+	"webooktrial/follow/events"
+	evtmocks "webooktrial/follow/events/mocks"
+	"webooktrial/follow/repository"
+	repomocks "webooktrial/follow/repository/mocks"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/pagination"
+)
+
+func TestFollowRelationService_FollowInfo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockFollowRepository(ctrl)
+	repo.EXPECT().FollowInfo(gomock.Any(), int64(1), int64(2)).
+		Return(domain.FollowRelation{Id: 3, Follower: 1, Followee: 2}, nil)
+	svc := NewFollowRelationService(repo, nil, nil, logger.NewNopLogger())
+	info, err := svc.FollowInfo(context.Background(), 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.FollowRelation{Id: 3, Follower: 1, Followee: 2}, info)
+}
+
+func TestFollowRelationService_GetFollowerByCursor(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) repository.FollowRepository
+
+		wantRes []domain.FollowRelation
+		wantErr error
+	}{
+		{
+			name: "填充互相关注",
+			mock: func(ctrl *gomock.Controller) repository.FollowRepository {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().GetFollowerByCursor(gomock.Any(), int64(1), pagination.Cursor{}, int64(10)).
+					Return([]domain.FollowRelation{
+						{Id: 11, Follower: 2, Followee: 1},
+						{Id: 12, Follower: 3, Followee: 1},
+					}, nil)
+				repo.EXPECT().FollowStatus(gomock.Any(), int64(1), []int64{2, 3}).
+					Return([]domain.FollowStatus{
+						{Uid: 2, Following: true, FollowedBy: true},
+						{Uid: 3, FollowedBy: true},
+					}, nil)
+				return repo
+			},
+			wantRes: []domain.FollowRelation{
+				{Id: 11, Follower: 2, Followee: 1, Mutual: true},
+				{Id: 12, Follower: 3, Followee: 1},
+			},
+		},
+		{
+			name: "没有粉丝，不查关注关系",
+			mock: func(ctrl *gomock.Controller) repository.FollowRepository {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().GetFollowerByCursor(gomock.Any(), int64(1), pagination.Cursor{}, int64(10)).
+					Return([]domain.FollowRelation{}, nil)
+				return repo
+			},
+			wantRes: []domain.FollowRelation{},
+		},
+		{
+			name: "查询关注关系失败",
+			mock: func(ctrl *gomock.Controller) repository.FollowRepository {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().GetFollowerByCursor(gomock.Any(), int64(1), pagination.Cursor{}, int64(10)).
+					Return([]domain.FollowRelation{{Id: 11, Follower: 2, Followee: 1}}, nil)
+				repo.EXPECT().FollowStatus(gomock.Any(), int64(1), []int64{2}).
+					Return(nil, errors.New("mock db error"))
+				return repo
+			},
+			wantRes: []domain.FollowRelation{{Id: 11, Follower: 2, Followee: 1}},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewFollowRelationService(tc.mock(ctrl), nil, nil, logger.NewNopLogger())
+			res, err := svc.GetFollowerByCursor(context.Background(), 1, pagination.Cursor{}, 10)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
+
+func TestFollowRelationService_Follow(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.FollowRepository, repository.BlockRepository, events.Producer)
+		r    domain.FollowRelation
+
+		wantErr error
+	}{
+		{
+			name: "关注到自己的分组",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository, repository.BlockRepository, events.Producer) {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().FindGroup(gomock.Any(), int64(1), int64(5)).
+					Return(domain.FollowGroup{Id: 5, Uid: 1, Name: "同事"}, nil)
+				repo.EXPECT().AddFollowRelation(gomock.Any(), domain.FollowRelation{
+					Follower: 1, Followee: 2, Gid: 5, Special: true, Note: "老王",
+				}).Return(nil)
+				blockRepo := repomocks.NewMockBlockRepository(ctrl)
+				blockRepo.EXPECT().IsBlockedEither(gomock.Any(), int64(1), int64(2)).Return(false, nil)
+				producer := evtmocks.NewMockProducer(ctrl)
+				producer.EXPECT().ProduceFollowCreatedEvent(gomock.Any(),
+					events.FollowCreatedEvent{Follower: 1, Followee: 2}).Return(nil)
+				return repo, blockRepo, producer
+			},
+			r: domain.FollowRelation{Follower: 1, Followee: 2, Gid: 5, Special: true, Note: "老王"},
+		},
+		{
+			name: "不是自己的分组",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository, repository.BlockRepository, events.Producer) {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().FindGroup(gomock.Any(), int64(1), int64(6)).
+					Return(domain.FollowGroup{}, ErrFollowGroupNotFound)
+				return repo, nil, nil
+			},
+			r:       domain.FollowRelation{Follower: 1, Followee: 2, Gid: 6},
+			wantErr: ErrFollowGroupNotFound,
+		},
+		{
+			name: "备注太长",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository, repository.BlockRepository, events.Producer) {
+				return repomocks.NewMockFollowRepository(ctrl), nil, nil
+			},
+			r:       domain.FollowRelation{Follower: 1, Followee: 2, Note: strings.Repeat("长", 256)},
+			wantErr: ErrFollowNoteTooLong,
+		},
+		{
+			name: "被拉黑了",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository, repository.BlockRepository, events.Producer) {
+				blockRepo := repomocks.NewMockBlockRepository(ctrl)
+				blockRepo.EXPECT().IsBlockedEither(gomock.Any(), int64(1), int64(2)).Return(true, nil)
+				return repomocks.NewMockFollowRepository(ctrl), blockRepo, nil
+			},
+			r:       domain.FollowRelation{Follower: 1, Followee: 2},
+			wantErr: ErrBlocked,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, blockRepo, producer := tc.mock(ctrl)
+			svc := NewFollowRelationService(repo, blockRepo, producer, logger.NewNopLogger())
+			err := svc.Follow(context.Background(), tc.r)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestFollowRelationService_CreateGroup(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.FollowRepository
+		nameArg string
+		wantRes domain.FollowGroup
+		wantErr error
+	}{
+		{
+			name: "创建成功",
+			mock: func(ctrl *gomock.Controller) repository.FollowRepository {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().CntGroups(gomock.Any(), int64(1)).Return(int64(3), nil)
+				repo.EXPECT().CreateGroup(gomock.Any(), domain.FollowGroup{Uid: 1, Name: "同事"}).
+					Return(int64(9), nil)
+				return repo
+			},
+			nameArg: " 同事 ",
+			wantRes: domain.FollowGroup{Id: 9, Uid: 1, Name: "同事"},
+		},
+		{
+			name: "分组太多",
+			mock: func(ctrl *gomock.Controller) repository.FollowRepository {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().CntGroups(gomock.Any(), int64(1)).Return(int64(maxFollowGroups), nil)
+				return repo
+			},
+			nameArg: "同事",
+			wantErr: ErrTooManyFollowGroups,
+		},
+		{
+			name: "名字为空",
+			mock: func(ctrl *gomock.Controller) repository.FollowRepository {
+				return repomocks.NewMockFollowRepository(ctrl)
+			},
+			nameArg: "  ",
+			wantErr: ErrInvalidFollowGroupName,
+		},
+		{
+			name: "创建失败，不返回分组",
+			mock: func(ctrl *gomock.Controller) repository.FollowRepository {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().CntGroups(gomock.Any(), int64(1)).Return(int64(3), nil)
+				repo.EXPECT().CreateGroup(gomock.Any(), domain.FollowGroup{Uid: 1, Name: "同事"}).
+					Return(int64(0), ErrDuplicateFollowGroup)
+				return repo
+			},
+			nameArg: "同事",
+			wantErr: ErrDuplicateFollowGroup,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewFollowRelationService(tc.mock(ctrl), nil, nil, logger.NewNopLogger())
+			g, err := svc.CreateGroup(context.Background(), 1, tc.nameArg)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRes, g)
+		})
+	}
+}
+
+func TestFollowRelationService_Block(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.BlockRepository, events.Producer)
+		blocker int64
+		blocked int64
+
+		wantErr error
+	}{
+		{
+			name: "两个方向都发取消关注事件",
+			mock: func(ctrl *gomock.Controller) (repository.BlockRepository, events.Producer) {
+				blockRepo := repomocks.NewMockBlockRepository(ctrl)
+				blockRepo.EXPECT().Block(gomock.Any(), int64(1), int64(2)).Return(nil)
+				producer := evtmocks.NewMockProducer(ctrl)
+				producer.EXPECT().ProduceFollowCanceledEvent(gomock.Any(),
+					events.FollowCanceledEvent{Follower: 1, Followee: 2}).Return(nil)
+				producer.EXPECT().ProduceFollowCanceledEvent(gomock.Any(),
+					events.FollowCanceledEvent{Follower: 2, Followee: 1}).Return(nil)
+				return blockRepo, producer
+			},
+			blocker: 1,
+			blocked: 2,
+		},
+		{
+			name: "发事件失败不影响拉黑",
+			mock: func(ctrl *gomock.Controller) (repository.BlockRepository, events.Producer) {
+				blockRepo := repomocks.NewMockBlockRepository(ctrl)
+				blockRepo.EXPECT().Block(gomock.Any(), int64(1), int64(2)).Return(nil)
+				producer := evtmocks.NewMockProducer(ctrl)
+				producer.EXPECT().ProduceFollowCanceledEvent(gomock.Any(), gomock.Any()).
+					Times(2).Return(errors.New("kafka 挂了"))
+				return blockRepo, producer
+			},
+			blocker: 1,
+			blocked: 2,
+		},
+		{
+			name: "拉黑失败不发事件",
+			mock: func(ctrl *gomock.Controller) (repository.BlockRepository, events.Producer) {
+				blockRepo := repomocks.NewMockBlockRepository(ctrl)
+				blockRepo.EXPECT().Block(gomock.Any(), int64(1), int64(2)).Return(errors.New("db 错误"))
+				return blockRepo, evtmocks.NewMockProducer(ctrl)
+			},
+			blocker: 1,
+			blocked: 2,
+			wantErr: errors.New("db 错误"),
+		},
+		{
+			name: "拉黑自己",
+			mock: func(ctrl *gomock.Controller) (repository.BlockRepository, events.Producer) {
+				return repomocks.NewMockBlockRepository(ctrl), evtmocks.NewMockProducer(ctrl)
+			},
+			blocker: 1,
+			blocked: 1,
+			wantErr: ErrBlockSelf,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			blockRepo, producer := tc.mock(ctrl)
+			svc := NewFollowRelationService(repomocks.NewMockFollowRepository(ctrl),
+				blockRepo, producer, logger.NewNopLogger())
+			err := svc.Block(context.Background(), tc.blocker, tc.blocked)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestFollowRelationService_UpdateFollow(t *testing.T) {
+	testCases := []struct {
+		name   string
+		mock   func(ctrl *gomock.Controller) repository.FollowRepository
+		r      domain.FollowRelation
+		fields domain.FollowPrefsField
+
+		wantErr error
+	}{
+		{
+			name: "只改备注，不检查分组",
+			mock: func(ctrl *gomock.Controller) repository.FollowRepository {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().UpdateFollowPrefs(gomock.Any(),
+					domain.FollowRelation{Follower: 1, Followee: 2, Gid: 9, Note: "同学"},
+					domain.FollowPrefsNote).Return(nil)
+				return repo
+			},
+			r:      domain.FollowRelation{Follower: 1, Followee: 2, Gid: 9, Note: "同学"},
+			fields: domain.FollowPrefsNote,
+		},
+		{
+			name: "改分组，分组不存在",
+			mock: func(ctrl *gomock.Controller) repository.FollowRepository {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().FindGroup(gomock.Any(), int64(1), int64(9)).
+					Return(domain.FollowGroup{}, ErrFollowGroupNotFound)
+				return repo
+			},
+			r:       domain.FollowRelation{Follower: 1, Followee: 2, Gid: 9},
+			fields:  domain.FollowPrefsGid,
+			wantErr: ErrFollowGroupNotFound,
+		},
+		{
+			name: "不改备注的时候不检查长度",
+			mock: func(ctrl *gomock.Controller) repository.FollowRepository {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().UpdateFollowPrefs(gomock.Any(), gomock.Any(), domain.FollowPrefsSpecial).Return(nil)
+				return repo
+			},
+			r:      domain.FollowRelation{Follower: 1, Followee: 2, Special: true, Note: strings.Repeat("备", maxFollowNoteLen+1)},
+			fields: domain.FollowPrefsSpecial,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewFollowRelationService(tc.mock(ctrl), nil, nil, logger.NewNopLogger())
+			err := svc.UpdateFollow(context.Background(), tc.r, tc.fields)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"github.com/google/wire"
 
+	"webooktrial/follow/events"
 	grpc2 "webooktrial/follow/grpc"
 	"webooktrial/follow/ioc"
 	"webooktrial/follow/repository"
@@ -13,8 +14,11 @@ import (
 
 var serviceProviderSet = wire.NewSet(
 	dao.NewGORMFollowRelationDAO,
+	dao.NewGORMBlockDAO,
 	repository.NewCachedRelationRepository,
+	repository.NewBlockRepository,
 	service.NewFollowRelationService,
+	events.NewKafkaProducer,
 	cache.NewRedisFollowCache,
 	grpc2.NewFollowRelationServiceServer,
 )
@@ -23,6 +27,9 @@ var thirdProvider = wire.NewSet(
 	ioc.InitRedis,
 	ioc.InitDB,
 	ioc.InitLogger,
+	ioc.InitCursorCodec,
+	ioc.InitKafka,
+	ioc.InitSyncProducer,
 )
 
 func Init() *App {
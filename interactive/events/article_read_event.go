@@ -4,3 +4,24 @@ type ReadEvent struct {
 	Uid int64
 	Aid int64
 }
+
+// ReadEventV1 批量的阅读事件，下标相同的是同一次阅读
+type ReadEventV1 struct {
+	Uids []int64
+	Aids []int64
+}
+
+// ReadEventMsg read_article 里面既有单个的 ReadEvent，也有批量的 ReadEventV1，
+// 两者的字段不重叠，所以直接组合起来反序列化
+type ReadEventMsg struct {
+	ReadEvent
+	ReadEventV1
+}
+
+// ArticleIds 阅读计数只关心文章 ID
+func (m ReadEventMsg) ArticleIds() []int64 {
+	if len(m.Aids) > 0 {
+		return m.Aids
+	}
+	return []int64{m.Aid}
+}
@@ -30,7 +30,7 @@ func (r *InteractiveReadEventBatchConsumer) Start() error {
 	go func() {
 		er := cg.Consume(context.Background(),
 			[]string{"read_article"},
-			saramax.NewBatchHandler[ReadEvent](r.l, r.Consume))
+			saramax.NewBatchHandler[ReadEventMsg](r.l, r.Consume))
 		if er != nil {
 			r.l.Error("退出了消费循环异常", logger.Error(err))
 		}
@@ -39,12 +39,14 @@ func (r *InteractiveReadEventBatchConsumer) Start() error {
 }
 
 // Consume 这个不是幂等的
-func (r *InteractiveReadEventBatchConsumer) Consume(msgs []*sarama.ConsumerMessage, ts []ReadEvent) error {
+func (r *InteractiveReadEventBatchConsumer) Consume(msgs []*sarama.ConsumerMessage, ts []ReadEventMsg) error {
 	aids := make([]int64, 0, len(ts))
 	bizs := make([]string, 0, len(ts))
-	for _, evt := range ts {
-		aids = append(aids, evt.Aid)
-		bizs = append(bizs, "article")
+	for _, t := range ts {
+		for _, aid := range t.ArticleIds() {
+			aids = append(aids, aid)
+			bizs = append(bizs, "article")
+		}
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
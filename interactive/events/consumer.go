@@ -38,7 +38,7 @@ func (r *InteractiveReadEventConsumer) Start() error {
 	go func() {
 		er := cg.Consume(context.Background(),
 			[]string{"read_article"},
-			saramax.NewHandler[ReadEvent](r.l, r.Consume))
+			saramax.NewHandler[ReadEventMsg](r.l, r.Consume))
 		if er != nil {
 			r.l.Error("退出了消费循环异常", logger.Error(err))
 		}
@@ -47,8 +47,16 @@ func (r *InteractiveReadEventConsumer) Start() error {
 }
 
 // Consume 这个不是幂等的
-func (r *InteractiveReadEventConsumer) Consume(msg *sarama.ConsumerMessage, t ReadEvent) error {
+func (r *InteractiveReadEventConsumer) Consume(msg *sarama.ConsumerMessage, t ReadEventMsg) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
-	return r.repo.IncrReadCnt(ctx, "article", t.Aid)
+	aids := t.ArticleIds()
+	if len(aids) == 1 {
+		return r.repo.IncrReadCnt(ctx, "article", aids[0])
+	}
+	bizs := make([]string, len(aids))
+	for i := range bizs {
+		bizs[i] = "article"
+	}
+	return r.repo.BatchIncrReadCnt(ctx, bizs, aids)
 }
@@ -0,0 +1,47 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/IBM/sarama"
+)
+
+const TopicLiked = "interactive_liked"
+
+// LikedEvent 点赞了。BizOwner 是被点赞的资源的作者，
+// 还没有同步过来的时候为 0
+type LikedEvent struct {
+	Biz      string
+	BizId    int64
+	BizOwner int64
+	Uid      int64
+}
+
+type Producer interface {
+	ProduceLikedEvent(ctx context.Context, evt LikedEvent) error
+}
+
+type KafkaProducer struct {
+	producer sarama.SyncProducer
+}
+
+func NewKafkaProducer(pc sarama.SyncProducer) Producer {
+	return &KafkaProducer{
+		producer: pc,
+	}
+}
+
+func (k *KafkaProducer) ProduceLikedEvent(ctx context.Context, evt LikedEvent) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, _, err = k.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: TopicLiked,
+		Key:   sarama.StringEncoder(strconv.FormatInt(evt.BizOwner, 10)),
+		Value: sarama.ByteEncoder(data),
+	})
+	return err
+}
@@ -2,14 +2,19 @@ package grpc
 
 import (
 	"context"
+	"errors"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	"webooktrial/api/proto/gen/intr/v1"
+	followcli "webooktrial/follow/client"
 	"webooktrial/interactive/domain"
+	"webooktrial/interactive/events"
 	"webooktrial/interactive/service"
+	"webooktrial/pkg/bizowner"
+	"webooktrial/pkg/logger"
 )
 
 // InteractiveServiceServer 只是把 service 包装成一个 grpc
@@ -18,10 +23,20 @@ type InteractiveServiceServer struct {
 	intrv1.UnimplementedInteractiveServiceServer
 	// 核心业务逻辑一定是在 service 里
 	svc service.InteractiveService
+	// blockChecker 被作者拉黑了就不能点赞和收藏
+	blockChecker followcli.BlockChecker
+	// owners 资源的作者，不能用调用方传过来的
+	owners bizowner.Resolver
+	// producer 只有这一层知道资源的作者，所以点赞事件在这里发
+	producer events.Producer
+	l        logger.LoggerV1
 }
 
-func NewInteractiveServiceServer(svc service.InteractiveService) *InteractiveServiceServer {
-	return &InteractiveServiceServer{svc: svc}
+func NewInteractiveServiceServer(svc service.InteractiveService,
+	blockChecker followcli.BlockChecker, owners bizowner.Resolver,
+	producer events.Producer, l logger.LoggerV1) *InteractiveServiceServer {
+	return &InteractiveServiceServer{svc: svc, blockChecker: blockChecker, owners: owners,
+		producer: producer, l: l}
 }
 
 func (i *InteractiveServiceServer) Register(server *grpc.Server) {
@@ -34,8 +49,27 @@ func (i *InteractiveServiceServer) IncrReadCnt(ctx context.Context, request *int
 }
 
 func (i *InteractiveServiceServer) Like(ctx context.Context, request *intrv1.LikeRequest) (*intrv1.LikeResponse, error) {
-	err := i.svc.Like(ctx, request.GetBiz(), request.GetBizId(), request.GetUid())
-	return &intrv1.LikeResponse{}, err
+	owner, err := i.checkBlocked(ctx, request.GetBiz(), request.GetBizId(), request.GetUid())
+	if err != nil {
+		return nil, err
+	}
+	err = i.svc.Like(ctx, request.GetBiz(), request.GetBizId(), request.GetUid())
+	if err != nil {
+		return nil, err
+	}
+	er := i.producer.ProduceLikedEvent(ctx, events.LikedEvent{
+		Biz:      request.GetBiz(),
+		BizId:    request.GetBizId(),
+		BizOwner: owner,
+		Uid:      request.GetUid(),
+	})
+	if er != nil {
+		i.l.Error("发送点赞事件失败",
+			logger.String("biz", request.GetBiz()),
+			logger.Int64("bizId", request.GetBizId()),
+			logger.Error(er))
+	}
+	return &intrv1.LikeResponse{}, nil
 }
 
 func (i *InteractiveServiceServer) CancelLike(ctx context.Context, request *intrv1.CancelLikeRequest) (*intrv1.CancelLikeResponse, error) {
@@ -47,10 +81,40 @@ func (i *InteractiveServiceServer) CancelLike(ctx context.Context, request *intr
 }
 
 func (i *InteractiveServiceServer) Collect(ctx context.Context, request *intrv1.CollectRequest) (*intrv1.CollectResponse, error) {
-	err := i.svc.Collect(ctx, request.GetBiz(), request.GetBizId(), request.GetCid(), request.GetUid())
+	_, err := i.checkBlocked(ctx, request.GetBiz(), request.GetBizId(), request.GetUid())
+	if err != nil {
+		return nil, err
+	}
+	err = i.svc.Collect(ctx, request.GetBiz(), request.GetBizId(), request.GetCid(), request.GetUid())
 	return &intrv1.CollectResponse{}, err
 }
 
+// checkBlocked 返回资源的作者，查不到作者的时候不校验，作者是 0。
+// follow 服务出问题的时候放行，不能因为它影响点赞和收藏
+func (i *InteractiveServiceServer) checkBlocked(ctx context.Context, biz string, bizId, uid int64) (int64, error) {
+	owner, err := i.owners.Owner(ctx, biz, bizId)
+	if err != nil {
+		if !errors.Is(err, bizowner.ErrOwnerNotFound) {
+			i.l.Error("查询资源的作者失败",
+				logger.String("biz", biz),
+				logger.Int64("bizId", bizId),
+				logger.Error(err))
+		}
+		return 0, nil
+	}
+	if owner == uid {
+		return owner, nil
+	}
+	blocked, err := i.blockChecker.IsBlocked(ctx, owner, uid)
+	if err != nil {
+		return owner, err
+	}
+	if blocked {
+		return owner, status.Errorf(codes.PermissionDenied, "被作者拉黑了")
+	}
+	return owner, nil
+}
+
 func (i *InteractiveServiceServer) Get(ctx context.Context, request *intrv1.GetRequest) (*intrv1.GetResponse, error) {
 	intr, err := i.svc.Get(ctx, request.GetBiz(), request.GetBizId(), request.GetUid())
 	if err != nil {
@@ -0,0 +1,30 @@
+package startup
+
+import (
+	"context"
+
+	followcli "webooktrial/follow/client"
+	"webooktrial/pkg/bizowner"
+)
+
+// InitBlockChecker 测试里面不启动 follow 服务，谁都没有被拉黑
+func InitBlockChecker() followcli.BlockChecker {
+	return nopBlockChecker{}
+}
+
+type nopBlockChecker struct{}
+
+func (nopBlockChecker) IsBlocked(ctx context.Context, blocker, blocked int64) (bool, error) {
+	return false, nil
+}
+
+// InitBizOwnerResolver 测试里面不同步文章的作者，都查不到
+func InitBizOwnerResolver() bizowner.Resolver {
+	return nopResolver{}
+}
+
+type nopResolver struct{}
+
+func (nopResolver) Owner(ctx context.Context, biz string, bizId int64) (int64, error) {
+	return 0, bizowner.ErrOwnerNotFound
+}
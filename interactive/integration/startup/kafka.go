@@ -0,0 +1,18 @@
+package startup
+
+import (
+	"context"
+
+	"webooktrial/interactive/events"
+)
+
+// InitProducer 测试里面不关心下游，消息直接丢掉
+func InitProducer() events.Producer {
+	return nopProducer{}
+}
+
+type nopProducer struct{}
+
+func (nopProducer) ProduceLikedEvent(ctx context.Context, evt events.LikedEvent) error {
+	return nil
+}
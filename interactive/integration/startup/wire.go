@@ -28,6 +28,7 @@ func InitInteractiveService() service2.InteractiveService {
 }
 
 func InitInteractiveGRPCServer() *grpc.InteractiveServiceServer {
-	wire.Build(thirdProvider, interactiveSvcProvider, grpc.NewInteractiveServiceServer)
+	wire.Build(thirdProvider, interactiveSvcProvider,
+		InitBlockChecker, InitBizOwnerResolver, InitProducer, grpc.NewInteractiveServiceServer)
 	return new(grpc.InteractiveServiceServer)
 }
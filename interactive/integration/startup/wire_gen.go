@@ -36,7 +36,10 @@ func InitInteractiveGRPCServer() *grpc.InteractiveServiceServer {
 	loggerV1 := InitLog()
 	interactiveRepository := repository.NewCachedInteractiveRepository(interactiveDAO, interactiveCache, loggerV1)
 	interactiveService := service.NewInteractiveService(interactiveRepository, loggerV1)
-	interactiveServiceServer := grpc.NewInteractiveServiceServer(interactiveService)
+	blockChecker := InitBlockChecker()
+	resolver := InitBizOwnerResolver()
+	producer := InitProducer()
+	interactiveServiceServer := grpc.NewInteractiveServiceServer(interactiveService, blockChecker, resolver, producer, loggerV1)
 	return interactiveServiceServer
 }
 
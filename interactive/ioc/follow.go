@@ -0,0 +1,12 @@
+package ioc
+
+import (
+	etcdv3 "go.etcd.io/etcd/client/v3"
+
+	followv1 "webooktrial/api/proto/gen/follow/v1"
+	followcli "webooktrial/follow/client"
+)
+
+func InitFollowClient(etcdClient *etcdv3.Client) followv1.FollowServiceClient {
+	return followcli.InitFollowClient(etcdClient, "interactive")
+}
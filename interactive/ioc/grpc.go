@@ -12,9 +12,12 @@ import (
 func InitGRPCxServer(l logger.LoggerV1,
 	intrServer *grpc2.InteractiveServiceServer) *grpcx.Server {
 	type Config struct {
-		port      int      `yaml:"port"`
+		Port      int      `yaml:"port"`
 		EtcdAddrs []string `yaml:"etcdAddrs"`
 		EtcdTTL   int64    `yaml:"etcdTTL"`
+		Weight    int      `yaml:"weight"`
+		Labels    []string `yaml:"labels"`
+		Group     string   `yaml:"group"`
 	}
 	var cfg Config
 	err := viper.UnmarshalKey("grpc.server", &cfg)
@@ -25,9 +28,12 @@ func InitGRPCxServer(l logger.LoggerV1,
 	intrServer.Register(server)
 	return &grpcx.Server{
 		Server:    server,
-		Port:      cfg.port,
+		Port:      cfg.Port,
 		EtcdAddrs: cfg.EtcdAddrs,
 		EtcdTTL:   cfg.EtcdTTL,
+		Weight:    cfg.Weight,
+		Labels:    cfg.Labels,
+		Group:     cfg.Group,
 		Name:      "interactive",
 		L:         l,
 	}
@@ -11,5 +11,5 @@ func InitLogger() logger.LoggerV1 {
 	if err != nil {
 		panic(err)
 	}
-	return logger.NewZapLogger(l)
+	return logger.NewRedactLogger(logger.NewZapLogger(l), logger.NewRedactor())
 }
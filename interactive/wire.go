@@ -5,6 +5,7 @@ package main
 import (
 	"github.com/google/wire"
 
+	followcli "webooktrial/follow/client"
 	"webooktrial/interactive/events"
 	"webooktrial/interactive/grpc"
 	"webooktrial/interactive/ioc"
@@ -12,6 +13,7 @@ import (
 	cache "webooktrial/interactive/repository/cache/redis"
 	"webooktrial/interactive/repository/dao"
 	"webooktrial/interactive/service"
+	"webooktrial/pkg/grpcx"
 )
 
 var thirdPartySet = wire.NewSet(
@@ -21,9 +23,14 @@ var thirdPartySet = wire.NewSet(
 	ioc.InitDoubleWritePool,
 	ioc.InitLogger,
 	ioc.InitKafka,
-	// 暂时不理会 consumer 怎么启动
 	ioc.InitSyncProducer,
-	ioc.InitRedis)
+	ioc.InitRedis,
+	grpcx.InitEtcdClient)
+
+var followClientProvider = wire.NewSet(
+	ioc.InitFollowClient,
+	followcli.InitBlockChecker,
+	ioc.InitBizOwnerResolver)
 
 var interactiveSvcProvider = wire.NewSet(
 	service.NewInteractiveService,
@@ -41,7 +48,9 @@ func InitApp() *App {
 	wire.Build(interactiveSvcProvider,
 		thirdPartySet,
 		migratorProvider,
+		followClientProvider,
 		events.NewInteractiveReadEventConsumer,
+		events.NewKafkaProducer,
 		grpc.NewInteractiveServiceServer,
 		ioc.NewConsumers,
 		ioc.InitGRPCxServer,
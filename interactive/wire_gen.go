@@ -8,6 +8,7 @@ package main
 
 import (
 	"github.com/google/wire"
+	followcli "webooktrial/follow/client"
 	"webooktrial/interactive/events"
 	"webooktrial/interactive/grpc"
 	"webooktrial/interactive/ioc"
@@ -15,6 +16,7 @@ import (
 	"webooktrial/interactive/repository/cache/redis"
 	"webooktrial/interactive/repository/dao"
 	"webooktrial/interactive/service"
+	"webooktrial/pkg/grpcx"
 )
 
 // Injectors from wire.go:
@@ -30,15 +32,20 @@ func InitApp() *App {
 	interactiveCache := redis.NewRedisInteractiveCache(cmdable)
 	interactiveRepository := repository.NewCachedInteractiveRepository(interactiveDAO, interactiveCache, loggerV1)
 	interactiveService := service.NewInteractiveService(interactiveRepository, loggerV1)
-	interactiveServiceServer := grpc.NewInteractiveServiceServer(interactiveService)
-	server := ioc.InitGRPCxServer(loggerV1, interactiveServiceServer)
+	etcdClient := grpcx.InitEtcdClient()
+	followServiceClient := ioc.InitFollowClient(etcdClient)
+	blockChecker := followcli.InitBlockChecker(followServiceClient, loggerV1)
 	client := ioc.InitKafka()
+	resolver := ioc.InitBizOwnerResolver(db, client, loggerV1)
+	syncProducer := ioc.InitSyncProducer(client)
+	producer := events.NewKafkaProducer(syncProducer)
+	interactiveServiceServer := grpc.NewInteractiveServiceServer(interactiveService, blockChecker, resolver, producer, loggerV1)
+	server := ioc.InitGRPCxServer(loggerV1, interactiveServiceServer)
 	interactiveReadEventConsumer := events.NewInteractiveReadEventConsumer(client, interactiveRepository, loggerV1)
 	consumer := ioc.InitFixDataConsumer(loggerV1, srcDB, dstDB, client)
 	v := ioc.NewConsumers(interactiveReadEventConsumer, consumer)
-	syncProducer := ioc.InitSyncProducer(client)
-	producer := ioc.InitMigradatorProducer(syncProducer)
-	ginxServer := ioc.InitMigratorWeb(loggerV1, srcDB, dstDB, doubleWritePool, producer)
+	producer2 := ioc.InitMigradatorProducer(syncProducer)
+	ginxServer := ioc.InitMigratorWeb(loggerV1, srcDB, dstDB, doubleWritePool, producer2)
 	app := &App{
 		server:    server,
 		consumers: v,
@@ -49,7 +56,9 @@ func InitApp() *App {
 
 // wire.go:
 
-var thirdPartySet = wire.NewSet(ioc.InitDST, ioc.InitSRC, ioc.InitBizDB, ioc.InitDoubleWritePool, ioc.InitLogger, ioc.InitKafka, ioc.InitSyncProducer, ioc.InitRedis)
+var thirdPartySet = wire.NewSet(ioc.InitDST, ioc.InitSRC, ioc.InitBizDB, ioc.InitDoubleWritePool, ioc.InitLogger, ioc.InitKafka, ioc.InitSyncProducer, ioc.InitRedis, grpcx.InitEtcdClient)
+
+var followClientProvider = wire.NewSet(ioc.InitFollowClient, followcli.InitBlockChecker, ioc.InitBizOwnerResolver)
 
 var interactiveSvcProvider = wire.NewSet(service.NewInteractiveService, repository.NewCachedInteractiveRepository, dao.NewGORMInteractiveDAO, redis.NewRedisInteractiveCache)
 
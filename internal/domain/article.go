@@ -13,7 +13,15 @@ type Article struct {
 	Status ArticleStatus
 	Ctime  time.Time
 	Utime  time.Time
-	
+	// PublishAt 定时发表的时间，零值表示立刻发表
+	PublishAt time.Time
+	// UnpublishAt 定时撤回的时间，零值表示不会自动撤回
+	UnpublishAt time.Time
+	// Category 分类，一篇文章只有一个分类
+	Category string
+	// Tags 标签的名字，nil 表示保存的时候不修改标签
+	Tags []string
+
 	// 做成这样，就应该在 service 或者 repository 里面完成构造
 	// 设计成这个样子，就认为 Interactive 是 Article 的一个属性（值对象）
 	// Intr Interactive
@@ -28,6 +36,8 @@ const (
 	ArticleStatusUnpublished
 	ArticleStatusPublished
 	ArticleStatusPrivate
+	// ArticleStatusScheduled 等待定时发表
+	ArticleStatusScheduled
 )
 
 func (a Article) Abstract() string {
@@ -58,6 +68,8 @@ func (s ArticleStatus) String() string {
 		return "unpublished"
 	case ArticleStatusPublished:
 		return "published"
+	case ArticleStatusScheduled:
+		return "scheduled"
 	default:
 		return "unknown"
 	}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"time"
+
+	"webooktrial/pkg/diffx"
+)
+
+// ArticleRevision 文章的历史版本，作者保存和发表都会产生一个
+type ArticleRevision struct {
+	ArticleId int64
+	// Version 同一篇文章内递增
+	Version int64
+	Title   string
+	Content string
+	Author  Author
+	Status  ArticleStatus
+	// Published 是不是发表的时候产生的版本
+	Published bool
+	Ctime     time.Time
+}
+
+// ArticleRevisionDiff 两个版本之间的差异，按行比较
+type ArticleRevisionDiff struct {
+	ArticleId int64
+	From      int64
+	To        int64
+	Title     []diffx.Line
+	Content   []diffx.Line
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// HistoryRecord 阅读记录，同一个人读同一篇文章只有一条，以最后一次阅读为准
+type HistoryRecord struct {
+	Uid int64
+	// Article 查询的时候才会填充标题之类的信息，写入的时候只需要 Id
+	Article Article
+	// Rtime 最后一次阅读的时间
+	Rtime time.Time
+}
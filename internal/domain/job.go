@@ -21,7 +21,11 @@ type Job struct {
 var parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom |
 	cron.Month | cron.Dow | cron.Descriptor)
 
+// NextTime cron 表达式不合法的时候返回零值，也就是不再调度
 func (j Job) NextTime() time.Time {
-	s, _ := parser.Parse(j.Cron)
+	s, err := parser.Parse(j.Cron)
+	if err != nil {
+		return time.Time{}
+	}
 	return s.Next(time.Now())
 }
@@ -0,0 +1,9 @@
+package domain
+
+// Tag 文章标签
+type Tag struct {
+	Id   int64
+	Name string
+	// ArticleCnt 这个标签下面已经发表的文章数量
+	ArticleCnt int64
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// Upload 用户上传的图片。同一个人上传同样的内容只会有一条记录，
+// 不同的人上传同样的内容，在对象存储上也只有一份
+type Upload struct {
+	Id  int64
+	Uid int64
+	// Hash 内容的 sha256，十六进制
+	Hash        string
+	ContentType string
+	Size        int64
+	Width       int
+	Height      int
+	// Key 原图在对象存储上的 key
+	Key string
+	// ThumbKey 缩略图在对象存储上的 key
+	ThumbKey string
+	Ctime    time.Time
+}
+
+// UploadRefVersion 文章的哪个版本引用了图片。
+// 编辑只改制作库，线上库还在用原来的图片，所以两个版本分开记录
+type UploadRefVersion uint8
+
+const (
+	UploadRefVersionUnknown UploadRefVersion = iota
+	// UploadRefVersionDraft 制作库
+	UploadRefVersionDraft
+	// UploadRefVersionPublished 线上库
+	UploadRefVersionPublished
+)
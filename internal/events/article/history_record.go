@@ -2,23 +2,30 @@ package article
 
 import (
 	"context"
+	"time"
 
 	"github.com/IBM/sarama"
 
+	"webooktrial/internal/domain"
+	"webooktrial/internal/repository"
 	"webooktrial/pkg/logger"
 	"webooktrial/pkg/saramax"
 )
 
 type HistoryReadEventConsumer struct {
 	client sarama.Client
+	repo   repository.HistoryRecordRepository
 	l      logger.LoggerV1
+	cancel context.CancelFunc
 }
 
 func NewHistoryReadEventConsumer(
 	client sarama.Client,
+	repo repository.HistoryRecordRepository,
 	l logger.LoggerV1) *HistoryReadEventConsumer {
 	return &HistoryReadEventConsumer{
 		client: client,
+		repo:   repo,
 		l:      l,
 	}
 }
@@ -29,21 +36,39 @@ func (r *HistoryReadEventConsumer) Start() error {
 	if err != nil {
 		return err
 	}
-	go func() {
-		err := cg.Consume(context.Background(),
-			[]string{"read_article"},
-			saramax.NewHandler[ReadEvent](r.l, r.Consume))
-		if err != nil {
-			r.l.Error("退出了消费循环异常", logger.Error(err))
-		}
-	}()
-	return err
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	go saramax.ConsumeLoop(ctx, cg, []string{TopicReadEvent},
+		saramax.NewBatchHandler[ReadEventMsg](r.l, r.Consume), r.l)
+	return nil
+}
+
+// Close 停止消费
+func (r *HistoryReadEventConsumer) Close() error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	return nil
 }
 
-// Consume 这个不是幂等的
-func (r *HistoryReadEventConsumer) Consume(msg *sarama.ConsumerMessage, t ReadEvent) error {
-	//ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	//defer cancel()
-	//return r.repo.Add(ctx, t.Aid, t.Uid)
-	panic("implement me")
+// Consume 阅读时间取最晚的，所以重复消费也没有关系
+func (r *HistoryReadEventConsumer) Consume(msgs []*sarama.ConsumerMessage, ts []ReadEventMsg) error {
+	records := make([]domain.HistoryRecord, 0, len(ts))
+	for i, t := range ts {
+		for _, evt := range t.Events() {
+			rtime := time.UnixMilli(evt.Ctime)
+			if evt.Ctime == 0 {
+				// 老版本的事件没有阅读时间，用消息的时间
+				rtime = msgs[i].Timestamp
+			}
+			records = append(records, domain.HistoryRecord{
+				Uid:     evt.Uid,
+				Article: domain.Article{Id: evt.Aid},
+				Rtime:   rtime,
+			})
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return r.repo.AddRecord(ctx, records...)
 }
@@ -0,0 +1,55 @@
+package article
+
+import (
+	"context"
+	"time"
+
+	"webooktrial/internal/repository"
+	"webooktrial/internal/repository/cache/local"
+	"webooktrial/internal/repository/cache/redis"
+	"webooktrial/pkg/logger"
+)
+
+// CacheInvalidationConsumer 监听文章变更的广播，清理本实例的本地缓存
+type CacheInvalidationConsumer struct {
+	invalidator redis.ArticleInvalidator
+	localCache  *local.ArticleLocalCache
+	ranking     repository.RankingRepository
+	l           logger.LoggerV1
+}
+
+func NewCacheInvalidationConsumer(
+	invalidator redis.ArticleInvalidator,
+	localCache *local.ArticleLocalCache,
+	ranking repository.RankingRepository,
+	l logger.LoggerV1) *CacheInvalidationConsumer {
+	return &CacheInvalidationConsumer{
+		invalidator: invalidator,
+		localCache:  localCache,
+		ranking:     ranking,
+		l:           l,
+	}
+}
+
+func (c *CacheInvalidationConsumer) Start() error {
+	go func() {
+		err := c.invalidator.Subscribe(context.Background(), c.Consume)
+		if err != nil {
+			c.l.Error("退出了文章缓存失效的订阅", logger.Error(err))
+		}
+	}()
+	return nil
+}
+
+func (c *CacheInvalidationConsumer) Consume(change redis.ArticleChange) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = c.localCache.DelPub(ctx, change.Id)
+	if change.Withdrawn {
+		// 每个实例都会执行一遍，Redis 里面的热榜删除是幂等的
+		err := c.ranking.RemoveArticle(ctx, change.Id)
+		if err != nil {
+			c.l.Error("从热榜中移除文章失败", logger.Int64("aid", change.Id), logger.Error(err))
+		}
+	}
+}
@@ -3,13 +3,19 @@ package article
 import (
 	"context"
 	"encoding/json"
+	"strconv"
 
 	"github.com/IBM/sarama"
 )
 
 type Producer interface {
 	ProduceReadEvent(ctx context.Context, evt ReadEvent) error
-	ProduceReadEventV1(ctx context.Context, v1 ReadEventV1)
+	// ProduceReadEventV1 一条消息里面有多个阅读事件
+	ProduceReadEventV1(ctx context.Context, v1 ReadEventV1) error
+	// ProducePublishedEvent 文章发表了，包括定时发表
+	ProducePublishedEvent(ctx context.Context, evt PublishedEvent) error
+	// ProduceWithdrawnEvent 文章撤回了，包括定时撤回
+	ProduceWithdrawnEvent(ctx context.Context, evt WithdrawnEvent) error
 }
 
 type KafkaProducer struct {
@@ -28,15 +34,51 @@ func (k *KafkaProducer) ProduceReadEvent(ctx context.Context, evt ReadEvent) err
 		return err
 	}
 	_, _, err = k.producer.SendMessage(&sarama.ProducerMessage{
-		Topic: "read_article",
+		Topic: TopicReadEvent,
 		Value: sarama.ByteEncoder(data),
 	})
 	return err
 }
 
-func (k *KafkaProducer) ProduceReadEventV1(ctx context.Context, v1 ReadEventV1) {
-	//TODO implement me
-	panic("implement me")
+func (k *KafkaProducer) ProduceReadEventV1(ctx context.Context, v1 ReadEventV1) error {
+	data, err := json.Marshal(v1)
+	if err != nil {
+		return err
+	}
+	_, _, err = k.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: TopicReadEvent,
+		Value: sarama.ByteEncoder(data),
+	})
+	return err
+}
+
+func (k *KafkaProducer) ProducePublishedEvent(ctx context.Context, evt PublishedEvent) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, _, err = k.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: TopicPublishedEvent,
+		// 同一篇文章的事件落到同一个分区，保证顺序
+		Key:   sarama.StringEncoder(strconv.FormatInt(evt.Aid, 10)),
+		Value: sarama.ByteEncoder(data),
+	})
+	return err
+}
+
+func (k *KafkaProducer) ProduceWithdrawnEvent(ctx context.Context, evt WithdrawnEvent) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, _, err = k.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: TopicWithdrawnEvent,
+		// 同一篇文章的撤回事件落到同一个分区。
+		// 发表和撤回是两个 topic，它们之间没有顺序保证，下游要用 Utime 丢掉旧的事件
+		Key:   sarama.StringEncoder(strconv.FormatInt(evt.Aid, 10)),
+		Value: sarama.ByteEncoder(data),
+	})
+	return err
 }
 
 // ProduceReadEvent 如果你有复杂的重试逻辑，就用装饰器
@@ -45,9 +87,67 @@ func (k *KafkaProducer) ProduceReadEventV1(ctx context.Context, v1 ReadEventV1)
 type ReadEvent struct {
 	Uid int64
 	Aid int64
+	// Ctime 阅读的时间，毫秒数，阅读记录要用
+	Ctime int64
 }
 
+const TopicPublishedEvent = "article_published"
+
+// PublishedEvent 搜索之类的下游需要文章的内容，所以这里带上了
+type PublishedEvent struct {
+	Aid     int64
+	Uid     int64
+	Title   string
+	Content string
+	// 发表时间，毫秒数
+	Utime    int64
+	Category string
+	Tags     []string
+}
+
+const TopicWithdrawnEvent = "article_withdrawn"
+
+type WithdrawnEvent struct {
+	Aid int64
+	Uid int64
+	// 撤回时间，毫秒数。和发表事件的 Utime 一起当作版本号，
+	// 下游用它丢掉乱序到达的旧事件
+	Utime int64
+}
+
+// ReadEventV1 批量的阅读事件，下标相同的是同一次阅读
 type ReadEventV1 struct {
 	Uids []int64
 	Aids []int64
+	// Ctimes 老版本没有这个字段
+	Ctimes []int64
+}
+
+func (v ReadEventV1) Events() []ReadEvent {
+	res := make([]ReadEvent, 0, len(v.Aids))
+	for i, aid := range v.Aids {
+		evt := ReadEvent{Aid: aid}
+		if i < len(v.Uids) {
+			evt.Uid = v.Uids[i]
+		}
+		if i < len(v.Ctimes) {
+			evt.Ctime = v.Ctimes[i]
+		}
+		res = append(res, evt)
+	}
+	return res
+}
+
+// ReadEventMsg read_article 里面既有单个的 ReadEvent，也有批量的 ReadEventV1，
+// 两者的字段不重叠，所以消费者用这个来反序列化
+type ReadEventMsg struct {
+	ReadEvent
+	ReadEventV1
+}
+
+func (m ReadEventMsg) Events() []ReadEvent {
+	if len(m.Aids) > 0 {
+		return m.ReadEventV1.Events()
+	}
+	return []ReadEvent{m.ReadEvent}
 }
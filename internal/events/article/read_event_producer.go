@@ -0,0 +1,267 @@
+package article
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"webooktrial/pkg/logger"
+)
+
+const TopicReadEvent = "read_article"
+
+var (
+	ErrProducerClosed   = errors.New("producer 已经关闭")
+	ErrReadEventDropped = errors.New("阅读事件缓冲区满了，事件被丢弃")
+)
+
+type BatchReadEventConfig struct {
+	// BatchSize 攒够这么多个事件就发送
+	BatchSize int `yaml:"batchSize"`
+	// Interval 没有攒够也最多等这么久
+	Interval time.Duration `yaml:"interval"`
+	// BufferSize 还没有发送的事件最多有这么多个
+	BufferSize int `yaml:"bufferSize"`
+	// DropWhenFull 缓冲区满了的时候直接丢弃，否则最多阻塞 BlockTimeout
+	DropWhenFull bool `yaml:"dropWhenFull"`
+	// BlockTimeout 缓冲区满了的时候最多等这么久，ctx 先过期的以 ctx 为准。
+	// gin 的 ctx 是不会过期的，不能只靠它
+	BlockTimeout time.Duration `yaml:"blockTimeout"`
+}
+
+// BatchReadEventProducer 阅读事件的量很大，先在内存里面攒一批，
+// 凑够数量或者到了时间就合并成一个 ReadEventV1，用异步的 producer 发出去。
+// 其它事件的量很小，还是交给 Producer 同步发送
+type BatchReadEventProducer struct {
+	Producer
+	producer sarama.AsyncProducer
+	l        logger.LoggerV1
+	cfg      BatchReadEventConfig
+
+	events chan ReadEvent
+	// mu 保护 closed，发送的时候不持有，避免 Close 等阻塞的发送
+	mu     sync.RWMutex
+	closed bool
+	// sending 正在往 events 里面放的 ProduceReadEvent
+	sending sync.WaitGroup
+	// closing 关闭了之后阻塞的 ProduceReadEvent 马上返回
+	closing chan struct{}
+	// drained 所有的 ProduceReadEvent 都返回了，loop 可以清空缓冲区了
+	drained chan struct{}
+	once    sync.Once
+	// done 所有的事件都交给了 Kafka，并且拿到了结果
+	done chan struct{}
+
+	// counter 按照事件的个数统计 sent, failed, dropped
+	counter *prometheus.CounterVec
+}
+
+func NewBatchReadEventProducer(base Producer, producer sarama.AsyncProducer,
+	l logger.LoggerV1, cfg BatchReadEventConfig) *BatchReadEventProducer {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Millisecond * 100
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 4096
+	}
+	if cfg.BlockTimeout <= 0 {
+		cfg.BlockTimeout = time.Millisecond * 50
+	}
+	b := &BatchReadEventProducer{
+		Producer: base,
+		producer: producer,
+		l:        l,
+		cfg:      cfg,
+		events:   make(chan ReadEvent, cfg.BufferSize),
+		closing:  make(chan struct{}),
+		drained:  make(chan struct{}),
+		done:     make(chan struct{}),
+		counter:  newReadEventCounter(),
+	}
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		b.loop()
+	}()
+	go func() {
+		defer wg.Done()
+		for msg := range producer.Successes() {
+			b.counter.WithLabelValues("sent").Add(batchLen(msg))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for err := range producer.Errors() {
+			b.l.Error("发送阅读事件失败", logger.Error(err.Err))
+			b.counter.WithLabelValues("failed").Add(batchLen(err.Msg))
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(b.done)
+	}()
+	return b
+}
+
+// newReadEventCounter 测试里面会创建多个 producer，所以已经注册过的就复用
+func newReadEventCounter() *prometheus.CounterVec {
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go_study",
+		Subsystem: "webook",
+		Name:      "read_event_producer",
+		Help:      "阅读事件的发送情况",
+	}, []string{"result"})
+	err := prometheus.Register(counter)
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		return are.ExistingCollector.(*prometheus.CounterVec)
+	}
+	return counter
+}
+
+func batchLen(msg *sarama.ProducerMessage) float64 {
+	n, _ := msg.Metadata.(int)
+	return float64(n)
+}
+
+// ProduceReadEvent 只是放进缓冲区，不会等 Kafka 的结果
+func (b *BatchReadEventProducer) ProduceReadEvent(ctx context.Context, evt ReadEvent) error {
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return ErrProducerClosed
+	}
+	b.sending.Add(1)
+	b.mu.RUnlock()
+	defer b.sending.Done()
+
+	select {
+	case b.events <- evt:
+		return nil
+	default:
+	}
+	if b.cfg.DropWhenFull {
+		b.counter.WithLabelValues("dropped").Inc()
+		return ErrReadEventDropped
+	}
+	timer := time.NewTimer(b.cfg.BlockTimeout)
+	defer timer.Stop()
+	select {
+	case b.events <- evt:
+		return nil
+	case <-timer.C:
+		b.counter.WithLabelValues("dropped").Inc()
+		return ErrReadEventDropped
+	case <-ctx.Done():
+		b.counter.WithLabelValues("dropped").Inc()
+		return ctx.Err()
+	case <-b.closing:
+		b.counter.WithLabelValues("dropped").Inc()
+		return ErrProducerClosed
+	}
+}
+
+func (b *BatchReadEventProducer) ProduceReadEventV1(ctx context.Context, v1 ReadEventV1) error {
+	for _, evt := range v1.Events() {
+		err := b.ProduceReadEvent(ctx, evt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close 发送缓冲区里面剩下的事件，并且等到 Kafka 返回结果。
+// ctx 过期了就直接返回，剩下的事件在后台继续发送
+func (b *BatchReadEventProducer) Close(ctx context.Context) error {
+	b.once.Do(func() {
+		b.mu.Lock()
+		b.closed = true
+		b.mu.Unlock()
+		close(b.closing)
+		// closed 之后 sending 不会再增加，等正在发送的返回之后再清空缓冲区
+		go func() {
+			b.sending.Wait()
+			close(b.drained)
+		}()
+	})
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *BatchReadEventProducer) loop() {
+	ticker := time.NewTicker(b.cfg.Interval)
+	defer ticker.Stop()
+	batch := make([]ReadEvent, 0, b.cfg.BatchSize)
+	add := func(evt ReadEvent) {
+		batch = append(batch, evt)
+		if len(batch) >= b.cfg.BatchSize {
+			b.send(batch)
+			batch = batch[:0]
+		}
+	}
+	for {
+		select {
+		case evt := <-b.events:
+			add(evt)
+		case <-ticker.C:
+			if len(batch) > 0 {
+				b.send(batch)
+				batch = batch[:0]
+			}
+		case <-b.drained:
+			// 不会再有新的事件了，把缓冲区清空就可以
+			for {
+				select {
+				case evt := <-b.events:
+					add(evt)
+				default:
+					if len(batch) > 0 {
+						b.send(batch)
+					}
+					// Successes 和 Errors 会在所有的消息都有结果之后关闭
+					b.producer.AsyncClose()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (b *BatchReadEventProducer) send(batch []ReadEvent) {
+	v1 := ReadEventV1{
+		Uids:   make([]int64, 0, len(batch)),
+		Aids:   make([]int64, 0, len(batch)),
+		Ctimes: make([]int64, 0, len(batch)),
+	}
+	for _, evt := range batch {
+		v1.Uids = append(v1.Uids, evt.Uid)
+		v1.Aids = append(v1.Aids, evt.Aid)
+		v1.Ctimes = append(v1.Ctimes, evt.Ctime)
+	}
+	data, err := json.Marshal(v1)
+	if err != nil {
+		b.l.Error("序列化阅读事件失败", logger.Error(err))
+		b.counter.WithLabelValues("failed").Add(float64(len(batch)))
+		return
+	}
+	// Kafka 那边的缓冲区满了也会阻塞在这里，最终会反馈到 ProduceReadEvent
+	b.producer.Input() <- &sarama.ProducerMessage{
+		Topic:    TopicReadEvent,
+		Value:    sarama.ByteEncoder(data),
+		Metadata: len(batch),
+	}
+}
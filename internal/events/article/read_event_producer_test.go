@@ -0,0 +1,181 @@
+package article
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"webooktrial/pkg/logger"
+)
+
+func TestBatchReadEventProducer(t *testing.T) {
+	testCases := []struct {
+		name string
+		cfg  BatchReadEventConfig
+		evts []ReadEvent
+		// 每一批的文章 ID
+		wantBatches [][]int64
+		failed      bool
+	}{
+		{
+			name: "凑够数量就发送",
+			cfg:  BatchReadEventConfig{BatchSize: 2, Interval: time.Hour},
+			evts: []ReadEvent{
+				{Uid: 1, Aid: 1, Ctime: 11},
+				{Uid: 1, Aid: 2, Ctime: 12},
+				{Uid: 2, Aid: 3, Ctime: 13},
+				{Uid: 2, Aid: 4, Ctime: 14},
+			},
+			wantBatches: [][]int64{{1, 2}, {3, 4}},
+		},
+		{
+			name: "关闭的时候发送剩下的",
+			cfg:  BatchReadEventConfig{BatchSize: 100, Interval: time.Hour},
+			evts: []ReadEvent{
+				{Uid: 1, Aid: 1, Ctime: 11},
+				{Uid: 1, Aid: 2, Ctime: 12},
+				{Uid: 2, Aid: 3, Ctime: 13},
+			},
+			wantBatches: [][]int64{{1, 2, 3}},
+		},
+		{
+			name: "发送失败",
+			cfg:  BatchReadEventConfig{BatchSize: 100, Interval: time.Hour},
+			evts: []ReadEvent{
+				{Uid: 1, Aid: 1, Ctime: 11},
+			},
+			wantBatches: [][]int64{{1}},
+			failed:      true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := mocks.NewTestConfig()
+			cfg.Producer.Return.Successes = true
+			ap := mocks.NewAsyncProducer(t, cfg)
+			for _, batch := range tc.wantBatches {
+				batch := batch
+				checker := func(msg *sarama.ProducerMessage) error {
+					assert.Equal(t, TopicReadEvent, msg.Topic)
+					data, err := msg.Value.Encode()
+					require.NoError(t, err)
+					var v1 ReadEventV1
+					require.NoError(t, json.Unmarshal(data, &v1))
+					assert.Equal(t, batch, v1.Aids)
+					assert.Equal(t, len(batch), len(v1.Uids))
+					assert.Equal(t, len(batch), len(v1.Ctimes))
+					return nil
+				}
+				if tc.failed {
+					ap.ExpectInputWithMessageCheckerFunctionAndFail(checker, errors.New("mock error"))
+				} else {
+					ap.ExpectInputWithMessageCheckerFunctionAndSucceed(checker)
+				}
+			}
+			p := NewBatchReadEventProducer(nil, ap, logger.NewNopLogger(), tc.cfg)
+			for _, evt := range tc.evts {
+				require.NoError(t, p.ProduceReadEvent(context.Background(), evt))
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			require.NoError(t, p.Close(ctx))
+			assert.Equal(t, ErrProducerClosed, p.ProduceReadEvent(context.Background(), ReadEvent{Uid: 1, Aid: 1}))
+		})
+	}
+}
+
+func TestBatchReadEventProducer_Full(t *testing.T) {
+	// 没有启动 loop，缓冲区放满了之后就不会有人来取
+	newProducer := func() *BatchReadEventProducer {
+		return &BatchReadEventProducer{
+			cfg:     BatchReadEventConfig{BlockTimeout: time.Millisecond * 10},
+			events:  make(chan ReadEvent, 1),
+			closing: make(chan struct{}),
+			drained: make(chan struct{}),
+			done:    make(chan struct{}),
+			counter: newReadEventCounter(),
+		}
+	}
+
+	t.Run("等待超时就丢弃", func(t *testing.T) {
+		p := newProducer()
+		require.NoError(t, p.ProduceReadEvent(context.Background(), ReadEvent{Aid: 1}))
+		assert.Equal(t, ErrReadEventDropped, p.ProduceReadEvent(context.Background(), ReadEvent{Aid: 2}))
+	})
+
+	t.Run("直接丢弃", func(t *testing.T) {
+		p := newProducer()
+		p.cfg = BatchReadEventConfig{DropWhenFull: true, BlockTimeout: time.Hour}
+		require.NoError(t, p.ProduceReadEvent(context.Background(), ReadEvent{Aid: 1}))
+		assert.Equal(t, ErrReadEventDropped, p.ProduceReadEvent(context.Background(), ReadEvent{Aid: 2}))
+	})
+
+	t.Run("ctx 先过期", func(t *testing.T) {
+		p := newProducer()
+		p.cfg.BlockTimeout = time.Hour
+		require.NoError(t, p.ProduceReadEvent(context.Background(), ReadEvent{Aid: 1}))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+		assert.Equal(t, context.DeadlineExceeded, p.ProduceReadEvent(ctx, ReadEvent{Aid: 2}))
+	})
+
+	t.Run("关闭的时候阻塞的发送马上返回，Close 按照 ctx 返回", func(t *testing.T) {
+		p := newProducer()
+		p.cfg.BlockTimeout = time.Hour
+		require.NoError(t, p.ProduceReadEvent(context.Background(), ReadEvent{Aid: 1}))
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- p.ProduceReadEvent(context.Background(), ReadEvent{Aid: 2})
+		}()
+		// 等发送的 goroutine 阻塞住
+		time.Sleep(time.Millisecond * 10)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+		defer cancel()
+		// 没有 loop，done 不会关闭
+		assert.Equal(t, context.DeadlineExceeded, p.Close(ctx))
+		assert.Equal(t, ErrProducerClosed, <-errCh)
+		select {
+		case <-p.drained:
+		case <-time.After(time.Second):
+			t.Fatal("正在发送的都返回了之后应该可以清空缓冲区")
+		}
+	})
+}
+
+func TestReadEventMsg_Events(t *testing.T) {
+	testCases := []struct {
+		name string
+		data string
+		want []ReadEvent
+	}{
+		{
+			name: "单个事件",
+			data: `{"Uid":1,"Aid":2,"Ctime":3}`,
+			want: []ReadEvent{{Uid: 1, Aid: 2, Ctime: 3}},
+		},
+		{
+			name: "批量事件",
+			data: `{"Uids":[1,2],"Aids":[3,4],"Ctimes":[5,6]}`,
+			want: []ReadEvent{{Uid: 1, Aid: 3, Ctime: 5}, {Uid: 2, Aid: 4, Ctime: 6}},
+		},
+		{
+			name: "老版本的批量事件没有时间",
+			data: `{"Uids":[1,2],"Aids":[3,4]}`,
+			want: []ReadEvent{{Uid: 1, Aid: 3}, {Uid: 2, Aid: 4}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var msg ReadEventMsg
+			require.NoError(t, json.Unmarshal([]byte(tc.data), &msg))
+			assert.Equal(t, tc.want, msg.Events())
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package startup
+
+import "webooktrial/internal/repository/article"
+
+func InitArticleRevisionRetention() article.RevisionRetention {
+	return article.RevisionRetention{
+		MaxCount: 10,
+	}
+}
@@ -0,0 +1,24 @@
+package startup
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	commentv1 "webooktrial/api/proto/gen/comment/v1"
+)
+
+// InitCommentClient 集成测试不启动 comment 服务，评论数都是 0，
+// 其它方法没有实现，调用了会 panic
+func InitCommentClient() commentv1.CommentServiceClient {
+	return noopCommentClient{}
+}
+
+type noopCommentClient struct {
+	commentv1.CommentServiceClient
+}
+
+func (noopCommentClient) GetCommentCount(ctx context.Context, in *commentv1.GetCommentCountRequest,
+	opts ...grpc.CallOption) (*commentv1.GetCommentCountResponse, error) {
+	return &commentv1.GetCommentCountResponse{}, nil
+}
@@ -0,0 +1,9 @@
+package startup
+
+import "webooktrial/pkg/moderation"
+
+func InitModerator() *moderation.Moderator {
+	return moderation.NewModerator(moderation.NewWordFilter([]moderation.Word{
+		{Text: "赌博", Score: 1},
+	}), moderation.Policy{ApproveBelow: 0.3, RejectAbove: 0.8})
+}
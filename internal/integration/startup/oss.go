@@ -0,0 +1,25 @@
+package startup
+
+import (
+	"os"
+
+	"webooktrial/internal/service"
+	"webooktrial/pkg/objectstore"
+)
+
+// InitObjectStore 集成测试里面用临时目录
+func InitObjectStore() objectstore.ObjectStore {
+	dir, err := os.MkdirTemp("", "webook-objects-")
+	if err != nil {
+		panic(err)
+	}
+	store, err := objectstore.NewLocalStore(dir, "http://localhost:8080/objects", "test-secret")
+	if err != nil {
+		panic(err)
+	}
+	return store
+}
+
+func InitUploadConfig() service.UploadConfig {
+	return service.UploadConfig{}
+}
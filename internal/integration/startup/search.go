@@ -0,0 +1,16 @@
+package startup
+
+import (
+	searchv1 "webooktrial/api/proto/gen/search/v1"
+	"webooktrial/client"
+	"webooktrial/search/grpc"
+	"webooktrial/search/repository"
+	"webooktrial/search/repository/dao"
+	"webooktrial/search/service"
+)
+
+// InitSearchClient 集成测试里面直接用内存索引，不需要启动 search 服务
+func InitSearchClient() searchv1.SearchServiceClient {
+	repo := repository.NewArticleRepository(dao.NewMemoryArticleIndex())
+	return client.NewSearchServiceAdapter(grpc.NewSearchServiceServer(service.NewSearchService(repo)))
+}
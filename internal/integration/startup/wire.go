@@ -13,6 +13,7 @@ import (
 	article3 "webooktrial/internal/events/article"
 	"webooktrial/internal/repository"
 	article2 "webooktrial/internal/repository/article"
+	"webooktrial/internal/repository/cache/local"
 	"webooktrial/internal/repository/cache/redis"
 	"webooktrial/internal/repository/dao"
 	"webooktrial/internal/repository/dao/article"
@@ -23,7 +24,8 @@ import (
 )
 
 var thirdProvider = wire.NewSet(InitRedis,
-	NewSyncProducer, InitTestDB, InitLog, InitKafka)
+	NewSyncProducer, InitTestDB, InitLog, InitKafka,
+	InitArticleRevisionRetention, InitModerator)
 var userSvcProvider = wire.NewSet(
 	dao.NewUserDAO,
 	redis.NewUserCache,
@@ -32,9 +34,34 @@ var userSvcProvider = wire.NewSet(
 
 var articleSvcProvider = wire.NewSet(
 	article.NewGormArticleDao,
+	article.NewGORMTagDAO,
 	article2.NewArticleRepository,
 	service.NewArticleService,
 	redis.NewRedisArticleCache,
+	redis.NewRedisArticleInvalidator,
+	local.NewArticleLocalCache,
+)
+
+var rankingSvcProvider = wire.NewSet(
+	repository.NewCachedRankingRepository,
+	redis.NewRankingRedisCache,
+	local.NewRankingLocalCache,
+	service.NewBatchRankingService,
+)
+
+var historySvcProvider = wire.NewSet(
+	dao.NewGORMHistoryRecordDAO,
+	redis.NewRedisHistoryCache,
+	repository.NewCachedHistoryRecordRepository,
+	service.NewHistoryService,
+)
+
+var uploadSvcProvider = wire.NewSet(
+	dao.NewGORMUploadDAO,
+	repository.NewUploadRepository,
+	service.NewUploadService,
+	InitObjectStore,
+	InitUploadConfig,
 )
 
 var interactiveSvcProvider = wire.NewSet(
@@ -53,7 +80,12 @@ func InitWebServer() *gin.Engine {
 		//article.NewGormArticleDao,
 		repository.NewCodeRepository,
 		interactiveSvcProvider,
+		rankingSvcProvider,
+		historySvcProvider,
+		uploadSvcProvider,
 		ioc.InitIntrGRPCClient,
+		InitSearchClient,
+		InitCommentClient,
 		//article2.NewArticleRepository,
 		// service 部分
 		// 集成测试我们显式指定使用内存实现
@@ -67,6 +99,7 @@ func InitWebServer() *gin.Engine {
 		web.NewUserHandler,
 		web.NewOAuth2WechatHandler,
 		web.NewArticleHandler,
+		web.NewUploadHandler,
 		//InitWechatHandlerConfig,
 		ijwt.NewRedisJWTHandler,
 
@@ -84,10 +117,18 @@ func InitArticleHandler(dao article.ArticleDao) *web.ArticleHandler {
 	wire.Build(thirdProvider,
 		userSvcProvider,
 		redis.NewRedisArticleCache,
+		redis.NewRedisArticleInvalidator,
+		local.NewArticleLocalCache,
 		interactiveSvcProvider,
+		rankingSvcProvider,
+		historySvcProvider,
+		uploadSvcProvider,
 		ioc.InitIntrGRPCClient,
+		InitSearchClient,
+		InitCommentClient,
 		//wire.InterfaceValue(new(article.ArticleDAO), dao),
 		//article.NewGormArticleDao,
+		article.NewGORMTagDAO,
 		article2.NewArticleRepository,
 		service.NewArticleService,
 		article3.NewKafkaProducer,
@@ -16,6 +16,7 @@ import (
 	article3 "webooktrial/internal/events/article"
 	"webooktrial/internal/repository"
 	article2 "webooktrial/internal/repository/article"
+	"webooktrial/internal/repository/cache/local"
 	"webooktrial/internal/repository/cache/redis"
 	"webooktrial/internal/repository/dao"
 	"webooktrial/internal/repository/dao/article"
@@ -45,19 +46,40 @@ func InitWebServer() *gin.Engine {
 	wechatService := InitPhantomWechatService(loggerV1)
 	oAuth2WechatHandler := web.NewOAuth2WechatHandler(wechatService, userService, handler)
 	articleDao := article.NewGormArticleDao(gormDB)
+	tagDAO := article.NewGORMTagDAO(gormDB)
 	articleCache := redis.NewRedisArticleCache(cmdable)
-	articleRepository := article2.NewArticleRepository(articleDao, loggerV1, articleCache, userRepository)
+	articleLocalCache := local.NewArticleLocalCache()
+	articleInvalidator := redis.NewRedisArticleInvalidator(cmdable)
+	revisionRetention := InitArticleRevisionRetention()
+	articleRepository := article2.NewArticleRepository(articleDao, loggerV1, tagDAO, articleCache, articleLocalCache, articleInvalidator, userRepository, revisionRetention)
 	client := InitKafka()
 	syncProducer := NewSyncProducer(client)
 	producer := article3.NewKafkaProducer(syncProducer)
-	articleService := service.NewArticleService(articleRepository, loggerV1, producer)
+	moderator := InitModerator()
+	articleService := service.NewArticleService(articleRepository, loggerV1, producer, moderator)
 	interactiveDAO := dao2.NewGORMInteractiveDAO(gormDB)
 	interactiveCache := redis2.NewRedisInteractiveCache(cmdable)
 	interactiveRepository := repository2.NewCachedInteractiveRepository(interactiveDAO, interactiveCache, loggerV1)
 	interactiveService := service2.NewInteractiveService(interactiveRepository, loggerV1)
 	interactiveServiceClient := ioc.InitIntrGRPCClient(interactiveService)
-	articleHandler := web.NewArticleHandler(articleService, loggerV1, interactiveServiceClient)
-	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler)
+	rankingRedisCache := redis.NewRankingRedisCache(cmdable)
+	rankingLocalCache := local.NewRankingLocalCache()
+	rankingRepository := repository.NewCachedRankingRepository(rankingRedisCache, rankingLocalCache)
+	rankingService := service.NewBatchRankingService(articleService, interactiveServiceClient, rankingRepository)
+	searchServiceClient := InitSearchClient()
+	historyRecordDAO := dao.NewGORMHistoryRecordDAO(gormDB)
+	historyCache := redis.NewRedisHistoryCache(cmdable)
+	historyRecordRepository := repository.NewCachedHistoryRecordRepository(historyRecordDAO, historyCache, loggerV1)
+	historyService := service.NewHistoryService(historyRecordRepository, articleRepository, loggerV1)
+	uploadDAO := dao.NewGORMUploadDAO(gormDB)
+	uploadRepository := repository.NewUploadRepository(uploadDAO)
+	objectStore := InitObjectStore()
+	uploadConfig := InitUploadConfig()
+	uploadService := service.NewUploadService(uploadRepository, objectStore, uploadConfig, loggerV1)
+	commentServiceClient := InitCommentClient()
+	articleHandler := web.NewArticleHandler(articleService, loggerV1, interactiveServiceClient, rankingService, searchServiceClient, historyService, uploadService, commentServiceClient)
+	uploadHandler := web.NewUploadHandler(uploadService, objectStore, uploadConfig, loggerV1)
+	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler, uploadHandler, objectStore)
 	return engine
 }
 
@@ -69,17 +91,37 @@ func InitArticleHandler(dao3 article.ArticleDao) *web.ArticleHandler {
 	userDAO := dao.NewUserDAO(gormDB)
 	userCache := redis.NewUserCache(cmdable)
 	userRepository := repository.NewUserRepository(userDAO, userCache)
-	articleRepository := article2.NewArticleRepository(dao3, loggerV1, articleCache, userRepository)
+	articleLocalCache := local.NewArticleLocalCache()
+	articleInvalidator := redis.NewRedisArticleInvalidator(cmdable)
+	tagDAO := article.NewGORMTagDAO(gormDB)
+	revisionRetention := InitArticleRevisionRetention()
+	articleRepository := article2.NewArticleRepository(dao3, loggerV1, tagDAO, articleCache, articleLocalCache, articleInvalidator, userRepository, revisionRetention)
 	client := InitKafka()
 	syncProducer := NewSyncProducer(client)
 	producer := article3.NewKafkaProducer(syncProducer)
-	articleService := service.NewArticleService(articleRepository, loggerV1, producer)
+	moderator := InitModerator()
+	articleService := service.NewArticleService(articleRepository, loggerV1, producer, moderator)
 	interactiveDAO := dao2.NewGORMInteractiveDAO(gormDB)
 	interactiveCache := redis2.NewRedisInteractiveCache(cmdable)
 	interactiveRepository := repository2.NewCachedInteractiveRepository(interactiveDAO, interactiveCache, loggerV1)
 	interactiveService := service2.NewInteractiveService(interactiveRepository, loggerV1)
 	interactiveServiceClient := ioc.InitIntrGRPCClient(interactiveService)
-	articleHandler := web.NewArticleHandler(articleService, loggerV1, interactiveServiceClient)
+	rankingRedisCache := redis.NewRankingRedisCache(cmdable)
+	rankingLocalCache := local.NewRankingLocalCache()
+	rankingRepository := repository.NewCachedRankingRepository(rankingRedisCache, rankingLocalCache)
+	rankingService := service.NewBatchRankingService(articleService, interactiveServiceClient, rankingRepository)
+	searchServiceClient := InitSearchClient()
+	historyRecordDAO := dao.NewGORMHistoryRecordDAO(gormDB)
+	historyCache := redis.NewRedisHistoryCache(cmdable)
+	historyRecordRepository := repository.NewCachedHistoryRecordRepository(historyRecordDAO, historyCache, loggerV1)
+	historyService := service.NewHistoryService(historyRecordRepository, articleRepository, loggerV1)
+	uploadDAO := dao.NewGORMUploadDAO(gormDB)
+	uploadRepository := repository.NewUploadRepository(uploadDAO)
+	objectStore := InitObjectStore()
+	uploadConfig := InitUploadConfig()
+	uploadService := service.NewUploadService(uploadRepository, objectStore, uploadConfig, loggerV1)
+	commentServiceClient := InitCommentClient()
+	articleHandler := web.NewArticleHandler(articleService, loggerV1, interactiveServiceClient, rankingService, searchServiceClient, historyService, uploadService, commentServiceClient)
 	return articleHandler
 }
 
@@ -103,10 +145,17 @@ func InitJwtHdl() jwt.Handler {
 // wire.go:
 
 var thirdProvider = wire.NewSet(InitRedis,
-	NewSyncProducer, InitTestDB, InitLog, InitKafka)
+	NewSyncProducer, InitTestDB, InitLog, InitKafka,
+	InitArticleRevisionRetention, InitModerator)
 
 var userSvcProvider = wire.NewSet(dao.NewUserDAO, redis.NewUserCache, repository.NewUserRepository, service.NewUserService)
 
-var articleSvcProvider = wire.NewSet(article.NewGormArticleDao, article2.NewArticleRepository, service.NewArticleService, redis.NewRedisArticleCache)
+var articleSvcProvider = wire.NewSet(article.NewGormArticleDao, article.NewGORMTagDAO, article2.NewArticleRepository, service.NewArticleService, redis.NewRedisArticleCache, redis.NewRedisArticleInvalidator, local.NewArticleLocalCache)
+
+var rankingSvcProvider = wire.NewSet(repository.NewCachedRankingRepository, redis.NewRankingRedisCache, local.NewRankingLocalCache, service.NewBatchRankingService)
+
+var historySvcProvider = wire.NewSet(dao.NewGORMHistoryRecordDAO, redis.NewRedisHistoryCache, repository.NewCachedHistoryRecordRepository, service.NewHistoryService)
+
+var uploadSvcProvider = wire.NewSet(dao.NewGORMUploadDAO, repository.NewUploadRepository, service.NewUploadService, InitObjectStore, InitUploadConfig)
 
 var interactiveSvcProvider = wire.NewSet(service2.NewInteractiveService, repository2.NewCachedInteractiveRepository, dao2.NewGORMInteractiveDAO, redis2.NewRedisInteractiveCache)
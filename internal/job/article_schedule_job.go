@@ -0,0 +1,36 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"webooktrial/internal/domain"
+	"webooktrial/internal/service"
+	"webooktrial/pkg/logger"
+)
+
+// ArticleScheduleJob 定时发表和定时撤回文章。
+// 注册到 Scheduler 上，任务在数据库里面被抢占了，同一时刻只有一个实例在执行
+type ArticleScheduleJob struct {
+	svc     service.ArticleService
+	timeout time.Duration
+	l       logger.LoggerV1
+}
+
+func NewArticleScheduleJob(svc service.ArticleService, timeout time.Duration,
+	l logger.LoggerV1) *ArticleScheduleJob {
+	return &ArticleScheduleJob{svc: svc,
+		timeout: timeout,
+		l:       l,
+	}
+}
+
+func (a *ArticleScheduleJob) Name() string {
+	return "article_schedule"
+}
+
+func (a *ArticleScheduleJob) Exec(ctx context.Context, j domain.Job) error {
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+	return a.svc.ExecuteSchedules(ctx, time.Now())
+}
@@ -2,6 +2,7 @@ package job
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -52,12 +53,15 @@ type Scheduler struct {
 	svc     service.JobService
 	l       logger.LoggerV1
 	limiter *semaphore.Weighted
+	// interval 没有抢到任务的时候，隔多久再抢
+	interval time.Duration
 }
 
 func NewScheduler(svc service.JobService, l logger.LoggerV1) *Scheduler {
 	return &Scheduler{svc: svc, l: l,
-		limiter: semaphore.NewWeighted(200),
-		execs:   make(map[string]Executor)}
+		limiter:  semaphore.NewWeighted(200),
+		execs:    make(map[string]Executor),
+		interval: time.Second}
 }
 func (s *Scheduler) RegisterExecutor(exec Executor) {
 	s.execs[exec.Name()] = exec
@@ -78,8 +82,16 @@ func (s *Scheduler) Schedule(ctx context.Context) error {
 		j, err := s.svc.Preempt(dbCtx)
 		cancel()
 		if err != nil {
-			// 继续下一轮抢占
-			s.l.Error("抢占任务失败", logger.Error(err))
+			s.limiter.Release(1)
+			if !errors.Is(err, service.ErrNoMoreJob) {
+				s.l.Error("抢占任务失败", logger.Error(err))
+			}
+			// 等一会再抢，不然没有任务的时候会一直查数据库
+			select {
+			case <-time.After(s.interval):
+			case <-ctx.Done():
+			}
+			continue
 		}
 		exec, ok := s.execs[j.Executor]
 		if !ok {
@@ -87,6 +99,10 @@ func (s *Scheduler) Schedule(ctx context.Context) error {
 			// 线上就继续
 			s.l.Error("未找到对应的执行器",
 				logger.String("executor", j.Executor))
+			s.limiter.Release(1)
+			if er := j.CancelFunc(); er != nil {
+				s.l.Error("释放任务失败", logger.Error(er), logger.Int64("jid", j.Id))
+			}
 			continue
 		}
 
@@ -0,0 +1,136 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"webooktrial/internal/domain"
+	"webooktrial/internal/service"
+	svcmocks "webooktrial/internal/service/mocks"
+	"webooktrial/pkg/logger"
+)
+
+// fakeJobService 前面几次没有任务或者出错，之后每次都能抢到 jobs 里面的任务
+type fakeJobService struct {
+	errs     chan error
+	jobs     chan domain.Job
+	released atomic.Int64
+	reset    chan domain.Job
+}
+
+func (f *fakeJobService) Add(ctx context.Context, j domain.Job) error {
+	return nil
+}
+
+func (f *fakeJobService) Preempt(ctx context.Context) (domain.Job, error) {
+	select {
+	case err := <-f.errs:
+		return domain.Job{}, err
+	default:
+	}
+	select {
+	case j := <-f.jobs:
+		j.CancelFunc = func() error {
+			f.released.Add(1)
+			return nil
+		}
+		return j, nil
+	default:
+		return domain.Job{}, service.ErrNoMoreJob
+	}
+}
+
+func (f *fakeJobService) ResetNextTime(ctx context.Context, j domain.Job) error {
+	f.reset <- j
+	return nil
+}
+
+type fakeExecutor struct {
+	name string
+	exec chan domain.Job
+}
+
+func (f fakeExecutor) Name() string {
+	return f.name
+}
+
+func (f fakeExecutor) Exec(ctx context.Context, j domain.Job) error {
+	f.exec <- j
+	return nil
+}
+
+func TestScheduler_Schedule(t *testing.T) {
+	svc := &fakeJobService{
+		errs:  make(chan error, 3),
+		jobs:  make(chan domain.Job, 2),
+		reset: make(chan domain.Job, 2),
+	}
+	svc.errs <- errors.New("数据库错误")
+	svc.errs <- service.ErrNoMoreJob
+	svc.jobs <- domain.Job{Id: 1, Name: "unknown", Executor: "unknown"}
+	svc.jobs <- domain.Job{Id: 2, Name: "article_schedule", Executor: "article_schedule"}
+	exec := fakeExecutor{name: "article_schedule", exec: make(chan domain.Job, 1)}
+
+	s := NewScheduler(svc, logger.NewNopLogger())
+	s.interval = time.Millisecond
+	s.RegisterExecutor(exec)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Schedule(ctx)
+	}()
+
+	select {
+	case j := <-exec.exec:
+		assert.Equal(t, int64(2), j.Id)
+	case <-time.After(time.Second):
+		t.Fatal("没有执行任务")
+	}
+	select {
+	case j := <-svc.reset:
+		assert.Equal(t, int64(2), j.Id)
+	case <-time.After(time.Second):
+		t.Fatal("执行完没有设置下一次的时间")
+	}
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	// 找不到执行器的任务也要释放
+	assert.Eventually(t, func() bool {
+		return svc.released.Load() == 2
+	}, time.Second, time.Millisecond)
+	// 抢不到任务的时候不会占着并发数
+	assert.True(t, s.limiter.TryAcquire(200))
+}
+
+func TestArticleScheduleJob_Exec(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc := svcmocks.NewMockArticleService(ctrl)
+	svc.EXPECT().ExecuteSchedules(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, now time.Time) error {
+			_, ok := ctx.Deadline()
+			assert.True(t, ok)
+			return nil
+		})
+	j := NewArticleScheduleJob(svc, time.Second, logger.NewNopLogger())
+	assert.Equal(t, "article_schedule", j.Name())
+	assert.NoError(t, j.Exec(context.Background(), domain.Job{Name: "article_schedule"}))
+}
+
+func TestUploadCleanupJob_Exec(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc := svcmocks.NewMockUploadService(ctrl)
+	// 第一批删满了，继续删；第二批不够一批，结束
+	svc.EXPECT().CleanUnreferenced(gomock.Any(), gomock.Any(), 100).Return(100, nil)
+	svc.EXPECT().CleanUnreferenced(gomock.Any(), gomock.Any(), 100).Return(3, nil)
+	j := NewUploadCleanupJob(svc, time.Second, time.Hour, logger.NewNopLogger())
+	assert.Equal(t, "upload_cleanup", j.Name())
+	assert.NoError(t, j.Exec(context.Background(), domain.Job{Name: "upload_cleanup"}))
+}
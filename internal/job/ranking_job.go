@@ -69,7 +69,12 @@ func (r *RankingJob) Run() error {
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
 	defer cancel()
-	return r.svc.TopN(ctx)
+	err := r.svc.TopN(ctx)
+	if err != nil {
+		return err
+	}
+	// 全站的热榜更重要，先算全站的，再算标签的
+	return r.svc.TopNHotTags(ctx)
 }
 
 func (r *RankingJob) Close() error {
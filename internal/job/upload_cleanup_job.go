@@ -0,0 +1,52 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"webooktrial/internal/domain"
+	"webooktrial/internal/service"
+	"webooktrial/pkg/logger"
+)
+
+// UploadCleanupJob 删除没有被任何文章引用的图片。
+// 刚上传的图片可能还没来得及保存文章，所以只清理 grace 之前上传的。
+// 和 ArticleScheduleJob 一样注册到 Scheduler 上
+type UploadCleanupJob struct {
+	svc     service.UploadService
+	timeout time.Duration
+	grace   time.Duration
+	// batchSize 每次查出来这么多个，删完了再查下一批
+	batchSize int
+	l         logger.LoggerV1
+}
+
+func NewUploadCleanupJob(svc service.UploadService, timeout, grace time.Duration,
+	l logger.LoggerV1) *UploadCleanupJob {
+	return &UploadCleanupJob{svc: svc,
+		timeout:   timeout,
+		grace:     grace,
+		batchSize: 100,
+		l:         l,
+	}
+}
+
+func (u *UploadCleanupJob) Name() string {
+	return "upload_cleanup"
+}
+
+func (u *UploadCleanupJob) Exec(ctx context.Context, j domain.Job) error {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	before := time.Now().Add(-u.grace)
+	for {
+		cnt, err := u.svc.CleanUnreferenced(ctx, before, u.batchSize)
+		if err != nil {
+			return err
+		}
+		// 不够一批，说明已经清理完了
+		if cnt < u.batchSize {
+			return nil
+		}
+	}
+}
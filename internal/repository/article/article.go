@@ -2,20 +2,39 @@ package article
 
 import (
 	"context"
+	"errors"
+	"strconv"
 	"time"
 
 	"github.com/ecodeclub/ekit/slice"
-	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
 
 	"webooktrial/internal/domain"
 	"webooktrial/internal/repository"
+	cachex "webooktrial/internal/repository/cache"
+	"webooktrial/internal/repository/cache/local"
 	cache "webooktrial/internal/repository/cache/redis"
 	"webooktrial/pkg/logger"
+	"webooktrial/pkg/pagination"
 
 	dao "webooktrial/internal/repository/dao/article"
 )
 
+var ErrArticleNotFound = dao.ErrRecordNotFound
+
+// loadPubTimeout 缓存未命中的时候查数据库的超时时间。
+// 查询是所有等待的请求共享的，不能跟着第一个请求的 ctx 一起被取消
+const loadPubTimeout = time.Second * 3
+
+// RevisionRetention 历史版本的保留策略，两个条件都是只要配置了就生效
+type RevisionRetention struct {
+	// MaxCount 每篇文章最多保留多少个版本，0 表示不限制
+	MaxCount int `yaml:"maxCount"`
+	// MaxAge 最多保留多久，0 表示不限制
+	MaxAge time.Duration `yaml:"maxAge"`
+}
+
 //go:generate mockgen -source=./article.go -package=artrepomocks -destination=mocks/article.mock.go ArticleRepository
 
 type ArticleRepository interface {
@@ -23,28 +42,58 @@ type ArticleRepository interface {
 	Update(ctx context.Context, art domain.Article) error
 	// Sync 存储并同步数据
 	Sync(ctx context.Context, art domain.Article) (int64, error)
-	SyncStatus(ctx *gin.Context, id int64, author int64, status domain.ArticleStatus) error
+	SyncStatus(ctx context.Context, id int64, author int64, status domain.ArticleStatus) error
+	// List Deprecated: 深分页性能差，用 ListByCursor
 	List(ctx context.Context, uid int64, offset, limit int) ([]domain.Article, error)
+	// ListByCursor 作者的文章，按照更新时间倒序，cursor 的 Key 是更新时间的毫秒数
+	ListByCursor(ctx context.Context, uid int64, cursor pagination.Cursor, limit int) ([]domain.Article, error)
 	GetByID(ctx context.Context, id int64) (domain.Article, error)
 	GetPublishedById(ctx context.Context, id int64) (domain.Article, error)
+	// ListPub Deprecated: 深分页性能差，用 ListPubByCursor
 	ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]domain.Article, error)
+	// ListPubByCursor 已发表的文章，按照更新时间倒序，cursor 的 Key 是更新时间的毫秒数
+	ListPubByCursor(ctx context.Context, cursor pagination.Cursor, limit int) ([]domain.Article, error)
+
+	// ListDueScheduled 到了定时发表时间的文章，从制作库里面查询
+	ListDueScheduled(ctx context.Context, now time.Time, limit int) ([]domain.Article, error)
+	// ListDueUnpublish 到了定时撤回时间的文章，从线上库里面查询
+	ListDueUnpublish(ctx context.Context, now time.Time, limit int) ([]domain.Article, error)
+
+	ListRevisions(ctx context.Context, artId int64, offset, limit int) ([]domain.ArticleRevision, error)
+	GetRevision(ctx context.Context, artId int64, version int64) (domain.ArticleRevision, error)
+
+	// ListPubByTag 按照更新时间倒序，cursor 的 Key 是更新时间的毫秒数
+	ListPubByTag(ctx context.Context, tag string, cursor pagination.Cursor, limit int) ([]domain.Article, error)
+	// ListTags 按照已发表文章的数量倒序，cursor 的 Key 是文章数，Id 是标签 ID
+	ListTags(ctx context.Context, cursor pagination.Cursor, limit int) ([]domain.Tag, error)
+	// ListRelated 和这篇文章有共同标签的已发表文章
+	ListRelated(ctx context.Context, id int64, limit int) ([]domain.Article, error)
 
 	//FindById(ctx context.Context, id int64) domain.Article
 }
 
 func NewArticleRepository(dao dao.ArticleDao, l logger.LoggerV1,
+	tagDao dao.TagDAO,
 	cache cache.ArticleCache,
-	userRepo repository.UserRepository) ArticleRepository {
+	localCache *local.ArticleLocalCache,
+	invalidator cache.ArticleInvalidator,
+	userRepo repository.UserRepository,
+	retention RevisionRetention) ArticleRepository {
 	return &CachedArticleRepository{
-		dao:      dao,
-		l:        l,
-		cache:    cache,
-		userRepo: userRepo,
+		dao:         dao,
+		tagDao:      tagDao,
+		l:           l,
+		cache:       cache,
+		localCache:  localCache,
+		invalidator: invalidator,
+		userRepo:    userRepo,
+		retention:   retention,
 	}
 }
 
 type CachedArticleRepository struct {
 	dao      dao.ArticleDao
+	tagDao   dao.TagDAO
 	userRepo repository.UserRepository
 	// v1 操作两个 DAO
 	readerDao dao.ReaderDao
@@ -56,7 +105,13 @@ type CachedArticleRepository struct {
 	// 或者，直接去掉 DAO 这一层，在 repository 的实现中，直接操作 db
 	db    *gorm.DB
 	cache cache.ArticleCache
-	l     logger.LoggerV1
+	// 线上库的文章：本地缓存 -> Redis -> 数据库
+	localCache  *local.ArticleLocalCache
+	invalidator cache.ArticleInvalidator
+	// 同一篇文章缓存未命中的时候，只放一个请求去查数据库
+	sg        singleflight.Group
+	retention RevisionRetention
+	l         logger.LoggerV1
 }
 
 func (c *CachedArticleRepository) ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]domain.Article, error) {
@@ -69,6 +124,26 @@ func (c *CachedArticleRepository) ListPub(ctx context.Context, start time.Time,
 	}), nil
 }
 
+func (c *CachedArticleRepository) ListPubByCursor(ctx context.Context, cursor pagination.Cursor, limit int) ([]domain.Article, error) {
+	res, err := c.dao.ListPubByCursor(ctx, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(res, func(idx int, src dao.Article) domain.Article {
+		return c.toDomain(src)
+	}), nil
+}
+
+func (c *CachedArticleRepository) ListByCursor(ctx context.Context, uid int64, cursor pagination.Cursor, limit int) ([]domain.Article, error) {
+	res, err := c.dao.GetByAuthorByCursor(ctx, uid, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(res, func(idx int, src dao.Article) domain.Article {
+		return c.toDomain(src)
+	}), nil
+}
+
 func (c *CachedArticleRepository) List(ctx context.Context, uid int64, offset, limit int) ([]domain.Article, error) {
 	// 你在这个地方，集成你的复杂的缓存方案
 	// 你只缓存这一页
@@ -109,22 +184,81 @@ func (c *CachedArticleRepository) GetByID(ctx context.Context, id int64) (domain
 	if err != nil {
 		return domain.Article{}, err
 	}
-	return c.toDomain(data), nil
+	res := c.toDomain(data)
+	res.Tags, err = c.getTags(ctx, id)
+	return res, err
 }
 
 func (c *CachedArticleRepository) GetPublishedById(ctx context.Context, id int64) (domain.Article, error) {
-	// 读取线上库数据，如果你的 Content 被你放过去了 OSS 上，你就要让前端去读 Content 字段
+	res, err := c.localCache.GetPub(ctx, id)
+	switch {
+	case err == nil:
+		return res, nil
+	case errors.Is(err, cachex.ErrNullValue):
+		return domain.Article{}, ErrArticleNotFound
+	}
+	res, err = c.cache.GetPub(ctx, id)
+	switch {
+	case err == nil:
+		_ = c.localCache.SetPub(ctx, res)
+		return res, nil
+	case errors.Is(err, cachex.ErrNullValue):
+		_ = c.localCache.SetPubNull(ctx, id)
+		return domain.Article{}, ErrArticleNotFound
+	}
+	// Redis 出错了也继续查数据库，反正有 singleflight 兜着
+	ch := c.sg.DoChan(strconv.FormatInt(id, 10), func() (any, error) {
+		// 保留 ctx 里面的链路信息，但是不继承它的取消
+		lctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadPubTimeout)
+		defer cancel()
+		return c.loadPublished(lctx, id)
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return domain.Article{}, res.Err
+		}
+		return res.Val.(domain.Article), nil
+	case <-ctx.Done():
+		// 自己不等了，查询还会继续，结果留给别的请求
+		return domain.Article{}, ctx.Err()
+	}
+}
+
+// loadPublished 查询数据库并且回写两级缓存
+func (c *CachedArticleRepository) loadPublished(ctx context.Context, id int64) (domain.Article, error) {
+	// 读取线上库数据，Content 放在对象存储上的时候 DAO 会顺便取回来，
+	// 所以缓存里面的也是完整的文章，读者不需要自己去对象存储上读
 	art, err := c.dao.GetPubById(ctx, id)
+	if errors.Is(err, dao.ErrRecordNotFound) {
+		// 缓存空值，防止有人用不存在的 ID 一直打数据库
+		if er := c.cache.SetPubNull(ctx, id); er != nil {
+			c.l.Error("缓存文章空值失败", logger.Int64("id", id), logger.Error(er))
+		}
+		_ = c.localCache.SetPubNull(ctx, id)
+		return domain.Article{}, ErrArticleNotFound
+	}
 	if err != nil {
 		return domain.Article{}, err
 	}
 	// 你在这边要组装 user 了，适合单体应用
+	// 作者信息和标签有一个查询失败，就不要缓存
 	usr, err := c.userRepo.FindById(ctx, art.AuthorId)
+	if err != nil {
+		c.l.Error("查询文章作者失败", logger.Int64("id", id), logger.Error(err))
+	}
+	tags, er := c.getTags(ctx, id)
+	if er != nil {
+		c.l.Error("查询文章标签失败", logger.Int64("id", id), logger.Error(er))
+		err = er
+	}
 	res := domain.Article{
-		Id:      art.Id,
-		Title:   art.Title,
-		Status:  domain.ArticleStatus(art.Status),
-		Content: art.Content,
+		Id:       art.Id,
+		Title:    art.Title,
+		Status:   domain.ArticleStatus(art.Status),
+		Content:  art.Content,
+		Category: art.Category,
+		Tags:     tags,
 		Author: domain.Author{
 			Id:   usr.Id,
 			Name: usr.Nickname,
@@ -132,26 +266,115 @@ func (c *CachedArticleRepository) GetPublishedById(ctx context.Context, id int64
 		Ctime: time.UnixMilli(art.Ctime),
 		Utime: time.UnixMilli(art.Utime),
 	}
+	if err == nil {
+		if er := c.cache.SetPub(ctx, res); er != nil {
+			c.l.Error("回写文章缓存失败", logger.Int64("id", id), logger.Error(er))
+		}
+		_ = c.localCache.SetPub(ctx, res)
+	}
 	return res, nil
 }
 
-func (c *CachedArticleRepository) SyncStatus(ctx *gin.Context, id int64, author int64, status domain.ArticleStatus) error {
-	return c.dao.SyncStatus(ctx, id, author, uint8(status))
+func (c *CachedArticleRepository) SyncStatus(ctx context.Context, id int64, author int64, status domain.ArticleStatus) error {
+	err := c.dao.SyncStatus(ctx, author, id, uint8(status))
+	if err == nil {
+		c.invalidatePub(ctx, id, status.NonPublished())
+	}
+	return err
+}
+
+// invalidatePub 删除 Redis 缓存，并且通知所有实例删除本地缓存
+func (c *CachedArticleRepository) invalidatePub(ctx context.Context, id int64, withdrawn bool) {
+	_ = c.localCache.DelPub(ctx, id)
+	if err := c.cache.DelPub(ctx, id); err != nil {
+		c.l.Error("删除文章缓存失败", logger.Int64("id", id), logger.Error(err))
+	}
+	err := c.invalidator.Publish(ctx, cache.ArticleChange{Id: id, Withdrawn: withdrawn})
+	if err != nil {
+		// 其它实例的本地缓存只能等过期了
+		c.l.Error("广播文章缓存失效失败", logger.Int64("id", id), logger.Error(err))
+	}
 }
 
 func (c *CachedArticleRepository) Sync(ctx context.Context, art domain.Article) (int64, error) {
-	id, err := c.dao.(*dao.GormArticleDao).SyncClosure(ctx, c.toEntity(art))
+	// 不要断言具体的 DAO 实现，GORM、MongoDB 和 S3 的实现都要能用
+	id, err := c.dao.Sync(ctx, c.toEntity(art))
 	if err == nil {
 		c.cache.DelFirstPage(ctx, art.Author.Id)
-		er := c.cache.SetPub(ctx, art)
-		if er != nil {
-			// 不需要特别关心
-			// 比如说输出 WARN 日志
-		}
+		// 这里的 art 没有作者名字这些信息，所以直接删掉缓存，等读者来读的时候再加载
+		c.invalidatePub(ctx, id, false)
+		c.pruneRevisions(id)
 	}
 	return id, err
 }
 
+func (c *CachedArticleRepository) ListDueScheduled(ctx context.Context, now time.Time, limit int) ([]domain.Article, error) {
+	res, err := c.dao.ListDueScheduled(ctx, now.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	// 发表的时候要带上标签，发表事件里面要用
+	tags, err := c.tagDao.GetByArticles(ctx, slice.Map(res, func(idx int, src dao.Article) int64 {
+		return src.Id
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(res, func(idx int, src dao.Article) domain.Article {
+		art := c.toDomain(src)
+		art.Tags = tagNames(tags[src.Id])
+		return art
+	}), nil
+}
+
+func (c *CachedArticleRepository) ListDueUnpublish(ctx context.Context, now time.Time, limit int) ([]domain.Article, error) {
+	res, err := c.dao.ListDueUnpublish(ctx, now.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(res, func(idx int, src dao.PublishedArticle) domain.Article {
+		return c.toDomain(dao.Article(src))
+	}), nil
+}
+
+func (c *CachedArticleRepository) ListRevisions(ctx context.Context, artId int64, offset, limit int) ([]domain.ArticleRevision, error) {
+	res, err := c.dao.ListRevisions(ctx, artId, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(res, func(idx int, src dao.ArticleRevision) domain.ArticleRevision {
+		return c.revisionToDomain(src)
+	}), nil
+}
+
+func (c *CachedArticleRepository) GetRevision(ctx context.Context, artId int64, version int64) (domain.ArticleRevision, error) {
+	res, err := c.dao.GetRevision(ctx, artId, version)
+	if err != nil {
+		return domain.ArticleRevision{}, err
+	}
+	return c.revisionToDomain(res), nil
+}
+
+// pruneRevisions 按照保留策略清理历史版本
+// 清理失败了也不影响保存，下一次保存的时候还会再清理，所以异步执行
+func (c *CachedArticleRepository) pruneRevisions(artId int64) {
+	if c.retention.MaxCount <= 0 && c.retention.MaxAge <= 0 {
+		return
+	}
+	var before int64
+	if c.retention.MaxAge > 0 {
+		before = time.Now().Add(-c.retention.MaxAge).UnixMilli()
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		err := c.dao.PruneRevisions(ctx, artId, c.retention.MaxCount, before)
+		if err != nil {
+			c.l.Error("清理文章历史版本失败", logger.Int64("aid", artId), logger.Error(err))
+		}
+	}()
+}
+
 //func (c *CachedArticleRepository) SyncV2_1(ctx context.Context, art domain.Article) (int64, error) {
 //	// 谁在控制事务，是 repository，还是DAO在控制事务？
 //	c.dao.Transaction(ctx, func(txDAO dao.ArticleDao) error {
@@ -219,12 +442,8 @@ func (c *CachedArticleRepository) Create(ctx context.Context, art domain.Article
 		// 清空缓存
 		c.cache.DelFirstPage(ctx, art.Author.Id)
 	}()
-	return c.dao.Insert(ctx, dao.Article{
-		Title:    art.Title,
-		Content:  art.Content,
-		AuthorId: art.Author.Id,
-		Status:   uint8(art.Status),
-	})
+	// 新建的文章只有一个版本，不需要清理
+	return c.dao.Insert(ctx, c.toEntity(art))
 }
 
 func (c *CachedArticleRepository) Update(ctx context.Context, art domain.Article) error {
@@ -232,22 +451,80 @@ func (c *CachedArticleRepository) Update(ctx context.Context, art domain.Article
 		// 清空缓存
 		c.cache.DelFirstPage(ctx, art.Author.Id)
 	}()
-	return c.dao.UpdateById(ctx, dao.Article{
-		Id:       art.Id,
-		Title:    art.Title,
-		Content:  art.Content,
-		AuthorId: art.Author.Id,
-		Status:   uint8(art.Status),
+	err := c.dao.UpdateById(ctx, c.toEntity(art))
+	if err == nil {
+		c.pruneRevisions(art.Id)
+	}
+	return err
+}
+
+func (c *CachedArticleRepository) ListPubByTag(ctx context.Context, tag string, cursor pagination.Cursor, limit int) ([]domain.Article, error) {
+	res, err := c.tagDao.ListPubByTag(ctx, tag, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	return c.pubToDomainWithTags(ctx, res)
+}
+
+func (c *CachedArticleRepository) ListTags(ctx context.Context, cursor pagination.Cursor, limit int) ([]domain.Tag, error) {
+	res, err := c.tagDao.CountPubByTag(ctx, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(res, func(idx int, src dao.TagCount) domain.Tag {
+		return domain.Tag{Id: src.Id, Name: src.Name, ArticleCnt: src.Cnt}
+	}), nil
+}
+
+func (c *CachedArticleRepository) ListRelated(ctx context.Context, id int64, limit int) ([]domain.Article, error) {
+	res, err := c.tagDao.ListRelated(ctx, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	return c.pubToDomainWithTags(ctx, res)
+}
+
+// pubToDomainWithTags 批量查询标签，列表页也要展示标签
+func (c *CachedArticleRepository) pubToDomainWithTags(ctx context.Context, arts []dao.PublishedArticle) ([]domain.Article, error) {
+	ids := slice.Map(arts, func(idx int, src dao.PublishedArticle) int64 {
+		return src.Id
+	})
+	tags, err := c.tagDao.GetByArticles(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(arts, func(idx int, src dao.PublishedArticle) domain.Article {
+		res := c.toDomain(dao.Article(src))
+		res.Tags = tagNames(tags[src.Id])
+		return res
+	}), nil
+}
+
+func (c *CachedArticleRepository) getTags(ctx context.Context, artId int64) ([]string, error) {
+	tags, err := c.tagDao.GetByArticle(ctx, artId)
+	if err != nil {
+		return nil, err
+	}
+	return tagNames(tags), nil
+}
+
+func tagNames(tags []dao.Tag) []string {
+	return slice.Map(tags, func(idx int, src dao.Tag) string {
+		return src.Name
 	})
 }
 
 func (c *CachedArticleRepository) toEntity(art domain.Article) dao.Article {
 	return dao.Article{
-		Id:       art.Id,
-		Title:    art.Title,
-		Content:  art.Content,
-		AuthorId: art.Author.Id,
-		Status:   uint8(art.Status),
+		Id:          art.Id,
+		Title:       art.Title,
+		Content:     art.Content,
+		AuthorId:    art.Author.Id,
+		Status:      uint8(art.Status),
+		Category:    art.Category,
+		PublishAt:   toMilli(art.PublishAt),
+		UnpublishAt: toMilli(art.UnpublishAt),
+		Tags:        art.Tags,
 	}
 }
 
@@ -260,7 +537,40 @@ func (c *CachedArticleRepository) toDomain(art dao.Article) domain.Article {
 		Author: domain.Author{
 			Id: art.AuthorId,
 		},
-		Ctime: time.UnixMilli(art.Ctime),
-		Utime: time.UnixMilli(art.Utime),
+		Category:    art.Category,
+		Ctime:       time.UnixMilli(art.Ctime),
+		Utime:       time.UnixMilli(art.Utime),
+		PublishAt:   fromMilli(art.PublishAt),
+		UnpublishAt: fromMilli(art.UnpublishAt),
+	}
+}
+
+func (c *CachedArticleRepository) revisionToDomain(rev dao.ArticleRevision) domain.ArticleRevision {
+	return domain.ArticleRevision{
+		ArticleId: rev.ArticleId,
+		Version:   rev.Version,
+		Title:     rev.Title,
+		Content:   rev.Content,
+		Author: domain.Author{
+			Id: rev.AuthorId,
+		},
+		Status:    domain.ArticleStatus(rev.Status),
+		Published: rev.Published,
+		Ctime:     time.UnixMilli(rev.Ctime),
+	}
+}
+
+// toMilli 零值的时间表示没有设置，存成 0
+func toMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+func fromMilli(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
 	}
+	return time.UnixMilli(ms)
 }
@@ -0,0 +1,75 @@
+package article
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"webooktrial/internal/domain"
+	"webooktrial/internal/repository/cache/local"
+	cachemocks "webooktrial/internal/repository/cache/redis/mocks"
+	dao "webooktrial/internal/repository/dao/article"
+	daomocks "webooktrial/internal/repository/dao/article/mocks"
+	"webooktrial/pkg/logger"
+)
+
+func TestCachedArticleRepository_GetPublishedById_CallerCanceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	artDAO := daomocks.NewMockArticleDao(ctrl)
+	artCache := cachemocks.NewMockArticleCache(ctrl)
+
+	artCache.EXPECT().GetPub(gomock.Any(), int64(1)).
+		Return(domain.Article{}, errors.New("redis 超时"))
+	started := make(chan struct{})
+	release := make(chan struct{})
+	loadErr := make(chan error, 1)
+	artDAO.EXPECT().GetPubById(gomock.Any(), int64(1)).
+		DoAndReturn(func(ctx context.Context, id int64) (dao.PublishedArticle, error) {
+			close(started)
+			<-release
+			// 第一个请求取消了，查询还要继续，并且有自己的超时时间
+			_, ok := ctx.Deadline()
+			assert.True(t, ok)
+			loadErr <- ctx.Err()
+			return dao.PublishedArticle{}, dao.ErrRecordNotFound
+		})
+	nullCached := make(chan struct{})
+	artCache.EXPECT().SetPubNull(gomock.Any(), int64(1)).
+		DoAndReturn(func(ctx context.Context, id int64) error {
+			close(nullCached)
+			return nil
+		})
+
+	repo := NewArticleRepository(artDAO, logger.NewNopLogger(), nil, artCache,
+		local.NewArticleLocalCache(), nil, nil, RevisionRetention{})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := repo.GetPublishedById(ctx, 1)
+		done <- err
+	}()
+	<-started
+	cancel()
+	// 调用者不用等查询结束
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("ctx 取消之后没有返回")
+	}
+	close(release)
+	assert.NoError(t, <-loadErr)
+	select {
+	case <-nullCached:
+	case <-time.After(time.Second):
+		t.Fatal("没有缓存空值")
+	}
+	// 查询的结果留在了本地缓存里面
+	_, err := repo.GetPublishedById(context.Background(), 1)
+	assert.Equal(t, ErrArticleNotFound, err)
+}
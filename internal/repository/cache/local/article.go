@@ -0,0 +1,71 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/coocood/freecache"
+
+	"webooktrial/internal/domain"
+	"webooktrial/internal/repository/cache"
+)
+
+// ArticleLocalCache 线上库文章的本地缓存
+// freecache 满了之后会按照近似 LRU 的策略淘汰，而且没有 GC 的压力
+type ArticleLocalCache struct {
+	client *freecache.Cache
+	// 本地缓存依赖广播来失效，广播可能会丢，所以过期时间要短
+	expiration     time.Duration
+	nullExpiration time.Duration
+}
+
+func NewArticleLocalCache() *ArticleLocalCache {
+	return &ArticleLocalCache{
+		client:         freecache.NewCache(64 * 1024 * 1024),
+		expiration:     time.Minute,
+		nullExpiration: time.Second * 10,
+	}
+}
+
+// nullValue 空值，JSON 序列化之后的文章不可能是一个空的 []byte
+var nullValue = []byte{}
+
+func (a *ArticleLocalCache) GetPub(ctx context.Context, id int64) (domain.Article, error) {
+	data, err := a.client.Get(a.key(id))
+	if err != nil {
+		return domain.Article{}, err
+	}
+	if len(data) == 0 {
+		return domain.Article{}, cache.ErrNullValue
+	}
+	var res domain.Article
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
+
+func (a *ArticleLocalCache) SetPub(ctx context.Context, art domain.Article) error {
+	data, err := json.Marshal(art)
+	if err != nil {
+		return err
+	}
+	return a.client.Set(a.key(art.Id), data, a.seconds(a.expiration))
+}
+
+func (a *ArticleLocalCache) SetPubNull(ctx context.Context, id int64) error {
+	return a.client.Set(a.key(id), nullValue, a.seconds(a.nullExpiration))
+}
+
+func (a *ArticleLocalCache) DelPub(ctx context.Context, id int64) error {
+	a.client.Del(a.key(id))
+	return nil
+}
+
+func (a *ArticleLocalCache) seconds(exp time.Duration) int {
+	return int(cache.Jitter(exp) / time.Second)
+}
+
+func (a *ArticleLocalCache) key(id int64) []byte {
+	return []byte("article:reader:" + strconv.FormatInt(id, 10))
+}
@@ -0,0 +1,35 @@
+package local
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coocood/freecache"
+	"github.com/stretchr/testify/assert"
+
+	"webooktrial/internal/domain"
+	"webooktrial/internal/repository/cache"
+)
+
+func TestArticleLocalCache(t *testing.T) {
+	c := NewArticleLocalCache()
+	ctx := context.Background()
+
+	_, err := c.GetPub(ctx, 1)
+	assert.Equal(t, freecache.ErrNotFound, err)
+
+	art := domain.Article{Id: 1, Title: "标题", Author: domain.Author{Id: 2, Name: "作者"}}
+	assert.NoError(t, c.SetPub(ctx, art))
+	res, err := c.GetPub(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, art.Title, res.Title)
+	assert.Equal(t, art.Author, res.Author)
+
+	assert.NoError(t, c.SetPubNull(ctx, 3))
+	_, err = c.GetPub(ctx, 3)
+	assert.Equal(t, cache.ErrNullValue, err)
+
+	assert.NoError(t, c.DelPub(ctx, 1))
+	_, err = c.GetPub(ctx, 1)
+	assert.Equal(t, freecache.ErrNotFound, err)
+}
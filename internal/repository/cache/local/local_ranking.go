@@ -5,6 +5,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/ecodeclub/ekit/slice"
 	"github.com/ecodeclub/ekit/syncx/atomicx"
 
 	"webooktrial/internal/domain"
@@ -46,6 +47,18 @@ func (r *RankingLocalCache) Get(ctx context.Context) ([]domain.Article, error) {
 	return arts, nil
 }
 
+// Remove 只是去掉一篇文章，过期时间不变
+func (r *RankingLocalCache) Remove(ctx context.Context, aid int64) {
+	arts := r.topN.Load()
+	// 不能原地删除，别的 goroutine 可能正在读
+	res := slice.FilterMap(arts, func(idx int, src domain.Article) (domain.Article, bool) {
+		return src, src.Id != aid
+	})
+	if len(res) != len(arts) {
+		r.topN.Store(res)
+	}
+}
+
 func (r *RankingLocalCache) ForceGet(ctx context.Context) ([]domain.Article, error) {
 	arts := r.topN.Load()
 	return arts, nil
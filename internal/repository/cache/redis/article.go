@@ -9,6 +9,15 @@ import (
 	"github.com/redis/go-redis/v9"
 
 	"webooktrial/internal/domain"
+	"webooktrial/internal/repository/cache"
+)
+
+const (
+	// nullValue 数据库里面没有的文章，缓存这个值，防止缓存穿透
+	nullValue = "<null>"
+	// 空值不能缓存太久，不然文章发表之后读者要等很久才能看到
+	nullExpiration = time.Minute
+	pubExpiration  = time.Minute * 30
 )
 
 //go:generate mockgen -source=./article.go -package=cachemocks -destination=mocks/article.mock.go ArticleCache
@@ -22,7 +31,11 @@ type ArticleCache interface {
 
 	// SetPub 正常来说，创作者和读者的 Redis 集群要分开，因为读者是一个核心中的核心
 	SetPub(ctx context.Context, art domain.Article) error
+	// GetPub 如果缓存的是空值，返回 cache.ErrNullValue
 	GetPub(ctx context.Context, id int64) (domain.Article, error)
+	// SetPubNull 缓存空值
+	SetPubNull(ctx context.Context, id int64) error
+	DelPub(ctx context.Context, id int64) error
 }
 
 type RedisArticleCache struct {
@@ -87,8 +100,16 @@ func (r *RedisArticleCache) SetPub(ctx context.Context, art domain.Article) erro
 	}
 	return r.client.Set(ctx, r.readerArtKey(art.Id),
 		data,
-		// 设置长过期时间
-		time.Minute*30).Err()
+		// 设置长过期时间，加上随机值，避免一起过期
+		cache.Jitter(pubExpiration)).Err()
+}
+
+func (r *RedisArticleCache) SetPubNull(ctx context.Context, id int64) error {
+	return r.client.Set(ctx, r.readerArtKey(id), nullValue, cache.Jitter(nullExpiration)).Err()
+}
+
+func (r *RedisArticleCache) DelPub(ctx context.Context, id int64) error {
+	return r.client.Del(ctx, r.readerArtKey(id)).Err()
 }
 
 func (r *RedisArticleCache) GetPub(ctx context.Context, id int64) (domain.Article, error) {
@@ -97,6 +118,9 @@ func (r *RedisArticleCache) GetPub(ctx context.Context, id int64) (domain.Articl
 	if err != nil {
 		return domain.Article{}, err
 	}
+	if string(data) == nullValue {
+		return domain.Article{}, cache.ErrNullValue
+	}
 	var res domain.Article
 	err = json.Unmarshal(data, &res)
 	return res, err
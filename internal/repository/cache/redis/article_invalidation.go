@@ -0,0 +1,73 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const articleInvalidateChannel = "article:invalidate"
+
+// ArticleChange 文章变更的通知
+type ArticleChange struct {
+	Id int64 `json:"id"`
+	// Withdrawn 文章被撤回了，热榜之类的地方也要把它去掉
+	Withdrawn bool `json:"withdrawn"`
+}
+
+// ArticleInvalidator 文章变更之后，通知所有的实例清掉本地缓存
+// Redis 里面的缓存只有一份，删掉就可以，但是本地缓存每个实例都有一份
+type ArticleInvalidator interface {
+	Publish(ctx context.Context, change ArticleChange) error
+	// Subscribe 收到通知就回调 fn，一直阻塞到 ctx 被取消
+	Subscribe(ctx context.Context, fn func(change ArticleChange)) error
+}
+
+// RedisArticleInvalidator 基于 Redis 的 pub/sub 来广播。
+// pub/sub 不保证送达，所以本地缓存的过期时间要设置得比较短，兜底
+type RedisArticleInvalidator struct {
+	client redis.Cmdable
+}
+
+func NewRedisArticleInvalidator(client redis.Cmdable) ArticleInvalidator {
+	return &RedisArticleInvalidator{
+		client: client,
+	}
+}
+
+func (r *RedisArticleInvalidator) Publish(ctx context.Context, change ArticleChange) error {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	return r.client.Publish(ctx, articleInvalidateChannel, data).Err()
+}
+
+func (r *RedisArticleInvalidator) Subscribe(ctx context.Context, fn func(change ArticleChange)) error {
+	// Cmdable 里面没有 Subscribe，实际上传进来的都是 *redis.Client 或者集群的客户端
+	client, ok := r.client.(redis.UniversalClient)
+	if !ok {
+		return errors.New("redis 客户端不支持订阅")
+	}
+	sub := client.Subscribe(ctx, articleInvalidateChannel)
+	defer sub.Close()
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			var change ArticleChange
+			err := json.Unmarshal([]byte(msg.Payload), &change)
+			if err != nil {
+				continue
+			}
+			fn(change)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package redis
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"webooktrial/internal/domain"
+	"webooktrial/internal/repository/cache"
+	"webooktrial/pkg/pagination"
+)
+
+var (
+	//go:embed lua/history_add.lua
+	luaHistoryAdd string
+	//go:embed lua/history_loading.lua
+	luaHistoryLoading string
+	//go:embed lua/history_set.lua
+	luaHistorySet string
+)
+
+const (
+	// historyCap 每个用户最多缓存这么多条阅读记录，再往后翻就查数据库
+	historyCap = 1000
+	// historyTieSlack 阅读时间相同的记录在 Redis 里面的顺序和数据库不一样，多取一点在内存里面排
+	historyTieSlack   = 16
+	historyExpiration = time.Hour * 24 * 7
+	// historyMarker 每个缓存里面都有的一个成员，分数是负数表示还在加载，0 表示加载完了。
+	// 文章 ID 不会是 0，阅读时间也都是正数，所以不会和阅读记录混在一起
+	historyMarker = 0
+	// historyLoadingExpiration 加载失败的时候，占位的缓存过一会儿就自己消失了
+	historyLoadingExpiration = time.Minute
+)
+
+//go:generate mockgen -source=./history.go -package=cachemocks -destination=mocks/history.mock.go HistoryCache
+type HistoryCache interface {
+	// Add 只有这个用户的阅读记录已经在缓存里面了，或者正在加载，才会写入
+	Add(ctx context.Context, records []domain.HistoryRecord) error
+	// MarkLoading 重建缓存之前调用，之后 Add 就会写进缓存里面，
+	// 但是在 Set 之前 List 还是返回 redis.Nil
+	MarkLoading(ctx context.Context, uid int64) error
+	// Set 把数据库里面最近的阅读记录合并到缓存里面，records 为空也会缓存下来。
+	// MarkLoading 之后缓存被删除了就什么也不做
+	Set(ctx context.Context, uid int64, records []domain.HistoryRecord) error
+	// List 没有缓存或者还在加载的时候返回 redis.Nil。
+	// complete 为 false 说明缓存里面不是全部的数据，取不满一页的时候要去查数据库
+	List(ctx context.Context, uid int64, cursor pagination.Cursor, limit int) (res []domain.HistoryRecord, complete bool, err error)
+	Del(ctx context.Context, uid int64) error
+}
+
+type RedisHistoryCache struct {
+	client redis.Cmdable
+}
+
+func NewRedisHistoryCache(client redis.Cmdable) HistoryCache {
+	return &RedisHistoryCache{client: client}
+}
+
+func (r *RedisHistoryCache) Add(ctx context.Context, records []domain.HistoryRecord) error {
+	args := make(map[int64][]any, len(records))
+	for _, record := range records {
+		args[record.Uid] = append(args[record.Uid],
+			record.Rtime.UnixMilli(), record.Article.Id)
+	}
+	pipe := r.client.Pipeline()
+	for uid, arg := range args {
+		pipe.Eval(ctx, luaHistoryAdd, []string{r.key(uid)},
+			append([]any{historyCap, int(cache.Jitter(historyExpiration).Seconds())}, arg...)...)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *RedisHistoryCache) MarkLoading(ctx context.Context, uid int64) error {
+	return r.client.Eval(ctx, luaHistoryLoading, []string{r.key(uid)},
+		historyMarker, int(historyLoadingExpiration.Seconds())).Err()
+}
+
+func (r *RedisHistoryCache) Set(ctx context.Context, uid int64, records []domain.HistoryRecord) error {
+	if len(records) > historyCap {
+		records = records[:historyCap]
+	}
+	args := make([]any, 0, 3+len(records)*2)
+	args = append(args, historyMarker, historyCap, int(cache.Jitter(historyExpiration).Seconds()))
+	for _, record := range records {
+		args = append(args, record.Rtime.UnixMilli(), record.Article.Id)
+	}
+	return r.client.Eval(ctx, luaHistorySet, []string{r.key(uid)}, args...).Err()
+}
+
+func (r *RedisHistoryCache) List(ctx context.Context, uid int64,
+	cursor pagination.Cursor, limit int) ([]domain.HistoryRecord, bool, error) {
+	key := r.key(uid)
+	max := "+inf"
+	if !cursor.IsZero() {
+		// 包含相同阅读时间的记录，下面再按照文章 ID 过滤
+		max = strconv.FormatInt(cursor.Key, 10)
+	}
+	count := int64(limit + historyTieSlack)
+	pipe := r.client.Pipeline()
+	markerCmd := pipe.ZScore(ctx, key, strconv.Itoa(historyMarker))
+	cardCmd := pipe.ZCard(ctx, key)
+	rangeCmd := pipe.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+		// 不包括分数不是正数的标记
+		Min:   "(0",
+		Max:   max,
+		Count: count,
+	})
+	_, err := pipe.Exec(ctx)
+	if err != nil && err != redis.Nil {
+		return nil, false, err
+	}
+	// 没有标记说明没有缓存
+	state, err := markerCmd.Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if state < 0 {
+		return nil, false, redis.Nil
+	}
+	// 去掉标记
+	card := cardCmd.Val() - 1
+	zs := rangeCmd.Val()
+	res := make([]domain.HistoryRecord, 0, len(zs))
+	for _, z := range zs {
+		aid, er := strconv.ParseInt(fmt.Sprint(z.Member), 10, 64)
+		if er != nil {
+			continue
+		}
+		rtime := int64(z.Score)
+		if !cursor.IsZero() && rtime == cursor.Key && aid >= cursor.Id {
+			continue
+		}
+		res = append(res, domain.HistoryRecord{
+			Uid:     uid,
+			Article: domain.Article{Id: aid},
+			Rtime:   time.UnixMilli(rtime),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].Rtime.Equal(res[j].Rtime) {
+			return res[i].Rtime.After(res[j].Rtime)
+		}
+		return res[i].Article.Id > res[j].Article.Id
+	})
+	// 取满了窗口，说明后面可能还有阅读时间相同的记录，不能认为已经到底了
+	complete := card < historyCap && int64(len(zs)) < count
+	if len(res) > limit {
+		res = res[:limit]
+		complete = true
+	}
+	return res, complete, nil
+}
+
+func (r *RedisHistoryCache) Del(ctx context.Context, uid int64) error {
+	return r.client.Del(ctx, r.key(uid)).Err()
+}
+
+func (r *RedisHistoryCache) key(uid int64) string {
+	return fmt.Sprintf("history:article:%d", uid)
+}
@@ -0,0 +1,16 @@
+-- 只有缓存了这个用户的阅读记录才写进去
+-- 不然缓存里面只有新的几条，看起来却像是完整的
+local key = KEYS[1]
+local cap = tonumber(ARGV[1])
+local ttl = tonumber(ARGV[2])
+if redis.call("EXISTS", key) == 0 then
+    return 0
+end
+for i = 3, #ARGV, 2 do
+    -- GT 保证乱序的时候，早一点的阅读时间不会覆盖晚一点的
+    redis.call("ZADD", key, "GT", ARGV[i], ARGV[i + 1])
+end
+-- 第 0 位是加载状态的标记，不能删，只保留最近的 cap 条
+redis.call("ZREMRANGEBYRANK", key, 1, -(cap + 1))
+redis.call("EXPIRE", key, ttl)
+return 1
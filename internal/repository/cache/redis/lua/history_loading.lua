@@ -0,0 +1,11 @@
+-- 开始重建缓存之前先占个位置，重建期间 Add 写进来的记录就不会丢
+-- 分数是负数的标记表示还没加载完，List 当成没有缓存
+local key = KEYS[1]
+local marker = ARGV[1]
+local ttl = tonumber(ARGV[2])
+if redis.call("EXISTS", key) == 1 then
+    return 0
+end
+redis.call("ZADD", key, -1, marker)
+redis.call("EXPIRE", key, ttl)
+return 1
@@ -0,0 +1,19 @@
+-- 把数据库里面的阅读记录合并进缓存，而不是先删除再写入，
+-- 这样重建期间 Add 写进来的更新的记录不会被覆盖
+local key = KEYS[1]
+local marker = ARGV[1]
+local cap = tonumber(ARGV[2])
+local ttl = tonumber(ARGV[3])
+-- 重建期间被清空了，查出来的已经是旧数据
+if redis.call("EXISTS", key) == 0 then
+    return 0
+end
+for i = 4, #ARGV, 2 do
+    redis.call("ZADD", key, "GT", ARGV[i], ARGV[i + 1])
+end
+-- 标记成已经加载完了，没有阅读记录的时候也只剩下这个标记
+redis.call("ZADD", key, 0, marker)
+-- 标记的分数最小，排在第 0 位，只保留最近的 cap 条
+redis.call("ZREMRANGEBYRANK", key, 1, -(cap + 1))
+redis.call("EXPIRE", key, ttl)
+return 1
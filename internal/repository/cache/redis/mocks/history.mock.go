@@ -0,0 +1,113 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./history.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./history.go -package=cachemocks -destination=mocks/history.mock.go HistoryCache
+//
+// Package cachemocks is a generated GoMock package.
+package cachemocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "webooktrial/internal/domain"
+	pagination "webooktrial/pkg/pagination"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockHistoryCache is a mock of HistoryCache interface.
+type MockHistoryCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockHistoryCacheMockRecorder
+}
+
+// MockHistoryCacheMockRecorder is the mock recorder for MockHistoryCache.
+type MockHistoryCacheMockRecorder struct {
+	mock *MockHistoryCache
+}
+
+// NewMockHistoryCache creates a new mock instance.
+func NewMockHistoryCache(ctrl *gomock.Controller) *MockHistoryCache {
+	mock := &MockHistoryCache{ctrl: ctrl}
+	mock.recorder = &MockHistoryCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHistoryCache) EXPECT() *MockHistoryCacheMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockHistoryCache) Add(ctx context.Context, records []domain.HistoryRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, records)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockHistoryCacheMockRecorder) Add(ctx, records any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockHistoryCache)(nil).Add), ctx, records)
+}
+
+// Del mocks base method.
+func (m *MockHistoryCache) Del(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Del", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Del indicates an expected call of Del.
+func (mr *MockHistoryCacheMockRecorder) Del(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Del", reflect.TypeOf((*MockHistoryCache)(nil).Del), ctx, uid)
+}
+
+// List mocks base method.
+func (m *MockHistoryCache) List(ctx context.Context, uid int64, cursor pagination.Cursor, limit int) ([]domain.HistoryRecord, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, uid, cursor, limit)
+	ret0, _ := ret[0].([]domain.HistoryRecord)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List.
+func (mr *MockHistoryCacheMockRecorder) List(ctx, uid, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockHistoryCache)(nil).List), ctx, uid, cursor, limit)
+}
+
+// MarkLoading mocks base method.
+func (m *MockHistoryCache) MarkLoading(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkLoading", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkLoading indicates an expected call of MarkLoading.
+func (mr *MockHistoryCacheMockRecorder) MarkLoading(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkLoading", reflect.TypeOf((*MockHistoryCache)(nil).MarkLoading), ctx, uid)
+}
+
+// Set mocks base method.
+func (m *MockHistoryCache) Set(ctx context.Context, uid int64, records []domain.HistoryRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", ctx, uid, records)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockHistoryCacheMockRecorder) Set(ctx, uid, records any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockHistoryCache)(nil).Set), ctx, uid, records)
+}
@@ -3,8 +3,10 @@ package redis
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"time"
 
+	"github.com/ecodeclub/ekit/slice"
 	"github.com/redis/go-redis/v9"
 
 	"webooktrial/internal/domain"
@@ -45,3 +47,52 @@ func (r *RankingRedisCache) Get(ctx context.Context) ([]domain.Article, error) {
 	err = json.Unmarshal(data, &res)
 	return res, err
 }
+
+// Remove 从热榜里面去掉一篇文章，过期时间不变
+// 和重新计算热榜之间存在并发问题，不过下一次计算热榜的时候就会修正
+func (r *RankingRedisCache) Remove(ctx context.Context, aid int64) error {
+	arts, err := r.Get(ctx)
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	res := slice.FilterMap(arts, func(idx int, src domain.Article) (domain.Article, bool) {
+		return src, src.Id != aid
+	})
+	if len(res) == len(arts) {
+		return nil
+	}
+	val, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.key, val, redis.KeepTTL).Err()
+}
+
+// SetByTag 标签的热榜，只放在 Redis 里面
+func (r *RankingRedisCache) SetByTag(ctx context.Context, tag string, arts []domain.Article) error {
+	for i := 0; i < len(arts); i++ {
+		arts[i].Content = ""
+	}
+	val, err := json.Marshal(arts)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.tagKey(tag), val, time.Minute*10).Err()
+}
+
+func (r *RankingRedisCache) GetByTag(ctx context.Context, tag string) ([]domain.Article, error) {
+	data, err := r.client.Get(ctx, r.tagKey(tag)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var res []domain.Article
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
+
+func (r *RankingRedisCache) tagKey(tag string) string {
+	return fmt.Sprintf("%s:tag:%s", r.key, tag)
+}
@@ -2,10 +2,26 @@ package cache
 
 import (
 	"context"
+	"errors"
+	"math/rand"
+	"time"
 
 	"webooktrial/internal/domain"
 )
 
+// ErrNullValue 缓存里面存的是空值，也就是说数据库里面确实没有这条数据
+// 用来防止有人拿着不存在的 ID 一直打到数据库上
+var ErrNullValue = errors.New("缓存的是空值")
+
+// Jitter 在过期时间上加一个 [0, exp/10) 的随机值，
+// 避免同一批写进去的 key 在同一个时刻一起过期
+func Jitter(exp time.Duration) time.Duration {
+	if exp < 10 {
+		return exp
+	}
+	return exp + time.Duration(rand.Int63n(int64(exp/10)))
+}
+
 //go:generate mockgen -source=./code.go -package=cachemocks -destination=mocks/code.mock.go CodeCache
 type CodeCache interface {
 	Set(ctx context.Context, biz, phone, code string) error
@@ -7,15 +7,60 @@ type Article struct {
 	Title   string `gorm:"type=varchar(4096)" bson:"title,omitempty"`
 	Content string `gorm:"type=BLOB" bson:"content,omitempty"`
 	// 作者
-	AuthorId int64 `gorm:"index" bson:"author_id,omitempty"`
-	Status   uint8 `bson:"status,omitempty"`
-	Ctime    int64 `bson:"ctime,omitempty"`
-	Utime    int64 `bson:"utime,omitempty"`
+	// 作者的文章列表按照 utime 和 id 翻页
+	AuthorId int64 `gorm:"index:author_utime,priority:1" bson:"author_id,omitempty"`
+	// 线上库按照状态和 utime 翻页
+	Status uint8 `gorm:"index:status_utime,priority:1" bson:"status,omitempty"`
+	Ctime  int64 `bson:"ctime,omitempty"`
+	Utime  int64 `gorm:"index:author_utime,priority:2;index:status_utime,priority:2" bson:"utime,omitempty"`
+	// Category 分类，一篇文章只属于一个分类，标签放在 ArticleTag 里面
+	Category string `gorm:"type:varchar(64);index" bson:"category,omitempty"`
+	// PublishAt 定时发表的时间，毫秒数
+	// 定时任务会按照 status 和 publish_at 来找到期的文章
+	PublishAt int64 `gorm:"index" bson:"publish_at,omitempty"`
+	// UnpublishAt 定时撤回的时间，毫秒数，0 表示不撤回
+	UnpublishAt int64 `gorm:"index" bson:"unpublish_at,omitempty"`
+
+	// Tags 不是表里面的列，保存文章的时候在同一个事务里面更新 ArticleTag，为 nil 的时候不修改。
+	// 标签在 MySQL 里面，所以 MongoDB 的实现会忽略它
+	Tags []string `gorm:"-" bson:"-"`
 }
 
 // PublishedArticle 衍生类型，偷个懒
 type PublishedArticle Article
 
+// ArticleRevision 文章的历史版本，每次保存和发表都会写一条
+// 制作库和线上库都只保留了最新的数据，历史版本只能从这里找
+type ArticleRevision struct {
+	Id int64 `gorm:"primaryKey,autoIncrement" bson:"id,omitempty"`
+	// 同一篇文章的版本号从 1 开始递增
+	ArticleId int64  `gorm:"uniqueIndex:art_version" bson:"article_id,omitempty"`
+	Version   int64  `gorm:"uniqueIndex:art_version" bson:"version,omitempty"`
+	AuthorId  int64  `bson:"author_id,omitempty"`
+	Title     string `gorm:"type=varchar(4096)" bson:"title,omitempty"`
+	Content   string `gorm:"type=BLOB" bson:"content,omitempty"`
+	Status    uint8  `bson:"status,omitempty"`
+	// Published 这个版本是不是发表的时候产生的
+	Published bool  `bson:"published,omitempty"`
+	Ctime     int64 `bson:"ctime,omitempty"`
+}
+
+// Tag 标签，名字是唯一的，第一次有文章用到的时候创建
+type Tag struct {
+	Id    int64  `gorm:"primaryKey,autoIncrement"`
+	Name  string `gorm:"type:varchar(64);uniqueIndex"`
+	Ctime int64
+}
+
+// ArticleTag 文章和标签的多对多关系
+// 按照标签查文章是主要的查询，所以联合索引 tag_id 在前
+type ArticleTag struct {
+	Id        int64 `gorm:"primaryKey,autoIncrement"`
+	TagId     int64 `gorm:"uniqueIndex:tag_article"`
+	ArticleId int64 `gorm:"uniqueIndex:tag_article;index"`
+	Ctime     int64
+}
+
 // PublishedArticleV1 s3 演示专属
 
 type PublishedArticleV1 struct {
@@ -25,6 +70,12 @@ type PublishedArticleV1 struct {
 	Status   uint8  `bson:"status,omitempty"`
 	Ctime    int64  `bson:"ctime,omitempty"`
 	Utime    int64  `bson:"utime,omitempty"`
+	Category string `gorm:"type:varchar(64);index" bson:"category,omitempty"`
+	// UnpublishAt 定时撤回的时间，毫秒数，0 表示不撤回
+	UnpublishAt int64 `gorm:"index" bson:"unpublish_at,omitempty"`
+	// ContentKey 内容在对象存储上的 key，每次发表都会换一个新的。
+	// 老数据是空的，key 就是文章 ID
+	ContentKey string `gorm:"type:varchar(256)" bson:"content_key,omitempty"`
 }
 
 //func (u *Article) BeforeCreate(tx *gorm.DB) (err error) {
@@ -7,6 +7,9 @@ import (
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+
+	"webooktrial/internal/domain"
+	"webooktrial/pkg/pagination"
 )
 
 func NewGormArticleDao(db *gorm.DB) ArticleDao {
@@ -21,9 +24,46 @@ type GormArticleDao struct {
 
 func (g *GormArticleDao) ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]Article, error) {
 	var res []Article
+	// 撤回了的文章和定时撤回的文章都不应该出现在这里，不然热榜里面会有已经撤回的文章
+	err := g.db.WithContext(ctx).Model(&PublishedArticle{}).
+		Where("utime < ? AND status = ?", start.UnixMilli(), domain.ArticleStatusPublished.ToUint8()).
+		Order("utime DESC").Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (g *GormArticleDao) ListPubByCursor(ctx context.Context, cursor pagination.Cursor, limit int) ([]Article, error) {
+	var res []Article
+	err := afterCursor(g.db.WithContext(ctx).Model(&PublishedArticle{}).
+		Where("status = ?", domain.ArticleStatusPublished.ToUint8()), cursor).
+		Order("utime DESC, id DESC").Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+// afterCursor 取排在 cursor 后面的数据，排序是 utime DESC, id DESC
+func afterCursor(db *gorm.DB, cursor pagination.Cursor) *gorm.DB {
+	if cursor.IsZero() {
+		return db
+	}
+	return db.Where("(utime < ? OR (utime = ? AND id < ?))", cursor.Key, cursor.Key, cursor.Id)
+}
+
+func (g *GormArticleDao) ListDueScheduled(ctx context.Context, now int64, limit int) ([]Article, error) {
+	var res []Article
+	err := g.db.WithContext(ctx).
+		Where("status = ? AND publish_at <= ?", domain.ArticleStatusScheduled.ToUint8(), now).
+		Order("publish_at ASC").Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (g *GormArticleDao) ListDueUnpublish(ctx context.Context, now int64, limit int) ([]PublishedArticle, error) {
+	var res []PublishedArticle
 	err := g.db.WithContext(ctx).
-		Where("utime < ?", start.UnixMilli()).
-		Order("utime DESC").Offset(offset).Limit(limit).Error
+		Where("status = ? AND unpublish_at > 0 AND unpublish_at <= ?", domain.ArticleStatusPublished.ToUint8(), now).
+		Order("unpublish_at ASC").Limit(limit).
+		Find(&res).Error
 	return res, err
 }
 
@@ -47,7 +87,16 @@ func (g *GormArticleDao) GetByAuthor(ctx context.Context, author int64, offset,
 		//	{Column: clause.Column{Name: "utime"}, Desc: true},
 		//	{Column: clause.Column{Name: "ctime"}, Desc: false},
 		//}}).
-		Find(arts).Error
+		Find(&arts).Error
+	return arts, err
+}
+
+func (g *GormArticleDao) GetByAuthorByCursor(ctx context.Context, author int64, cursor pagination.Cursor, limit int) ([]Article, error) {
+	var arts []Article
+	err := afterCursor(g.db.WithContext(ctx).Model(&Article{}).
+		Where("author_id = ?", author), cursor).
+		Order("utime DESC, id DESC").Limit(limit).
+		Find(&arts).Error
 	return arts, err
 }
 
@@ -100,15 +149,14 @@ func (g *GormArticleDao) Sync(ctx context.Context, art Article) (int64, error) {
 	tx := g.db.WithContext(ctx).Begin()
 	now := time.Now().UnixMilli()
 	defer tx.Rollback()
-	txDao := NewGormArticleDao(tx)
 	var (
 		id  = art.Id
 		err error
 	)
 	if id == 0 {
-		id, err = txDao.Insert(ctx, art)
+		id, err = insertArticle(tx, art)
 	} else {
-		err = txDao.UpdateById(ctx, art)
+		err = updateArticle(tx, art)
 	}
 	if err != nil {
 		return 0, err
@@ -117,7 +165,7 @@ func (g *GormArticleDao) Sync(ctx context.Context, art Article) (int64, error) {
 	publishArt := PublishedArticle(art)
 	publishArt.Utime = now
 	publishArt.Ctime = now
-	err = g.db.Clauses(clause.OnConflict{
+	err = tx.Clauses(clause.OnConflict{
 		// SQL 2003 标准
 		// INSERT AAAA ON CONFLICT(BBB) DO NOTHING
 		// INSERT AAAA ON CONFLICT(BBB) DO UPDATES CCC WHERE DDD
@@ -132,12 +180,22 @@ func (g *GormArticleDao) Sync(ctx context.Context, art Article) (int64, error) {
 		// MySQL 只需要关心这里
 		Columns: []clause.Column{{Name: "id"}},
 		DoUpdates: clause.Assignments(map[string]interface{}{
-			"title":   art.Title,
-			"content": art.Content,
-			"status":  art.Status,
-			"utime":   now,
+			"title":        art.Title,
+			"content":      art.Content,
+			"status":       art.Status,
+			"category":     art.Category,
+			"unpublish_at": art.UnpublishAt,
+			"utime":        now,
 		}),
-	}).Create(&art).Error
+	}).Create(&publishArt).Error
+	if err != nil {
+		return 0, err
+	}
+	err = addRevision(tx, art, true)
+	if err != nil {
+		return 0, err
+	}
+	err = setArticleTags(tx, id, art.Tags)
 	if err != nil {
 		return 0, err
 	}
@@ -150,52 +208,143 @@ func (g *GormArticleDao) SyncClosure(ctx context.Context, art Article) (int64, e
 	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var err error
 		now := time.Now().UnixMilli()
-		txDao := NewGormArticleDao(tx)
 		if id == 0 {
-			id, err = txDao.Insert(ctx, art)
+			id, err = insertArticle(tx, art)
 		} else {
-			err = txDao.UpdateById(ctx, art)
+			err = updateArticle(tx, art)
 		}
 		if err != nil {
 			return err
 		}
 		art.Id = id
-		publishArt := art
+		publishArt := PublishedArticle(art)
 		publishArt.Utime = now
 		publishArt.Ctime = now
-		return tx.Clauses(clause.OnConflict{
+		err = tx.Clauses(clause.OnConflict{
 			Columns: []clause.Column{{Name: "id"}},
 			DoUpdates: clause.Assignments(map[string]interface{}{
-				"title":   art.Title,
-				"content": art.Content,
-				"status":  art.Status,
-				"utime":   now,
+				"title":        art.Title,
+				"content":      art.Content,
+				"status":       art.Status,
+				"category":     art.Category,
+				"unpublish_at": art.UnpublishAt,
+				"utime":        now,
 			}),
 		}).Create(&publishArt).Error
+		if err != nil {
+			return err
+		}
+		err = addRevision(tx, art, true)
+		if err != nil {
+			return err
+		}
+		return setArticleTags(tx, id, art.Tags)
 	})
 	return id, err
 }
 
 func (g *GormArticleDao) Insert(ctx context.Context, art Article) (int64, error) {
+	var id int64
+	// 文章、历史版本和标签要么一起成功，要么一起失败
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		id, err = insertArticle(tx, art)
+		if err != nil {
+			return err
+		}
+		art.Id = id
+		err = addRevision(tx, art, false)
+		if err != nil {
+			return err
+		}
+		return setArticleTags(tx, id, art.Tags)
+	})
+	return id, err
+}
+
+func (g *GormArticleDao) UpdateById(ctx context.Context, art Article) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := updateArticle(tx, art)
+		if err != nil {
+			return err
+		}
+		err = addRevision(tx, art, false)
+		if err != nil {
+			return err
+		}
+		return setArticleTags(tx, art.Id, art.Tags)
+	})
+}
+
+func (g *GormArticleDao) ListRevisions(ctx context.Context, artId int64, offset, limit int) ([]ArticleRevision, error) {
+	var res []ArticleRevision
+	err := g.db.WithContext(ctx).
+		Where("article_id = ?", artId).
+		Order("version DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (g *GormArticleDao) GetRevision(ctx context.Context, artId int64, version int64) (ArticleRevision, error) {
+	var res ArticleRevision
+	err := g.db.WithContext(ctx).
+		Where("article_id = ? AND version = ?", artId, version).
+		First(&res).Error
+	return res, err
+}
+
+func (g *GormArticleDao) PruneRevisions(ctx context.Context, artId int64, keep int, before int64) error {
+	db := g.db.WithContext(ctx)
+	if keep > 0 {
+		// 找到第 keep 新的版本，比它老的都删掉
+		var versions []int64
+		err := db.Model(&ArticleRevision{}).
+			Where("article_id = ?", artId).
+			Order("version DESC").
+			Offset(keep-1).Limit(1).
+			Pluck("version", &versions).Error
+		if err != nil {
+			return err
+		}
+		if len(versions) > 0 {
+			err = db.Where("article_id = ? AND version < ?", artId, versions[0]).
+				Delete(&ArticleRevision{}).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if before > 0 {
+		return db.Where("article_id = ? AND ctime < ?", artId, before).
+			Delete(&ArticleRevision{}).Error
+	}
+	return nil
+}
+
+func insertArticle(tx *gorm.DB, art Article) (int64, error) {
 	now := time.Now().UnixMilli()
 	art.Ctime = now
 	art.Utime = now
-	err := g.db.WithContext(ctx).Create(&art).Error
+	err := tx.Create(&art).Error
 	return art.Id, err
 }
 
-func (g *GormArticleDao) UpdateById(ctx context.Context, art Article) error {
+func updateArticle(tx *gorm.DB, art Article) error {
 	now := time.Now().UnixMilli()
 	art.Utime = now
 	// 依赖 gorm 忽略零值的特性，会用主键进行更新
 	// 可读性很差
-	res := g.db.WithContext(ctx).Model(&art).
+	res := tx.Model(&art).
 		Where("id=? AND author_id = ?", art.Id, art.AuthorId).
 		Updates(map[string]any{
-			"title":   art.Title,
-			"content": art.Content,
-			"status":  art.Status,
-			"utime":   art.Utime,
+			"title":        art.Title,
+			"content":      art.Content,
+			"status":       art.Status,
+			"category":     art.Category,
+			"publish_at":   art.PublishAt,
+			"unpublish_at": art.UnpublishAt,
+			"utime":        art.Utime,
 		})
 	// 你要不要检查真的更新了没？
 	// res.RowsAffected // 更新行数
@@ -210,3 +359,26 @@ func (g *GormArticleDao) UpdateById(ctx context.Context, art Article) error {
 	}
 	return nil
 }
+
+// addRevision 在同一个事务里面写入历史版本。
+// 版本号是 MAX(version) + 1，并发保存同一篇文章的时候，唯一索引会让其中一个失败
+func addRevision(tx *gorm.DB, art Article, published bool) error {
+	var version int64
+	err := tx.Model(&ArticleRevision{}).
+		Where("article_id = ?", art.Id).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&version).Error
+	if err != nil {
+		return err
+	}
+	return tx.Create(&ArticleRevision{
+		ArticleId: art.Id,
+		Version:   version + 1,
+		AuthorId:  art.AuthorId,
+		Title:     art.Title,
+		Content:   art.Content,
+		Status:    art.Status,
+		Published: published,
+		Ctime:     time.Now().UnixMilli(),
+	}).Error
+}
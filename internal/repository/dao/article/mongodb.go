@@ -9,6 +9,9 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"webooktrial/internal/domain"
+	"webooktrial/pkg/pagination"
 )
 
 type MongoDBDao struct {
@@ -19,7 +22,9 @@ type MongoDBDao struct {
 	col *mongo.Collection
 	// 代表的是线上库
 	liveCol *mongo.Collection
-	node    *snowflake.Node
+	// 历史版本
+	revisionCol *mongo.Collection
+	node        *snowflake.Node
 
 	idGen IDGenerator
 }
@@ -32,6 +37,16 @@ func (m *MongoDBDao) ListPub(ctx context.Context, start time.Time, offset int, l
 type IDGenerator func() int64
 
 func (m *MongoDBDao) Insert(ctx context.Context, art Article) (int64, error) {
+	id, err := m.insert(ctx, art)
+	if err != nil {
+		return 0, err
+	}
+	art.Id = id
+	// 没有事务，历史版本写失败了也不影响文章本身
+	return id, m.addRevision(ctx, art, false)
+}
+
+func (m *MongoDBDao) insert(ctx context.Context, art Article) (int64, error) {
 	now := time.Now().UnixMilli()
 	art.Ctime = now
 	art.Utime = now
@@ -46,14 +61,25 @@ func (m *MongoDBDao) Insert(ctx context.Context, art Article) (int64, error) {
 }
 
 func (m *MongoDBDao) UpdateById(ctx context.Context, art Article) error {
+	err := m.update(ctx, art)
+	if err != nil {
+		return err
+	}
+	return m.addRevision(ctx, art, false)
+}
+
+func (m *MongoDBDao) update(ctx context.Context, art Article) error {
 	// 操作制作库
 	filter := bson.M{"id": art.Id,
 		"author_id": art.AuthorId}
 	update := bson.D{bson.E{"$set", bson.M{
-		"title":   art.Title,
-		"content": art.Content,
-		"utime":   time.Now().UnixMilli(),
-		"status":  art.Status,
+		"title":        art.Title,
+		"content":      art.Content,
+		"utime":        time.Now().UnixMilli(),
+		"status":       art.Status,
+		"category":     art.Category,
+		"publish_at":   art.PublishAt,
+		"unpublish_at": art.UnpublishAt,
 	}}}
 	res, err := m.col.UpdateOne(ctx, filter, update)
 	if err != nil {
@@ -97,6 +123,10 @@ func (m *MongoDBDao) GetById(ctx context.Context, id int64) (Article, error) {
 func (m *MongoDBDao) GetPubById(ctx context.Context, id int64) (PublishedArticle, error) {
 	var pub PublishedArticle
 	err := m.liveCol.FindOne(ctx, bson.D{bson.E{Key: "id", Value: id}}).Decode(&pub)
+	if err == mongo.ErrNoDocuments {
+		// 和 GORM 的实现保持一致，上层才能统一处理
+		return pub, ErrRecordNotFound
+	}
 	return pub, err
 }
 
@@ -108,9 +138,9 @@ func (m *MongoDBDao) Sync(ctx context.Context, art Article) (int64, error) {
 		err error
 	)
 	if id > 0 {
-		err = m.UpdateById(ctx, art)
+		err = m.update(ctx, art)
 	} else {
-		id, err = m.Insert(ctx, art)
+		id, err = m.insert(ctx, art)
 	}
 	if err != nil {
 		return 0, err
@@ -127,12 +157,168 @@ func (m *MongoDBDao) Sync(ctx context.Context, art Article) (int64, error) {
 		// 在插入的时候，要插入 ctime
 		"$setOnInsert": bson.M{"ctime": now},
 	}
+	if art.UnpublishAt == 0 {
+		// omitempty 会忽略零值，所以要显式地删掉之前设置的定时撤回
+		updateV1["$unset"] = bson.M{"unpublish_at": ""}
+	}
 	filter := bson.M{"id": art.Id}
 	_, err = m.liveCol.UpdateOne(ctx, filter,
 		//bson.D{update, upsert},
 		updateV1,
 		options.Update().SetUpsert(true))
-	return id, err
+	if err != nil {
+		return 0, err
+	}
+	return id, m.addRevision(ctx, art, true)
+}
+
+func (m *MongoDBDao) ListDueScheduled(ctx context.Context, now int64, limit int) ([]Article, error) {
+	filter := bson.M{
+		"status":     domain.ArticleStatusScheduled.ToUint8(),
+		"publish_at": bson.M{"$lte": now},
+	}
+	opts := options.Find().
+		SetSort(bson.D{bson.E{Key: "publish_at", Value: 1}}).
+		SetLimit(int64(limit))
+	cur, err := m.col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var res []Article
+	err = cur.All(ctx, &res)
+	return res, err
+}
+
+func (m *MongoDBDao) GetByAuthorByCursor(ctx context.Context, author int64, cursor pagination.Cursor, limit int) ([]Article, error) {
+	return m.findByCursor(ctx, m.col, bson.M{"author_id": author}, cursor, limit)
+}
+
+func (m *MongoDBDao) ListPubByCursor(ctx context.Context, cursor pagination.Cursor, limit int) ([]Article, error) {
+	return m.findByCursor(ctx, m.liveCol,
+		bson.M{"status": domain.ArticleStatusPublished.ToUint8()}, cursor, limit)
+}
+
+// findByCursor 按照 utime 和 id 倒序，取排在 cursor 后面的数据
+func (m *MongoDBDao) findByCursor(ctx context.Context, col *mongo.Collection,
+	filter bson.M, cursor pagination.Cursor, limit int) ([]Article, error) {
+	if !cursor.IsZero() {
+		filter["$or"] = bson.A{
+			bson.M{"utime": bson.M{"$lt": cursor.Key}},
+			bson.M{"utime": cursor.Key, "id": bson.M{"$lt": cursor.Id}},
+		}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "utime", Value: -1}, {Key: "id", Value: -1}}).
+		SetLimit(int64(limit))
+	cur, err := col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var res []Article
+	err = cur.All(ctx, &res)
+	return res, err
+}
+
+func (m *MongoDBDao) ListDueUnpublish(ctx context.Context, now int64, limit int) ([]PublishedArticle, error) {
+	filter := bson.M{
+		"status":       domain.ArticleStatusPublished.ToUint8(),
+		"unpublish_at": bson.M{"$gt": 0, "$lte": now},
+	}
+	opts := options.Find().
+		SetSort(bson.D{bson.E{Key: "unpublish_at", Value: 1}}).
+		SetLimit(int64(limit))
+	cur, err := m.liveCol.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var res []PublishedArticle
+	err = cur.All(ctx, &res)
+	return res, err
+}
+
+func (m *MongoDBDao) ListRevisions(ctx context.Context, artId int64, offset, limit int) ([]ArticleRevision, error) {
+	opts := options.Find().
+		SetSort(bson.D{bson.E{Key: "version", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	cur, err := m.revisionCol.Find(ctx, bson.M{"article_id": artId}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var res []ArticleRevision
+	err = cur.All(ctx, &res)
+	return res, err
+}
+
+func (m *MongoDBDao) GetRevision(ctx context.Context, artId int64, version int64) (ArticleRevision, error) {
+	var res ArticleRevision
+	err := m.revisionCol.FindOne(ctx, bson.M{"article_id": artId, "version": version}).Decode(&res)
+	if err == mongo.ErrNoDocuments {
+		return res, ErrRecordNotFound
+	}
+	return res, err
+}
+
+func (m *MongoDBDao) PruneRevisions(ctx context.Context, artId int64, keep int, before int64) error {
+	if keep > 0 {
+		// 找到第 keep 新的版本，比它老的都删掉
+		var rev ArticleRevision
+		err := m.revisionCol.FindOne(ctx, bson.M{"article_id": artId},
+			options.FindOne().
+				SetSort(bson.D{bson.E{Key: "version", Value: -1}}).
+				SetSkip(int64(keep-1))).Decode(&rev)
+		switch {
+		case err == nil:
+			_, err = m.revisionCol.DeleteMany(ctx, bson.M{"article_id": artId,
+				"version": bson.M{"$lt": rev.Version}})
+			if err != nil {
+				return err
+			}
+		case err != mongo.ErrNoDocuments:
+			return err
+		}
+	}
+	if before > 0 {
+		_, err := m.revisionCol.DeleteMany(ctx, bson.M{"article_id": artId,
+			"ctime": bson.M{"$lt": before}})
+		return err
+	}
+	return nil
+}
+
+// addRevision 版本号是当前最大的版本号 + 1，
+// 依赖 (article_id, version) 的唯一索引来避免并发写入同一个版本
+func (m *MongoDBDao) addRevision(ctx context.Context, art Article, published bool) error {
+	var latest ArticleRevision
+	err := m.revisionCol.FindOne(ctx, bson.M{"article_id": art.Id},
+		options.FindOne().SetSort(bson.D{bson.E{Key: "version", Value: -1}})).
+		Decode(&latest)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+	_, err = m.revisionCol.InsertOne(ctx, ArticleRevision{
+		Id:        m.nextId(),
+		ArticleId: art.Id,
+		Version:   latest.Version + 1,
+		AuthorId:  art.AuthorId,
+		Title:     art.Title,
+		Content:   art.Content,
+		Status:    art.Status,
+		Published: published,
+		Ctime:     time.Now().UnixMilli(),
+	})
+	return err
+}
+
+func (m *MongoDBDao) nextId() int64 {
+	if m.idGen != nil {
+		return m.idGen()
+	}
+	return m.node.Generate().Int64()
 }
 
 func (m *MongoDBDao) SyncStatus(ctx context.Context, author, id int64, status uint8) error {
@@ -145,7 +331,8 @@ func (m *MongoDBDao) SyncStatus(ctx context.Context, author, id int64, status ui
 	update := bson.D{bson.E{"$set", bson.M{
 		"status": status,
 	}}}
-	sess.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+	defer sess.EndSession(ctx)
+	_, err = sess.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
 		res, err := m.col.UpdateOne(sessCtx, filter, update)
 		if err != nil {
 			return nil, err
@@ -162,13 +349,14 @@ func (m *MongoDBDao) SyncStatus(ctx context.Context, author, id int64, status ui
 		}
 		return nil, err
 	})
-	return nil
+	return err
 }
 
 func NewMongoDBDAOV1(db *mongo.Database, idGen IDGenerator) ArticleDao {
 	return &MongoDBDao{
-		col:     db.Collection("articles"),
-		liveCol: db.Collection("published_articles"),
+		col:         db.Collection("articles"),
+		liveCol:     db.Collection("published_articles"),
+		revisionCol: db.Collection("article_revisions"),
 		//node:    node,
 		idGen: idGen,
 	}
@@ -196,14 +384,27 @@ func InitCollections(db *mongo.Database) error {
 	}
 	_, err = db.Collection("published_articles").Indexes().
 		CreateMany(ctx, index)
+	if err != nil {
+		return err
+	}
+	_, err = db.Collection("article_revisions").Indexes().
+		CreateMany(ctx, []mongo.IndexModel{
+			{
+				Keys: bson.D{bson.E{Key: "article_id", Value: 1},
+					bson.E{Key: "version", Value: 1},
+				},
+				Options: options.Index().SetUnique(true),
+			},
+		})
 	return err
 }
 
 func NewMongoDBDAO(db *mongo.Database, node *snowflake.Node) ArticleDao {
 	return &MongoDBDao{
-		client:  db.Client(),
-		col:     db.Collection("articles"),
-		liveCol: db.Collection("published_articles"),
-		node:    node,
+		client:      db.Client(),
+		col:         db.Collection("articles"),
+		liveCol:     db.Collection("published_articles"),
+		revisionCol: db.Collection("article_revisions"),
+		node:        node,
 	}
 }
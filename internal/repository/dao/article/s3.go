@@ -1,69 +1,100 @@
 package article
 
 import (
-	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"strconv"
 	"time"
 
-	_ "github.com/aws/aws-sdk-go"
-	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/ecodeclub/ekit"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 
 	"webooktrial/internal/domain"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/objectstore"
+	"webooktrial/pkg/pagination"
 )
 
 var statusPrivate = domain.ArticleStatusPrivate.ToUint8()
 
 type S3DAO struct {
-	oss *s3.S3
+	// store 线上库的内容放在这里，S3 或者本地文件都可以
+	store objectstore.ObjectStore
 	// 通过组合 GORMArticleDAO 来简化操作
 	// 当然在实践中，你是不太会有组合的机会
 	// 你操作制作库总是一样的
 	// 你就是操作线上库的时候不一样
 	GormArticleDao
-	bucket *string
+	l logger.LoggerV1
 }
 
 // NewOssDAO 因为组合 GORMArticleDAO 是一个内部实现细节
 // 所以这里要直接传入 DB
-func NewOssDAO(oss *s3.S3, db *gorm.DB) ArticleDao {
+func NewOssDAO(store objectstore.ObjectStore, db *gorm.DB, l logger.LoggerV1) ArticleDao {
 	return &S3DAO{
-		oss: oss,
-		// 你也可以考虑利用依赖注入来传入。
-		// 但是事实上这个很少变，所以你可以延迟到必要的时候再注入
-		bucket: ekit.ToPtr[string]("webook-1314583317"),
+		store: store,
 		GormArticleDao: GormArticleDao{
 			db: db,
 		},
+		l: l,
 	}
 }
 
+// newContentKey 每次发表都用新的 key，这样上传不需要放在事务里面，
+// 事务失败了删掉新的，事务成功了删掉旧的，读者不会读到不一致的内容
+func newContentKey(author int64) string {
+	var buf [8]byte
+	_, _ = rand.Read(buf[:])
+	return fmt.Sprintf("articles/%d/%d-%s", author, time.Now().UnixMilli(), hex.EncodeToString(buf[:]))
+}
+
+func (p PublishedArticleV1) contentKey() string {
+	if p.ContentKey == "" {
+		return strconv.FormatInt(p.Id, 10)
+	}
+	return p.ContentKey
+}
+
 func (o *S3DAO) Sync(ctx context.Context, art Article) (int64, error) {
-	// 保存制作库
-	// 保存线上库，并且把 content 上传到 OSS
-	//
+	// 先上传内容，再保存制作库和线上库
+	// 上传很慢，不能放在事务里面
+	key := newContentKey(art.AuthorId)
+	err := o.store.Put(ctx, key, []byte(art.Content), "text/plain;charset=utf-8")
+	if err != nil {
+		return 0, err
+	}
 	var (
-		id = art.Id
+		id     = art.Id
+		oldKey string
 	)
 	// 制作库流量不大，并发不高，你就保存到数据库就可以
 	// 当然，有钱或者体量大，就还是考虑 OSS
-	err := o.db.Transaction(func(tx *gorm.DB) error {
+	err = o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var err error
 		now := time.Now().UnixMilli()
 		// 制作库
-		txDao := NewGormArticleDao(tx)
 		if id == 0 {
-			id, err = txDao.Insert(ctx, art)
+			id, err = insertArticle(tx, art)
 		} else {
-			err = txDao.UpdateById(ctx, art)
+			err = updateArticle(tx, art)
 		}
 		if err != nil {
 			return err
 		}
 		art.Id = id
+		// 锁住线上库的这一行，拿到旧的内容的 key，并发发表的时候不会漏删
+		var old PublishedArticleV1
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).First(&old).Error
+		switch {
+		case err == nil:
+			oldKey = old.contentKey()
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
 		publishArt := PublishedArticleV1{
 			Id:       art.Id,
 			Title:    art.Title,
@@ -71,34 +102,153 @@ func (o *S3DAO) Sync(ctx context.Context, art Article) (int64, error) {
 			Status:   art.Status,
 			Ctime:    now,
 			Utime:    now,
+			Category: art.Category,
+			// 定时撤回要查线上库
+			UnpublishAt: art.UnpublishAt,
+			ContentKey:  key,
 		}
-		// 线上库不保存 Content,要准备上传到 OSS 里面
-		return tx.Clauses(clause.OnConflict{
+		// 线上库不保存 Content，只保存它在对象存储上的 key
+		err = tx.Clauses(clause.OnConflict{
 			// ID 冲突的时候。实际上，在 MYSQL 里面你写不写都可以
 			Columns: []clause.Column{{Name: "id"}},
 			DoUpdates: clause.Assignments(map[string]interface{}{
-				"title":  art.Title,
-				"utime":  now,
-				"status": art.Status,
+				"title":        art.Title,
+				"utime":        now,
+				"status":       art.Status,
+				"category":     art.Category,
+				"unpublish_at": art.UnpublishAt,
+				"content_key":  key,
 				// 要参与 SQL 运算的
 			}),
 		}).Create(&publishArt).Error
+		if err != nil {
+			return err
+		}
+		// 历史版本和制作库一样，内容直接存在数据库里面
+		err = addRevision(tx, art, true)
+		if err != nil {
+			return err
+		}
+		return setArticleTags(tx, id, art.Tags)
 	})
-	// 说明保存到数据库的时候失败了
 	if err != nil {
+		// 数据库失败了，刚刚上传的内容没有人引用，删掉
+		o.deleteContent(key)
 		return 0, err
 	}
-	// 接下来就是保存到 OSS 里面
-	// 你要有监控，你要有重试，你要有补偿机制
-	_, err = o.oss.PutObjectWithContext(ctx, &s3.PutObjectInput{
-		Bucket:      o.bucket,
-		Key:         ekit.ToPtr[string](strconv.FormatInt(art.Id, 10)),
-		Body:        bytes.NewReader([]byte(art.Content)),
-		ContentType: ekit.ToPtr[string]("text/plain;charset=utf-8"),
-	})
-	return id, err
+	if oldKey != "" {
+		o.deleteContent(oldKey)
+	}
+	return id, nil
+}
+
+// deleteContent 删除失败只会留下没有引用的对象，不影响正确性，所以只记录日志
+func (o *S3DAO) deleteContent(key string) {
+	// 调用方的 ctx 可能已经过期了
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	err := o.store.Delete(ctx, key)
+	if err != nil {
+		o.l.Error("删除对象存储上的文章内容失败",
+			logger.String("key", key),
+			logger.Error(err))
+	}
+}
+
+// GetPubById 线上库的内容在对象存储上，这里顺便取回来，上层拿到的就是完整的文章
+func (o *S3DAO) GetPubById(ctx context.Context, id int64) (PublishedArticle, error) {
+	art, err := o.getPub(ctx, id)
+	if errors.Is(err, objectstore.ErrObjectNotFound) {
+		// 查询线上库之后，作者刚好重新发表了，旧的内容被删掉了，再查一次就可以
+		art, err = o.getPub(ctx, id)
+	}
+	return art, err
+}
+
+func (o *S3DAO) getPub(ctx context.Context, id int64) (PublishedArticle, error) {
+	var pub PublishedArticleV1
+	err := o.db.WithContext(ctx).Where("id = ?", id).First(&pub).Error
+	if err != nil {
+		return PublishedArticle{}, err
+	}
+	data, err := o.store.Get(ctx, pub.contentKey())
+	if err != nil {
+		return PublishedArticle{}, err
+	}
+	art := fromPublishedV1(pub)
+	art.Content = string(data)
+	return PublishedArticle(art), nil
 }
 
 func (o *S3DAO) SyncStatus(ctx context.Context, author, id int64, status uint8) error {
-	panic("implement me")
+	// OSS 上的内容不需要动，读者那边是根据线上库的状态来决定能不能看的
+	return o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&Article{}).
+			Where("id = ? AND author_id = ?", id, author).
+			Update("status", status)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != 1 {
+			return ErrPossibleIncorrectAuthor
+		}
+		res = tx.Model(&PublishedArticleV1{}).
+			Where("id = ? AND author_id = ?", id, author).
+			Update("status", status)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != 1 {
+			return ErrPossibleIncorrectAuthor
+		}
+		return nil
+	})
+}
+
+func (o *S3DAO) ListDueUnpublish(ctx context.Context, now int64, limit int) ([]PublishedArticle, error) {
+	var res []PublishedArticleV1
+	err := o.db.WithContext(ctx).
+		Where("status = ? AND unpublish_at > 0 AND unpublish_at <= ?",
+			domain.ArticleStatusPublished.ToUint8(), now).
+		Order("unpublish_at ASC").Limit(limit).
+		Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	// 只用到了 id 和 author_id，内容在 OSS 上，不需要
+	arts := make([]PublishedArticle, 0, len(res))
+	for _, art := range res {
+		arts = append(arts, PublishedArticle(fromPublishedV1(art)))
+	}
+	return arts, nil
+}
+
+// ListPubByCursor 线上库在 PublishedArticleV1 里面，列表不需要内容
+func (o *S3DAO) ListPubByCursor(ctx context.Context, cursor pagination.Cursor, limit int) ([]Article, error) {
+	var res []PublishedArticleV1
+	err := afterCursor(o.db.WithContext(ctx).
+		Where("status = ?", domain.ArticleStatusPublished.ToUint8()), cursor).
+		Order("utime DESC, id DESC").Limit(limit).
+		Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	arts := make([]Article, 0, len(res))
+	for _, art := range res {
+		arts = append(arts, fromPublishedV1(art))
+	}
+	return arts, nil
+}
+
+func fromPublishedV1(art PublishedArticleV1) Article {
+	return Article{
+		Id:          art.Id,
+		Title:       art.Title,
+		AuthorId:    art.AuthorId,
+		Status:      art.Status,
+		Ctime:       art.Ctime,
+		Utime:       art.Utime,
+		Category:    art.Category,
+		UnpublishAt: art.UnpublishAt,
+	}
 }
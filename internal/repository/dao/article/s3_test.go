@@ -1 +1,215 @@
 package article
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/objectstore"
+)
+
+// memoryStore 测试用的对象存储
+type memoryStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (m *memoryStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = data
+	return nil
+}
+
+func (m *memoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, objectstore.ErrObjectNotFound
+	}
+	return data, nil
+}
+
+func (m *memoryStore) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *memoryStore) Presign(ctx context.Context, key string, expire time.Duration) (string, error) {
+	return "http://localhost/" + key, nil
+}
+
+func newS3TestDAO(t *testing.T) (*S3DAO, sqlmock.Sqlmock, *memoryStore) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	store := &memoryStore{objects: map[string][]byte{}}
+	return NewOssDAO(store, db, logger.NewNopLogger()).(*S3DAO), mock, store
+}
+
+func anyArgs(n int) []driver.Value {
+	res := make([]driver.Value, n)
+	for i := range res {
+		res[i] = sqlmock.AnyArg()
+	}
+	return res
+}
+
+func pubV1Rows(id int64, contentKey string) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "title", "author_id", "status", "content_key"}).
+		AddRow(id, "标题", 123, 2, contentKey)
+}
+
+func TestS3DAO_GetPubById(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mock        func(mock sqlmock.Sqlmock)
+		objects     map[string]string
+		wantContent string
+		wantErr     error
+	}{
+		{
+			name: "从对象存储取回内容",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `published_article_v1`.*").WithArgs(anyArgs(1)...).
+					WillReturnRows(pubV1Rows(1, "articles/123/a"))
+			},
+			objects:     map[string]string{"articles/123/a": "内容"},
+			wantContent: "内容",
+		},
+		{
+			name: "老数据用文章 ID 作为 key",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `published_article_v1`.*").WithArgs(anyArgs(1)...).
+					WillReturnRows(pubV1Rows(1, ""))
+			},
+			objects:     map[string]string{"1": "老内容"},
+			wantContent: "老内容",
+		},
+		{
+			name: "内容刚好被替换了，重新查一次",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `published_article_v1`.*").WithArgs(anyArgs(1)...).
+					WillReturnRows(pubV1Rows(1, "articles/123/old"))
+				mock.ExpectQuery("SELECT \\* FROM `published_article_v1`.*").WithArgs(anyArgs(1)...).
+					WillReturnRows(pubV1Rows(1, "articles/123/new"))
+			},
+			objects:     map[string]string{"articles/123/new": "新内容"},
+			wantContent: "新内容",
+		},
+		{
+			name: "线上库没有",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `published_article_v1`.*").WithArgs(anyArgs(1)...).
+					WillReturnError(gorm.ErrRecordNotFound)
+			},
+			wantErr: ErrRecordNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, mock, store := newS3TestDAO(t)
+			tc.mock(mock)
+			for k, v := range tc.objects {
+				require.NoError(t, store.Put(context.Background(), k, []byte(v), ""))
+			}
+			art, err := d.GetPubById(context.Background(), 1)
+			assert.True(t, errors.Is(err, tc.wantErr), err)
+			if err == nil {
+				assert.Equal(t, tc.wantContent, art.Content)
+				assert.Equal(t, int64(123), art.AuthorId)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestS3DAO_Sync(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(mock sqlmock.Sqlmock)
+		art     Article
+		objects map[string]string
+		wantId  int64
+		wantErr bool
+		// 剩下的对象
+		wantObjects int
+	}{
+		{
+			name: "更新，删除旧的内容",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` .*").WithArgs(anyArgs(10)...).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT \\* FROM `published_article_v1` .* FOR UPDATE").WithArgs(anyArgs(1)...).
+					WillReturnRows(pubV1Rows(1, "articles/123/old"))
+				mock.ExpectExec("INSERT INTO `published_article_v1` .*").WithArgs(anyArgs(15)...).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("SELECT .* FROM `article_revisions`.*").WithArgs(anyArgs(1)...).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+				mock.ExpectExec("INSERT INTO `article_revisions` .*").WithArgs(anyArgs(8)...).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			art:         Article{Id: 1, AuthorId: 123, Title: "标题", Content: "新内容"},
+			objects:     map[string]string{"articles/123/old": "旧内容"},
+			wantId:      1,
+			wantObjects: 1,
+		},
+		{
+			name: "数据库失败，删除刚刚上传的内容",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` .*").WithArgs(anyArgs(10)...).
+					WillReturnError(errors.New("mock db error"))
+				mock.ExpectRollback()
+			},
+			art:         Article{Id: 1, AuthorId: 123, Title: "标题", Content: "新内容"},
+			objects:     map[string]string{"articles/123/old": "旧内容"},
+			wantErr:     true,
+			wantObjects: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, mock, store := newS3TestDAO(t)
+			tc.mock(mock)
+			for k, v := range tc.objects {
+				require.NoError(t, store.Put(context.Background(), k, []byte(v), ""))
+			}
+			id, err := d.Sync(context.Background(), tc.art)
+			assert.Equal(t, tc.wantErr, err != nil, err)
+			assert.Equal(t, tc.wantId, id)
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tc.wantObjects, len(store.objects))
+			if !tc.wantErr {
+				_, err = store.Get(context.Background(), "articles/123/old")
+				assert.Equal(t, objectstore.ErrObjectNotFound, err)
+			} else {
+				data, err := store.Get(context.Background(), "articles/123/old")
+				require.NoError(t, err)
+				assert.Equal(t, "旧内容", string(data))
+			}
+		})
+	}
+}
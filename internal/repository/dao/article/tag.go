@@ -0,0 +1,181 @@
+package article
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"webooktrial/internal/domain"
+	"webooktrial/pkg/pagination"
+)
+
+// TagDAO 标签相关的查询。
+// 标签要和线上库做关联查询，所以只有 GORM 的实现。
+// 修改文章的标签要和保存文章在同一个事务里面，所以放在 ArticleDao 里面，见 Article.Tags
+type TagDAO interface {
+	GetByArticle(ctx context.Context, artId int64) ([]Tag, error)
+	// GetByArticles 批量查询，key 是文章 ID
+	GetByArticles(ctx context.Context, artIds []int64) (map[int64][]Tag, error)
+	// ListPubByTag 按照 utime 和 id 倒序，cursor 的 Key 是 utime，零值表示第一页
+	ListPubByTag(ctx context.Context, tag string, cursor pagination.Cursor, limit int) ([]PublishedArticle, error)
+	// CountPubByTag 每个标签下面已经发表的文章数，按照数量倒序、标签 ID 正序。
+	// cursor 的 Key 是文章数，Id 是标签 ID，零值表示第一页
+	CountPubByTag(ctx context.Context, cursor pagination.Cursor, limit int) ([]TagCount, error)
+	// ListRelated 和 artId 有共同标签的已发表文章，共同标签越多越靠前
+	ListRelated(ctx context.Context, artId int64, limit int) ([]PublishedArticle, error)
+}
+
+type TagCount struct {
+	Id   int64
+	Name string
+	Cnt  int64
+}
+
+type GORMTagDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMTagDAO(db *gorm.DB) TagDAO {
+	return &GORMTagDAO{db: db}
+}
+
+// setArticleTags 把文章的标签设置成 names，多余的删掉，缺少的补上。
+// names 为 nil 的时候不修改，tx 是保存文章的事务
+func setArticleTags(tx *gorm.DB, artId int64, names []string) error {
+	if names == nil {
+		return nil
+	}
+	now := time.Now().UnixMilli()
+	var tagIds []int64
+	if len(names) > 0 {
+		tags := slice.Map(names, func(idx int, src string) Tag {
+			return Tag{Name: src, Ctime: now}
+		})
+		// 标签已经存在就什么也不做，后面再按照名字查 ID
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&Tag{}).Where("name IN ?", names).Pluck("id", &tagIds).Error
+		if err != nil {
+			return err
+		}
+	}
+	del := tx.Where("article_id = ?", artId)
+	if len(tagIds) > 0 {
+		del = del.Where("tag_id NOT IN ?", tagIds)
+	}
+	err := del.Delete(&ArticleTag{}).Error
+	if err != nil || len(tagIds) == 0 {
+		return err
+	}
+	rels := slice.Map(tagIds, func(idx int, src int64) ArticleTag {
+		return ArticleTag{TagId: src, ArticleId: artId, Ctime: now}
+	})
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rels).Error
+}
+
+func (g *GORMTagDAO) GetByArticle(ctx context.Context, artId int64) ([]Tag, error) {
+	var res []Tag
+	err := g.db.WithContext(ctx).Model(&Tag{}).
+		Joins("JOIN article_tags ON article_tags.tag_id = tags.id").
+		Where("article_tags.article_id = ?", artId).
+		Order("tags.id ASC").
+		Find(&res).Error
+	return res, err
+}
+
+func (g *GORMTagDAO) GetByArticles(ctx context.Context, artIds []int64) (map[int64][]Tag, error) {
+	if len(artIds) == 0 {
+		return map[int64][]Tag{}, nil
+	}
+	type row struct {
+		ArticleId int64
+		Id        int64
+		Name      string
+	}
+	var rows []row
+	err := g.db.WithContext(ctx).Table("article_tags").
+		Select("article_tags.article_id, tags.id, tags.name").
+		Joins("JOIN tags ON tags.id = article_tags.tag_id").
+		Where("article_tags.article_id IN ?", artIds).
+		Order("tags.id ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64][]Tag, len(artIds))
+	for _, r := range rows {
+		res[r.ArticleId] = append(res[r.ArticleId], Tag{Id: r.Id, Name: r.Name})
+	}
+	return res, nil
+}
+
+func (g *GORMTagDAO) ListPubByTag(ctx context.Context, tag string, cursor pagination.Cursor, limit int) ([]PublishedArticle, error) {
+	var res []PublishedArticle
+	db := g.db.WithContext(ctx).Model(&PublishedArticle{}).
+		Select("published_articles.*").
+		Joins("JOIN article_tags ON article_tags.article_id = published_articles.id").
+		Joins("JOIN tags ON tags.id = article_tags.tag_id").
+		Where("tags.name = ? AND published_articles.status = ?", tag, domain.ArticleStatusPublished.ToUint8())
+	if !cursor.IsZero() {
+		// 关联了别的表，不能用 afterCursor，要带上表名
+		db = db.Where("published_articles.utime < ? OR (published_articles.utime = ? AND published_articles.id < ?)",
+			cursor.Key, cursor.Key, cursor.Id)
+	}
+	err := db.Order("published_articles.utime DESC, published_articles.id DESC").Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (g *GORMTagDAO) CountPubByTag(ctx context.Context, cursor pagination.Cursor, limit int) ([]TagCount, error) {
+	var res []TagCount
+	db := g.db.WithContext(ctx).Table("article_tags").
+		Select("tags.id AS id, tags.name AS name, COUNT(*) AS cnt").
+		Joins("JOIN tags ON tags.id = article_tags.tag_id").
+		Joins("JOIN published_articles ON published_articles.id = article_tags.article_id").
+		Where("published_articles.status = ?", domain.ArticleStatusPublished.ToUint8()).
+		Group("tags.id, tags.name")
+	if !cursor.IsZero() {
+		// 文章数是聚合出来的，只能放在 HAVING 里面
+		db = db.Having("COUNT(*) < ? OR (COUNT(*) = ? AND tags.id > ?)",
+			cursor.Key, cursor.Key, cursor.Id)
+	}
+	err := db.Order("cnt DESC, tags.id ASC").Limit(limit).Scan(&res).Error
+	return res, err
+}
+
+func (g *GORMTagDAO) ListRelated(ctx context.Context, artId int64, limit int) ([]PublishedArticle, error) {
+	db := g.db.WithContext(ctx)
+	var ids []int64
+	err := db.Table("article_tags AS a").
+		Joins("JOIN article_tags AS b ON b.tag_id = a.tag_id AND b.article_id <> a.article_id").
+		Joins("JOIN published_articles AS p ON p.id = b.article_id").
+		Where("a.article_id = ? AND p.status = ?", artId, domain.ArticleStatusPublished.ToUint8()).
+		Group("b.article_id").
+		Order("COUNT(*) DESC, b.article_id DESC").
+		Limit(limit).
+		Pluck("b.article_id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	var arts []PublishedArticle
+	err = db.Where("id IN ?", ids).Find(&arts).Error
+	if err != nil {
+		return nil, err
+	}
+	// IN 查询不保证顺序，按照共同标签的数量重新排一下
+	idx := make(map[int64]PublishedArticle, len(arts))
+	for _, art := range arts {
+		idx[art.Id] = art
+	}
+	res := make([]PublishedArticle, 0, len(arts))
+	for _, id := range ids {
+		if art, ok := idx[id]; ok {
+			res = append(res, art)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,231 @@
+package article
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"webooktrial/pkg/pagination"
+)
+
+func newTagTestDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	return db, mock
+}
+
+func TestGormArticleDao_UpdateById_Tags(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(mock sqlmock.Sqlmock)
+		art     Article
+		wantErr bool
+	}{
+		{
+			name: "标签和文章在同一个事务里面",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` .*").WithArgs(anyArgs(10)...).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT .* FROM `article_revisions`.*").WithArgs(anyArgs(1)...).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+				mock.ExpectExec("INSERT INTO `article_revisions` .*").WithArgs(anyArgs(8)...).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO `tags` .*").WithArgs(anyArgs(4)...).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("SELECT `id` FROM `tags` WHERE name IN \\(\\?,\\?\\)").
+					WithArgs("go", "mysql").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+				mock.ExpectExec("DELETE FROM `article_tags` WHERE article_id = \\? AND tag_id NOT IN \\(\\?,\\?\\)").
+					WithArgs(int64(1), int64(1), int64(2)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `article_tags` .*").WithArgs(anyArgs(6)...).
+					WillReturnResult(sqlmock.NewResult(1, 2))
+				mock.ExpectCommit()
+			},
+			art: Article{Id: 1, AuthorId: 123, Title: "标题", Tags: []string{"go", "mysql"}},
+		},
+		{
+			name: "标签保存失败，文章也回滚",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` .*").WithArgs(anyArgs(10)...).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT .* FROM `article_revisions`.*").WithArgs(anyArgs(1)...).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+				mock.ExpectExec("INSERT INTO `article_revisions` .*").WithArgs(anyArgs(8)...).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("DELETE FROM `article_tags` WHERE article_id = \\?").
+					WithArgs(int64(1)).
+					WillReturnError(errors.New("mock db error"))
+				mock.ExpectRollback()
+			},
+			// 清空标签
+			art:     Article{Id: 1, AuthorId: 123, Title: "标题", Tags: []string{}},
+			wantErr: true,
+		},
+		{
+			name: "Tags 为 nil 不修改标签",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` .*").WithArgs(anyArgs(10)...).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT .* FROM `article_revisions`.*").WithArgs(anyArgs(1)...).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+				mock.ExpectExec("INSERT INTO `article_revisions` .*").WithArgs(anyArgs(8)...).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			art: Article{Id: 1, AuthorId: 123, Title: "标题"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock := newTagTestDB(t)
+			tc.mock(mock)
+			err := NewGormArticleDao(db).UpdateById(context.Background(), tc.art)
+			assert.Equal(t, tc.wantErr, err != nil, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGORMTagDAO(t *testing.T) {
+	pubCols := []string{"id", "title", "author_id", "status"}
+	testCases := []struct {
+		name string
+		mock func(mock sqlmock.Sqlmock)
+		call func(t *testing.T, d TagDAO)
+	}{
+		{
+			name: "GetByArticle",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT `tags`.`id`,`tags`.`name`,`tags`.`ctime` FROM `tags` " +
+					"JOIN article_tags ON article_tags.tag_id = tags.id WHERE article_tags.article_id = \\? " +
+					"ORDER BY tags.id ASC").
+					WithArgs(int64(1)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "go").AddRow(2, "mysql"))
+			},
+			call: func(t *testing.T, d TagDAO) {
+				tags, err := d.GetByArticle(context.Background(), 1)
+				require.NoError(t, err)
+				assert.Equal(t, []Tag{{Id: 1, Name: "go"}, {Id: 2, Name: "mysql"}}, tags)
+			},
+		},
+		{
+			name: "GetByArticles 按照文章分组",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT article_tags.article_id, tags.id, tags.name FROM `article_tags` .* "+
+					"WHERE article_tags.article_id IN \\(\\?,\\?\\)").
+					WithArgs(int64(1), int64(2)).
+					WillReturnRows(sqlmock.NewRows([]string{"article_id", "id", "name"}).
+						AddRow(1, 1, "go").AddRow(2, 1, "go").AddRow(1, 2, "mysql"))
+			},
+			call: func(t *testing.T, d TagDAO) {
+				res, err := d.GetByArticles(context.Background(), []int64{1, 2})
+				require.NoError(t, err)
+				assert.Equal(t, map[int64][]Tag{
+					1: {{Id: 1, Name: "go"}, {Id: 2, Name: "mysql"}},
+					2: {{Id: 1, Name: "go"}},
+				}, res)
+			},
+		},
+		{
+			name: "GetByArticles 没有文章不查数据库",
+			mock: func(mock sqlmock.Sqlmock) {},
+			call: func(t *testing.T, d TagDAO) {
+				res, err := d.GetByArticles(context.Background(), nil)
+				require.NoError(t, err)
+				assert.Empty(t, res)
+			},
+		},
+		{
+			name: "ListPubByTag 从游标后面开始",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT published_articles.\\* FROM `published_articles` .* "+
+					"WHERE \\(tags.name = \\? AND published_articles.status = \\?\\) "+
+					"AND \\(published_articles.utime < \\? OR \\(published_articles.utime = \\? AND published_articles.id < \\?\\)\\) "+
+					"ORDER BY published_articles.utime DESC, published_articles.id DESC LIMIT 2").
+					WithArgs("go", uint8(2), int64(100), int64(100), int64(10)).
+					WillReturnRows(sqlmock.NewRows(pubCols).AddRow(9, "a", 1, 2).AddRow(7, "b", 1, 2))
+			},
+			call: func(t *testing.T, d TagDAO) {
+				arts, err := d.ListPubByTag(context.Background(), "go", pagination.Cursor{Key: 100, Id: 10}, 2)
+				require.NoError(t, err)
+				require.Len(t, arts, 2)
+				assert.Equal(t, int64(7), arts[1].Id)
+			},
+		},
+		{
+			name: "CountPubByTag 第一页",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT tags.id AS id, tags.name AS name, COUNT\\(\\*\\) AS cnt FROM `article_tags` .* " +
+					"GROUP BY tags.id, tags.name ORDER BY cnt DESC, tags.id ASC LIMIT 2").
+					WithArgs(uint8(2)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "cnt"}).AddRow(1, "go", 10).AddRow(3, "k8s", 8))
+			},
+			call: func(t *testing.T, d TagDAO) {
+				res, err := d.CountPubByTag(context.Background(), pagination.Cursor{}, 2)
+				require.NoError(t, err)
+				assert.Equal(t, []TagCount{{Id: 1, Name: "go", Cnt: 10}, {Id: 3, Name: "k8s", Cnt: 8}}, res)
+			},
+		},
+		{
+			name: "CountPubByTag 从游标后面开始",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("GROUP BY tags.id, tags.name "+
+					"HAVING COUNT\\(\\*\\) < \\? OR \\(COUNT\\(\\*\\) = \\? AND tags.id > \\?\\) "+
+					"ORDER BY cnt DESC, tags.id ASC LIMIT 2").
+					WithArgs(uint8(2), int64(8), int64(8), int64(3)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "cnt"}).AddRow(4, "mysql", 8))
+			},
+			call: func(t *testing.T, d TagDAO) {
+				res, err := d.CountPubByTag(context.Background(), pagination.Cursor{Key: 8, Id: 3}, 2)
+				require.NoError(t, err)
+				assert.Equal(t, []TagCount{{Id: 4, Name: "mysql", Cnt: 8}}, res)
+			},
+		},
+		{
+			name: "ListRelated 按照共同标签的数量排序",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT `b`.`article_id` FROM article_tags AS a .* "+
+					"GROUP BY `b`.`article_id` ORDER BY COUNT\\(\\*\\) DESC, b.article_id DESC LIMIT 3").
+					WithArgs(int64(1), uint8(2)).
+					WillReturnRows(sqlmock.NewRows([]string{"article_id"}).AddRow(5).AddRow(2))
+				// IN 查出来的顺序和上面的不一样
+				mock.ExpectQuery("SELECT \\* FROM `published_articles` WHERE id IN \\(\\?,\\?\\)").
+					WithArgs(int64(5), int64(2)).
+					WillReturnRows(sqlmock.NewRows(pubCols).AddRow(2, "a", 1, 2).AddRow(5, "b", 1, 2))
+			},
+			call: func(t *testing.T, d TagDAO) {
+				arts, err := d.ListRelated(context.Background(), 1, 3)
+				require.NoError(t, err)
+				require.Len(t, arts, 2)
+				assert.Equal(t, int64(5), arts[0].Id)
+				assert.Equal(t, int64(2), arts[1].Id)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock := newTagTestDB(t)
+			tc.mock(mock)
+			tc.call(t, NewGORMTagDAO(db))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
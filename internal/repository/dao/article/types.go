@@ -4,17 +4,43 @@ import (
 	"context"
 	"errors"
 	"time"
+
+	"gorm.io/gorm"
+
+	"webooktrial/pkg/pagination"
 )
 
-var ErrPossibleIncorrectAuthor = errors.New("用户在尝试操作非本人数据")
+var (
+	ErrPossibleIncorrectAuthor = errors.New("用户在尝试操作非本人数据")
+	ErrRecordNotFound          = gorm.ErrRecordNotFound
+)
 
+//go:generate mockgen -source=./types.go -package=daomocks -destination=mocks/types.mock.go ArticleDao
 type ArticleDao interface {
 	Insert(ctx context.Context, art Article) (int64, error)
 	UpdateById(ctx context.Context, art Article) error
+	// GetByAuthor Deprecated: 深分页性能差，用 GetByAuthorByCursor
 	GetByAuthor(ctx context.Context, author int64, offset, limit int) ([]Article, error)
+	// GetByAuthorByCursor 按照 utime 和 id 倒序，cursor 的 Key 是 utime，零值表示第一页
+	GetByAuthorByCursor(ctx context.Context, author int64, cursor pagination.Cursor, limit int) ([]Article, error)
 	GetById(ctx context.Context, id int64) (Article, error)
 	GetPubById(ctx context.Context, id int64) (PublishedArticle, error)
 	Sync(ctx context.Context, art Article) (int64, error)
 	SyncStatus(ctx context.Context, author, id int64, status uint8) error
+	// ListPub Deprecated: 深分页性能差，用 ListPubByCursor
 	ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]Article, error)
+	// ListPubByCursor 已发表的文章，按照 utime 和 id 倒序，cursor 的 Key 是 utime，零值表示第一页
+	ListPubByCursor(ctx context.Context, cursor pagination.Cursor, limit int) ([]Article, error)
+
+	// ListDueScheduled 定时发表的时间已经到了，但是还没有发表的文章
+	ListDueScheduled(ctx context.Context, now int64, limit int) ([]Article, error)
+	// ListDueUnpublish 定时撤回的时间已经到了，但是还处于发表状态的文章
+	ListDueUnpublish(ctx context.Context, now int64, limit int) ([]PublishedArticle, error)
+
+	// ListRevisions 按照版本号倒序
+	ListRevisions(ctx context.Context, artId int64, offset, limit int) ([]ArticleRevision, error)
+	GetRevision(ctx context.Context, artId int64, version int64) (ArticleRevision, error)
+	// PruneRevisions 只保留最新的 keep 个版本，并且删除 ctime 早于 before 的版本
+	// keep 和 before 小于等于 0 的时候，对应的条件不生效
+	PruneRevisions(ctx context.Context, artId int64, keep int, before int64) error
 }
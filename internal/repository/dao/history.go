@@ -0,0 +1,67 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"webooktrial/pkg/pagination"
+)
+
+//go:generate mockgen -source=./history.go -package=daomocks -destination=mocks/history.mock.go HistoryRecordDAO
+type HistoryRecordDAO interface {
+	// Upsert 同一个人同一篇文章只保留一条，阅读时间取最晚的
+	Upsert(ctx context.Context, records []HistoryRecord) error
+	// ListByCursor 按照阅读时间和文章 ID 倒序，cursor 的 Key 是阅读时间，零值表示第一页
+	ListByCursor(ctx context.Context, uid int64, cursor pagination.Cursor, limit int) ([]HistoryRecord, error)
+	DeleteByUid(ctx context.Context, uid int64) error
+}
+
+// HistoryRecord 阅读记录
+// 按照 uid 查询，按照阅读时间排序，所以 <uid, rtime> 上有索引
+type HistoryRecord struct {
+	Id  int64 `gorm:"primaryKey,autoIncrement"`
+	Uid int64 `gorm:"uniqueIndex:uid_aid;index:uid_rtime,priority:1"`
+	Aid int64 `gorm:"uniqueIndex:uid_aid"`
+	// Rtime 最后一次阅读的时间，毫秒数
+	Rtime int64 `gorm:"index:uid_rtime,priority:2"`
+	Ctime int64
+	Utime int64
+}
+
+type GORMHistoryRecordDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMHistoryRecordDAO(db *gorm.DB) HistoryRecordDAO {
+	return &GORMHistoryRecordDAO{db: db}
+}
+
+func (g *GORMHistoryRecordDAO) Upsert(ctx context.Context, records []HistoryRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	return g.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			// 消息可能乱序，不能让早一点的阅读覆盖掉晚一点的
+			"rtime": gorm.Expr("GREATEST(rtime, VALUES(rtime))"),
+			"utime": gorm.Expr("VALUES(utime)"),
+		}),
+	}).Create(&records).Error
+}
+
+func (g *GORMHistoryRecordDAO) ListByCursor(ctx context.Context, uid int64,
+	cursor pagination.Cursor, limit int) ([]HistoryRecord, error) {
+	db := g.db.WithContext(ctx).Where("uid = ?", uid)
+	if !cursor.IsZero() {
+		db = db.Where("(rtime < ? OR (rtime = ? AND aid < ?))", cursor.Key, cursor.Key, cursor.Id)
+	}
+	var res []HistoryRecord
+	err := db.Order("rtime DESC, aid DESC").Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (g *GORMHistoryRecordDAO) DeleteByUid(ctx context.Context, uid int64) error {
+	return g.db.WithContext(ctx).Where("uid = ?", uid).Delete(&HistoryRecord{}).Error
+}
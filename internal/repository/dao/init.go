@@ -12,5 +12,12 @@ func InitTable(db *gorm.DB) error {
 		&article.Article{},
 		&SMSMsg{},
 		&article.PublishedArticle{},
-		&Job{})
+		&article.ArticleRevision{},
+		&article.Tag{},
+		&article.ArticleTag{},
+		&Job{},
+		&HistoryRecord{},
+		&Upload{},
+		&UploadRef{},
+		&UploadQuota{})
 }
@@ -5,8 +5,12 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrNoMoreJob 没有到时间的任务
+var ErrNoMoreJob = gorm.ErrRecordNotFound
+
 const (
 	jobStatusWaiting = iota
 	// 已经被抢占
@@ -18,6 +22,8 @@ const (
 )
 
 type JobDAO interface {
+	// Insert 已经有同名的任务就什么都不做
+	Insert(ctx context.Context, j Job) error
 	Preempt(ctx context.Context) (Job, error)
 	UpdateUtime(ctx context.Context, id int64) error
 	Release(ctx context.Context, id int64) error
@@ -29,6 +35,10 @@ type GormJobDAO struct {
 	db *gorm.DB
 }
 
+func NewGormJobDAO(db *gorm.DB) JobDAO {
+	return &GormJobDAO{db: db}
+}
+
 type Job struct {
 	Id       int64 `gorm:"primaryKey,autoIncrement"`
 	Cfg      string
@@ -57,6 +67,17 @@ type Job struct {
 	Utime int64
 }
 
+func (g *GormJobDAO) Insert(ctx context.Context, j Job) error {
+	now := time.Now().UnixMilli()
+	j.Status = jobStatusWaiting
+	j.Ctime = now
+	j.Utime = now
+	return g.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoNothing: true,
+	}).Create(&j).Error
+}
+
 func (g *GormJobDAO) Preempt(ctx context.Context) (Job, error) {
 	// 高并发情况下，大部分都是陪太子读书
 	// 100 个 goroutine
@@ -82,14 +103,14 @@ func (g *GormJobDAO) Preempt(ctx context.Context) (Job, error) {
 		// 乐观锁，CAS 操作，compare AND Swap
 		// 有一个很常见的面试刷亮点：就是用乐观锁取代 FOR UPDATE
 		// 面试套路（性能优化）：曾将用了 FOR UPDATE =>性能差，还会有死锁 => 我优化成了乐观锁
-		res := db.Where("id = ? AND version = ?", j.Id, j.Version).
+		res := db.Model(&Job{}).Where("id = ? AND version = ?", j.Id, j.Version).
 			Updates(map[string]any{
 				"status":  jobStatusRunning,
 				"utime":   now.UnixMilli(),
 				"version": j.Version + 1,
 			})
 		if res.Error != nil {
-			return Job{}, err
+			return Job{}, res.Error
 		}
 		if res.RowsAffected == 0 {
 			// 抢占失败，继续抢
@@ -117,7 +138,7 @@ func (g *GormJobDAO) Release(ctx context.Context, id int64) error {
 }
 
 func (g *GormJobDAO) Stop(ctx context.Context, id int64) error {
-	return g.db.WithContext(ctx).
+	return g.db.WithContext(ctx).Model(&Job{}).
 		Where("id = ?", id).Updates(map[string]any{
 		"status": jobStatusPaused,
 		"utime":  time.Now().UnixMilli(),
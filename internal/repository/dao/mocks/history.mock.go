@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./history.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./history.go -package=daomocks -destination=mocks/history.mock.go HistoryRecordDAO
+//
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	dao "webooktrial/internal/repository/dao"
+	pagination "webooktrial/pkg/pagination"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockHistoryRecordDAO is a mock of HistoryRecordDAO interface.
+type MockHistoryRecordDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockHistoryRecordDAOMockRecorder
+}
+
+// MockHistoryRecordDAOMockRecorder is the mock recorder for MockHistoryRecordDAO.
+type MockHistoryRecordDAOMockRecorder struct {
+	mock *MockHistoryRecordDAO
+}
+
+// NewMockHistoryRecordDAO creates a new mock instance.
+func NewMockHistoryRecordDAO(ctrl *gomock.Controller) *MockHistoryRecordDAO {
+	mock := &MockHistoryRecordDAO{ctrl: ctrl}
+	mock.recorder = &MockHistoryRecordDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHistoryRecordDAO) EXPECT() *MockHistoryRecordDAOMockRecorder {
+	return m.recorder
+}
+
+// DeleteByUid mocks base method.
+func (m *MockHistoryRecordDAO) DeleteByUid(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByUid", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByUid indicates an expected call of DeleteByUid.
+func (mr *MockHistoryRecordDAOMockRecorder) DeleteByUid(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUid", reflect.TypeOf((*MockHistoryRecordDAO)(nil).DeleteByUid), ctx, uid)
+}
+
+// ListByCursor mocks base method.
+func (m *MockHistoryRecordDAO) ListByCursor(ctx context.Context, uid int64, cursor pagination.Cursor, limit int) ([]dao.HistoryRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByCursor", ctx, uid, cursor, limit)
+	ret0, _ := ret[0].([]dao.HistoryRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByCursor indicates an expected call of ListByCursor.
+func (mr *MockHistoryRecordDAOMockRecorder) ListByCursor(ctx, uid, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByCursor", reflect.TypeOf((*MockHistoryRecordDAO)(nil).ListByCursor), ctx, uid, cursor, limit)
+}
+
+// Upsert mocks base method.
+func (m *MockHistoryRecordDAO) Upsert(ctx context.Context, records []dao.HistoryRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, records)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockHistoryRecordDAOMockRecorder) Upsert(ctx, records any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockHistoryRecordDAO)(nil).Upsert), ctx, records)
+}
@@ -0,0 +1,246 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrUploadQuotaExceeded 加上这次上传就超过 quota 了
+var ErrUploadQuotaExceeded = errors.New("上传空间不够了")
+
+type UploadDAO interface {
+	// Insert uid 和 hash 冲突的时候返回已有的记录，不占用空间。
+	// 新的记录会在同一个事务里面占用空间，超过 quota 返回 ErrUploadQuotaExceeded
+	Insert(ctx context.Context, u Upload, quota int64) (Upload, error)
+	FindByHashes(ctx context.Context, uid int64, hashes []string) ([]Upload, error)
+	// Touch 把 ctime 改成现在，返回记录还在不在
+	Touch(ctx context.Context, id int64) (bool, error)
+	// SumSize 这个人已经用掉的空间
+	SumSize(ctx context.Context, uid int64) (int64, error)
+	// SetRefs 文章的 version 版本引用的上传就是 ids，
+	// 这个版本原本有但是不在 ids 里面的引用会被删掉，其它版本的引用不受影响
+	SetRefs(ctx context.Context, aid int64, version uint8, ids []int64) error
+	// ListUnreferenced ctime 早于 before，并且没有被任何文章引用的上传
+	ListUnreferenced(ctx context.Context, before int64, limit int) ([]Upload, error)
+	// Delete 只有 ctime 早于 before 并且没有被引用的时候才会删除，已经删掉的不算错误，
+	// 返回值是有没有删除，以及同样内容的其它记录还有几条
+	Delete(ctx context.Context, id int64, before int64) (bool, int64, error)
+}
+
+// Upload 上传的图片，<uid, hash> 唯一
+type Upload struct {
+	Id          int64  `gorm:"primaryKey,autoIncrement"`
+	Uid         int64  `gorm:"uniqueIndex:uid_hash"`
+	Hash        string `gorm:"type:char(64);uniqueIndex:uid_hash;index"`
+	ContentType string `gorm:"type:varchar(64)"`
+	Size        int64
+	Width       int
+	Height      int
+	ObjectKey   string `gorm:"type:varchar(256)"`
+	ThumbKey    string `gorm:"type:varchar(256)"`
+	// Ctime 清理的时候按照创建时间扫描
+	Ctime int64 `gorm:"index"`
+	Utime int64
+}
+
+// UploadRef 文章引用了哪些上传，制作库和线上库的内容可能不一样，所以按照版本分开记录
+type UploadRef struct {
+	Id        int64 `gorm:"primaryKey,autoIncrement"`
+	UploadId  int64 `gorm:"uniqueIndex:upload_article"`
+	ArticleId int64 `gorm:"uniqueIndex:upload_article;index"`
+	Version   uint8 `gorm:"uniqueIndex:upload_article"`
+	Ctime     int64
+}
+
+// UploadQuota 每个人已经用掉的空间，和 Upload 在同一个事务里面修改
+type UploadQuota struct {
+	Uid   int64 `gorm:"primaryKey"`
+	Used  int64
+	Utime int64
+}
+
+type GORMUploadDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMUploadDAO(db *gorm.DB) UploadDAO {
+	return &GORMUploadDAO{db: db}
+}
+
+func (g *GORMUploadDAO) Insert(ctx context.Context, u Upload, quota int64) (Upload, error) {
+	now := time.Now().UnixMilli()
+	u.Ctime = now
+	u.Utime = now
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 并发上传同样的内容，后来的什么都不做
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&u)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return tx.Where("uid = ? AND hash = ?", u.Uid, u.Hash).First(&u).Error
+		}
+		return g.occupy(tx, u, quota, now)
+	})
+	return u, err
+}
+
+// occupy 检查和增加在一条 UPDATE 里面，并发上传的时候不会超过 quota，
+// 超过了整个事务回滚，刚插入的记录也就没了
+func (g *GORMUploadDAO) occupy(tx *gorm.DB, u Upload, quota int64, now int64) error {
+	occupy := func() (int64, error) {
+		res := tx.Model(&UploadQuota{}).
+			Where("uid = ? AND used + ? <= ?", u.Uid, u.Size, quota).
+			Updates(map[string]any{
+				"used":  gorm.Expr("used + ?", u.Size),
+				"utime": now,
+			})
+		return res.RowsAffected, res.Error
+	}
+	cnt, err := occupy()
+	if err != nil || cnt == 1 {
+		return err
+	}
+	var exist int64
+	err = tx.Model(&UploadQuota{}).Where("uid = ?", u.Uid).Count(&exist).Error
+	if err != nil {
+		return err
+	}
+	if exist > 0 {
+		return ErrUploadQuotaExceeded
+	}
+	// 第一次上传，用已有的上传初始化，兼容有这张表之前的数据
+	var used int64
+	err = tx.Model(&Upload{}).
+		Where("uid = ? AND id <> ?", u.Uid, u.Id).
+		Select("COALESCE(SUM(size), 0)").
+		Scan(&used).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&UploadQuota{Uid: u.Uid, Used: used, Utime: now}).Error
+	if err != nil {
+		return err
+	}
+	cnt, err = occupy()
+	if err != nil {
+		return err
+	}
+	if cnt == 0 {
+		return ErrUploadQuotaExceeded
+	}
+	return nil
+}
+
+func (g *GORMUploadDAO) FindByHashes(ctx context.Context, uid int64, hashes []string) ([]Upload, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	var res []Upload
+	err := g.db.WithContext(ctx).
+		Where("uid = ? AND hash IN ?", uid, hashes).
+		Find(&res).Error
+	return res, err
+}
+
+func (g *GORMUploadDAO) Touch(ctx context.Context, id int64) (bool, error) {
+	now := time.Now().UnixMilli()
+	res := g.db.WithContext(ctx).Model(&Upload{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"ctime": now,
+			"utime": now,
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (g *GORMUploadDAO) SumSize(ctx context.Context, uid int64) (int64, error) {
+	var size int64
+	err := g.db.WithContext(ctx).Model(&Upload{}).
+		Where("uid = ?", uid).
+		Select("COALESCE(SUM(size), 0)").
+		Scan(&size).Error
+	return size, err
+}
+
+func (g *GORMUploadDAO) SetRefs(ctx context.Context, aid int64, version uint8, ids []int64) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		db := tx.Where("article_id = ? AND version = ?", aid, version)
+		if len(ids) > 0 {
+			db = db.Where("upload_id NOT IN ?", ids)
+		}
+		err := db.Delete(&UploadRef{}).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		now := time.Now().UnixMilli()
+		refs := make([]UploadRef, 0, len(ids))
+		for _, id := range ids {
+			refs = append(refs, UploadRef{UploadId: id, ArticleId: aid, Version: version, Ctime: now})
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&refs).Error
+	})
+}
+
+func (g *GORMUploadDAO) ListUnreferenced(ctx context.Context, before int64, limit int) ([]Upload, error) {
+	var res []Upload
+	err := g.db.WithContext(ctx).
+		Where("ctime < ?", before).
+		Where("NOT EXISTS (?)", g.db.Model(&UploadRef{}).
+			Select("1").Where("upload_refs.upload_id = uploads.id")).
+		Order("ctime ASC").Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (g *GORMUploadDAO) Delete(ctx context.Context, id int64, before int64) (bool, int64, error) {
+	var (
+		deleted bool
+		remain  int64
+	)
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var u Upload
+		err := tx.Where("id = ?", id).First(&u).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 已经被别的清理删掉了
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// 锁住同样内容的记录，别人并发上传同样的内容要等这个事务提交，
+		// 这样删除和剩下几条是一致的
+		var ids []int64
+		err = tx.Model(&Upload{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("hash = ?", u.Hash).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		// 扫描之后可能又被引用了，或者又被上传了一次
+		res := tx.Where("id = ? AND ctime < ?", id, before).
+			Where("NOT EXISTS (?)", tx.Model(&UploadRef{}).
+				Select("1").Where("upload_id = ?", id)).
+			Delete(&Upload{})
+		if res.Error != nil {
+			return res.Error
+		}
+		deleted = res.RowsAffected == 1
+		if !deleted {
+			return nil
+		}
+		remain = int64(len(ids)) - 1
+		return tx.Model(&UploadQuota{}).Where("uid = ?", u.Uid).
+			Updates(map[string]any{
+				"used":  gorm.Expr("GREATEST(used - ?, 0)", u.Size),
+				"utime": time.Now().UnixMilli(),
+			}).Error
+	})
+	return deleted, remain, err
+}
@@ -0,0 +1,253 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestGORMUploadDAO_Insert(t *testing.T) {
+	uploadArgs := []driver.Value{int64(123), "abc", "", int64(100), 0, 0, "", "",
+		sqlmock.AnyArg(), sqlmock.AnyArg()}
+	testCases := []struct {
+		name string
+		mock func(t *testing.T) *sql.DB
+
+		upload  Upload
+		quota   int64
+		wantId  int64
+		wantErr error
+	}{
+		{
+			name: "新上传，占用空间",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `uploads` .*").
+					WithArgs(uploadArgs...).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectExec("UPDATE `upload_quota` SET .* WHERE uid = \\? AND used \\+ \\? <= \\?").
+					WithArgs(int64(100), sqlmock.AnyArg(), int64(123), int64(100), int64(1000)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return mockDB
+			},
+			upload: Upload{Uid: 123, Hash: "abc", Size: 100},
+			quota:  1000,
+			wantId: 3,
+		},
+		{
+			name: "重复上传，不占用空间",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `uploads` .*").
+					WithArgs(uploadArgs...).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT \\* FROM `uploads` WHERE uid = \\? AND hash = \\?").
+					WithArgs(int64(123), "abc").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "uid", "hash"}).
+						AddRow(int64(2), int64(123), "abc"))
+				mock.ExpectCommit()
+				return mockDB
+			},
+			upload: Upload{Uid: 123, Hash: "abc", Size: 100},
+			quota:  1000,
+			wantId: 2,
+		},
+		{
+			name: "空间不够，回滚",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `uploads` .*").
+					WithArgs(uploadArgs...).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectExec("UPDATE `upload_quota` SET .*").
+					WithArgs(int64(100), sqlmock.AnyArg(), int64(123), int64(100), int64(1000)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT count\\(\\*\\) FROM `upload_quota` WHERE uid = \\?").
+					WithArgs(int64(123)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+				return mockDB
+			},
+			upload:  Upload{Uid: 123, Hash: "abc", Size: 100},
+			quota:   1000,
+			wantId:  3,
+			wantErr: ErrUploadQuotaExceeded,
+		},
+		{
+			name: "第一次占用，用已有的上传初始化",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `uploads` .*").
+					WithArgs(uploadArgs...).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectExec("UPDATE `upload_quota` SET .*").
+					WithArgs(int64(100), sqlmock.AnyArg(), int64(123), int64(100), int64(1000)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT count\\(\\*\\) FROM `upload_quota` WHERE uid = \\?").
+					WithArgs(int64(123)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery("SELECT COALESCE\\(SUM\\(size\\), 0\\) FROM `uploads` WHERE uid = \\? AND id <> \\?").
+					WithArgs(int64(123), int64(3)).
+					WillReturnRows(sqlmock.NewRows([]string{"size"}).AddRow(int64(950)))
+				mock.ExpectExec("INSERT INTO `upload_quota` .*").
+					WithArgs(int64(950), sqlmock.AnyArg(), int64(123)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				// 加上已有的 950 就超了
+				mock.ExpectExec("UPDATE `upload_quota` SET .*").
+					WithArgs(int64(100), sqlmock.AnyArg(), int64(123), int64(100), int64(1000)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				return mockDB
+			},
+			upload:  Upload{Uid: 123, Hash: "abc", Size: 100},
+			quota:   1000,
+			wantId:  3,
+			wantErr: ErrUploadQuotaExceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+				Conn:                      tc.mock(t),
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			require.NoError(t, err)
+			d := NewGORMUploadDAO(db)
+			u, err := d.Insert(context.Background(), tc.upload, tc.quota)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, u.Id)
+		})
+	}
+}
+
+func TestGORMUploadDAO_SetRefs(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	mock.ExpectBegin()
+	// 只删除这个版本的引用
+	mock.ExpectExec("DELETE FROM `upload_refs` WHERE \\(article_id = \\? AND version = \\?\\) AND upload_id NOT IN \\(\\?,\\?\\)").
+		WithArgs(int64(10), uint8(2), int64(1), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO `upload_refs` \\(`upload_id`,`article_id`,`version`,`ctime`\\) .*").
+		WithArgs(int64(1), int64(10), uint8(2), sqlmock.AnyArg(),
+			int64(2), int64(10), uint8(2), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectCommit()
+	db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	d := NewGORMUploadDAO(db)
+	assert.NoError(t, d.SetRefs(context.Background(), 10, 2, []int64{1, 2}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGORMUploadDAO_Delete(t *testing.T) {
+	uploadCols := []string{"id", "uid", "hash", "size", "ctime"}
+	testCases := []struct {
+		name string
+		mock func(t *testing.T) *sql.DB
+
+		wantDeleted bool
+		wantRemain  int64
+	}{
+		{
+			name: "删除，还有别人上传了同样的内容",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `uploads` WHERE id = \\?").
+					WithArgs(int64(1)).
+					WillReturnRows(sqlmock.NewRows(uploadCols).AddRow(1, 123, "abc", 100, 10))
+				mock.ExpectQuery("SELECT `id` FROM `uploads` WHERE hash = \\? FOR UPDATE").
+					WithArgs("abc").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+				mock.ExpectExec("DELETE FROM `uploads` WHERE \\(id = \\? AND ctime < \\?\\) AND NOT EXISTS .*").
+					WithArgs(int64(1), int64(100), int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE `upload_quota` SET .* WHERE uid = \\?").
+					WithArgs(int64(100), sqlmock.AnyArg(), int64(123)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return mockDB
+			},
+			wantDeleted: true,
+			wantRemain:  1,
+		},
+		{
+			name: "扫描之后又被引用或者又被上传了",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `uploads` WHERE id = \\?").
+					WithArgs(int64(1)).
+					WillReturnRows(sqlmock.NewRows(uploadCols).AddRow(1, 123, "abc", 100, 10))
+				mock.ExpectQuery("SELECT `id` FROM `uploads` WHERE hash = \\? FOR UPDATE").
+					WithArgs("abc").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec("DELETE FROM `uploads` .*").
+					WithArgs(int64(1), int64(100), int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+				return mockDB
+			},
+		},
+		{
+			name: "已经被删掉了",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `uploads` WHERE id = \\?").
+					WithArgs(int64(1)).
+					WillReturnRows(sqlmock.NewRows(uploadCols))
+				mock.ExpectCommit()
+				return mockDB
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+				Conn:                      tc.mock(t),
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			require.NoError(t, err)
+			d := NewGORMUploadDAO(db)
+			deleted, remain, err := d.Delete(context.Background(), 1, 100)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantDeleted, deleted)
+			assert.Equal(t, tc.wantRemain, remain)
+		})
+	}
+}
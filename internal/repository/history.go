@@ -1,7 +1,152 @@
 package repository
 
-import "context"
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+
+	"webooktrial/internal/domain"
+	cache "webooktrial/internal/repository/cache/redis"
+	"webooktrial/internal/repository/dao"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/pagination"
+)
+
+// historyReloadSize 缓存未命中的时候，从数据库里面加载这么多条最近的阅读记录
+const historyReloadSize = 1000
 
 type HistoryRecordRepository interface {
-	AddRecord(ctx context.Context)
+	// AddRecord 同一个人同一篇文章只保留一条，阅读时间取最晚的
+	AddRecord(ctx context.Context, records ...domain.HistoryRecord) error
+	// List 按照阅读时间倒序，只有文章 ID，没有文章的内容
+	List(ctx context.Context, uid int64, cursor pagination.Cursor, limit int) ([]domain.HistoryRecord, error)
+	Clear(ctx context.Context, uid int64) error
+}
+
+type CachedHistoryRecordRepository struct {
+	dao   dao.HistoryRecordDAO
+	cache cache.HistoryCache
+	// 同一个用户的缓存同时只有一个 goroutine 在重建
+	sg singleflight.Group
+	l  logger.LoggerV1
+}
+
+func NewCachedHistoryRecordRepository(dao dao.HistoryRecordDAO,
+	cache cache.HistoryCache, l logger.LoggerV1) HistoryRecordRepository {
+	return &CachedHistoryRecordRepository{dao: dao, cache: cache, l: l}
+}
+
+func (c *CachedHistoryRecordRepository) AddRecord(ctx context.Context, records ...domain.HistoryRecord) error {
+	records = dedupHistory(records)
+	now := time.Now().UnixMilli()
+	err := c.dao.Upsert(ctx, slice.Map(records, func(idx int, src domain.HistoryRecord) dao.HistoryRecord {
+		return dao.HistoryRecord{
+			Uid:   src.Uid,
+			Aid:   src.Article.Id,
+			Rtime: src.Rtime.UnixMilli(),
+			Ctime: now,
+			Utime: now,
+		}
+	}))
+	if err != nil {
+		return err
+	}
+	err = c.cache.Add(ctx, records)
+	if err != nil {
+		// 缓存会过期，过期之后从数据库里面重新加载
+		c.l.Error("写入阅读记录缓存失败", logger.Error(err))
+	}
+	return nil
+}
+
+// dedupHistory 同一批里面同一个人同一篇文章只留最晚的一条
+func dedupHistory(records []domain.HistoryRecord) []domain.HistoryRecord {
+	type key struct {
+		uid int64
+		aid int64
+	}
+	idx := make(map[key]int, len(records))
+	res := make([]domain.HistoryRecord, 0, len(records))
+	for _, r := range records {
+		k := key{uid: r.Uid, aid: r.Article.Id}
+		i, ok := idx[k]
+		if !ok {
+			idx[k] = len(res)
+			res = append(res, r)
+			continue
+		}
+		if r.Rtime.After(res[i].Rtime) {
+			res[i] = r
+		}
+	}
+	return res
+}
+
+func (c *CachedHistoryRecordRepository) List(ctx context.Context, uid int64,
+	cursor pagination.Cursor, limit int) ([]domain.HistoryRecord, error) {
+	res, complete, err := c.cache.List(ctx, uid, cursor, limit)
+	if err == nil && (len(res) == limit || complete) {
+		return res, nil
+	}
+	if err == redis.Nil {
+		// 不等重建完，这一次先查数据库
+		c.sg.DoChan(strconv.FormatInt(uid, 10), func() (any, error) {
+			c.reload(uid)
+			return nil, nil
+		})
+	} else if err != nil {
+		c.l.Error("查询阅读记录缓存失败", logger.Int64("uid", uid), logger.Error(err))
+	}
+	// 缓存里面只有最近的一部分，翻得太深就只能查数据库
+	records, err := c.dao.ListByCursor(ctx, uid, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(records, func(idx int, src dao.HistoryRecord) domain.HistoryRecord {
+		return c.toDomain(src)
+	}), nil
+}
+
+// reload 缓存未命中的时候，异步地把最近的阅读记录加载到缓存里面
+func (c *CachedHistoryRecordRepository) reload(uid int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	// 先占位，查数据库期间新的阅读记录也会写进缓存
+	err := c.cache.MarkLoading(ctx, uid)
+	if err != nil {
+		c.l.Error("重建阅读记录缓存失败", logger.Int64("uid", uid), logger.Error(err))
+		return
+	}
+	records, err := c.dao.ListByCursor(ctx, uid, pagination.Cursor{}, historyReloadSize)
+	if err != nil {
+		c.l.Error("重建阅读记录缓存失败", logger.Int64("uid", uid), logger.Error(err))
+		return
+	}
+	// 没有阅读记录也要缓存下来，不然每次都要查数据库
+	err = c.cache.Set(ctx, uid, slice.Map(records, func(idx int, src dao.HistoryRecord) domain.HistoryRecord {
+		return c.toDomain(src)
+	}))
+	if err != nil {
+		c.l.Error("重建阅读记录缓存失败", logger.Int64("uid", uid), logger.Error(err))
+	}
+}
+
+func (c *CachedHistoryRecordRepository) Clear(ctx context.Context, uid int64) error {
+	err := c.dao.DeleteByUid(ctx, uid)
+	if err != nil {
+		return err
+	}
+	return c.cache.Del(ctx, uid)
+}
+
+func (c *CachedHistoryRecordRepository) toDomain(record dao.HistoryRecord) domain.HistoryRecord {
+	return domain.HistoryRecord{
+		Uid:     record.Uid,
+		Article: domain.Article{Id: record.Aid},
+		Rtime:   time.UnixMilli(record.Rtime),
+	}
 }
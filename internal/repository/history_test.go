@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"webooktrial/internal/domain"
+	cachemocks "webooktrial/internal/repository/cache/redis/mocks"
+	"webooktrial/internal/repository/dao"
+	daomocks "webooktrial/internal/repository/dao/mocks"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/pagination"
+)
+
+func TestDedupHistory(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	record := func(uid, aid int64, rtime time.Time) domain.HistoryRecord {
+		return domain.HistoryRecord{Uid: uid, Article: domain.Article{Id: aid}, Rtime: rtime}
+	}
+	testCases := []struct {
+		name    string
+		records []domain.HistoryRecord
+		want    []domain.HistoryRecord
+	}{
+		{
+			name: "没有重复",
+			records: []domain.HistoryRecord{
+				record(1, 1, now),
+				record(1, 2, now),
+				record(2, 1, now),
+			},
+			want: []domain.HistoryRecord{
+				record(1, 1, now),
+				record(1, 2, now),
+				record(2, 1, now),
+			},
+		},
+		{
+			name: "重复的以最晚的阅读时间为准",
+			records: []domain.HistoryRecord{
+				record(1, 1, now),
+				record(1, 2, now),
+				record(1, 1, now.Add(time.Second)),
+				// 乱序到达的旧事件
+				record(1, 1, now.Add(-time.Second)),
+			},
+			want: []domain.HistoryRecord{
+				record(1, 1, now.Add(time.Second)),
+				record(1, 2, now),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, dedupHistory(tc.records))
+		})
+	}
+}
+
+func TestCachedHistoryRecordRepository_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	historyDAO := daomocks.NewMockHistoryRecordDAO(ctrl)
+	historyCache := cachemocks.NewMockHistoryCache(ctrl)
+
+	historyCache.EXPECT().List(gomock.Any(), int64(1), pagination.Cursor{}, 10).
+		Return(nil, false, redis.Nil).Times(3)
+	// 每一次都直接查数据库，这个用户还没有阅读记录
+	historyDAO.EXPECT().ListByCursor(gomock.Any(), int64(1), pagination.Cursor{}, 10).
+		Return(nil, nil).Times(3)
+
+	// 重建缓存只有一次，重建完成之前后面的请求不会再触发重建
+	unblock := make(chan struct{})
+	reloaded := make(chan []domain.HistoryRecord, 1)
+	historyCache.EXPECT().MarkLoading(gomock.Any(), int64(1)).
+		DoAndReturn(func(ctx context.Context, uid int64) error {
+			<-unblock
+			return nil
+		})
+	historyDAO.EXPECT().ListByCursor(gomock.Any(), int64(1), pagination.Cursor{}, historyReloadSize).
+		Return([]dao.HistoryRecord{}, nil)
+	historyCache.EXPECT().Set(gomock.Any(), int64(1), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, uid int64, records []domain.HistoryRecord) error {
+			reloaded <- records
+			return nil
+		})
+
+	repo := NewCachedHistoryRecordRepository(historyDAO, historyCache, logger.NewNopLogger())
+	for i := 0; i < 3; i++ {
+		res, err := repo.List(context.Background(), 1, pagination.Cursor{}, 10)
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	}
+	close(unblock)
+	select {
+	case records := <-reloaded:
+		// 没有阅读记录也要缓存
+		assert.Empty(t, records)
+	case <-time.After(time.Second):
+		t.Fatal("没有重建缓存")
+	}
+}
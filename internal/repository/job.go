@@ -8,7 +8,11 @@ import (
 	"webooktrial/internal/repository/dao"
 )
 
+var ErrNoMoreJob = dao.ErrNoMoreJob
+
 type JobRepository interface {
+	// Add 已经有同名的任务就什么都不做
+	Add(ctx context.Context, j domain.Job) error
 	Preempt(ctx context.Context) (domain.Job, error)
 	Release(ctx context.Context, id int64) error
 	UpdateUtime(ctx context.Context, id int64) error
@@ -20,6 +24,21 @@ type PreemptCronJobRepository struct {
 	dao dao.JobDAO
 }
 
+func NewPreemptCronJobRepository(dao dao.JobDAO) JobRepository {
+	return &PreemptCronJobRepository{dao: dao}
+}
+
+func (p *PreemptCronJobRepository) Add(ctx context.Context, j domain.Job) error {
+	return p.dao.Insert(ctx, dao.Job{
+		Name:     j.Name,
+		Executor: j.Executor,
+		Cfg:      j.Cfg,
+		Cron:     j.Cron,
+		// 加进来之后马上就可以调度
+		NextTime: time.Now().UnixMilli(),
+	})
+}
+
 func (p *PreemptCronJobRepository) Preempt(ctx context.Context) (domain.Job, error) {
 	j, err := p.dao.Preempt(ctx)
 	if err != nil {
@@ -29,6 +48,7 @@ func (p *PreemptCronJobRepository) Preempt(ctx context.Context) (domain.Job, err
 		Cfg:      j.Cfg,
 		Id:       j.Id,
 		Name:     j.Name,
+		Cron:     j.Cron,
 		Executor: j.Executor,
 	}, nil
 }
@@ -0,0 +1,146 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./upload.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./upload.go -package=repomocks -destination=mocks/upload.mock.go UploadRepository
+//
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	domain "webooktrial/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUploadRepository is a mock of UploadRepository interface.
+type MockUploadRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUploadRepositoryMockRecorder
+}
+
+// MockUploadRepositoryMockRecorder is the mock recorder for MockUploadRepository.
+type MockUploadRepositoryMockRecorder struct {
+	mock *MockUploadRepository
+}
+
+// NewMockUploadRepository creates a new mock instance.
+func NewMockUploadRepository(ctrl *gomock.Controller) *MockUploadRepository {
+	mock := &MockUploadRepository{ctrl: ctrl}
+	mock.recorder = &MockUploadRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUploadRepository) EXPECT() *MockUploadRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockUploadRepository) Create(ctx context.Context, u domain.Upload, quota int64) (domain.Upload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, u, quota)
+	ret0, _ := ret[0].(domain.Upload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockUploadRepositoryMockRecorder) Create(ctx, u, quota any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUploadRepository)(nil).Create), ctx, u, quota)
+}
+
+// Delete mocks base method.
+func (m *MockUploadRepository) Delete(ctx context.Context, id int64, before time.Time) (bool, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, before)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockUploadRepositoryMockRecorder) Delete(ctx, id, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUploadRepository)(nil).Delete), ctx, id, before)
+}
+
+// FindByHashes mocks base method.
+func (m *MockUploadRepository) FindByHashes(ctx context.Context, uid int64, hashes []string) ([]domain.Upload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByHashes", ctx, uid, hashes)
+	ret0, _ := ret[0].([]domain.Upload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByHashes indicates an expected call of FindByHashes.
+func (mr *MockUploadRepositoryMockRecorder) FindByHashes(ctx, uid, hashes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByHashes", reflect.TypeOf((*MockUploadRepository)(nil).FindByHashes), ctx, uid, hashes)
+}
+
+// ListUnreferenced mocks base method.
+func (m *MockUploadRepository) ListUnreferenced(ctx context.Context, before time.Time, limit int) ([]domain.Upload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUnreferenced", ctx, before, limit)
+	ret0, _ := ret[0].([]domain.Upload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUnreferenced indicates an expected call of ListUnreferenced.
+func (mr *MockUploadRepositoryMockRecorder) ListUnreferenced(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUnreferenced", reflect.TypeOf((*MockUploadRepository)(nil).ListUnreferenced), ctx, before, limit)
+}
+
+// SetArticleRefs mocks base method.
+func (m *MockUploadRepository) SetArticleRefs(ctx context.Context, aid int64, version domain.UploadRefVersion, ids []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetArticleRefs", ctx, aid, version, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetArticleRefs indicates an expected call of SetArticleRefs.
+func (mr *MockUploadRepositoryMockRecorder) SetArticleRefs(ctx, aid, version, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetArticleRefs", reflect.TypeOf((*MockUploadRepository)(nil).SetArticleRefs), ctx, aid, version, ids)
+}
+
+// Touch mocks base method.
+func (m *MockUploadRepository) Touch(ctx context.Context, id int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Touch", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Touch indicates an expected call of Touch.
+func (mr *MockUploadRepositoryMockRecorder) Touch(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Touch", reflect.TypeOf((*MockUploadRepository)(nil).Touch), ctx, id)
+}
+
+// UsedSize mocks base method.
+func (m *MockUploadRepository) UsedSize(ctx context.Context, uid int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UsedSize", ctx, uid)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UsedSize indicates an expected call of UsedSize.
+func (mr *MockUploadRepositoryMockRecorder) UsedSize(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UsedSize", reflect.TypeOf((*MockUploadRepository)(nil).UsedSize), ctx, uid)
+}
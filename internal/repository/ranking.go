@@ -11,6 +11,12 @@ import (
 type RankingRepository interface {
 	ReplaceTopN(ctx context.Context, arts []domain.Article) error
 	GetTopN(ctx context.Context) ([]domain.Article, error)
+	// RemoveArticle 文章撤回之后，要从热榜里面去掉，不用等下一次计算热榜
+	// 标签的热榜不处理，等下一次计算热榜的时候自然就没有了
+	RemoveArticle(ctx context.Context, aid int64) error
+
+	ReplaceTopNByTag(ctx context.Context, tag string, arts []domain.Article) error
+	GetTopNByTag(ctx context.Context, tag string) ([]domain.Article, error)
 }
 
 type CachedRankingRepository struct {
@@ -41,6 +47,20 @@ func (c *CachedRankingRepository) GetTopN(ctx context.Context) ([]domain.Article
 	return data, err
 }
 
+func (c *CachedRankingRepository) RemoveArticle(ctx context.Context, aid int64) error {
+	c.local.Remove(ctx, aid)
+	return c.redis.Remove(ctx, aid)
+}
+
+func (c *CachedRankingRepository) ReplaceTopNByTag(ctx context.Context, tag string, arts []domain.Article) error {
+	return c.redis.SetByTag(ctx, tag, arts)
+}
+
+func (c *CachedRankingRepository) GetTopNByTag(ctx context.Context, tag string) ([]domain.Article, error) {
+	// 标签很多，本地缓存放不下，只查 Redis
+	return c.redis.GetByTag(ctx, tag)
+}
+
 func NewCachedRankingRepository(
 	redis *redis.RankingRedisCache,
 	local *local.RankingLocalCache,
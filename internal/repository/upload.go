@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+
+	"webooktrial/internal/domain"
+	"webooktrial/internal/repository/dao"
+)
+
+var ErrUploadQuotaExceeded = dao.ErrUploadQuotaExceeded
+
+type UploadRepository interface {
+	// Create 同一个人上传同样的内容的时候返回已有的，
+	// 新的上传加上已经用掉的空间超过 quota 返回 ErrUploadQuotaExceeded
+	Create(ctx context.Context, u domain.Upload, quota int64) (domain.Upload, error)
+	FindByHashes(ctx context.Context, uid int64, hashes []string) ([]domain.Upload, error)
+	// Touch 重新开始计算清理的时间，返回 false 说明已经被清理掉了
+	Touch(ctx context.Context, id int64) (bool, error)
+	UsedSize(ctx context.Context, uid int64) (int64, error)
+	SetArticleRefs(ctx context.Context, aid int64, version domain.UploadRefVersion, ids []int64) error
+	ListUnreferenced(ctx context.Context, before time.Time, limit int) ([]domain.Upload, error)
+	// Delete 还被引用的，或者 before 之后又被上传过的不会删除，
+	// 返回有没有删除，以及同样内容的其它记录还有几条
+	Delete(ctx context.Context, id int64, before time.Time) (bool, int64, error)
+}
+
+type uploadRepository struct {
+	dao dao.UploadDAO
+}
+
+func NewUploadRepository(dao dao.UploadDAO) UploadRepository {
+	return &uploadRepository{dao: dao}
+}
+
+func (u *uploadRepository) Create(ctx context.Context, upload domain.Upload, quota int64) (domain.Upload, error) {
+	res, err := u.dao.Insert(ctx, u.toEntity(upload), quota)
+	if err != nil {
+		return domain.Upload{}, err
+	}
+	return u.toDomain(res), nil
+}
+
+func (u *uploadRepository) FindByHashes(ctx context.Context, uid int64, hashes []string) ([]domain.Upload, error) {
+	res, err := u.dao.FindByHashes(ctx, uid, hashes)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(res, func(idx int, src dao.Upload) domain.Upload {
+		return u.toDomain(src)
+	}), nil
+}
+
+func (u *uploadRepository) Touch(ctx context.Context, id int64) (bool, error) {
+	return u.dao.Touch(ctx, id)
+}
+
+func (u *uploadRepository) UsedSize(ctx context.Context, uid int64) (int64, error) {
+	return u.dao.SumSize(ctx, uid)
+}
+
+func (u *uploadRepository) SetArticleRefs(ctx context.Context, aid int64,
+	version domain.UploadRefVersion, ids []int64) error {
+	return u.dao.SetRefs(ctx, aid, uint8(version), ids)
+}
+
+func (u *uploadRepository) ListUnreferenced(ctx context.Context, before time.Time, limit int) ([]domain.Upload, error) {
+	res, err := u.dao.ListUnreferenced(ctx, before.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(res, func(idx int, src dao.Upload) domain.Upload {
+		return u.toDomain(src)
+	}), nil
+}
+
+func (u *uploadRepository) Delete(ctx context.Context, id int64, before time.Time) (bool, int64, error) {
+	return u.dao.Delete(ctx, id, before.UnixMilli())
+}
+
+func (u *uploadRepository) toEntity(upload domain.Upload) dao.Upload {
+	return dao.Upload{
+		Id:          upload.Id,
+		Uid:         upload.Uid,
+		Hash:        upload.Hash,
+		ContentType: upload.ContentType,
+		Size:        upload.Size,
+		Width:       upload.Width,
+		Height:      upload.Height,
+		ObjectKey:   upload.Key,
+		ThumbKey:    upload.ThumbKey,
+	}
+}
+
+func (u *uploadRepository) toDomain(upload dao.Upload) domain.Upload {
+	return domain.Upload{
+		Id:          upload.Id,
+		Uid:         upload.Uid,
+		Hash:        upload.Hash,
+		ContentType: upload.ContentType,
+		Size:        upload.Size,
+		Width:       upload.Width,
+		Height:      upload.Height,
+		Key:         upload.ObjectKey,
+		ThumbKey:    upload.ThumbKey,
+		Ctime:       time.UnixMilli(upload.Ctime),
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"webooktrial/internal/domain"
 	cacheRedis "webooktrial/internal/repository/cache/redis"
 	"webooktrial/internal/repository/dao"
+	"webooktrial/pkg/grpcx/interceptors/ratelimit"
 )
 
 var ErrKeyNotExist = redis.Nil
@@ -93,7 +94,7 @@ func (r *CachedUserRepository) FindById(ctx context.Context, id int64) (domain.U
 	//}
 	// 再从 dao 里面找
 	// 找到了回写 cache
-	if ctx.Value("limited") == "true" {
+	if ratelimit.IsLimited(ctx) {
 		return domain.User{}, errors.New("触发限流，缓存未命中，不查询数据库")
 	}
 
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -10,45 +12,76 @@ import (
 	events "webooktrial/internal/events/article"
 	"webooktrial/internal/repository/article"
 	"webooktrial/pkg/logger"
+	"webooktrial/pkg/moderation"
+	"webooktrial/pkg/pagination"
 )
 
+// ErrArticleContentRejected 没过内容审核
+var ErrArticleContentRejected = errors.New("文章包含违规内容")
+
 //go:generate mockgen -source=./article.go -package=svcmocks -destination=mocks/article.mock.go ArticleService
 type ArticleService interface {
 	Save(ctx context.Context, art domain.Article) (int64, error)
 	Publish(ctx context.Context, art domain.Article) (int64, error)
 	PublishV1(ctx context.Context, art domain.Article) (int64, error)
 	Withdraw(ctx *gin.Context, art domain.Article) error
+	// List Deprecated: 用 ListByCursor
 	List(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error)
+	// ListByCursor 作者自己的文章列表，cursor 零值表示第一页
+	ListByCursor(ctx context.Context, uid int64, cursor pagination.Cursor, limit int) ([]domain.Article, error)
 	GetById(ctx context.Context, id int64) (domain.Article, error)
 	// ListPub 只会取 startup 七天内的数据
+	// Deprecated: 用 ListPubByCursor
 	ListPub(ctx context.Context, start time.Time, offset, limit int) ([]domain.Article, error)
+	// ListPubByCursor 已发表的文章，按照更新时间倒序
+	ListPubByCursor(ctx context.Context, cursor pagination.Cursor, limit int) ([]domain.Article, error)
 	GetPublishedById(ctx context.Context, id int64, uid int64) (domain.Article, error)
+
+	// ListRevisions 作者查看自己文章的历史版本
+	ListRevisions(ctx context.Context, uid, artId int64, offset, limit int) ([]domain.ArticleRevision, error)
+	// DiffRevisions 比较两个版本，to 为 0 的时候和当前的草稿比较
+	DiffRevisions(ctx context.Context, uid, artId, from, to int64) (domain.ArticleRevisionDiff, error)
+	// RestoreRevision 把某个版本恢复成草稿，publish 为 true 的时候顺便发表
+	RestoreRevision(ctx context.Context, uid, artId, version int64, publish bool) (int64, error)
+
+	// ExecuteSchedules 发表到了定时发表时间的文章，撤回到了定时撤回时间的文章
+	ExecuteSchedules(ctx context.Context, now time.Time) error
+
+	// ListPubByTag 按照更新时间倒序，cursor 零值表示第一页
+	ListPubByTag(ctx context.Context, tag string, cursor pagination.Cursor, limit int) ([]domain.Article, error)
+	// ListTags 标签和标签下已发表的文章数量，按照数量倒序，cursor 零值表示第一页
+	ListTags(ctx context.Context, cursor pagination.Cursor, limit int) ([]domain.Tag, error)
+	// ListRelated 和这篇文章有共同标签的文章
+	ListRelated(ctx context.Context, id int64, limit int) ([]domain.Article, error)
 }
 
 type ArticleCoreService struct {
 	repo article.ArticleRepository
 
 	// V1 依靠两个不同的 repository 来解决这种跨表，或者跨库的问题
-	author   article.ArticleAuthorRepository
-	reader   article.ArticleReaderRepository
-	l        logger.LoggerV1
-	producer events.Producer
-	ch       chan readInfo
+	author    article.ArticleAuthorRepository
+	reader    article.ArticleReaderRepository
+	l         logger.LoggerV1
+	producer  events.Producer
+	moderator *moderation.Moderator
 }
 
 func (a *ArticleCoreService) ListPub(ctx context.Context, start time.Time, offset, limit int) ([]domain.Article, error) {
 	return a.repo.ListPub(ctx, start, offset, limit)
 }
 
-type readInfo struct {
-	uid int64
-	aid int64
+func (a *ArticleCoreService) ListPubByCursor(ctx context.Context, cursor pagination.Cursor, limit int) ([]domain.Article, error) {
+	return a.repo.ListPubByCursor(ctx, cursor, limit)
 }
 
 func (a *ArticleCoreService) List(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error) {
 	return a.repo.List(ctx, uid, offset, limit)
 }
 
+func (a *ArticleCoreService) ListByCursor(ctx context.Context, uid int64, cursor pagination.Cursor, limit int) ([]domain.Article, error) {
+	return a.repo.ListByCursor(ctx, uid, cursor, limit)
+}
+
 func (a *ArticleCoreService) GetById(ctx context.Context, id int64) (domain.Article, error) {
 	return a.repo.GetByID(ctx, id)
 
@@ -58,41 +91,34 @@ func (a *ArticleCoreService) GetPublishedById(ctx context.Context, id int64, uid
 	// 另一个选项，在这里组装 Author，调用 UserService
 	art, err := a.repo.GetPublishedById(ctx, id)
 	if err == nil {
-		go func() {
-			// 生产者也可以通过改批量来提高性能
-			er := a.producer.ProduceReadEvent(
-				// 即便你的消费者要用 art 的里面的数据，
-				// 让它去查询，你不要在 events 里面带
-				ctx, events.ReadEvent{
-					Uid: uid,
-					Aid: id,
-				})
-			if er != nil {
-				a.l.Error("发送读者阅读事件失败",
-					logger.Int64("Uid", uid),
-					logger.Int64("Aid", id))
-			}
-		}()
-	}
-	go func() {
-		// 改批量的做法
-		a.ch <- readInfo{
-			aid: id,
-			uid: uid,
+		// producer 内部是攒批异步发送的，这里只是放进缓冲区
+		er := a.producer.ProduceReadEvent(
+			// 即便你的消费者要用 art 的里面的数据，
+			// 让它去查询，你不要在 events 里面带
+			ctx, events.ReadEvent{
+				Uid:   uid,
+				Aid:   id,
+				Ctime: time.Now().UnixMilli(),
+			})
+		if er != nil {
+			a.l.Error("发送读者阅读事件失败",
+				logger.Int64("Uid", uid),
+				logger.Int64("Aid", id),
+				logger.Error(er))
 		}
-
-	}()
+	}
 	return art, err
 }
 
 func NewArticleService(repo article.ArticleRepository,
 	l logger.LoggerV1,
-	producer events.Producer) ArticleService {
+	producer events.Producer,
+	moderator *moderation.Moderator) ArticleService {
 	return &ArticleCoreService{
-		repo:     repo,
-		l:        l,
-		producer: producer,
-		//ch: make(chan readInfo, 10),
+		repo:      repo,
+		l:         l,
+		producer:  producer,
+		moderator: moderator,
 	}
 }
 
@@ -105,52 +131,100 @@ func NewArticleServiceV1(author article.ArticleAuthorRepository,
 	}
 }
 
-func NewArticleServiceV2(repo article.ArticleRepository,
-	l logger.LoggerV1,
-	producer events.Producer) ArticleService {
-	ch := make(chan readInfo, 10)
-	go func() {
-		for {
-			uids := make([]int64, 0, 10)
-			aids := make([]int64, 0, 10)
-			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-			for i := 0; i < 10; i++ {
-				select {
-				case info, ok := <-ch:
-					if !ok {
-						cancel()
-						return
-					}
-					uids = append(uids, info.uid)
-					aids = append(aids, info.aid)
-				case <-ctx.Done():
-					break
-				}
-			}
-			cancel()
-			ctx, cancel = context.WithTimeout(context.Background(), time.Second)
-			producer.ProduceReadEventV1(ctx, events.ReadEventV1{
-				Uids: uids,
-				Aids: aids,
-			})
-			cancel()
+func (a *ArticleCoreService) Withdraw(ctx *gin.Context, art domain.Article) error {
+	err := a.repo.SyncStatus(ctx, art.Id, art.Author.Id, domain.ArticleStatusPrivate)
+	if err == nil {
+		a.produceWithdrawnEvent(ctx, art.Id, art.Author.Id)
+	}
+	return err
+}
+
+func (a *ArticleCoreService) Publish(ctx context.Context, art domain.Article) (int64, error) {
+	art, err := normalizeTaxonomy(art)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	publishAt := art.PublishAt
+	if publishAt.Before(now) {
+		publishAt = now
+	}
+	if !art.UnpublishAt.IsZero() && !art.UnpublishAt.After(publishAt) {
+		return 0, ErrInvalidArticleSchedule
+	}
+	err = a.moderate(ctx, art)
+	if err != nil {
+		return 0, err
+	}
+	if art.PublishAt.After(now) {
+		// 定时发表，只保存到制作库，时间到了由定时任务来发表
+		art.Status = domain.ArticleStatusScheduled
+		if art.Id > 0 {
+			return art.Id, a.repo.Update(ctx, art)
 		}
-	}()
-	return &ArticleCoreService{
-		repo:     repo,
-		producer: producer,
-		l:        l,
-		ch:       ch,
+		return a.repo.Create(ctx, art)
 	}
+	art.Status = domain.ArticleStatusPublished
+	id, err := a.repo.Sync(ctx, art)
+	if err == nil {
+		art.Id = id
+		a.producePublishedEvent(ctx, art)
+	}
+	return id, err
 }
 
-func (a *ArticleCoreService) Withdraw(ctx *gin.Context, art domain.Article) error {
-	return a.repo.SyncStatus(ctx, art.Id, art.Author.Id, domain.ArticleStatusPrivate)
+// moderate 文章没有人工审核的队列，所以只拦截自动拒绝的，
+// 需要人工审核的先发表出去，打个日志方便事后处理
+func (a *ArticleCoreService) moderate(ctx context.Context, art domain.Article) error {
+	verdict, err := a.moderator.Moderate(ctx, art.Title+"\n"+art.Content)
+	if err != nil {
+		// 审核出问题了就放行，不能因为它发表不了
+		a.l.Error("文章自动审核失败",
+			logger.Int64("aid", art.Id),
+			logger.Error(err))
+		return nil
+	}
+	switch verdict.Decision {
+	case moderation.DecisionReject:
+		return ErrArticleContentRejected
+	case moderation.DecisionReview:
+		a.l.Warn("文章需要人工复查",
+			logger.Int64("aid", art.Id),
+			logger.Int64("uid", art.Author.Id),
+			logger.String("hits", strings.Join(verdict.Hits, ",")))
+	}
+	return nil
 }
 
-func (a *ArticleCoreService) Publish(ctx context.Context, art domain.Article) (int64, error) {
-	art.Status = domain.ArticleStatusPublished
-	return a.repo.Sync(ctx, art)
+func (a *ArticleCoreService) producePublishedEvent(ctx context.Context, art domain.Article) {
+	er := a.producer.ProducePublishedEvent(ctx, events.PublishedEvent{
+		Aid:      art.Id,
+		Uid:      art.Author.Id,
+		Title:    art.Title,
+		Content:  art.Content,
+		Utime:    time.Now().UnixMilli(),
+		Category: art.Category,
+		Tags:     art.Tags,
+	})
+	if er != nil {
+		// 文章已经发表成功了，事件丢了只影响下游，不需要返回错误
+		a.l.Error("发送文章发表事件失败",
+			logger.Int64("aid", art.Id),
+			logger.Error(er))
+	}
+}
+
+func (a *ArticleCoreService) produceWithdrawnEvent(ctx context.Context, aid, uid int64) {
+	er := a.producer.ProduceWithdrawnEvent(ctx, events.WithdrawnEvent{
+		Aid:   aid,
+		Uid:   uid,
+		Utime: time.Now().UnixMilli(),
+	})
+	if er != nil {
+		a.l.Error("发送文章撤回事件失败",
+			logger.Int64("aid", aid),
+			logger.Error(er))
+	}
 }
 
 func (a *ArticleCoreService) PublishV1(ctx context.Context, art domain.Article) (int64, error) {
@@ -190,6 +264,11 @@ func (a *ArticleCoreService) PublishV1(ctx context.Context, art domain.Article)
 }
 
 func (a *ArticleCoreService) Save(ctx context.Context, art domain.Article) (int64, error) {
+	art, err := normalizeTaxonomy(art)
+	if err != nil {
+		return 0, err
+	}
+	// 保存草稿会取消定时发表
 	art.Status = domain.ArticleStatusUnpublished
 	if art.Id > 0 {
 		err := a.repo.Update(ctx, art)
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"webooktrial/internal/domain"
+	"webooktrial/pkg/diffx"
+)
+
+var ErrIncorrectArticleAuthor = errors.New("不是文章的作者")
+
+func (a *ArticleCoreService) ListRevisions(ctx context.Context, uid, artId int64, offset, limit int) ([]domain.ArticleRevision, error) {
+	// 历史版本里面也有作者，但是列表可能为空，所以直接查文章
+	art, err := a.repo.GetByID(ctx, artId)
+	if err != nil {
+		return nil, err
+	}
+	if art.Author.Id != uid {
+		return nil, ErrIncorrectArticleAuthor
+	}
+	return a.repo.ListRevisions(ctx, artId, offset, limit)
+}
+
+func (a *ArticleCoreService) DiffRevisions(ctx context.Context, uid, artId, from, to int64) (domain.ArticleRevisionDiff, error) {
+	old, err := a.getRevision(ctx, uid, artId, from)
+	if err != nil {
+		return domain.ArticleRevisionDiff{}, err
+	}
+	var cur domain.ArticleRevision
+	if to > 0 {
+		cur, err = a.getRevision(ctx, uid, artId, to)
+		if err != nil {
+			return domain.ArticleRevisionDiff{}, err
+		}
+	} else {
+		// 和当前的草稿比较
+		art, err := a.repo.GetByID(ctx, artId)
+		if err != nil {
+			return domain.ArticleRevisionDiff{}, err
+		}
+		if art.Author.Id != uid {
+			return domain.ArticleRevisionDiff{}, ErrIncorrectArticleAuthor
+		}
+		cur = domain.ArticleRevision{Title: art.Title, Content: art.Content}
+	}
+	return domain.ArticleRevisionDiff{
+		ArticleId: artId,
+		From:      from,
+		To:        to,
+		Title:     diffx.Lines(old.Title, cur.Title),
+		Content:   diffx.Lines(old.Content, cur.Content),
+	}, nil
+}
+
+func (a *ArticleCoreService) RestoreRevision(ctx context.Context, uid, artId, version int64, publish bool) (int64, error) {
+	rev, err := a.getRevision(ctx, uid, artId, version)
+	if err != nil {
+		return 0, err
+	}
+	// 历史版本只有标题和内容，分类、标签、定时发表这些都用当前草稿的，不然会被清空
+	art, err := a.repo.GetByID(ctx, artId)
+	if err != nil {
+		return 0, err
+	}
+	if art.Author.Id != uid {
+		return 0, ErrIncorrectArticleAuthor
+	}
+	art.Title = rev.Title
+	art.Content = rev.Content
+	// 恢复也是一次保存或者发表，会产生一个新的版本，原本的历史不会被改写
+	if publish {
+		return a.Publish(ctx, art)
+	}
+	return a.Save(ctx, art)
+}
+
+func (a *ArticleCoreService) getRevision(ctx context.Context, uid, artId, version int64) (domain.ArticleRevision, error) {
+	rev, err := a.repo.GetRevision(ctx, artId, version)
+	if err != nil {
+		return domain.ArticleRevision{}, err
+	}
+	if rev.Author.Id != uid {
+		return domain.ArticleRevision{}, ErrIncorrectArticleAuthor
+	}
+	return rev, nil
+}
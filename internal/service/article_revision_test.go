@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"webooktrial/internal/domain"
+	"webooktrial/internal/repository/article"
+	artrepomocks "webooktrial/internal/repository/article/mocks"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/moderation"
+)
+
+func TestArticleCoreService_RestoreRevision(t *testing.T) {
+	publishAt := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	unpublishAt := publishAt.Add(24 * time.Hour)
+	draft := domain.Article{
+		Id:          1,
+		Title:       "当前标题",
+		Content:     "当前内容",
+		Author:      domain.Author{Id: 123},
+		Category:    "后端",
+		Tags:        []string{"go"},
+		PublishAt:   publishAt,
+		UnpublishAt: unpublishAt,
+		Status:      domain.ArticleStatusScheduled,
+	}
+	rev := domain.ArticleRevision{
+		ArticleId: 1,
+		Version:   2,
+		Title:     "旧标题",
+		Content:   "旧内容",
+		Author:    domain.Author{Id: 123},
+	}
+	// 恢复之后，除了标题和内容，别的字段都还是当前草稿的
+	restored := func(status domain.ArticleStatus) domain.Article {
+		art := draft
+		art.Title = rev.Title
+		art.Content = rev.Content
+		art.Status = status
+		return art
+	}
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) article.ArticleRepository
+		uid     int64
+		publish bool
+
+		wantId  int64
+		wantErr error
+	}{
+		{
+			name: "恢复成草稿",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetRevision(gomock.Any(), int64(1), int64(2)).Return(rev, nil)
+				repo.EXPECT().GetByID(gomock.Any(), int64(1)).Return(draft, nil)
+				repo.EXPECT().Update(gomock.Any(), restored(domain.ArticleStatusUnpublished)).Return(nil)
+				return repo
+			},
+			uid:    123,
+			wantId: 1,
+		},
+		{
+			name: "恢复并发表，定时发表还在",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetRevision(gomock.Any(), int64(1), int64(2)).Return(rev, nil)
+				repo.EXPECT().GetByID(gomock.Any(), int64(1)).Return(draft, nil)
+				repo.EXPECT().Update(gomock.Any(), restored(domain.ArticleStatusScheduled)).Return(nil)
+				return repo
+			},
+			uid:     123,
+			publish: true,
+			wantId:  1,
+		},
+		{
+			name: "不是作者",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetRevision(gomock.Any(), int64(1), int64(2)).Return(rev, nil)
+				return repo
+			},
+			uid:     456,
+			wantErr: ErrIncorrectArticleAuthor,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewArticleService(tc.mock(ctrl), &logger.NopLogger{}, &fakeArticleProducer{},
+				moderation.NewModerator(moderation.NewWordFilter(nil),
+					moderation.Policy{ApproveBelow: 0.3, RejectAbove: 0.8}))
+			id, err := svc.RestoreRevision(context.Background(), tc.uid, 1, 2, tc.publish)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+		})
+	}
+}
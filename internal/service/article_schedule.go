@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"webooktrial/internal/domain"
+	"webooktrial/pkg/logger"
+)
+
+var ErrInvalidArticleSchedule = errors.New("定时撤回的时间必须晚于发表时间")
+
+// scheduleBatchSize 每次从数据库里面捞多少篇到期的文章
+const scheduleBatchSize = 100
+
+func (a *ArticleCoreService) ExecuteSchedules(ctx context.Context, now time.Time) error {
+	failed := a.publishDue(ctx, now) + a.unpublishDue(ctx, now)
+	if failed > 0 {
+		// 失败的文章状态没有变，下一次调度的时候还会再捞出来
+		return fmt.Errorf("定时发表或撤回失败 %d 篇", failed)
+	}
+	return ctx.Err()
+}
+
+func (a *ArticleCoreService) publishDue(ctx context.Context, now time.Time) int {
+	failed := 0
+	for ctx.Err() == nil {
+		arts, err := a.repo.ListDueScheduled(ctx, now, scheduleBatchSize)
+		if err != nil {
+			a.l.Error("查询定时发表的文章失败", logger.Error(err))
+			return failed + 1
+		}
+		for _, art := range arts {
+			art.Status = domain.ArticleStatusPublished
+			_, err = a.repo.Sync(ctx, art)
+			if err != nil {
+				failed++
+				a.l.Error("定时发表文章失败", logger.Int64("aid", art.Id), logger.Error(err))
+				continue
+			}
+			a.producePublishedEvent(ctx, art)
+		}
+		// 有失败的话，继续捞会一直捞到同样的文章
+		if len(arts) < scheduleBatchSize || failed > 0 {
+			break
+		}
+	}
+	return failed
+}
+
+func (a *ArticleCoreService) unpublishDue(ctx context.Context, now time.Time) int {
+	failed := 0
+	for ctx.Err() == nil {
+		arts, err := a.repo.ListDueUnpublish(ctx, now, scheduleBatchSize)
+		if err != nil {
+			a.l.Error("查询定时撤回的文章失败", logger.Error(err))
+			return failed + 1
+		}
+		for _, art := range arts {
+			// 缓存和热榜在 repository 里面处理
+			err = a.repo.SyncStatus(ctx, art.Id, art.Author.Id, domain.ArticleStatusPrivate)
+			if err != nil {
+				failed++
+				a.l.Error("定时撤回文章失败", logger.Int64("aid", art.Id), logger.Error(err))
+				continue
+			}
+			a.produceWithdrawnEvent(ctx, art.Id, art.Author.Id)
+		}
+		if len(arts) < scheduleBatchSize || failed > 0 {
+			break
+		}
+	}
+	return failed
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"webooktrial/internal/domain"
+	"webooktrial/pkg/pagination"
+)
+
+var ErrInvalidArticleTags = errors.New("标签或者分类不合法")
+
+const (
+	// maxTagCnt 一篇文章最多多少个标签
+	maxTagCnt = 10
+	// maxTagLen 标签和分类的最大长度，按照字符算
+	maxTagLen = 32
+)
+
+func (a *ArticleCoreService) ListPubByTag(ctx context.Context, tag string, cursor pagination.Cursor, limit int) ([]domain.Article, error) {
+	return a.repo.ListPubByTag(ctx, strings.TrimSpace(tag), cursor, limit)
+}
+
+func (a *ArticleCoreService) ListTags(ctx context.Context, cursor pagination.Cursor, limit int) ([]domain.Tag, error) {
+	return a.repo.ListTags(ctx, cursor, limit)
+}
+
+func (a *ArticleCoreService) ListRelated(ctx context.Context, id int64, limit int) ([]domain.Article, error) {
+	return a.repo.ListRelated(ctx, id, limit)
+}
+
+// normalizeTaxonomy 去掉标签两边的空白和重复的标签，并且校验长度和数量
+// Tags 为 nil 的时候表示不修改标签，要保持 nil
+func normalizeTaxonomy(art domain.Article) (domain.Article, error) {
+	art.Category = strings.TrimSpace(art.Category)
+	if utf8.RuneCountInString(art.Category) > maxTagLen {
+		return art, ErrInvalidArticleTags
+	}
+	if art.Tags == nil {
+		return art, nil
+	}
+	tags := make([]string, 0, len(art.Tags))
+	seen := make(map[string]struct{}, len(art.Tags))
+	for _, tag := range art.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLen {
+			return art, ErrInvalidArticleTags
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxTagCnt {
+		return art, ErrInvalidArticleTags
+	}
+	art.Tags = tags
+	return art, nil
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"webooktrial/internal/domain"
+)
+
+func TestNormalizeTaxonomy(t *testing.T) {
+	testCases := []struct {
+		name    string
+		art     domain.Article
+		want    domain.Article
+		wantErr error
+	}{
+		{
+			name: "不修改标签",
+			art:  domain.Article{Category: " 后端 "},
+			want: domain.Article{Category: "后端"},
+		},
+		{
+			name: "去掉空白和重复",
+			art:  domain.Article{Tags: []string{" Go", "Go ", "", "MySQL"}},
+			want: domain.Article{Tags: []string{"Go", "MySQL"}},
+		},
+		{
+			name: "清空标签",
+			art:  domain.Article{Tags: []string{}},
+			want: domain.Article{Tags: []string{}},
+		},
+		{
+			name:    "标签太多",
+			art:     domain.Article{Tags: strings.Split("a,b,c,d,e,f,g,h,i,j,k", ",")},
+			wantErr: ErrInvalidArticleTags,
+		},
+		{
+			name:    "标签太长",
+			art:     domain.Article{Tags: []string{strings.Repeat("标", 33)}},
+			wantErr: ErrInvalidArticleTags,
+		},
+		{
+			name:    "分类太长",
+			art:     domain.Article{Category: strings.Repeat("类", 33)},
+			wantErr: ErrInvalidArticleTags,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := normalizeTaxonomy(tc.art)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.want, res)
+		})
+	}
+}
@@ -9,9 +9,11 @@ import (
 	"go.uber.org/mock/gomock"
 
 	"webooktrial/internal/domain"
+	events "webooktrial/internal/events/article"
 	"webooktrial/internal/repository/article"
 	artrepomocks "webooktrial/internal/repository/article/mocks"
 	"webooktrial/pkg/logger"
+	"webooktrial/pkg/moderation"
 )
 
 func Test_articleService_Publish(t *testing.T) {
@@ -220,3 +222,99 @@ func Test_articleService_Publish(t *testing.T) {
 		})
 	}
 }
+
+// fakeArticleProducer 只记录发表事件
+type fakeArticleProducer struct {
+	events.Producer
+	published []int64
+}
+
+func (f *fakeArticleProducer) ProducePublishedEvent(ctx context.Context, evt events.PublishedEvent) error {
+	f.published = append(f.published, evt.Aid)
+	return nil
+}
+
+type errModerationChecker struct{}
+
+func (errModerationChecker) Check(ctx context.Context, text string) (moderation.Result, error) {
+	return moderation.Result{}, errors.New("审核服务不可用")
+}
+
+func TestArticleCoreService_PublishModeration(t *testing.T) {
+	policy := moderation.Policy{ApproveBelow: 0.3, RejectAbove: 0.8}
+	filter := moderation.NewWordFilter([]moderation.Word{
+		{Text: "广告", Score: 0.5},
+		{Text: "违禁", Score: 0.9},
+	})
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) article.ArticleRepository
+		checker moderation.Checker
+
+		art domain.Article
+
+		wantId        int64
+		wantErr       error
+		wantPublished []int64
+	}{
+		{
+			name: "标题违规，拒绝发表",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				// 不会保存
+				return artrepomocks.NewMockArticleRepository(ctrl)
+			},
+			checker: filter,
+			art: domain.Article{
+				Title:   "违禁标题",
+				Content: "我的内容",
+				Author:  domain.Author{Id: 123},
+			},
+			wantErr: ErrArticleContentRejected,
+		},
+		{
+			name: "需要复查的先发表",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().Sync(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+				return repo
+			},
+			checker: filter,
+			art: domain.Article{
+				Title:   "我的标题",
+				Content: "有一点广告",
+				Author:  domain.Author{Id: 123},
+			},
+			wantId:        1,
+			wantPublished: []int64{1},
+		},
+		{
+			name: "审核出错，放行",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().Sync(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+				return repo
+			},
+			checker: errModerationChecker{},
+			art: domain.Article{
+				Title:   "违禁标题",
+				Content: "我的内容",
+				Author:  domain.Author{Id: 123},
+			},
+			wantId:        1,
+			wantPublished: []int64{1},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			producer := &fakeArticleProducer{}
+			svc := NewArticleService(tc.mock(ctrl), &logger.NopLogger{}, producer,
+				moderation.NewModerator(tc.checker, policy))
+			id, err := svc.Publish(context.Background(), tc.art)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+			assert.Equal(t, tc.wantPublished, producer.published)
+		})
+	}
+}
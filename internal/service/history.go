@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+
+	"webooktrial/internal/domain"
+	"webooktrial/internal/repository"
+	"webooktrial/internal/repository/article"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/pagination"
+)
+
+type HistoryService interface {
+	// List 我的阅读记录，按照阅读时间倒序，会带上文章的标题和作者
+	List(ctx context.Context, uid int64, cursor pagination.Cursor, limit int) ([]domain.HistoryRecord, error)
+	Clear(ctx context.Context, uid int64) error
+}
+
+type historyService struct {
+	repo    repository.HistoryRecordRepository
+	artRepo article.ArticleRepository
+	l       logger.LoggerV1
+}
+
+func NewHistoryService(repo repository.HistoryRecordRepository,
+	artRepo article.ArticleRepository, l logger.LoggerV1) HistoryService {
+	return &historyService{repo: repo, artRepo: artRepo, l: l}
+}
+
+func (h *historyService) List(ctx context.Context, uid int64,
+	cursor pagination.Cursor, limit int) ([]domain.HistoryRecord, error) {
+	records, err := h.repo.List(ctx, uid, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	// 线上库的文章有两级缓存，一篇一篇查问题不大
+	var eg errgroup.Group
+	for i := range records {
+		i := i
+		eg.Go(func() error {
+			art, er := h.artRepo.GetPublishedById(ctx, records[i].Article.Id)
+			if er != nil {
+				// 文章被删除或者撤回了，记录还是保留，只是没有标题
+				h.l.Debug("阅读记录对应的文章查询失败",
+					logger.Int64("aid", records[i].Article.Id),
+					logger.Error(er))
+				return nil
+			}
+			if art.Status == domain.ArticleStatusPublished {
+				art.Content = ""
+				records[i].Article = art
+			}
+			return nil
+		})
+	}
+	_ = eg.Wait()
+	return records, nil
+}
+
+func (h *historyService) Clear(ctx context.Context, uid int64) error {
+	return h.repo.Clear(ctx, uid)
+}
@@ -9,7 +9,13 @@ import (
 	"webooktrial/pkg/logger"
 )
 
+// ErrNoMoreJob 现在没有到时间的任务
+var ErrNoMoreJob = repository.ErrNoMoreJob
+
 type JobService interface {
+	// Add 启动的时候用来保证任务存在，已经有同名的任务就什么都不做
+	Add(ctx context.Context, j domain.Job) error
+	// Preempt 没有可以抢的任务返回 ErrNoMoreJob
 	Preempt(ctx context.Context) (domain.Job, error)
 	ResetNextTime(ctx context.Context, j domain.Job) error
 	// 返回一个释放的方法，然后调用者调用
@@ -24,8 +30,24 @@ type cronJobService struct {
 	l               logger.LoggerV1
 }
 
+func NewCronJobService(repo repository.JobRepository, l logger.LoggerV1) JobService {
+	return &cronJobService{
+		repo:            repo,
+		refreshInterval: time.Minute,
+		l:               l,
+	}
+}
+
+func (c *cronJobService) Add(ctx context.Context, j domain.Job) error {
+	return c.repo.Add(ctx, j)
+}
+
 func (c *cronJobService) Preempt(ctx context.Context) (domain.Job, error) {
 	j, err := c.repo.Preempt(ctx)
+	if err != nil {
+		// 没有抢到，也就不用续约
+		return domain.Job{}, err
+	}
 	ticker := time.NewTicker(c.refreshInterval)
 	go func() {
 		for range ticker.C {
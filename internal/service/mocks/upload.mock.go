@@ -0,0 +1,99 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./upload.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./upload.go -package=svcmocks -destination=mocks/upload.mock.go UploadService
+//
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	domain "webooktrial/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUploadService is a mock of UploadService interface.
+type MockUploadService struct {
+	ctrl     *gomock.Controller
+	recorder *MockUploadServiceMockRecorder
+}
+
+// MockUploadServiceMockRecorder is the mock recorder for MockUploadService.
+type MockUploadServiceMockRecorder struct {
+	mock *MockUploadService
+}
+
+// NewMockUploadService creates a new mock instance.
+func NewMockUploadService(ctrl *gomock.Controller) *MockUploadService {
+	mock := &MockUploadService{ctrl: ctrl}
+	mock.recorder = &MockUploadServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUploadService) EXPECT() *MockUploadServiceMockRecorder {
+	return m.recorder
+}
+
+// CleanUnreferenced mocks base method.
+func (m *MockUploadService) CleanUnreferenced(ctx context.Context, before time.Time, limit int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CleanUnreferenced", ctx, before, limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CleanUnreferenced indicates an expected call of CleanUnreferenced.
+func (mr *MockUploadServiceMockRecorder) CleanUnreferenced(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanUnreferenced", reflect.TypeOf((*MockUploadService)(nil).CleanUnreferenced), ctx, before, limit)
+}
+
+// ReleaseArticle mocks base method.
+func (m *MockUploadService) ReleaseArticle(ctx context.Context, aid int64, version domain.UploadRefVersion) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseArticle", ctx, aid, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseArticle indicates an expected call of ReleaseArticle.
+func (mr *MockUploadServiceMockRecorder) ReleaseArticle(ctx, aid, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseArticle", reflect.TypeOf((*MockUploadService)(nil).ReleaseArticle), ctx, aid, version)
+}
+
+// SyncArticleRefs mocks base method.
+func (m *MockUploadService) SyncArticleRefs(ctx context.Context, uid, aid int64, version domain.UploadRefVersion, content string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncArticleRefs", ctx, uid, aid, version, content)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncArticleRefs indicates an expected call of SyncArticleRefs.
+func (mr *MockUploadServiceMockRecorder) SyncArticleRefs(ctx, uid, aid, version, content any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncArticleRefs", reflect.TypeOf((*MockUploadService)(nil).SyncArticleRefs), ctx, uid, aid, version, content)
+}
+
+// UploadImage mocks base method.
+func (m *MockUploadService) UploadImage(ctx context.Context, uid int64, data []byte) (domain.Upload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadImage", ctx, uid, data)
+	ret0, _ := ret[0].(domain.Upload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadImage indicates an expected call of UploadImage.
+func (mr *MockUploadServiceMockRecorder) UploadImage(ctx, uid, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadImage", reflect.TypeOf((*MockUploadService)(nil).UploadImage), ctx, uid, data)
+}
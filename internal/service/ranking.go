@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"math"
+	"slices"
 	"time"
 
 	"github.com/ecodeclub/ekit/queue"
@@ -12,10 +13,16 @@ import (
 	intrv1 "webooktrial/api/proto/gen/intr/v1"
 	"webooktrial/internal/domain"
 	"webooktrial/internal/repository"
+	"webooktrial/pkg/pagination"
 )
 
 type RankingService interface {
 	TopN(ctx context.Context) error
+	// TopNByTag 计算某个标签下的热榜
+	TopNByTag(ctx context.Context, tag string) error
+	// TopNHotTags 给文章最多的几个标签计算热榜
+	TopNHotTags(ctx context.Context) error
+	GetTopNByTag(ctx context.Context, tag string) ([]domain.Article, error)
 	//TopN(ctx context.Context, n int64) error
 	//TopN(ctx context.Context, n int64) ([]domain.Article, error)
 }
@@ -26,6 +33,8 @@ type BatchRankingService struct {
 	repo      repository.RankingRepository
 	batchSize int
 	n         int
+	// hotTagCnt 要计算热榜的标签数量
+	hotTagCnt int
 	// scoreFunc 不能返回负数
 	scoreFunc func(t time.Time, likeCnt int64) float64
 }
@@ -38,6 +47,7 @@ func NewBatchRankingService(artSvc ArticleService,
 		intrSvc:   intrSvc,
 		batchSize: 100,
 		n:         100,
+		hotTagCnt: 20,
 		repo:      repo,
 		scoreFunc: func(t time.Time, likeCnt int64) float64 {
 			sec := time.Since(t).Seconds()
@@ -56,11 +66,63 @@ func (b *BatchRankingService) TopN(ctx context.Context) error {
 	return b.repo.ReplaceTopN(ctx, arts)
 }
 
+func (b *BatchRankingService) TopNByTag(ctx context.Context, tag string) error {
+	var cursor pagination.Cursor
+	arts, err := b.topNFrom(ctx, func(ctx context.Context, now time.Time) ([]domain.Article, error) {
+		// 和 topN 一样按照更新时间翻页，七天之前的就不用再取了
+		res, err := b.artSvc.ListPubByTag(ctx, tag, cursor, b.batchSize)
+		if len(res) > 0 {
+			last := res[len(res)-1]
+			cursor = pagination.Cursor{Key: last.Utime.UnixMilli(), Id: last.Id}
+		}
+		return res, err
+	})
+	if err != nil {
+		return err
+	}
+	return b.repo.ReplaceTopNByTag(ctx, tag, arts)
+}
+
+func (b *BatchRankingService) TopNHotTags(ctx context.Context) error {
+	tags, err := b.artSvc.ListTags(ctx, pagination.Cursor{}, b.hotTagCnt)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		// 一个标签失败了就整体失败，下一次调度的时候重来
+		err = b.TopNByTag(ctx, tag.Name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *BatchRankingService) GetTopNByTag(ctx context.Context, tag string) ([]domain.Article, error) {
+	return b.repo.GetTopNByTag(ctx, tag)
+}
+
 func (b *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error) {
+	var cursor pagination.Cursor
+	return b.topNFrom(ctx, func(ctx context.Context, now time.Time) ([]domain.Article, error) {
+		if cursor.IsZero() {
+			// 第一批从 now 开始往前取
+			cursor.Key = now.UnixMilli()
+		}
+		res, err := b.artSvc.ListPubByCursor(ctx, cursor, b.batchSize)
+		if len(res) > 0 {
+			last := res[len(res)-1]
+			cursor = pagination.Cursor{Key: last.Utime.UnixMilli(), Id: last.Id}
+		}
+		return res, err
+	})
+}
+
+// topNFrom 从 next 里面一批一批地取文章，计算热榜，翻页的位置由 next 自己维护
+func (b *BatchRankingService) topNFrom(ctx context.Context,
+	next func(ctx context.Context, now time.Time) ([]domain.Article, error)) ([]domain.Article, error) {
 	// 只取七天以内的数据
 	now := time.Now()
-	// 先拿一批数据
-	offset := 0
 	type Score struct {
 		art   domain.Article
 		score float64
@@ -77,10 +139,14 @@ func (b *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error
 			}
 		})
 	for {
-		arts, err := b.artSvc.ListPub(ctx, now, offset, b.batchSize)
+		arts, err := next(ctx, now)
 		if err != nil {
 			return nil, err
 		}
+		if len(arts) == 0 {
+			// 没有文章的标签，热榜就是空的
+			break
+		}
 		ids := slice.Map[domain.Article, int64](arts,
 			func(idx int, src domain.Article) int64 {
 				return src.Id
@@ -92,18 +158,14 @@ func (b *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error
 		if err != nil {
 			return nil, err
 		}
-
-		if len(intrs.Intrs) == 0 {
-			return nil, errors.New("没有数据")
-		}
 		// 合并计算 score
 		// 排序
 		for _, art := range arts {
-			intr := intrs.Intrs[art.Id]
-			//if !ok {
-			//	// 都没有，肯定不可能是热榜
-			//	continue
-			//}
+			intr, ok := intrs.Intrs[art.Id]
+			if !ok || intr == nil {
+				// 都没有，肯定不可能是热榜
+				continue
+			}
 			score := b.scoreFunc(art.Utime, intr.LikeCnt)
 			err = topN.Enqueue(Score{
 				art:   art,
@@ -123,18 +185,18 @@ func (b *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error
 			// 当前批次为取满或者已经取到一周之前的数据，说明可以中断计算热榜
 			break
 		}
-		// 更新 offset
-		offset = offset + len(arts)
 	}
-	// 最后得出结果
-	res := make([]domain.Article, b.n)
-	for i := b.n - 1; i >= 0; i-- {
+	// 最后得出结果，不够 n 篇的时候不能用零值凑数
+	res := make([]domain.Article, 0, b.n)
+	for {
 		val, err := topN.Dequeue()
 		if err != nil {
 			// 说明已经取完
 			break
 		}
-		res[i] = val.art
+		res = append(res, val.art)
 	}
+	// 出队是分数从低到高
+	slices.Reverse(res)
 	return res, nil
 }
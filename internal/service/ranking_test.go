@@ -6,14 +6,29 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
 	intrv1 "webooktrial/api/proto/gen/intr/v1"
-	domain2 "webooktrial/interactive/domain"
+	intrmocks "webooktrial/api/proto/gen/intr/v1/mocks"
 	"webooktrial/internal/domain"
+	repomocks "webooktrial/internal/repository/mocks"
 	svcmocks "webooktrial/internal/service/mocks"
+	"webooktrial/pkg/pagination"
 )
 
+func newTestRankingService(artSvc ArticleService, intrSvc intrv1.InteractiveServiceClient,
+	repo *repomocks.MockRankingRepository) *BatchRankingService {
+	svc := NewBatchRankingService(artSvc, intrSvc, repo).(*BatchRankingService)
+	// 为了测试
+	svc.batchSize = 3
+	svc.n = 3
+	svc.scoreFunc = func(t time.Time, likeCnt int64) float64 {
+		return float64(likeCnt)
+	}
+	return svc
+}
+
 func TestRankingTopN(t *testing.T) {
 	now := time.Now()
 	testCases := []struct {
@@ -26,29 +41,26 @@ func TestRankingTopN(t *testing.T) {
 	}{
 		{
 			name: "计算成功",
-			// 怎么模拟我的数据？
 			mock: func(ctrl *gomock.Controller) (ArticleService, intrv1.InteractiveServiceClient) {
 				artSvc := svcmocks.NewMockArticleService(ctrl)
-				// 最简单，一批就搞完
-				artSvc.EXPECT().ListPub(gomock.Any(), gomock.Any(), 0, 3).
+				artSvc.EXPECT().ListPubByCursor(gomock.Any(), gomock.Any(), 3).
 					Return([]domain.Article{
 						{Id: 1, Utime: now, Ctime: now},
 						{Id: 2, Utime: now, Ctime: now},
 						{Id: 3, Utime: now, Ctime: now},
 					}, nil)
-				artSvc.EXPECT().ListPub(gomock.Any(), gomock.Any(), 3, 3).
+				// 第二批从上一批最后一篇文章后面开始
+				artSvc.EXPECT().ListPubByCursor(gomock.Any(),
+					pagination.Cursor{Key: now.UnixMilli(), Id: 3}, 3).
 					Return([]domain.Article{}, nil)
-				intrSvc := svcmocks.NewMockInteractiveService(ctrl)
-				intrSvc.EXPECT().GetByIds(gomock.Any(),
-					"article", []int64{1, 2, 3}).
-					Return(map[int64]domain2.Interactive{
-						1: {BizId: 1, LikeCnt: 1},
-						2: {BizId: 2, LikeCnt: 2},
-						3: {BizId: 3, LikeCnt: 3},
-					}, nil)
-				intrSvc.EXPECT().GetByIds(gomock.Any(),
-					"article", []int64{}).
-					Return(map[int64]domain2.Interactive{}, nil)
+				intrSvc := intrmocks.NewMockInteractiveServiceClient(ctrl)
+				intrSvc.EXPECT().GetByIds(gomock.Any(), &intrv1.GetByIdsRequest{
+					Biz: "article", Ids: []int64{1, 2, 3},
+				}).Return(&intrv1.GetByIdsResponse{Intrs: map[int64]*intrv1.Interactive{
+					1: {BizId: 1, LikeCnt: 1},
+					2: {BizId: 2, LikeCnt: 2},
+					3: {BizId: 3, LikeCnt: 3},
+				}}, nil)
 				return artSvc, intrSvc
 			},
 			wantArts: []domain.Article{
@@ -63,16 +75,109 @@ func TestRankingTopN(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			artSvc, intrSvc := tc.mock(ctrl)
-			svc := NewBatchRankingService(artSvc, intrSvc).(*BatchRankingService)
-			// 为了测试
-			svc.batchSize = 3
-			svc.n = 3
-			svc.scoreFunc = func(t time.Time, likeCnt int64) float64 {
-				return float64(likeCnt)
-			}
+			svc := newTestRankingService(artSvc, intrSvc, repomocks.NewMockRankingRepository(ctrl))
 			arts, err := svc.topN(context.Background())
 			assert.Equal(t, tc.wantErr, err)
 			assert.Equal(t, tc.wantArts, arts)
 		})
 	}
 }
+
+func TestBatchRankingService_TopNByTag(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-8 * 24 * time.Hour)
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (ArticleService,
+			intrv1.InteractiveServiceClient, *repomocks.MockRankingRepository)
+		wantErr error
+	}{
+		{
+			name: "没有互动数据的文章跳过，不够 n 篇不补零值",
+			mock: func(ctrl *gomock.Controller) (ArticleService,
+				intrv1.InteractiveServiceClient, *repomocks.MockRankingRepository) {
+				artSvc := svcmocks.NewMockArticleService(ctrl)
+				artSvc.EXPECT().ListPubByTag(gomock.Any(), "go", pagination.Cursor{}, 3).
+					Return([]domain.Article{{Id: 5, Utime: now}, {Id: 4, Utime: now}}, nil)
+				intrSvc := intrmocks.NewMockInteractiveServiceClient(ctrl)
+				intrSvc.EXPECT().GetByIds(gomock.Any(), gomock.Any()).
+					Return(&intrv1.GetByIdsResponse{Intrs: map[int64]*intrv1.Interactive{
+						4: {BizId: 4, LikeCnt: 4},
+					}}, nil)
+				repo := repomocks.NewMockRankingRepository(ctrl)
+				repo.EXPECT().ReplaceTopNByTag(gomock.Any(), "go",
+					[]domain.Article{{Id: 4, Utime: now}}).Return(nil)
+				return artSvc, intrSvc, repo
+			},
+		},
+		{
+			name: "按照更新时间翻页，取到七天之前就停",
+			mock: func(ctrl *gomock.Controller) (ArticleService,
+				intrv1.InteractiveServiceClient, *repomocks.MockRankingRepository) {
+				artSvc := svcmocks.NewMockArticleService(ctrl)
+				artSvc.EXPECT().ListPubByTag(gomock.Any(), "go", pagination.Cursor{}, 3).
+					Return([]domain.Article{{Id: 1, Utime: now}, {Id: 9, Utime: now}, {Id: 2, Utime: now}}, nil)
+				artSvc.EXPECT().ListPubByTag(gomock.Any(), "go",
+					pagination.Cursor{Key: now.UnixMilli(), Id: 2}, 3).
+					Return([]domain.Article{{Id: 3, Utime: now}, {Id: 7, Utime: old}, {Id: 8, Utime: old}}, nil)
+				intrSvc := intrmocks.NewMockInteractiveServiceClient(ctrl)
+				intrSvc.EXPECT().GetByIds(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, req *intrv1.GetByIdsRequest,
+						opts ...any) (*intrv1.GetByIdsResponse, error) {
+						res := make(map[int64]*intrv1.Interactive, len(req.Ids))
+						for _, id := range req.Ids {
+							res[id] = &intrv1.Interactive{BizId: id, LikeCnt: id}
+						}
+						return &intrv1.GetByIdsResponse{Intrs: res}, nil
+					}).Times(2)
+				repo := repomocks.NewMockRankingRepository(ctrl)
+				repo.EXPECT().ReplaceTopNByTag(gomock.Any(), "go", []domain.Article{
+					{Id: 9, Utime: now}, {Id: 8, Utime: old}, {Id: 7, Utime: old},
+				}).Return(nil)
+				return artSvc, intrSvc, repo
+			},
+		},
+		{
+			name: "标签下没有文章，热榜是空的",
+			mock: func(ctrl *gomock.Controller) (ArticleService,
+				intrv1.InteractiveServiceClient, *repomocks.MockRankingRepository) {
+				artSvc := svcmocks.NewMockArticleService(ctrl)
+				artSvc.EXPECT().ListPubByTag(gomock.Any(), "go", pagination.Cursor{}, 3).
+					Return(nil, nil)
+				repo := repomocks.NewMockRankingRepository(ctrl)
+				repo.EXPECT().ReplaceTopNByTag(gomock.Any(), "go", []domain.Article{}).Return(nil)
+				return artSvc, intrmocks.NewMockInteractiveServiceClient(ctrl), repo
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			artSvc, intrSvc, repo := tc.mock(ctrl)
+			svc := newTestRankingService(artSvc, intrSvc, repo)
+			err := svc.TopNByTag(context.Background(), "go")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestBatchRankingService_TopNHotTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	artSvc := svcmocks.NewMockArticleService(ctrl)
+	artSvc.EXPECT().ListTags(gomock.Any(), pagination.Cursor{}, 20).
+		Return([]domain.Tag{{Name: "empty"}, {Name: "go"}}, nil)
+	// 一个标签没有文章，不影响后面的标签
+	artSvc.EXPECT().ListPubByTag(gomock.Any(), "empty", pagination.Cursor{}, 3).Return(nil, nil)
+	artSvc.EXPECT().ListPubByTag(gomock.Any(), "go", pagination.Cursor{}, 3).
+		Return([]domain.Article{{Id: 1, Utime: time.Now()}}, nil)
+	intrSvc := intrmocks.NewMockInteractiveServiceClient(ctrl)
+	intrSvc.EXPECT().GetByIds(gomock.Any(), gomock.Any()).
+		Return(&intrv1.GetByIdsResponse{Intrs: map[int64]*intrv1.Interactive{1: {BizId: 1, LikeCnt: 1}}}, nil)
+	repo := repomocks.NewMockRankingRepository(ctrl)
+	repo.EXPECT().ReplaceTopNByTag(gomock.Any(), "empty", []domain.Article{}).Return(nil)
+	repo.EXPECT().ReplaceTopNByTag(gomock.Any(), "go", gomock.Len(1)).Return(nil)
+	svc := newTestRankingService(artSvc, intrSvc, repo)
+	require.NoError(t, svc.TopNHotTags(context.Background()))
+}
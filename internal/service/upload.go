@@ -0,0 +1,222 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	// 注册 GIF 的解码器
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+
+	"webooktrial/internal/domain"
+	"webooktrial/internal/repository"
+	"webooktrial/pkg/imagex"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/objectstore"
+)
+
+var (
+	ErrUploadTooLarge        = errors.New("文件太大")
+	ErrUploadUnsupportedType = errors.New("不支持的文件类型")
+	ErrUploadQuotaExceeded   = repository.ErrUploadQuotaExceeded
+)
+
+// imageExts 只支持标准库能解码的格式，按照内容判断，不相信文件名和请求头
+var imageExts = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// uploadKeyPattern 文章内容里面引用的图片，URL 的前缀由 web 层决定，这里只认 key
+var uploadKeyPattern = regexp.MustCompile(`images/([0-9a-f]{64})`)
+
+type UploadConfig struct {
+	// MaxSize 单个文件的大小上限
+	MaxSize int64 `yaml:"maxSize"`
+	// Quota 每个人总共可以用的空间
+	Quota int64 `yaml:"quota"`
+	// MaxPixels 宽乘高的上限，防止解码的时候把内存撑爆
+	MaxPixels int `yaml:"maxPixels"`
+	// ThumbSize 缩略图的长边
+	ThumbSize int `yaml:"thumbSize"`
+}
+
+//go:generate mockgen -source=./upload.go -package=svcmocks -destination=mocks/upload.mock.go UploadService
+type UploadService interface {
+	// UploadImage 同一个人重复上传同样的图片，返回已有的，也不会占用空间
+	UploadImage(ctx context.Context, uid int64, data []byte) (domain.Upload, error)
+	// SyncArticleRefs 保存文章之后调用，记录文章的 version 版本的内容里面引用了哪些图片
+	SyncArticleRefs(ctx context.Context, uid, aid int64, version domain.UploadRefVersion, content string) error
+	// ReleaseArticle 撤回或者删除文章之后调用，只释放 version 版本的引用，
+	// 没有被其它版本或者其它文章引用的图片在清理的时候就会被删掉
+	ReleaseArticle(ctx context.Context, aid int64, version domain.UploadRefVersion) error
+	// CleanUnreferenced 删除 before 之前上传，并且没有被任何文章引用的图片，返回删除的个数
+	CleanUnreferenced(ctx context.Context, before time.Time, limit int) (int, error)
+}
+
+type uploadService struct {
+	repo  repository.UploadRepository
+	store objectstore.ObjectStore
+	cfg   UploadConfig
+	l     logger.LoggerV1
+}
+
+func NewUploadService(repo repository.UploadRepository,
+	store objectstore.ObjectStore, cfg UploadConfig, l logger.LoggerV1) UploadService {
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = 5 << 20
+	}
+	if cfg.Quota <= 0 {
+		cfg.Quota = 200 << 20
+	}
+	if cfg.MaxPixels <= 0 {
+		cfg.MaxPixels = 40_000_000
+	}
+	if cfg.ThumbSize <= 0 {
+		cfg.ThumbSize = 320
+	}
+	return &uploadService{repo: repo, store: store, cfg: cfg, l: l}
+}
+
+func (u *uploadService) UploadImage(ctx context.Context, uid int64, data []byte) (domain.Upload, error) {
+	if int64(len(data)) > u.cfg.MaxSize {
+		return domain.Upload{}, ErrUploadTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := imageExts[contentType]
+	if !ok {
+		return domain.Upload{}, ErrUploadUnsupportedType
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	existing, err := u.repo.FindByHashes(ctx, uid, []string{hash})
+	if err != nil {
+		return domain.Upload{}, err
+	}
+	if len(existing) > 0 {
+		// 之前传过但是一直没有被引用的，可能马上就要被清理了，重新计时
+		ok, er := u.repo.Touch(ctx, existing[0].Id)
+		if er != nil {
+			return domain.Upload{}, er
+		}
+		if ok {
+			return existing[0], nil
+		}
+		// 刚好被清理掉了，当成新的上传
+	}
+	// 这里只是提前拦住，不用上传到对象存储，真正的检查在写数据库的时候
+	used, err := u.repo.UsedSize(ctx, uid)
+	if err != nil {
+		return domain.Upload{}, err
+	}
+	if used+int64(len(data)) > u.cfg.Quota {
+		return domain.Upload{}, ErrUploadQuotaExceeded
+	}
+	// 先看尺寸，太大的图片不解码
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width*cfg.Height > u.cfg.MaxPixels {
+		return domain.Upload{}, ErrUploadUnsupportedType
+	}
+	thumb, thumbType, err := u.thumbnail(data, contentType)
+	if err != nil {
+		return domain.Upload{}, ErrUploadUnsupportedType
+	}
+	upload := domain.Upload{
+		Uid:         uid,
+		Hash:        hash,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+		// key 只和内容有关，不同的人上传同样的内容只存一份
+		Key:      "images/" + hash + ext,
+		ThumbKey: "images/" + hash + "_thumb" + imageExts[thumbType],
+	}
+	// 先上传再写数据库，数据库失败了留下的对象会在清理的时候覆盖或者删掉
+	err = u.store.Put(ctx, upload.Key, data, contentType)
+	if err != nil {
+		return domain.Upload{}, err
+	}
+	err = u.store.Put(ctx, upload.ThumbKey, thumb, thumbType)
+	if err != nil {
+		return domain.Upload{}, err
+	}
+	return u.repo.Create(ctx, upload, u.cfg.Quota)
+}
+
+// thumbnail 有透明度的格式缩略图用 PNG，其它用 JPEG
+func (u *uploadService) thumbnail(data []byte, contentType string) ([]byte, string, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	thumb := imagex.Thumbnail(img, u.cfg.ThumbSize)
+	var buf bytes.Buffer
+	if contentType == "image/jpeg" {
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 85})
+		return buf.Bytes(), "image/jpeg", err
+	}
+	err = png.Encode(&buf, thumb)
+	return buf.Bytes(), "image/png", err
+}
+
+func (u *uploadService) SyncArticleRefs(ctx context.Context, uid, aid int64,
+	version domain.UploadRefVersion, content string) error {
+	matches := uploadKeyPattern.FindAllStringSubmatch(content, -1)
+	hashes := make([]string, 0, len(matches))
+	for _, m := range matches {
+		hashes = append(hashes, m[1])
+	}
+	// 只认自己上传的图片，引用别人的图片不会让它免于清理
+	uploads, err := u.repo.FindByHashes(ctx, uid, hashes)
+	if err != nil {
+		return err
+	}
+	return u.repo.SetArticleRefs(ctx, aid, version, slice.Map(uploads, func(idx int, src domain.Upload) int64 {
+		return src.Id
+	}))
+}
+
+func (u *uploadService) ReleaseArticle(ctx context.Context, aid int64, version domain.UploadRefVersion) error {
+	return u.repo.SetArticleRefs(ctx, aid, version, nil)
+}
+
+func (u *uploadService) CleanUnreferenced(ctx context.Context, before time.Time, limit int) (int, error) {
+	uploads, err := u.repo.ListUnreferenced(ctx, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	cnt := 0
+	for _, upload := range uploads {
+		deleted, remain, err := u.repo.Delete(ctx, upload.Id, before)
+		if err != nil {
+			return cnt, err
+		}
+		if !deleted {
+			continue
+		}
+		cnt++
+		if remain > 0 {
+			// 别人也上传了同样的内容，对象存储上的不能删
+			continue
+		}
+		for _, key := range []string{upload.Key, upload.ThumbKey} {
+			if er := u.store.Delete(ctx, key); er != nil {
+				u.l.Error("删除上传的图片失败",
+					logger.String("key", key),
+					logger.Error(er))
+			}
+		}
+	}
+	return cnt, nil
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"webooktrial/internal/domain"
+	"webooktrial/internal/repository"
+	repomocks "webooktrial/internal/repository/mocks"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/objectstore"
+)
+
+func testPNG(t *testing.T, w, h int) ([]byte, string) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))))
+	sum := sha256.Sum256(buf.Bytes())
+	return buf.Bytes(), hex.EncodeToString(sum[:])
+}
+
+func TestUploadService_UploadImage(t *testing.T) {
+	img, hash := testPNG(t, 400, 200)
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) repository.UploadRepository
+		cfg  UploadConfig
+		data []byte
+
+		wantUpload domain.Upload
+		wantErr    error
+		// 对象存储上应该有的 key
+		wantKeys []string
+	}{
+		{
+			name: "上传成功",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				repo := repomocks.NewMockUploadRepository(ctrl)
+				repo.EXPECT().FindByHashes(gomock.Any(), int64(123), []string{hash}).Return(nil, nil)
+				repo.EXPECT().UsedSize(gomock.Any(), int64(123)).Return(int64(0), nil)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any(), int64(200<<20)).
+					DoAndReturn(func(ctx context.Context, u domain.Upload, quota int64) (domain.Upload, error) {
+						u.Id = 1
+						return u, nil
+					})
+				return repo
+			},
+			data: img,
+			wantUpload: domain.Upload{
+				Id:          1,
+				Uid:         123,
+				Hash:        hash,
+				ContentType: "image/png",
+				Size:        int64(len(img)),
+				Width:       400,
+				Height:      200,
+				Key:         "images/" + hash + ".png",
+				ThumbKey:    "images/" + hash + "_thumb.png",
+			},
+			wantKeys: []string{"images/" + hash + ".png", "images/" + hash + "_thumb.png"},
+		},
+		{
+			name: "重复上传，直接返回",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				repo := repomocks.NewMockUploadRepository(ctrl)
+				repo.EXPECT().FindByHashes(gomock.Any(), int64(123), []string{hash}).
+					Return([]domain.Upload{{Id: 1, Uid: 123, Hash: hash}}, nil)
+				repo.EXPECT().Touch(gomock.Any(), int64(1)).Return(true, nil)
+				return repo
+			},
+			data:       img,
+			wantUpload: domain.Upload{Id: 1, Uid: 123, Hash: hash},
+		},
+		{
+			name: "重复上传，但是刚好被清理掉了",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				repo := repomocks.NewMockUploadRepository(ctrl)
+				repo.EXPECT().FindByHashes(gomock.Any(), int64(123), []string{hash}).
+					Return([]domain.Upload{{Id: 1, Uid: 123, Hash: hash}}, nil)
+				repo.EXPECT().Touch(gomock.Any(), int64(1)).Return(false, nil)
+				repo.EXPECT().UsedSize(gomock.Any(), int64(123)).Return(int64(0), nil)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any(), int64(200<<20)).
+					DoAndReturn(func(ctx context.Context, u domain.Upload, quota int64) (domain.Upload, error) {
+						u.Id = 2
+						return u, nil
+					})
+				return repo
+			},
+			data: img,
+			wantUpload: domain.Upload{
+				Id:          2,
+				Uid:         123,
+				Hash:        hash,
+				ContentType: "image/png",
+				Size:        int64(len(img)),
+				Width:       400,
+				Height:      200,
+				Key:         "images/" + hash + ".png",
+				ThumbKey:    "images/" + hash + "_thumb.png",
+			},
+			wantKeys: []string{"images/" + hash + ".png", "images/" + hash + "_thumb.png"},
+		},
+		{
+			name: "空间不够",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				repo := repomocks.NewMockUploadRepository(ctrl)
+				repo.EXPECT().FindByHashes(gomock.Any(), int64(123), []string{hash}).Return(nil, nil)
+				repo.EXPECT().UsedSize(gomock.Any(), int64(123)).Return(int64(1000), nil)
+				return repo
+			},
+			cfg:     UploadConfig{Quota: 1000},
+			data:    img,
+			wantErr: ErrUploadQuotaExceeded,
+		},
+		{
+			name: "并发上传，写数据库的时候空间不够",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				repo := repomocks.NewMockUploadRepository(ctrl)
+				repo.EXPECT().FindByHashes(gomock.Any(), int64(123), []string{hash}).Return(nil, nil)
+				repo.EXPECT().UsedSize(gomock.Any(), int64(123)).Return(int64(0), nil)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any(), int64(1<<20)).
+					Return(domain.Upload{}, repository.ErrUploadQuotaExceeded)
+				return repo
+			},
+			cfg:     UploadConfig{Quota: 1 << 20},
+			data:    img,
+			wantErr: ErrUploadQuotaExceeded,
+		},
+		{
+			name: "文件太大",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				return repomocks.NewMockUploadRepository(ctrl)
+			},
+			cfg:     UploadConfig{MaxSize: 10},
+			data:    img,
+			wantErr: ErrUploadTooLarge,
+		},
+		{
+			name: "不是图片",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				return repomocks.NewMockUploadRepository(ctrl)
+			},
+			data:    []byte("<html><script>alert(1)</script></html>"),
+			wantErr: ErrUploadUnsupportedType,
+		},
+		{
+			name: "像素太多",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				repo := repomocks.NewMockUploadRepository(ctrl)
+				repo.EXPECT().FindByHashes(gomock.Any(), int64(123), []string{hash}).Return(nil, nil)
+				repo.EXPECT().UsedSize(gomock.Any(), int64(123)).Return(int64(0), nil)
+				return repo
+			},
+			cfg:     UploadConfig{MaxPixels: 100},
+			data:    img,
+			wantErr: ErrUploadUnsupportedType,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store, err := objectstore.NewLocalStore(t.TempDir(), "http://localhost/objects", "secret")
+			require.NoError(t, err)
+			svc := NewUploadService(tc.mock(ctrl), store, tc.cfg, logger.NewNopLogger())
+			upload, err := svc.UploadImage(context.Background(), 123, tc.data)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantUpload, upload)
+			for _, key := range tc.wantKeys {
+				_, err = store.Get(context.Background(), key)
+				assert.NoError(t, err, key)
+			}
+		})
+	}
+}
+
+func TestUploadService_SyncArticleRefs(t *testing.T) {
+	h1 := hex.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	h2 := hex.EncodeToString(bytes.Repeat([]byte{2}, 32))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockUploadRepository(ctrl)
+	repo.EXPECT().FindByHashes(gomock.Any(), int64(123), []string{h1, h2}).
+		Return([]domain.Upload{{Id: 1, Hash: h1}, {Id: 2, Hash: h2}}, nil)
+	repo.EXPECT().SetArticleRefs(gomock.Any(), int64(10), domain.UploadRefVersionDraft, []int64{1, 2}).Return(nil)
+	svc := NewUploadService(repo, nil, UploadConfig{}, logger.NewNopLogger())
+	content := `<p>正文</p><img src="/uploads/images/` + h1 + `.png">` +
+		`![](https://cdn.example.com/uploads/images/` + h2 + `_thumb.jpg)`
+	assert.NoError(t, svc.SyncArticleRefs(context.Background(), 123, 10, domain.UploadRefVersionDraft, content))
+}
+
+func TestUploadService_ReleaseArticle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockUploadRepository(ctrl)
+	// 只释放线上库的引用，制作库的不动
+	repo.EXPECT().SetArticleRefs(gomock.Any(), int64(10), domain.UploadRefVersionPublished, []int64(nil)).Return(nil)
+	svc := NewUploadService(repo, nil, UploadConfig{}, logger.NewNopLogger())
+	assert.NoError(t, svc.ReleaseArticle(context.Background(), 10, domain.UploadRefVersionPublished))
+}
+
+func TestUploadService_CleanUnreferenced(t *testing.T) {
+	ctx := context.Background()
+	store, err := objectstore.NewLocalStore(t.TempDir(), "http://localhost/objects", "secret")
+	require.NoError(t, err)
+	uploads := []domain.Upload{
+		// 没有别人用，对象也要删掉
+		{Id: 1, Key: "images/a.png", ThumbKey: "images/a_thumb.png"},
+		// 别人也上传了，对象要留着
+		{Id: 2, Key: "images/b.png", ThumbKey: "images/b_thumb.png"},
+		// 扫描之后又被引用了
+		{Id: 3, Key: "images/c.png", ThumbKey: "images/c_thumb.png"},
+	}
+	for _, u := range uploads {
+		require.NoError(t, store.Put(ctx, u.Key, []byte("x"), "image/png"))
+		require.NoError(t, store.Put(ctx, u.ThumbKey, []byte("x"), "image/png"))
+	}
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	before := time.Now().Add(-time.Hour)
+	repo := repomocks.NewMockUploadRepository(ctrl)
+	repo.EXPECT().ListUnreferenced(gomock.Any(), before, 10).Return(uploads, nil)
+	repo.EXPECT().Delete(gomock.Any(), int64(1), before).Return(true, int64(0), nil)
+	repo.EXPECT().Delete(gomock.Any(), int64(2), before).Return(true, int64(1), nil)
+	repo.EXPECT().Delete(gomock.Any(), int64(3), before).Return(false, int64(0), nil)
+	svc := NewUploadService(repo, store, UploadConfig{}, logger.NewNopLogger())
+
+	cnt, err := svc.CleanUnreferenced(ctx, before, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, cnt)
+	_, err = store.Get(ctx, "images/a.png")
+	assert.True(t, errors.Is(err, objectstore.ErrObjectNotFound))
+	_, err = store.Get(ctx, "images/a_thumb.png")
+	assert.True(t, errors.Is(err, objectstore.ErrObjectNotFound))
+	for _, key := range []string{"images/b.png", "images/b_thumb.png", "images/c.png", "images/c_thumb.png"} {
+		_, err = store.Get(ctx, key)
+		assert.NoError(t, err, key)
+	}
+}
@@ -1,7 +1,9 @@
 package web
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
@@ -9,34 +11,54 @@ import (
 	"github.com/ecodeclub/ekit/slice"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	commentv1 "webooktrial/api/proto/gen/comment/v1"
 	intrv1 "webooktrial/api/proto/gen/intr/v1"
 	rewardv1 "webooktrial/api/proto/gen/reward/v1"
+	searchv1 "webooktrial/api/proto/gen/search/v1"
 	"webooktrial/internal/domain"
 	"webooktrial/internal/service"
 	ijwt "webooktrial/internal/web/jwt"
 	"webooktrial/pkg/ginx"
 	"webooktrial/pkg/logger"
+	"webooktrial/pkg/pagination"
 )
 
 var _ handler = (*ArticleHandler)(nil)
 
 type ArticleHandler struct {
-	svc       service.ArticleService
-	l         logger.LoggerV1
-	rewardSvc rewardv1.RewardServiceClient
-	intrSvc   intrv1.InteractiveServiceClient
-	biz       string
+	svc        service.ArticleService
+	rankingSvc service.RankingService
+	l          logger.LoggerV1
+	rewardSvc  rewardv1.RewardServiceClient
+	intrSvc    intrv1.InteractiveServiceClient
+	searchSvc  searchv1.SearchServiceClient
+	commentSvc commentv1.CommentServiceClient
+	historySvc service.HistoryService
+	uploadSvc  service.UploadService
+	biz        string
 }
 
 func NewArticleHandler(svc service.ArticleService,
 	l logger.LoggerV1,
-	intrSvc intrv1.InteractiveServiceClient) *ArticleHandler {
+	intrSvc intrv1.InteractiveServiceClient,
+	rankingSvc service.RankingService,
+	searchSvc searchv1.SearchServiceClient,
+	historySvc service.HistoryService,
+	uploadSvc service.UploadService,
+	commentSvc commentv1.CommentServiceClient) *ArticleHandler {
 	return &ArticleHandler{
-		svc:     svc,
-		l:       l,
-		biz:     "article",
-		intrSvc: intrSvc,
+		svc:        svc,
+		l:          l,
+		biz:        "article",
+		intrSvc:    intrSvc,
+		rankingSvc: rankingSvc,
+		searchSvc:  searchSvc,
+		historySvc: historySvc,
+		uploadSvc:  uploadSvc,
+		commentSvc: commentSvc,
 	}
 }
 
@@ -58,6 +80,17 @@ func (h *ArticleHandler) RegisterRoutes(server *gin.Engine) {
 		ginx.WrapBodyAndToken[ListReq, ijwt.UserClaims](h.List))
 	g.GET("/detail/:id", ginx.WrapToken[ijwt.UserClaims](h.Detail))
 
+	// 历史版本
+	rev := g.Group("/revisions")
+	rev.POST("/list", ginx.WrapBodyAndToken[RevisionListReq, ijwt.UserClaims](h.ListRevisions))
+	rev.POST("/diff", ginx.WrapBodyAndToken[RevisionDiffReq, ijwt.UserClaims](h.DiffRevisions))
+	rev.POST("/restore", ginx.WrapBodyAndToken[RevisionRestoreReq, ijwt.UserClaims](h.RestoreRevision))
+
+	// 我的阅读记录
+	his := g.Group("/history")
+	his.POST("/list", ginx.WrapBodyAndToken[ListReq, ijwt.UserClaims](h.ListHistory))
+	his.POST("/clear", ginx.WrapToken[ijwt.UserClaims](h.ClearHistory))
+
 	pub := g.Group("/pub")
 	pub.GET("/:id", h.PubDetail, func(ctx *gin.Context) {
 		// 增加阅读计数。
@@ -81,6 +114,84 @@ func (h *ArticleHandler) RegisterRoutes(server *gin.Engine) {
 	//	ijwt.UserClaims](h.Like))
 	pub.POST("/reward", ginx.WrapBodyAndToken[RewardReq,
 		ijwt.UserClaims](h.reward))
+	// 打开文章页面的时候订阅，新评论通过 SSE 推过来
+	pub.GET("/:id/comments/stream", h.StreamComments)
+
+	// 标签，不需要登录
+	pub.POST("/tag", ginx.WrapBodyV1[TagArticlesReq](h.ListPubByTag))
+	pub.POST("/tag/hot", ginx.WrapBodyV1[TagReq](h.TagTopN))
+	pub.POST("/tags", ginx.WrapBodyV1[TagListReq](h.ListTags))
+	pub.POST("/related", ginx.WrapBodyV1[RelatedReq](h.ListRelated))
+	// 搜索，不需要登录
+	pub.POST("/search", ginx.WrapBodyV1[SearchReq](h.Search))
+}
+
+// StreamComments 事件名是 comment，数据是 CommentVO。
+// 每 30 秒发一个 ping 事件，免得连接被中间的代理断掉
+func (h *ArticleHandler) StreamComments(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{Code: 4, Msg: "参数错误"})
+		return
+	}
+	// 浏览器断开之后，请求的 ctx 会被取消，comment 服务那边的订阅也就跟着结束了
+	reqCtx := ctx.Request.Context()
+	stream, err := h.commentSvc.SubscribeComments(reqCtx, &commentv1.SubscribeCommentsRequest{
+		Biz:   h.biz,
+		BizId: id,
+	})
+	if err != nil {
+		h.l.Error("订阅新评论失败", logger.Int64("aid", id), logger.Error(err))
+		ctx.JSON(http.StatusOK, Result{Code: 5, Msg: "系统错误"})
+		return
+	}
+	comments := make(chan *commentv1.Comment)
+	go func() {
+		defer close(comments)
+		for {
+			c, er := stream.Recv()
+			if er != nil {
+				return
+			}
+			select {
+			case comments <- c:
+			case <-reqCtx.Done():
+				return
+			}
+		}
+	}()
+	ctx.Header("Cache-Control", "no-cache")
+	// nginx 不要缓冲
+	ctx.Header("X-Accel-Buffering", "no")
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case c, ok := <-comments:
+			if !ok {
+				// comment 服务断开了，前端自己重连
+				return false
+			}
+			ctx.SSEvent("comment", newCommentVO(c))
+			return true
+		case <-ticker.C:
+			ctx.SSEvent("ping", "")
+			return true
+		case <-reqCtx.Done():
+			return false
+		}
+	})
+}
+
+func newCommentVO(c *commentv1.Comment) CommentVO {
+	return CommentVO{
+		Id:      c.GetId(),
+		Uid:     c.GetUid(),
+		Pid:     c.GetParentComment().GetId(),
+		Rid:     c.GetRootComment().GetId(),
+		Content: c.GetContent(),
+		Ctime:   c.GetCtime().AsTime().Local().Format(time.DateTime),
+	}
 }
 
 func (h *ArticleHandler) Like(ctx *gin.Context, req LikeReq, uc ijwt.UserClaims) (ginx.Result, error) {
@@ -99,6 +210,18 @@ func (h *ArticleHandler) Like(ctx *gin.Context, req LikeReq, uc ijwt.UserClaims)
 		})
 	}
 
+	switch status.Code(err) {
+	case codes.PermissionDenied:
+		return ginx.Result{
+			Code: 4,
+			Msg:  "你已被作者拉黑",
+		}, nil
+	case codes.ResourceExhausted:
+		return ginx.Result{
+			Code: 4,
+			Msg:  "打赏的人太多了，请稍后再试",
+		}, nil
+	}
 	if err != nil {
 		return ginx.Result{
 			Code: 5,
@@ -128,6 +251,13 @@ func (h *ArticleHandler) Edit(ctx *gin.Context) {
 	// 检测输入，跳过这一步
 	// 调用 svc 的代码
 	id, err := h.svc.Save(ctx, req.toDomain(claims.Uid))
+	if errors.Is(err, service.ErrInvalidArticleTags) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "标签或者分类不合法",
+		})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -137,6 +267,7 @@ func (h *ArticleHandler) Edit(ctx *gin.Context) {
 		h.l.Error("保存帖子失败", logger.Error(err))
 		return
 	}
+	h.syncUploadRefs(ctx, claims.Uid, id, domain.UploadRefVersionDraft, req.Content)
 	ctx.JSON(http.StatusOK, Result{
 		Msg:  "OK",
 		Data: id,
@@ -161,6 +292,27 @@ func (h *ArticleHandler) Publish(ctx *gin.Context) {
 		return
 	}
 	id, err := h.svc.Publish(ctx, req.toDomain(claims.Uid))
+	if errors.Is(err, service.ErrInvalidArticleSchedule) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "定时撤回的时间必须晚于发表时间",
+		})
+		return
+	}
+	if errors.Is(err, service.ErrInvalidArticleTags) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "标签或者分类不合法",
+		})
+		return
+	}
+	if errors.Is(err, service.ErrArticleContentRejected) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "文章包含违规内容",
+		})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -170,6 +322,9 @@ func (h *ArticleHandler) Publish(ctx *gin.Context) {
 		h.l.Error("发表帖子失败", logger.Error(err))
 		return
 	}
+	// 发表的时候制作库和线上库是同样的内容
+	h.syncUploadRefs(ctx, claims.Uid, id, domain.UploadRefVersionDraft, req.Content)
+	h.syncUploadRefs(ctx, claims.Uid, id, domain.UploadRefVersionPublished, req.Content)
 	ctx.JSON(http.StatusOK, Result{
 		Msg:  "OK",
 		Data: id,
@@ -211,13 +366,38 @@ func (h *ArticleHandler) Withdraw(ctx *gin.Context) {
 		h.l.Error("保存帖子失败", logger.Error(err))
 		return
 	}
+	h.releaseUploadRefs(ctx, req.Id)
 	ctx.JSON(http.StatusOK, Result{
 		Msg: "OK",
 	})
 }
 
 func (h *ArticleHandler) List(ctx *gin.Context, req ListReq, uc ijwt.UserClaims) (ginx.Result, error) {
-	res, err := h.svc.List(ctx, uc.Uid, req.Offset, req.Limit)
+	limit := pageSize(req.Limit)
+	var (
+		res []domain.Article
+		err error
+	)
+	if req.Cursor == "" && !req.UseCursor {
+		// 兼容还在用 offset 翻页的前端，包括第一页，返回的还是原来的数组
+		res, err = h.svc.List(ctx, uc.Uid, req.Offset, limit)
+		if err != nil {
+			return ginx.Result{
+				Code: 5,
+				Msg:  "系统错误",
+			}, err
+		}
+		return ginx.Result{
+			Data: slice.Map(res, func(idx int, src domain.Article) ArticleVO {
+				return authorListVO(src)
+			}),
+		}, nil
+	}
+	cursor, err := ginx.ParseCursor(req.Cursor)
+	if err != nil {
+		return ginx.Result{Code: 4, Msg: "分页参数错误"}, nil
+	}
+	res, err = h.svc.ListByCursor(ctx, uc.Uid, cursor, limit)
 	if err != nil {
 		return ginx.Result{
 			Code: 5,
@@ -225,23 +405,31 @@ func (h *ArticleHandler) List(ctx *gin.Context, req ListReq, uc ijwt.UserClaims)
 		}, err
 	}
 	return ginx.Result{
-		Data: slice.Map[domain.Article, ArticleVO](res, func(idx int, src domain.Article) ArticleVO {
-			return ArticleVO{
-				Id:       src.Id,
-				Title:    src.Title,
-				Abstract: src.Abstract(),
-				Status:   src.Status.ToUint8(),
-				// 这个列表请求，不需要返回内容
-				//Content: src.Content,
-				// 这个是创作者看自己的文章列表，也不需要这个字段
-				//Author: src.Author
-				Ctime: src.Ctime.Format(time.DateTime),
-				Utime: src.Utime.Format(time.DateTime),
-			}
-		}),
+		Data: ginx.NewCursorPage(res, limit, articleCursor, authorListVO),
 	}, nil
 }
 
+// authorListVO 创作者看自己的文章列表
+func authorListVO(src domain.Article) ArticleVO {
+	return ArticleVO{
+		Id:       src.Id,
+		Title:    src.Title,
+		Abstract: src.Abstract(),
+		Status:   src.Status.ToUint8(),
+		// 这个列表请求，不需要返回内容
+		//Content: src.Content,
+		// 这个是创作者看自己的文章列表，也不需要这个字段
+		//Author: src.Author
+		Ctime: src.Ctime.Format(time.DateTime),
+		Utime: src.Utime.Format(time.DateTime),
+	}
+}
+
+// articleCursor 文章列表按照更新时间和 ID 倒序
+func articleCursor(art domain.Article) pagination.Cursor {
+	return pagination.Cursor{Key: art.Utime.UnixMilli(), Id: art.Id}
+}
+
 func (h *ArticleHandler) Detail(ctx *gin.Context, uc ijwt.UserClaims) (ginx.Result, error) {
 	idstr := ctx.Param("id")
 	id, err := strconv.ParseInt(idstr, 10, 64)
@@ -284,8 +472,12 @@ func (h *ArticleHandler) Detail(ctx *gin.Context, uc ijwt.UserClaims) (ginx.Resu
 			Content: art.Content,
 			// 这个是创作者看自己的文章列表，也不需要这个字段
 			//Author: art.Author
-			Ctime: art.Ctime.Format(time.DateTime),
-			Utime: art.Utime.Format(time.DateTime),
+			Category:    art.Category,
+			Tags:        art.Tags,
+			Ctime:       art.Ctime.Format(time.DateTime),
+			Utime:       art.Utime.Format(time.DateTime),
+			PublishAt:   formatOptionalTime(art.PublishAt),
+			UnpublishAt: formatOptionalTime(art.UnpublishAt),
 		},
 	}, nil
 }
@@ -326,7 +518,22 @@ func (h *ArticleHandler) PubDetail(ctx *gin.Context) {
 		return err
 	})
 
-	// 在这儿等，要保证前面两个
+	var commentCnt int64
+	eg.Go(func() error {
+		// 评论数拿不到就显示 0，不影响看文章
+		resp, er := h.commentSvc.GetCommentCount(ctx, &commentv1.GetCommentCountRequest{
+			Biz:    h.biz,
+			BizIds: []int64{id},
+		})
+		if er != nil {
+			h.l.Error("查询评论数失败", logger.Int64("aid", id), logger.Error(er))
+			return nil
+		}
+		commentCnt = resp.GetCounts()[id]
+		return nil
+	})
+
+	// 在这儿等，要保证前面几个
 	err = eg.Wait()
 	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
@@ -363,6 +570,8 @@ func (h *ArticleHandler) PubDetail(ctx *gin.Context) {
 			Content: art.Content,
 			// 要把作者信息带出去
 			Author:     art.Author.Name,
+			Category:   art.Category,
+			Tags:       art.Tags,
 			Ctime:      art.Ctime.Format(time.DateTime),
 			Utime:      art.Utime.Format(time.DateTime),
 			Liked:      intr.Liked,
@@ -370,6 +579,7 @@ func (h *ArticleHandler) PubDetail(ctx *gin.Context) {
 			LikeCnt:    intr.LikeCnt,
 			ReadCnt:    intr.ReadCnt,
 			CollectCnt: intr.CollectCnt,
+			CommentCnt: commentCnt,
 		},
 	})
 }
@@ -395,6 +605,24 @@ func (h *ArticleHandler) reward(ctx *gin.Context, req RewardReq,
 		// 作者写得好
 		BizName: art.Title,
 	})
+	switch status.Code(err) {
+	case codes.PermissionDenied:
+		return ginx.Result{
+			Code: 4,
+			Msg:  "你已被作者拉黑",
+		}, nil
+	case codes.ResourceExhausted:
+		return ginx.Result{
+			Code: 4,
+			Msg:  "打赏的人太多了，请稍后再试",
+		}, nil
+	}
+	if err != nil {
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
 	return ginx.Result{
 		Data: map[string]any{
 			"codeURL": resp.CodeUrl,
@@ -403,3 +631,11 @@ func (h *ArticleHandler) reward(ctx *gin.Context, req RewardReq,
 		},
 	}, nil
 }
+
+// formatOptionalTime 零值说明没有设置，返回空字符串
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.DateTime)
+}
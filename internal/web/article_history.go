@@ -0,0 +1,49 @@
+package web
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"webooktrial/internal/domain"
+	ijwt "webooktrial/internal/web/jwt"
+	"webooktrial/pkg/ginx"
+	"webooktrial/pkg/pagination"
+)
+
+// ListHistory 我的阅读记录，只支持 cursor 翻页
+func (h *ArticleHandler) ListHistory(ctx *gin.Context, req ListReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	limit := pageSize(req.Limit)
+	cursor, err := ginx.ParseCursor(req.Cursor)
+	if err != nil {
+		return ginx.Result{Code: 4, Msg: "分页参数错误"}, nil
+	}
+	res, err := h.historySvc.List(ctx, uc.Uid, cursor, limit)
+	if err != nil {
+		return ginx.Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return ginx.Result{
+		Data: ginx.NewCursorPage(res, limit, historyCursor, func(src domain.HistoryRecord) HistoryVO {
+			return HistoryVO{
+				Aid:      src.Article.Id,
+				Title:    src.Article.Title,
+				AuthorId: src.Article.Author.Id,
+				Author:   src.Article.Author.Name,
+				Rtime:    src.Rtime.Format(time.DateTime),
+			}
+		}),
+	}, nil
+}
+
+func (h *ArticleHandler) ClearHistory(ctx *gin.Context, uc ijwt.UserClaims) (ginx.Result, error) {
+	err := h.historySvc.Clear(ctx, uc.Uid)
+	if err != nil {
+		return ginx.Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return ginx.Result{Msg: "OK"}, nil
+}
+
+// historyCursor 阅读记录按照阅读时间和文章 ID 倒序
+func historyCursor(r domain.HistoryRecord) pagination.Cursor {
+	return pagination.Cursor{Key: r.Rtime.UnixMilli(), Id: r.Article.Id}
+}
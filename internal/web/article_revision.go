@@ -0,0 +1,74 @@
+package web
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+
+	"webooktrial/internal/domain"
+	"webooktrial/internal/service"
+	ijwt "webooktrial/internal/web/jwt"
+	"webooktrial/pkg/diffx"
+	"webooktrial/pkg/ginx"
+)
+
+func (h *ArticleHandler) ListRevisions(ctx *gin.Context, req RevisionListReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+	res, err := h.svc.ListRevisions(ctx, uc.Uid, req.Id, req.Offset, req.Limit)
+	if err != nil {
+		return h.revisionErrResult(err), err
+	}
+	return ginx.Result{
+		Data: slice.Map(res, func(idx int, src domain.ArticleRevision) RevisionVO {
+			return RevisionVO{
+				Version:   src.Version,
+				Title:     src.Title,
+				Abstract:  domain.Article{Content: src.Content}.Abstract(),
+				Status:    src.Status.ToUint8(),
+				Published: src.Published,
+				Ctime:     src.Ctime.Format(time.DateTime),
+			}
+		}),
+	}, nil
+}
+
+func (h *ArticleHandler) DiffRevisions(ctx *gin.Context, req RevisionDiffReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	res, err := h.svc.DiffRevisions(ctx, uc.Uid, req.Id, req.From, req.To)
+	if err != nil {
+		return h.revisionErrResult(err), err
+	}
+	toVO := func(idx int, src diffx.Line) DiffLineVO {
+		return DiffLineVO{Op: string(src.Op), Text: src.Text}
+	}
+	return ginx.Result{
+		Data: RevisionDiffVO{
+			From:    res.From,
+			To:      res.To,
+			Title:   slice.Map(res.Title, toVO),
+			Content: slice.Map(res.Content, toVO),
+		},
+	}, nil
+}
+
+func (h *ArticleHandler) RestoreRevision(ctx *gin.Context, req RevisionRestoreReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	id, err := h.svc.RestoreRevision(ctx, uc.Uid, req.Id, req.Version, req.Publish)
+	if err != nil {
+		return h.revisionErrResult(err), err
+	}
+	return ginx.Result{
+		Msg:  "OK",
+		Data: id,
+	}, nil
+}
+
+func (h *ArticleHandler) revisionErrResult(err error) ginx.Result {
+	if errors.Is(err, service.ErrIncorrectArticleAuthor) {
+		// 不需要告诉前端究竟发生了什么
+		return ginx.Result{Code: 4, Msg: "输入有误"}
+	}
+	return ginx.Result{Code: 5, Msg: "系统错误"}
+}
@@ -0,0 +1,43 @@
+package web
+
+import (
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+
+	searchv1 "webooktrial/api/proto/gen/search/v1"
+	"webooktrial/pkg/ginx"
+)
+
+func (h *ArticleHandler) Search(ctx *gin.Context, req SearchReq) (ginx.Result, error) {
+	if req.Expression == "" {
+		return ginx.Result{Code: 4, Msg: "关键字不能为空"}, nil
+	}
+	resp, err := h.searchSvc.SearchArticle(ctx, &searchv1.SearchArticleRequest{
+		Expression: req.Expression,
+		AuthorId:   req.AuthorId,
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+		Offset:     int32(req.Offset),
+		// 分页的大小由搜索服务来限制
+		Limit: int32(req.Limit),
+	})
+	if err != nil {
+		return ginx.Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return ginx.Result{Data: SearchVO{
+		Total: resp.GetTotal(),
+		Arts: slice.Map(resp.GetArticles(), func(idx int, src *searchv1.Article) SearchArticleVO {
+			return SearchArticleVO{
+				Id:       src.GetId(),
+				AuthorId: src.GetAuthorId(),
+				Title:    src.GetTitle(),
+				Snippet:  src.GetSnippet(),
+				Category: src.GetCategory(),
+				Tags:     src.GetTags(),
+				Utime:    time.UnixMilli(src.GetUtime()).Format(time.DateTime),
+			}
+		}),
+	}}, nil
+}
@@ -0,0 +1,98 @@
+package web
+
+import (
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+
+	"webooktrial/internal/domain"
+	"webooktrial/pkg/ginx"
+	"webooktrial/pkg/pagination"
+)
+
+// maxPageSize 列表接口一页最多这么多
+const maxPageSize = 100
+
+func (h *ArticleHandler) ListPubByTag(ctx *gin.Context, req TagArticlesReq) (ginx.Result, error) {
+	if req.Tag == "" {
+		return ginx.Result{Code: 4, Msg: "标签不能为空"}, nil
+	}
+	limit := pageSize(req.Limit)
+	cursor, err := ginx.ParseCursor(req.Cursor)
+	if err != nil {
+		return ginx.Result{Code: 4, Msg: "分页参数错误"}, nil
+	}
+	arts, err := h.svc.ListPubByTag(ctx, req.Tag, cursor, limit)
+	if err != nil {
+		return ginx.Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return ginx.Result{Data: ginx.NewCursorPage(arts, limit, tagArticleCursor, pubListVO)}, nil
+}
+
+// tagArticleCursor 标签下的文章和别的列表一样，按照更新时间倒序
+func tagArticleCursor(art domain.Article) pagination.Cursor {
+	return pagination.Cursor{Key: art.Utime.UnixMilli(), Id: art.Id}
+}
+
+func (h *ArticleHandler) TagTopN(ctx *gin.Context, req TagReq) (ginx.Result, error) {
+	arts, err := h.rankingSvc.GetTopNByTag(ctx, req.Tag)
+	if err != nil {
+		// 冷门的标签没有计算热榜
+		return ginx.Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return ginx.Result{Data: slice.Map(arts, func(idx int, src domain.Article) ArticleVO {
+		return pubListVO(src)
+	})}, nil
+}
+
+func (h *ArticleHandler) ListTags(ctx *gin.Context, req TagListReq) (ginx.Result, error) {
+	limit := pageSize(req.Limit)
+	cursor, err := ginx.ParseCursor(req.Cursor)
+	if err != nil {
+		return ginx.Result{Code: 4, Msg: "分页参数错误"}, nil
+	}
+	tags, err := h.svc.ListTags(ctx, cursor, limit)
+	if err != nil {
+		return ginx.Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return ginx.Result{Data: ginx.NewCursorPage(tags, limit, tagCursor, func(src domain.Tag) TagVO {
+		return TagVO{Name: src.Name, ArticleCnt: src.ArticleCnt}
+	})}, nil
+}
+
+// tagCursor 标签按照文章数倒序、ID 正序
+func tagCursor(tag domain.Tag) pagination.Cursor {
+	return pagination.Cursor{Key: tag.ArticleCnt, Id: tag.Id}
+}
+
+func (h *ArticleHandler) ListRelated(ctx *gin.Context, req RelatedReq) (ginx.Result, error) {
+	arts, err := h.svc.ListRelated(ctx, req.Id, pageSize(req.Limit))
+	if err != nil {
+		return ginx.Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return ginx.Result{Data: slice.Map(arts, func(idx int, src domain.Article) ArticleVO {
+		return pubListVO(src)
+	})}, nil
+}
+
+// pubListVO 读者看到的列表，不需要内容
+func pubListVO(art domain.Article) ArticleVO {
+	return ArticleVO{
+		Id:       art.Id,
+		Title:    art.Title,
+		Abstract: art.Abstract(),
+		Status:   art.Status.ToUint8(),
+		Category: art.Category,
+		Tags:     art.Tags,
+		Ctime:    art.Ctime.Format(time.DateTime),
+		Utime:    art.Utime.Format(time.DateTime),
+	}
+}
+
+func pageSize(limit int) int {
+	if limit <= 0 || limit > maxPageSize {
+		return maxPageSize
+	}
+	return limit
+}
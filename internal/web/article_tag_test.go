@@ -0,0 +1,72 @@
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"webooktrial/internal/domain"
+	svcmocks "webooktrial/internal/service/mocks"
+	"webooktrial/pkg/ginx"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/pagination"
+)
+
+func TestArticleHandler_ListPubByTag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc := svcmocks.NewMockArticleService(ctrl)
+	utime := time.UnixMilli(100)
+	svc.EXPECT().ListPubByTag(gomock.Any(), "go", pagination.Cursor{}, 2).
+		Return([]domain.Article{{Id: 9, Utime: utime}, {Id: 7, Utime: utime}}, nil)
+	// 游标里面是上一页最后一篇文章的更新时间和 ID
+	svc.EXPECT().ListPubByTag(gomock.Any(), "go", pagination.Cursor{Key: 100, Id: 7}, 2).
+		Return([]domain.Article{{Id: 5, Utime: utime}}, nil)
+	h := NewArticleHandler(svc, &logger.NopLogger{}, nil, nil, nil, nil, nil, nil)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	res, err := h.ListPubByTag(ctx, TagArticlesReq{Tag: "go", Limit: 2})
+	require.NoError(t, err)
+	page := res.Data.(ginx.CursorPage[ArticleVO])
+	assert.Len(t, page.List, 2)
+	require.NotEmpty(t, page.NextCursor)
+
+	res, err = h.ListPubByTag(ctx, TagArticlesReq{Tag: "go", Cursor: page.NextCursor, Limit: 2})
+	require.NoError(t, err)
+	assert.Empty(t, res.Data.(ginx.CursorPage[ArticleVO]).NextCursor)
+
+	// 前端自己拼的游标不认
+	res, err = h.ListPubByTag(ctx, TagArticlesReq{Tag: "go", Cursor: "7", Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 4, res.Code)
+}
+
+func TestArticleHandler_ListTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc := svcmocks.NewMockArticleService(ctrl)
+	svc.EXPECT().ListTags(gomock.Any(), pagination.Cursor{}, 2).
+		Return([]domain.Tag{{Id: 1, Name: "go", ArticleCnt: 10}, {Id: 3, Name: "k8s", ArticleCnt: 8}}, nil)
+	// 游标是最后一个标签的文章数和 ID
+	svc.EXPECT().ListTags(gomock.Any(), pagination.Cursor{Key: 8, Id: 3}, 2).
+		Return([]domain.Tag{{Id: 4, Name: "mysql", ArticleCnt: 8}}, nil)
+	h := NewArticleHandler(svc, &logger.NopLogger{}, nil, nil, nil, nil, nil, nil)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	res, err := h.ListTags(ctx, TagListReq{Limit: 2})
+	require.NoError(t, err)
+	page := res.Data.(ginx.CursorPage[TagVO])
+	assert.Equal(t, []TagVO{{Name: "go", ArticleCnt: 10}, {Name: "k8s", ArticleCnt: 8}}, page.List)
+	require.NotEmpty(t, page.NextCursor)
+
+	res, err = h.ListTags(ctx, TagListReq{Cursor: page.NextCursor, Limit: 2})
+	require.NoError(t, err)
+	page = res.Data.(ginx.CursorPage[TagVO])
+	assert.Equal(t, []TagVO{{Name: "mysql", ArticleCnt: 8}}, page.List)
+	assert.Empty(t, page.NextCursor)
+}
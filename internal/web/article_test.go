@@ -2,22 +2,31 @@ package web
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	commentv1 "webooktrial/api/proto/gen/comment/v1"
 	"webooktrial/internal/domain"
 	"webooktrial/internal/service"
 	svcmocks "webooktrial/internal/service/mocks"
 	ijwt "webooktrial/internal/web/jwt"
+	"webooktrial/pkg/ginx"
 	"webooktrial/pkg/logger"
+	"webooktrial/pkg/pagination"
 )
 
 func TestArticleHandler_Publish(t *testing.T) {
@@ -88,12 +97,15 @@ func TestArticleHandler_Publish(t *testing.T) {
 			defer ctrl.Finish()
 			server := gin.Default()
 			server.Use(func(ctx *gin.Context) {
-				ctx.Set("claims", &ijwt.UserClaims{
+				ctx.Set("claims", ijwt.UserClaims{
 					Uid: 123,
 				})
 			})
-			// 用不上 codeSvc
-			h := NewArticleHandler(tc.mock(ctrl), &logger.NopLogger{})
+			// 发表成功之后会记录制作库和线上库引用的图片
+			uploadSvc := svcmocks.NewMockUploadService(ctrl)
+			uploadSvc.EXPECT().SyncArticleRefs(gomock.Any(), int64(123), int64(1),
+				gomock.Any(), "我的内容").Return(nil).AnyTimes()
+			h := NewArticleHandler(tc.mock(ctrl), &logger.NopLogger{}, nil, nil, nil, nil, uploadSvc, nil)
 			h.RegisterRoutes(server)
 
 			req, err := http.NewRequest(http.MethodPost,
@@ -114,3 +126,123 @@ func TestArticleHandler_Publish(t *testing.T) {
 		})
 	}
 }
+
+// fakeCommentClient 只实现 SubscribeComments，推完 comments 就断开
+type fakeCommentClient struct {
+	commentv1.CommentServiceClient
+	comments []*commentv1.Comment
+	req      *commentv1.SubscribeCommentsRequest
+}
+
+func (f *fakeCommentClient) SubscribeComments(ctx context.Context, in *commentv1.SubscribeCommentsRequest,
+	opts ...grpc.CallOption) (commentv1.CommentService_SubscribeCommentsClient, error) {
+	f.req = in
+	return &fakeSubscribeClient{comments: f.comments}, nil
+}
+
+type fakeSubscribeClient struct {
+	grpc.ClientStream
+	comments []*commentv1.Comment
+}
+
+func (f *fakeSubscribeClient) Recv() (*commentv1.Comment, error) {
+	if len(f.comments) == 0 {
+		return nil, io.EOF
+	}
+	c := f.comments[0]
+	f.comments = f.comments[1:]
+	return c, nil
+}
+
+func TestArticleHandler_StreamComments(t *testing.T) {
+	client := &fakeCommentClient{comments: []*commentv1.Comment{
+		{Id: 1, Uid: 2, Content: "第一条", Ctime: timestamppb.Now()},
+		{Id: 2, Uid: 3, Content: "回复", Ctime: timestamppb.Now(),
+			RootComment: &commentv1.Comment{Id: 1}, ParentComment: &commentv1.Comment{Id: 1}},
+	}}
+	server := gin.New()
+	h := NewArticleHandler(nil, &logger.NopLogger{}, nil, nil, nil, nil, nil, client)
+	h.RegisterRoutes(server)
+	// ctx.Stream 要用到 CloseNotify，httptest.ResponseRecorder 没有实现
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/articles/pub/11/comments/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+	// comment 服务断开之后这边也结束，前端自己重连
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, &commentv1.SubscribeCommentsRequest{Biz: "article", BizId: 11}, client.req)
+
+	events := strings.Split(strings.TrimSpace(string(body)), "\n\n")
+	require.Len(t, events, 2)
+	var vos []CommentVO
+	for _, evt := range events {
+		lines := strings.Split(evt, "\n")
+		require.Len(t, lines, 2)
+		assert.Equal(t, "event:comment", lines[0])
+		var vo CommentVO
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(lines[1], "data:")), &vo))
+		vos = append(vos, vo)
+	}
+	assert.Equal(t, int64(1), vos[0].Id)
+	assert.Equal(t, "第一条", vos[0].Content)
+	assert.Equal(t, int64(2), vos[1].Id)
+	assert.Equal(t, int64(1), vos[1].Rid)
+	assert.Equal(t, int64(1), vos[1].Pid)
+}
+
+func TestArticleHandler_StreamComments_BadId(t *testing.T) {
+	server := gin.New()
+	h := NewArticleHandler(nil, &logger.NopLogger{}, nil, nil, nil, nil, nil, &fakeCommentClient{})
+	h.RegisterRoutes(server)
+	req, err := http.NewRequest(http.MethodGet, "/articles/pub/abc/comments/stream", nil)
+	require.NoError(t, err)
+	resp := httptest.NewRecorder()
+	server.ServeHTTP(resp, req)
+	var webRes Result
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&webRes))
+	assert.Equal(t, Result{Code: 4, Msg: "参数错误"}, webRes)
+}
+
+func TestArticleHandler_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc := svcmocks.NewMockArticleService(ctrl)
+	now := time.Now()
+	arts := []domain.Article{{Id: 3, Utime: now}, {Id: 2, Utime: now}}
+	svc.EXPECT().List(gomock.Any(), int64(123), 0, 2).Return(arts, nil)
+	svc.EXPECT().List(gomock.Any(), int64(123), 10, 2).Return(arts, nil)
+	svc.EXPECT().ListByCursor(gomock.Any(), int64(123), pagination.Cursor{}, 2).Return(arts, nil)
+	svc.EXPECT().ListByCursor(gomock.Any(), int64(123),
+		pagination.Cursor{Key: now.UnixMilli(), Id: 2}, 2).Return(arts[:1], nil)
+	h := NewArticleHandler(svc, &logger.NopLogger{}, nil, nil, nil, nil, nil, nil)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	uc := ijwt.UserClaims{Uid: 123}
+
+	// 还在用 offset 的前端拿到的还是原来的数组，第一页 offset 是 0 也一样
+	res, err := h.List(ctx, ListReq{Offset: 0, Limit: 2}, uc)
+	require.NoError(t, err)
+	assert.Len(t, res.Data.([]ArticleVO), 2)
+	res, err = h.List(ctx, ListReq{Offset: 10, Limit: 2}, uc)
+	require.NoError(t, err)
+	assert.Len(t, res.Data.([]ArticleVO), 2)
+
+	res, err = h.List(ctx, ListReq{UseCursor: true, Limit: 2}, uc)
+	require.NoError(t, err)
+	page := res.Data.(ginx.CursorPage[ArticleVO])
+	assert.Len(t, page.List, 2)
+	require.NotEmpty(t, page.NextCursor)
+
+	// 不满一页，没有下一页了
+	res, err = h.List(ctx, ListReq{Cursor: page.NextCursor, Limit: 2}, uc)
+	require.NoError(t, err)
+	assert.Empty(t, res.Data.(ginx.CursorPage[ArticleVO]).NextCursor)
+
+	res, err = h.List(ctx, ListReq{Cursor: "abc", Limit: 2}, uc)
+	require.NoError(t, err)
+	assert.Equal(t, 4, res.Code)
+}
@@ -1,6 +1,10 @@
 package web
 
-import "webooktrial/internal/domain"
+import (
+	"time"
+
+	"webooktrial/internal/domain"
+)
 
 type LikeReq struct {
 	Id int64 `json:"id"`
@@ -25,6 +29,7 @@ type ArticleVO struct {
 	ReadCnt    int64 `json:"read_cnt"`
 	LikeCnt    int64 `json:"like_cnt"`
 	CollectCnt int64 `json:"collect_cnt"`
+	CommentCnt int64 `json:"comment_cnt"`
 
 	// 我个人有没有收藏，有没有点赞
 	Liked     bool `json:"liked"`
@@ -32,9 +37,48 @@ type ArticleVO struct {
 
 	Ctime string `json:"ctime"`
 	Utime string `json:"utime"`
+
+	// 定时发表和定时撤回的时间，只有创作者自己能看到
+	PublishAt   string `json:"publish_at,omitempty"`
+	UnpublishAt string `json:"unpublish_at,omitempty"`
+
+	Category string   `json:"category,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+type TagArticlesReq struct {
+	Tag string `json:"tag"`
+	// Cursor 上一页返回的 next_cursor，第一页不传
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+type TagListReq struct {
+	// Cursor 上一页返回的 next_cursor，第一页不传
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+type TagReq struct {
+	Tag string `json:"tag"`
+}
+
+type TagVO struct {
+	Name       string `json:"name"`
+	ArticleCnt int64  `json:"article_cnt"`
+}
+
+type RelatedReq struct {
+	Id    int64 `json:"id"`
+	Limit int   `json:"limit"`
 }
 
 type ListReq struct {
+	// Cursor 上一页返回的 next_cursor，第一页不传
+	Cursor string `json:"cursor"`
+	// UseCursor 第一页要按照游标翻页的时候传 true，后面的页传 Cursor 就可以了
+	UseCursor bool `json:"use_cursor"`
+	// Offset Deprecated: 深分页性能差，Cursor 和 UseCursor 都没有传的时候才会用
 	Offset int `json:"offset"`
 	Limit  int `json:"limit"`
 }
@@ -43,20 +87,136 @@ type ArticleReq struct {
 	Id      int64  `json:"id"`
 	Title   string `json:"title"`
 	Content string `json:"content"`
+	// PublishAt 定时发表的时间，毫秒数，不传就是立刻发表
+	PublishAt int64 `json:"publish_at"`
+	// UnpublishAt 定时撤回的时间，毫秒数，不传就是不撤回
+	UnpublishAt int64  `json:"unpublish_at"`
+	Category    string `json:"category"`
+	// Tags 不传就是不修改标签，传空数组就是清空标签
+	Tags []string `json:"tags"`
 }
 
 func (req ArticleReq) toDomain(uid int64) domain.Article {
-	return domain.Article{
-		Id:      req.Id,
-		Title:   req.Title,
-		Content: req.Content,
+	art := domain.Article{
+		Id:       req.Id,
+		Title:    req.Title,
+		Content:  req.Content,
+		Category: req.Category,
+		Tags:     req.Tags,
 		Author: domain.Author{
 			Id: uid,
 		},
 	}
+	if req.PublishAt > 0 {
+		art.PublishAt = time.UnixMilli(req.PublishAt)
+	}
+	if req.UnpublishAt > 0 {
+		art.UnpublishAt = time.UnixMilli(req.UnpublishAt)
+	}
+	return art
 }
 
 type RewardReq struct {
 	Id     int64 `json:"id"`
 	Amount int64 `json:"amount"`
 }
+
+type RevisionListReq struct {
+	Id     int64 `json:"id"`
+	Offset int   `json:"offset"`
+	Limit  int   `json:"limit"`
+}
+
+type RevisionDiffReq struct {
+	Id   int64 `json:"id"`
+	From int64 `json:"from"`
+	// To 不传的时候，和当前的草稿比较
+	To int64 `json:"to"`
+}
+
+type RevisionRestoreReq struct {
+	Id      int64 `json:"id"`
+	Version int64 `json:"version"`
+	// Publish 恢复之后是否直接发表
+	Publish bool `json:"publish"`
+}
+
+type RevisionVO struct {
+	Version int64  `json:"version"`
+	Title   string `json:"title"`
+	// 列表只返回摘要
+	Abstract  string `json:"abstract"`
+	Status    uint8  `json:"status"`
+	Published bool   `json:"published"`
+	Ctime     string `json:"ctime"`
+}
+
+type DiffLineVO struct {
+	// equal, insert, delete
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type RevisionDiffVO struct {
+	From    int64        `json:"from"`
+	To      int64        `json:"to"`
+	Title   []DiffLineVO `json:"title"`
+	Content []DiffLineVO `json:"content"`
+}
+
+type SearchReq struct {
+	// Expression 空格分开的多个关键字必须同时命中
+	Expression string `json:"expression"`
+	AuthorId   int64  `json:"author_id"`
+	// 发表时间的范围，毫秒数，0 表示不限制
+	StartTime int64 `json:"start_time"`
+	EndTime   int64 `json:"end_time"`
+	Offset    int   `json:"offset"`
+	Limit     int   `json:"limit"`
+}
+
+type SearchVO struct {
+	Arts  []SearchArticleVO `json:"arts"`
+	Total int64             `json:"total"`
+}
+
+// SearchArticleVO 标题和片段是高亮过的 HTML，命中的关键字用 <em></em> 包起来
+type SearchArticleVO struct {
+	Id       int64    `json:"id"`
+	AuthorId int64    `json:"author_id"`
+	Title    string   `json:"title"`
+	Snippet  string   `json:"snippet"`
+	Category string   `json:"category"`
+	Tags     []string `json:"tags"`
+	Utime    string   `json:"utime"`
+}
+
+// HistoryVO 文章撤回或者删除之后，标题和作者是空的
+type HistoryVO struct {
+	Aid      int64  `json:"aid"`
+	Title    string `json:"title"`
+	AuthorId int64  `json:"author_id"`
+	Author   string `json:"author"`
+	Rtime    string `json:"rtime"`
+}
+
+// UploadVO URL 是稳定的，可以直接写到文章内容里面
+type UploadVO struct {
+	Id          int64  `json:"id"`
+	URL         string `json:"url"`
+	ThumbURL    string `json:"thumb_url"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+}
+
+// CommentVO 实时推送的新评论，pid 和 rid 为 0 表示是一级评论
+type CommentVO struct {
+	Id      int64  `json:"id"`
+	Uid     int64  `json:"uid"`
+	Pid     int64  `json:"pid"`
+	Rid     int64  `json:"rid"`
+	Content string `json:"content"`
+	Ctime   string `json:"ctime"`
+}
@@ -0,0 +1,138 @@
+package web
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"webooktrial/internal/domain"
+	"webooktrial/internal/service"
+	ijwt "webooktrial/internal/web/jwt"
+	"webooktrial/pkg/ginx"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/objectstore"
+)
+
+// uploadURLPrefix 上传的文件的地址前缀，key 是内容的哈希，所以地址是稳定的
+const uploadURLPrefix = "/uploads/"
+
+var _ handler = (*UploadHandler)(nil)
+
+type UploadHandler struct {
+	svc   service.UploadService
+	store objectstore.ObjectStore
+	l     logger.LoggerV1
+	// maxBodySize 请求体的上限，比文件的上限稍微大一点，留给 multipart 的其它部分
+	maxBodySize int64
+}
+
+func NewUploadHandler(svc service.UploadService, store objectstore.ObjectStore,
+	cfg service.UploadConfig, l logger.LoggerV1) *UploadHandler {
+	maxSize := cfg.MaxSize
+	if maxSize <= 0 {
+		maxSize = 5 << 20
+	}
+	return &UploadHandler{svc: svc, store: store, l: l, maxBodySize: maxSize + 1<<20}
+}
+
+func (h *UploadHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/uploads")
+	g.POST("/image", ginx.WrapToken[ijwt.UserClaims](h.UploadImage))
+	// 不需要登录，图片要在文章里面给所有人看
+	g.GET("/images/:name", h.Image)
+}
+
+// UploadImage 表单字段是 file
+func (h *UploadHandler) UploadImage(ctx *gin.Context, uc ijwt.UserClaims) (ginx.Result, error) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, h.maxBodySize)
+	fh, err := ctx.FormFile("file")
+	if err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			return ginx.Result{Code: 4, Msg: "文件太大"}, nil
+		}
+		return ginx.Result{Code: 4, Msg: "没有上传文件"}, nil
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return ginx.Result{Code: 5, Msg: "系统错误"}, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return ginx.Result{Code: 5, Msg: "系统错误"}, err
+	}
+	upload, err := h.svc.UploadImage(ctx, uc.Uid, data)
+	switch {
+	case errors.Is(err, service.ErrUploadTooLarge):
+		return ginx.Result{Code: 4, Msg: "文件太大"}, nil
+	case errors.Is(err, service.ErrUploadUnsupportedType):
+		return ginx.Result{Code: 4, Msg: "只支持 JPEG、PNG 和 GIF 格式的图片"}, nil
+	case errors.Is(err, service.ErrUploadQuotaExceeded):
+		return ginx.Result{Code: 4, Msg: "上传空间不够了"}, nil
+	case err != nil:
+		return ginx.Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return ginx.Result{Data: newUploadVO(upload)}, nil
+}
+
+// Image 内容不会变，所以可以让浏览器和 CDN 一直缓存
+func (h *UploadHandler) Image(ctx *gin.Context) {
+	name := ctx.Param("name")
+	if strings.Contains(name, "/") {
+		ctx.Status(http.StatusNotFound)
+		return
+	}
+	key := "images/" + name
+	data, err := h.store.Get(ctx, key)
+	if errors.Is(err, objectstore.ErrObjectNotFound) {
+		ctx.Status(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.l.Error("读取上传的图片失败", logger.String("key", key), logger.Error(err))
+		ctx.Status(http.StatusInternalServerError)
+		return
+	}
+	ctx.Header("Cache-Control", "public, max-age=31536000, immutable")
+	// 只有图片才能上传，这里按照内容判断，防止被当成 HTML 解析
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Data(http.StatusOK, http.DetectContentType(data), data)
+}
+
+func newUploadVO(u domain.Upload) UploadVO {
+	return UploadVO{
+		Id:          u.Id,
+		URL:         uploadURLPrefix + u.Key,
+		ThumbURL:    uploadURLPrefix + u.ThumbKey,
+		ContentType: u.ContentType,
+		Size:        u.Size,
+		Width:       u.Width,
+		Height:      u.Height,
+	}
+}
+
+// syncUploadRefs 记录文章引用了哪些图片，失败了只是清理的时候可能误删，不影响保存文章
+func (h *ArticleHandler) syncUploadRefs(ctx *gin.Context, uid, aid int64,
+	version domain.UploadRefVersion, content string) {
+	err := h.uploadSvc.SyncArticleRefs(ctx, uid, aid, version, content)
+	if err != nil {
+		h.l.Error("记录文章引用的图片失败",
+			logger.Int64("aid", aid),
+			logger.Error(err))
+	}
+}
+
+// releaseUploadRefs 撤回之后线上库的内容就不算了，制作库引用的图片还会保留。
+// 失败了只是图片晚一点被清理
+func (h *ArticleHandler) releaseUploadRefs(ctx *gin.Context, aid int64) {
+	err := h.uploadSvc.ReleaseArticle(ctx, aid, domain.UploadRefVersionPublished)
+	if err != nil {
+		h.l.Error("释放文章引用的图片失败",
+			logger.Int64("aid", aid),
+			logger.Error(err))
+	}
+}
@@ -0,0 +1,19 @@
+package ioc
+
+import (
+	"github.com/spf13/viper"
+
+	"webooktrial/internal/repository/article"
+)
+
+func InitArticleRevisionRetention() article.RevisionRetention {
+	// 默认每篇文章保留最近的 50 个版本
+	cfg := article.RevisionRetention{
+		MaxCount: 50,
+	}
+	err := viper.UnmarshalKey("article.revision", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
@@ -1,16 +1,26 @@
 package ioc
 
 import (
+	"time"
+
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/client/v3/naming/resolver"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/grpc/health"
 
 	intrv1 "webooktrial/api/proto/gen/intr/v1"
 	"webooktrial/client"
 	"webooktrial/interactive/service"
+	"webooktrial/pkg/grpcx/balancer/wrr"
+	"webooktrial/pkg/grpcx/interceptors"
+	"webooktrial/pkg/grpcx/interceptors/circuitbreaker"
+	"webooktrial/pkg/grpcx/interceptors/prometheus"
+	"webooktrial/pkg/grpcx/interceptors/retry"
+	"webooktrial/pkg/viperx"
 )
 
 func InitEtcd() *clientv3.Client {
@@ -43,7 +53,11 @@ func InitIntrGRPCClientV1(client *clientv3.Client) intrv1.InteractiveServiceClie
 		panic(err)
 	}
 
-	opts := []grpc.DialOption{grpc.WithResolvers(bd)}
+	opts := []grpc.DialOption{grpc.WithResolvers(bd),
+		// 按照 interactive 节点注册的权重和标签来做负载均衡，并且摘掉健康检查不通过的节点
+		grpc.WithDefaultServiceConfig(`{"loadBalancingConfig": [{"` + wrr.Name + `":{}}],
+			"healthCheckConfig": {"serviceName": "interactive"}}`),
+		grpc.WithChainUnaryInterceptor(intrClientInterceptors()...)}
 	if cfg.Secure {
 		// 上面，要去加载你的证书之类的东西
 		// 启用 HTTPS
@@ -84,7 +98,7 @@ func InitIntrGRPCClient(svc service.InteractiveService) intrv1.InteractiveServic
 	remote := intrv1.NewInteractiveServiceClient(cc)
 	local := client.NewInteractiveServiceAdapter(svc)
 	res := client.NewGreyScaleInteractiveClient(remote, local)
-	viper.OnConfigChange(func(in fsnotify.Event) {
+	viperx.OnConfigChange(func(in fsnotify.Event) {
 		var cfg Config
 		err = viper.UnmarshalKey("grpc.client.intr", &cfg)
 		if err != nil {
@@ -94,3 +108,35 @@ func InitIntrGRPCClient(svc service.InteractiveService) intrv1.InteractiveServic
 	})
 	return res
 }
+
+// intrClientInterceptors 调用 interactive 的客户端拦截器
+// 顺序是：带上调用方 -> 监控 -> 熔断 -> 重试
+// 熔断在重试外面，这样熔断之后就不会再重试了
+func intrClientInterceptors() []grpc.UnaryClientInterceptor {
+	pb := &prometheus.InterceptorBuilder{
+		Namespace: "go_study",
+		Subsystem: "webook",
+	}
+	metrics := pb.BuildClientMetrics()
+	readPolicy := retry.Policy{
+		Idempotent:     true,
+		MaxAttempts:    3,
+		InitialBackoff: 20 * time.Millisecond,
+		MaxBackoff:     200 * time.Millisecond,
+		RetryableCodes: []codes.Code{codes.Unavailable, codes.DeadlineExceeded},
+	}
+	// 批量查询一般是列表页，对延迟敏感，用对冲来削掉长尾
+	hedgePolicy := readPolicy
+	hedgePolicy.HedgingDelay = 50 * time.Millisecond
+	return []grpc.UnaryClientInterceptor{
+		interceptors.BuildCallerClientInterceptor("webook"),
+		pb.BuildClient(),
+		circuitbreaker.NewClientInterceptorBuilder().Observer(metrics).Build(),
+		retry.NewClientInterceptorBuilder().
+			Policy(intrv1.InteractiveService_Get_FullMethodName, readPolicy).
+			Policy(intrv1.InteractiveService_GetByIds_FullMethodName, hedgePolicy).
+			// 点赞、收藏、阅读数都会直接修改计数，重试可能会重复计数，所以不重试
+			Observer(metrics).
+			Build(),
+	}
+}
@@ -12,12 +12,41 @@ import (
 
 func InitScheduler(l logger.LoggerV1,
 	local *job.LocalFuncExecutor,
+	articleSchedule *job.ArticleScheduleJob,
+	uploadCleanup *job.UploadCleanupJob,
 	svc service.JobService) *job.Scheduler {
 	res := job.NewScheduler(svc, l)
 	res.RegisterExecutor(local)
+	res.RegisterExecutor(articleSchedule)
+	res.RegisterExecutor(uploadCleanup)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	jobs := []domain.Job{
+		// 定时发表和撤回，每分钟扫一次，误差在一分钟以内
+		{Name: articleSchedule.Name(), Executor: articleSchedule.Name(), Cron: "* * * * *"},
+		// 清理没有被引用的图片，不着急，每小时一次
+		{Name: uploadCleanup.Name(), Executor: uploadCleanup.Name(), Cron: "0 * * * *"},
+	}
+	for _, j := range jobs {
+		err := svc.Add(ctx, j)
+		if err != nil {
+			panic(err)
+		}
+	}
 	return res
 }
 
+func InitArticleScheduleJob(svc service.ArticleService,
+	l logger.LoggerV1) *job.ArticleScheduleJob {
+	return job.NewArticleScheduleJob(svc, time.Second*30, l)
+}
+
+func InitUploadCleanupJob(svc service.UploadService,
+	l logger.LoggerV1) *job.UploadCleanupJob {
+	// 上传了还没来得及保存文章的图片，留一天
+	return job.NewUploadCleanupJob(svc, time.Minute, time.Hour*24, l)
+}
+
 func InitLocalFuncExecutor(svc service.RankingService) *job.LocalFuncExecutor {
 	res := job.NewLocalFuncExecutor()
 	// 要在数据库里面插入一条记录。
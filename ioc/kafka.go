@@ -5,6 +5,8 @@ import (
 	"github.com/spf13/viper"
 
 	"webooktrial/internal/events"
+	"webooktrial/internal/events/article"
+	"webooktrial/pkg/logger"
 )
 
 func InitKafka() sarama.Client {
@@ -33,12 +35,28 @@ func NewSyncProducer(client sarama.Client) sarama.SyncProducer {
 	return res
 }
 
+// InitArticleProducer 阅读事件攒批之后异步发送，其它事件还是同步发送
+func InitArticleProducer(client sarama.Client, pc sarama.SyncProducer,
+	l logger.LoggerV1) *article.BatchReadEventProducer {
+	var cfg article.BatchReadEventConfig
+	err := viper.UnmarshalKey("kafka.readEvent", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	ap, err := sarama.NewAsyncProducerFromClient(client)
+	if err != nil {
+		panic(err)
+	}
+	return article.NewBatchReadEventProducer(article.NewKafkaProducer(pc), ap, l, cfg)
+}
+
 //// NewConsumers 面临的问题依旧是所有的 Consumer 在这里注册一下
 //func NewConsumers(c1 *events2.InteractiveReadEventBatchConsumer) []events.Consumer {
 //	return []events.Consumer{c1}
 //}
 
 // NewConsumers 面临的问题依旧是所有的 Consumer 在这里注册一下
-func NewConsumers() []events.Consumer {
-	return []events.Consumer{}
+func NewConsumers(c1 *article.CacheInvalidationConsumer,
+	c2 *article.HistoryReadEventConsumer) []events.Consumer {
+	return []events.Consumer{c1, c2}
 }
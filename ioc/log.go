@@ -6,10 +6,11 @@ import (
 	"webooktrial/pkg/logger"
 )
 
+// InitLogger 手机号、邮箱之类的敏感字段在打印之前会被处理掉
 func InitLogger() logger.LoggerV1 {
 	l, err := zap.NewDevelopment()
 	if err != nil {
 		panic(err)
 	}
-	return logger.NewZapLogger(l)
+	return logger.NewRedactLogger(logger.NewZapLogger(l), logger.NewRedactor())
 }
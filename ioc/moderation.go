@@ -0,0 +1,11 @@
+package ioc
+
+import (
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/moderation"
+)
+
+// InitModerator 文章发表的时候用，敏感词在 moderation.words 下面
+func InitModerator(l logger.LoggerV1) *moderation.Moderator {
+	return moderation.NewModeratorFromConfig("moderation", l)
+}
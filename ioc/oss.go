@@ -0,0 +1,59 @@
+package ioc
+
+import (
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/spf13/viper"
+
+	"webooktrial/pkg/objectstore"
+)
+
+// InitObjectStore 文章内容和上传的图片都放在这里。
+// 开发环境用本地目录，线上用兼容 S3 协议的对象存储
+func InitObjectStore() objectstore.ObjectStore {
+	type LocalConfig struct {
+		Dir     string `yaml:"dir"`
+		BaseURL string `yaml:"baseURL"`
+		Secret  string `yaml:"secret"`
+	}
+	type S3Config struct {
+		Endpoint string `yaml:"endpoint"`
+		Region   string `yaml:"region"`
+		Bucket   string `yaml:"bucket"`
+	}
+	type Config struct {
+		// Type local 或者 s3
+		Type  string      `yaml:"type"`
+		Local LocalConfig `yaml:"local"`
+		S3    S3Config    `yaml:"s3"`
+	}
+	var cfg Config
+	err := viper.UnmarshalKey("oss", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	switch cfg.Type {
+	case "s3":
+		sess, err := session.NewSession(&aws.Config{
+			Endpoint: aws.String(cfg.S3.Endpoint),
+			Region:   aws.String(cfg.S3.Region),
+			// 密钥不要放在配置文件里面
+			Credentials: credentials.NewStaticCredentials(
+				os.Getenv("OSS_ACCESS_KEY_ID"), os.Getenv("OSS_ACCESS_KEY_SECRET"), ""),
+		})
+		if err != nil {
+			panic(err)
+		}
+		return objectstore.NewS3Store(s3.New(sess), cfg.S3.Bucket)
+	default:
+		store, err := objectstore.NewLocalStore(cfg.Local.Dir, cfg.Local.BaseURL, cfg.Local.Secret)
+		if err != nil {
+			panic(err)
+		}
+		return store
+	}
+}
@@ -0,0 +1,43 @@
+package ioc
+
+import (
+	"github.com/spf13/viper"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/naming/resolver"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	searchv1 "webooktrial/api/proto/gen/search/v1"
+	"webooktrial/pkg/grpcx/balancer/wrr"
+	"webooktrial/pkg/grpcx/interceptors"
+)
+
+func InitSearchGRPCClient(client *clientv3.Client) searchv1.SearchServiceClient {
+	type Config struct {
+		Secure bool
+		Name   string
+	}
+	var cfg Config
+	err := viper.UnmarshalKey("grpc.client.search", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	bd, err := resolver.NewBuilder(client)
+	if err != nil {
+		panic(err)
+	}
+	opts := []grpc.DialOption{grpc.WithResolvers(bd),
+		grpc.WithDefaultServiceConfig(`{"loadBalancingConfig": [{"` + wrr.Name + `":{}}],
+			"healthCheckConfig": {"serviceName": "search"}}`),
+		grpc.WithChainUnaryInterceptor(interceptors.BuildCallerClientInterceptor("webook"))}
+	if cfg.Secure {
+		// 启用 HTTPS
+	} else {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+	cc, err := grpc.Dial("etcd:///service/"+cfg.Name, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return searchv1.NewSearchServiceClient(cc)
+}
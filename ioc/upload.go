@@ -0,0 +1,17 @@
+package ioc
+
+import (
+	"github.com/spf13/viper"
+
+	"webooktrial/internal/service"
+)
+
+func InitUploadConfig() service.UploadConfig {
+	// 没有配置的字段，NewUploadService 里面会用默认值
+	var cfg service.UploadConfig
+	err := viper.UnmarshalKey("upload", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
@@ -4,10 +4,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
 	"webooktrial/internal/web"
@@ -17,19 +19,34 @@ import (
 	"webooktrial/pkg/ginx/middlewares/metric"
 	"webooktrial/pkg/ginx/middlewares/ratelimit"
 	"webooktrial/pkg/logger"
+	"webooktrial/pkg/objectstore"
 	ratelimit2 "webooktrial/pkg/ratelimit"
+	"webooktrial/pkg/viperx"
 )
 
+// localObjectsPath 本地对象存储挂载的路径，oss.local.baseURL 要指到这里
+const localObjectsPath = "/objects"
+
 func InitWebServer(mdls []gin.HandlerFunc, userHdl *web.UserHandler,
-	oauth2WechatHandler *web.OAuth2WechatHandler, articleHdl *web.ArticleHandler) *gin.Engine {
+	oauth2WechatHandler *web.OAuth2WechatHandler, articleHdl *web.ArticleHandler,
+	uploadHdl *web.UploadHandler, store objectstore.ObjectStore) *gin.Engine {
 	server := gin.Default()
 	server.Use(mdls...)
 	userHdl.RegisterRoutes(server)
 	articleHdl.RegisterRoutes(server)
 	oauth2WechatHandler.RegisterRoutes(server)
+	uploadHdl.RegisterRoutes(server)
+	registerLocalObjects(server, store)
 	return server
 }
 
+// registerLocalObjects 用 S3 的时候链接直接指向对象存储，本地的要自己提供下载
+func registerLocalObjects(server *gin.Engine, store objectstore.ObjectStore) {
+	if local, ok := store.(*objectstore.LocalStore); ok {
+		server.GET(localObjectsPath+"/*key", gin.WrapH(local))
+	}
+}
+
 func InitMiddlewares(redisClient redis.Cmdable, jwtHdl ijwt.Handler, l logger.LoggerV1) []gin.HandlerFunc {
 	//bd := logger2.NewBuilder(func(ctx context.Context, al *logger2.AccessLog) {
 	//	l.Debug("HTTP请求", logger.Field{Key: "al", Value: al})
@@ -44,6 +61,7 @@ func InitMiddlewares(redisClient redis.Cmdable, jwtHdl ijwt.Handler, l logger.Lo
 		Name:      "http_biz_code",
 		Help:      "HTTP 的业务错误码",
 	})
+	ginx.InitCursorCodec(viper.GetString("web.cursor.secret"))
 	return []gin.HandlerFunc{
 		corsHdl(),
 		IgnorePathsHdl(jwtHdl),
@@ -56,10 +74,56 @@ func InitMiddlewares(redisClient redis.Cmdable, jwtHdl ijwt.Handler, l logger.Lo
 			InstanceID: "my-instance-1",
 		}).Build(),
 		otelgin.Middleware("webook"),
-		ratelimit.NewBuilder(ratelimit2.NewRedisSlidingWindowLimiter(redisClient, time.Second, 1000)).Build(),
+		initRateLimit(redisClient, l).Build(),
 	}
 }
 
+// initRateLimit 基于规则的限流，规则放在 web.ratelimit.rules 下面，支持热更新
+// 没有配置的时候，保持和原本一样，按照 IP 每秒 1000 个请求
+func initRateLimit(redisClient redis.Cmdable, l logger.LoggerV1) *ratelimit.RuleBuilder {
+	return newRateLimitBuilder(func(interval time.Duration, rate int) ratelimit2.Limiter {
+		return ratelimit2.NewRedisSlidingWindowLimiter(redisClient, interval, rate)
+	}, l)
+}
+
+func newRateLimitBuilder(factory ratelimit2.LimiterFactory, l logger.LoggerV1) *ratelimit.RuleBuilder {
+	type Config struct {
+		Rules []ratelimit.Rule `yaml:"rules"`
+	}
+	loadRules := func() ([]ratelimit.Rule, error) {
+		var cfg Config
+		err := viper.UnmarshalKey("web.ratelimit", &cfg)
+		if err != nil {
+			return nil, err
+		}
+		if len(cfg.Rules) == 0 {
+			return []ratelimit.Rule{
+				{Name: "ip", KeyType: ratelimit.KeyTypeIP, Interval: time.Second, Rate: 1000},
+			}, nil
+		}
+		return cfg.Rules, nil
+	}
+	rules, err := loadRules()
+	if err != nil {
+		panic(err)
+	}
+	bd, err := ratelimit.NewRuleBuilder(factory, l, rules...)
+	if err != nil {
+		panic(err)
+	}
+	viperx.OnConfigChange(func(in fsnotify.Event) {
+		rules, err := loadRules()
+		if err == nil {
+			err = bd.UpdateRules(rules)
+		}
+		if err != nil {
+			// 新规则有问题就继续用旧的规则
+			l.Error("更新限流规则失败", logger.Error(err))
+		}
+	})
+	return bd
+}
+
 func corsHdl() gin.HandlerFunc {
 	return cors.New(cors.Config{
 		//AllowOrigins: []string{"*"},
@@ -89,5 +153,14 @@ func IgnorePathsHdl(jwtHdl ijwt.Handler) gin.HandlerFunc {
 		IgnorePaths("/oauth2/wechat/callback").
 		IgnorePaths("/users/login_sms").
 		IgnorePaths("/users/refresh_token").
-		IgnorePaths("/test/metric").Build()
+		IgnorePaths("/articles/pub/tag").
+		IgnorePaths("/articles/pub/tag/hot").
+		IgnorePaths("/articles/pub/tags").
+		IgnorePaths("/articles/pub/related").
+		IgnorePaths("/articles/pub/search").
+		IgnorePaths("/test/metric").
+		// 文章里面的图片，谁都可以看
+		IgnorePrefix("/uploads/images/").
+		// 本地对象存储的链接自带签名
+		IgnorePrefix(localObjectsPath + "/").Build()
 }
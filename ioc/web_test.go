@@ -0,0 +1,94 @@
+package ioc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/objectstore"
+	"webooktrial/pkg/ratelimit"
+)
+
+// alwaysLimited 每个请求都触发限流，这样响应头里面就有规则的阈值
+type alwaysLimited struct{}
+
+func (alwaysLimited) Limit(ctx context.Context, key string) (bool, error) {
+	return true, nil
+}
+
+func TestRateLimitHotReload(t *testing.T) {
+	const tpl = `
+web:
+  ratelimit:
+    rules:
+      - name: "all"
+        interval: "1s"
+        rate: %d
+moderation:
+  words:
+    - text: "赌博"
+      score: 0.9
+`
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	writeCfg := func(rate int) {
+		require.NoError(t, os.WriteFile(file, []byte(fmt.Sprintf(tpl, rate)), 0o644))
+	}
+	writeCfg(10)
+	viper.SetConfigFile(file)
+	require.NoError(t, viper.ReadInConfig())
+	viper.WatchConfig()
+
+	factory := func(interval time.Duration, rate int) ratelimit.Limiter {
+		return alwaysLimited{}
+	}
+	server := gin.New()
+	server.Use(newRateLimitBuilder(factory, logger.NewNopLogger()).Build())
+	server.GET("/hello", func(ctx *gin.Context) {})
+	limitHeader := func() string {
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/hello", nil)
+		require.NoError(t, err)
+		server.ServeHTTP(recorder, req)
+		return recorder.Header().Get("X-RateLimit-Limit")
+	}
+	assert.Equal(t, "10", limitHeader())
+
+	// 后面初始化的组件也注册了热更新，不能把限流的覆盖掉
+	InitModerator(logger.NewNopLogger())
+	writeCfg(20)
+	assert.Eventually(t, func() bool {
+		return limitHeader() == "20"
+	}, 3*time.Second, 50*time.Millisecond)
+}
+
+func TestRegisterLocalObjects(t *testing.T) {
+	store, err := objectstore.NewLocalStore(t.TempDir(), "http://localhost:8080"+localObjectsPath, "secret")
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, store.Put(ctx, "images/a.png", []byte("abc"), "image/png"))
+	link, err := store.Presign(ctx, "images/a.png", time.Minute)
+	require.NoError(t, err)
+
+	server := gin.New()
+	registerLocalObjects(server, store)
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, link, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "abc", recorder.Body.String())
+
+	// 没有签名的不给看
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, localObjectsPath+"/images/a.png", nil))
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
@@ -15,8 +16,11 @@ import (
 	"github.com/spf13/viper"
 	_ "github.com/spf13/viper/remote"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	"webooktrial/ioc"
+	"webooktrial/pkg/viperx"
+	"webooktrial/pkg/zapx"
 )
 
 func main() {
@@ -37,6 +41,13 @@ func main() {
 		}
 	}
 	app.cron.Start()
+	schCtx, schCancel := context.WithCancel(context.Background())
+	go func() {
+		err := app.scheduler.Schedule(schCtx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			zap.L().Error("任务调度退出了", zap.Error(err))
+		}
+	}()
 	server := app.web
 	server.GET("/hello", func(ctx *gin.Context) {
 		ctx.String(http.StatusOK, "你好，你来了")
@@ -44,10 +55,23 @@ func main() {
 	if err := server.Run(":8080"); err != nil {
 		panic(err)
 	}
+	// 不再抢新的任务，已经在执行的任务也会收到取消的信号
+	schCancel()
+	for _, c := range app.consumers {
+		if closer, ok := c.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				zap.L().Error("关闭消费者失败", zap.Error(err))
+			}
+		}
+	}
 	// 一分钟内你要关完，要退出
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 	closeFunc(ctx)
+	err := app.readProducer.Close(ctx)
+	if err != nil {
+		zap.L().Error("发送剩余的阅读事件失败", zap.Error(err))
+	}
 
 	ctx = app.cron.Stop()
 	tm := time.NewTimer(time.Minute * 10)
@@ -68,7 +92,10 @@ func initPrometheus() {
 }
 
 func initLogger() {
-	logger, err := zap.NewDevelopment()
+	// 直接用 zap.L() 的地方绕过了 LoggerV1，在 core 这一层处理敏感字段
+	logger, err := zap.NewDevelopment(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapx.NewMyCore(core, nil)
+	}))
 	if err != nil {
 		panic(err)
 	}
@@ -112,7 +139,7 @@ func initViperRemote() {
 	if err != nil {
 		panic(err)
 	}
-	viper.OnConfigChange(func(in fsnotify.Event) {
+	viperx.OnConfigChange(func(in fsnotify.Event) {
 		fmt.Println(in.Name, in.Op)
 	})
 	err = viper.ReadRemoteConfig()
@@ -129,7 +156,7 @@ func initViperV1() {
 	// 实时监听配置变更
 	viper.WatchConfig()
 	// 只能告诉你文件变了，不能告诉你，文件的哪些内容变了
-	viper.OnConfigChange(func(in fsnotify.Event) {
+	viperx.OnConfigChange(func(in fsnotify.Event) {
 		// 比较好的设计，它会在 in 里面告诉你变更前的数据，和变更后的数据
 		// 更好的设计是，它会直接告诉你差异。
 		fmt.Println(in.Name, in.Op)
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"webooktrial/pkg/grpcx"
+	"webooktrial/pkg/saramax"
+)
+
+type App struct {
+	server    *grpcx.Server
+	consumers []saramax.CloseableConsumer
+}
@@ -0,0 +1,65 @@
+package domain
+
+import "time"
+
+type NotificationType uint8
+
+const (
+	NotificationTypeUnknown NotificationType = iota
+	// NotificationTypeReply 回复了我的评论，按照被回复的评论聚合
+	NotificationTypeReply
+	// NotificationTypeMention 在评论里面 @ 了我，每条评论一条通知
+	NotificationTypeMention
+	// NotificationTypeComment 评论了我的文章之类的，按照评论对象聚合
+	NotificationTypeComment
+	// NotificationTypeFollow 关注了我，所有的关注聚合成一条
+	NotificationTypeFollow
+	NotificationTypeLike
+	NotificationTypeReward
+)
+
+// Event 一次需要通知的操作，同一个 Uid、Type、Biz、BizId 的会聚合成一条 Notification
+type Event struct {
+	// Uid 接收通知的人
+	Uid   int64
+	Type  NotificationType
+	Biz   string
+	BizId int64
+	// Actor 做了这个操作的人
+	Actor int64
+	// RefId 用来去重，同一个 Actor 的同一个 RefId 只算一次。
+	// 比如回复的评论 ID、打赏的 ID，点赞和关注用 0，也就是同一个人只通知一次
+	RefId   int64
+	Content string
+}
+
+// Notification 聚合之后的通知
+type Notification struct {
+	Id    int64
+	Uid   int64
+	Type  NotificationType
+	Biz   string
+	BizId int64
+	// ActorCnt 做了这个操作的人数，同一个人只算一次
+	ActorCnt  int64
+	LastActor int64
+	// Content 最近一次的内容摘要
+	Content string
+	Read    bool
+	// Utime 最近一次有人做了这个操作的时间
+	Utime time.Time
+}
+
+// Comment 新评论，会产生回复、@ 和评论了作者三种通知
+type Comment struct {
+	Id    int64
+	Biz   string
+	BizId int64
+	// BizOwner 评论对象的作者，不知道的时候为 0
+	BizOwner int64
+	Uid      int64
+	// ParentId 和 ParentUid 是被回复的评论和它的评论者，一级评论为 0
+	ParentId  int64
+	ParentUid int64
+	Content   string
+}
@@ -0,0 +1,68 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"webooktrial/notification/domain"
+	"webooktrial/notification/service"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/saramax"
+)
+
+const topicCommentCreated = "comment_created"
+
+// CommentCreatedConsumer 回复、@ 和评论了作者的通知
+type CommentCreatedConsumer struct {
+	client sarama.Client
+	svc    service.NotificationService
+	l      logger.LoggerV1
+	cancel context.CancelFunc
+}
+
+func NewCommentCreatedConsumer(client sarama.Client,
+	svc service.NotificationService,
+	l logger.LoggerV1) *CommentCreatedConsumer {
+	return &CommentCreatedConsumer{
+		client: client,
+		svc:    svc,
+		l:      l,
+	}
+}
+
+func (c *CommentCreatedConsumer) Start() error {
+	cg, err := sarama.NewConsumerGroupFromClient("notification_comment", c.client)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	go saramax.ConsumeLoop(ctx, cg, []string{topicCommentCreated},
+		saramax.NewHandler[CommentCreatedEvent](c.l, c.Consume), c.l)
+	return nil
+}
+
+// Close 停止消费
+func (c *CommentCreatedConsumer) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	return nil
+}
+
+func (c *CommentCreatedConsumer) Consume(msg *sarama.ConsumerMessage, evt CommentCreatedEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return c.svc.NotifyComment(ctx, domain.Comment{
+		Id:        evt.Id,
+		Biz:       evt.Biz,
+		BizId:     evt.BizId,
+		BizOwner:  evt.BizOwner,
+		Uid:       evt.Uid,
+		ParentId:  evt.ParentId,
+		ParentUid: evt.ParentUid,
+		Content:   evt.Content,
+	})
+}
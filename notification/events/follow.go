@@ -0,0 +1,58 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"webooktrial/notification/service"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/saramax"
+)
+
+const topicFollowCreated = "follow_created"
+
+// FollowCreatedConsumer 关注的通知，重复关注不会重复通知
+type FollowCreatedConsumer struct {
+	client sarama.Client
+	svc    service.NotificationService
+	l      logger.LoggerV1
+	cancel context.CancelFunc
+}
+
+func NewFollowCreatedConsumer(client sarama.Client,
+	svc service.NotificationService,
+	l logger.LoggerV1) *FollowCreatedConsumer {
+	return &FollowCreatedConsumer{
+		client: client,
+		svc:    svc,
+		l:      l,
+	}
+}
+
+func (c *FollowCreatedConsumer) Start() error {
+	cg, err := sarama.NewConsumerGroupFromClient("notification_follow", c.client)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	go saramax.ConsumeLoop(ctx, cg, []string{topicFollowCreated},
+		saramax.NewHandler[FollowCreatedEvent](c.l, c.Consume), c.l)
+	return nil
+}
+
+// Close 停止消费
+func (c *FollowCreatedConsumer) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	return nil
+}
+
+func (c *FollowCreatedConsumer) Consume(msg *sarama.ConsumerMessage, evt FollowCreatedEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return c.svc.NotifyFollow(ctx, evt.Follower, evt.Followee)
+}
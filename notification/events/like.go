@@ -0,0 +1,65 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"webooktrial/notification/domain"
+	"webooktrial/notification/service"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/saramax"
+)
+
+const topicLiked = "interactive_liked"
+
+// LikedConsumer 点赞的通知，按照被点赞的对象聚合。不知道作者的点赞忽略掉
+type LikedConsumer struct {
+	client sarama.Client
+	svc    service.NotificationService
+	l      logger.LoggerV1
+	cancel context.CancelFunc
+}
+
+func NewLikedConsumer(client sarama.Client,
+	svc service.NotificationService,
+	l logger.LoggerV1) *LikedConsumer {
+	return &LikedConsumer{
+		client: client,
+		svc:    svc,
+		l:      l,
+	}
+}
+
+func (c *LikedConsumer) Start() error {
+	cg, err := sarama.NewConsumerGroupFromClient("notification_like", c.client)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	go saramax.ConsumeLoop(ctx, cg, []string{topicLiked},
+		saramax.NewHandler[LikedEvent](c.l, c.Consume), c.l)
+	return nil
+}
+
+// Close 停止消费
+func (c *LikedConsumer) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	return nil
+}
+
+func (c *LikedConsumer) Consume(msg *sarama.ConsumerMessage, evt LikedEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return c.svc.Notify(ctx, domain.Event{
+		Uid:   evt.BizOwner,
+		Type:  domain.NotificationTypeLike,
+		Biz:   evt.Biz,
+		BizId: evt.BizId,
+		Actor: evt.Uid,
+	})
+}
@@ -0,0 +1,66 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"webooktrial/notification/domain"
+	"webooktrial/notification/service"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/saramax"
+)
+
+const topicRewardPaid = "reward_paid"
+
+// RewardPaidConsumer 打赏的通知，同一个人打赏多次每次都会更新通知
+type RewardPaidConsumer struct {
+	client sarama.Client
+	svc    service.NotificationService
+	l      logger.LoggerV1
+	cancel context.CancelFunc
+}
+
+func NewRewardPaidConsumer(client sarama.Client,
+	svc service.NotificationService,
+	l logger.LoggerV1) *RewardPaidConsumer {
+	return &RewardPaidConsumer{
+		client: client,
+		svc:    svc,
+		l:      l,
+	}
+}
+
+func (c *RewardPaidConsumer) Start() error {
+	cg, err := sarama.NewConsumerGroupFromClient("notification_reward", c.client)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	go saramax.ConsumeLoop(ctx, cg, []string{topicRewardPaid},
+		saramax.NewHandler[RewardPaidEvent](c.l, c.Consume), c.l)
+	return nil
+}
+
+// Close 停止消费
+func (c *RewardPaidConsumer) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	return nil
+}
+
+func (c *RewardPaidConsumer) Consume(msg *sarama.ConsumerMessage, evt RewardPaidEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return c.svc.Notify(ctx, domain.Event{
+		Uid:   evt.TargetUid,
+		Type:  domain.NotificationTypeReward,
+		Biz:   evt.Biz,
+		BizId: evt.BizId,
+		Actor: evt.Uid,
+		RefId: evt.Rid,
+	})
+}
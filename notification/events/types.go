@@ -0,0 +1,36 @@
+package events
+
+// CommentCreatedEvent 和 comment/events.CommentCreatedEvent 保持一致
+type CommentCreatedEvent struct {
+	Id        int64
+	Biz       string
+	BizId     int64
+	BizOwner  int64
+	Uid       int64
+	ParentId  int64
+	ParentUid int64
+	Content   string
+}
+
+// FollowCreatedEvent 和 follow/events.FollowCreatedEvent 保持一致
+type FollowCreatedEvent struct {
+	Follower int64
+	Followee int64
+}
+
+// LikedEvent 和 interactive/events.LikedEvent 保持一致
+type LikedEvent struct {
+	Biz      string
+	BizId    int64
+	BizOwner int64
+	Uid      int64
+}
+
+// RewardPaidEvent 和 reward/events/reward.PaidEvent 保持一致，只需要其中几个字段
+type RewardPaidEvent struct {
+	Rid       int64
+	Uid       int64
+	Biz       string
+	BizId     int64
+	TargetUid int64
+}
@@ -0,0 +1,91 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/ecodeclub/ekit/slice"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	notificationv1 "webooktrial/api/proto/gen/notification/v1"
+	"webooktrial/notification/domain"
+	"webooktrial/notification/service"
+	"webooktrial/pkg/pagination"
+)
+
+// maxLimit 一页最多这么多条
+const maxLimit = 100
+
+type NotificationServiceServer struct {
+	notificationv1.UnimplementedNotificationServiceServer
+	svc service.NotificationService
+	// codec 通知列表的分页游标
+	codec *pagination.Codec
+}
+
+func NewNotificationServiceServer(svc service.NotificationService,
+	codec *pagination.Codec) *NotificationServiceServer {
+	return &NotificationServiceServer{svc: svc, codec: codec}
+}
+
+func (n *NotificationServiceServer) Register(server *grpc.Server) {
+	notificationv1.RegisterNotificationServiceServer(server, n)
+}
+
+func (n *NotificationServiceServer) List(ctx context.Context, request *notificationv1.ListRequest) (*notificationv1.ListResponse, error) {
+	if request.GetLimit() <= 0 || request.GetLimit() > maxLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit 必须在 1 到 %d 之间", maxLimit)
+	}
+	cursor, err := n.codec.Decode(request.GetCursor())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	list, err := n.svc.List(ctx, request.GetUid(),
+		domain.NotificationType(request.GetType()), cursor, request.GetLimit())
+	if err != nil {
+		return nil, err
+	}
+	next := pagination.Next(list, int(request.GetLimit()), func(src domain.Notification) pagination.Cursor {
+		return pagination.Cursor{Key: src.Utime.UnixMilli(), Id: src.Id}
+	})
+	return &notificationv1.ListResponse{
+		Notifications: slice.Map(list, func(idx int, src domain.Notification) *notificationv1.Notification {
+			return n.toDTO(src)
+		}),
+		NextCursor: n.codec.Encode(next),
+	}, nil
+}
+
+func (n *NotificationServiceServer) UnreadCount(ctx context.Context, request *notificationv1.UnreadCountRequest) (*notificationv1.UnreadCountResponse, error) {
+	cnts, err := n.svc.UnreadCount(ctx, request.GetUid())
+	if err != nil {
+		return nil, err
+	}
+	res := &notificationv1.UnreadCountResponse{Counts: make(map[int32]int64, len(cnts))}
+	for typ, cnt := range cnts {
+		res.Counts[int32(typ)] = cnt
+		res.Total += cnt
+	}
+	return res, nil
+}
+
+func (n *NotificationServiceServer) MarkRead(ctx context.Context, request *notificationv1.MarkReadRequest) (*notificationv1.MarkReadResponse, error) {
+	err := n.svc.MarkRead(ctx, request.GetUid(),
+		domain.NotificationType(request.GetType()), request.GetIds())
+	return &notificationv1.MarkReadResponse{}, err
+}
+
+func (n *NotificationServiceServer) toDTO(src domain.Notification) *notificationv1.Notification {
+	return &notificationv1.Notification{
+		Id:        src.Id,
+		Type:      notificationv1.NotificationType(src.Type),
+		Biz:       src.Biz,
+		BizId:     src.BizId,
+		ActorCnt:  src.ActorCnt,
+		LastActor: src.LastActor,
+		Content:   src.Content,
+		Read:      src.Read,
+		Utime:     src.Utime.UnixMilli(),
+	}
+}
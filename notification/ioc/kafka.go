@@ -0,0 +1,38 @@
+package ioc
+
+import (
+	"github.com/IBM/sarama"
+	"github.com/spf13/viper"
+
+	"webooktrial/notification/events"
+	"webooktrial/pkg/saramax"
+)
+
+func InitKafka() sarama.Client {
+	type Config struct {
+		Addrs []string `yaml:"addrs"`
+	}
+	saramaCfg := sarama.NewConfig()
+	var cfg Config
+	err := viper.UnmarshalKey("kafka", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	client, err := sarama.NewClient(cfg.Addrs, saramaCfg)
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
+func NewConsumers(comment *events.CommentCreatedConsumer,
+	follow *events.FollowCreatedConsumer,
+	liked *events.LikedConsumer,
+	reward *events.RewardPaidConsumer) []saramax.CloseableConsumer {
+	return []saramax.CloseableConsumer{
+		comment,
+		follow,
+		liked,
+		reward,
+	}
+}
@@ -0,0 +1,15 @@
+package ioc
+
+import (
+	"go.uber.org/zap"
+
+	"webooktrial/pkg/logger"
+)
+
+func InitLogger() logger.LoggerV1 {
+	l, err := zap.NewDevelopment()
+	if err != nil {
+		panic(err)
+	}
+	return logger.NewRedactLogger(logger.NewZapLogger(l), logger.NewRedactor())
+}
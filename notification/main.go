@@ -0,0 +1,35 @@
+package main
+
+import (
+	"log"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func main() {
+	initViper()
+	app := InitApp()
+	for _, c := range app.consumers {
+		err := c.Start()
+		if err != nil {
+			panic(err)
+		}
+	}
+	err := app.server.Serve()
+	log.Println(err)
+	for _, c := range app.consumers {
+		_ = c.Close()
+	}
+}
+
+func initViper() {
+	cfile := pflag.String("config",
+		"config/dev.yaml", "指定配置文件路径")
+	pflag.Parse()
+	viper.SetConfigFile(*cfile)
+	err := viper.ReadInConfig()
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,148 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"webooktrial/pkg/pagination"
+)
+
+// NotificationGroup 聚合之后的一条通知。
+// 典型查询是某个人的通知列表，按照 <utime, id> 倒序
+type NotificationGroup struct {
+	Id    int64  `gorm:"primaryKey,autoIncrement"`
+	Uid   int64  `gorm:"uniqueIndex:uid_type_biz,priority:1;index:uid_utime_id,priority:1;index:uid_unread,priority:1"`
+	Type  uint8  `gorm:"uniqueIndex:uid_type_biz,priority:2"`
+	Biz   string `gorm:"type:varchar(64);uniqueIndex:uid_type_biz,priority:3"`
+	BizId int64  `gorm:"uniqueIndex:uid_type_biz,priority:4"`
+	// ActorCnt 不同的 actor 的个数
+	ActorCnt  int64
+	LastActor int64
+	Content   string `gorm:"type:varchar(512)"`
+	Unread    bool   `gorm:"index:uid_unread,priority:2"`
+	Ctime     int64
+	Utime     int64 `gorm:"index:uid_utime_id,priority:2"`
+}
+
+// NotificationActor 聚合进来的每一次操作，唯一索引保证重复消费不会重复计数
+type NotificationActor struct {
+	Id      int64 `gorm:"primaryKey,autoIncrement"`
+	GroupId int64 `gorm:"uniqueIndex:group_actor_ref,priority:1"`
+	Actor   int64 `gorm:"uniqueIndex:group_actor_ref,priority:2"`
+	RefId   int64 `gorm:"uniqueIndex:group_actor_ref,priority:3"`
+	Ctime   int64
+}
+
+type NotificationDAO interface {
+	// Upsert 把 a 聚合到 g 里面，g 不存在就创建。
+	// a 已经聚合过了什么也不做，所以重试是安全的
+	Upsert(ctx context.Context, g NotificationGroup, a NotificationActor) error
+	// FindByUid typ 为 0 的时候不限类型。cursor 的 Key 是 utime，Id 是 id，零值表示第一页
+	FindByUid(ctx context.Context, uid int64, typ uint8, cursor pagination.Cursor, limit int64) ([]NotificationGroup, error)
+	// CountUnread 每个类型的未读数
+	CountUnread(ctx context.Context, uid int64) (map[uint8]int64, error)
+	// MarkRead ids 为空的时候按照 typ 标记，typ 为 0 就是全部
+	MarkRead(ctx context.Context, uid int64, typ uint8, ids []int64) error
+}
+
+type GORMNotificationDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMNotificationDAO(db *gorm.DB) NotificationDAO {
+	return &GORMNotificationDAO{db: db}
+}
+
+func (g *GORMNotificationDAO) Upsert(ctx context.Context, group NotificationGroup, a NotificationActor) error {
+	now := time.Now().UnixMilli()
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		group.Ctime, group.Utime = now, now
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&group).Error
+		if err != nil {
+			return err
+		}
+		// 不管是不是刚创建的，都要锁住，并发聚合的时候 actor_cnt 才不会算错
+		var existing NotificationGroup
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("uid = ? AND type = ? AND biz = ? AND biz_id = ?",
+				group.Uid, group.Type, group.Biz, group.BizId).
+			First(&existing).Error
+		if err != nil {
+			return err
+		}
+		a.GroupId, a.Ctime = existing.Id, now
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&a)
+		if res.Error != nil || res.RowsAffected == 0 {
+			// 重复消费
+			return res.Error
+		}
+		var cnt int64
+		err = tx.Model(&NotificationActor{}).
+			Where("group_id = ? AND actor = ?", existing.Id, a.Actor).
+			Count(&cnt).Error
+		if err != nil {
+			return err
+		}
+		updates := map[string]any{
+			"last_actor": a.Actor,
+			"content":    group.Content,
+			"unread":     true,
+			"utime":      now,
+		}
+		if cnt == 1 {
+			updates["actor_cnt"] = gorm.Expr("actor_cnt + 1")
+		}
+		return tx.Model(&NotificationGroup{}).Where("id = ?", existing.Id).
+			Updates(updates).Error
+	})
+}
+
+func (g *GORMNotificationDAO) FindByUid(ctx context.Context, uid int64, typ uint8,
+	cursor pagination.Cursor, limit int64) ([]NotificationGroup, error) {
+	var res []NotificationGroup
+	db := g.db.WithContext(ctx).Where("uid = ?", uid)
+	if typ > 0 {
+		db = db.Where("type = ?", typ)
+	}
+	if !cursor.IsZero() {
+		db = db.Where("(utime < ? OR (utime = ? AND id < ?))", cursor.Key, cursor.Key, cursor.Id)
+	}
+	err := db.Order("utime DESC, id DESC").Limit(int(limit)).Find(&res).Error
+	return res, err
+}
+
+func (g *GORMNotificationDAO) CountUnread(ctx context.Context, uid int64) (map[uint8]int64, error) {
+	var rows []struct {
+		Type uint8
+		Cnt  int64
+	}
+	err := g.db.WithContext(ctx).Model(&NotificationGroup{}).
+		Select("type, COUNT(*) AS cnt").
+		Where("uid = ? AND unread = ?", uid, true).
+		Group("type").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[uint8]int64, len(rows))
+	for _, r := range rows {
+		res[r.Type] = r.Cnt
+	}
+	return res, nil
+}
+
+func (g *GORMNotificationDAO) MarkRead(ctx context.Context, uid int64, typ uint8, ids []int64) error {
+	db := g.db.WithContext(ctx).Model(&NotificationGroup{}).
+		Where("uid = ? AND unread = ?", uid, true)
+	switch {
+	case len(ids) > 0:
+		db = db.Where("id IN ?", ids)
+	case typ > 0:
+		db = db.Where("type = ?", typ)
+	}
+	// 不更新 utime，已读不能改变列表的顺序
+	return db.UpdateColumn("unread", false).Error
+}
@@ -0,0 +1,139 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestGORMNotificationDAO_Upsert(t *testing.T) {
+	groupArgs := []driver.Value{int64(1), uint8(2), "article", int64(10), int64(0), int64(0),
+		"写得好", false, sqlmock.AnyArg(), sqlmock.AnyArg()}
+	testCases := []struct {
+		name string
+		mock func(t *testing.T) *sql.DB
+
+		wantErr error
+	}{
+		{
+			name: "新的 actor，actor_cnt 加一",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `notification_groups` .* ON DUPLICATE KEY UPDATE").
+					WithArgs(groupArgs...).
+					WillReturnResult(sqlmock.NewResult(5, 1))
+				mock.ExpectQuery("SELECT \\* FROM `notification_groups` WHERE .* FOR UPDATE").
+					WithArgs(int64(1), uint8(2), "article", int64(10)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "uid", "actor_cnt"}).AddRow(5, 1, 3))
+				mock.ExpectExec("INSERT INTO `notification_actors` .* ON DUPLICATE KEY UPDATE").
+					WithArgs(int64(5), int64(3), int64(100), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(7, 1))
+				mock.ExpectQuery("SELECT count\\(\\*\\) FROM `notification_actors` WHERE group_id = \\? AND actor = \\?").
+					WithArgs(int64(5), int64(3)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectExec("UPDATE `notification_groups` SET `actor_cnt`=actor_cnt \\+ 1,`content`=\\?,`last_actor`=\\?,`unread`=\\?,`utime`=\\? WHERE id = \\?").
+					WithArgs("写得好", int64(3), true, sqlmock.AnyArg(), int64(5)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+		},
+		{
+			name: "同一个 actor 又来了一次，actor_cnt 不变",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `notification_groups` .* ON DUPLICATE KEY UPDATE").
+					WithArgs(groupArgs...).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT \\* FROM `notification_groups` WHERE .* FOR UPDATE").
+					WithArgs(int64(1), uint8(2), "article", int64(10)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "uid", "actor_cnt"}).AddRow(5, 1, 3))
+				mock.ExpectExec("INSERT INTO `notification_actors` .* ON DUPLICATE KEY UPDATE").
+					WithArgs(int64(5), int64(3), int64(100), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(8, 1))
+				mock.ExpectQuery("SELECT count\\(\\*\\) FROM `notification_actors` WHERE group_id = \\? AND actor = \\?").
+					WithArgs(int64(5), int64(3)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectExec("UPDATE `notification_groups` SET `content`=\\?,`last_actor`=\\?,`unread`=\\?,`utime`=\\? WHERE id = \\?").
+					WithArgs("写得好", int64(3), true, sqlmock.AnyArg(), int64(5)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+		},
+		{
+			name: "重复消费，什么也不做",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `notification_groups` .* ON DUPLICATE KEY UPDATE").
+					WithArgs(groupArgs...).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT \\* FROM `notification_groups` WHERE .* FOR UPDATE").
+					WithArgs(int64(1), uint8(2), "article", int64(10)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "uid", "actor_cnt"}).AddRow(5, 1, 3))
+				mock.ExpectExec("INSERT INTO `notification_actors` .* ON DUPLICATE KEY UPDATE").
+					WithArgs(int64(5), int64(3), int64(100), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+				return db
+			},
+		},
+		{
+			name: "更新失败，回滚",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `notification_groups` .* ON DUPLICATE KEY UPDATE").
+					WithArgs(groupArgs...).
+					WillReturnResult(sqlmock.NewResult(5, 1))
+				mock.ExpectQuery("SELECT \\* FROM `notification_groups` WHERE .* FOR UPDATE").
+					WithArgs(int64(1), uint8(2), "article", int64(10)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "uid", "actor_cnt"}).AddRow(5, 1, 0))
+				mock.ExpectExec("INSERT INTO `notification_actors` .* ON DUPLICATE KEY UPDATE").
+					WithArgs(int64(5), int64(3), int64(100), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(7, 1))
+				mock.ExpectQuery("SELECT count\\(\\*\\) FROM `notification_actors`").
+					WithArgs(int64(5), int64(3)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectExec("UPDATE `notification_groups`").
+					WithArgs("写得好", int64(3), true, sqlmock.AnyArg(), int64(5)).
+					WillReturnError(errors.New("db 错误"))
+				mock.ExpectRollback()
+				return db
+			},
+			wantErr: errors.New("db 错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+				Conn:                      tc.mock(t),
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			require.NoError(t, err)
+			d := NewGORMNotificationDAO(db)
+			err = d.Upsert(context.Background(), NotificationGroup{
+				Uid: 1, Type: 2, Biz: "article", BizId: 10, Content: "写得好",
+			}, NotificationActor{Actor: 3, RefId: 100})
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"webooktrial/notification/domain"
+	"webooktrial/notification/repository/dao"
+	"webooktrial/pkg/pagination"
+)
+
+//go:generate mockgen -source=./notification.go -package=repomocks -destination=mocks/notification.mock.go NotificationRepository
+type NotificationRepository interface {
+	// Aggregate 聚合到对应的通知里面，重复的 Event 会被忽略
+	Aggregate(ctx context.Context, evt domain.Event) error
+	// FindByUid typ 为 NotificationTypeUnknown 的时候不限类型。
+	// cursor 的 Key 是更新时间的毫秒数，Id 是通知 ID，零值表示第一页
+	FindByUid(ctx context.Context, uid int64, typ domain.NotificationType,
+		cursor pagination.Cursor, limit int64) ([]domain.Notification, error)
+	CountUnread(ctx context.Context, uid int64) (map[domain.NotificationType]int64, error)
+	// MarkRead ids 为空的时候按照 typ 标记
+	MarkRead(ctx context.Context, uid int64, typ domain.NotificationType, ids []int64) error
+}
+
+type notificationRepository struct {
+	dao dao.NotificationDAO
+}
+
+func NewNotificationRepository(dao dao.NotificationDAO) NotificationRepository {
+	return &notificationRepository{dao: dao}
+}
+
+func (n *notificationRepository) Aggregate(ctx context.Context, evt domain.Event) error {
+	return n.dao.Upsert(ctx, dao.NotificationGroup{
+		Uid:     evt.Uid,
+		Type:    uint8(evt.Type),
+		Biz:     evt.Biz,
+		BizId:   evt.BizId,
+		Content: evt.Content,
+	}, dao.NotificationActor{
+		Actor: evt.Actor,
+		RefId: evt.RefId,
+	})
+}
+
+func (n *notificationRepository) FindByUid(ctx context.Context, uid int64, typ domain.NotificationType,
+	cursor pagination.Cursor, limit int64) ([]domain.Notification, error) {
+	groups, err := n.dao.FindByUid(ctx, uid, uint8(typ), cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Notification, 0, len(groups))
+	for _, g := range groups {
+		res = append(res, n.toDomain(g))
+	}
+	return res, nil
+}
+
+func (n *notificationRepository) CountUnread(ctx context.Context, uid int64) (map[domain.NotificationType]int64, error) {
+	cnts, err := n.dao.CountUnread(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[domain.NotificationType]int64, len(cnts))
+	for typ, cnt := range cnts {
+		res[domain.NotificationType(typ)] = cnt
+	}
+	return res, nil
+}
+
+func (n *notificationRepository) MarkRead(ctx context.Context, uid int64,
+	typ domain.NotificationType, ids []int64) error {
+	return n.dao.MarkRead(ctx, uid, uint8(typ), ids)
+}
+
+func (n *notificationRepository) toDomain(g dao.NotificationGroup) domain.Notification {
+	return domain.Notification{
+		Id:        g.Id,
+		Uid:       g.Uid,
+		Type:      domain.NotificationType(g.Type),
+		Biz:       g.Biz,
+		BizId:     g.BizId,
+		ActorCnt:  g.ActorCnt,
+		LastActor: g.LastActor,
+		Content:   g.Content,
+		Read:      !g.Unread,
+		Utime:     time.UnixMilli(g.Utime),
+	}
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// maxMentions 一条评论最多通知这么多个被 @ 的人，多出来的忽略，防止用来刷通知
+const maxMentions = 10
+
+// mentionPattern 客户端选中用户之后插入 @[昵称](uid)，展示的时候再渲染成链接。
+// 只认 uid，昵称改了也不影响
+var mentionPattern = regexp.MustCompile(`@\[[^\[\]]{1,32}\]\((\d{1,19})\)`)
+
+// ParseMentions 按照出现的顺序返回被 @ 的 uid，去掉了重复的
+func ParseMentions(content string) []int64 {
+	var res []int64
+	seen := make(map[int64]struct{})
+	for _, m := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		uid, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil || uid <= 0 {
+			continue
+		}
+		if _, ok := seen[uid]; ok {
+			continue
+		}
+		seen[uid] = struct{}{}
+		res = append(res, uid)
+		if len(res) == maxMentions {
+			break
+		}
+	}
+	return res
+}
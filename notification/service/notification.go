@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+
+	followcli "webooktrial/follow/client"
+	"webooktrial/notification/domain"
+	"webooktrial/notification/repository"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/pagination"
+)
+
+const (
+	// contentLimit 通知里面只保留评论的前面这么多个字
+	contentLimit = 100
+	// bizComment 回复和 @ 的通知关联的是评论
+	bizComment = "comment"
+	// bizUser 关注的通知关联的是被关注的人
+	bizUser = "user"
+)
+
+type NotificationService interface {
+	// Notify 自己对自己的操作、接收者拉黑了的人的操作都不通知。
+	// 重复的 Event 会被忽略，所以重试是安全的
+	Notify(ctx context.Context, evts ...domain.Event) error
+	// NotifyComment 通知被回复的人、被 @ 的人，一级评论还会通知评论对象的作者。
+	// 同一个人只会收到其中一种通知
+	NotifyComment(ctx context.Context, c domain.Comment) error
+	// NotifyFollow 所有的关注聚合成一条通知
+	NotifyFollow(ctx context.Context, follower, followee int64) error
+
+	List(ctx context.Context, uid int64, typ domain.NotificationType,
+		cursor pagination.Cursor, limit int64) ([]domain.Notification, error)
+	UnreadCount(ctx context.Context, uid int64) (map[domain.NotificationType]int64, error)
+	// MarkRead ids 为空的时候把 typ 下面的全部标记为已读，typ 也为空就是全部
+	MarkRead(ctx context.Context, uid int64, typ domain.NotificationType, ids []int64) error
+}
+
+type notificationService struct {
+	repo repository.NotificationRepository
+	// blockChecker 被拉黑的人的操作不通知
+	blockChecker followcli.BlockChecker
+	l            logger.LoggerV1
+}
+
+func NewNotificationService(repo repository.NotificationRepository,
+	blockChecker followcli.BlockChecker, l logger.LoggerV1) NotificationService {
+	return &notificationService{repo: repo, blockChecker: blockChecker, l: l}
+}
+
+func (n *notificationService) Notify(ctx context.Context, evts ...domain.Event) error {
+	for _, evt := range evts {
+		if evt.Uid <= 0 || evt.Uid == evt.Actor {
+			continue
+		}
+		blocked, err := n.blockChecker.IsBlocked(ctx, evt.Uid, evt.Actor)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			continue
+		}
+		evt.Content = truncate(evt.Content, contentLimit)
+		err = n.repo.Aggregate(ctx, evt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *notificationService) NotifyComment(ctx context.Context, c domain.Comment) error {
+	var evts []domain.Event
+	// notified 同一条评论，回复了我又 @ 了我，只通知一次
+	notified := make(map[int64]struct{})
+	add := func(evt domain.Event) {
+		if _, ok := notified[evt.Uid]; ok {
+			return
+		}
+		notified[evt.Uid] = struct{}{}
+		evt.Actor, evt.RefId, evt.Content = c.Uid, c.Id, c.Content
+		evts = append(evts, evt)
+	}
+	if c.ParentId > 0 {
+		if c.ParentUid > 0 {
+			add(domain.Event{Uid: c.ParentUid, Type: domain.NotificationTypeReply,
+				Biz: bizComment, BizId: c.ParentId})
+		}
+	} else if c.BizOwner > 0 {
+		add(domain.Event{Uid: c.BizOwner, Type: domain.NotificationTypeComment,
+			Biz: c.Biz, BizId: c.BizId})
+	}
+	for _, uid := range ParseMentions(c.Content) {
+		add(domain.Event{Uid: uid, Type: domain.NotificationTypeMention,
+			Biz: bizComment, BizId: c.Id})
+	}
+	return n.Notify(ctx, evts...)
+}
+
+func (n *notificationService) NotifyFollow(ctx context.Context, follower, followee int64) error {
+	return n.Notify(ctx, domain.Event{
+		Uid:   followee,
+		Type:  domain.NotificationTypeFollow,
+		Biz:   bizUser,
+		BizId: followee,
+		Actor: follower,
+	})
+}
+
+func (n *notificationService) List(ctx context.Context, uid int64, typ domain.NotificationType,
+	cursor pagination.Cursor, limit int64) ([]domain.Notification, error) {
+	return n.repo.FindByUid(ctx, uid, typ, cursor, limit)
+}
+
+func (n *notificationService) UnreadCount(ctx context.Context, uid int64) (map[domain.NotificationType]int64, error) {
+	return n.repo.CountUnread(ctx, uid)
+}
+
+func (n *notificationService) MarkRead(ctx context.Context, uid int64,
+	typ domain.NotificationType, ids []int64) error {
+	return n.repo.MarkRead(ctx, uid, typ, ids)
+}
+
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit]) + "…"
+}
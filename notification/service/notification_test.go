@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	followcli "webooktrial/follow/client"
+	"webooktrial/notification/domain"
+	"webooktrial/notification/repository"
+	repomocks "webooktrial/notification/repository/mocks"
+	"webooktrial/pkg/logger"
+)
+
+// fakeBlockChecker blocked 里面的 [blocker, blocked] 是拉黑了的
+type fakeBlockChecker struct {
+	blocked map[[2]int64]bool
+	err     error
+}
+
+func (f fakeBlockChecker) IsBlocked(ctx context.Context, blocker, blocked int64) (bool, error) {
+	return f.blocked[[2]int64{blocker, blocked}], f.err
+}
+
+func TestNotificationService_NotifyComment(t *testing.T) {
+	testCases := []struct {
+		name    string
+		comment domain.Comment
+		checker followcli.BlockChecker
+		mock    func(ctrl *gomock.Controller) repository.NotificationRepository
+
+		wantErr error
+	}{
+		{
+			name: "一级评论通知作者和被 @ 的人",
+			comment: domain.Comment{Id: 100, Biz: "article", BizId: 10, BizOwner: 1, Uid: 2,
+				Content: "写得好 @[小明](3) @[小红](4)"},
+			mock: func(ctrl *gomock.Controller) repository.NotificationRepository {
+				repo := repomocks.NewMockNotificationRepository(ctrl)
+				content := "写得好 @[小明](3) @[小红](4)"
+				gomock.InOrder(
+					repo.EXPECT().Aggregate(gomock.Any(), domain.Event{Uid: 1, Type: domain.NotificationTypeComment,
+						Biz: "article", BizId: 10, Actor: 2, RefId: 100, Content: content}).Return(nil),
+					repo.EXPECT().Aggregate(gomock.Any(), domain.Event{Uid: 3, Type: domain.NotificationTypeMention,
+						Biz: bizComment, BizId: 100, Actor: 2, RefId: 100, Content: content}).Return(nil),
+					repo.EXPECT().Aggregate(gomock.Any(), domain.Event{Uid: 4, Type: domain.NotificationTypeMention,
+						Biz: bizComment, BizId: 100, Actor: 2, RefId: 100, Content: content}).Return(nil),
+				)
+				return repo
+			},
+		},
+		{
+			name: "回复并且 @ 了同一个人，只通知回复；@ 自己不通知",
+			comment: domain.Comment{Id: 101, Biz: "article", BizId: 10, BizOwner: 1, Uid: 2,
+				ParentId: 100, ParentUid: 3, Content: "@[小明](3) @[我](2)"},
+			mock: func(ctrl *gomock.Controller) repository.NotificationRepository {
+				repo := repomocks.NewMockNotificationRepository(ctrl)
+				repo.EXPECT().Aggregate(gomock.Any(), domain.Event{Uid: 3, Type: domain.NotificationTypeReply,
+					Biz: bizComment, BizId: 100, Actor: 2, RefId: 101, Content: "@[小明](3) @[我](2)"}).Return(nil)
+				return repo
+			},
+		},
+		{
+			name:    "被拉黑了不通知",
+			comment: domain.Comment{Id: 102, Biz: "article", BizId: 10, BizOwner: 1, Uid: 2, Content: "hi"},
+			checker: fakeBlockChecker{blocked: map[[2]int64]bool{{1, 2}: true}},
+			mock: func(ctrl *gomock.Controller) repository.NotificationRepository {
+				return repomocks.NewMockNotificationRepository(ctrl)
+			},
+		},
+		{
+			name:    "查询拉黑失败照常通知，内容太长截断",
+			comment: domain.Comment{Id: 103, Biz: "article", BizId: 10, BizOwner: 1, Uid: 2, Content: strings.Repeat("长", 120)},
+			checker: followcli.NewFailOpenBlockChecker(fakeBlockChecker{err: errors.New("follow 挂了")},
+				logger.NewNopLogger()),
+			mock: func(ctrl *gomock.Controller) repository.NotificationRepository {
+				repo := repomocks.NewMockNotificationRepository(ctrl)
+				repo.EXPECT().Aggregate(gomock.Any(), domain.Event{Uid: 1, Type: domain.NotificationTypeComment,
+					Biz: "article", BizId: 10, Actor: 2, RefId: 103,
+					Content: strings.Repeat("长", contentLimit) + "…"}).Return(nil)
+				return repo
+			},
+		},
+		{
+			name:    "保存失败",
+			comment: domain.Comment{Id: 104, Biz: "article", BizId: 10, BizOwner: 1, Uid: 2},
+			mock: func(ctrl *gomock.Controller) repository.NotificationRepository {
+				repo := repomocks.NewMockNotificationRepository(ctrl)
+				repo.EXPECT().Aggregate(gomock.Any(), gomock.Any()).Return(errors.New("db 错误"))
+				return repo
+			},
+			wantErr: errors.New("db 错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			checker := tc.checker
+			if checker == nil {
+				checker = fakeBlockChecker{}
+			}
+			svc := NewNotificationService(tc.mock(ctrl), checker, logger.NewNopLogger())
+			err := svc.NotifyComment(context.Background(), tc.comment)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestParseMentions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		want    []int64
+	}{
+		{name: "没有 @", content: "普通的评论 @小明", want: nil},
+		{name: "去重并且保持顺序", content: "@[a](3) @[b](2) @[a](3)", want: []int64{3, 2}},
+		{name: "uid 不合法", content: "@[a](0) @[b](abc) @[c](99999999999999999999)", want: nil},
+		{name: "最多 10 个",
+			content: "@[x](1)@[x](2)@[x](3)@[x](4)@[x](5)@[x](6)" +
+				"@[x](7)@[x](8)@[x](9)@[x](10)@[x](11)",
+			want: []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ParseMentions(tc.content))
+		})
+	}
+}
@@ -0,0 +1,46 @@
+//go:build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+
+	followcli "webooktrial/follow/client"
+	"webooktrial/notification/events"
+	"webooktrial/notification/grpc"
+	"webooktrial/notification/ioc"
+	"webooktrial/notification/repository"
+	"webooktrial/notification/repository/dao"
+	"webooktrial/notification/service"
+	"webooktrial/pkg/grpcx"
+)
+
+var thirdPartySet = wire.NewSet(
+	ioc.InitLogger,
+	ioc.InitDB,
+	ioc.InitKafka,
+	grpcx.InitEtcdClient,
+	ioc.InitFollowClient,
+	followcli.InitBlockChecker,
+	ioc.InitCursorCodec)
+
+var notificationSvcProvider = wire.NewSet(
+	service.NewNotificationService,
+	repository.NewNotificationRepository,
+	dao.NewGORMNotificationDAO,
+)
+
+func InitApp() *App {
+	wire.Build(thirdPartySet,
+		notificationSvcProvider,
+		events.NewCommentCreatedConsumer,
+		events.NewFollowCreatedConsumer,
+		events.NewLikedConsumer,
+		events.NewRewardPaidConsumer,
+		grpc.NewNotificationServiceServer,
+		ioc.NewConsumers,
+		ioc.InitGRPCxServer,
+		wire.Struct(new(App), "*"),
+	)
+	return new(App)
+}
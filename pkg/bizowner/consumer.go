@@ -0,0 +1,70 @@
+package bizowner
+
+import (
+	"context"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/saramax"
+)
+
+const topicArticlePublished = "article_published"
+
+// ArticlePublishedEvent 和 internal/events/article.PublishedEvent 保持一致，
+// 只需要其中几个字段
+type ArticlePublishedEvent struct {
+	Aid int64
+	Uid int64
+}
+
+// ArticleConsumer 把文章的作者同步到本地。
+// 每个服务各存一份，所以 group 要用自己服务的名字
+type ArticleConsumer struct {
+	client sarama.Client
+	group  string
+	dao    DAO
+	l      logger.LoggerV1
+	cancel context.CancelFunc
+}
+
+func NewArticleConsumer(client sarama.Client, group string,
+	dao DAO, l logger.LoggerV1) *ArticleConsumer {
+	return &ArticleConsumer{
+		client: client,
+		group:  group,
+		dao:    dao,
+		l:      l,
+	}
+}
+
+func (a *ArticleConsumer) Start() error {
+	cg, err := sarama.NewConsumerGroupFromClient(a.group, a.client)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	go saramax.ConsumeLoop(ctx, cg, []string{topicArticlePublished},
+		saramax.NewHandler[ArticlePublishedEvent](a.l, a.Consume), a.l)
+	return nil
+}
+
+// Close 停止消费
+func (a *ArticleConsumer) Close() error {
+	if a.cancel != nil {
+		a.cancel()
+	}
+	return nil
+}
+
+func (a *ArticleConsumer) Consume(msg *sarama.ConsumerMessage, evt ArticlePublishedEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return a.dao.Upsert(ctx, BizOwner{
+		Biz:   BizArticle,
+		BizId: evt.Aid,
+		Owner: evt.Uid,
+	})
+}
@@ -0,0 +1,61 @@
+package bizowner
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BizOwner 资源和它的作者，文章的作者不会变，所以重复同步是幂等的
+type BizOwner struct {
+	Id    int64  `gorm:"primaryKey,autoIncrement"`
+	Biz   string `gorm:"type:varchar(128);uniqueIndex:biz_type_id"`
+	BizId int64  `gorm:"uniqueIndex:biz_type_id"`
+	Owner int64
+	Ctime int64
+	Utime int64
+}
+
+type DAO interface {
+	Upsert(ctx context.Context, o BizOwner) error
+	// Find 找不到的时候返回 ErrOwnerNotFound
+	Find(ctx context.Context, biz string, bizId int64) (BizOwner, error)
+}
+
+type GORMDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMDAO(db *gorm.DB) DAO {
+	return &GORMDAO{db: db}
+}
+
+func InitTables(db *gorm.DB) error {
+	return db.AutoMigrate(&BizOwner{})
+}
+
+func (g *GORMDAO) Upsert(ctx context.Context, o BizOwner) error {
+	now := time.Now().UnixMilli()
+	o.Ctime = now
+	o.Utime = now
+	return g.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"owner": o.Owner,
+			"utime": now,
+		}),
+	}).Create(&o).Error
+}
+
+func (g *GORMDAO) Find(ctx context.Context, biz string, bizId int64) (BizOwner, error) {
+	var res BizOwner
+	err := g.db.WithContext(ctx).
+		Where("biz = ? AND biz_id = ?", biz, bizId).
+		First(&res).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return res, ErrOwnerNotFound
+	}
+	return res, err
+}
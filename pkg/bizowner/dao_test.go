@@ -0,0 +1,110 @@
+package bizowner
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func newMockDB(t *testing.T, mock func(mock sqlmock.Sqlmock)) *gorm.DB {
+	sqlDB, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock(m)
+	db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+		Conn:                      sqlDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	return db
+}
+
+func TestGORMDAO_Upsert(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "插入或者更新作者",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO `biz_owners` .* ON DUPLICATE KEY UPDATE `owner`=\\?,`utime`=\\?").
+					WithArgs("article", int64(1), int64(123), sqlmock.AnyArg(), sqlmock.AnyArg(),
+						int64(123), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name: "数据库错误",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO `biz_owners` .*").
+					WithArgs("article", int64(1), int64(123), sqlmock.AnyArg(), sqlmock.AnyArg(),
+						int64(123), sqlmock.AnyArg()).
+					WillReturnError(errors.New("mock db error"))
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewGORMDAO(newMockDB(t, tc.mock))
+			err := d.Upsert(context.Background(), BizOwner{Biz: BizArticle, BizId: 1, Owner: 123})
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestGORMDAO_Find(t *testing.T) {
+	testCases := []struct {
+		name      string
+		mock      func(mock sqlmock.Sqlmock)
+		wantOwner int64
+		wantErr   error
+	}{
+		{
+			name: "找到了",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `biz_owners` WHERE biz = \\? AND biz_id = \\?").
+					WithArgs("article", int64(1)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "biz", "biz_id", "owner"}).
+						AddRow(1, "article", 1, 123))
+			},
+			wantOwner: 123,
+		},
+		{
+			name: "还没有同步过来",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `biz_owners` WHERE biz = \\? AND biz_id = \\?").
+					WithArgs("article", int64(1)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			wantErr: ErrOwnerNotFound,
+		},
+		{
+			name: "数据库错误",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `biz_owners` .*").
+					WithArgs("article", int64(1)).
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: sql.ErrConnDone,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewGORMDAO(newMockDB(t, tc.mock))
+			o, err := d.Find(context.Background(), BizArticle, 1)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantOwner, o.Owner)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package bizowner
+
+import (
+	"context"
+	"encoding/binary"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/coocood/freecache"
+	"gorm.io/gorm"
+
+	"webooktrial/pkg/logger"
+)
+
+// CachedResolver 查数据库，结果在本地缓存一段时间。
+// 作者不会变，所以缓存多久都可以，找不到的不缓存，同步过来之后马上就能查到
+type CachedResolver struct {
+	dao        DAO
+	cache      *freecache.Cache
+	expiration time.Duration
+}
+
+func NewCachedResolver(dao DAO, expiration time.Duration) *CachedResolver {
+	return &CachedResolver{
+		dao: dao,
+		// 每一条只有几十个字节，16M 足够了
+		cache:      freecache.NewCache(16 * 1024 * 1024),
+		expiration: expiration,
+	}
+}
+
+func (r *CachedResolver) Owner(ctx context.Context, biz string, bizId int64) (int64, error) {
+	key := r.key(biz, bizId)
+	val, err := r.cache.Get(key)
+	if err == nil {
+		return int64(binary.BigEndian.Uint64(val)), nil
+	}
+	o, err := r.dao.Find(ctx, biz, bizId)
+	if err != nil {
+		return 0, err
+	}
+	val = make([]byte, 8)
+	binary.BigEndian.PutUint64(val, uint64(o.Owner))
+	// 缓存失败了也没关系，下一次再查
+	_ = r.cache.Set(key, val, int(r.expiration.Seconds()))
+	return o.Owner, nil
+}
+
+func (r *CachedResolver) key(biz string, bizId int64) []byte {
+	key := make([]byte, 8, 8+len(biz))
+	binary.BigEndian.PutUint64(key, uint64(bizId))
+	return append(key, biz...)
+}
+
+// InitResolver 建表，并且开始同步文章的作者。
+// group 用调用方自己的服务名，每个服务各存一份
+func InitResolver(db *gorm.DB, client sarama.Client, group string, l logger.LoggerV1) Resolver {
+	err := InitTables(db)
+	if err != nil {
+		panic(err)
+	}
+	d := NewGORMDAO(db)
+	err = NewArticleConsumer(client, group, d, l).Start()
+	if err != nil {
+		panic(err)
+	}
+	return NewCachedResolver(d, time.Minute*10)
+}
@@ -0,0 +1,54 @@
+package bizowner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDAO 只实现了 Find
+type fakeDAO struct {
+	DAO
+	owners map[int64]int64
+	calls  int
+}
+
+func (f *fakeDAO) Find(ctx context.Context, biz string, bizId int64) (BizOwner, error) {
+	f.calls++
+	owner, ok := f.owners[bizId]
+	if !ok {
+		return BizOwner{}, ErrOwnerNotFound
+	}
+	return BizOwner{Biz: biz, BizId: bizId, Owner: owner}, nil
+}
+
+func TestCachedResolver_Owner(t *testing.T) {
+	ctx := context.Background()
+	d := &fakeDAO{owners: map[int64]int64{1: 123}}
+	r := NewCachedResolver(d, time.Minute)
+
+	owner, err := r.Owner(ctx, BizArticle, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(123), owner)
+	// 第二次走缓存
+	owner, err = r.Owner(ctx, BizArticle, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(123), owner)
+	assert.Equal(t, 1, d.calls)
+
+	// 其它 biz 的同一个 ID 不能用这个缓存
+	_, err = r.Owner(ctx, "video", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, d.calls)
+
+	// 找不到的不缓存，同步过来之后就能查到
+	_, err = r.Owner(ctx, BizArticle, 2)
+	assert.Equal(t, ErrOwnerNotFound, err)
+	d.owners[2] = 456
+	owner, err = r.Owner(ctx, BizArticle, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(456), owner)
+}
@@ -0,0 +1,19 @@
+package bizowner
+
+import (
+	"context"
+	"errors"
+)
+
+// BizArticle 目前只有文章有作者
+const BizArticle = "article"
+
+// ErrOwnerNotFound 资源不存在，或者发表的消息还没有同步过来
+var ErrOwnerNotFound = errors.New("没有找到资源的作者")
+
+// Resolver 查询资源的作者。
+// 评论、点赞这些服务不能相信调用方传过来的作者，又调不到文章服务，
+// 所以在本地存一份，由文章发表的消息同步过来
+type Resolver interface {
+	Owner(ctx context.Context, biz string, bizId int64) (int64, error)
+}
@@ -0,0 +1,56 @@
+package diffx
+
+import (
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+type Op string
+
+const (
+	OpEqual  Op = "equal"
+	OpInsert Op = "insert"
+	OpDelete Op = "delete"
+)
+
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Lines 按行比较 a 和 b，修改会表达为先删除再插入
+func Lines(a, b string) []Line {
+	al, bl := splitLines(a), splitLines(b)
+	// 关掉 autoJunk，不然文章里面大量重复的空行会被当成垃圾数据，diff 结果很奇怪
+	m := difflib.NewMatcherWithJunk(al, bl, false, nil)
+	res := make([]Line, 0, max(len(al), len(bl)))
+	for _, oc := range m.GetOpCodes() {
+		switch oc.Tag {
+		case 'e':
+			res = appendLines(res, OpEqual, al[oc.I1:oc.I2])
+		case 'd':
+			res = appendLines(res, OpDelete, al[oc.I1:oc.I2])
+		case 'i':
+			res = appendLines(res, OpInsert, bl[oc.J1:oc.J2])
+		case 'r':
+			res = appendLines(res, OpDelete, al[oc.I1:oc.I2])
+			res = appendLines(res, OpInsert, bl[oc.J1:oc.J2])
+		}
+	}
+	return res
+}
+
+func appendLines(res []Line, op Op, lines []string) []Line {
+	for _, l := range lines {
+		res = append(res, Line{Op: op, Text: l})
+	}
+	return res
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
@@ -0,0 +1,51 @@
+package ginx
+
+import (
+	"crypto/rand"
+
+	"webooktrial/pkg/pagination"
+)
+
+// cursorCodec 没有初始化的时候用进程内随机的 secret，只适合单实例或者测试
+var cursorCodec = pagination.NewCodec(randomSecret())
+
+// InitCursorCodec 在启动的时候调用。
+// 多个实例之间要用同一个 secret，不然别的实例发出去的游标会解析失败
+func InitCursorCodec(secret string) {
+	if secret == "" {
+		return
+	}
+	cursorCodec = pagination.NewCodec([]byte(secret))
+}
+
+func randomSecret() []byte {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	return key
+}
+
+// CursorPage 游标分页的响应，NextCursor 为空说明没有下一页了
+type CursorPage[T any] struct {
+	List       []T    `json:"list"`
+	NextCursor string `json:"next_cursor"`
+}
+
+// ParseCursor 解析前端传过来的游标，空字符串表示第一页
+func ParseCursor(token string) (pagination.Cursor, error) {
+	return cursorCodec.Decode(token)
+}
+
+// NewCursorPage 根据 src 计算下一页的游标，然后转换成 VO。
+// src 不满 limit 条的时候认为没有下一页了
+func NewCursorPage[Src any, T any](src []Src, limit int,
+	cursor func(s Src) pagination.Cursor,
+	toVO func(s Src) T) CursorPage[T] {
+	list := make([]T, 0, len(src))
+	for _, s := range src {
+		list = append(list, toVO(s))
+	}
+	return CursorPage[T]{
+		List:       list,
+		NextCursor: cursorCodec.Encode(pagination.Next(src, limit, cursor)),
+	}
+}
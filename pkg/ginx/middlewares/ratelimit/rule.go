@@ -0,0 +1,138 @@
+package ratelimit
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	ijwt "webooktrial/internal/web/jwt"
+	"webooktrial/pkg/ratelimit"
+)
+
+const (
+	// KeyTypeIP 按照客户端 IP 限流
+	KeyTypeIP = "ip"
+	// KeyTypeUid 按照登录用户限流，uid 从 ijwt.UserClaims 里面取
+	KeyTypeUid = "uid"
+	// KeyTypeHeader 按照某个请求头限流，比如说 X-App-Key
+	KeyTypeHeader = "header"
+)
+
+// Rule 一条限流规则。可以直接从配置文件里面读出来
+type Rule struct {
+	// Name 规则名字，会用来拼接 redis 的 key，所以要唯一
+	Name string `yaml:"name" json:"name"`
+	// Path 命中的路由，使用的是 gin 注册的路由模式，比如说 /articles/detail/:id
+	// 支持 path.Match 的通配符，以 /* 结尾的代表前缀匹配，为空代表匹配所有路由
+	Path string `yaml:"path" json:"path"`
+	// Methods 命中的 HTTP 方法，为空代表所有方法
+	Methods []string `yaml:"methods" json:"methods"`
+	// KeyType 限流的维度，ip、uid 或者 header
+	KeyType string `yaml:"keyType" json:"keyType"`
+	// Header KeyType 为 header 的时候，用哪个请求头
+	Header string `yaml:"header" json:"header"`
+	// Interval 窗口大小
+	Interval time.Duration `yaml:"interval" json:"interval"`
+	// Rate Interval 内允许 Rate 个请求
+	Rate int `yaml:"rate" json:"rate"`
+	// FailOpen 限流器本身出错的时候，true 代表放行（激进策略），
+	// false 代表拒绝（保守策略）
+	FailOpen bool `yaml:"failOpen" json:"failOpen"`
+}
+
+type compiledRule struct {
+	Rule
+	methods map[string]struct{}
+	limiter ratelimit.Limiter
+}
+
+func compileRules(rules []Rule, factory ratelimit.LimiterFactory) ([]*compiledRule, error) {
+	res := make([]*compiledRule, 0, len(rules))
+	names := make(map[string]struct{}, len(rules))
+	for _, r := range rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("限流规则缺少名字 %+v", r)
+		}
+		if _, ok := names[r.Name]; ok {
+			return nil, fmt.Errorf("限流规则名字重复 %s", r.Name)
+		}
+		names[r.Name] = struct{}{}
+		if r.Interval <= 0 || r.Rate <= 0 {
+			return nil, fmt.Errorf("限流规则 %s 的窗口大小或者阈值不合法", r.Name)
+		}
+		if r.KeyType == "" {
+			r.KeyType = KeyTypeIP
+		}
+		switch r.KeyType {
+		case KeyTypeIP, KeyTypeUid:
+		case KeyTypeHeader:
+			if r.Header == "" {
+				return nil, fmt.Errorf("限流规则 %s 没有指定请求头", r.Name)
+			}
+		default:
+			return nil, fmt.Errorf("限流规则 %s 的维度 %s 不支持", r.Name, r.KeyType)
+		}
+		if _, err := path.Match(strings.TrimSuffix(r.Path, "/*"), ""); err != nil {
+			return nil, fmt.Errorf("限流规则 %s 的路由不合法 %w", r.Name, err)
+		}
+		cr := &compiledRule{
+			Rule:    r,
+			limiter: factory(r.Interval, r.Rate),
+		}
+		if len(r.Methods) > 0 {
+			cr.methods = make(map[string]struct{}, len(r.Methods))
+			for _, m := range r.Methods {
+				cr.methods[strings.ToUpper(m)] = struct{}{}
+			}
+		}
+		res = append(res, cr)
+	}
+	return res, nil
+}
+
+func (r *compiledRule) match(method, route string) bool {
+	if r.methods != nil {
+		if _, ok := r.methods[method]; !ok {
+			return false
+		}
+	}
+	if r.Path == "" || r.Path == "*" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(r.Path, "/*"); ok {
+		if route == prefix || strings.HasPrefix(route, prefix+"/") {
+			return true
+		}
+	}
+	ok, _ := path.Match(r.Path, route)
+	return ok
+}
+
+// key 返回限流对象。返回 false 代表这个请求没有这个维度，例如没有登录
+// 那么这条规则就不适用
+func (r *compiledRule) key(prefix string, ctx *gin.Context) (string, bool) {
+	var val string
+	switch r.KeyType {
+	case KeyTypeUid:
+		c, ok := ctx.Get("claims")
+		if !ok {
+			return "", false
+		}
+		uc, ok := c.(ijwt.UserClaims)
+		if !ok || uc.Uid == 0 {
+			return "", false
+		}
+		val = fmt.Sprintf("%d", uc.Uid)
+	case KeyTypeHeader:
+		val = ctx.GetHeader(r.Header)
+		if val == "" {
+			return "", false
+		}
+	default:
+		val = ctx.ClientIP()
+	}
+	return fmt.Sprintf("%s:%s:%s:%s", prefix, r.Name, r.KeyType, val), true
+}
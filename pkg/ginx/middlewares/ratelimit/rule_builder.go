@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/ratelimit"
+)
+
+// RuleBuilder 基于规则的限流中间件
+// 和 Builder 不同的是，它可以按照路由、方法来选择限流维度和阈值
+// 并且规则可以在运行期间替换
+type RuleBuilder struct {
+	prefix  string
+	factory ratelimit.LimiterFactory
+	rules   atomic.Pointer[[]*compiledRule]
+	l       logger.LoggerV1
+}
+
+func NewRuleBuilder(factory ratelimit.LimiterFactory, l logger.LoggerV1, rules ...Rule) (*RuleBuilder, error) {
+	b := &RuleBuilder{
+		prefix:  "rule-limiter",
+		factory: factory,
+		l:       l,
+	}
+	return b, b.UpdateRules(rules)
+}
+
+func (b *RuleBuilder) Prefix(prefix string) *RuleBuilder {
+	b.prefix = prefix
+	return b
+}
+
+// UpdateRules 整体替换限流规则，用于配置热更新
+// 新规则不合法的时候，保留旧的规则
+func (b *RuleBuilder) UpdateRules(rules []Rule) error {
+	compiled, err := compileRules(rules, b.factory)
+	if err != nil {
+		return err
+	}
+	b.rules.Store(&compiled)
+	return nil
+}
+
+func (b *RuleBuilder) Build() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		rules := b.rules.Load()
+		if rules == nil {
+			ctx.Next()
+			return
+		}
+		method := ctx.Request.Method
+		route := ctx.FullPath()
+		if route == "" {
+			// 没有命中路由，例如 404，那就用原始路径
+			route = ctx.Request.URL.Path
+		}
+		// 所有命中的规则都要检查，任何一条触发了限流就拒绝
+		for _, r := range *rules {
+			if !r.match(method, route) {
+				continue
+			}
+			key, ok := r.key(b.prefix, ctx)
+			if !ok {
+				continue
+			}
+			limited, err := r.limiter.Limit(ctx, key)
+			if err != nil {
+				b.l.Error("判定限流出现问题",
+					logger.String("rule", r.Name),
+					logger.String("key", key),
+					logger.Error(err))
+				if r.FailOpen {
+					// 激进的策略，限流器出问题就放行
+					continue
+				}
+				// 保守的策略，限流器出问题就当作触发了限流
+				limited = true
+			}
+			if limited {
+				b.reject(ctx, r)
+				return
+			}
+		}
+		ctx.Next()
+	}
+}
+
+func (b *RuleBuilder) reject(ctx *gin.Context, r *compiledRule) {
+	// 滑动窗口没办法精确算出什么时候可以重试，保守一点，用整个窗口
+	retryAfter := int64(math.Ceil(r.Interval.Seconds()))
+	ctx.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+	ctx.Header("X-RateLimit-Limit", strconv.Itoa(r.Rate))
+	ctx.Header("X-RateLimit-Remaining", "0")
+	ctx.Header("X-RateLimit-Reset",
+		strconv.FormatInt(time.Now().Add(r.Interval).Unix(), 10))
+	ctx.Header("X-RateLimit-Rule", r.Name)
+	ctx.AbortWithStatus(http.StatusTooManyRequests)
+}
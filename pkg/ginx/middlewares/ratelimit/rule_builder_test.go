@@ -0,0 +1,199 @@
+package ratelimit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	ijwt "webooktrial/internal/web/jwt"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/ratelimit"
+	limitmocks "webooktrial/pkg/ratelimit/mocks"
+)
+
+func TestRuleBuilder_Build(t *testing.T) {
+	testCases := []struct {
+		name   string
+		rules  []Rule
+		mock   func(ctrl *gomock.Controller) ratelimit.Limiter
+		method string
+		path   string
+		header map[string]string
+		uid    int64
+
+		wantCode   int
+		wantHeader map[string]string
+	}{
+		{
+			name: "没有命中规则",
+			rules: []Rule{
+				{Name: "edit", Path: "/articles/edit", Methods: []string{"POST"},
+					Interval: time.Second, Rate: 10},
+			},
+			mock: func(ctrl *gomock.Controller) ratelimit.Limiter {
+				return limitmocks.NewMockLimiter(ctrl)
+			},
+			method:   http.MethodGet,
+			path:     "/articles/detail/1",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "按照 IP 限流，没触发",
+			rules: []Rule{
+				{Name: "all", Interval: time.Second, Rate: 10},
+			},
+			mock: func(ctrl *gomock.Controller) ratelimit.Limiter {
+				l := limitmocks.NewMockLimiter(ctrl)
+				l.EXPECT().Limit(gomock.Any(), "test:all:ip:192.0.2.1").
+					Return(false, nil)
+				return l
+			},
+			method:   http.MethodGet,
+			path:     "/articles/detail/1",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "按照路由模式和用户限流，触发限流",
+			rules: []Rule{
+				{Name: "detail", Path: "/articles/detail/:id", KeyType: KeyTypeUid,
+					Interval: 2 * time.Second, Rate: 10},
+			},
+			mock: func(ctrl *gomock.Controller) ratelimit.Limiter {
+				l := limitmocks.NewMockLimiter(ctrl)
+				l.EXPECT().Limit(gomock.Any(), "test:detail:uid:123").
+					Return(true, nil)
+				return l
+			},
+			method:   http.MethodGet,
+			path:     "/articles/detail/1",
+			uid:      123,
+			wantCode: http.StatusTooManyRequests,
+			wantHeader: map[string]string{
+				"Retry-After":           "2",
+				"X-RateLimit-Limit":     "10",
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Rule":      "detail",
+			},
+		},
+		{
+			name: "按照用户限流，没有登录就跳过",
+			rules: []Rule{
+				{Name: "detail", Path: "/articles/*", KeyType: KeyTypeUid,
+					Interval: time.Second, Rate: 10},
+			},
+			mock: func(ctrl *gomock.Controller) ratelimit.Limiter {
+				return limitmocks.NewMockLimiter(ctrl)
+			},
+			method:   http.MethodGet,
+			path:     "/articles/detail/1",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "按照请求头限流",
+			rules: []Rule{
+				{Name: "app", Path: "/articles/*", KeyType: KeyTypeHeader,
+					Header: "X-App-Key", Interval: time.Second, Rate: 10},
+			},
+			mock: func(ctrl *gomock.Controller) ratelimit.Limiter {
+				l := limitmocks.NewMockLimiter(ctrl)
+				l.EXPECT().Limit(gomock.Any(), "test:app:header:abc").
+					Return(true, nil)
+				return l
+			},
+			method:   http.MethodGet,
+			path:     "/articles/detail/1",
+			header:   map[string]string{"X-App-Key": "abc"},
+			wantCode: http.StatusTooManyRequests,
+		},
+		{
+			name: "限流器出错，放行",
+			rules: []Rule{
+				{Name: "all", Interval: time.Second, Rate: 10, FailOpen: true},
+			},
+			mock: func(ctrl *gomock.Controller) ratelimit.Limiter {
+				l := limitmocks.NewMockLimiter(ctrl)
+				l.EXPECT().Limit(gomock.Any(), gomock.Any()).
+					Return(false, errors.New("redis 崩了"))
+				return l
+			},
+			method:   http.MethodGet,
+			path:     "/articles/detail/1",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "限流器出错，拒绝",
+			rules: []Rule{
+				{Name: "all", Interval: time.Second, Rate: 10},
+			},
+			mock: func(ctrl *gomock.Controller) ratelimit.Limiter {
+				l := limitmocks.NewMockLimiter(ctrl)
+				l.EXPECT().Limit(gomock.Any(), gomock.Any()).
+					Return(false, errors.New("redis 崩了"))
+				return l
+			},
+			method:   http.MethodGet,
+			path:     "/articles/detail/1",
+			wantCode: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			limiter := tc.mock(ctrl)
+			bd, err := NewRuleBuilder(func(interval time.Duration, rate int) ratelimit.Limiter {
+				return limiter
+			}, logger.NewNopLogger(), tc.rules...)
+			require.NoError(t, err)
+
+			server := gin.New()
+			server.Use(func(ctx *gin.Context) {
+				if tc.uid > 0 {
+					ctx.Set("claims", ijwt.UserClaims{Uid: tc.uid})
+				}
+			}, bd.Prefix("test").Build())
+			server.GET("/articles/detail/:id", func(ctx *gin.Context) {
+				ctx.String(http.StatusOK, "OK")
+			})
+
+			req, err := http.NewRequest(tc.method, tc.path, nil)
+			require.NoError(t, err)
+			req.RemoteAddr = "192.0.2.1:1234"
+			for k, v := range tc.header {
+				req.Header.Set(k, v)
+			}
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.wantCode, resp.Code)
+			for k, v := range tc.wantHeader {
+				assert.Equal(t, v, resp.Header().Get(k))
+			}
+		})
+	}
+}
+
+func TestRuleBuilder_UpdateRules(t *testing.T) {
+	bd, err := NewRuleBuilder(func(interval time.Duration, rate int) ratelimit.Limiter {
+		return nil
+	}, logger.NewNopLogger(), Rule{Name: "all", Interval: time.Second, Rate: 10})
+	require.NoError(t, err)
+	err = bd.UpdateRules([]Rule{{Name: "all", Interval: time.Second}})
+	assert.Error(t, err)
+	// 不合法的规则不会覆盖旧的规则
+	assert.Len(t, *bd.rules.Load(), 1)
+	err = bd.UpdateRules([]Rule{
+		{Name: "a", Interval: time.Second, Rate: 10},
+		{Name: "b", KeyType: KeyTypeUid, Interval: time.Second, Rate: 10},
+	})
+	require.NoError(t, err)
+	assert.Len(t, *bd.rules.Load(), 2)
+}
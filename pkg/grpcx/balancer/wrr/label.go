@@ -0,0 +1,20 @@
+package wrr
+
+import "context"
+
+type labelsKey struct{}
+
+// WithLabels 指定这一次调用只发给带有这些标签的节点
+// 例如灰度发布的时候带上 "grey"，同机房优先的时候带上 "zone=hz"
+// 节点需要包含全部标签才算命中，没有节点命中的时候会退化为在所有节点里面挑选
+func WithLabels(ctx context.Context, labels ...string) context.Context {
+	return context.WithValue(ctx, labelsKey{}, labels)
+}
+
+func LabelsFromContext(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	labels, _ := ctx.Value(labelsKey{}).([]string)
+	return labels
+}
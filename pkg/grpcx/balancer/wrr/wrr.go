@@ -4,14 +4,19 @@ import (
 	"context"
 	"io"
 	"sync"
+	"time"
 
 	"google.golang.org/grpc/balancer"
 	"google.golang.org/grpc/balancer/base"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	"webooktrial/pkg/grpcx/registry"
 )
 
-const name = "custom_wrr"
+const Name = "custom_wrr"
+
+const defaultWeight = 10
 
 // balancer.Balancer 接口
 // balancer.Builder 接口
@@ -19,46 +24,84 @@ const name = "custom_wrr"
 // base.PickerBuilder 接口
 // 可以认为，Balancer 是 Picker 的装饰器
 func init() {
+	balancer.Register(&builder{cooldown: 30 * time.Second})
+}
+
+// builder 每个 ClientConn 创建一个自己的 PickerBuilder，
+// 这样节点的健康状态只在同一个 ClientConn 里面延续，不会串到别的服务上
+type builder struct {
+	cooldown time.Duration
+}
+
+func (b *builder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
 	// NewBalancerBuilder 是帮我们把一个 PickerBuilder 转化为一个 balancer.Builder
-	balancer.Register(base.NewBalancerBuilder("custom_wrr",
-		&PickerBuilder{}, base.Config{HealthCheck: false}))
+	// 开启健康检查之后，NOT_SERVING 的节点不会出现在 ReadySCs 里面
+	// 客户端还需要在 service config 里面配置 healthCheckConfig
+	return base.NewBalancerBuilder(Name,
+		&PickerBuilder{Cooldown: b.cooldown}, base.Config{HealthCheck: true}).Build(cc, opts)
+}
+
+func (b *builder) Name() string {
+	return Name
 }
 
 type PickerBuilder struct {
+	// Cooldown 节点被判定为不可用之后，多久之后再给它机会
+	Cooldown time.Duration
+
+	// 节点上下线都会重新 Build 一个 Picker，
+	// 这里记住上一次的节点，还在的节点继续用原来的有效权重和冷却时间
+	// 新旧 Picker 共用同一把锁，因为旧 Picker 上的请求结束的时候还会回调 feedback
+	mutex sync.Mutex
+	conns map[balancer.SubConn]*conn
 }
 
 func (p *PickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
 	conns := make([]*conn, 0, len(info.ReadySCs))
+	connMap := make(map[balancer.SubConn]*conn, len(info.ReadySCs))
 	// sc => SubConn
 	// sci => SubConnInfo
 	for sc, sci := range info.ReadySCs {
-		cc := &conn{
-			cc: sc,
+		// 权重、标签、分组是 grpcx.Server 注册到注册中心里面的
+		si := registry.InstanceFromMetadata(sci.Address.Addr, sci.Address.Metadata)
+		weight := si.Weight
+		if weight <= 0 {
+			weight = defaultWeight
 		}
-		md, ok := sci.Address.Metadata.(map[string]any)
+		cc, ok := p.conns[sc]
 		if ok {
-			weightVal := md["weight"]
-			weight, _ := weightVal.(float64)
-			cc.weight = int(weight)
-			//group, _ := md["group"]
-			//cc.group = group.(string)
-			cc.labels = md["labels"].([]string)
-		}
-		if cc.weight == 0 {
-			cc.weight = 10
+			cc.weight = weight
+			cc.efficientWeight = min(cc.efficientWeight, weight)
+		} else {
+			cc = &conn{
+				cc:              sc,
+				weight:          weight,
+				efficientWeight: weight,
+			}
 		}
-		cc.currentWeight = cc.weight
+		cc.labels = si.Labels
+		cc.group = si.Group
 		conns = append(conns, cc)
+		connMap[sc] = cc
 	}
+	// 已经下线的节点直接丢掉
+	p.conns = connMap
 	return &Picker{
-		conns: conns,
+		conns:    conns,
+		cooldown: p.Cooldown,
+		mutex:    &p.mutex,
 	}
 }
 
 // Picker 是真的执行负载均衡的地方
+// 使用的是平滑的加权轮询算法，用来计算的是有效权重：
+// 调用出错、超时的时候降低有效权重，成功的时候慢慢恢复
 type Picker struct {
-	conns []*conn
-	mutex sync.Mutex
+	conns    []*conn
+	cooldown time.Duration
+	mutex    *sync.Mutex
 }
 
 // Pick 在这里实现基于权重的负载均衡算法
@@ -69,25 +112,17 @@ func (p *Picker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
 		// 没有候选节点
 		return balancer.PickResult{}, balancer.ErrNoSubConnAvailable
 	}
+	now := time.Now()
+	candidates := p.candidates(info.Ctx, now)
+
 	var total int
 	var maxCC *conn
-	// 计算当前权重
-	//label := info.Ctx.Value("label")
-	for _, cc := range p.conns {
-		if !cc.available {
-			continue
-		}
-		// 如果要是 cc 里面的所有标签都不包含这个 label ，就跳过
-		// 性能最好就是在 cc 上用原子操作
-		// 但是筛选结果不会严格符合 WRR 算法
-		// 整体效果可以
-		//cc.lock.Lock()
-		total += cc.weight
-		cc.currentWeight = cc.currentWeight + cc.weight
+	for _, cc := range candidates {
+		total += cc.efficientWeight
+		cc.currentWeight = cc.currentWeight + cc.efficientWeight
 		if maxCC == nil || cc.currentWeight > maxCC.currentWeight {
 			maxCC = cc
 		}
-		//cc.lock.Unlock()
 	}
 
 	// 更新被选中节点的当前权重
@@ -95,62 +130,81 @@ func (p *Picker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
 	return balancer.PickResult{
 		SubConn: maxCC.cc,
 		Done: func(info balancer.DoneInfo) {
-			// 很多动态算法，根据调用结果来调整权重，就在这里
-			err := info.Err
-			if err == nil {
-				// 可以考虑增加权重 weight/currentWeight
-				return
-			}
-			switch err {
-			// 一般是主动取消，没有必要管
-			case context.Canceled:
-				return
-			case context.DeadlineExceeded:
-			case io.EOF, io.ErrUnexpectedEOF:
-				// 基本可以认为这个节点已经崩了
-				maxCC.available = false
-				// 可以考虑降低权重
-			default:
-				st, ok := status.FromError(err)
-				if ok {
-					code := st.Code()
-					switch code {
-					case codes.Unavailable:
-						// 这里可能表达的是熔断
-						// 这里就要考虑挪走该节点，这个节点已经不可用了
-						// 注意并发，这里可以用原子操作
-						maxCC.available = false
-						go func() {
-							// 要开一个额外的 goroutine 去探活
-							// 借助 health check
-							// for 循环
-							if p.healthCheck(maxCC) {
-								// 放回来
-								maxCC.available = true
-								// 最好加点流量控制的措施
-								// maxCC.currentWeight
-								// 要求下一次选中 maxCC 的时候
-								// 掷骰子
-							}
-						}()
-					case codes.ResourceExhausted:
-						// 这里可能表达的是限流
-						// 你可以挪走
-						// 也可以留着，留着的话，你就要降低权重，
-						// 最好是 currentWeight 和 weight 都调低
-						// 减少它被选中的概率
-
-						// 加一个错误码表达降级
-					}
-				}
-			}
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+			p.feedback(maxCC, info.Err)
 		},
 	}, nil
 }
 
-func (p *Picker) healthCheck(cc *conn) bool {
-	// 调用 grpc 内置的那个 health check 接口
-	return true
+// candidates 筛选候选节点
+// 1. 先按照标签筛选，没有节点命中标签的时候，退化为不筛选
+// 2. 再去掉不可用的节点，全部都不可用的时候，也只能从里面挑一个
+func (p *Picker) candidates(ctx context.Context, now time.Time) []*conn {
+	conns := p.conns
+	if labels := LabelsFromContext(ctx); len(labels) > 0 {
+		matched := make([]*conn, 0, len(conns))
+		for _, cc := range conns {
+			if cc.hasLabels(labels) {
+				matched = append(matched, cc)
+			}
+		}
+		if len(matched) > 0 {
+			conns = matched
+		}
+	}
+
+	available := make([]*conn, 0, len(conns))
+	for _, cc := range conns {
+		if cc.available(now) {
+			available = append(available, cc)
+		}
+	}
+	if len(available) > 0 {
+		return available
+	}
+	return conns
+}
+
+// feedback 很多动态算法，根据调用结果来调整权重，就在这里
+func (p *Picker) feedback(cc *conn, err error) {
+	if err == nil {
+		// 每次成功恢复十分之一，避免刚恢复的节点一下子被打垮
+		cc.efficientWeight = min(cc.weight, cc.efficientWeight+max(cc.weight/10, 1))
+		return
+	}
+	switch err {
+	// 一般是主动取消，没有必要管
+	case context.Canceled:
+		return
+	case context.DeadlineExceeded:
+		cc.decrease()
+		return
+	case io.EOF, io.ErrUnexpectedEOF:
+		// 基本可以认为这个节点已经崩了
+		p.markUnavailable(cc)
+		return
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return
+	}
+	switch st.Code() {
+	case codes.Unavailable:
+		// 这里可能表达的是熔断，或者节点已经不可用了
+		// 先挪走该节点，过一段时间之后再用很小的权重试探
+		p.markUnavailable(cc)
+	case codes.DeadlineExceeded, codes.ResourceExhausted:
+		// 超时或者限流，留着这个节点，但是降低权重，减少它被选中的概率
+		cc.decrease()
+	}
+}
+
+func (p *Picker) markUnavailable(cc *conn) {
+	cc.unavailableUntil = time.Now().Add(p.cooldown)
+	// 恢复之后从最小的权重开始
+	cc.efficientWeight = 1
+	cc.currentWeight = 0
 }
 
 // conn 代表节点
@@ -158,17 +212,40 @@ type conn struct {
 	// （初始）权重
 	weight int
 	labels []string
-	// 有效权重
-	//efficientWeight int
-	currentWeight int
-
-	//lock sync.Mutex
+	// 有效权重，会根据调用结果动态调整
+	efficientWeight int
+	currentWeight   int
 
 	//	真正的，grpc 里面的一个节点的表达
 	cc balancer.SubConn
 
-	available bool
+	// 在这个时间之前，节点都是不可用的
+	unavailableUntil time.Time
 
 	// 假如有 vip 或者非 vip
 	group string
 }
+
+func (c *conn) available(now time.Time) bool {
+	return !now.Before(c.unavailableUntil)
+}
+
+func (c *conn) decrease() {
+	c.efficientWeight = max(c.efficientWeight/2, 1)
+}
+
+func (c *conn) hasLabels(labels []string) bool {
+	for _, l := range labels {
+		found := false
+		for _, cl := range c.labels {
+			if cl == l {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
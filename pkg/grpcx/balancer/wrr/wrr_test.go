@@ -0,0 +1,141 @@
+package wrr
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
+)
+
+type subConn struct {
+	balancer.SubConn
+	name string
+}
+
+func newPicker(metas map[string]any) (*Picker, map[balancer.SubConn]string) {
+	ready := make(map[balancer.SubConn]base.SubConnInfo, len(metas))
+	names := make(map[balancer.SubConn]string, len(metas))
+	for name, md := range metas {
+		sc := &subConn{name: name}
+		ready[sc] = base.SubConnInfo{Address: resolver.Address{Addr: name, Metadata: md}}
+		names[sc] = name
+	}
+	p := (&PickerBuilder{Cooldown: time.Minute}).Build(base.PickerBuildInfo{ReadySCs: ready})
+	return p.(*Picker), names
+}
+
+func pickN(t *testing.T, p *Picker, ctx context.Context, n int, err error) map[string]int {
+	res := make(map[string]int)
+	for i := 0; i < n; i++ {
+		pr, e := p.Pick(balancer.PickInfo{Ctx: ctx})
+		assert.NoError(t, e)
+		res[pr.SubConn.(*subConn).name]++
+		pr.Done(balancer.DoneInfo{Err: err})
+	}
+	return res
+}
+
+func TestPicker_Weight(t *testing.T) {
+	// etcd 里面的元数据是 JSON 反序列化出来的
+	p, _ := newPicker(map[string]any{
+		"a": map[string]any{"weight": float64(30)},
+		"b": map[string]any{"weight": float64(10)},
+		// 没有元数据的时候，不能 panic，使用默认权重
+		"c": nil,
+	})
+	res := pickN(t, p, context.Background(), 50, nil)
+	assert.Equal(t, map[string]int{"a": 30, "b": 10, "c": 10}, res)
+}
+
+func TestPicker_Labels(t *testing.T) {
+	p, _ := newPicker(map[string]any{
+		"a": map[string]any{"weight": float64(10), "labels": []any{"grey", "zone=hz"}},
+		"b": map[string]any{"weight": float64(10), "labels": []any{"zone=hz"}},
+		"c": map[string]any{"weight": float64(10)},
+	})
+	res := pickN(t, p, WithLabels(context.Background(), "zone=hz"), 10, nil)
+	assert.Equal(t, map[string]int{"a": 5, "b": 5}, res)
+
+	res = pickN(t, p, WithLabels(context.Background(), "grey", "zone=hz"), 10, nil)
+	assert.Equal(t, map[string]int{"a": 10}, res)
+
+	// 没有节点命中，退化为所有节点
+	res = pickN(t, p, WithLabels(context.Background(), "zone=sh"), 30, nil)
+	assert.Equal(t, map[string]int{"a": 10, "b": 10, "c": 10}, res)
+}
+
+func TestPicker_Feedback(t *testing.T) {
+	p, names := newPicker(map[string]any{
+		"a": map[string]any{"weight": float64(10)},
+		"b": map[string]any{"weight": float64(10)},
+	})
+	conns := make(map[string]*conn, 2)
+	for _, c := range p.conns {
+		conns[names[c.cc]] = c
+	}
+
+	p.feedback(conns["a"], status.Error(codes.ResourceExhausted, "限流"))
+	assert.Equal(t, 5, conns["a"].efficientWeight)
+	p.feedback(conns["a"], context.DeadlineExceeded)
+	assert.Equal(t, 2, conns["a"].efficientWeight)
+	// 成功之后慢慢恢复
+	p.feedback(conns["a"], nil)
+	assert.Equal(t, 3, conns["a"].efficientWeight)
+
+	// 节点崩了，挪走
+	p.feedback(conns["b"], io.EOF)
+	res := pickN(t, p, context.Background(), 10, nil)
+	assert.Equal(t, map[string]int{"a": 10}, res)
+
+	// 冷却时间过了之后，用最小的权重重新加入
+	conns["b"].unavailableUntil = time.Now().Add(-time.Second)
+	res = pickN(t, p, context.Background(), 11, nil)
+	assert.Equal(t, map[string]int{"a": 10, "b": 1}, res)
+	assert.Equal(t, 2, conns["b"].efficientWeight)
+}
+
+func TestPickerBuilder_Rebuild(t *testing.T) {
+	a, b, c := &subConn{name: "a"}, &subConn{name: "b"}, &subConn{name: "c"}
+	info := func(scs ...*subConn) base.PickerBuildInfo {
+		ready := make(map[balancer.SubConn]base.SubConnInfo, len(scs))
+		for _, sc := range scs {
+			ready[sc] = base.SubConnInfo{Address: resolver.Address{
+				Addr: sc.name, Metadata: map[string]any{"weight": float64(10)}}}
+		}
+		return base.PickerBuildInfo{ReadySCs: ready}
+	}
+	pb := &PickerBuilder{Cooldown: time.Minute}
+	p := pb.Build(info(a, b)).(*Picker)
+	pickN(t, p, context.Background(), 2, io.EOF)
+	pickN(t, p, context.Background(), 1, status.Error(codes.ResourceExhausted, "限流"))
+
+	// 新增节点 c 之后，a b 原本的状态还在
+	p = pb.Build(info(a, b, c)).(*Picker)
+	conns := make(map[string]*conn, 3)
+	for _, cc := range p.conns {
+		conns[cc.cc.(*subConn).name] = cc
+	}
+	assert.False(t, conns["a"].available(time.Now()))
+	assert.False(t, conns["b"].available(time.Now()))
+	assert.True(t, conns["c"].available(time.Now()))
+	res := pickN(t, p, context.Background(), 10, nil)
+	assert.Equal(t, map[string]int{"c": 10}, res)
+
+	// 下线的节点不再保留，重新上线的时候从头开始
+	pb.Build(info(b, c))
+	assert.Len(t, pb.conns, 2)
+	p = pb.Build(info(a, b, c)).(*Picker)
+	for _, cc := range p.conns {
+		if cc.cc == a {
+			assert.True(t, cc.available(time.Now()))
+			assert.Equal(t, 10, cc.efficientWeight)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package grpcx
+
+import (
+	"github.com/spf13/viper"
+	etcdv3 "go.etcd.io/etcd/client/v3"
+)
+
+// InitEtcdClient 读取 etcd 配置创建客户端，服务发现的时候用。
+// 各个服务的配置 key 都是 etcd，wire 里面可以直接用
+func InitEtcdClient() *etcdv3.Client {
+	var cfg etcdv3.Config
+	err := viper.UnmarshalKey("etcd", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	client, err := etcdv3.New(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
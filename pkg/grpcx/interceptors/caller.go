@@ -0,0 +1,18 @@
+package interceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// BuildCallerClientInterceptor 客户端在元数据里面带上自己的应用名
+// 服务端通过 Builder.PeerName 就能知道是谁在调用，用来做按照调用方的限流、监控
+func BuildCallerClientInterceptor(app string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any,
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx = metadata.AppendToOutgoingContext(ctx, "app", app)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
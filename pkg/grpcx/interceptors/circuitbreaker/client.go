@@ -0,0 +1,84 @@
+package circuitbreaker
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-kratos/aegis/circuitbreaker"
+	"github.com/go-kratos/aegis/circuitbreaker/sre"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Observer 用来上报熔断器的状态，一般是 prometheus.ClientMetrics
+type Observer interface {
+	ObserveBreaker(target, method string, open bool)
+}
+
+// ClientInterceptorBuilder 客户端熔断，每个目标服务的每个方法一个熔断器
+//
+// 默认使用的是 Google SRE 的自适应熔断，它本身就是"随机数 + 阈值"的思路：
+// 请求失败越多，拒绝的概率越高；后端恢复之后，放过去的请求成功了，
+// 拒绝的概率就会逐步下降，而不是一下子把流量全部打过去
+type ClientInterceptorBuilder struct {
+	newBreaker func() circuitbreaker.CircuitBreaker
+	breakers   sync.Map
+	observer   Observer
+}
+
+func NewClientInterceptorBuilder() *ClientInterceptorBuilder {
+	return &ClientInterceptorBuilder{
+		newBreaker: func() circuitbreaker.CircuitBreaker {
+			return sre.NewBreaker()
+		},
+	}
+}
+
+// BreakerFactory 自定义熔断器，例如调整 sre 的窗口大小、成功率
+func (b *ClientInterceptorBuilder) BreakerFactory(fn func() circuitbreaker.CircuitBreaker) *ClientInterceptorBuilder {
+	b.newBreaker = fn
+	return b
+}
+
+func (b *ClientInterceptorBuilder) Observer(observer Observer) *ClientInterceptorBuilder {
+	b.observer = observer
+	return b
+}
+
+func (b *ClientInterceptorBuilder) Build() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any,
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		target := cc.Target()
+		breaker := b.breaker(target, method)
+		if err := breaker.Allow(); err != nil {
+			breaker.MarkFailed()
+			b.observe(target, method, true)
+			return status.Errorf(codes.Unavailable, "触发熔断 %s", method)
+		}
+		b.observe(target, method, false)
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if IsSystemError(err) {
+			breaker.MarkFailed()
+		} else {
+			breaker.MarkSuccess()
+		}
+		return err
+	}
+}
+
+func (b *ClientInterceptorBuilder) breaker(target, method string) circuitbreaker.CircuitBreaker {
+	key := target + method
+	val, ok := b.breakers.Load(key)
+	if ok {
+		return val.(circuitbreaker.CircuitBreaker)
+	}
+	val, _ = b.breakers.LoadOrStore(key, b.newBreaker())
+	return val.(circuitbreaker.CircuitBreaker)
+}
+
+func (b *ClientInterceptorBuilder) observe(target, method string, open bool) {
+	if b.observer != nil {
+		b.observer.ObserveBreaker(target, method, open)
+	}
+}
@@ -0,0 +1,142 @@
+package circuitbreaker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/aegis/circuitbreaker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+type mockBreaker struct {
+	open    bool
+	success int
+	failed  int
+}
+
+func (b *mockBreaker) Allow() error {
+	if b.open {
+		return circuitbreaker.ErrNotAllowed
+	}
+	return nil
+}
+
+func (b *mockBreaker) MarkSuccess() {
+	b.success++
+}
+
+func (b *mockBreaker) MarkFailed() {
+	b.failed++
+}
+
+type mockObserver struct {
+	states []bool
+}
+
+func (o *mockObserver) ObserveBreaker(target, method string, open bool) {
+	o.states = append(o.states, open)
+}
+
+func TestClientInterceptorBuilder_Build(t *testing.T) {
+	const method = "/intr.v1.InteractiveService/Get"
+	testCases := []struct {
+		name      string
+		open      bool
+		invokeErr error
+
+		wantErr     codes.Code
+		wantInvoked bool
+		wantSuccess int
+		wantFailed  int
+		wantStates  []bool
+	}{
+		{
+			name:        "调用成功",
+			wantInvoked: true,
+			wantSuccess: 1,
+			wantStates:  []bool{false},
+		},
+		{
+			name:        "系统错误，记为失败",
+			invokeErr:   status.Error(codes.Unavailable, "节点不可用"),
+			wantErr:     codes.Unavailable,
+			wantInvoked: true,
+			wantFailed:  1,
+			wantStates:  []bool{false},
+		},
+		{
+			name:        "业务错误，不算失败",
+			invokeErr:   status.Error(codes.NotFound, "没有数据"),
+			wantErr:     codes.NotFound,
+			wantInvoked: true,
+			wantSuccess: 1,
+			wantStates:  []bool{false},
+		},
+		{
+			name:       "熔断，不发请求",
+			open:       true,
+			wantErr:    codes.Unavailable,
+			wantFailed: 1,
+			wantStates: []bool{true},
+		},
+	}
+
+	cc, err := grpc.Dial("passthrough:///localhost:0",
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer cc.Close()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			breaker := &mockBreaker{open: tc.open}
+			observer := &mockObserver{}
+			interceptor := NewClientInterceptorBuilder().
+				BreakerFactory(func() circuitbreaker.CircuitBreaker {
+					return breaker
+				}).Observer(observer).Build()
+			invoked := false
+			err := interceptor(context.Background(), method, nil, nil, cc,
+				func(ctx context.Context, method string, req, reply any,
+					cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+					invoked = true
+					return tc.invokeErr
+				})
+			assert.Equal(t, tc.wantErr, status.Code(err))
+			assert.Equal(t, tc.wantInvoked, invoked)
+			assert.Equal(t, tc.wantSuccess, breaker.success)
+			assert.Equal(t, tc.wantFailed, breaker.failed)
+			assert.Equal(t, tc.wantStates, observer.states)
+		})
+	}
+}
+
+func TestClientInterceptorBuilder_BreakerPerMethod(t *testing.T) {
+	cc, err := grpc.Dial("passthrough:///localhost:0",
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer cc.Close()
+
+	breakers := make([]*mockBreaker, 0, 2)
+	interceptor := NewClientInterceptorBuilder().
+		BreakerFactory(func() circuitbreaker.CircuitBreaker {
+			b := &mockBreaker{}
+			breakers = append(breakers, b)
+			return b
+		}).Build()
+	invoker := func(ctx context.Context, method string, req, reply any,
+		cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return nil
+	}
+	for _, method := range []string{"/svc/A", "/svc/A", "/svc/B"} {
+		require.NoError(t, interceptor(context.Background(), method, nil, nil, cc, invoker))
+	}
+	// A 和 B 各自一个熔断器，A 的熔断器复用
+	require.Len(t, breakers, 2)
+	assert.Equal(t, 2, breakers[0].success)
+	assert.Equal(t, 1, breakers[1].success)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	"webooktrial/pkg/grpcx/interceptors"
@@ -59,3 +60,95 @@ func (b *InterceptorBuilder) splitMethodName(fullMethodName string) (string, str
 	}
 	return "unknown", "unknown"
 }
+
+func (b *InterceptorBuilder) BuildClient() grpc.UnaryClientInterceptor {
+	summary := prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Namespace: b.Namespace,
+			Subsystem: b.Subsystem,
+			Name:      "client_handle_seconds",
+			Objectives: map[float64]float64{
+				0.5:   0.01,
+				0.9:   0.01,
+				0.95:  0.01,
+				0.99:  0.001,
+				0.999: 0.0001,
+			},
+		}, []string{"type", "target", "service", "method", "code"})
+	prometheus.MustRegister(summary)
+	return func(ctx context.Context, method string, req, reply any,
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) (err error) {
+		start := time.Now()
+		defer func() {
+			s, m := b.splitMethodName(method)
+			duration := time.Since(start).Seconds()
+			st, _ := status.FromError(err)
+			summary.WithLabelValues("unary", cc.Target(), s, m, st.Code().String()).Observe(duration)
+		}()
+		err = invoker(ctx, method, req, reply, cc, opts...)
+		return
+	}
+}
+
+// ClientMetrics 客户端熔断、重试、对冲的指标
+// 实现了 circuitbreaker.Observer 和 retry.Observer
+type ClientMetrics struct {
+	builder      *InterceptorBuilder
+	breakerState *prometheus.GaugeVec
+	rejected     *prometheus.CounterVec
+	retries      *prometheus.CounterVec
+	hedges       *prometheus.CounterVec
+}
+
+func (b *InterceptorBuilder) BuildClientMetrics() *ClientMetrics {
+	labels := []string{"target", "service", "method"}
+	m := &ClientMetrics{
+		builder: b,
+		breakerState: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: b.Namespace,
+			Subsystem: b.Subsystem,
+			Name:      "client_breaker_open",
+			Help:      "熔断器状态，1 代表最近一次请求被熔断拒绝",
+		}, labels),
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: b.Namespace,
+			Subsystem: b.Subsystem,
+			Name:      "client_breaker_rejected_total",
+			Help:      "被熔断器拒绝的请求数",
+		}, labels),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: b.Namespace,
+			Subsystem: b.Subsystem,
+			Name:      "client_retry_total",
+			Help:      "重试次数，code 是触发重试的错误码",
+		}, append(labels, "code")),
+		hedges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: b.Namespace,
+			Subsystem: b.Subsystem,
+			Name:      "client_hedge_total",
+			Help:      "发出的对冲请求数",
+		}, labels),
+	}
+	prometheus.MustRegister(m.breakerState, m.rejected, m.retries, m.hedges)
+	return m
+}
+
+func (m *ClientMetrics) ObserveBreaker(target, method string, open bool) {
+	s, mtd := m.builder.splitMethodName(method)
+	if open {
+		m.breakerState.WithLabelValues(target, s, mtd).Set(1)
+		m.rejected.WithLabelValues(target, s, mtd).Inc()
+		return
+	}
+	m.breakerState.WithLabelValues(target, s, mtd).Set(0)
+}
+
+func (m *ClientMetrics) ObserveRetry(target, method string, code codes.Code) {
+	s, mtd := m.builder.splitMethodName(method)
+	m.retries.WithLabelValues(target, s, mtd, code.String()).Inc()
+}
+
+func (m *ClientMetrics) ObserveHedge(target, method string) {
+	s, mtd := m.builder.splitMethodName(method)
+	m.hedges.WithLabelValues(target, s, mtd).Inc()
+}
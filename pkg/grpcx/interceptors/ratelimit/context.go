@@ -0,0 +1,17 @@
+package ratelimit
+
+import "context"
+
+type limitedKey struct{}
+
+// WithLimited 标记这个请求已经触发了限流，但是还是放行到了业务里面
+// 业务可以据此走降级逻辑，比如说只查缓存，不查数据库，不走慢路径
+func WithLimited(ctx context.Context) context.Context {
+	return context.WithValue(ctx, limitedKey{}, true)
+}
+
+// IsLimited 判断请求是不是处于限流降级状态
+func IsLimited(ctx context.Context) bool {
+	limited, _ := ctx.Value(limitedKey{}).(bool)
+	return limited
+}
@@ -16,10 +16,6 @@ type InterceptorBuilder struct {
 	limiter ratelimit.Limiter
 	key     string
 	l       logger.LoggerV1
-
-	// key 是 FullMethod, value 是默认值的 json
-	//defaultValueMap map[string]string
-	//defaultValueFuncMap map[string]func() any
 }
 
 func NewInterceptorBuilder(limiter ratelimit.Limiter, key string, l logger.LoggerV1) *InterceptorBuilder {
@@ -35,11 +31,6 @@ func (b *InterceptorBuilder) BuildServerInterceptor() grpc.UnaryServerIntercepto
 			return nil, status.Errorf(codes.ResourceExhausted, "触发限流")
 		}
 		if limited {
-			//defVal, ok := b.defaultValueMap[info.FullMethod]
-			//if ok {
-			//	err = json.Unmarshal([]byte(defVal), &resp)
-			//	return defVal, err
-			//}
 			return nil, status.Errorf(codes.ResourceExhausted, "触发限流")
 		}
 		return handler(ctx, req)
@@ -52,7 +43,7 @@ func (b *InterceptorBuilder) BuildServerInterceptorV1() grpc.UnaryServerIntercep
 		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
 		limited, err := b.limiter.Limit(ctx, b.key)
 		if err != nil || limited {
-			ctx = context.WithValue(ctx, "limited", "true")
+			ctx = WithLimited(ctx)
 		}
 
 		return handler(ctx, req)
@@ -77,23 +68,23 @@ func (b *InterceptorBuilder) BuildClientInterceptor() grpc.UnaryClientIntercepto
 	}
 }
 
-// 服务级别限流
+// BuildServerInterceptorService 服务级别限流，同一个服务的所有方法共享一个阈值
 func (b *InterceptorBuilder) BuildServerInterceptorService() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any,
 		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
-		if strings.HasPrefix(info.FullMethod, "/UserService") {
-			limited, err := b.limiter.Limit(ctx, "limiter:service:user:UserService")
-			if err != nil {
-				// err 不为nil，你要考虑你用保守的，还是用激进的策略
-				// 这是保守的策略
-				b.l.Error("判定限流出现问题", logger.Error(err))
-				return nil, status.Errorf(codes.ResourceExhausted, "触发限流")
-				// 这是激进的策略
-				// return handler(ctx, req)
-			}
-			if limited {
-				return nil, status.Errorf(codes.ResourceExhausted, "触发限流")
-			}
+		// /user.v1.UserService/GetByID 里面的 user.v1.UserService
+		service, _, _ := strings.Cut(strings.TrimPrefix(info.FullMethod, "/"), "/")
+		limited, err := b.limiter.Limit(ctx, b.key+":service:"+service)
+		if err != nil {
+			// err 不为nil，你要考虑你用保守的，还是用激进的策略
+			// 这是保守的策略
+			b.l.Error("判定限流出现问题", logger.Error(err))
+			return nil, status.Errorf(codes.ResourceExhausted, "触发限流")
+			// 这是激进的策略
+			// return handler(ctx, req)
+		}
+		if limited {
+			return nil, status.Errorf(codes.ResourceExhausted, "触发限流")
 		}
 		return handler(ctx, req)
 	}
@@ -0,0 +1,181 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"webooktrial/pkg/grpcx/interceptors"
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/ratelimit"
+)
+
+// MethodRule 按照方法和调用方的限流规则
+type MethodRule struct {
+	// Name 规则名字，会用来拼接限流的 key，所以要唯一
+	Name string `yaml:"name" json:"name"`
+	// FullMethod 命中的方法，例如 /reward.v1.RewardService/GetReward
+	// /reward.v1.RewardService/* 代表整个服务，为空或者 * 代表所有方法
+	// 使用通配的时候，每个方法还是单独计数的
+	FullMethod string `yaml:"fullMethod" json:"fullMethod"`
+	// Callers 只对这些调用方生效，为空代表所有调用方
+	// 调用方是客户端通过 interceptors.BuildCallerClientInterceptor 带过来的
+	Callers []string `yaml:"callers" json:"callers"`
+	// PerCaller 为 true 的时候，每个调用方单独计数，否则所有调用方共享阈值
+	PerCaller bool `yaml:"perCaller" json:"perCaller"`
+	// Interval 窗口大小
+	Interval time.Duration `yaml:"interval" json:"interval"`
+	// Rate Interval 内允许 Rate 个请求
+	Rate int `yaml:"rate" json:"rate"`
+	// FailOpen 限流器本身出错的时候，true 代表放行，false 代表当作触发了限流
+	FailOpen bool `yaml:"failOpen" json:"failOpen"`
+	// Degrade 触发限流之后，不直接拒绝，而是带上限流标记继续执行业务
+	// 业务通过 IsLimited 判断要不要走降级逻辑
+	Degrade bool `yaml:"degrade" json:"degrade"`
+}
+
+// FallbackFunc 触发限流之后返回降级响应
+type FallbackFunc func(ctx context.Context, req any) (any, error)
+
+type methodRule struct {
+	MethodRule
+	callers map[string]struct{}
+	limiter ratelimit.Limiter
+}
+
+// MethodInterceptorBuilder 方法级别、调用方级别的限流
+// 触发限流的时候，优先使用注册的降级函数，其次是带上限流标记放行，最后才是拒绝
+type MethodInterceptorBuilder struct {
+	interceptors.Builder
+	prefix    string
+	factory   ratelimit.LimiterFactory
+	rules     atomic.Pointer[[]*methodRule]
+	fallbacks sync.Map
+	l         logger.LoggerV1
+}
+
+func NewMethodInterceptorBuilder(factory ratelimit.LimiterFactory,
+	l logger.LoggerV1, rules ...MethodRule) (*MethodInterceptorBuilder, error) {
+	b := &MethodInterceptorBuilder{
+		prefix:  "limiter:grpc",
+		factory: factory,
+		l:       l,
+	}
+	return b, b.UpdateRules(rules)
+}
+
+func (b *MethodInterceptorBuilder) Prefix(prefix string) *MethodInterceptorBuilder {
+	b.prefix = prefix
+	return b
+}
+
+// RegisterFallback 给某个方法注册降级函数，fullMethod 必须是完整的方法名
+func (b *MethodInterceptorBuilder) RegisterFallback(fullMethod string, fn FallbackFunc) *MethodInterceptorBuilder {
+	b.fallbacks.Store(fullMethod, fn)
+	return b
+}
+
+// UpdateRules 整体替换规则，用于配置热更新。新规则不合法的时候保留旧规则
+func (b *MethodInterceptorBuilder) UpdateRules(rules []MethodRule) error {
+	res := make([]*methodRule, 0, len(rules))
+	names := make(map[string]struct{}, len(rules))
+	for _, r := range rules {
+		if r.Name == "" {
+			return fmt.Errorf("限流规则缺少名字 %+v", r)
+		}
+		if _, ok := names[r.Name]; ok {
+			return fmt.Errorf("限流规则名字重复 %s", r.Name)
+		}
+		names[r.Name] = struct{}{}
+		if r.Interval <= 0 || r.Rate <= 0 {
+			return fmt.Errorf("限流规则 %s 的窗口大小或者阈值不合法", r.Name)
+		}
+		mr := &methodRule{
+			MethodRule: r,
+			limiter:    b.factory(r.Interval, r.Rate),
+		}
+		if len(r.Callers) > 0 {
+			mr.callers = make(map[string]struct{}, len(r.Callers))
+			for _, c := range r.Callers {
+				mr.callers[c] = struct{}{}
+			}
+		}
+		res = append(res, mr)
+	}
+	b.rules.Store(&res)
+	return nil
+}
+
+func (b *MethodInterceptorBuilder) BuildServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any,
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		rules := b.rules.Load()
+		if rules == nil {
+			return handler(ctx, req)
+		}
+		caller := b.PeerName(ctx)
+		degrade := false
+		for _, r := range *rules {
+			if !r.match(info.FullMethod, caller) {
+				continue
+			}
+			limited, err := r.limiter.Limit(ctx, b.key(r, info.FullMethod, caller))
+			if err != nil {
+				b.l.Error("判定限流出现问题",
+					logger.String("rule", r.Name),
+					logger.String("method", info.FullMethod),
+					logger.String("caller", caller),
+					logger.Error(err))
+				limited = !r.FailOpen
+			}
+			if !limited {
+				continue
+			}
+			if fn, ok := b.fallbacks.Load(info.FullMethod); ok {
+				return fn.(FallbackFunc)(WithLimited(ctx), req)
+			}
+			if !r.Degrade {
+				return nil, status.Errorf(codes.ResourceExhausted, "触发限流")
+			}
+			// 先不急着放行，后面的规则也许是要直接拒绝的
+			degrade = true
+		}
+		if degrade {
+			ctx = WithLimited(ctx)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (b *MethodInterceptorBuilder) key(r *methodRule, fullMethod, caller string) string {
+	if r.PerCaller {
+		if caller == "" {
+			caller = "unknown"
+		}
+		return fmt.Sprintf("%s:%s:%s:%s", b.prefix, r.Name, fullMethod, caller)
+	}
+	return fmt.Sprintf("%s:%s:%s", b.prefix, r.Name, fullMethod)
+}
+
+func (r *methodRule) match(fullMethod, caller string) bool {
+	if r.callers != nil {
+		if _, ok := r.callers[caller]; !ok {
+			return false
+		}
+	}
+	switch {
+	case r.FullMethod == "" || r.FullMethod == "*":
+		return true
+	case strings.HasSuffix(r.FullMethod, "/*"):
+		return strings.HasPrefix(fullMethod, strings.TrimSuffix(r.FullMethod, "*"))
+	default:
+		return r.FullMethod == fullMethod
+	}
+}
@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/ratelimit"
+	limitmocks "webooktrial/pkg/ratelimit/mocks"
+)
+
+func TestMethodInterceptorBuilder_BuildServerInterceptor(t *testing.T) {
+	const method = "/reward.v1.RewardService/GetReward"
+	testCases := []struct {
+		name     string
+		rule     MethodRule
+		fallback FallbackFunc
+		mock     func(ctrl *gomock.Controller) ratelimit.Limiter
+		caller   string
+
+		wantResp any
+		wantCode codes.Code
+	}{
+		{
+			name: "调用方不匹配",
+			rule: MethodRule{Name: "r", FullMethod: method, Callers: []string{"webook"},
+				Interval: time.Second, Rate: 1},
+			mock: func(ctrl *gomock.Controller) ratelimit.Limiter {
+				return limitmocks.NewMockLimiter(ctrl)
+			},
+			caller:   "reward",
+			wantResp: "normal",
+		},
+		{
+			name: "按照调用方计数，触发限流，拒绝",
+			rule: MethodRule{Name: "r", FullMethod: "/reward.v1.RewardService/*",
+				PerCaller: true, Interval: time.Second, Rate: 1},
+			mock: func(ctrl *gomock.Controller) ratelimit.Limiter {
+				l := limitmocks.NewMockLimiter(ctrl)
+				l.EXPECT().Limit(gomock.Any(), "limiter:grpc:r:"+method+":webook").
+					Return(true, nil)
+				return l
+			},
+			caller:   "webook",
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			name: "触发限流，降级执行",
+			rule: MethodRule{Name: "r", FullMethod: method, Degrade: true,
+				Interval: time.Second, Rate: 1},
+			mock: func(ctrl *gomock.Controller) ratelimit.Limiter {
+				l := limitmocks.NewMockLimiter(ctrl)
+				l.EXPECT().Limit(gomock.Any(), "limiter:grpc:r:"+method).
+					Return(true, nil)
+				return l
+			},
+			wantResp: "limited",
+		},
+		{
+			name: "触发限流，使用降级函数",
+			rule: MethodRule{Name: "r", FullMethod: method, Interval: time.Second, Rate: 1},
+			fallback: func(ctx context.Context, req any) (any, error) {
+				return "fallback", nil
+			},
+			mock: func(ctrl *gomock.Controller) ratelimit.Limiter {
+				l := limitmocks.NewMockLimiter(ctrl)
+				l.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(true, nil)
+				return l
+			},
+			wantResp: "fallback",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			limiter := tc.mock(ctrl)
+			bd, err := NewMethodInterceptorBuilder(func(interval time.Duration, rate int) ratelimit.Limiter {
+				return limiter
+			}, logger.NewNopLogger(), tc.rule)
+			require.NoError(t, err)
+			if tc.fallback != nil {
+				bd.RegisterFallback(method, tc.fallback)
+			}
+			ctx := metadata.NewIncomingContext(context.Background(),
+				metadata.Pairs("app", tc.caller))
+			resp, err := bd.BuildServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method},
+				func(ctx context.Context, req any) (any, error) {
+					if IsLimited(ctx) {
+						return "limited", nil
+					}
+					return "normal", nil
+				})
+			assert.Equal(t, tc.wantCode, status.Code(err))
+			assert.Equal(t, tc.wantResp, resp)
+		})
+	}
+}
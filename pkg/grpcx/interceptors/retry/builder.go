@@ -0,0 +1,195 @@
+package retry
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Observer 用来上报重试和对冲的次数，一般是 prometheus.ClientMetrics
+type Observer interface {
+	ObserveRetry(target, method string, code codes.Code)
+	ObserveHedge(target, method string)
+}
+
+// Policy 某个方法的重试策略
+type Policy struct {
+	// Idempotent 方法是不是幂等的，只有幂等的方法才会重试和对冲
+	// 例如 IncrReadCnt 重试了就可能多加一次阅读数
+	Idempotent bool `yaml:"idempotent" json:"idempotent"`
+	// MaxAttempts 最多调用几次，包含第一次
+	MaxAttempts int `yaml:"maxAttempts" json:"maxAttempts"`
+	// InitialBackoff 第一次重试之前最多等待多久，之后每次翻倍
+	InitialBackoff time.Duration `yaml:"initialBackoff" json:"initialBackoff"`
+	// MaxBackoff 重试间隔的上限
+	MaxBackoff time.Duration `yaml:"maxBackoff" json:"maxBackoff"`
+	// RetryableCodes 哪些错误码可以重试，为空的时候只重试 Unavailable
+	RetryableCodes []codes.Code `yaml:"retryableCodes" json:"retryableCodes"`
+	// HedgingDelay 大于 0 的时候启用对冲：
+	// 超过这个时间还没有返回，就再发一个请求，谁先返回用谁的
+	HedgingDelay time.Duration `yaml:"hedgingDelay" json:"hedgingDelay"`
+}
+
+// DefaultPolicy 默认不重试，调用方要显式声明哪些方法是幂等的
+var DefaultPolicy = Policy{
+	MaxAttempts:    1,
+	InitialBackoff: 50 * time.Millisecond,
+	MaxBackoff:     time.Second,
+}
+
+// ClientInterceptorBuilder 客户端重试和对冲
+type ClientInterceptorBuilder struct {
+	defaultPolicy Policy
+	// key 是 FullMethod
+	policies map[string]Policy
+	observer Observer
+}
+
+func NewClientInterceptorBuilder() *ClientInterceptorBuilder {
+	return &ClientInterceptorBuilder{
+		defaultPolicy: DefaultPolicy,
+		policies:      make(map[string]Policy),
+	}
+}
+
+func (b *ClientInterceptorBuilder) DefaultPolicy(p Policy) *ClientInterceptorBuilder {
+	b.defaultPolicy = p
+	return b
+}
+
+// Policy 给某个方法设置重试策略，fullMethod 例如 /intr.v1.InteractiveService/GetByIds
+func (b *ClientInterceptorBuilder) Policy(fullMethod string, p Policy) *ClientInterceptorBuilder {
+	b.policies[fullMethod] = p
+	return b
+}
+
+func (b *ClientInterceptorBuilder) Observer(observer Observer) *ClientInterceptorBuilder {
+	b.observer = observer
+	return b
+}
+
+func (b *ClientInterceptorBuilder) Build() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any,
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		p, ok := b.policies[method]
+		if !ok {
+			p = b.defaultPolicy
+		}
+		if !p.Idempotent {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		call := func(ctx context.Context, reply any) error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		if p.HedgingDelay > 0 {
+			if msg, ok := reply.(proto.Message); ok {
+				call = b.hedge(cc.Target(), method, p.HedgingDelay, msg, call)
+			}
+		}
+		return b.retry(ctx, cc.Target(), method, p, reply, call)
+	}
+}
+
+func (b *ClientInterceptorBuilder) retry(ctx context.Context, target, method string,
+	p Policy, reply any, call func(ctx context.Context, reply any) error) error {
+	var err error
+	for i := 0; i < max(p.MaxAttempts, 1); i++ {
+		if i > 0 {
+			code := status.Code(err)
+			if !p.retryable(code) {
+				return err
+			}
+			if b.observer != nil {
+				b.observer.ObserveRetry(target, method, code)
+			}
+			timer := time.NewTimer(p.backoff(i))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+		err = call(ctx, reply)
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// hedge 超过 delay 还没有返回，就再发一次请求
+// 两次请求用的是不同的 reply，避免并发写，最后把先成功的那个复制到 reply 里面
+func (b *ClientInterceptorBuilder) hedge(target, method string, delay time.Duration,
+	reply proto.Message, call func(ctx context.Context, reply any) error) func(ctx context.Context, reply any) error {
+	type result struct {
+		reply proto.Message
+		err   error
+	}
+	return func(ctx context.Context, _ any) error {
+		ctx, cancel := context.WithCancel(ctx)
+		// 返回之后，还没有结束的那个请求就取消掉
+		defer cancel()
+		ch := make(chan result, 2)
+		send := func() {
+			r := reply.ProtoReflect().New().Interface()
+			ch <- result{reply: r, err: call(ctx, r)}
+		}
+		go send()
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		inflight := 1
+		var res result
+		for inflight > 0 {
+			select {
+			case <-timer.C:
+				if b.observer != nil {
+					b.observer.ObserveHedge(target, method)
+				}
+				inflight++
+				go send()
+			case res = <-ch:
+				inflight--
+				if res.err == nil {
+					proto.Reset(reply)
+					proto.Merge(reply, res.reply)
+					return nil
+				}
+				// 失败了，如果对冲的请求还没有发出去，也不用再等了
+				if timer.Stop() {
+					return res.err
+				}
+			}
+		}
+		return res.err
+	}
+}
+
+func (p Policy) retryable(code codes.Code) bool {
+	if len(p.RetryableCodes) == 0 {
+		return code == codes.Unavailable
+	}
+	for _, c := range p.RetryableCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff 指数退避加上全抖动，避免大量客户端在同一时刻重试
+func (p Policy) backoff(attempt int) time.Duration {
+	maxBackoff := p.InitialBackoff << (attempt - 1)
+	if maxBackoff <= 0 || (p.MaxBackoff > 0 && maxBackoff > p.MaxBackoff) {
+		maxBackoff = p.MaxBackoff
+	}
+	if maxBackoff <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(maxBackoff)))
+}
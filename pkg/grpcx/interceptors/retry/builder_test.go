@@ -0,0 +1,98 @@
+package retry
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestClientInterceptorBuilder_Build(t *testing.T) {
+	const method = "/intr.v1.InteractiveService/GetByIds"
+	testCases := []struct {
+		name   string
+		policy Policy
+		// 第几次调用返回什么
+		invoke func(attempt int32, reply *wrapperspb.StringValue) error
+
+		wantErr      codes.Code
+		wantReply    string
+		wantAttempts int32
+	}{
+		{
+			name:   "不是幂等的，不重试",
+			policy: Policy{MaxAttempts: 3},
+			invoke: func(attempt int32, reply *wrapperspb.StringValue) error {
+				return status.Error(codes.Unavailable, "节点不可用")
+			},
+			wantErr:      codes.Unavailable,
+			wantAttempts: 1,
+		},
+		{
+			name:   "重试之后成功",
+			policy: Policy{Idempotent: true, MaxAttempts: 3, InitialBackoff: time.Millisecond},
+			invoke: func(attempt int32, reply *wrapperspb.StringValue) error {
+				if attempt < 3 {
+					return status.Error(codes.Unavailable, "节点不可用")
+				}
+				reply.Value = "ok"
+				return nil
+			},
+			wantReply:    "ok",
+			wantAttempts: 3,
+		},
+		{
+			name:   "错误码不可重试",
+			policy: Policy{Idempotent: true, MaxAttempts: 3, InitialBackoff: time.Millisecond},
+			invoke: func(attempt int32, reply *wrapperspb.StringValue) error {
+				return status.Error(codes.InvalidArgument, "参数不对")
+			},
+			wantErr:      codes.InvalidArgument,
+			wantAttempts: 1,
+		},
+		{
+			name:   "对冲，慢的请求被取消",
+			policy: Policy{Idempotent: true, MaxAttempts: 1, HedgingDelay: 10 * time.Millisecond},
+			invoke: func(attempt int32, reply *wrapperspb.StringValue) error {
+				if attempt == 1 {
+					time.Sleep(200 * time.Millisecond)
+					reply.Value = "slow"
+					return nil
+				}
+				reply.Value = "hedge"
+				return nil
+			},
+			wantReply:    "hedge",
+			wantAttempts: 2,
+		},
+	}
+
+	cc, err := grpc.Dial("passthrough:///localhost:0",
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer cc.Close()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var attempts int32
+			interceptor := NewClientInterceptorBuilder().Policy(method, tc.policy).Build()
+			reply := &wrapperspb.StringValue{}
+			err := interceptor(context.Background(), method, nil, reply, cc,
+				func(ctx context.Context, method string, req, reply any,
+					cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+					return tc.invoke(atomic.AddInt32(&attempts, 1), reply.(*wrapperspb.StringValue))
+				})
+			assert.Equal(t, tc.wantErr, status.Code(err))
+			assert.Equal(t, tc.wantReply, reply.GetValue())
+			assert.Equal(t, tc.wantAttempts, atomic.LoadInt32(&attempts))
+		})
+	}
+}
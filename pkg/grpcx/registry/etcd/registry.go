@@ -0,0 +1,163 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	etcdv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/naming/endpoints"
+
+	"webooktrial/pkg/grpcx/registry"
+	"webooktrial/pkg/logger"
+)
+
+// Registry 基于 etcd 的服务注册
+// key 是 service/{name}/{addr}，和 etcd 的 naming/resolver 是兼容的
+// 所以客户端直接用 etcd:///service/{name} 就可以发现服务
+type Registry struct {
+	client *etcdv3.Client
+	ttl    int64
+	l      logger.LoggerV1
+
+	mutex sync.Mutex
+	// key 是 etcd 里面的 key
+	cancels map[string]func()
+}
+
+func NewRegistry(client *etcdv3.Client, ttl int64, l logger.LoggerV1) *Registry {
+	if ttl <= 0 {
+		ttl = 30
+	}
+	return &Registry{
+		client:  client,
+		ttl:     ttl,
+		l:       l,
+		cancels: make(map[string]func()),
+	}
+}
+
+func (r *Registry) Register(ctx context.Context, si registry.ServiceInstance) error {
+	leaseID, err := r.register(ctx, si)
+	if err != nil {
+		return err
+	}
+	kaCtx, cancel := context.WithCancel(context.Background())
+	r.mutex.Lock()
+	if old, ok := r.cancels[r.key(si)]; ok {
+		old()
+	}
+	r.cancels[r.key(si)] = cancel
+	r.mutex.Unlock()
+	go r.keepAlive(kaCtx, si, leaseID)
+	return nil
+}
+
+func (r *Registry) register(ctx context.Context, si registry.ServiceInstance) (etcdv3.LeaseID, error) {
+	em, err := endpoints.NewManager(r.client, r.target(si.Name))
+	if err != nil {
+		return 0, err
+	}
+	leaseResp, err := r.client.Grant(ctx, r.ttl)
+	if err != nil {
+		return 0, err
+	}
+	err = em.AddEndpoint(ctx, r.key(si), endpoints.Endpoint{
+		Addr:     si.Addr,
+		Metadata: si.Metadata(),
+	}, etcdv3.WithLease(leaseResp.ID))
+	return leaseResp.ID, err
+}
+
+// keepAlive 续约。续约的 channel 被关闭，说明租约已经丢了，
+// 例如网络抖动超过了 ttl，或者 etcd 重启了，这时候要重新注册
+func (r *Registry) keepAlive(ctx context.Context, si registry.ServiceInstance, leaseID etcdv3.LeaseID) {
+	for {
+		ch, err := r.client.KeepAlive(ctx, leaseID)
+		if err == nil {
+			for kaResp := range ch {
+				r.l.Debug("续约成功", logger.String("key", r.key(si)),
+					logger.Int64("ttl", kaResp.TTL))
+			}
+		}
+		if ctx.Err() != nil {
+			// 主动注销了
+			return
+		}
+		r.l.Error("租约丢失，重新注册", logger.String("key", r.key(si)), logger.Error(err))
+		backoff := time.Second
+		for {
+			leaseID, err = r.reRegister(ctx, si)
+			if err == nil {
+				break
+			}
+			r.l.Error("重新注册失败", logger.String("key", r.key(si)), logger.Error(err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, time.Duration(r.ttl)*time.Second)
+		}
+	}
+}
+
+func (r *Registry) reRegister(ctx context.Context, si registry.ServiceInstance) (etcdv3.LeaseID, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	return r.register(ctx, si)
+}
+
+func (r *Registry) UnRegister(ctx context.Context, si registry.ServiceInstance) error {
+	r.mutex.Lock()
+	cancel, ok := r.cancels[r.key(si)]
+	delete(r.cancels, r.key(si))
+	r.mutex.Unlock()
+	if ok {
+		cancel()
+	}
+	em, err := endpoints.NewManager(r.client, r.target(si.Name))
+	if err != nil {
+		return err
+	}
+	return em.DeleteEndpoint(ctx, r.key(si))
+}
+
+func (r *Registry) ListServices(ctx context.Context, name string) ([]registry.ServiceInstance, error) {
+	em, err := endpoints.NewManager(r.client, r.target(name))
+	if err != nil {
+		return nil, err
+	}
+	eps, err := em.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]registry.ServiceInstance, 0, len(eps))
+	for _, ep := range eps {
+		si := registry.InstanceFromMetadata(ep.Addr, ep.Metadata)
+		si.Name = name
+		res = append(res, si)
+	}
+	return res, nil
+}
+
+// Close 停止所有的续约，租约过期之后 etcd 会自动删掉对应的 key
+// etcd 的客户端是外面传进来的，这里不负责关闭
+func (r *Registry) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for key, cancel := range r.cancels {
+		cancel()
+		delete(r.cancels, key)
+	}
+	return nil
+}
+
+func (r *Registry) target(name string) string {
+	return "service/" + name
+}
+
+func (r *Registry) key(si registry.ServiceInstance) string {
+	return fmt.Sprintf("%s/%s", r.target(si.Name), si.Addr)
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"webooktrial/pkg/grpcx/registry"
+)
+
+// Registry 基于内存的服务注册，用于测试
+type Registry struct {
+	mutex sync.RWMutex
+	// name => addr => 实例
+	services map[string]map[string]registry.ServiceInstance
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		services: make(map[string]map[string]registry.ServiceInstance),
+	}
+}
+
+func (r *Registry) Register(ctx context.Context, si registry.ServiceInstance) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	instances, ok := r.services[si.Name]
+	if !ok {
+		instances = make(map[string]registry.ServiceInstance)
+		r.services[si.Name] = instances
+	}
+	instances[si.Addr] = si
+	return nil
+}
+
+func (r *Registry) UnRegister(ctx context.Context, si registry.ServiceInstance) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.services[si.Name], si.Addr)
+	return nil
+}
+
+func (r *Registry) ListServices(ctx context.Context, name string) ([]registry.ServiceInstance, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	res := make([]registry.ServiceInstance, 0, len(r.services[name]))
+	for _, si := range r.services[name] {
+		res = append(res, si)
+	}
+	return res, nil
+}
+
+func (r *Registry) Close() error {
+	return nil
+}
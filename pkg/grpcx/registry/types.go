@@ -0,0 +1,75 @@
+package registry
+
+import (
+	"context"
+	"io"
+)
+
+// Registry 服务注册与发现
+type Registry interface {
+	// Register 注册一个服务实例，实现要负责续约，续约失败之后要重新注册
+	Register(ctx context.Context, si ServiceInstance) error
+	// UnRegister 注销服务实例，注销之后客户端就不会再把请求发过来
+	UnRegister(ctx context.Context, si ServiceInstance) error
+	// ListServices 列出某个服务的所有实例
+	ListServices(ctx context.Context, name string) ([]ServiceInstance, error)
+	io.Closer
+}
+
+// ServiceInstance 服务实例
+// Weight、Labels、Group、Version 都会作为元数据发布出去，
+// 客户端的 custom_wrr 负载均衡就是依据这些元数据来挑选节点的
+type ServiceInstance struct {
+	Name    string
+	Addr    string
+	Weight  int
+	Labels  []string
+	Group   string
+	Version string
+}
+
+const (
+	mdWeight  = "weight"
+	mdLabels  = "labels"
+	mdGroup   = "group"
+	mdVersion = "version"
+)
+
+// Metadata 转化为注册中心里面的元数据
+func (si ServiceInstance) Metadata() map[string]any {
+	return map[string]any{
+		mdWeight:  si.Weight,
+		mdLabels:  si.Labels,
+		mdGroup:   si.Group,
+		mdVersion: si.Version,
+	}
+}
+
+// InstanceFromMetadata 从元数据里面恢复服务实例
+// etcd 里面的元数据是 JSON 反序列化出来的，所以数字是 float64，数组是 []any
+func InstanceFromMetadata(addr string, val any) ServiceInstance {
+	si := ServiceInstance{Addr: addr}
+	md, ok := val.(map[string]any)
+	if !ok {
+		return si
+	}
+	switch w := md[mdWeight].(type) {
+	case float64:
+		si.Weight = int(w)
+	case int:
+		si.Weight = w
+	}
+	switch labels := md[mdLabels].(type) {
+	case []string:
+		si.Labels = labels
+	case []any:
+		for _, l := range labels {
+			if str, ok := l.(string); ok {
+				si.Labels = append(si.Labels, str)
+			}
+		}
+	}
+	si.Group, _ = md[mdGroup].(string)
+	si.Version, _ = md[mdVersion].(string)
+	return si
+}
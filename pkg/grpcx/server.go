@@ -2,14 +2,18 @@ package grpcx
 
 import (
 	"context"
+	"errors"
 	"net"
 	"strconv"
 	"time"
 
 	etcdv3 "go.etcd.io/etcd/client/v3"
-	"go.etcd.io/etcd/client/v3/naming/endpoints"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
+	"webooktrial/pkg/grpcx/registry"
+	"webooktrial/pkg/grpcx/registry/etcd"
 	"webooktrial/pkg/logger"
 	"webooktrial/pkg/netx"
 )
@@ -20,12 +24,26 @@ type Server struct {
 	EtcdAddrs []string
 	Name      string
 	L         logger.LoggerV1
-	kaCancel  func()
-	em        endpoints.Manager
 	// ETCD 服务注册租约 TTL
-	EtcdTTL    int64
-	EtcdClient *etcdv3.Client
-	EtcdKey    string
+	EtcdTTL int64
+	// Registry 注册中心，没有设置的时候，使用 EtcdAddrs 创建一个 etcd 的注册中心
+	// 测试的时候可以用 memory.Registry
+	Registry registry.Registry
+	// 下面这些会作为元数据注册到注册中心里面，客户端的 custom_wrr 负载均衡会用到
+	Weight  int
+	Labels  []string
+	Group   string
+	Version string
+	// UnregisterWait 从注册中心摘掉之后，等多久再开始关闭，
+	// 让客户端有时间收到通知，不再把请求发过来。默认 1 秒，小于 0 就不等
+	UnregisterWait time.Duration
+	// DrainTimeout 关闭的时候，最多等待多久让正在处理的请求结束，默认 10 秒
+	DrainTimeout time.Duration
+
+	si     registry.ServiceInstance
+	health *health.Server
+	// 自己创建的 etcd 客户端，关闭的时候要负责关掉
+	etcdClient *etcdv3.Client
 }
 
 func (s *Server) Serve() error {
@@ -33,69 +51,97 @@ func (s *Server) Serve() error {
 	if err != nil {
 		return err
 	}
+	// 标准的 gRPC 健康检查，客户端负载均衡可以据此摘除节点
+	s.health = health.NewServer()
+	healthpb.RegisterHealthServer(s.Server, s.health)
 	err = s.register()
 	if err != nil {
 		return err
 	}
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	s.health.SetServingStatus(s.Name, healthpb.HealthCheckResponse_SERVING)
 	return s.Server.Serve(l)
 }
 
 func (s *Server) register() error {
-	client, err := etcdv3.New(etcdv3.Config{
-		Endpoints: s.EtcdAddrs,
-	})
-	if err != nil {
-		return err
+	if s.Registry == nil {
+		client, err := etcdv3.New(etcdv3.Config{
+			Endpoints: s.EtcdAddrs,
+		})
+		if err != nil {
+			return err
+		}
+		s.etcdClient = client
+		s.Registry = etcd.NewRegistry(client, s.EtcdTTL, s.L)
 	}
-	s.EtcdClient = client
-	// endpoint 以服务为维度。一个服务一个 Manager
-	em, err := endpoints.NewManager(client, "service/"+s.Name)
-	if err != nil {
-		return err
+	s.si = registry.ServiceInstance{
+		Name:    s.Name,
+		Addr:    netx.GetOutboundIP() + ":" + strconv.Itoa(s.Port),
+		Weight:  s.Weight,
+		Labels:  s.Labels,
+		Group:   s.Group,
+		Version: s.Version,
 	}
-	addr := netx.GetOutboundIP() + ":" + strconv.Itoa(s.Port)
-	key := "service/" + s.Name + addr
-	s.EtcdKey = key
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
-	leaseResp, err := client.Grant(ctx, s.EtcdTTL)
-	err = em.AddEndpoint(ctx, key, endpoints.Endpoint{
-		Addr: addr,
-	}, etcdv3.WithLease(leaseResp.ID))
-
-	kaCtx, kaCancel := context.WithCancel(context.Background())
-	s.kaCancel = kaCancel
-	ch, err := client.KeepAlive(kaCtx, leaseResp.ID)
-	if err != nil {
-		return err
-	}
-	go func() {
-		for kaResp := range ch {
-			s.L.Debug(kaResp.String())
-		}
-	}()
-	return nil
+	return s.Registry.Register(ctx, s.si)
 }
 
 // Close 你可以叫做 Shutdown
+// 1. 健康检查先返回 NOT_SERVING，并且从注册中心摘掉，不再有新的请求进来
+// 2. 等 UnregisterWait，客户端摘掉节点之前发过来的请求照样处理
+// 3. 等正在处理的请求结束，超过 DrainTimeout 就强制关闭
 func (s *Server) Close() error {
-	if s.kaCancel != nil {
-		s.kaCancel()
+	if s.health != nil {
+		s.health.Shutdown()
 	}
-	if s.em != nil {
+	var errs []error
+	if s.Registry != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-		defer cancel()
-		err := s.em.DeleteEndpoint(ctx, s.EtcdKey)
+		err := s.Registry.UnRegister(ctx, s.si)
+		cancel()
 		if err != nil {
-			return err
+			s.L.Error("注销服务失败", logger.String("name", s.Name), logger.Error(err))
+			errs = append(errs, err)
+		}
+		if err = s.Registry.Close(); err != nil {
+			errs = append(errs, err)
 		}
 	}
-	if s.EtcdClient != nil {
-		err := s.EtcdClient.Close()
-		if err != nil {
-			return err
+	if s.etcdClient != nil {
+		if err := s.etcdClient.Close(); err != nil {
+			errs = append(errs, err)
 		}
 	}
-	s.Server.GracefulStop()
-	return nil
+	s.waitUnregister()
+	s.drain()
+	return errors.Join(errs...)
+}
+
+func (s *Server) waitUnregister() {
+	wait := s.UnregisterWait
+	if wait == 0 {
+		wait = time.Second
+	}
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+func (s *Server) drain() {
+	timeout := s.DrainTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	done := make(chan struct{})
+	go func() {
+		s.Server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		s.L.Warn("等待请求结束超时，强制关闭", logger.String("name", s.Name))
+		s.Server.Stop()
+	}
 }
@@ -0,0 +1,138 @@
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"webooktrial/pkg/grpcx/registry"
+	"webooktrial/pkg/grpcx/registry/memory"
+	"webooktrial/pkg/logger"
+)
+
+func TestServer_Lifecycle(t *testing.T) {
+	// 先找一个空闲的端口
+	l, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+
+	reg := memory.NewRegistry()
+	server := &Server{
+		Server:   grpc.NewServer(),
+		Port:     port,
+		Name:     "test",
+		L:        logger.NewNopLogger(),
+		Registry: reg,
+		Weight:   20,
+		Labels:   []string{"grey"},
+		Version:  "v1.0.0",
+		// 注销之后等一会儿，检查这段时间还能处理请求
+		UnregisterWait: 500 * time.Millisecond,
+	}
+	go func() {
+		_ = server.Serve()
+	}()
+
+	cc, err := grpc.Dial("localhost:"+strconv.Itoa(port),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer cc.Close()
+	client := healthpb.NewHealthClient(cc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	assert.Eventually(t, func() bool {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "test"})
+		return err == nil && resp.Status == healthpb.HealthCheckResponse_SERVING
+	}, 3*time.Second, 10*time.Millisecond)
+
+	sis, err := reg.ListServices(ctx, "test")
+	require.NoError(t, err)
+	require.Len(t, sis, 1)
+	assert.Equal(t, 20, sis[0].Weight)
+	assert.Equal(t, []string{"grey"}, sis[0].Labels)
+	assert.Equal(t, "v1.0.0", sis[0].Version)
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- server.Close()
+	}()
+	// 已经摘掉了，但是还没有停止服务，请求照样能处理
+	assert.Eventually(t, func() bool {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "test"})
+		return err == nil && resp.Status == healthpb.HealthCheckResponse_NOT_SERVING
+	}, 300*time.Millisecond, 10*time.Millisecond)
+	sis, err = reg.ListServices(ctx, "test")
+	require.NoError(t, err)
+	assert.Len(t, sis, 0)
+	require.NoError(t, <-closed)
+}
+
+type failedRegistry struct {
+	*memory.Registry
+	unRegisterErr error
+	closeErr      error
+}
+
+func (r *failedRegistry) UnRegister(ctx context.Context, si registry.ServiceInstance) error {
+	return r.unRegisterErr
+}
+
+func (r *failedRegistry) Close() error {
+	return r.closeErr
+}
+
+func TestServer_Close(t *testing.T) {
+	unRegisterErr := errors.New("注销失败")
+	closeErr := errors.New("关闭失败")
+	testCases := []struct {
+		name     string
+		reg      *failedRegistry
+		wantErrs []error
+	}{
+		{
+			name: "都成功",
+			reg:  &failedRegistry{Registry: memory.NewRegistry()},
+		},
+		{
+			name:     "注销失败，关闭成功",
+			reg:      &failedRegistry{Registry: memory.NewRegistry(), unRegisterErr: unRegisterErr},
+			wantErrs: []error{unRegisterErr},
+		},
+		{
+			name: "注销和关闭都失败",
+			reg: &failedRegistry{Registry: memory.NewRegistry(),
+				unRegisterErr: unRegisterErr, closeErr: closeErr},
+			wantErrs: []error{unRegisterErr, closeErr},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := &Server{
+				Server:         grpc.NewServer(),
+				Name:           "test",
+				L:              logger.NewNopLogger(),
+				Registry:       tc.reg,
+				UnregisterWait: -1,
+			}
+			err := server.Close()
+			if len(tc.wantErrs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			for _, want := range tc.wantErrs {
+				assert.ErrorIs(t, err, want)
+			}
+		})
+	}
+}
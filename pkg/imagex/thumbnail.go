@@ -0,0 +1,51 @@
+package imagex
+
+import (
+	"image"
+	"image/color"
+)
+
+// Thumbnail 按比例缩小到长边不超过 maxSide，本来就比较小的直接返回。
+// 用的是区域平均，每个目标像素取对应区域里面所有源像素的平均值，
+// 缩小的时候效果比最近邻好很多，也不需要依赖 C 库
+func Thumbnail(src image.Image, maxSide int) image.Image {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	if sw <= maxSide && sh <= maxSide {
+		return src
+	}
+	dw, dh := maxSide, maxSide
+	if sw > sh {
+		dh = max(1, sh*maxSide/sw)
+	} else {
+		dw = max(1, sw*maxSide/sh)
+	}
+	dst := image.NewRGBA64(image.Rect(0, 0, dw, dh))
+	for dy := 0; dy < dh; dy++ {
+		y0 := b.Min.Y + dy*sh/dh
+		y1 := max(y0+1, b.Min.Y+(dy+1)*sh/dh)
+		for dx := 0; dx < dw; dx++ {
+			x0 := b.Min.X + dx*sw/dw
+			x1 := max(x0+1, b.Min.X+(dx+1)*sw/dw)
+			var r, g, bl, a, n uint64
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					cr, cg, cb, ca := src.At(x, y).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					bl += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+			// RGBA() 返回的是预乘过的值，直接平均就可以
+			dst.SetRGBA64(dx, dy, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(bl / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
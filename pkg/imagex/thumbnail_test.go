@@ -0,0 +1,56 @@
+package imagex
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThumbnail(t *testing.T) {
+	testCases := []struct {
+		name     string
+		w, h     int
+		maxSide  int
+		wantW    int
+		wantH    int
+		wantSame bool
+	}{
+		{name: "本来就很小", w: 100, h: 50, maxSide: 200, wantW: 100, wantH: 50, wantSame: true},
+		{name: "横图", w: 400, h: 200, maxSide: 100, wantW: 100, wantH: 50},
+		{name: "竖图", w: 300, h: 600, maxSide: 100, wantW: 50, wantH: 100},
+		{name: "特别细长的图", w: 1000, h: 2, maxSide: 100, wantW: 100, wantH: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := image.NewRGBA(image.Rect(0, 0, tc.w, tc.h))
+			res := Thumbnail(src, tc.maxSide)
+			assert.Equal(t, tc.wantW, res.Bounds().Dx())
+			assert.Equal(t, tc.wantH, res.Bounds().Dy())
+			if tc.wantSame {
+				assert.Same(t, src, res)
+			}
+		})
+	}
+}
+
+func TestThumbnail_Average(t *testing.T) {
+	// 左边一半黑，右边一半白，缩成 2x1 之后还是一黑一白
+	src := image.NewGray(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 2; x < 4; x++ {
+			src.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+	res := Thumbnail(src, 2)
+	r, _, _, _ := res.At(0, 0).RGBA()
+	assert.Equal(t, uint32(0), r)
+	r, _, _, _ = res.At(1, 0).RGBA()
+	assert.Equal(t, uint32(0xffff), r)
+
+	// 缩成 1x1 就是灰色
+	res = Thumbnail(src, 1)
+	r, _, _, _ = res.At(0, 0).RGBA()
+	assert.Equal(t, uint32(0xffff/2), r)
+}
@@ -0,0 +1,54 @@
+package logger
+
+import "strings"
+
+// Masker 把敏感的值变成可以打印的样子
+type Masker func(val string) string
+
+// maskStars 不管原来有多长都用固定个数的星号，长度也不泄露
+const maskStars = "****"
+
+// maskMiddle 保留前 head 个和后 tail 个字符。
+// 太短的时候全部遮住，至少要遮住 4 个字符才有意义
+func maskMiddle(val string, head, tail int) string {
+	runes := []rune(val)
+	if len(runes) < head+tail+len(maskStars) {
+		return maskStars
+	}
+	return string(runes[:head]) + maskStars + string(runes[len(runes)-tail:])
+}
+
+// MaskPhone 152****1234
+func MaskPhone(val string) string {
+	return maskMiddle(val, 3, 4)
+}
+
+// MaskEmail 只保留用户名的第一个字符和域名，a****@qq.com
+func MaskEmail(val string) string {
+	at := strings.LastIndexByte(val, '@')
+	if at <= 0 {
+		return maskStars
+	}
+	name := []rune(val[:at])
+	return string(name[0]) + maskStars + val[at:]
+}
+
+// MaskIDCard 身份证号，保留前 3 位和后 4 位
+func MaskIDCard(val string) string {
+	return maskMiddle(val, 3, 4)
+}
+
+// MaskBankCard 只保留后 4 位，对账的时候够用了
+func MaskBankCard(val string) string {
+	return maskMiddle(val, 0, 4)
+}
+
+// MaskToken 只保留前 4 位，用来区分是哪个 token
+func MaskToken(val string) string {
+	return maskMiddle(val, 4, 0)
+}
+
+// MaskAll 密码之类的，一个字符都不留
+func MaskAll(val string) string {
+	return maskStars
+}
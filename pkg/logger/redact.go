@@ -0,0 +1,267 @@
+package logger
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// maxRedactDepth 嵌套太深的不再往下找，也顺便防止循环引用
+const maxRedactDepth = 8
+
+// opaqueTypes 实现了这些接口的类型自己决定怎么输出，比如 error 和 time.Time，不往里面找
+var opaqueTypes = []reflect.Type{
+	reflect.TypeOf((*error)(nil)).Elem(),
+	reflect.TypeOf((*fmt.Stringer)(nil)).Elem(),
+	reflect.TypeOf((*json.Marshaler)(nil)).Elem(),
+	reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem(),
+}
+
+// Redactor 按照字段名或者类型找到敏感的值，换成 Masker 处理之后的结果。
+// 字段名包括 Field 的 Key、结构体字段名、json tag 和 map 的 key，
+// 比较的时候忽略大小写、下划线和中划线，所以 id_card 和 IdCard 是同一个
+type Redactor struct {
+	byKey  map[string]Masker
+	byType map[reflect.Type]Masker
+}
+
+type RedactorOption func(r *Redactor)
+
+// WithKeyRule 这些字段名的值用 m 处理，会覆盖默认的规则
+func WithKeyRule(m Masker, keys ...string) RedactorOption {
+	return func(r *Redactor) {
+		for _, key := range keys {
+			r.byKey[normalizeKey(key)] = m
+		}
+	}
+}
+
+// WithTypeRule 这个类型的值不管字段名叫什么都用 m 处理，
+// 比如 domain 里面定义的 type Phone string。
+// 不是字符串的类型先用 fmt.Sprint 转成字符串
+func WithTypeRule(typ reflect.Type, m Masker) RedactorOption {
+	return func(r *Redactor) {
+		r.byType[typ] = m
+	}
+}
+
+// NewRedactor 默认识别手机号、邮箱、身份证、银行卡、token 和密码
+func NewRedactor(opts ...RedactorOption) *Redactor {
+	r := &Redactor{
+		byKey:  make(map[string]Masker),
+		byType: make(map[reflect.Type]Masker),
+	}
+	defaults := []RedactorOption{
+		WithKeyRule(MaskPhone, "phone", "mobile", "phone_number"),
+		WithKeyRule(MaskEmail, "email", "mail"),
+		WithKeyRule(MaskIDCard, "id_card", "id_no", "id_number"),
+		WithKeyRule(MaskBankCard, "bank_card", "card_no", "card_number"),
+		WithKeyRule(MaskToken, "token", "access_token", "refresh_token", "jwt", "authorization"),
+		WithKeyRule(MaskAll, "password", "secret"),
+	}
+	for _, opt := range append(defaults, opts...) {
+		opt(r)
+	}
+	return r
+}
+
+// Redact 返回处理之后的 Field，不会修改传进来的值。
+// 没有敏感的值的时候原样返回，结构体里面有的时候会变成 map[string]any
+func (r *Redactor) Redact(f Field) Field {
+	if f.Value == nil {
+		return f
+	}
+	val, changed := r.redact(reflect.ValueOf(f.Value), r.byKey[normalizeKey(f.Key)], 0)
+	if changed {
+		f.Value = val
+	}
+	return f
+}
+
+// redact m 是字段名匹配到的 Masker，没有匹配到就是 nil
+func (r *Redactor) redact(v reflect.Value, m Masker, depth int) (any, bool) {
+	if !v.IsValid() || depth > maxRedactDepth {
+		return nil, false
+	}
+	if tm, ok := r.byType[v.Type()]; ok {
+		return r.mask(v, tm), true
+	}
+	switch v.Kind() {
+	case reflect.String:
+		if m == nil {
+			return nil, false
+		}
+		return m(v.String()), true
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() || (m == nil && r.opaque(v.Type())) {
+			return nil, false
+		}
+		return r.redact(v.Elem(), m, depth+1)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			// []byte 当成一个值，不一个个字节处理
+			if m == nil {
+				return nil, false
+			}
+			return m(string(v.Bytes())), true
+		}
+		return r.redactSlice(v, m, depth)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		return r.redactMap(v, depth)
+	case reflect.Struct:
+		if r.opaque(v.Type()) {
+			return nil, false
+		}
+		return r.redactStruct(v, depth)
+	default:
+		if m == nil {
+			return nil, false
+		}
+		// 数字类型的手机号之类的
+		return r.mask(v, m), true
+	}
+}
+
+func (r *Redactor) opaque(typ reflect.Type) bool {
+	for _, it := range opaqueTypes {
+		if typ.Implements(it) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) redactSlice(v reflect.Value, m Masker, depth int) (any, bool) {
+	res := make([]any, v.Len())
+	changed := false
+	for i := range res {
+		elem := v.Index(i)
+		val, ok := r.redact(elem, m, depth+1)
+		if ok {
+			changed = true
+			res[i] = val
+		} else if elem.CanInterface() {
+			res[i] = elem.Interface()
+		}
+	}
+	return res, changed
+}
+
+func (r *Redactor) redactMap(v reflect.Value, depth int) (any, bool) {
+	res := make(map[string]any, v.Len())
+	changed := false
+	iter := v.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+		val, ok := r.redact(iter.Value(), r.byKey[normalizeKey(key)], depth+1)
+		if ok {
+			changed = true
+			res[key] = val
+		} else {
+			res[key] = iter.Value().Interface()
+		}
+	}
+	return res, changed
+}
+
+// redactStruct 按照 json 的规则转成 map，这样输出的格式和原来一样
+func (r *Redactor) redactStruct(v reflect.Value, depth int) (any, bool) {
+	res := make(map[string]any, v.NumField())
+	changed := false
+	typ := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		sf := typ.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		fv := v.Field(i)
+		// 匿名嵌入的结构体，json 会把字段展开到外面，类型没有导出也一样
+		if sf.Anonymous && name == "" && fv.Kind() == reflect.Struct && !r.opaque(sf.Type) {
+			val, ok := r.redactStruct(fv, depth+1)
+			changed = changed || ok
+			for k, sub := range val.(map[string]any) {
+				res[k] = sub
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		m := r.byKey[normalizeKey(name)]
+		if m == nil {
+			m = r.byKey[normalizeKey(sf.Name)]
+		}
+		val, ok := r.redact(fv, m, depth+1)
+		if ok {
+			changed = true
+			res[name] = val
+		} else {
+			res[name] = fv.Interface()
+		}
+	}
+	return res, changed
+}
+
+func (r *Redactor) mask(v reflect.Value, m Masker) string {
+	if v.Kind() == reflect.String {
+		return m(v.String())
+	}
+	if v.CanInterface() {
+		return m(fmt.Sprint(v.Interface()))
+	}
+	return m("")
+}
+
+func normalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(key))
+}
+
+// RedactLogger 在交给真正的 LoggerV1 之前处理掉敏感的值，
+// 所以对任何实现都有效
+type RedactLogger struct {
+	l LoggerV1
+	r *Redactor
+}
+
+func NewRedactLogger(l LoggerV1, r *Redactor) *RedactLogger {
+	return &RedactLogger{l: l, r: r}
+}
+
+func (l *RedactLogger) Debug(msg string, args ...Field) {
+	l.l.Debug(msg, l.redact(args)...)
+}
+
+func (l *RedactLogger) Info(msg string, args ...Field) {
+	l.l.Info(msg, l.redact(args)...)
+}
+
+func (l *RedactLogger) Warn(msg string, args ...Field) {
+	l.l.Warn(msg, l.redact(args)...)
+}
+
+func (l *RedactLogger) Error(msg string, args ...Field) {
+	l.l.Error(msg, l.redact(args)...)
+}
+
+// redact 复制一份，调用者的 args 不能被改掉
+func (l *RedactLogger) redact(args []Field) []Field {
+	res := make([]Field, len(args))
+	for i, arg := range args {
+		res[i] = l.r.Redact(arg)
+	}
+	return res
+}
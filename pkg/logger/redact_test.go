@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskers(t *testing.T) {
+	testCases := []struct {
+		name   string
+		masker Masker
+		val    string
+		want   string
+	}{
+		{name: "手机号", masker: MaskPhone, val: "15212345678", want: "152****5678"},
+		{name: "手机号太短", masker: MaskPhone, val: "152", want: "****"},
+		{name: "空字符串", masker: MaskPhone, val: "", want: "****"},
+		{name: "邮箱", masker: MaskEmail, val: "xqc123@qq.com", want: "x****@qq.com"},
+		{name: "中文用户名的邮箱", masker: MaskEmail, val: "小明@qq.com", want: "小****@qq.com"},
+		{name: "不是邮箱", masker: MaskEmail, val: "@qq.com", want: "****"},
+		{name: "身份证", masker: MaskIDCard, val: "11010519491231002X", want: "110****002X"},
+		{name: "银行卡", masker: MaskBankCard, val: "6222021234567890123", want: "****0123"},
+		{name: "token", masker: MaskToken, val: "eyJhbGciOiJIUzI1NiJ9", want: "eyJh****"},
+		{name: "token 太短", masker: MaskToken, val: "abcdefg", want: "****"},
+		{name: "密码", masker: MaskAll, val: "hello#world123", want: "****"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.masker(tc.val))
+		})
+	}
+}
+
+type Phone string
+
+type profile struct {
+	Nickname string `json:"nickname"`
+	Email    string `json:"email"`
+	Password string `json:"-"`
+	Ctime    time.Time
+	token    string
+}
+
+type user struct {
+	profile
+	Id      int64  `json:"id"`
+	Mobile  string `json:"mobile,omitempty"`
+	IdCard  string
+	Contact Phone `json:"contact"`
+}
+
+func TestRedactor_Redact(t *testing.T) {
+	ctime := time.UnixMilli(1700000000000)
+	err := errors.New("手机号 15212345678 不存在")
+	testCases := []struct {
+		name  string
+		field Field
+		want  Field
+	}{
+		{name: "按照 Key", field: String("phone", "15212345678"), want: String("phone", "152****5678")},
+		{name: "Key 忽略大小写和下划线", field: String("ID_Card", "11010519491231002X"),
+			want: String("ID_Card", "110****002X")},
+		{name: "不敏感的 Key", field: String("nickname", "小明"), want: String("nickname", "小明")},
+		{name: "数字", field: Int64("phone", 15212345678), want: String("phone", "152****5678")},
+		{name: "指针", field: Field{Key: "token", Value: ptr("eyJhbGciOiJIUzI1NiJ9")},
+			want: String("token", "eyJh****")},
+		{name: "nil", field: Field{Key: "phone"}, want: Field{Key: "phone"}},
+		{name: "按照类型", field: Field{Key: "to", Value: Phone("15212345678")},
+			want: String("to", "152****5678")},
+		{name: "error 不处理", field: Error(err), want: Error(err)},
+		{name: "map", field: Field{Key: "req", Value: map[string]any{
+			"phone": "15212345678", "code": 123456, "extra": map[string]string{"access_token": "abcd1234efgh"},
+		}}, want: Field{Key: "req", Value: map[string]any{
+			"phone": "152****5678", "code": 123456, "extra": map[string]any{"access_token": "abcd****"},
+		}}},
+		{name: "没有敏感字段的 map 原样返回", field: Field{Key: "req", Value: map[string]int{"cnt": 1}},
+			want: Field{Key: "req", Value: map[string]int{"cnt": 1}}},
+		{name: "结构体按照 json 的规则展开", field: Field{Key: "user", Value: &user{
+			profile: profile{Nickname: "小明", Email: "xqc123@qq.com", Password: "123", Ctime: ctime, token: "x"},
+			Id:      1, Mobile: "15212345678", IdCard: "11010519491231002X", Contact: "13800138000",
+		}}, want: Field{Key: "user", Value: map[string]any{
+			"nickname": "小明", "email": "x****@qq.com", "Ctime": ctime,
+			"id": int64(1), "mobile": "152****5678", "IdCard": "110****002X", "contact": "138****8000",
+		}}},
+		{name: "切片", field: Field{Key: "phone", Value: []string{"15212345678", "13800138000"}},
+			want: Field{Key: "phone", Value: []any{"152****5678", "138****8000"}}},
+		{name: "[]byte 当成一个值", field: Field{Key: "token", Value: []byte("abcd1234efgh")},
+			want: String("token", "abcd****")},
+	}
+	r := NewRedactor(WithTypeRule(reflect.TypeOf(Phone("")), MaskPhone))
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, r.Redact(tc.field))
+		})
+	}
+}
+
+func TestRedactor_CustomKeyRule(t *testing.T) {
+	r := NewRedactor(WithKeyRule(MaskAll, "phone", "openid"))
+	assert.Equal(t, String("phone", "****"), r.Redact(String("phone", "15212345678")))
+	assert.Equal(t, String("openId", "****"), r.Redact(String("openId", "o6_bmjrPTlm6")))
+}
+
+// recordLogger 把收到的 Field 记下来
+type recordLogger struct {
+	NopLogger
+	fields []Field
+}
+
+func (r *recordLogger) Info(msg string, args ...Field) {
+	r.fields = args
+}
+
+func TestRedactLogger(t *testing.T) {
+	rec := &recordLogger{}
+	l := NewRedactLogger(rec, NewRedactor())
+	args := []Field{String("phone", "15212345678"), Int64("uid", 1)}
+	l.Info("用户未注册", args...)
+	assert.Equal(t, []Field{String("phone", "152****5678"), Int64("uid", 1)}, rec.fields)
+	// 调用者的 args 不能被改掉
+	assert.Equal(t, String("phone", "15212345678"), args[0])
+
+	// NopLogger 也一样能用
+	NewRedactLogger(NewNopLogger(), NewRedactor()).Error("失败", args...)
+}
+
+func ptr[T any](t T) *T {
+	return &t
+}
@@ -0,0 +1,114 @@
+package moderation
+
+import "unicode"
+
+// acMatcher Aho-Corasick 自动机，一次扫描就能找出文本里面所有的敏感词。
+// 构建好之后只读，所以可以并发使用，更新词库就重新构建一个
+type acMatcher struct {
+	nodes []acNode
+	words []Word
+}
+
+type acNode struct {
+	children map[rune]int
+	// fail 失配的时候跳过去的节点，也就是当前前缀最长的、同时是某个词前缀的后缀
+	fail int
+	// outputs 以这个节点结尾的词，包括顺着 fail 链能到达的
+	outputs []int
+}
+
+func newACMatcher(words []Word) *acMatcher {
+	m := &acMatcher{
+		nodes: []acNode{{children: map[rune]int{}}},
+	}
+	for _, w := range words {
+		rs := normalize(w.Text)
+		if len(rs) == 0 {
+			continue
+		}
+		cur := 0
+		for _, r := range rs {
+			next, ok := m.nodes[cur].children[r]
+			if !ok {
+				next = len(m.nodes)
+				m.nodes = append(m.nodes, acNode{children: map[rune]int{}})
+				m.nodes[cur].children[r] = next
+			}
+			cur = next
+		}
+		m.nodes[cur].outputs = append(m.nodes[cur].outputs, len(m.words))
+		m.words = append(m.words, w)
+	}
+	m.buildFail()
+	return m
+}
+
+// buildFail 按照层次遍历，父节点的 fail 一定先算好
+func (m *acMatcher) buildFail() {
+	queue := make([]int, 0, len(m.nodes))
+	for _, child := range m.nodes[0].children {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for r, child := range m.nodes[cur].children {
+			f := m.nodes[cur].fail
+			for f > 0 {
+				if _, ok := m.nodes[f].children[r]; ok {
+					break
+				}
+				f = m.nodes[f].fail
+			}
+			if next, ok := m.nodes[f].children[r]; ok && next != child {
+				m.nodes[child].fail = next
+			}
+			fail := m.nodes[child].fail
+			m.nodes[child].outputs = append(m.nodes[child].outputs, m.nodes[fail].outputs...)
+			queue = append(queue, child)
+		}
+	}
+}
+
+// match 返回命中的词，同一个词只返回一次
+func (m *acMatcher) match(text string) []Word {
+	var (
+		res  []Word
+		seen map[int]struct{}
+		cur  int
+	)
+	for _, r := range normalize(text) {
+		for cur > 0 {
+			if _, ok := m.nodes[cur].children[r]; ok {
+				break
+			}
+			cur = m.nodes[cur].fail
+		}
+		if next, ok := m.nodes[cur].children[r]; ok {
+			cur = next
+		}
+		for _, idx := range m.nodes[cur].outputs {
+			if seen == nil {
+				seen = make(map[int]struct{}, 4)
+			}
+			if _, ok := seen[idx]; ok {
+				continue
+			}
+			seen[idx] = struct{}{}
+			res = append(res, m.words[idx])
+		}
+	}
+	return res
+}
+
+// normalize 忽略大小写，并且跳过空白和标点，避免 "敏 感 词" 这种绕过
+func normalize(s string) []rune {
+	rs := make([]rune, 0, len(s))
+	for _, r := range s {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) {
+			continue
+		}
+		rs = append(rs, unicode.ToLower(r))
+	}
+	return rs
+}
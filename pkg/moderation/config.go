@@ -0,0 +1,36 @@
+package moderation
+
+import (
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/viperx"
+)
+
+// NewModeratorFromConfig 读取 key 下面的 policy 和 words，
+// 修改配置文件之后会重新加载词库，policy 不会热更新
+func NewModeratorFromConfig(key string, l logger.LoggerV1) *Moderator {
+	type Config struct {
+		Policy Policy `yaml:"policy"`
+		Words  []Word `yaml:"words"`
+	}
+	cfg := Config{
+		Policy: Policy{ApproveBelow: 0.3, RejectAbove: 0.8},
+	}
+	err := viper.UnmarshalKey(key, &cfg)
+	if err != nil {
+		panic(err)
+	}
+	filter := NewWordFilter(cfg.Words)
+	viperx.OnConfigChange(func(in fsnotify.Event) {
+		var words []Word
+		err := viper.UnmarshalKey(key+".words", &words)
+		if err != nil {
+			l.Error("重新加载敏感词失败", logger.Error(err))
+			return
+		}
+		filter.Reload(words)
+	})
+	return NewModerator(filter, cfg.Policy)
+}
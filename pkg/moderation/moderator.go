@@ -0,0 +1,29 @@
+package moderation
+
+import "context"
+
+// Verdict 审核的结论
+type Verdict struct {
+	Result
+	Decision Decision
+}
+
+// Moderator 把 Checker 和 Policy 组合起来，评论和文章共用
+type Moderator struct {
+	checker Checker
+	policy  Policy
+}
+
+func NewModerator(checker Checker, policy Policy) *Moderator {
+	return &Moderator{checker: checker, policy: policy}
+}
+
+// Moderate Checker 出错的时候返回 DecisionReview 和这个错误，
+// 调用者可以选择转人工，也可以直接放行
+func (m *Moderator) Moderate(ctx context.Context, text string) (Verdict, error) {
+	res, err := m.checker.Check(ctx, text)
+	if err != nil {
+		return Verdict{Decision: DecisionReview}, err
+	}
+	return Verdict{Result: res, Decision: m.policy.Decide(res.Score)}, nil
+}
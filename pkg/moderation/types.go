@@ -0,0 +1,56 @@
+package moderation
+
+import "context"
+
+// Checker 内容审核，可以是本地的敏感词过滤，也可以是第三方的审核服务
+type Checker interface {
+	// Check 返回内容的风险分数，分数在 [0, 1] 之间，越高越危险
+	Check(ctx context.Context, text string) (Result, error)
+}
+
+type Result struct {
+	Score float64
+	// Hits 命中的敏感词或者标签，给人工审核的时候参考
+	Hits []string
+}
+
+type Decision uint8
+
+const (
+	// DecisionReview 需要人工审核
+	DecisionReview Decision = iota
+	// DecisionApprove 自动通过
+	DecisionApprove
+	// DecisionReject 自动拒绝
+	DecisionReject
+)
+
+func (d Decision) String() string {
+	switch d {
+	case DecisionApprove:
+		return "approve"
+	case DecisionReject:
+		return "reject"
+	default:
+		return "review"
+	}
+}
+
+// Policy 根据分数决定自动通过、人工审核还是自动拒绝
+type Policy struct {
+	// ApproveBelow 分数低于这个值自动通过
+	ApproveBelow float64 `yaml:"approveBelow"`
+	// RejectAbove 分数大于等于这个值自动拒绝，介于两者之间的走人工审核
+	RejectAbove float64 `yaml:"rejectAbove"`
+}
+
+func (p Policy) Decide(score float64) Decision {
+	switch {
+	case score >= p.RejectAbove:
+		return DecisionReject
+	case score < p.ApproveBelow:
+		return DecisionApprove
+	default:
+		return DecisionReview
+	}
+}
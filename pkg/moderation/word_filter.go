@@ -0,0 +1,57 @@
+package moderation
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Word 敏感词和它的风险分数
+type Word struct {
+	Text  string  `yaml:"text"`
+	Score float64 `yaml:"score"`
+}
+
+// WordFilter 基于 Aho-Corasick 的敏感词过滤，词库可以在运行期间替换
+type WordFilter struct {
+	matcher atomic.Pointer[acMatcher]
+}
+
+func NewWordFilter(words []Word) *WordFilter {
+	f := &WordFilter{}
+	f.Reload(words)
+	return f
+}
+
+// Reload 整体替换词库，用于词库热更新。
+// 正在进行的检查继续用旧的词库
+func (f *WordFilter) Reload(words []Word) {
+	f.matcher.Store(newACMatcher(words))
+}
+
+// Check 分数是命中的词的分数之和，最多为 1
+func (f *WordFilter) Check(ctx context.Context, text string) (Result, error) {
+	var res Result
+	for _, w := range f.matcher.Load().match(text) {
+		res.Score += w.Score
+		res.Hits = append(res.Hits, w.Text)
+	}
+	res.Score = min(res.Score, 1)
+	return res, nil
+}
+
+// MultiChecker 组合多个 Checker，取最高的分数，命中的合并起来。
+// 任何一个出错都返回错误
+type MultiChecker []Checker
+
+func (m MultiChecker) Check(ctx context.Context, text string) (Result, error) {
+	var res Result
+	for _, c := range m {
+		r, err := c.Check(ctx, text)
+		if err != nil {
+			return Result{}, err
+		}
+		res.Score = max(res.Score, r.Score)
+		res.Hits = append(res.Hits, r.Hits...)
+	}
+	return res, nil
+}
@@ -0,0 +1,63 @@
+package moderation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWordFilter_Check(t *testing.T) {
+	words := []Word{
+		{Text: "he", Score: 0.1},
+		{Text: "she", Score: 0.2},
+		{Text: "his", Score: 0.3},
+		{Text: "hers", Score: 0.4},
+		{Text: "赌博", Score: 0.9},
+	}
+	testCases := []struct {
+		name      string
+		text      string
+		wantHits  []string
+		wantScore float64
+	}{
+		{name: "单词里面带了敏感词", text: "hello world", wantHits: []string{"he"}, wantScore: 0.1},
+		{name: "完全干净", text: "你好", wantScore: 0},
+		// 经典的 ushers，要靠 fail 链找到 he 和 hers
+		{name: "重叠的词", text: "ushers", wantHits: []string{"she", "he", "hers"}, wantScore: 0.7},
+		{name: "同一个词只算一次", text: "she she she", wantHits: []string{"she", "he"}, wantScore: 0.3},
+		{name: "忽略大小写", text: "HIS", wantHits: []string{"his"}, wantScore: 0.3},
+		{name: "中间插了空格和标点", text: "线上赌 ，博", wantHits: []string{"赌博"}, wantScore: 0.9},
+		{name: "分数最多为 1", text: "赌博hers", wantHits: []string{"赌博", "he", "hers"}, wantScore: 1},
+	}
+	f := NewWordFilter(words)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := f.Check(context.Background(), tc.text)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tc.wantHits, res.Hits)
+			assert.InDelta(t, tc.wantScore, res.Score, 1e-9)
+		})
+	}
+}
+
+func TestWordFilter_Reload(t *testing.T) {
+	f := NewWordFilter([]Word{{Text: "旧词", Score: 1}})
+	res, err := f.Check(context.Background(), "旧词新词")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"旧词"}, res.Hits)
+
+	f.Reload([]Word{{Text: "新词", Score: 0.5}})
+	res, err = f.Check(context.Background(), "旧词新词")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"新词"}, res.Hits)
+}
+
+func TestPolicy_Decide(t *testing.T) {
+	p := Policy{ApproveBelow: 0.3, RejectAbove: 0.8}
+	assert.Equal(t, DecisionApprove, p.Decide(0))
+	assert.Equal(t, DecisionReview, p.Decide(0.3))
+	assert.Equal(t, DecisionReview, p.Decide(0.79))
+	assert.Equal(t, DecisionReject, p.Decide(0.8))
+}
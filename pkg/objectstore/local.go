@@ -0,0 +1,155 @@
+package objectstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errInvalidKey = errors.New("非法的 key")
+
+// LocalStore 把对象放在本地目录里面，用于开发和测试，不需要任何外部依赖。
+// 预签名的链接由 LocalStore 自己作为 http.Handler 来校验和返回
+type LocalStore struct {
+	dir string
+	// baseURL 挂载 LocalStore 的地址，比如说 http://localhost:8080/objects
+	baseURL string
+	secret  []byte
+}
+
+func NewLocalStore(dir string, baseURL string, secret string) (*LocalStore, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalStore{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		secret:  []byte(secret),
+	}, nil
+}
+
+// path key 不能跳出 dir
+func (l *LocalStore) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key ||
+		key == ".." || strings.HasPrefix(key, "../") {
+		return "", errInvalidKey
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
+
+func (l *LocalStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(p), 0o755)
+	if err != nil {
+		return err
+	}
+	// 先写临时文件再改名，读的人不会读到写了一半的内容
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if er := tmp.Close(); err == nil {
+		err = er
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), p)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func (l *LocalStore) Get(ctx context.Context, key string) ([]byte, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	return data, err
+}
+
+func (l *LocalStore) Delete(ctx context.Context, key string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (l *LocalStore) Presign(ctx context.Context, key string, expire time.Duration) (string, error) {
+	if _, err := l.path(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(time.Now().Add(expire).Unix(), 10)
+	q := url.Values{}
+	q.Set("expires", expires)
+	q.Set("sign", l.sign(key, expires))
+	return fmt.Sprintf("%s/%s?%s", l.baseURL, key, q.Encode()), nil
+}
+
+func (l *LocalStore) sign(key string, expires string) string {
+	h := hmac.New(sha256.New, l.secret)
+	h.Write([]byte(key))
+	h.Write([]byte{0})
+	h.Write([]byte(expires))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ServeHTTP 校验 Presign 生成的链接，路径去掉 baseURL 的部分就是 key
+func (l *LocalStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	u, err := url.Parse(l.baseURL)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, u.Path), "/")
+	expires := r.URL.Query().Get("expires")
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp ||
+		!hmac.Equal([]byte(l.sign(key, expires)), []byte(r.URL.Query().Get("sign"))) {
+		http.Error(w, "链接无效或者已经过期", http.StatusForbidden)
+		return
+	}
+	p, err := l.path(key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil || st.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeContent(w, r, path.Base(key), st.ModTime(), f)
+}
@@ -0,0 +1,111 @@
+package objectstore
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir(), "http://localhost/objects", "secret")
+	require.NoError(t, err)
+
+	_, err = store.Get(ctx, "articles/1")
+	assert.Equal(t, ErrObjectNotFound, err)
+
+	require.NoError(t, store.Put(ctx, "articles/1", []byte("v1"), "text/plain"))
+	require.NoError(t, store.Put(ctx, "articles/1", []byte("v2"), "text/plain"))
+	data, err := store.Get(ctx, "articles/1")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(data))
+
+	require.NoError(t, store.Delete(ctx, "articles/1"))
+	_, err = store.Get(ctx, "articles/1")
+	assert.Equal(t, ErrObjectNotFound, err)
+	// 删除不存在的对象不是错误
+	assert.NoError(t, store.Delete(ctx, "articles/1"))
+}
+
+func TestLocalStore_InvalidKey(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir(), "http://localhost/objects", "secret")
+	require.NoError(t, err)
+	for _, key := range []string{"", "/abs", "../escape", "a/../../b", "a//b", ".."} {
+		assert.Equal(t, errInvalidKey, store.Put(ctx, key, []byte("x"), ""), key)
+		_, err = store.Get(ctx, key)
+		assert.Equal(t, errInvalidKey, err, key)
+	}
+}
+
+func TestLocalStore_Presign(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir(), "http://localhost/objects", "secret")
+	require.NoError(t, err)
+	require.NoError(t, store.Put(ctx, "img/a.txt", []byte("hello"), "text/plain"))
+
+	testCases := []struct {
+		name     string
+		url      func(t *testing.T) string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name: "有效的链接",
+			url: func(t *testing.T) string {
+				u, err := store.Presign(ctx, "img/a.txt", time.Minute)
+				require.NoError(t, err)
+				return u
+			},
+			wantCode: http.StatusOK,
+			wantBody: "hello",
+		},
+		{
+			name: "过期了",
+			url: func(t *testing.T) string {
+				u, err := store.Presign(ctx, "img/a.txt", -time.Minute)
+				require.NoError(t, err)
+				return u
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name: "篡改了 key",
+			url: func(t *testing.T) string {
+				u, err := store.Presign(ctx, "img/a.txt", time.Minute)
+				require.NoError(t, err)
+				pu, err := url.Parse(u)
+				require.NoError(t, err)
+				pu.Path = "/objects/img/b.txt"
+				return pu.String()
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name: "对象不存在",
+			url: func(t *testing.T) string {
+				u, err := store.Presign(ctx, "img/none.txt", time.Minute)
+				require.NoError(t, err)
+				return u
+			},
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.url(t), nil)
+			recorder := httptest.NewRecorder()
+			store.ServeHTTP(recorder, req)
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			if tc.wantBody != "" {
+				assert.Equal(t, tc.wantBody, recorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrObjectNotFound = errors.New("对象不存在")
+
+// ObjectStore 对象存储的抽象，key 用 / 分隔，不要以 / 开头
+type ObjectStore interface {
+	// Put 同一个 key 会被覆盖
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get 对象不存在的时候返回 ErrObjectNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete 对象不存在也不会返回错误
+	Delete(ctx context.Context, key string) error
+	// Presign 生成一个 expire 之内有效的下载链接，前端可以直接用
+	Presign(ctx context.Context, key string, expire time.Duration) (string, error)
+}
@@ -0,0 +1,68 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodec(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+	testCases := []struct {
+		name   string
+		cursor Cursor
+	}{
+		{name: "毫秒时间戳", cursor: Cursor{Key: 1700000000000, Id: 123}},
+		{name: "负数", cursor: Cursor{Key: -1, Id: 1}},
+		{name: "只有 ID", cursor: Cursor{Id: 99}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			token := codec.Encode(tc.cursor)
+			assert.NotEmpty(t, token)
+			c, err := codec.Decode(token)
+			require.NoError(t, err)
+			assert.Equal(t, tc.cursor, c)
+		})
+	}
+}
+
+func TestCodec_Zero(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+	assert.Equal(t, "", codec.Encode(Cursor{}))
+	c, err := codec.Decode("")
+	require.NoError(t, err)
+	assert.True(t, c.IsZero())
+}
+
+func TestCodec_Invalid(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+	token := codec.Encode(Cursor{Key: 100, Id: 1})
+	testCases := []struct {
+		name  string
+		token string
+	}{
+		{name: "不是 base64", token: "!!!"},
+		{name: "太短", token: "AAAA"},
+		{name: "篡改", token: "A" + token[1:]},
+		{name: "别的密钥签的", token: NewCodec([]byte("other")).Encode(Cursor{Key: 100, Id: 1})},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.token == token {
+				t.Skip("篡改之后和原来一样")
+			}
+			_, err := codec.Decode(tc.token)
+			assert.Equal(t, ErrInvalidCursor, err)
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	fn := func(i int64) Cursor { return Cursor{Key: i * 10, Id: i} }
+	assert.Equal(t, Cursor{Key: 30, Id: 3}, Next([]int64{1, 2, 3}, 3, fn))
+	// 不满一页
+	assert.True(t, Next([]int64{1, 2}, 3, fn).IsZero())
+	assert.True(t, Next([]int64{}, 0, fn).IsZero())
+}
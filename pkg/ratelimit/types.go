@@ -1,6 +1,9 @@
 package ratelimit
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Limiter interface {
 	// Limit 有咩有触发限流。key 就是限流对象
@@ -8,3 +11,7 @@ type Limiter interface {
 	// err 限流器本身有咩有错误
 	Limit(ctx context.Context, key string) (bool, error)
 }
+
+// LimiterFactory 根据窗口大小和阈值创建限流器
+// 一般来说就是 NewRedisSlidingWindowLimiter 绑定上 redis 客户端
+type LimiterFactory func(interval time.Duration, rate int) Limiter
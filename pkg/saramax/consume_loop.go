@@ -0,0 +1,40 @@
+package saramax
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"webooktrial/pkg/logger"
+)
+
+// retryInterval Consume 出错之后等一下再重试，不然 Kafka 挂了的时候会空转
+const retryInterval = time.Second
+
+// ConsumeLoop 一直消费到 ctx 被取消，退出的时候关闭 cg。
+// 每次重平衡 Consume 都会返回，只调用一次的话重平衡之后就收不到消息了
+func ConsumeLoop(ctx context.Context, cg sarama.ConsumerGroup, topics []string,
+	handler sarama.ConsumerGroupHandler, l logger.LoggerV1) {
+	defer func() {
+		err := cg.Close()
+		if err != nil {
+			l.Error("关闭消费者组失败", logger.Error(err))
+		}
+	}()
+	for ctx.Err() == nil {
+		err := cg.Consume(ctx, topics, handler)
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+			return
+		}
+		l.Error("消费出错，稍后重试", logger.Error(err))
+		select {
+		case <-ctx.Done():
+		case <-time.After(retryInterval):
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package saramax
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+
+	"webooktrial/pkg/logger"
+)
+
+// fakeConsumerGroup 每次 Consume 都按顺序返回 errs 里面的错误，用完之后取消 ctx
+type fakeConsumerGroup struct {
+	sarama.ConsumerGroup
+	errs   []error
+	calls  int
+	closed bool
+	cancel context.CancelFunc
+}
+
+func (f *fakeConsumerGroup) Consume(ctx context.Context, topics []string, handler sarama.ConsumerGroupHandler) error {
+	f.calls++
+	if f.calls >= len(f.errs) {
+		f.cancel()
+	}
+	return f.errs[f.calls-1]
+}
+
+func (f *fakeConsumerGroup) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestConsumeLoop(t *testing.T) {
+	testCases := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+	}{
+		{
+			name: "重平衡之后继续消费",
+			errs: []error{nil, nil, nil},
+			// 三次都返回了，说明没有只消费一次
+			wantCalls: 3,
+		},
+		{
+			name:      "出错之后重试",
+			errs:      []error{errors.New("broker 不可用"), nil},
+			wantCalls: 2,
+		},
+		{
+			name:      "消费者组关闭了就退出",
+			errs:      []error{sarama.ErrClosedConsumerGroup, nil},
+			wantCalls: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			cg := &fakeConsumerGroup{errs: tc.errs, cancel: cancel}
+			done := make(chan struct{})
+			go func() {
+				ConsumeLoop(ctx, cg, []string{"test_topic"}, nil, logger.NewNopLogger())
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(3 * retryInterval):
+				t.Fatal("ctx 取消之后没有退出")
+			}
+			assert.Equal(t, tc.wantCalls, cg.calls)
+			assert.True(t, cg.closed)
+		})
+	}
+}
@@ -3,3 +3,9 @@ package saramax
 type Consumer interface {
 	Start() error
 }
+
+// CloseableConsumer Close 之后停止消费，已经拉到的消息会处理完
+type CloseableConsumer interface {
+	Consumer
+	Close() error
+}
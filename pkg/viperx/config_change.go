@@ -0,0 +1,33 @@
+package viperx
+
+import (
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+var (
+	mu        sync.RWMutex
+	listeners []func(in fsnotify.Event)
+)
+
+// OnConfigChange viper 只保留最后一次 OnConfigChange 注册的回调，
+// 限流规则、敏感词这些需要热更新的组件会互相覆盖。
+// 所以统一在这里注册，配置变了之后按照注册的顺序依次调用
+func OnConfigChange(fn func(in fsnotify.Event)) {
+	mu.Lock()
+	listeners = append(listeners, fn)
+	mu.Unlock()
+	// 每次都重新注册，防止被直接调用 viper.OnConfigChange 的地方覆盖掉
+	viper.OnConfigChange(dispatch)
+}
+
+func dispatch(in fsnotify.Event) {
+	mu.RLock()
+	fns := listeners
+	mu.RUnlock()
+	for _, fn := range fns {
+		fn(in)
+	}
+}
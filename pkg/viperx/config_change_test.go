@@ -0,0 +1,40 @@
+package viperx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnConfigChange(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("rate: 1\n"), 0o644))
+	viper.SetConfigFile(file)
+	require.NoError(t, viper.ReadInConfig())
+	viper.WatchConfig()
+
+	first, second := make(chan int, 4), make(chan int, 4)
+	OnConfigChange(func(in fsnotify.Event) {
+		first <- viper.GetInt("rate")
+	})
+	OnConfigChange(func(in fsnotify.Event) {
+		second <- viper.GetInt("rate")
+	})
+
+	require.NoError(t, os.WriteFile(file, []byte("rate: 2\n"), 0o644))
+	// 两个回调都要被调用，后注册的不能覆盖先注册的
+	for _, ch := range []chan int{first, second} {
+		select {
+		case rate := <-ch:
+			assert.Equal(t, 2, rate)
+		case <-time.After(3 * time.Second):
+			t.Fatal("没有收到配置变更")
+		}
+	}
+}
@@ -3,26 +3,62 @@ package zapx
 import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+
+	"webooktrial/pkg/logger"
 )
 
+// MyCore 在 zap 这一层处理敏感字段，直接用 zap.Logger 的地方也能生效。
+// 规则和 logger.Redactor 一样，只处理字符串和 zap.Any 的字段
 type MyCore struct {
 	zapcore.Core
+	// Redactor 为 nil 的时候用默认的规则
+	Redactor *logger.Redactor
+}
+
+var defaultRedactor = logger.NewRedactor()
+
+func NewMyCore(core zapcore.Core, r *logger.Redactor) MyCore {
+	return MyCore{Core: core, Redactor: r}
+}
+
+// With 不覆盖的话，With 出来的 core 就不会处理敏感字段
+func (c MyCore) With(fds []zapcore.Field) zapcore.Core {
+	return MyCore{Core: c.Core.With(c.redact(fds)), Redactor: c.Redactor}
+}
+
+// Check 不覆盖的话，加进去的是里面的 core，Write 根本不会被调用
+func (c MyCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
 }
 
 func (c MyCore) Write(entry zapcore.Entry, fds []zapcore.Field) error {
-	for _, fd := range fds {
-		if fd.Key == "phone" {
-			phone := fd.String
-			fd.String = phone[:3] + "****" + phone[7:]
+	return c.Core.Write(entry, c.redact(fds))
+}
+
+// redact 复制一份，调用者的 fds 不能被改掉
+func (c MyCore) redact(fds []zapcore.Field) []zapcore.Field {
+	r := c.Redactor
+	if r == nil {
+		r = defaultRedactor
+	}
+	res := make([]zapcore.Field, len(fds))
+	for i, fd := range fds {
+		res[i] = fd
+		switch fd.Type {
+		case zapcore.StringType:
+			f := r.Redact(logger.String(fd.Key, fd.String))
+			res[i].String = f.Value.(string)
+		case zapcore.ReflectType:
+			f := r.Redact(logger.Field{Key: fd.Key, Value: fd.Interface})
+			res[i].Interface = f.Value
 		}
 	}
-	return c.Core.Write(entry, fds)
+	return res
 }
 
 func MaskPhone(key string, value string) zap.Field {
-	value = value[:3] + "****" + value[7:]
-	return zap.Field{
-		Key:    key,
-		String: value,
-	}
+	return zap.String(key, logger.MaskPhone(value))
 }
@@ -0,0 +1,50 @@
+package zapx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMyCore(t *testing.T) {
+	testCases := []struct {
+		name   string
+		fields []zap.Field
+		want   map[string]any
+	}{
+		{name: "手机号", fields: []zap.Field{zap.String("phone", "15212345678")},
+			want: map[string]any{"phone": "152****5678"}},
+		// 以前会 panic
+		{name: "太短的手机号", fields: []zap.Field{zap.String("phone", "152")},
+			want: map[string]any{"phone": "****"}},
+		{name: "不敏感的字段", fields: []zap.Field{zap.String("biz", "article"), zap.Int64("uid", 1)},
+			want: map[string]any{"biz": "article", "uid": int64(1)}},
+		{name: "zap.Any 的 map", fields: []zap.Field{zap.Any("req", map[string]string{"email": "xqc123@qq.com"})},
+			want: map[string]any{"req": map[string]any{"email": "x****@qq.com"}}},
+		{name: "MaskPhone", fields: []zap.Field{MaskPhone("to", "15212345678")},
+			want: map[string]any{"to": "152****5678"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			l := zap.New(NewMyCore(core, nil))
+			l.Info("测试", tc.fields...)
+			entries := logs.AllUntimed()
+			assert.Len(t, entries, 1)
+			assert.Equal(t, tc.want, entries[0].ContextMap())
+		})
+	}
+}
+
+func TestMyCore_With(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	fields := []zap.Field{zap.String("phone", "15212345678")}
+	l := zap.New(NewMyCore(core, nil)).With(fields...)
+	l.Info("测试")
+	assert.Equal(t, map[string]any{"phone": "152****5678"}, logs.AllUntimed()[0].ContextMap())
+	// 调用者的 fields 不能被改掉
+	assert.Equal(t, "15212345678", fields[0].String)
+}
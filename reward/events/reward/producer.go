@@ -0,0 +1,51 @@
+package reward
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/IBM/sarama"
+)
+
+const TopicRewardPaid = "reward_paid"
+
+// PaidEvent 打赏支付成功并且入账了。
+// 支付回调可能重复，所以同一笔打赏也可能发多次，下游按照 Rid 去重
+type PaidEvent struct {
+	Rid   int64
+	Uid   int64
+	Biz   string
+	BizId int64
+	// TargetUid 被打赏的人
+	TargetUid int64
+	Amt       int64
+}
+
+// Producer 放在单独的包里面，因为 events 里面的消费者依赖了 service
+type Producer interface {
+	ProducePaidEvent(ctx context.Context, evt PaidEvent) error
+}
+
+type KafkaProducer struct {
+	producer sarama.SyncProducer
+}
+
+func NewKafkaProducer(pc sarama.SyncProducer) Producer {
+	return &KafkaProducer{
+		producer: pc,
+	}
+}
+
+func (k *KafkaProducer) ProducePaidEvent(ctx context.Context, evt PaidEvent) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, _, err = k.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: TopicRewardPaid,
+		Key:   sarama.StringEncoder(strconv.FormatInt(evt.TargetUid, 10)),
+		Value: sarama.ByteEncoder(data),
+	})
+	return err
+}
@@ -2,8 +2,11 @@ package grpc
 
 import (
 	"context"
+	"errors"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	rewardv1 "webooktrial/api/proto/gen/reward/v1"
 	"webooktrial/reward/domain"
@@ -34,12 +37,22 @@ func (r *RewardServiceServer) PreReward(ctx context.Context, req *rewardv1.PreRe
 		},
 		Amt: req.Amt,
 	})
+	// service.ErrLimited 本身就是 ResourceExhausted，原样返回
+	if errors.Is(err, service.ErrBlocked) {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
 	return &rewardv1.PreRewardResponse{
 		CodeUrl: codeURL.URL,
 		Rid:     codeURL.Rid,
 	}, err
 }
 
+// PreRewardFallback PreReward 触发限流之后的降级函数
+// 之前已经生成过二维码的，照样返回二维码，不然才拒绝
+func (r *RewardServiceServer) PreRewardFallback(ctx context.Context, req any) (any, error) {
+	return r.PreReward(ctx, req.(*rewardv1.PreRewardRequest))
+}
+
 func (r *RewardServiceServer) GetReward(ctx context.Context, req *rewardv1.GetRewardRequest) (*rewardv1.GetRewardResponse, error) {
 	rw, err := r.svc.GetReward(ctx, req.Rid, req.Uid)
 	if err != nil {
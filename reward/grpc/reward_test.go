@@ -0,0 +1,70 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	rewardv1 "webooktrial/api/proto/gen/reward/v1"
+	"webooktrial/reward/domain"
+	"webooktrial/reward/service"
+	svcmocks "webooktrial/reward/service/mocks"
+)
+
+func TestRewardServiceServer_PreRewardFallback(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) service.RewardService
+
+		wantCode codes.Code
+		wantURL  string
+	}{
+		{
+			name: "已经生成过二维码",
+			mock: func(ctrl *gomock.Controller) service.RewardService {
+				svc := svcmocks.NewMockRewardService(ctrl)
+				svc.EXPECT().PreReward(gomock.Any(), gomock.Any()).
+					Return(domain.CodeURL{Rid: 1, URL: "weixin://abc"}, nil)
+				return svc
+			},
+			wantCode: codes.OK,
+			wantURL:  "weixin://abc",
+		},
+		{
+			name: "限流，不再创建新的打赏",
+			mock: func(ctrl *gomock.Controller) service.RewardService {
+				svc := svcmocks.NewMockRewardService(ctrl)
+				svc.EXPECT().PreReward(gomock.Any(), gomock.Any()).
+					Return(domain.CodeURL{}, service.ErrLimited)
+				return svc
+			},
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			name: "被拉黑了",
+			mock: func(ctrl *gomock.Controller) service.RewardService {
+				svc := svcmocks.NewMockRewardService(ctrl)
+				svc.EXPECT().PreReward(gomock.Any(), gomock.Any()).
+					Return(domain.CodeURL{}, service.ErrBlocked)
+				return svc
+			},
+			wantCode: codes.PermissionDenied,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := NewRewardServiceServer(tc.mock(ctrl))
+			resp, err := server.PreRewardFallback(context.Background(), &rewardv1.PreRewardRequest{Uid: 123})
+			assert.Equal(t, tc.wantCode, status.Code(err))
+			if err == nil {
+				assert.Equal(t, tc.wantURL, resp.(*rewardv1.PreRewardResponse).CodeUrl)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 
 	accountv1 "webooktrial/api/proto/gen/account/v1"
+	"webooktrial/pkg/grpcx/interceptors"
 )
 
 func InitAccountClient(etcdClient *etcdv3.Client) accountv1.AccountServiceClient {
@@ -24,7 +25,8 @@ func InitAccountClient(etcdClient *etcdv3.Client) accountv1.AccountServiceClient
 	if err != nil {
 		panic(err)
 	}
-	opts := []grpc.DialOption{grpc.WithResolvers(rs)}
+	opts := []grpc.DialOption{grpc.WithResolvers(rs),
+		grpc.WithChainUnaryInterceptor(interceptors.BuildCallerClientInterceptor("reward"))}
 	if !cfg.Secure {
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
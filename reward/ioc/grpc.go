@@ -1,15 +1,24 @@
 package ioc
 
 import (
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/redis/go-redis/v9"
 	"github.com/spf13/viper"
 	"google.golang.org/grpc"
 
+	rewardv1 "webooktrial/api/proto/gen/reward/v1"
 	"webooktrial/pkg/grpcx"
+	"webooktrial/pkg/grpcx/interceptors/ratelimit"
 	"webooktrial/pkg/logger"
+	ratelimit2 "webooktrial/pkg/ratelimit"
+	"webooktrial/pkg/viperx"
 	grpc2 "webooktrial/reward/grpc"
 )
 
 func InitGRPCxServer(reward *grpc2.RewardServiceServer,
+	redisClient redis.Cmdable,
 	l logger.LoggerV1) *grpcx.Server {
 	type Config struct {
 		Port      int      `yaml:"port"`
@@ -21,7 +30,9 @@ func InitGRPCxServer(reward *grpc2.RewardServiceServer,
 	if err != nil {
 		panic(err)
 	}
-	server := grpc.NewServer()
+	limiter := initRateLimitInterceptor(redisClient, l).
+		RegisterFallback(rewardv1.RewardService_PreReward_FullMethodName, reward.PreRewardFallback)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(limiter.BuildServerInterceptor()))
 	reward.Register(server)
 	return &grpcx.Server{
 		Server:    server,
@@ -32,3 +43,38 @@ func InitGRPCxServer(reward *grpc2.RewardServiceServer,
 		EtcdTTL:   cfg.EtcdTTL,
 	}
 }
+
+// initRateLimitInterceptor 按照方法和调用方限流，规则在 grpc.server.ratelimit.rules 下面
+// 例如 GetReward 触发限流之后，带上限流标记继续执行，业务就不会走慢路径查询支付
+// PreReward 触发限流之后走降级函数，只返回已经生成过的二维码
+func initRateLimitInterceptor(redisClient redis.Cmdable, l logger.LoggerV1) *ratelimit.MethodInterceptorBuilder {
+	type Config struct {
+		Rules []ratelimit.MethodRule `yaml:"rules"`
+	}
+	loadRules := func() ([]ratelimit.MethodRule, error) {
+		var cfg Config
+		err := viper.UnmarshalKey("grpc.server.ratelimit", &cfg)
+		return cfg.Rules, err
+	}
+	rules, err := loadRules()
+	if err != nil {
+		panic(err)
+	}
+	bd, err := ratelimit.NewMethodInterceptorBuilder(
+		func(interval time.Duration, rate int) ratelimit2.Limiter {
+			return ratelimit2.NewRedisSlidingWindowLimiter(redisClient, interval, rate)
+		}, l, rules...)
+	if err != nil {
+		panic(err)
+	}
+	viperx.OnConfigChange(func(in fsnotify.Event) {
+		rules, err := loadRules()
+		if err == nil {
+			err = bd.UpdateRules(rules)
+		}
+		if err != nil {
+			l.Error("更新限流规则失败", logger.Error(err))
+		}
+	})
+	return bd.Prefix("limiter:reward")
+}
@@ -8,6 +8,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 
 	pmtv1 "webooktrial/api/proto/gen/payment/v1"
+	"webooktrial/pkg/grpcx/interceptors"
 )
 
 func InitPaymentClient(etcdClient *etcdv3.Client) pmtv1.WechatPaymentServiceClient {
@@ -24,7 +25,8 @@ func InitPaymentClient(etcdClient *etcdv3.Client) pmtv1.WechatPaymentServiceClie
 	if err != nil {
 		panic(err)
 	}
-	opts := []grpc.DialOption{grpc.WithResolvers(rs)}
+	opts := []grpc.DialOption{grpc.WithResolvers(rs),
+		grpc.WithChainUnaryInterceptor(interceptors.BuildCallerClientInterceptor("reward"))}
 	if !cfg.Secure {
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
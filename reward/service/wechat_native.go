@@ -7,10 +7,16 @@ import (
 	"strconv"
 	"strings"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	accountv1 "webooktrial/api/proto/gen/account/v1"
 	pmtv1 "webooktrial/api/proto/gen/payment/v1"
+	followcli "webooktrial/follow/client"
+	"webooktrial/pkg/grpcx/interceptors/ratelimit"
 	"webooktrial/pkg/logger"
 	"webooktrial/reward/domain"
+	rewardevt "webooktrial/reward/events/reward"
 	"webooktrial/reward/repository"
 )
 
@@ -19,14 +25,35 @@ type WechatNativeRewardService struct {
 	repo   repository.RewardRepository
 	l      logger.LoggerV1
 	acli   accountv1.AccountServiceClient
+	// blockChecker 被拉黑了就不能打赏
+	blockChecker followcli.BlockChecker
+	producer     rewardevt.Producer
 }
 
+// ErrBlocked 被打赏的人拉黑了
+var ErrBlocked = errors.New("被作者拉黑了")
+
+// ErrLimited 限流的时候不再创建新的打赏。
+// 直接用 gRPC 的错误码，不管从哪条路径返回，调用方拿到的都是 ResourceExhausted
+var ErrLimited = status.Error(codes.ResourceExhausted, "打赏的人太多了，请稍后再试")
+
 func (w *WechatNativeRewardService) PreReward(ctx context.Context, r domain.Reward) (domain.CodeURL, error) {
+	blocked, err := w.blockChecker.IsBlocked(ctx, r.Target.Uid, r.Uid)
+	if err != nil {
+		return domain.CodeURL{}, err
+	}
+	if blocked {
+		return domain.CodeURL{}, ErrBlocked
+	}
 	// 可以考虑缓存我的二维码，一旦我发现支付成功了，我就清除我的二维码
 	cu, err := w.repo.GetCachedCodeURL(ctx, r)
 	if err == nil {
 		return cu, err
 	}
+	// 限流的时候，只能把已经生成过的二维码还给用户，不再去创建订单和预支付
+	if ratelimit.IsLimited(ctx) {
+		return domain.CodeURL{}, ErrLimited
+	}
 	r.Status = domain.RewardStatusInit
 	rid, err := w.repo.CreateReward(ctx, r)
 	if err != nil {
@@ -66,7 +93,7 @@ func (w *WechatNativeRewardService) GetReward(ctx context.Context, rid, uid int6
 		// 非法查询
 		return domain.Reward{}, errors.New("查询的打赏记录和打赏人对不上")
 	}
-	if r.Completed() || ctx.Value("limited") == "true" {
+	if r.Completed() || ratelimit.IsLimited(ctx) {
 		return r, nil
 	}
 	// 这个时候，考虑到支付到查询结果，我们搞一个慢路径
@@ -145,6 +172,19 @@ func (w *WechatNativeRewardService) UpdateReward(ctx context.Context, bizTradeNO
 			// 引入自动修复功能
 			return err
 		}
+		// 钱已经入账了，通知丢了问题不大
+		er := w.producer.ProducePaidEvent(ctx, rewardevt.PaidEvent{
+			Rid:       rid,
+			Uid:       r.Uid,
+			Biz:       r.Target.Biz,
+			BizId:     r.Target.BizId,
+			TargetUid: r.Target.Uid,
+			Amt:       r.Amt,
+		})
+		if er != nil {
+			w.l.Error("发送打赏成功事件失败",
+				logger.Int64("rid", rid), logger.Error(er))
+		}
 	}
 	return nil
 }
@@ -159,6 +199,9 @@ func (w *WechatNativeRewardService) toRid(tradeNO string) int64 {
 	return val
 }
 
-func NewWechatNativeRewardService(client pmtv1.WechatPaymentServiceClient, repo repository.RewardRepository, l logger.LoggerV1, acli accountv1.AccountServiceClient) RewardService {
-	return &WechatNativeRewardService{client: client, repo: repo, l: l, acli: acli}
+func NewWechatNativeRewardService(client pmtv1.WechatPaymentServiceClient, repo repository.RewardRepository, l logger.LoggerV1,
+	acli accountv1.AccountServiceClient, blockChecker followcli.BlockChecker,
+	producer rewardevt.Producer) RewardService {
+	return &WechatNativeRewardService{client: client, repo: repo, l: l, acli: acli,
+		blockChecker: blockChecker, producer: producer}
 }
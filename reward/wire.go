@@ -3,7 +3,9 @@ package reward
 import (
 	"github.com/google/wire"
 
+	followcli "webooktrial/follow/client"
 	"webooktrial/pkg/wego"
+	rewardevt "webooktrial/reward/events/reward"
 	"webooktrial/reward/grpc"
 	"webooktrial/reward/ioc"
 	"webooktrial/reward/repository"
@@ -16,14 +18,19 @@ var thirdPartySet = wire.NewSet(
 	ioc.InitDB,
 	ioc.InitLogger,
 	ioc.InitEtcdClient,
-	ioc.InitRedis)
+	ioc.InitRedis,
+	ioc.InitKafka,
+	ioc.InitSyncProducer)
 
 func Init() *wego.App {
 	wire.Build(thirdPartySet,
 		service.NewWechatNativeRewardService,
 		ioc.InitAccountClient,
+		ioc.InitFollowClient,
+		followcli.InitBlockChecker,
 		ioc.InitGRPCxServer,
 		ioc.InitPaymentClient,
+		rewardevt.NewKafkaProducer,
 		repository.NewRewardRepository,
 		cache.NewRewardRedisCache,
 		dao.NewRewardGORMDAO,
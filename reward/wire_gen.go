@@ -8,7 +8,9 @@ package reward
 
 import (
 	"github.com/google/wire"
+	followcli "webooktrial/follow/client"
 	"webooktrial/pkg/wego"
+	"webooktrial/reward/events/reward"
 	"webooktrial/reward/grpc"
 	"webooktrial/reward/ioc"
 	"webooktrial/reward/repository"
@@ -29,9 +31,14 @@ func Init() *wego.App {
 	rewardRepository := repository.NewRewardRepository(rewardDAO, rewardCache)
 	loggerV1 := ioc.InitLogger()
 	accountServiceClient := ioc.InitAccountClient(client)
-	rewardService := service.NewWechatNativeRewardService(wechatPaymentServiceClient, rewardRepository, loggerV1, accountServiceClient)
+	followServiceClient := ioc.InitFollowClient(client)
+	blockChecker := followcli.InitBlockChecker(followServiceClient, loggerV1)
+	saramaClient := ioc.InitKafka()
+	syncProducer := ioc.InitSyncProducer(saramaClient)
+	producer := reward.NewKafkaProducer(syncProducer)
+	rewardService := service.NewWechatNativeRewardService(wechatPaymentServiceClient, rewardRepository, loggerV1, accountServiceClient, blockChecker, producer)
 	rewardServiceServer := grpc.NewRewardServiceServer(rewardService)
-	server := ioc.InitGRPCxServer(rewardServiceServer, loggerV1)
+	server := ioc.InitGRPCxServer(rewardServiceServer, cmdable, loggerV1)
 	app := &wego.App{
 		GRPCServer: server,
 	}
@@ -40,4 +47,4 @@ func Init() *wego.App {
 
 // wire.go:
 
-var thirdPartySet = wire.NewSet(ioc.InitDB, ioc.InitLogger, ioc.InitEtcdClient, ioc.InitRedis)
+var thirdPartySet = wire.NewSet(ioc.InitDB, ioc.InitLogger, ioc.InitEtcdClient, ioc.InitRedis, ioc.InitKafka, ioc.InitSyncProducer)
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"webooktrial/pkg/grpcx"
+	"webooktrial/pkg/saramax"
+	"webooktrial/search/ioc"
+)
+
+type App struct {
+	server    *grpcx.Server
+	consumers []saramax.CloseableConsumer
+	snapshot  *ioc.IndexSnapshot
+}
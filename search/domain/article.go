@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+// Article 索引里面的文章，只有已经发表的文章才会进索引
+type Article struct {
+	Id       int64
+	AuthorId int64
+	Title    string
+	Content  string
+	Category string
+	Tags     []string
+	// Utime 发表时间
+	Utime time.Time
+}
+
+// ArticleQuery 搜索条件，零值的过滤条件表示不过滤
+type ArticleQuery struct {
+	// Expression 空格分开的多个关键字必须同时命中
+	Expression string
+	AuthorId   int64
+	Start      time.Time
+	End        time.Time
+	Offset     int
+	Limit      int
+}
+
+// ArticleHit 命中的文章，HighlightTitle 和 Snippet 都是高亮过的
+type ArticleHit struct {
+	Article
+	// HighlightTitle 高亮之后的标题
+	HighlightTitle string
+	// Snippet 内容里面命中关键字的片段
+	Snippet string
+	Score   float64
+}
+
+type ArticleSearchResult struct {
+	Hits  []ArticleHit
+	Total int64
+}
@@ -0,0 +1,143 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"webooktrial/pkg/logger"
+	"webooktrial/pkg/saramax"
+	"webooktrial/search/domain"
+	"webooktrial/search/service"
+)
+
+const (
+	topicArticlePublished = "article_published"
+	topicArticleWithdrawn = "article_withdrawn"
+)
+
+// GroupID 消费者组的前缀。内嵌的索引每个实例各存一份，
+// 所以每个实例要用自己的组，才能收到全部的消息
+type GroupID string
+
+// ArticlePublishedConsumer 文章发表之后写入索引，重复消费会覆盖，所以是幂等的
+type ArticlePublishedConsumer struct {
+	client  sarama.Client
+	group   GroupID
+	svc     service.SyncService
+	offsets *Offsets
+	l       logger.LoggerV1
+	cancel  context.CancelFunc
+}
+
+func NewArticlePublishedConsumer(client sarama.Client,
+	group GroupID,
+	svc service.SyncService,
+	offsets *Offsets,
+	l logger.LoggerV1) *ArticlePublishedConsumer {
+	return &ArticlePublishedConsumer{
+		client:  client,
+		group:   group,
+		svc:     svc,
+		offsets: offsets,
+		l:       l,
+	}
+}
+
+func (a *ArticlePublishedConsumer) Start() error {
+	cg, err := sarama.NewConsumerGroupFromClient(string(a.group)+"_published", a.client)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	go saramax.ConsumeLoop(ctx, cg, []string{topicArticlePublished},
+		a.offsets.handler(saramax.NewHandler[ArticlePublishedEvent](a.l, a.Consume)), a.l)
+	return nil
+}
+
+// Close 停止消费
+func (a *ArticlePublishedConsumer) Close() error {
+	if a.cancel != nil {
+		a.cancel()
+	}
+	return nil
+}
+
+func (a *ArticlePublishedConsumer) Consume(msg *sarama.ConsumerMessage, evt ArticlePublishedEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := a.svc.InputArticle(ctx, domain.Article{
+		Id:       evt.Aid,
+		AuthorId: evt.Uid,
+		Title:    evt.Title,
+		Content:  evt.Content,
+		Category: evt.Category,
+		Tags:     evt.Tags,
+		Utime:    time.UnixMilli(evt.Utime),
+	})
+	if err == nil {
+		a.offsets.Mark(msg)
+	}
+	return err
+}
+
+// ArticleWithdrawnConsumer 文章撤回之后从索引里面删除
+type ArticleWithdrawnConsumer struct {
+	client  sarama.Client
+	group   GroupID
+	svc     service.SyncService
+	offsets *Offsets
+	l       logger.LoggerV1
+	cancel  context.CancelFunc
+}
+
+func NewArticleWithdrawnConsumer(client sarama.Client,
+	group GroupID,
+	svc service.SyncService,
+	offsets *Offsets,
+	l logger.LoggerV1) *ArticleWithdrawnConsumer {
+	return &ArticleWithdrawnConsumer{
+		client:  client,
+		group:   group,
+		svc:     svc,
+		offsets: offsets,
+		l:       l,
+	}
+}
+
+func (a *ArticleWithdrawnConsumer) Start() error {
+	cg, err := sarama.NewConsumerGroupFromClient(string(a.group)+"_withdrawn", a.client)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	go saramax.ConsumeLoop(ctx, cg, []string{topicArticleWithdrawn},
+		a.offsets.handler(saramax.NewHandler[ArticleWithdrawnEvent](a.l, a.Consume)), a.l)
+	return nil
+}
+
+// Close 停止消费
+func (a *ArticleWithdrawnConsumer) Close() error {
+	if a.cancel != nil {
+		a.cancel()
+	}
+	return nil
+}
+
+func (a *ArticleWithdrawnConsumer) Consume(msg *sarama.ConsumerMessage, evt ArticleWithdrawnEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	utime := evt.Utime
+	if utime == 0 {
+		// 老版本的事件，用消息的时间凑合
+		utime = msg.Timestamp.UnixMilli()
+	}
+	err := a.svc.DeleteArticle(ctx, evt.Aid, time.UnixMilli(utime))
+	if err == nil {
+		a.offsets.Mark(msg)
+	}
+	return err
+}
@@ -0,0 +1,98 @@
+package events
+
+import (
+	"sync"
+
+	"github.com/IBM/sarama"
+)
+
+// Offsets 记录已经写进内嵌索引的消息位置，和索引快照保存在一起。
+// 启动的时候从快照里面的位置开始消费，而不是 Kafka 里面自动提交的位置，
+// 这样快照之后才写进索引的消息，重启之后会重新消费一遍。
+// Elasticsearch 自己会持久化，不需要这个，传 nil 就可以
+type Offsets struct {
+	mu sync.Mutex
+	// topic -> 分区 -> 下一条要消费的消息的位置
+	m map[string]map[int32]int64
+}
+
+func NewOffsets() *Offsets {
+	return &Offsets{m: make(map[string]map[int32]int64)}
+}
+
+// Mark 消息已经写进索引
+func (o *Offsets) Mark(msg *sarama.ConsumerMessage) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	partitions, ok := o.m[msg.Topic]
+	if !ok {
+		partitions = make(map[int32]int64)
+		o.m[msg.Topic] = partitions
+	}
+	if msg.Offset+1 > partitions[msg.Partition] {
+		partitions[msg.Partition] = msg.Offset + 1
+	}
+}
+
+// All 复制一份，用来写快照
+func (o *Offsets) All() map[string]map[int32]int64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	res := make(map[string]map[int32]int64, len(o.m))
+	for topic, partitions := range o.m {
+		cp := make(map[int32]int64, len(partitions))
+		for p, offset := range partitions {
+			cp[p] = offset
+		}
+		res[topic] = cp
+	}
+	return res
+}
+
+// Reset 用快照里面的位置替换
+func (o *Offsets) Reset(m map[string]map[int32]int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.m = make(map[string]map[int32]int64, len(m))
+	for topic, partitions := range m {
+		o.m[topic] = partitions
+	}
+}
+
+func (o *Offsets) get(topic string, partition int32) (int64, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	offset, ok := o.m[topic][partition]
+	return offset, ok
+}
+
+// handler 分到分区的时候，先把消费位置退回到快照里面的位置。
+// 快照里面没有的分区，从头开始消费
+func (o *Offsets) handler(h sarama.ConsumerGroupHandler) sarama.ConsumerGroupHandler {
+	if o == nil {
+		return h
+	}
+	return offsetsHandler{ConsumerGroupHandler: h, offsets: o}
+}
+
+type offsetsHandler struct {
+	sarama.ConsumerGroupHandler
+	offsets *Offsets
+}
+
+func (h offsetsHandler) Setup(session sarama.ConsumerGroupSession) error {
+	for topic, partitions := range session.Claims() {
+		for _, p := range partitions {
+			offset, ok := h.offsets.get(topic, p)
+			if !ok {
+				offset = sarama.OffsetOldest
+			}
+			// 只会往回退，Kafka 里面提交的位置比快照旧的时候不用动
+			session.ResetOffset(topic, p, offset, "")
+		}
+	}
+	return h.ConsumerGroupHandler.Setup(session)
+}
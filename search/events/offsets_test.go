@@ -0,0 +1,56 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSession struct {
+	sarama.ConsumerGroupSession
+	claims map[string][]int32
+	resets map[int32]int64
+}
+
+func (m *mockSession) Claims() map[string][]int32 {
+	return m.claims
+}
+
+func (m *mockSession) ResetOffset(topic string, partition int32, offset int64, metadata string) {
+	m.resets[partition] = offset
+}
+
+type nopHandler struct {
+	sarama.ConsumerGroupHandler
+	setup bool
+}
+
+func (h *nopHandler) Setup(session sarama.ConsumerGroupSession) error {
+	h.setup = true
+	return nil
+}
+
+func TestOffsets_Setup(t *testing.T) {
+	offsets := NewOffsets()
+	offsets.Mark(&sarama.ConsumerMessage{Topic: topicArticlePublished, Partition: 0, Offset: 10})
+	// 乱序标记不会回退
+	offsets.Mark(&sarama.ConsumerMessage{Topic: topicArticlePublished, Partition: 0, Offset: 5})
+	assert.Equal(t, map[string]map[int32]int64{topicArticlePublished: {0: 11}}, offsets.All())
+
+	inner := &nopHandler{}
+	session := &mockSession{
+		claims: map[string][]int32{topicArticlePublished: {0, 1}},
+		resets: make(map[int32]int64),
+	}
+	require.NoError(t, offsets.handler(inner).Setup(session))
+	assert.True(t, inner.setup)
+	// 快照里面没有的分区从头开始
+	assert.Equal(t, map[int32]int64{0: 11, 1: sarama.OffsetOldest}, session.resets)
+
+	// 不需要记录位置的时候原样返回
+	var nilOffsets *Offsets
+	assert.Equal(t, sarama.ConsumerGroupHandler(inner), nilOffsets.handler(inner))
+	nilOffsets.Mark(&sarama.ConsumerMessage{})
+}
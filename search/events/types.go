@@ -0,0 +1,21 @@
+package events
+
+// ArticlePublishedEvent 和 internal/events/article.PublishedEvent 保持一致
+type ArticlePublishedEvent struct {
+	Aid     int64
+	Uid     int64
+	Title   string
+	Content string
+	// 发表时间，毫秒数
+	Utime    int64
+	Category string
+	Tags     []string
+}
+
+// ArticleWithdrawnEvent 和 internal/events/article.WithdrawnEvent 保持一致
+type ArticleWithdrawnEvent struct {
+	Aid int64
+	Uid int64
+	// 撤回时间，毫秒数，老版本没有这个字段
+	Utime int64
+}
@@ -0,0 +1,60 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"google.golang.org/grpc"
+
+	searchv1 "webooktrial/api/proto/gen/search/v1"
+	"webooktrial/search/domain"
+	"webooktrial/search/service"
+)
+
+type SearchServiceServer struct {
+	searchv1.UnimplementedSearchServiceServer
+	svc service.SearchService
+}
+
+func NewSearchServiceServer(svc service.SearchService) *SearchServiceServer {
+	return &SearchServiceServer{svc: svc}
+}
+
+func (s *SearchServiceServer) Register(server *grpc.Server) {
+	searchv1.RegisterSearchServiceServer(server, s)
+}
+
+func (s *SearchServiceServer) SearchArticle(ctx context.Context, request *searchv1.SearchArticleRequest) (*searchv1.SearchArticleResponse, error) {
+	q := domain.ArticleQuery{
+		Expression: request.GetExpression(),
+		AuthorId:   request.GetAuthorId(),
+		Offset:     int(request.GetOffset()),
+		Limit:      int(request.GetLimit()),
+	}
+	if request.GetStartTime() > 0 {
+		q.Start = time.UnixMilli(request.GetStartTime())
+	}
+	if request.GetEndTime() > 0 {
+		q.End = time.UnixMilli(request.GetEndTime())
+	}
+	res, err := s.svc.SearchArticle(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return &searchv1.SearchArticleResponse{
+		Total: res.Total,
+		Articles: slice.Map(res.Hits, func(idx int, src domain.ArticleHit) *searchv1.Article {
+			return &searchv1.Article{
+				Id:       src.Id,
+				AuthorId: src.AuthorId,
+				Title:    src.HighlightTitle,
+				Snippet:  src.Snippet,
+				Tags:     src.Tags,
+				Category: src.Category,
+				Utime:    src.Utime.UnixMilli(),
+				Score:    src.Score,
+			}
+		}),
+	}, nil
+}
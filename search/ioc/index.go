@@ -0,0 +1,204 @@
+package ioc
+
+import (
+	"bufio"
+	"context"
+	"encoding/gob"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"webooktrial/pkg/logger"
+	"webooktrial/search/events"
+	"webooktrial/search/repository/dao"
+)
+
+// InitArticleIndex 默认用内嵌的索引，配置了 elastic 就用 Elasticsearch
+func InitArticleIndex() dao.ArticleIndex {
+	type Config struct {
+		Type    string `yaml:"type"`
+		Elastic struct {
+			Addr  string `yaml:"addr"`
+			Index string `yaml:"index"`
+		} `yaml:"elastic"`
+	}
+	var cfg Config
+	err := viper.UnmarshalKey("index", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	switch cfg.Type {
+	case "elastic":
+		index := cfg.Elastic.Index
+		if index == "" {
+			index = "article_index"
+		}
+		res := dao.NewElasticArticleIndex(&http.Client{Timeout: time.Second * 3},
+			cfg.Elastic.Addr, index)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+		err = res.InitIndex(ctx)
+		if err != nil {
+			panic(err)
+		}
+		return res
+	default:
+		return dao.NewMemoryArticleIndex()
+	}
+}
+
+// tombstoneTTL 删除记录保留多久，写快照的时候清理更旧的
+const tombstoneTTL = 24 * time.Hour
+
+// IndexSnapshot 定时把内嵌的索引和消费位置一起写到快照里面，关闭的时候再写一次
+type IndexSnapshot struct {
+	index   *dao.MemoryArticleIndex
+	offsets *events.Offsets
+	path    string
+	l       logger.LoggerV1
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// InitOffsets 只有内嵌的索引需要自己记录消费位置
+func InitOffsets() *events.Offsets {
+	if sharedIndex() {
+		return nil
+	}
+	return events.NewOffsets()
+}
+
+// InitIndexSnapshot 启动的时候从快照恢复索引和消费位置，要在消费者启动之前
+func InitIndexSnapshot(index dao.ArticleIndex, offsets *events.Offsets, l logger.LoggerV1) *IndexSnapshot {
+	type Config struct {
+		Path string `yaml:"path"`
+		// Interval 多久保存一次快照，秒
+		Interval int `yaml:"interval"`
+	}
+	var cfg Config
+	err := viper.UnmarshalKey("index.snapshot", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	memIndex, ok := index.(*dao.MemoryArticleIndex)
+	if !ok || cfg.Path == "" {
+		// 没有快照，重启之后从头消费
+		return &IndexSnapshot{}
+	}
+	res := &IndexSnapshot{
+		index:   memIndex,
+		offsets: offsets,
+		path:    cfg.Path,
+		l:       l,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	err = res.restore()
+	if err != nil {
+		panic(err)
+	}
+	interval := time.Duration(cfg.Interval) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	go res.loop(interval)
+	return res
+}
+
+func (s *IndexSnapshot) loop(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			er := s.save()
+			if er != nil {
+				s.l.Error("保存索引快照失败",
+					logger.String("path", s.path),
+					logger.Error(er))
+			}
+		}
+	}
+}
+
+// Close 停止定时保存，再保存最后一次。要在消费者关闭之后调用
+func (s *IndexSnapshot) Close() error {
+	if s.index == nil {
+		return nil
+	}
+	close(s.stop)
+	<-s.done
+	return s.save()
+}
+
+type snapshotOffsets struct {
+	Offsets map[string]map[int32]int64
+}
+
+func (s *IndexSnapshot) restore() error {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		// 第一次启动，还没有快照
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	// bufio.Reader 实现了 io.ByteReader，gob 不会多读，后面的索引可以接着读
+	r := bufio.NewReader(f)
+	var offsets snapshotOffsets
+	err = gob.NewDecoder(r).Decode(&offsets)
+	if err != nil {
+		return err
+	}
+	err = s.index.Restore(r)
+	if err != nil {
+		return err
+	}
+	s.offsets.Reset(offsets.Offsets)
+	return nil
+}
+
+// save 先写临时文件再改名，避免写到一半的时候崩溃把快照弄坏
+func (s *IndexSnapshot) save() error {
+	s.index.PruneTombstones(time.Now().Add(-tombstoneTTL).UnixMilli())
+	err := os.MkdirAll(filepath.Dir(s.path), 0o755)
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	err = s.write(f)
+	if er := f.Close(); err == nil {
+		err = er
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// write 先取消费位置，再取索引。这样索引里面只会多不会少，重启之后多出来的部分重复消费，是幂等的
+func (s *IndexSnapshot) write(f io.Writer) error {
+	w := bufio.NewWriter(f)
+	err := gob.NewEncoder(w).Encode(snapshotOffsets{Offsets: s.offsets.All()})
+	if err != nil {
+		return err
+	}
+	err = s.index.Snapshot(w)
+	if err != nil {
+		return err
+	}
+	return w.Flush()
+}
@@ -0,0 +1,40 @@
+package ioc
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"webooktrial/pkg/logger"
+	"webooktrial/search/events"
+	"webooktrial/search/repository/dao"
+)
+
+func TestIndexSnapshot(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "article_index.snapshot")
+	index := dao.NewMemoryArticleIndex()
+	offsets := events.NewOffsets()
+	require.NoError(t, index.Upsert(ctx, dao.Article{Id: 1, Title: "搜索服务", Utime: 100}))
+	offsets.Mark(&sarama.ConsumerMessage{Topic: "article_published", Partition: 1, Offset: 7})
+	snapshot := &IndexSnapshot{index: index, offsets: offsets, path: path, l: logger.NewNopLogger()}
+	require.NoError(t, snapshot.save())
+
+	restoredIndex := dao.NewMemoryArticleIndex()
+	restoredOffsets := events.NewOffsets()
+	restored := &IndexSnapshot{index: restoredIndex, offsets: restoredOffsets, path: path}
+	require.NoError(t, restored.restore())
+	assert.Equal(t, map[string]map[int32]int64{"article_published": {1: 8}}, restoredOffsets.All())
+	res, err := restoredIndex.Search(ctx, dao.ArticleQuery{Expression: "搜索"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), res.Total)
+
+	// 没有快照的时候什么都不做
+	empty := &IndexSnapshot{index: dao.NewMemoryArticleIndex(), offsets: events.NewOffsets(),
+		path: filepath.Join(t.TempDir(), "none")}
+	assert.NoError(t, empty.restore())
+}
@@ -0,0 +1,58 @@
+package ioc
+
+import (
+	"os"
+
+	"github.com/IBM/sarama"
+	"github.com/spf13/viper"
+
+	"webooktrial/pkg/saramax"
+	"webooktrial/search/events"
+)
+
+func InitKafka() sarama.Client {
+	type Config struct {
+		Addrs []string `yaml:"addrs"`
+	}
+	saramaCfg := sarama.NewConfig()
+	if !sharedIndex() {
+		// 新的实例要从头消费，把已经发表的文章都放进自己的索引
+		saramaCfg.Consumer.Offsets.Initial = sarama.OffsetOldest
+	}
+	var cfg Config
+	err := viper.UnmarshalKey("kafka", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	client, err := sarama.NewClient(cfg.Addrs, saramaCfg)
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
+// InitGroupID Elasticsearch 是所有实例共享的，用一个消费者组就可以。
+// 内嵌的索引每个实例一份，每个实例都要收到全部的消息，
+// 所以按照主机名区分。重启之后从快照里面记录的位置继续消费，见 IndexSnapshot
+func InitGroupID() events.GroupID {
+	if sharedIndex() {
+		return "search"
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		panic(err)
+	}
+	return events.GroupID("search_" + host)
+}
+
+func sharedIndex() bool {
+	return viper.GetString("index.type") == "elastic"
+}
+
+func NewConsumers(published *events.ArticlePublishedConsumer,
+	withdrawn *events.ArticleWithdrawnConsumer) []saramax.CloseableConsumer {
+	return []saramax.CloseableConsumer{
+		published,
+		withdrawn,
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func main() {
+	initViper()
+	app := InitApp()
+	for _, c := range app.consumers {
+		err := c.Start()
+		if err != nil {
+			panic(err)
+		}
+	}
+	go func() {
+		// 收到退出信号之后关闭服务器，Serve 就会返回
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		<-quit
+		_ = app.server.Close()
+	}()
+	err := app.server.Serve()
+	log.Println(err)
+	for _, c := range app.consumers {
+		_ = c.Close()
+	}
+	// 消费者停了之后再写最后一次快照，不然重启之后会丢掉上次快照之后的文章
+	err = app.snapshot.Close()
+	if err != nil {
+		log.Println("保存索引快照失败", err)
+	}
+}
+
+func initViper() {
+	cfile := pflag.String("config",
+		"config/dev.yaml", "指定配置文件路径")
+	pflag.Parse()
+	viper.SetConfigFile(*cfile)
+	err := viper.ReadInConfig()
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+
+	"webooktrial/search/domain"
+	"webooktrial/search/repository/dao"
+)
+
+type ArticleRepository interface {
+	InputArticle(ctx context.Context, art domain.Article) error
+	// DeleteArticle utime 是撤回时间，索引里面的文章比它新的时候不删除
+	DeleteArticle(ctx context.Context, id int64, utime time.Time) error
+	SearchArticle(ctx context.Context, q domain.ArticleQuery) (domain.ArticleSearchResult, error)
+}
+
+type ArticleIndexRepository struct {
+	index dao.ArticleIndex
+}
+
+func NewArticleRepository(index dao.ArticleIndex) ArticleRepository {
+	return &ArticleIndexRepository{index: index}
+}
+
+func (a *ArticleIndexRepository) InputArticle(ctx context.Context, art domain.Article) error {
+	return a.index.Upsert(ctx, dao.Article{
+		Id:       art.Id,
+		AuthorId: art.AuthorId,
+		Title:    art.Title,
+		Content:  art.Content,
+		Category: art.Category,
+		Tags:     art.Tags,
+		Utime:    art.Utime.UnixMilli(),
+	})
+}
+
+func (a *ArticleIndexRepository) DeleteArticle(ctx context.Context, id int64, utime time.Time) error {
+	return a.index.Delete(ctx, id, utime.UnixMilli())
+}
+
+func (a *ArticleIndexRepository) SearchArticle(ctx context.Context, q domain.ArticleQuery) (domain.ArticleSearchResult, error) {
+	res, err := a.index.Search(ctx, dao.ArticleQuery{
+		Expression: q.Expression,
+		AuthorId:   q.AuthorId,
+		Start:      toMilli(q.Start),
+		End:        toMilli(q.End),
+		Offset:     q.Offset,
+		Limit:      q.Limit,
+	})
+	if err != nil {
+		return domain.ArticleSearchResult{}, err
+	}
+	return domain.ArticleSearchResult{
+		Total: res.Total,
+		Hits: slice.Map(res.Hits, func(idx int, src dao.ArticleHit) domain.ArticleHit {
+			return domain.ArticleHit{
+				Article: domain.Article{
+					Id:       src.Id,
+					AuthorId: src.AuthorId,
+					Category: src.Category,
+					Tags:     src.Tags,
+					Utime:    time.UnixMilli(src.Utime),
+				},
+				HighlightTitle: src.HighlightTitle,
+				Snippet:        src.Snippet,
+				Score:          src.Score,
+			}
+		}),
+	}, nil
+}
+
+// toMilli 零值表示不过滤
+func toMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
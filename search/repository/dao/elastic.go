@@ -0,0 +1,210 @@
+package dao
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// ElasticArticleIndex 基于 Elasticsearch 的 REST API，不依赖客户端 SDK。
+// 索引使用内置的 cjk 分词器，中日韩文字按照相邻的两个字切分
+type ElasticArticleIndex struct {
+	client *http.Client
+	// addr 形如 http://localhost:9200
+	addr  string
+	index string
+}
+
+func NewElasticArticleIndex(client *http.Client, addr string, index string) *ElasticArticleIndex {
+	return &ElasticArticleIndex{client: client, addr: addr, index: index}
+}
+
+// articleMapping 标题、标签和内容用 cjk 分词，其它字段用来过滤
+const articleMapping = `{
+  "mappings": {
+    "properties": {
+      "id": {"type": "long"},
+      "author_id": {"type": "long"},
+      "title": {"type": "text", "analyzer": "cjk"},
+      "content": {"type": "text", "analyzer": "cjk"},
+      "category": {"type": "text", "analyzer": "cjk"},
+      "tags": {"type": "text", "analyzer": "cjk"},
+      "utime": {"type": "long"}
+    }
+  }
+}`
+
+// InitIndex 索引不存在的时候创建
+func (e *ElasticArticleIndex) InitIndex(ctx context.Context) error {
+	resp, err := e.do(ctx, http.MethodHead, "/"+e.index, nil)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	resp, err = e.do(ctx, http.MethodPut, "/"+e.index, []byte(articleMapping))
+	if err != nil {
+		return err
+	}
+	return checkResp(resp, http.StatusOK)
+}
+
+// Upsert 用 Utime 作为外部版本号，版本比已有的旧的时候 ES 返回 409，直接忽略
+func (e *ElasticArticleIndex) Upsert(ctx context.Context, art Article) error {
+	body, err := json.Marshal(art)
+	if err != nil {
+		return err
+	}
+	resp, err := e.do(ctx, http.MethodPut, e.versionedDocPath(art.Id, art.Utime), body)
+	if err != nil {
+		return err
+	}
+	return checkResp(resp, http.StatusOK, http.StatusCreated, http.StatusConflict)
+}
+
+// Delete 带上外部版本号，ES 会保留删除的版本号一段时间（index.gc_deletes，默认 60 秒），
+// 这段时间里面到达的旧的 Upsert 会被拒绝
+func (e *ElasticArticleIndex) Delete(ctx context.Context, id int64, version int64) error {
+	resp, err := e.do(ctx, http.MethodDelete, e.versionedDocPath(id, version), nil)
+	if err != nil {
+		return err
+	}
+	return checkResp(resp, http.StatusOK, http.StatusNotFound, http.StatusConflict)
+}
+
+type esSearchResp struct {
+	Hits struct {
+		Total struct {
+			Value int64 `json:"value"`
+		} `json:"total"`
+		Hits []struct {
+			Score     float64             `json:"_score"`
+			Source    Article             `json:"_source"`
+			Highlight map[string][]string `json:"highlight"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+func (e *ElasticArticleIndex) Search(ctx context.Context, q ArticleQuery) (ArticleSearchResult, error) {
+	body, err := json.Marshal(e.searchBody(q))
+	if err != nil {
+		return ArticleSearchResult{}, err
+	}
+	resp, err := e.do(ctx, http.MethodPost, "/"+e.index+"/_search", body)
+	if err != nil {
+		return ArticleSearchResult{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ArticleSearchResult{}, checkResp(resp, http.StatusOK)
+	}
+	var sr esSearchResp
+	err = json.NewDecoder(resp.Body).Decode(&sr)
+	if err != nil {
+		return ArticleSearchResult{}, err
+	}
+	res := ArticleSearchResult{Total: sr.Hits.Total.Value, Hits: make([]ArticleHit, 0, len(sr.Hits.Hits))}
+	for _, h := range sr.Hits.Hits {
+		hit := ArticleHit{Article: h.Source, Score: h.Score,
+			// 没有命中的字段不会返回高亮，用原文
+			HighlightTitle: html.EscapeString(h.Source.Title),
+		}
+		if t := h.Highlight["title"]; len(t) > 0 {
+			hit.HighlightTitle = t[0]
+		}
+		if c := h.Highlight["content"]; len(c) > 0 {
+			hit.Snippet = c[0]
+		} else {
+			hit.Snippet = snippet(h.Source.Content, "")
+		}
+		res.Hits = append(res.Hits, hit)
+	}
+	return res, nil
+}
+
+func (e *ElasticArticleIndex) searchBody(q ArticleQuery) map[string]any {
+	filters := []any{}
+	if q.AuthorId > 0 {
+		filters = append(filters, map[string]any{"term": map[string]any{"author_id": q.AuthorId}})
+	}
+	if q.Start > 0 || q.End > 0 {
+		rng := map[string]any{}
+		if q.Start > 0 {
+			rng["gte"] = q.Start
+		}
+		if q.End > 0 {
+			rng["lte"] = q.End
+		}
+		filters = append(filters, map[string]any{"range": map[string]any{"utime": rng}})
+	}
+	return map[string]any{
+		"from":             q.Offset,
+		"size":             q.Limit,
+		"track_total_hits": true,
+		"query": map[string]any{
+			"bool": map[string]any{
+				"must": []any{map[string]any{
+					"multi_match": map[string]any{
+						"query":    q.Expression,
+						"fields":   []string{"title^2", "tags^2", "category^2", "content"},
+						"operator": "and",
+						"type":     "cross_fields",
+					},
+				}},
+				"filter": filters,
+			},
+		},
+		"highlight": map[string]any{
+			"pre_tags":  []string{highlightPre},
+			"post_tags": []string{highlightPost},
+			"encoder":   "html",
+			"fields": map[string]any{
+				"title":   map[string]any{"number_of_fragments": 0},
+				"content": map[string]any{"fragment_size": snippetLen, "number_of_fragments": 1},
+			},
+		},
+	}
+}
+
+func (e *ElasticArticleIndex) docPath(id int64) string {
+	return "/" + e.index + "/_doc/" + strconv.FormatInt(id, 10)
+}
+
+// versionedDocPath external_gte 允许版本号相同，重复消费的时候也能写进去
+func (e *ElasticArticleIndex) versionedDocPath(id int64, version int64) string {
+	return e.docPath(id) + "?version_type=external_gte&version=" + strconv.FormatInt(version, 10)
+}
+
+func (e *ElasticArticleIndex) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, e.addr+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return e.client.Do(req)
+}
+
+// checkResp 关闭 Body，状态码不在 expected 里面的时候返回错误
+func checkResp(resp *http.Response, expected ...int) error {
+	defer resp.Body.Close()
+	for _, code := range expected {
+		if resp.StatusCode == code {
+			return nil
+		}
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("elasticsearch 返回了 %d: %s", resp.StatusCode, msg)
+}
@@ -0,0 +1,113 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElasticArticleIndex_Upsert(t *testing.T) {
+	var (
+		method string
+		path   string
+		query  string
+		body   Article
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, query = r.Method, r.URL.Path, r.URL.RawQuery
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	idx := NewElasticArticleIndex(server.Client(), server.URL, "article_index")
+	art := Article{Id: 12, AuthorId: 3, Title: "标题", Content: "内容", Tags: []string{"go"}, Utime: 100}
+	err := idx.Upsert(context.Background(), art)
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "/article_index/_doc/12", path)
+	assert.Equal(t, "version_type=external_gte&version=100", query)
+	assert.Equal(t, art, body)
+}
+
+func TestElasticArticleIndex_Delete(t *testing.T) {
+	testCases := []struct {
+		name    string
+		code    int
+		wantErr bool
+	}{
+		{name: "删除成功", code: http.StatusOK},
+		// 本来就不在索引里面
+		{name: "不存在", code: http.StatusNotFound},
+		// 文章又发表了，比撤回的版本新
+		{name: "版本冲突", code: http.StatusConflict},
+		{name: "服务端出错", code: http.StatusInternalServerError, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodDelete, r.Method)
+				assert.Equal(t, "version_type=external_gte&version=100", r.URL.RawQuery)
+				w.WriteHeader(tc.code)
+			}))
+			defer server.Close()
+			idx := NewElasticArticleIndex(server.Client(), server.URL, "article_index")
+			err := idx.Delete(context.Background(), 1, 100)
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestElasticArticleIndex_Search(t *testing.T) {
+	var req map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/article_index/_search", r.URL.Path)
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &req)
+		_, _ = w.Write([]byte(`{"hits":{"total":{"value":2},"hits":[
+{"_score":2.5,"_source":{"id":1,"author_id":3,"title":"搜索服务","content":"倒排索引","utime":100},
+ "highlight":{"title":["<em>搜索</em>服务"],"content":["<em>倒排</em>索引"]}},
+{"_score":1.5,"_source":{"id":2,"author_id":3,"title":"a<b","content":"讲搜索","utime":50},
+ "highlight":{"content":["讲<em>搜索</em>"]}}]}}`))
+	}))
+	defer server.Close()
+	idx := NewElasticArticleIndex(server.Client(), server.URL, "article_index")
+	res, err := idx.Search(context.Background(), ArticleQuery{
+		Expression: "搜索", AuthorId: 3, Start: 10, Offset: 0, Limit: 10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), res.Total)
+	require.Len(t, res.Hits, 2)
+	assert.Equal(t, "<em>搜索</em>服务", res.Hits[0].HighlightTitle)
+	assert.Equal(t, "<em>倒排</em>索引", res.Hits[0].Snippet)
+	assert.Equal(t, 2.5, res.Hits[0].Score)
+	// 标题没有命中的时候用转义之后的原文
+	assert.Equal(t, "a&lt;b", res.Hits[1].HighlightTitle)
+
+	// 过滤条件：作者和开始时间
+	filters := req["query"].(map[string]any)["bool"].(map[string]any)["filter"].([]any)
+	assert.Len(t, filters, 2)
+	assert.Equal(t, float64(10), req["size"])
+}
+
+func TestElasticArticleIndex_InitIndex(t *testing.T) {
+	var created bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodHead:
+			w.WriteHeader(http.StatusNotFound)
+		case http.MethodPut:
+			created = true
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+	idx := NewElasticArticleIndex(server.Client(), server.URL, "article_index")
+	require.NoError(t, idx.InitIndex(context.Background()))
+	assert.True(t, created)
+}
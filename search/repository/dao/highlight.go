@@ -0,0 +1,121 @@
+package dao
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	highlightPre  = "<em>"
+	highlightPost = "</em>"
+	// snippetLen 摘要的长度，按照字符算
+	snippetLen = 100
+)
+
+type span struct {
+	start int
+	end   int
+}
+
+// matchSpans 找到 text 里面命中查询的位置，按照起始位置排序并且合并了重叠的部分。
+// 英文要整个单词匹配，中日韩文字按照子串匹配
+func matchSpans(text string, expr string) []span {
+	words := make(map[string]struct{})
+	var cjks []string
+	for _, seg := range segments(expr) {
+		if seg.cjk {
+			cjks = append(cjks, seg.text)
+		} else {
+			words[seg.text] = struct{}{}
+		}
+	}
+	var spans []span
+	for _, seg := range segments(text) {
+		if seg.cjk {
+			for _, k := range cjks {
+				for off := 0; ; {
+					idx := strings.Index(seg.text[off:], k)
+					if idx < 0 {
+						break
+					}
+					start := seg.start + off + idx
+					spans = append(spans, span{start: start, end: start + len(k)})
+					off += idx + len(k)
+				}
+			}
+			continue
+		}
+		if _, ok := words[seg.text]; ok {
+			spans = append(spans, span{start: seg.start, end: seg.end})
+		}
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].start < spans[j].start
+	})
+	res := spans[:1]
+	for _, s := range spans[1:] {
+		last := &res[len(res)-1]
+		if s.start <= last.end {
+			if s.end > last.end {
+				last.end = s.end
+			}
+			continue
+		}
+		res = append(res, s)
+	}
+	return res
+}
+
+// highlight 转义 HTML，并且用 <em></em> 把命中的部分包起来
+func highlight(text string, spans []span) string {
+	var b strings.Builder
+	prev := 0
+	for _, s := range spans {
+		b.WriteString(html.EscapeString(text[prev:s.start]))
+		b.WriteString(highlightPre)
+		b.WriteString(html.EscapeString(text[s.start:s.end]))
+		b.WriteString(highlightPost)
+		prev = s.end
+	}
+	b.WriteString(html.EscapeString(text[prev:]))
+	return b.String()
+}
+
+// snippet 从第一个命中的位置附近截取一段内容，并且高亮。
+// 没有命中的时候取开头
+func snippet(text string, expr string) string {
+	spans := matchSpans(text, expr)
+	start := 0
+	if len(spans) > 0 {
+		// 命中的位置前面留一点上下文
+		start = spans[0].start
+		for i := 0; i < snippetLen/5 && start > 0; i++ {
+			_, size := utf8.DecodeLastRuneInString(text[:start])
+			start -= size
+		}
+	}
+	end := start
+	for i := 0; i < snippetLen && end < len(text); i++ {
+		_, size := utf8.DecodeRuneInString(text[end:])
+		end += size
+	}
+	var inWindow []span
+	for _, s := range spans {
+		if s.start >= start && s.end <= end {
+			inWindow = append(inWindow, span{start: s.start - start, end: s.end - start})
+		}
+	}
+	res := highlight(text[start:end], inWindow)
+	if start > 0 {
+		res = "..." + res
+	}
+	if end < len(text) {
+		res += "..."
+	}
+	return res
+}
@@ -0,0 +1,277 @@
+package dao
+
+import (
+	"context"
+	"encoding/gob"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// BM25 的参数，用的是常见的默认值
+	bm25K1 = 1.2
+	bm25B  = 0.75
+	// titleBoost 标题命中比内容命中重要
+	titleBoost = 2.0
+)
+
+// MemoryArticleIndex 纯 Go 实现的倒排索引，数据都在内存里面。
+// 重启之后数据就没了，可以用 Snapshot 和 Restore 来持久化
+type MemoryArticleIndex struct {
+	mu   sync.RWMutex
+	docs map[int64]*memoryDoc
+	// postings 词 -> 文章 ID -> 词频
+	postings map[string]map[int64]termFreq
+	// 所有文章的标题和内容的总长度，用来算平均长度
+	titleLen   int
+	contentLen int
+	// tombstones 删除了的文章的版本号，挡住乱序到达的旧的 Upsert。
+	// 会一直变多，所以要定期调用 PruneTombstones 清理
+	tombstones map[int64]int64
+}
+
+type memoryDoc struct {
+	art        Article
+	titleLen   int
+	contentLen int
+}
+
+type termFreq struct {
+	title   int
+	content int
+}
+
+func NewMemoryArticleIndex() *MemoryArticleIndex {
+	return &MemoryArticleIndex{
+		docs:       make(map[int64]*memoryDoc),
+		postings:   make(map[string]map[int64]termFreq),
+		tombstones: make(map[int64]int64),
+	}
+}
+
+func (m *MemoryArticleIndex) Upsert(ctx context.Context, art Article) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if doc, ok := m.docs[art.Id]; ok && doc.art.Utime > art.Utime {
+		return nil
+	}
+	if version, ok := m.tombstones[art.Id]; ok {
+		if version > art.Utime {
+			return nil
+		}
+		delete(m.tombstones, art.Id)
+	}
+	m.upsert(art)
+	return nil
+}
+
+func (m *MemoryArticleIndex) upsert(art Article) {
+	m.remove(art.Id)
+	// 标签和分类也可以搜，算在标题里面
+	titleTerms := indexTerms(art.Title + " " + art.Category + " " + strings.Join(art.Tags, " "))
+	contentTerms := indexTerms(art.Content)
+	for _, t := range titleTerms {
+		tf := m.posting(t)[art.Id]
+		tf.title++
+		m.postings[t][art.Id] = tf
+	}
+	for _, t := range contentTerms {
+		tf := m.posting(t)[art.Id]
+		tf.content++
+		m.postings[t][art.Id] = tf
+	}
+	m.docs[art.Id] = &memoryDoc{art: art, titleLen: len(titleTerms), contentLen: len(contentTerms)}
+	m.titleLen += len(titleTerms)
+	m.contentLen += len(contentTerms)
+}
+
+func (m *MemoryArticleIndex) posting(term string) map[int64]termFreq {
+	p, ok := m.postings[term]
+	if !ok {
+		p = make(map[int64]termFreq)
+		m.postings[term] = p
+	}
+	return p
+}
+
+func (m *MemoryArticleIndex) Delete(ctx context.Context, id int64, version int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if doc, ok := m.docs[id]; ok && doc.art.Utime > version {
+		return nil
+	}
+	m.remove(id)
+	if version > m.tombstones[id] {
+		m.tombstones[id] = version
+	}
+	return nil
+}
+
+// remove 重新分词来找到要删除的倒排项，比遍历所有的词要快
+func (m *MemoryArticleIndex) remove(id int64) {
+	doc, ok := m.docs[id]
+	if !ok {
+		return
+	}
+	art := doc.art
+	terms := indexTerms(art.Title + " " + art.Category + " " + strings.Join(art.Tags, " ") + " " + art.Content)
+	for _, t := range terms {
+		p := m.postings[t]
+		delete(p, id)
+		if len(p) == 0 {
+			delete(m.postings, t)
+		}
+	}
+	m.titleLen -= doc.titleLen
+	m.contentLen -= doc.contentLen
+	delete(m.docs, id)
+}
+
+func (m *MemoryArticleIndex) Search(ctx context.Context, q ArticleQuery) (ArticleSearchResult, error) {
+	terms := queryTerms(q.Expression)
+	if len(terms) == 0 {
+		return ArticleSearchResult{}, nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	postings := make([]map[int64]termFreq, 0, len(terms))
+	for _, t := range terms {
+		p, ok := m.postings[t]
+		if !ok {
+			// 所有的词都要命中
+			return ArticleSearchResult{}, nil
+		}
+		postings = append(postings, p)
+	}
+	// 从最短的倒排链开始求交集
+	sort.Slice(postings, func(i, j int) bool {
+		return len(postings[i]) < len(postings[j])
+	})
+	n := float64(len(m.docs))
+	avgTitle := float64(m.titleLen) / n
+	avgContent := float64(m.contentLen) / n
+	var hits []ArticleHit
+	for id := range postings[0] {
+		doc := m.docs[id]
+		if !matchFilter(doc.art, q) {
+			continue
+		}
+		score := 0.0
+		matched := true
+		for _, p := range postings {
+			tf, ok := p[id]
+			if !ok {
+				matched = false
+				break
+			}
+			idf := math.Log(1 + (n-float64(len(p))+0.5)/(float64(len(p))+0.5))
+			score += idf * (titleBoost*bm25(tf.title, doc.titleLen, avgTitle) +
+				bm25(tf.content, doc.contentLen, avgContent))
+		}
+		if matched {
+			hits = append(hits, ArticleHit{Article: doc.art, Score: score})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].Utime != hits[j].Utime {
+			return hits[i].Utime > hits[j].Utime
+		}
+		return hits[i].Id > hits[j].Id
+	})
+	res := ArticleSearchResult{Total: int64(len(hits))}
+	if q.Offset >= len(hits) {
+		return res, nil
+	}
+	hits = hits[q.Offset:]
+	if q.Limit > 0 && len(hits) > q.Limit {
+		hits = hits[:q.Limit]
+	}
+	// 只有返回的这一页需要高亮
+	for i := range hits {
+		hits[i].HighlightTitle = highlight(hits[i].Title, matchSpans(hits[i].Title, q.Expression))
+		hits[i].Snippet = snippet(hits[i].Content, q.Expression)
+	}
+	res.Hits = hits
+	return res, nil
+}
+
+func matchFilter(art Article, q ArticleQuery) bool {
+	if q.AuthorId > 0 && art.AuthorId != q.AuthorId {
+		return false
+	}
+	if q.Start > 0 && art.Utime < q.Start {
+		return false
+	}
+	if q.End > 0 && art.Utime > q.End {
+		return false
+	}
+	return true
+}
+
+func bm25(tf int, docLen int, avgLen float64) float64 {
+	if tf == 0 {
+		return 0
+	}
+	f := float64(tf)
+	return f * (bm25K1 + 1) / (f + bm25K1*(1-bm25B+bm25B*float64(docLen)/avgLen))
+}
+
+// PruneTombstones 清理版本号比 version 旧的删除记录。
+// 这么旧的发表事件早就消费过了，不会再乱序到达
+func (m *MemoryArticleIndex) PruneTombstones(version int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, v := range m.tombstones {
+		if v < version {
+			delete(m.tombstones, id)
+		}
+	}
+}
+
+type memorySnapshot struct {
+	Articles   []Article
+	Tombstones map[int64]int64
+}
+
+// Snapshot 把所有的文章和删除记录写出去，倒排索引在 Restore 的时候重建
+func (m *MemoryArticleIndex) Snapshot(w io.Writer) error {
+	m.mu.RLock()
+	snapshot := memorySnapshot{
+		Articles:   make([]Article, 0, len(m.docs)),
+		Tombstones: make(map[int64]int64, len(m.tombstones)),
+	}
+	for _, doc := range m.docs {
+		snapshot.Articles = append(snapshot.Articles, doc.art)
+	}
+	for id, v := range m.tombstones {
+		snapshot.Tombstones[id] = v
+	}
+	m.mu.RUnlock()
+	return gob.NewEncoder(w).Encode(snapshot)
+}
+
+// Restore 从 Snapshot 的结果里面恢复，同一篇文章以 Snapshot 里面的为准
+func (m *MemoryArticleIndex) Restore(r io.Reader) error {
+	var snapshot memorySnapshot
+	err := gob.NewDecoder(r).Decode(&snapshot)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, art := range snapshot.Articles {
+		m.upsert(art)
+	}
+	for id, v := range snapshot.Tombstones {
+		if v > m.tombstones[id] {
+			m.tombstones[id] = v
+		}
+	}
+	return nil
+}
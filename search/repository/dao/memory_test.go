@@ -0,0 +1,194 @@
+package dao
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryArticleIndex_Search(t *testing.T) {
+	arts := []Article{
+		{Id: 1, AuthorId: 1, Title: "Go 并发编程", Content: "goroutine 和 channel 的用法", Utime: 100},
+		{Id: 2, AuthorId: 2, Title: "数据库索引", Content: "B+ 树和倒排索引，也可以用 Go 实现", Utime: 200},
+		{Id: 3, AuthorId: 1, Title: "搜索服务", Content: "倒排索引是搜索的核心", Tags: []string{"go"}, Utime: 300},
+		{Id: 4, AuthorId: 3, Title: "无关的文章", Content: "什么都没有", Utime: 400},
+	}
+	testCases := []struct {
+		name    string
+		q       ArticleQuery
+		wantIds []int64
+		total   int64
+	}{
+		{
+			name: "标题命中排在前面",
+			q:    ArticleQuery{Expression: "go"},
+			// 1 和 3 都是标题或者标签命中，3 的标题更短
+			wantIds: []int64{3, 1, 2},
+			total:   3,
+		},
+		{
+			name: "中文",
+			q:    ArticleQuery{Expression: "倒排索引"},
+			// 2 的标题里面有索引
+			wantIds: []int64{2, 3},
+			total:   2,
+		},
+		{
+			name:    "多个关键字都要命中",
+			q:       ArticleQuery{Expression: "倒排 go"},
+			wantIds: []int64{3, 2},
+			total:   2,
+		},
+		{
+			name:    "按照作者过滤",
+			q:       ArticleQuery{Expression: "go", AuthorId: 1},
+			wantIds: []int64{3, 1},
+			total:   2,
+		},
+		{
+			name:    "按照时间过滤",
+			q:       ArticleQuery{Expression: "go", Start: 150, End: 250},
+			wantIds: []int64{2},
+			total:   1,
+		},
+		{
+			name:    "分页",
+			q:       ArticleQuery{Expression: "go", Offset: 1, Limit: 1},
+			wantIds: []int64{1},
+			total:   3,
+		},
+		{
+			name:    "超出范围",
+			q:       ArticleQuery{Expression: "go", Offset: 10, Limit: 1},
+			wantIds: []int64{},
+			total:   3,
+		},
+		{
+			name:    "没有命中",
+			q:       ArticleQuery{Expression: "rust"},
+			wantIds: []int64{},
+		},
+	}
+	idx := NewMemoryArticleIndex()
+	for _, art := range arts {
+		require.NoError(t, idx.Upsert(context.Background(), art))
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := idx.Search(context.Background(), tc.q)
+			require.NoError(t, err)
+			assert.Equal(t, tc.total, res.Total)
+			ids := make([]int64, 0, len(res.Hits))
+			for _, h := range res.Hits {
+				ids = append(ids, h.Id)
+			}
+			assert.Equal(t, tc.wantIds, ids)
+		})
+	}
+}
+
+func TestMemoryArticleIndex_Highlight(t *testing.T) {
+	idx := NewMemoryArticleIndex()
+	content := strings.Repeat("无关的内容。", 30) + "这里讲的是倒排索引。" + strings.Repeat("后面的内容。", 30)
+	require.NoError(t, idx.Upsert(context.Background(), Article{
+		Id: 1, Title: "<倒排索引>入门", Content: content,
+	}))
+	res, err := idx.Search(context.Background(), ArticleQuery{Expression: "倒排索引"})
+	require.NoError(t, err)
+	require.Len(t, res.Hits, 1)
+	assert.Equal(t, "&lt;<em>倒排索引</em>&gt;入门", res.Hits[0].HighlightTitle)
+	assert.True(t, strings.HasPrefix(res.Hits[0].Snippet, "..."))
+	assert.True(t, strings.HasSuffix(res.Hits[0].Snippet, "..."))
+	assert.Contains(t, res.Hits[0].Snippet, "这里讲的是<em>倒排索引</em>。")
+}
+
+func TestMemoryArticleIndex_UpsertDelete(t *testing.T) {
+	idx := NewMemoryArticleIndex()
+	ctx := context.Background()
+	require.NoError(t, idx.Upsert(ctx, Article{Id: 1, Title: "旧的标题"}))
+	// 更新之后旧的词就搜不到了
+	require.NoError(t, idx.Upsert(ctx, Article{Id: 1, Title: "新的标题"}))
+	res, err := idx.Search(ctx, ArticleQuery{Expression: "旧的"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), res.Total)
+	res, err = idx.Search(ctx, ArticleQuery{Expression: "新的"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), res.Total)
+
+	require.NoError(t, idx.Delete(ctx, 1, 0))
+	// 删除不存在的文章不会报错
+	require.NoError(t, idx.Delete(ctx, 1, 0))
+	res, err = idx.Search(ctx, ArticleQuery{Expression: "新的"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), res.Total)
+	assert.Empty(t, idx.postings)
+}
+
+func TestMemoryArticleIndex_Version(t *testing.T) {
+	idx := NewMemoryArticleIndex()
+	ctx := context.Background()
+	search := func(expr string) int64 {
+		res, err := idx.Search(ctx, ArticleQuery{Expression: expr})
+		require.NoError(t, err)
+		return res.Total
+	}
+	require.NoError(t, idx.Upsert(ctx, Article{Id: 1, Title: "新的标题", Utime: 200}))
+	// 旧的发表事件后到，不覆盖
+	require.NoError(t, idx.Upsert(ctx, Article{Id: 1, Title: "旧的标题", Utime: 100}))
+	assert.Equal(t, int64(1), search("新的"))
+	assert.Equal(t, int64(0), search("旧的"))
+	// 撤回事件比重新发表旧，不删除
+	require.NoError(t, idx.Delete(ctx, 1, 150))
+	assert.Equal(t, int64(1), search("新的"))
+
+	require.NoError(t, idx.Delete(ctx, 1, 300))
+	assert.Equal(t, int64(0), search("新的"))
+	// 撤回之后才到的旧的发表事件被挡住
+	require.NoError(t, idx.Upsert(ctx, Article{Id: 1, Title: "新的标题", Utime: 200}))
+	assert.Equal(t, int64(0), search("新的"))
+	// 撤回之后重新发表
+	require.NoError(t, idx.Upsert(ctx, Article{Id: 1, Title: "再发表", Utime: 400}))
+	assert.Equal(t, int64(1), search("再发表"))
+	assert.Empty(t, idx.tombstones)
+}
+
+func TestMemoryArticleIndex_Snapshot(t *testing.T) {
+	ctx := context.Background()
+	idx := NewMemoryArticleIndex()
+	require.NoError(t, idx.Upsert(ctx, Article{Id: 1, AuthorId: 2, Title: "搜索服务", Tags: []string{"go"}, Utime: 100}))
+	require.NoError(t, idx.Upsert(ctx, Article{Id: 2, AuthorId: 2, Title: "Go 并发", Utime: 200}))
+	var buf bytes.Buffer
+	require.NoError(t, idx.Snapshot(&buf))
+
+	restored := NewMemoryArticleIndex()
+	require.NoError(t, restored.Restore(&buf))
+	want, err := idx.Search(ctx, ArticleQuery{Expression: "go"})
+	require.NoError(t, err)
+	got, err := restored.Search(ctx, ArticleQuery{Expression: "go"})
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestMemoryArticleIndex_SnapshotTombstones(t *testing.T) {
+	ctx := context.Background()
+	idx := NewMemoryArticleIndex()
+	require.NoError(t, idx.Delete(ctx, 1, 100))
+	require.NoError(t, idx.Delete(ctx, 2, 300))
+	// 旧的删除记录清理掉
+	idx.PruneTombstones(200)
+	assert.Equal(t, map[int64]int64{2: 300}, idx.tombstones)
+
+	var buf bytes.Buffer
+	require.NoError(t, idx.Snapshot(&buf))
+	restored := NewMemoryArticleIndex()
+	require.NoError(t, restored.Restore(&buf))
+	// 删除记录也要恢复，不然重启之后乱序到达的旧的发表事件会把文章加回来
+	require.NoError(t, restored.Upsert(ctx, Article{Id: 2, Title: "已经撤回", Utime: 250}))
+	res, err := restored.Search(ctx, ArticleQuery{Expression: "撤回"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), res.Total)
+}
@@ -0,0 +1,112 @@
+package dao
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// segment 一段连续的文字，要么是一个英文单词（或者数字），要么是一串中日韩文字
+type segment struct {
+	// text 英文单词会转成小写
+	text string
+	// start 和 end 是在原文里面的字节偏移量
+	start int
+	end   int
+	cjk   bool
+}
+
+// isCJK 中日韩的文字没有空格分词，按照字来切
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
+
+func isWord(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// segments 把文本切成英文单词和中日韩文字串，标点和空白都丢掉
+func segments(text string) []segment {
+	var res []segment
+	start := -1
+	cjk := false
+	flush := func(end int) {
+		if start >= 0 {
+			seg := segment{text: text[start:end], start: start, end: end, cjk: cjk}
+			if !cjk {
+				seg.text = strings.ToLower(seg.text)
+			}
+			res = append(res, seg)
+		}
+		start = -1
+	}
+	for i, r := range text {
+		switch {
+		case isCJK(r):
+			if start >= 0 && !cjk {
+				flush(i)
+			}
+			if start < 0 {
+				start, cjk = i, true
+			}
+		case isWord(r):
+			if start >= 0 && cjk {
+				flush(i)
+			}
+			if start < 0 {
+				start, cjk = i, false
+			}
+		default:
+			flush(i)
+		}
+	}
+	flush(len(text))
+	return res
+}
+
+// indexTerms 建索引用的词。
+// 英文按照单词；中日韩文字同时索引单字和相邻的两个字，这样单字和多个字的查询都能命中
+func indexTerms(text string) []string {
+	var res []string
+	for _, seg := range segments(text) {
+		if !seg.cjk {
+			res = append(res, seg.text)
+			continue
+		}
+		runes := []rune(seg.text)
+		for i := range runes {
+			res = append(res, string(runes[i]))
+			if i+1 < len(runes) {
+				res = append(res, string(runes[i:i+2]))
+			}
+		}
+	}
+	return res
+}
+
+// queryTerms 查询用的词，已经去重。
+// 中日韩文字超过一个字的时候只用相邻的两个字，所有的词都要命中，近似于短语查询
+func queryTerms(expr string) []string {
+	seen := make(map[string]struct{})
+	var res []string
+	add := func(term string) {
+		if _, ok := seen[term]; !ok {
+			seen[term] = struct{}{}
+			res = append(res, term)
+		}
+	}
+	for _, seg := range segments(expr) {
+		if !seg.cjk || utf8.RuneCountInString(seg.text) == 1 {
+			add(seg.text)
+			continue
+		}
+		runes := []rune(seg.text)
+		for i := 0; i+1 < len(runes); i++ {
+			add(string(runes[i : i+2]))
+		}
+	}
+	return res
+}
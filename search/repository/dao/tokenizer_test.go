@@ -0,0 +1,109 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexTerms(t *testing.T) {
+	testCases := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "英文转小写",
+			text: "Hello, Go-World!",
+			want: []string{"hello", "go", "world"},
+		},
+		{
+			name: "中文单字加双字",
+			text: "搜索服务",
+			want: []string{"搜", "搜索", "索", "索服", "服", "服务", "务"},
+		},
+		{
+			name: "中英混合",
+			text: "学习Go语言",
+			want: []string{"学", "学习", "习", "go", "语", "语言", "言"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, indexTerms(tc.text))
+		})
+	}
+}
+
+func TestQueryTerms(t *testing.T) {
+	testCases := []struct {
+		name string
+		expr string
+		want []string
+	}{
+		{
+			name: "去重",
+			expr: "go Go GO",
+			want: []string{"go"},
+		},
+		{
+			name: "中文只用双字",
+			expr: "搜索服务",
+			want: []string{"搜索", "索服", "服务"},
+		},
+		{
+			name: "单个汉字",
+			expr: "搜 go",
+			want: []string{"搜", "go"},
+		},
+		{
+			name: "全是标点",
+			expr: "，。!",
+			want: []string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ElementsMatch(t, tc.want, queryTerms(tc.expr))
+		})
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	testCases := []struct {
+		name string
+		text string
+		expr string
+		want string
+	}{
+		{
+			name: "英文整个单词",
+			text: "Go is good, goroutine too",
+			expr: "go",
+			want: "<em>Go</em> is good, goroutine too",
+		},
+		{
+			name: "中文子串",
+			text: "如何实现搜索服务",
+			expr: "搜索",
+			want: "如何实现<em>搜索</em>服务",
+		},
+		{
+			name: "相邻的命中合并",
+			text: "搜索服务",
+			expr: "搜索 服务",
+			want: "<em>搜索服务</em>",
+		},
+		{
+			name: "转义",
+			text: "<b>go</b>",
+			expr: "go",
+			want: "&lt;b&gt;<em>go</em>&lt;/b&gt;",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, highlight(tc.text, matchSpans(tc.text, tc.expr)))
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package dao
+
+import "context"
+
+// ArticleIndex 文章的倒排索引，可以换成不同的实现：
+// MemoryArticleIndex 是纯 Go 的嵌入式实现，本地开发和测试都可以直接用；
+// ElasticArticleIndex 使用外部的 Elasticsearch
+type ArticleIndex interface {
+	// Upsert 文章已经存在的时候覆盖。art.Utime 是版本号，
+	// 比索引里面的文章或者删除时的版本旧的时候什么也不做
+	Upsert(ctx context.Context, art Article) error
+	// Delete 文章不存在的时候不返回错误。version 是撤回时间，
+	// 索引里面的文章比它新的时候什么也不做，删除之后挡住比它旧的 Upsert
+	Delete(ctx context.Context, id int64, version int64) error
+	Search(ctx context.Context, q ArticleQuery) (ArticleSearchResult, error)
+}
+
+type Article struct {
+	Id       int64    `json:"id"`
+	AuthorId int64    `json:"author_id"`
+	Title    string   `json:"title"`
+	Content  string   `json:"content"`
+	Category string   `json:"category"`
+	Tags     []string `json:"tags"`
+	// Utime 发表时间，毫秒数
+	Utime int64 `json:"utime"`
+}
+
+// ArticleQuery 过滤条件是 0 的时候不过滤
+type ArticleQuery struct {
+	Expression string
+	AuthorId   int64
+	// Start 和 End 是毫秒数，闭区间
+	Start  int64
+	End    int64
+	Offset int
+	Limit  int
+}
+
+type ArticleHit struct {
+	Article
+	// HighlightTitle 和 Snippet 里面的内容已经转义过 HTML 了
+	HighlightTitle string
+	Snippet        string
+	Score          float64
+}
+
+type ArticleSearchResult struct {
+	Hits  []ArticleHit
+	Total int64
+}
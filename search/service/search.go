@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"webooktrial/search/domain"
+	"webooktrial/search/repository"
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 100
+)
+
+type SearchService interface {
+	SearchArticle(ctx context.Context, q domain.ArticleQuery) (domain.ArticleSearchResult, error)
+}
+
+// SyncService 文章发表和撤回的时候，同步到索引里面。
+// 发表和撤回事件到达的顺序没有保证，用发表时间和撤回时间丢掉旧的事件
+type SyncService interface {
+	InputArticle(ctx context.Context, art domain.Article) error
+	DeleteArticle(ctx context.Context, id int64, utime time.Time) error
+}
+
+type searchService struct {
+	repo repository.ArticleRepository
+}
+
+func NewSearchService(repo repository.ArticleRepository) SearchService {
+	return &searchService{repo: repo}
+}
+
+func (s *searchService) SearchArticle(ctx context.Context, q domain.ArticleQuery) (domain.ArticleSearchResult, error) {
+	q.Expression = strings.TrimSpace(q.Expression)
+	if q.Expression == "" {
+		return domain.ArticleSearchResult{}, nil
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultLimit
+	}
+	if q.Limit > maxLimit {
+		q.Limit = maxLimit
+	}
+	return s.repo.SearchArticle(ctx, q)
+}
+
+type syncService struct {
+	repo repository.ArticleRepository
+}
+
+func NewSyncService(repo repository.ArticleRepository) SyncService {
+	return &syncService{repo: repo}
+}
+
+func (s *syncService) InputArticle(ctx context.Context, art domain.Article) error {
+	return s.repo.InputArticle(ctx, art)
+}
+
+func (s *syncService) DeleteArticle(ctx context.Context, id int64, utime time.Time) error {
+	return s.repo.DeleteArticle(ctx, id, utime)
+}
@@ -0,0 +1,40 @@
+//go:build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+
+	"webooktrial/search/events"
+	"webooktrial/search/grpc"
+	"webooktrial/search/ioc"
+	"webooktrial/search/repository"
+	"webooktrial/search/service"
+)
+
+var thirdPartySet = wire.NewSet(
+	ioc.InitLogger,
+	ioc.InitKafka,
+	ioc.InitGroupID,
+	ioc.InitArticleIndex,
+	ioc.InitOffsets,
+	ioc.InitIndexSnapshot)
+
+var searchSvcProvider = wire.NewSet(
+	service.NewSearchService,
+	service.NewSyncService,
+	repository.NewArticleRepository,
+)
+
+func InitApp() *App {
+	wire.Build(thirdPartySet,
+		searchSvcProvider,
+		events.NewArticlePublishedConsumer,
+		events.NewArticleWithdrawnConsumer,
+		grpc.NewSearchServiceServer,
+		ioc.NewConsumers,
+		ioc.InitGRPCxServer,
+		wire.Struct(new(App), "*"),
+	)
+	return new(App)
+}
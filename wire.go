@@ -36,6 +36,29 @@ var rankingServiceSet = wire.NewSet(
 	service.NewBatchRankingService,
 )
 
+var historyServiceSet = wire.NewSet(
+	dao.NewGORMHistoryRecordDAO,
+	redis.NewRedisHistoryCache,
+	repository.NewCachedHistoryRecordRepository,
+	service.NewHistoryService,
+)
+
+var jobServiceSet = wire.NewSet(
+	dao.NewGormJobDAO,
+	repository.NewPreemptCronJobRepository,
+	service.NewCronJobService,
+	ioc.InitLocalFuncExecutor,
+	ioc.InitScheduler,
+)
+
+var uploadServiceSet = wire.NewSet(
+	dao.NewGORMUploadDAO,
+	repository.NewUploadRepository,
+	service.NewUploadService,
+	ioc.InitObjectStore,
+	ioc.InitUploadConfig,
+)
+
 func InitWebServer() *App {
 	wire.Build(
 		// 最基础的第三方依赖
@@ -54,26 +77,41 @@ func InitWebServer() *App {
 		// 启用了 etcd 作为配置中心
 		ioc.InitEtcd,
 		ioc.InitIntrGRPCClientV1,
+		ioc.InitSearchGRPCClient,
+		ioc.InitCommentGRPCClient,
 
 		rankingServiceSet,
+		historyServiceSet,
+		uploadServiceSet,
+		jobServiceSet,
 		ioc.InitJobs,
 		ioc.InitRankingJob,
+		ioc.InitArticleScheduleJob,
+		ioc.InitUploadCleanupJob,
 
 		// consumer
 		//events.NewInteractiveReadEventBatchConsumer,
-		article.NewKafkaProducer,
+		article.NewCacheInvalidationConsumer,
+		article.NewHistoryReadEventConsumer,
+		ioc.InitArticleProducer,
+		wire.Bind(new(article.Producer), new(*article.BatchReadEventProducer)),
 
 		// 初始化 DAO
 		dao.NewUserDAO,
 		article2.NewGormArticleDao,
+		article2.NewGORMTagDAO,
 
 		redis.NewUserCache,
 		redis.NewCodeCache,
 		redis.NewRedisArticleCache,
+		redis.NewRedisArticleInvalidator,
+		local.NewArticleLocalCache,
 
 		repository.NewUserRepository,
 		repository.NewCodeRepository,
 		article3.NewArticleRepository,
+		ioc.InitArticleRevisionRetention,
+		ioc.InitModerator,
 
 		service.NewUserService,
 		service.NewCodeService,
@@ -85,6 +123,7 @@ func InitWebServer() *App {
 		web.NewOAuth2WechatHandler,
 		web.NewUserHandler,
 		web.NewArticleHandler,
+		web.NewUploadHandler,
 		//ioc.NewWechatHandlerConfig,
 		ijwt.NewRedisJWTHandler,
 
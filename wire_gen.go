@@ -49,28 +49,56 @@ func InitWebServer() *App {
 	wechatService := ioc.InitWechatService(loggerV1)
 	oAuth2WechatHandler := web.NewOAuth2WechatHandler(wechatService, userService, handler)
 	articleDao := article.NewGormArticleDao(db)
+	tagDAO := article.NewGORMTagDAO(db)
 	articleCache := redis.NewRedisArticleCache(cmdable)
-	articleRepository := article2.NewArticleRepository(articleDao, loggerV1, articleCache, userRepository)
+	articleLocalCache := local.NewArticleLocalCache()
+	articleInvalidator := redis.NewRedisArticleInvalidator(cmdable)
+	revisionRetention := ioc.InitArticleRevisionRetention()
+	articleRepository := article2.NewArticleRepository(articleDao, loggerV1, tagDAO, articleCache, articleLocalCache, articleInvalidator, userRepository, revisionRetention)
 	client := ioc.InitKafka()
 	syncProducer := ioc.NewSyncProducer(client)
-	producer := article3.NewKafkaProducer(syncProducer)
-	articleService := service.NewArticleService(articleRepository, loggerV1, producer)
+	batchReadEventProducer := ioc.InitArticleProducer(client, syncProducer, loggerV1)
+	moderator := ioc.InitModerator(loggerV1)
+	articleService := service.NewArticleService(articleRepository, loggerV1, batchReadEventProducer, moderator)
 	clientv3Client := ioc.InitEtcd()
 	interactiveServiceClient := ioc.InitIntrGRPCClientV1(clientv3Client)
-	articleHandler := web.NewArticleHandler(articleService, loggerV1, interactiveServiceClient)
-	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler)
-	v2 := ioc.NewConsumers()
 	rankingRedisCache := redis.NewRankingRedisCache(cmdable)
 	rankingLocalCache := local.NewRankingLocalCache()
 	rankingRepository := repository.NewCachedRankingRepository(rankingRedisCache, rankingLocalCache)
 	rankingService := service.NewBatchRankingService(articleService, interactiveServiceClient, rankingRepository)
+	searchServiceClient := ioc.InitSearchGRPCClient(clientv3Client)
+	historyRecordDAO := dao.NewGORMHistoryRecordDAO(db)
+	historyCache := redis.NewRedisHistoryCache(cmdable)
+	historyRecordRepository := repository.NewCachedHistoryRecordRepository(historyRecordDAO, historyCache, loggerV1)
+	historyService := service.NewHistoryService(historyRecordRepository, articleRepository, loggerV1)
+	uploadDAO := dao.NewGORMUploadDAO(db)
+	uploadRepository := repository.NewUploadRepository(uploadDAO)
+	objectStore := ioc.InitObjectStore()
+	uploadConfig := ioc.InitUploadConfig()
+	uploadService := service.NewUploadService(uploadRepository, objectStore, uploadConfig, loggerV1)
+	commentServiceClient := ioc.InitCommentGRPCClient(clientv3Client)
+	articleHandler := web.NewArticleHandler(articleService, loggerV1, interactiveServiceClient, rankingService, searchServiceClient, historyService, uploadService, commentServiceClient)
+	uploadHandler := web.NewUploadHandler(uploadService, objectStore, uploadConfig, loggerV1)
+	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler, uploadHandler, objectStore)
+	cacheInvalidationConsumer := article3.NewCacheInvalidationConsumer(articleInvalidator, articleLocalCache, rankingRepository, loggerV1)
+	historyReadEventConsumer := article3.NewHistoryReadEventConsumer(client, historyRecordRepository, loggerV1)
+	v2 := ioc.NewConsumers(cacheInvalidationConsumer, historyReadEventConsumer)
 	rlockClient := ioc.InitRLockClient(cmdable)
 	rankingJob := ioc.InitRankingJob(rankingService, rlockClient, loggerV1)
 	cron := ioc.InitJobs(loggerV1, rankingJob)
+	localFuncExecutor := ioc.InitLocalFuncExecutor(rankingService)
+	articleScheduleJob := ioc.InitArticleScheduleJob(articleService, loggerV1)
+	uploadCleanupJob := ioc.InitUploadCleanupJob(uploadService, loggerV1)
+	jobDAO := dao.NewGormJobDAO(db)
+	jobRepository := repository.NewPreemptCronJobRepository(jobDAO)
+	jobService := service.NewCronJobService(jobRepository, loggerV1)
+	scheduler := ioc.InitScheduler(loggerV1, localFuncExecutor, articleScheduleJob, uploadCleanupJob, jobService)
 	app := &App{
-		web:       engine,
-		consumers: v2,
-		cron:      cron,
+		web:          engine,
+		consumers:    v2,
+		cron:         cron,
+		scheduler:    scheduler,
+		readProducer: batchReadEventProducer,
 	}
 	return app
 }
@@ -80,3 +108,9 @@ func InitWebServer() *App {
 var interactiveSvcProvider = wire.NewSet(service2.NewInteractiveService, repository2.NewCachedInteractiveRepository, dao2.NewGORMInteractiveDAO, redis2.NewRedisInteractiveCache)
 
 var rankingServiceSet = wire.NewSet(repository.NewCachedRankingRepository, redis.NewRankingRedisCache, local.NewRankingLocalCache, service.NewBatchRankingService)
+
+var historyServiceSet = wire.NewSet(dao.NewGORMHistoryRecordDAO, redis.NewRedisHistoryCache, repository.NewCachedHistoryRecordRepository, service.NewHistoryService)
+
+var jobServiceSet = wire.NewSet(dao.NewGormJobDAO, repository.NewPreemptCronJobRepository, service.NewCronJobService, ioc.InitLocalFuncExecutor, ioc.InitScheduler)
+
+var uploadServiceSet = wire.NewSet(dao.NewGORMUploadDAO, repository.NewUploadRepository, service.NewUploadService, ioc.InitObjectStore, ioc.InitUploadConfig)